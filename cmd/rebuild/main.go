@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/somatom98/brokeli/internal/features/rebuild_projections"
+	"github.com/somatom98/brokeli/internal/setup"
+)
+
+func main() {
+	projection := flag.String("projection", "", "projection to rebuild")
+	mode := flag.String("mode", string(rebuild_projections.ModeShadow), "rebuild mode: shadow or in_place")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	dsn := os.Getenv("DB_DSN")
+	db, err := setup.OpenDB(dsn)
+	if err != nil {
+		log.Fatalf("Setup: %v", err)
+	}
+	defer db.Close()
+
+	transactionES, accountES, loanES, ruleES, _, err := setup.EventStores(db)
+	if err != nil {
+		log.Fatalf("Setup: %v", err)
+	}
+
+	rebuilder := setup.Rebuilder(db, dsn, transactionES, accountES, loanES, ruleES)
+	if *projection == "" {
+		fmt.Fprintf(os.Stderr, "usage: rebuild -projection <%s> [-mode shadow|in_place]\n", strings.Join(rebuilder.Projections(), "|"))
+		os.Exit(2)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if p, ok := rebuilder.Progress(*projection); ok {
+					log.Printf("%s: %d events processed, position %d of %d", p.Projection, p.Processed, p.Position, p.Head)
+				}
+			}
+		}
+	}()
+
+	progress, err := rebuilder.Rebuild(ctx, *projection, rebuild_projections.Mode(*mode))
+	close(done)
+	if err != nil {
+		log.Fatalf("rebuild of %s failed: %v", *projection, err)
+	}
+
+	log.Printf("%s rebuilt: %d events replayed in %s", progress.Projection, progress.Processed, progress.FinishedAt.Sub(progress.StartedAt).Round(time.Millisecond))
+}
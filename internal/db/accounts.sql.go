@@ -59,17 +59,52 @@ func (q *Queries) GetAccountBalanceForUpdate(ctx context.Context, id uuid.UUID)
 	return balance, err
 }
 
+const getAccountExpectedReimbursementsForUpdate = `-- name: GetAccountExpectedReimbursementsForUpdate :one
+SELECT expected_reimbursements
+FROM accounts_projection
+WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetAccountExpectedReimbursementsForUpdate(ctx context.Context, id uuid.UUID) (json.RawMessage, error) {
+	row := q.db.QueryRowContext(ctx, getAccountExpectedReimbursementsForUpdate, id)
+	var expected_reimbursements json.RawMessage
+	err := row.Scan(&expected_reimbursements)
+	return expected_reimbursements, err
+}
+
+const getAccountOverdraftLimitsForUpdate = `-- name: GetAccountOverdraftLimitsForUpdate :one
+SELECT overdraft_limits
+FROM accounts_projection
+WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetAccountOverdraftLimitsForUpdate(ctx context.Context, id uuid.UUID) (json.RawMessage, error) {
+	row := q.db.QueryRowContext(ctx, getAccountOverdraftLimitsForUpdate, id)
+	var overdraft_limits json.RawMessage
+	err := row.Scan(&overdraft_limits)
+	return overdraft_limits, err
+}
+
 const getAllAccounts = `-- name: GetAllAccounts :many
-SELECT id, name, balance, created_at, closed_at 
+SELECT id, name, kind, institution, account_number, owner, icon, color, tags, balance, expected_reimbursements, overdraft_limits, created_at, closed_at 
 FROM accounts_projection
 `
 
 type GetAllAccountsRow struct {
-	ID        uuid.UUID       `json:"id"`
-	Name      string          `json:"name"`
-	Balance   json.RawMessage `json:"balance"`
-	CreatedAt sql.NullTime    `json:"created_at"`
-	ClosedAt  sql.NullTime    `json:"closed_at"`
+	ID                     uuid.UUID       `json:"id"`
+	Name                   string          `json:"name"`
+	Kind                   string          `json:"kind"`
+	Institution            string          `json:"institution"`
+	AccountNumber          string          `json:"account_number"`
+	Owner                  string          `json:"owner"`
+	Icon                   string          `json:"icon"`
+	Color                  string          `json:"color"`
+	Tags                   json.RawMessage `json:"tags"`
+	Balance                json.RawMessage `json:"balance"`
+	ExpectedReimbursements json.RawMessage `json:"expected_reimbursements"`
+	OverdraftLimits        json.RawMessage `json:"overdraft_limits"`
+	CreatedAt              sql.NullTime    `json:"created_at"`
+	ClosedAt               sql.NullTime    `json:"closed_at"`
 }
 
 func (q *Queries) GetAllAccounts(ctx context.Context) ([]GetAllAccountsRow, error) {
@@ -84,7 +119,16 @@ func (q *Queries) GetAllAccounts(ctx context.Context) ([]GetAllAccountsRow, erro
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
+			&i.Kind,
+			&i.Institution,
+			&i.AccountNumber,
+			&i.Owner,
+			&i.Icon,
+			&i.Color,
+			&i.Tags,
 			&i.Balance,
+			&i.ExpectedReimbursements,
+			&i.OverdraftLimits,
 			&i.CreatedAt,
 			&i.ClosedAt,
 		); err != nil {
@@ -101,6 +145,17 @@ func (q *Queries) GetAllAccounts(ctx context.Context) ([]GetAllAccountsRow, erro
 	return items, nil
 }
 
+const reopenAccount = `-- name: ReopenAccount :exec
+UPDATE accounts_projection
+SET closed_at = NULL
+WHERE id = $1
+`
+
+func (q *Queries) ReopenAccount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, reopenAccount, id)
+	return err
+}
+
 const updateAccountBalance = `-- name: UpdateAccountBalance :exec
 UPDATE accounts_projection 
 SET balance = $2 
@@ -117,6 +172,67 @@ func (q *Queries) UpdateAccountBalance(ctx context.Context, arg UpdateAccountBal
 	return err
 }
 
+const updateAccountDetails = `-- name: UpdateAccountDetails :exec
+UPDATE accounts_projection
+SET institution = $2, account_number = $3, owner = $4, icon = $5, color = $6, tags = $7
+WHERE id = $1
+`
+
+type UpdateAccountDetailsParams struct {
+	ID            uuid.UUID       `json:"id"`
+	Institution   string          `json:"institution"`
+	AccountNumber string          `json:"account_number"`
+	Owner         string          `json:"owner"`
+	Icon          string          `json:"icon"`
+	Color         string          `json:"color"`
+	Tags          json.RawMessage `json:"tags"`
+}
+
+func (q *Queries) UpdateAccountDetails(ctx context.Context, arg UpdateAccountDetailsParams) error {
+	_, err := q.db.ExecContext(ctx, updateAccountDetails,
+		arg.ID,
+		arg.Institution,
+		arg.AccountNumber,
+		arg.Owner,
+		arg.Icon,
+		arg.Color,
+		arg.Tags,
+	)
+	return err
+}
+
+const updateAccountExpectedReimbursements = `-- name: UpdateAccountExpectedReimbursements :exec
+UPDATE accounts_projection
+SET expected_reimbursements = $2
+WHERE id = $1
+`
+
+type UpdateAccountExpectedReimbursementsParams struct {
+	ID                     uuid.UUID       `json:"id"`
+	ExpectedReimbursements json.RawMessage `json:"expected_reimbursements"`
+}
+
+func (q *Queries) UpdateAccountExpectedReimbursements(ctx context.Context, arg UpdateAccountExpectedReimbursementsParams) error {
+	_, err := q.db.ExecContext(ctx, updateAccountExpectedReimbursements, arg.ID, arg.ExpectedReimbursements)
+	return err
+}
+
+const updateAccountKind = `-- name: UpdateAccountKind :exec
+UPDATE accounts_projection
+SET kind = $2
+WHERE id = $1
+`
+
+type UpdateAccountKindParams struct {
+	ID   uuid.UUID `json:"id"`
+	Kind string    `json:"kind"`
+}
+
+func (q *Queries) UpdateAccountKind(ctx context.Context, arg UpdateAccountKindParams) error {
+	_, err := q.db.ExecContext(ctx, updateAccountKind, arg.ID, arg.Kind)
+	return err
+}
+
 const updateAccountName = `-- name: UpdateAccountName :exec
 UPDATE accounts_projection
 SET name = $2
@@ -133,6 +249,22 @@ func (q *Queries) UpdateAccountName(ctx context.Context, arg UpdateAccountNamePa
 	return err
 }
 
+const updateAccountOverdraftLimits = `-- name: UpdateAccountOverdraftLimits :exec
+UPDATE accounts_projection
+SET overdraft_limits = $2
+WHERE id = $1
+`
+
+type UpdateAccountOverdraftLimitsParams struct {
+	ID              uuid.UUID       `json:"id"`
+	OverdraftLimits json.RawMessage `json:"overdraft_limits"`
+}
+
+func (q *Queries) UpdateAccountOverdraftLimits(ctx context.Context, arg UpdateAccountOverdraftLimitsParams) error {
+	_, err := q.db.ExecContext(ctx, updateAccountOverdraftLimits, arg.ID, arg.OverdraftLimits)
+	return err
+}
+
 const upsertPlaceholderAccount = `-- name: UpsertPlaceholderAccount :exec
 INSERT INTO accounts_projection (id, name, balance) 
 VALUES ($1, $2, $3)
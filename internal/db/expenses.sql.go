@@ -0,0 +1,240 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: expenses.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createExpense = `-- name: CreateExpense :exec
+INSERT INTO expenses (transaction_id, account_id, currency, cost, happened_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (transaction_id) DO NOTHING
+`
+
+type CreateExpenseParams struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	AccountID     uuid.UUID `json:"account_id"`
+	Currency      string    `json:"currency"`
+	Cost          string    `json:"cost"`
+	HappenedAt    time.Time `json:"happened_at"`
+}
+
+func (q *Queries) CreateExpense(ctx context.Context, arg CreateExpenseParams) error {
+	_, err := q.db.ExecContext(ctx, createExpense,
+		arg.TransactionID,
+		arg.AccountID,
+		arg.Currency,
+		arg.Cost,
+		arg.HappenedAt,
+	)
+	return err
+}
+
+const getExpense = `-- name: GetExpense :one
+SELECT transaction_id, account_id, currency, cost, receivable_account_id, counterparty, expected, received, outstanding, happened_at
+FROM expenses
+WHERE transaction_id = $1 AND voided_at IS NULL
+`
+
+type GetExpenseRow struct {
+	TransactionID       uuid.UUID     `json:"transaction_id"`
+	AccountID           uuid.UUID     `json:"account_id"`
+	Currency            string        `json:"currency"`
+	Cost                string        `json:"cost"`
+	ReceivableAccountID uuid.NullUUID `json:"receivable_account_id"`
+	Counterparty        string        `json:"counterparty"`
+	Expected            string        `json:"expected"`
+	Received            string        `json:"received"`
+	Outstanding         string        `json:"outstanding"`
+	HappenedAt          time.Time     `json:"happened_at"`
+}
+
+func (q *Queries) GetExpense(ctx context.Context, transactionID uuid.UUID) (GetExpenseRow, error) {
+	row := q.db.QueryRowContext(ctx, getExpense, transactionID)
+	var i GetExpenseRow
+	err := row.Scan(
+		&i.TransactionID,
+		&i.AccountID,
+		&i.Currency,
+		&i.Cost,
+		&i.ReceivableAccountID,
+		&i.Counterparty,
+		&i.Expected,
+		&i.Received,
+		&i.Outstanding,
+		&i.HappenedAt,
+	)
+	return i, err
+}
+
+const listOutstandingByAccount = `-- name: ListOutstandingByAccount :many
+SELECT receivable_account_id, currency, SUM(outstanding)::DECIMAL AS outstanding
+FROM expenses
+WHERE voided_at IS NULL AND outstanding > 0
+GROUP BY receivable_account_id, currency
+ORDER BY receivable_account_id, currency
+`
+
+type ListOutstandingByAccountRow struct {
+	ReceivableAccountID uuid.NullUUID `json:"receivable_account_id"`
+	Currency            string        `json:"currency"`
+	Outstanding         string        `json:"outstanding"`
+}
+
+func (q *Queries) ListOutstandingByAccount(ctx context.Context) ([]ListOutstandingByAccountRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOutstandingByAccount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOutstandingByAccountRow
+	for rows.Next() {
+		var i ListOutstandingByAccountRow
+		if err := rows.Scan(&i.ReceivableAccountID, &i.Currency, &i.Outstanding); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutstandingByCounterparty = `-- name: ListOutstandingByCounterparty :many
+SELECT counterparty, currency, SUM(outstanding)::DECIMAL AS outstanding
+FROM expenses
+WHERE voided_at IS NULL AND outstanding > 0
+GROUP BY counterparty, currency
+ORDER BY counterparty, currency
+`
+
+type ListOutstandingByCounterpartyRow struct {
+	Counterparty string `json:"counterparty"`
+	Currency     string `json:"currency"`
+	Outstanding  string `json:"outstanding"`
+}
+
+func (q *Queries) ListOutstandingByCounterparty(ctx context.Context) ([]ListOutstandingByCounterpartyRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOutstandingByCounterparty)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOutstandingByCounterpartyRow
+	for rows.Next() {
+		var i ListOutstandingByCounterpartyRow
+		if err := rows.Scan(&i.Counterparty, &i.Currency, &i.Outstanding); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateExpenseCost = `-- name: UpdateExpenseCost :exec
+UPDATE expenses
+SET cost = $2
+WHERE transaction_id = $1
+`
+
+type UpdateExpenseCostParams struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Cost          string    `json:"cost"`
+}
+
+func (q *Queries) UpdateExpenseCost(ctx context.Context, arg UpdateExpenseCostParams) error {
+	_, err := q.db.ExecContext(ctx, updateExpenseCost, arg.TransactionID, arg.Cost)
+	return err
+}
+
+const updateExpenseHappenedAt = `-- name: UpdateExpenseHappenedAt :exec
+UPDATE expenses
+SET happened_at = $2
+WHERE transaction_id = $1
+`
+
+type UpdateExpenseHappenedAtParams struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	HappenedAt    time.Time `json:"happened_at"`
+}
+
+func (q *Queries) UpdateExpenseHappenedAt(ctx context.Context, arg UpdateExpenseHappenedAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateExpenseHappenedAt, arg.TransactionID, arg.HappenedAt)
+	return err
+}
+
+const updateExpenseReceivable = `-- name: UpdateExpenseReceivable :exec
+UPDATE expenses
+SET receivable_account_id = $2, counterparty = $3, expected = $4, outstanding = $5
+WHERE transaction_id = $1
+`
+
+type UpdateExpenseReceivableParams struct {
+	TransactionID       uuid.UUID     `json:"transaction_id"`
+	ReceivableAccountID uuid.NullUUID `json:"receivable_account_id"`
+	Counterparty        string        `json:"counterparty"`
+	Expected            string        `json:"expected"`
+	Outstanding         string        `json:"outstanding"`
+}
+
+func (q *Queries) UpdateExpenseReceivable(ctx context.Context, arg UpdateExpenseReceivableParams) error {
+	_, err := q.db.ExecContext(ctx, updateExpenseReceivable,
+		arg.TransactionID,
+		arg.ReceivableAccountID,
+		arg.Counterparty,
+		arg.Expected,
+		arg.Outstanding,
+	)
+	return err
+}
+
+const updateExpenseReceived = `-- name: UpdateExpenseReceived :exec
+UPDATE expenses
+SET received = received + $1::DECIMAL, outstanding = $2::DECIMAL
+WHERE transaction_id = $3
+`
+
+type UpdateExpenseReceivedParams struct {
+	Amount        string    `json:"amount"`
+	Outstanding   string    `json:"outstanding"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+}
+
+func (q *Queries) UpdateExpenseReceived(ctx context.Context, arg UpdateExpenseReceivedParams) error {
+	_, err := q.db.ExecContext(ctx, updateExpenseReceived, arg.Amount, arg.Outstanding, arg.TransactionID)
+	return err
+}
+
+const voidExpense = `-- name: VoidExpense :exec
+UPDATE expenses
+SET voided_at = $2, outstanding = 0
+WHERE transaction_id = $1
+`
+
+type VoidExpenseParams struct {
+	TransactionID uuid.UUID    `json:"transaction_id"`
+	VoidedAt      sql.NullTime `json:"voided_at"`
+}
+
+func (q *Queries) VoidExpense(ctx context.Context, arg VoidExpenseParams) error {
+	_, err := q.db.ExecContext(ctx, voidExpense, arg.TransactionID, arg.VoidedAt)
+	return err
+}
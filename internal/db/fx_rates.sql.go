@@ -0,0 +1,122 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: fx_rates.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const listFXRates = `-- name: ListFXRates :many
+SELECT base, quote, date, rate
+FROM fx_rates
+WHERE base = $1 AND quote = $2 AND date >= $3 AND date <= $4
+ORDER BY date
+`
+
+type ListFXRatesParams struct {
+	Base     string    `json:"base"`
+	Quote    string    `json:"quote"`
+	FromDate time.Time `json:"from_date"`
+	ToDate   time.Time `json:"to_date"`
+}
+
+func (q *Queries) ListFXRates(ctx context.Context, arg ListFXRatesParams) ([]FxRate, error) {
+	rows, err := q.db.QueryContext(ctx, listFXRates,
+		arg.Base,
+		arg.Quote,
+		arg.FromDate,
+		arg.ToDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FxRate
+	for rows.Next() {
+		var i FxRate
+		if err := rows.Scan(
+			&i.Base,
+			&i.Quote,
+			&i.Date,
+			&i.Rate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLatestFXRates = `-- name: ListLatestFXRates :many
+SELECT DISTINCT ON (base, quote) base, quote, date, rate
+FROM fx_rates
+WHERE (base = $1 OR quote = $1) AND date <= $2
+ORDER BY base, quote, date DESC
+`
+
+type ListLatestFXRatesParams struct {
+	Currency string    `json:"currency"`
+	OnDate   time.Time `json:"on_date"`
+}
+
+func (q *Queries) ListLatestFXRates(ctx context.Context, arg ListLatestFXRatesParams) ([]FxRate, error) {
+	rows, err := q.db.QueryContext(ctx, listLatestFXRates, arg.Currency, arg.OnDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FxRate
+	for rows.Next() {
+		var i FxRate
+		if err := rows.Scan(
+			&i.Base,
+			&i.Quote,
+			&i.Date,
+			&i.Rate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertFXRate = `-- name: UpsertFXRate :exec
+INSERT INTO fx_rates (base, quote, date, rate)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (base, quote, date) DO UPDATE
+SET rate = EXCLUDED.rate
+`
+
+type UpsertFXRateParams struct {
+	Base  string    `json:"base"`
+	Quote string    `json:"quote"`
+	Date  time.Time `json:"date"`
+	Rate  string    `json:"rate"`
+}
+
+func (q *Queries) UpsertFXRate(ctx context.Context, arg UpsertFXRateParams) error {
+	_, err := q.db.ExecContext(ctx, upsertFXRate,
+		arg.Base,
+		arg.Quote,
+		arg.Date,
+		arg.Rate,
+	)
+	return err
+}
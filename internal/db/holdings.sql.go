@@ -0,0 +1,313 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: holdings.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createHoldingLot = `-- name: CreateHoldingLot :exec
+INSERT INTO holding_lots (account_id, ticker, transaction_id, units, price, acquired_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type CreateHoldingLotParams struct {
+	AccountID     uuid.UUID `json:"account_id"`
+	Ticker        string    `json:"ticker"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Units         string    `json:"units"`
+	Price         string    `json:"price"`
+	AcquiredAt    time.Time `json:"acquired_at"`
+}
+
+func (q *Queries) CreateHoldingLot(ctx context.Context, arg CreateHoldingLotParams) error {
+	_, err := q.db.ExecContext(ctx, createHoldingLot,
+		arg.AccountID,
+		arg.Ticker,
+		arg.TransactionID,
+		arg.Units,
+		arg.Price,
+		arg.AcquiredAt,
+	)
+	return err
+}
+
+const createHoldingTrade = `-- name: CreateHoldingTrade :exec
+INSERT INTO holding_trades (transaction_id, account_id, ticker, kind, units, price, ratio, currency, happened_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (transaction_id) DO NOTHING
+`
+
+type CreateHoldingTradeParams struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	AccountID     uuid.UUID `json:"account_id"`
+	Ticker        string    `json:"ticker"`
+	Kind          string    `json:"kind"`
+	Units         string    `json:"units"`
+	Price         string    `json:"price"`
+	Ratio         string    `json:"ratio"`
+	Currency      string    `json:"currency"`
+	HappenedAt    time.Time `json:"happened_at"`
+}
+
+func (q *Queries) CreateHoldingTrade(ctx context.Context, arg CreateHoldingTradeParams) error {
+	_, err := q.db.ExecContext(ctx, createHoldingTrade,
+		arg.TransactionID,
+		arg.AccountID,
+		arg.Ticker,
+		arg.Kind,
+		arg.Units,
+		arg.Price,
+		arg.Ratio,
+		arg.Currency,
+		arg.HappenedAt,
+	)
+	return err
+}
+
+const deleteHoldingLots = `-- name: DeleteHoldingLots :exec
+DELETE FROM holding_lots
+WHERE account_id = $1 AND ticker = $2
+`
+
+type DeleteHoldingLotsParams struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Ticker    string    `json:"ticker"`
+}
+
+func (q *Queries) DeleteHoldingLots(ctx context.Context, arg DeleteHoldingLotsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteHoldingLots, arg.AccountID, arg.Ticker)
+	return err
+}
+
+const getHolding = `-- name: GetHolding :one
+SELECT account_id, ticker, currency, units, cost_basis, average_cost, realized_gain
+FROM holdings
+WHERE account_id = $1 AND ticker = $2
+`
+
+type GetHoldingParams struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Ticker    string    `json:"ticker"`
+}
+
+func (q *Queries) GetHolding(ctx context.Context, arg GetHoldingParams) (Holding, error) {
+	row := q.db.QueryRowContext(ctx, getHolding, arg.AccountID, arg.Ticker)
+	var i Holding
+	err := row.Scan(
+		&i.AccountID,
+		&i.Ticker,
+		&i.Currency,
+		&i.Units,
+		&i.CostBasis,
+		&i.AverageCost,
+		&i.RealizedGain,
+	)
+	return i, err
+}
+
+const listHoldingLots = `-- name: ListHoldingLots :many
+SELECT transaction_id, units, price, acquired_at
+FROM holding_lots
+WHERE account_id = $1 AND ticker = $2
+ORDER BY acquired_at, transaction_id
+`
+
+type ListHoldingLotsParams struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Ticker    string    `json:"ticker"`
+}
+
+type ListHoldingLotsRow struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Units         string    `json:"units"`
+	Price         string    `json:"price"`
+	AcquiredAt    time.Time `json:"acquired_at"`
+}
+
+func (q *Queries) ListHoldingLots(ctx context.Context, arg ListHoldingLotsParams) ([]ListHoldingLotsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listHoldingLots, arg.AccountID, arg.Ticker)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListHoldingLotsRow
+	for rows.Next() {
+		var i ListHoldingLotsRow
+		if err := rows.Scan(
+			&i.TransactionID,
+			&i.Units,
+			&i.Price,
+			&i.AcquiredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHoldingTrades = `-- name: ListHoldingTrades :many
+SELECT transaction_id, account_id, ticker, kind, units, price, ratio, currency, happened_at
+FROM holding_trades
+WHERE account_id = $1 AND ticker = $2
+ORDER BY happened_at, transaction_id
+`
+
+type ListHoldingTradesParams struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Ticker    string    `json:"ticker"`
+}
+
+func (q *Queries) ListHoldingTrades(ctx context.Context, arg ListHoldingTradesParams) ([]HoldingTrade, error) {
+	rows, err := q.db.QueryContext(ctx, listHoldingTrades, arg.AccountID, arg.Ticker)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HoldingTrade
+	for rows.Next() {
+		var i HoldingTrade
+		if err := rows.Scan(
+			&i.TransactionID,
+			&i.AccountID,
+			&i.Ticker,
+			&i.Kind,
+			&i.Units,
+			&i.Price,
+			&i.Ratio,
+			&i.Currency,
+			&i.HappenedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHoldings = `-- name: ListHoldings :many
+SELECT account_id, ticker, currency, units, cost_basis, average_cost, realized_gain
+FROM holdings
+ORDER BY account_id, ticker
+`
+
+func (q *Queries) ListHoldings(ctx context.Context) ([]Holding, error) {
+	rows, err := q.db.QueryContext(ctx, listHoldings)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Holding
+	for rows.Next() {
+		var i Holding
+		if err := rows.Scan(
+			&i.AccountID,
+			&i.Ticker,
+			&i.Currency,
+			&i.Units,
+			&i.CostBasis,
+			&i.AverageCost,
+			&i.RealizedGain,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHoldingsByAccount = `-- name: ListHoldingsByAccount :many
+SELECT account_id, ticker, currency, units, cost_basis, average_cost, realized_gain
+FROM holdings
+WHERE account_id = $1
+ORDER BY ticker
+`
+
+func (q *Queries) ListHoldingsByAccount(ctx context.Context, accountID uuid.UUID) ([]Holding, error) {
+	rows, err := q.db.QueryContext(ctx, listHoldingsByAccount, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Holding
+	for rows.Next() {
+		var i Holding
+		if err := rows.Scan(
+			&i.AccountID,
+			&i.Ticker,
+			&i.Currency,
+			&i.Units,
+			&i.CostBasis,
+			&i.AverageCost,
+			&i.RealizedGain,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertHolding = `-- name: UpsertHolding :exec
+INSERT INTO holdings (account_id, ticker, currency, units, cost_basis, average_cost, realized_gain)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (account_id, ticker) DO UPDATE
+SET units = EXCLUDED.units,
+    cost_basis = EXCLUDED.cost_basis,
+    average_cost = EXCLUDED.average_cost,
+    realized_gain = EXCLUDED.realized_gain
+`
+
+type UpsertHoldingParams struct {
+	AccountID    uuid.UUID `json:"account_id"`
+	Ticker       string    `json:"ticker"`
+	Currency     string    `json:"currency"`
+	Units        string    `json:"units"`
+	CostBasis    string    `json:"cost_basis"`
+	AverageCost  string    `json:"average_cost"`
+	RealizedGain string    `json:"realized_gain"`
+}
+
+func (q *Queries) UpsertHolding(ctx context.Context, arg UpsertHoldingParams) error {
+	_, err := q.db.ExecContext(ctx, upsertHolding,
+		arg.AccountID,
+		arg.Ticker,
+		arg.Currency,
+		arg.Units,
+		arg.CostBasis,
+		arg.AverageCost,
+		arg.RealizedGain,
+	)
+	return err
+}
@@ -0,0 +1,97 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: idempotency_keys.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status_code = $2, headers = $3, body = $4
+WHERE key = $1
+`
+
+type CompleteIdempotencyKeyParams struct {
+	Key        string          `json:"key"`
+	StatusCode sql.NullInt32   `json:"status_code"`
+	Headers    json.RawMessage `json:"headers"`
+	Body       []byte          `json:"body"`
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, completeIdempotencyKey,
+		arg.Key,
+		arg.StatusCode,
+		arg.Headers,
+		arg.Body,
+	)
+	return err
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE key = $1
+`
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKey, key)
+	return err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT key, fingerprint, status_code, headers, body, created_at FROM idempotency_keys
+WHERE key = $1
+`
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, key string) (IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, getIdempotencyKey, key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.Fingerprint,
+		&i.StatusCode,
+		&i.Headers,
+		&i.Body,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const reserveIdempotencyKey = `-- name: ReserveIdempotencyKey :execrows
+INSERT INTO idempotency_keys (key, fingerprint, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (key) DO UPDATE SET
+    fingerprint = EXCLUDED.fingerprint,
+    status_code = NULL,
+    headers = '{}',
+    body = NULL,
+    created_at = EXCLUDED.created_at
+WHERE idempotency_keys.created_at < $4
+`
+
+type ReserveIdempotencyKeyParams struct {
+	Key           string    `json:"key"`
+	Fingerprint   string    `json:"fingerprint"`
+	CreatedAt     time.Time `json:"created_at"`
+	ExpiredBefore time.Time `json:"expired_before"`
+}
+
+func (q *Queries) ReserveIdempotencyKey(ctx context.Context, arg ReserveIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reserveIdempotencyKey,
+		arg.Key,
+		arg.Fingerprint,
+		arg.CreatedAt,
+		arg.ExpiredBefore,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,20 @@
+-- The projections and the transfer handler used to be fed by the outbox relay,
+-- so they have already seen every event that is no longer in the outbox.
+INSERT INTO subscription_checkpoints (name, aggregate_type, position)
+SELECT subscriptions.name, types.aggregate_type, COALESCE(
+    (SELECT MIN(o.position) - 1 FROM outbox_events o WHERE o.aggregate_type = types.aggregate_type),
+    (SELECT MAX(e.position) FROM events e WHERE e.aggregate_type = types.aggregate_type)
+)
+FROM (VALUES ('accounts_projection'), ('balance_updates_projection'), ('transactions_projection')) AS subscriptions (name)
+CROSS JOIN (SELECT DISTINCT aggregate_type FROM events) AS types
+ON CONFLICT (name, aggregate_type) DO NOTHING;
+
+INSERT INTO subscription_checkpoints (name, aggregate_type, position)
+SELECT 'manage_accounts_transfers', 'Transaction', COALESCE(
+    (SELECT MIN(o.position) - 1 FROM outbox_events o WHERE o.aggregate_type = 'Transaction'),
+    MAX(e.position)
+)
+FROM events e
+WHERE e.aggregate_type = 'Transaction'
+HAVING COUNT(*) > 0
+ON CONFLICT (name, aggregate_type) DO NOTHING;
@@ -0,0 +1,22 @@
+ALTER TABLE transactions ADD COLUMN transaction_id UUID;
+
+CREATE INDEX idx_transactions_transaction_id ON transactions (transaction_id);
+
+-- Rows are identified by the name-based (version 3, OID namespace) UUID of the
+-- event they were projected from, so the rows projected so far can be traced
+-- back to their transaction. Account movements keep no transaction.
+UPDATE transactions t
+SET transaction_id = projected.aggregate_id
+FROM (
+    SELECT e.aggregate_id, decode(md5(
+        decode('6ba7b8129dad11d180b400c04fd430c8', 'hex') ||
+        convert_to('Transaction_' || e.aggregate_id || '_' || e.version || suffixes.suffix, 'UTF8')
+    ), 'hex') AS digest
+    FROM events e
+    CROSS JOIN (VALUES (''), ('_source'), ('_destination'), ('_fee')) AS suffixes (suffix)
+    WHERE e.aggregate_type = 'Transaction'
+) AS projected
+WHERE t.id = encode(
+    set_byte(set_byte(projected.digest, 6, (get_byte(projected.digest, 6) & 15) | 48), 8, (get_byte(projected.digest, 8) & 63) | 128),
+    'hex'
+)::UUID;
@@ -0,0 +1 @@
+ALTER TABLE accounts_projection ADD COLUMN expected_reimbursements JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,15 @@
+CREATE TABLE expenses (
+    transaction_id UUID PRIMARY KEY,
+    account_id UUID NOT NULL,
+    currency TEXT NOT NULL,
+    cost DECIMAL NOT NULL,
+    receivable_account_id UUID,
+    counterparty TEXT NOT NULL DEFAULT '',
+    expected DECIMAL NOT NULL DEFAULT 0,
+    received DECIMAL NOT NULL DEFAULT 0,
+    outstanding DECIMAL NOT NULL DEFAULT 0,
+    happened_at TIMESTAMP NOT NULL,
+    voided_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_expenses_outstanding ON expenses (receivable_account_id, counterparty) WHERE outstanding > 0;
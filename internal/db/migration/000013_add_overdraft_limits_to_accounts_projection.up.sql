@@ -0,0 +1 @@
+ALTER TABLE accounts_projection ADD COLUMN overdraft_limits JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,8 @@
+ALTER TABLE accounts_projection
+    ADD COLUMN kind TEXT NOT NULL DEFAULT '',
+    ADD COLUMN institution TEXT NOT NULL DEFAULT '',
+    ADD COLUMN account_number TEXT NOT NULL DEFAULT '',
+    ADD COLUMN owner TEXT NOT NULL DEFAULT '',
+    ADD COLUMN icon TEXT NOT NULL DEFAULT '',
+    ADD COLUMN color TEXT NOT NULL DEFAULT '',
+    ADD COLUMN tags JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,24 @@
+-- The movements of every account are kept, as an account can be made a credit
+-- card after its first movements were projected.
+CREATE TABLE card_movements (
+    transaction_id UUID NOT NULL,
+    account_id UUID NOT NULL,
+    currency TEXT NOT NULL,
+    amount DECIMAL NOT NULL,
+    is_payment BOOLEAN NOT NULL DEFAULT FALSE,
+    happened_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (transaction_id, account_id)
+);
+
+CREATE INDEX idx_card_movements_account ON card_movements (account_id, currency, happened_at);
+
+CREATE TABLE statements (
+    account_id UUID NOT NULL,
+    closing_date DATE NOT NULL,
+    currency TEXT NOT NULL,
+    period_start DATE,
+    due_date DATE NOT NULL,
+    balance DECIMAL NOT NULL,
+    minimum_due DECIMAL NOT NULL,
+    PRIMARY KEY (account_id, closing_date)
+);
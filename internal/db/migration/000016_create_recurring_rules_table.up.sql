@@ -0,0 +1,14 @@
+CREATE TABLE recurring_rules (
+    id UUID PRIMARY KEY,
+    kind TEXT NOT NULL,
+    account_id UUID NOT NULL,
+    currency TEXT NOT NULL,
+    amount DECIMAL NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    frequency TEXT NOT NULL,
+    "interval" INTEGER NOT NULL,
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP,
+    finished BOOLEAN NOT NULL DEFAULT FALSE
+);
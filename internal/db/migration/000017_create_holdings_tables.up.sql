@@ -0,0 +1,20 @@
+CREATE TABLE holdings (
+    account_id UUID NOT NULL,
+    ticker TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    units DECIMAL NOT NULL,
+    cost_basis DECIMAL NOT NULL,
+    average_cost DECIMAL NOT NULL,
+    realized_gain DECIMAL NOT NULL,
+    PRIMARY KEY (account_id, ticker)
+);
+
+CREATE TABLE holding_lots (
+    account_id UUID NOT NULL,
+    ticker TEXT NOT NULL,
+    transaction_id UUID NOT NULL,
+    units DECIMAL NOT NULL,
+    price DECIMAL NOT NULL,
+    acquired_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (account_id, ticker, transaction_id)
+);
@@ -0,0 +1,20 @@
+CREATE TABLE holding_trades (
+    transaction_id UUID PRIMARY KEY,
+    account_id UUID NOT NULL,
+    ticker TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    units DECIMAL NOT NULL,
+    price DECIMAL NOT NULL,
+    ratio DECIMAL NOT NULL,
+    currency TEXT NOT NULL,
+    happened_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_holding_trades_holding ON holding_trades (account_id, ticker, happened_at);
+
+-- The trades made so far are only in the events, so the holdings projection
+-- is replayed from the start to record them.
+TRUNCATE holdings, holding_lots;
+
+DELETE FROM subscription_checkpoints WHERE name = 'holdings_projection';
+DELETE FROM dead_letter_events WHERE subscription = 'holdings_projection';
@@ -0,0 +1,7 @@
+CREATE TABLE prices (
+    ticker TEXT NOT NULL,
+    date DATE NOT NULL,
+    price DECIMAL NOT NULL,
+    currency TEXT NOT NULL,
+    PRIMARY KEY (ticker, date)
+);
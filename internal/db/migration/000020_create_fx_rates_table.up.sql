@@ -0,0 +1,7 @@
+CREATE TABLE fx_rates (
+    base TEXT NOT NULL,
+    quote TEXT NOT NULL,
+    date DATE NOT NULL,
+    rate DECIMAL NOT NULL,
+    PRIMARY KEY (base, quote, date)
+);
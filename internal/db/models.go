@@ -13,11 +13,20 @@ import (
 )
 
 type AccountsProjection struct {
-	ID        uuid.UUID       `json:"id"`
-	Balance   json.RawMessage `json:"balance"`
-	CreatedAt sql.NullTime    `json:"created_at"`
-	ClosedAt  sql.NullTime    `json:"closed_at"`
-	Name      string          `json:"name"`
+	ID                     uuid.UUID       `json:"id"`
+	Balance                json.RawMessage `json:"balance"`
+	CreatedAt              sql.NullTime    `json:"created_at"`
+	ClosedAt               sql.NullTime    `json:"closed_at"`
+	Name                   string          `json:"name"`
+	ExpectedReimbursements json.RawMessage `json:"expected_reimbursements"`
+	OverdraftLimits        json.RawMessage `json:"overdraft_limits"`
+	Kind                   string          `json:"kind"`
+	Institution            string          `json:"institution"`
+	AccountNumber          string          `json:"account_number"`
+	Owner                  string          `json:"owner"`
+	Icon                   string          `json:"icon"`
+	Color                  string          `json:"color"`
+	Tags                   json.RawMessage `json:"tags"`
 }
 
 type BalanceUpdate struct {
@@ -40,14 +49,119 @@ type Budget struct {
 	UpdatedAt sql.NullTime    `json:"updated_at"`
 }
 
+type CardMovement struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	AccountID     uuid.UUID `json:"account_id"`
+	Currency      string    `json:"currency"`
+	Amount        string    `json:"amount"`
+	IsPayment     bool      `json:"is_payment"`
+	HappenedAt    time.Time `json:"happened_at"`
+}
+
+type Expense struct {
+	TransactionID       uuid.UUID     `json:"transaction_id"`
+	AccountID           uuid.UUID     `json:"account_id"`
+	Currency            string        `json:"currency"`
+	Cost                string        `json:"cost"`
+	ReceivableAccountID uuid.NullUUID `json:"receivable_account_id"`
+	Counterparty        string        `json:"counterparty"`
+	Expected            string        `json:"expected"`
+	Received            string        `json:"received"`
+	Outstanding         string        `json:"outstanding"`
+	HappenedAt          time.Time     `json:"happened_at"`
+	VoidedAt            sql.NullTime  `json:"voided_at"`
+}
+
+type FxRate struct {
+	Base  string    `json:"base"`
+	Quote string    `json:"quote"`
+	Date  time.Time `json:"date"`
+	Rate  string    `json:"rate"`
+}
+
+type Holding struct {
+	AccountID    uuid.UUID `json:"account_id"`
+	Ticker       string    `json:"ticker"`
+	Currency     string    `json:"currency"`
+	Units        string    `json:"units"`
+	CostBasis    string    `json:"cost_basis"`
+	AverageCost  string    `json:"average_cost"`
+	RealizedGain string    `json:"realized_gain"`
+}
+
+type HoldingLot struct {
+	AccountID     uuid.UUID `json:"account_id"`
+	Ticker        string    `json:"ticker"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Units         string    `json:"units"`
+	Price         string    `json:"price"`
+	AcquiredAt    time.Time `json:"acquired_at"`
+}
+
+type HoldingTrade struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	AccountID     uuid.UUID `json:"account_id"`
+	Ticker        string    `json:"ticker"`
+	Kind          string    `json:"kind"`
+	Units         string    `json:"units"`
+	Price         string    `json:"price"`
+	Ratio         string    `json:"ratio"`
+	Currency      string    `json:"currency"`
+	HappenedAt    time.Time `json:"happened_at"`
+}
+
+type IdempotencyKey struct {
+	Key         string          `json:"key"`
+	Fingerprint string          `json:"fingerprint"`
+	StatusCode  sql.NullInt32   `json:"status_code"`
+	Headers     json.RawMessage `json:"headers"`
+	Body        []byte          `json:"body"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type Price struct {
+	Ticker   string    `json:"ticker"`
+	Date     time.Time `json:"date"`
+	Price    string    `json:"price"`
+	Currency string    `json:"currency"`
+}
+
+type RecurringRule struct {
+	ID          uuid.UUID    `json:"id"`
+	Kind        string       `json:"kind"`
+	AccountID   uuid.UUID    `json:"account_id"`
+	Currency    string       `json:"currency"`
+	Amount      string       `json:"amount"`
+	Category    string       `json:"category"`
+	Description string       `json:"description"`
+	Frequency   string       `json:"frequency"`
+	Interval    int32        `json:"interval"`
+	StartDate   time.Time    `json:"start_date"`
+	EndDate     sql.NullTime `json:"end_date"`
+	Finished    bool         `json:"finished"`
+}
+
+type Statement struct {
+	AccountID   uuid.UUID    `json:"account_id"`
+	ClosingDate time.Time    `json:"closing_date"`
+	Currency    string       `json:"currency"`
+	PeriodStart sql.NullTime `json:"period_start"`
+	DueDate     time.Time    `json:"due_date"`
+	Balance     string       `json:"balance"`
+	MinimumDue  string       `json:"minimum_due"`
+}
+
 type Transaction struct {
-	ID              uuid.UUID `json:"id"`
-	AccountID       uuid.UUID `json:"account_id"`
-	TransactionType string    `json:"transaction_type"`
-	Amount          string    `json:"amount"`
-	Currency        string    `json:"currency"`
-	Category        string    `json:"category"`
-	Description     string    `json:"description"`
-	HappenedAt      time.Time `json:"happened_at"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID              uuid.UUID      `json:"id"`
+	AccountID       uuid.UUID      `json:"account_id"`
+	TransactionType string         `json:"transaction_type"`
+	Amount          string         `json:"amount"`
+	Currency        string         `json:"currency"`
+	Category        string         `json:"category"`
+	Description     string         `json:"description"`
+	HappenedAt      time.Time      `json:"happened_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+	TransactionID   uuid.NullUUID  `json:"transaction_id"`
+	VoidedAt        sql.NullTime   `json:"voided_at"`
+	ExchangeRate    sql.NullString `json:"exchange_rate"`
 }
@@ -0,0 +1,102 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: prices.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const getLatestPrice = `-- name: GetLatestPrice :one
+SELECT ticker, date, price, currency
+FROM prices
+WHERE ticker = $1 AND date <= $2
+ORDER BY date DESC
+LIMIT 1
+`
+
+type GetLatestPriceParams struct {
+	Ticker string    `json:"ticker"`
+	OnDate time.Time `json:"on_date"`
+}
+
+func (q *Queries) GetLatestPrice(ctx context.Context, arg GetLatestPriceParams) (Price, error) {
+	row := q.db.QueryRowContext(ctx, getLatestPrice, arg.Ticker, arg.OnDate)
+	var i Price
+	err := row.Scan(
+		&i.Ticker,
+		&i.Date,
+		&i.Price,
+		&i.Currency,
+	)
+	return i, err
+}
+
+const listPrices = `-- name: ListPrices :many
+SELECT ticker, date, price, currency
+FROM prices
+WHERE ticker = $1 AND date >= $2 AND date <= $3
+ORDER BY date
+`
+
+type ListPricesParams struct {
+	Ticker   string    `json:"ticker"`
+	FromDate time.Time `json:"from_date"`
+	ToDate   time.Time `json:"to_date"`
+}
+
+func (q *Queries) ListPrices(ctx context.Context, arg ListPricesParams) ([]Price, error) {
+	rows, err := q.db.QueryContext(ctx, listPrices, arg.Ticker, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Price
+	for rows.Next() {
+		var i Price
+		if err := rows.Scan(
+			&i.Ticker,
+			&i.Date,
+			&i.Price,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertPrice = `-- name: UpsertPrice :exec
+INSERT INTO prices (ticker, date, price, currency)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (ticker, date) DO UPDATE
+SET price = EXCLUDED.price,
+    currency = EXCLUDED.currency
+`
+
+type UpsertPriceParams struct {
+	Ticker   string    `json:"ticker"`
+	Date     time.Time `json:"date"`
+	Price    string    `json:"price"`
+	Currency string    `json:"currency"`
+}
+
+func (q *Queries) UpsertPrice(ctx context.Context, arg UpsertPriceParams) error {
+	_, err := q.db.ExecContext(ctx, upsertPrice,
+		arg.Ticker,
+		arg.Date,
+		arg.Price,
+		arg.Currency,
+	)
+	return err
+}
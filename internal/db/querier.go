@@ -13,24 +13,76 @@ import (
 
 type Querier interface {
 	CloseAccount(ctx context.Context, arg CloseAccountParams) error
+	CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error
 	CreateAccount(ctx context.Context, arg CreateAccountParams) error
 	CreateBudget(ctx context.Context, arg CreateBudgetParams) error
+	CreateCardMovement(ctx context.Context, arg CreateCardMovementParams) error
+	CreateExpense(ctx context.Context, arg CreateExpenseParams) error
+	CreateHoldingLot(ctx context.Context, arg CreateHoldingLotParams) error
+	CreateHoldingTrade(ctx context.Context, arg CreateHoldingTradeParams) error
+	CreateRecurringRule(ctx context.Context, arg CreateRecurringRuleParams) error
+	CreateStatement(ctx context.Context, arg CreateStatementParams) error
 	CreateTransaction(ctx context.Context, arg CreateTransactionParams) error
 	DeleteBudget(ctx context.Context, id uuid.UUID) error
+	DeleteCardMovements(ctx context.Context, transactionID uuid.UUID) error
+	DeleteHoldingLots(ctx context.Context, arg DeleteHoldingLotsParams) error
+	DeleteIdempotencyKey(ctx context.Context, key string) error
+	FinishRecurringRule(ctx context.Context, id uuid.UUID) error
 	GetAccountBalanceForUpdate(ctx context.Context, id uuid.UUID) (json.RawMessage, error)
 	GetAccountDistributions(ctx context.Context, arg GetAccountDistributionsParams) ([]GetAccountDistributionsRow, error)
+	GetAccountExpectedReimbursementsForUpdate(ctx context.Context, id uuid.UUID) (json.RawMessage, error)
+	GetAccountOverdraftLimitsForUpdate(ctx context.Context, id uuid.UUID) (json.RawMessage, error)
 	GetAllAccounts(ctx context.Context) ([]GetAllAccountsRow, error)
 	GetAllBalances(ctx context.Context, balanceType string) ([]GetAllBalancesRow, error)
 	GetBalancesByAccount(ctx context.Context, arg GetBalancesByAccountParams) ([]GetBalancesByAccountRow, error)
 	GetBudgetByID(ctx context.Context, id uuid.UUID) (Budget, error)
 	GetBudgets(ctx context.Context) ([]Budget, error)
+	GetCardBalanceBefore(ctx context.Context, arg GetCardBalanceBeforeParams) (string, error)
+	GetExpense(ctx context.Context, transactionID uuid.UUID) (GetExpenseRow, error)
+	GetHolding(ctx context.Context, arg GetHoldingParams) (Holding, error)
+	GetIdempotencyKey(ctx context.Context, key string) (IdempotencyKey, error)
+	GetLatestPrice(ctx context.Context, arg GetLatestPriceParams) (Price, error)
 	InsertBalanceUpdate(ctx context.Context, arg InsertBalanceUpdateParams) error
 	ListCategories(ctx context.Context) ([]string, error)
+	ListFXRates(ctx context.Context, arg ListFXRatesParams) ([]FxRate, error)
+	ListHoldingLots(ctx context.Context, arg ListHoldingLotsParams) ([]ListHoldingLotsRow, error)
+	ListHoldingTrades(ctx context.Context, arg ListHoldingTradesParams) ([]HoldingTrade, error)
+	ListHoldings(ctx context.Context) ([]Holding, error)
+	ListHoldingsByAccount(ctx context.Context, accountID uuid.UUID) ([]Holding, error)
+	ListLatestFXRates(ctx context.Context, arg ListLatestFXRatesParams) ([]FxRate, error)
+	ListOutstandingByAccount(ctx context.Context) ([]ListOutstandingByAccountRow, error)
+	ListOutstandingByCounterparty(ctx context.Context) ([]ListOutstandingByCounterpartyRow, error)
+	ListPrices(ctx context.Context, arg ListPricesParams) ([]Price, error)
+	ListRecurringRules(ctx context.Context, activeOnly bool) ([]RecurringRule, error)
+	ListStatements(ctx context.Context, accountID uuid.UUID) ([]ListStatementsRow, error)
 	ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]ListTransactionsRow, error)
 	ListTransactionsPaginated(ctx context.Context, arg ListTransactionsPaginatedParams) ([]ListTransactionsPaginatedRow, error)
+	ReopenAccount(ctx context.Context, id uuid.UUID) error
+	ReserveIdempotencyKey(ctx context.Context, arg ReserveIdempotencyKeyParams) (int64, error)
 	UpdateAccountBalance(ctx context.Context, arg UpdateAccountBalanceParams) error
+	UpdateAccountDetails(ctx context.Context, arg UpdateAccountDetailsParams) error
+	UpdateAccountExpectedReimbursements(ctx context.Context, arg UpdateAccountExpectedReimbursementsParams) error
+	UpdateAccountKind(ctx context.Context, arg UpdateAccountKindParams) error
 	UpdateAccountName(ctx context.Context, arg UpdateAccountNameParams) error
+	UpdateAccountOverdraftLimits(ctx context.Context, arg UpdateAccountOverdraftLimitsParams) error
+	UpdateCardMovementAmount(ctx context.Context, arg UpdateCardMovementAmountParams) error
+	UpdateCardMovementHappenedAt(ctx context.Context, arg UpdateCardMovementHappenedAtParams) error
+	UpdateExpenseCost(ctx context.Context, arg UpdateExpenseCostParams) error
+	UpdateExpenseHappenedAt(ctx context.Context, arg UpdateExpenseHappenedAtParams) error
+	UpdateExpenseReceivable(ctx context.Context, arg UpdateExpenseReceivableParams) error
+	UpdateExpenseReceived(ctx context.Context, arg UpdateExpenseReceivedParams) error
+	UpdateTransactionAmount(ctx context.Context, arg UpdateTransactionAmountParams) error
+	// The FX cost of a transfer, the only expense with an exchange rate, keeps its
+	// category.
+	UpdateTransactionCategory(ctx context.Context, arg UpdateTransactionCategoryParams) error
+	UpdateTransactionDescription(ctx context.Context, arg UpdateTransactionDescriptionParams) error
+	UpdateTransactionHappenedAt(ctx context.Context, arg UpdateTransactionHappenedAtParams) error
+	UpsertFXRate(ctx context.Context, arg UpsertFXRateParams) error
+	UpsertHolding(ctx context.Context, arg UpsertHoldingParams) error
 	UpsertPlaceholderAccount(ctx context.Context, arg UpsertPlaceholderAccountParams) error
+	UpsertPrice(ctx context.Context, arg UpsertPriceParams) error
+	VoidExpense(ctx context.Context, arg VoidExpenseParams) error
+	VoidTransaction(ctx context.Context, arg VoidTransactionParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -8,6 +8,11 @@ UPDATE accounts_projection
 SET closed_at = $2
 WHERE id = $1;
 
+-- name: ReopenAccount :exec
+UPDATE accounts_projection
+SET closed_at = NULL
+WHERE id = $1;
+
 -- name: GetAccountBalanceForUpdate :one
 SELECT balance 
 FROM accounts_projection 
@@ -18,8 +23,28 @@ UPDATE accounts_projection
 SET balance = $2 
 WHERE id = $1;
 
+-- name: GetAccountExpectedReimbursementsForUpdate :one
+SELECT expected_reimbursements
+FROM accounts_projection
+WHERE id = $1 FOR UPDATE;
+
+-- name: UpdateAccountExpectedReimbursements :exec
+UPDATE accounts_projection
+SET expected_reimbursements = $2
+WHERE id = $1;
+
+-- name: GetAccountOverdraftLimitsForUpdate :one
+SELECT overdraft_limits
+FROM accounts_projection
+WHERE id = $1 FOR UPDATE;
+
+-- name: UpdateAccountOverdraftLimits :exec
+UPDATE accounts_projection
+SET overdraft_limits = $2
+WHERE id = $1;
+
 -- name: GetAllAccounts :many
-SELECT id, name, balance, created_at, closed_at 
+SELECT id, name, kind, institution, account_number, owner, icon, color, tags, balance, expected_reimbursements, overdraft_limits, created_at, closed_at 
 FROM accounts_projection;
 
 -- name: UpsertPlaceholderAccount :exec
@@ -31,3 +56,13 @@ ON CONFLICT (id) DO NOTHING;
 UPDATE accounts_projection
 SET name = $2
 WHERE id = $1;
+
+-- name: UpdateAccountKind :exec
+UPDATE accounts_projection
+SET kind = $2
+WHERE id = $1;
+
+-- name: UpdateAccountDetails :exec
+UPDATE accounts_projection
+SET institution = $2, account_number = $3, owner = $4, icon = $5, color = $6, tags = $7
+WHERE id = $1;
@@ -0,0 +1,48 @@
+-- name: CreateExpense :exec
+INSERT INTO expenses (transaction_id, account_id, currency, cost, happened_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (transaction_id) DO NOTHING;
+
+-- name: UpdateExpenseCost :exec
+UPDATE expenses
+SET cost = $2
+WHERE transaction_id = $1;
+
+-- name: UpdateExpenseHappenedAt :exec
+UPDATE expenses
+SET happened_at = $2
+WHERE transaction_id = $1;
+
+-- name: UpdateExpenseReceivable :exec
+UPDATE expenses
+SET receivable_account_id = $2, counterparty = $3, expected = $4, outstanding = $5
+WHERE transaction_id = $1;
+
+-- name: UpdateExpenseReceived :exec
+UPDATE expenses
+SET received = received + sqlc.arg(amount)::DECIMAL, outstanding = sqlc.arg(outstanding)::DECIMAL
+WHERE transaction_id = sqlc.arg(transaction_id);
+
+-- name: VoidExpense :exec
+UPDATE expenses
+SET voided_at = $2, outstanding = 0
+WHERE transaction_id = $1;
+
+-- name: GetExpense :one
+SELECT transaction_id, account_id, currency, cost, receivable_account_id, counterparty, expected, received, outstanding, happened_at
+FROM expenses
+WHERE transaction_id = $1 AND voided_at IS NULL;
+
+-- name: ListOutstandingByAccount :many
+SELECT receivable_account_id, currency, SUM(outstanding)::DECIMAL AS outstanding
+FROM expenses
+WHERE voided_at IS NULL AND outstanding > 0
+GROUP BY receivable_account_id, currency
+ORDER BY receivable_account_id, currency;
+
+-- name: ListOutstandingByCounterparty :many
+SELECT counterparty, currency, SUM(outstanding)::DECIMAL AS outstanding
+FROM expenses
+WHERE voided_at IS NULL AND outstanding > 0
+GROUP BY counterparty, currency
+ORDER BY counterparty, currency;
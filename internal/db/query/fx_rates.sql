@@ -0,0 +1,17 @@
+-- name: UpsertFXRate :exec
+INSERT INTO fx_rates (base, quote, date, rate)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (base, quote, date) DO UPDATE
+SET rate = EXCLUDED.rate;
+
+-- name: ListLatestFXRates :many
+SELECT DISTINCT ON (base, quote) base, quote, date, rate
+FROM fx_rates
+WHERE (base = sqlc.arg(currency) OR quote = sqlc.arg(currency)) AND date <= sqlc.arg(on_date)
+ORDER BY base, quote, date DESC;
+
+-- name: ListFXRates :many
+SELECT base, quote, date, rate
+FROM fx_rates
+WHERE base = sqlc.arg(base) AND quote = sqlc.arg(quote) AND date >= sqlc.arg(from_date) AND date <= sqlc.arg(to_date)
+ORDER BY date;
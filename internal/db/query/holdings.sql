@@ -0,0 +1,49 @@
+-- name: GetHolding :one
+SELECT account_id, ticker, currency, units, cost_basis, average_cost, realized_gain
+FROM holdings
+WHERE account_id = $1 AND ticker = $2;
+
+-- name: ListHoldings :many
+SELECT account_id, ticker, currency, units, cost_basis, average_cost, realized_gain
+FROM holdings
+ORDER BY account_id, ticker;
+
+-- name: ListHoldingsByAccount :many
+SELECT account_id, ticker, currency, units, cost_basis, average_cost, realized_gain
+FROM holdings
+WHERE account_id = $1
+ORDER BY ticker;
+
+-- name: UpsertHolding :exec
+INSERT INTO holdings (account_id, ticker, currency, units, cost_basis, average_cost, realized_gain)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (account_id, ticker) DO UPDATE
+SET units = EXCLUDED.units,
+    cost_basis = EXCLUDED.cost_basis,
+    average_cost = EXCLUDED.average_cost,
+    realized_gain = EXCLUDED.realized_gain;
+
+-- name: ListHoldingLots :many
+SELECT transaction_id, units, price, acquired_at
+FROM holding_lots
+WHERE account_id = $1 AND ticker = $2
+ORDER BY acquired_at, transaction_id;
+
+-- name: DeleteHoldingLots :exec
+DELETE FROM holding_lots
+WHERE account_id = $1 AND ticker = $2;
+
+-- name: CreateHoldingLot :exec
+INSERT INTO holding_lots (account_id, ticker, transaction_id, units, price, acquired_at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: CreateHoldingTrade :exec
+INSERT INTO holding_trades (transaction_id, account_id, ticker, kind, units, price, ratio, currency, happened_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (transaction_id) DO NOTHING;
+
+-- name: ListHoldingTrades :many
+SELECT transaction_id, account_id, ticker, kind, units, price, ratio, currency, happened_at
+FROM holding_trades
+WHERE account_id = $1 AND ticker = $2
+ORDER BY happened_at, transaction_id;
@@ -0,0 +1,23 @@
+-- name: ReserveIdempotencyKey :execrows
+INSERT INTO idempotency_keys (key, fingerprint, created_at)
+VALUES (sqlc.arg(key), sqlc.arg(fingerprint), sqlc.arg(created_at))
+ON CONFLICT (key) DO UPDATE SET
+    fingerprint = EXCLUDED.fingerprint,
+    status_code = NULL,
+    headers = '{}',
+    body = NULL,
+    created_at = EXCLUDED.created_at
+WHERE idempotency_keys.created_at < sqlc.arg(expired_before);
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE key = $1;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status_code = $2, headers = $3, body = $4
+WHERE key = $1;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE key = $1;
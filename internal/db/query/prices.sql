@@ -0,0 +1,19 @@
+-- name: UpsertPrice :exec
+INSERT INTO prices (ticker, date, price, currency)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (ticker, date) DO UPDATE
+SET price = EXCLUDED.price,
+    currency = EXCLUDED.currency;
+
+-- name: GetLatestPrice :one
+SELECT ticker, date, price, currency
+FROM prices
+WHERE ticker = sqlc.arg(ticker) AND date <= sqlc.arg(on_date)
+ORDER BY date DESC
+LIMIT 1;
+
+-- name: ListPrices :many
+SELECT ticker, date, price, currency
+FROM prices
+WHERE ticker = sqlc.arg(ticker) AND date >= sqlc.arg(from_date) AND date <= sqlc.arg(to_date)
+ORDER BY date;
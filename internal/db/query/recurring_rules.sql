@@ -0,0 +1,15 @@
+-- name: CreateRecurringRule :exec
+INSERT INTO recurring_rules (id, kind, account_id, currency, amount, category, description, frequency, "interval", start_date, end_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (id) DO NOTHING;
+
+-- name: FinishRecurringRule :exec
+UPDATE recurring_rules
+SET finished = TRUE
+WHERE id = $1;
+
+-- name: ListRecurringRules :many
+SELECT id, kind, account_id, currency, amount, category, description, frequency, "interval", start_date, end_date, finished
+FROM recurring_rules
+WHERE NOT (sqlc.arg(active_only)::BOOLEAN AND finished)
+ORDER BY start_date, id;
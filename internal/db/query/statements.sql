@@ -0,0 +1,40 @@
+-- name: CreateCardMovement :exec
+INSERT INTO card_movements (transaction_id, account_id, currency, amount, is_payment, happened_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (transaction_id, account_id) DO NOTHING;
+
+-- name: UpdateCardMovementAmount :exec
+UPDATE card_movements
+SET amount = $3
+WHERE transaction_id = $1 AND account_id = $2;
+
+-- name: UpdateCardMovementHappenedAt :exec
+UPDATE card_movements
+SET happened_at = $2
+WHERE transaction_id = $1;
+
+-- name: DeleteCardMovements :exec
+DELETE FROM card_movements
+WHERE transaction_id = $1;
+
+-- name: GetCardBalanceBefore :one
+SELECT COALESCE(SUM(amount), 0)::DECIMAL AS balance
+FROM card_movements
+WHERE account_id = sqlc.arg(account_id) AND currency = sqlc.arg(currency) AND happened_at < sqlc.arg(before);
+
+-- name: CreateStatement :exec
+INSERT INTO statements (account_id, closing_date, currency, period_start, due_date, balance, minimum_due)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (account_id, closing_date) DO NOTHING;
+
+-- name: ListStatements :many
+SELECT s.account_id, s.closing_date, s.currency, s.period_start, s.due_date, s.balance, s.minimum_due,
+    COALESCE((
+        SELECT SUM(m.amount)
+        FROM card_movements m
+        WHERE m.account_id = s.account_id AND m.currency = s.currency AND m.is_payment
+            AND m.happened_at >= s.closing_date + 1 AND m.happened_at < s.due_date + 1
+    ), 0)::DECIMAL AS paid
+FROM statements s
+WHERE s.account_id = $1
+ORDER BY s.closing_date DESC;
@@ -1,8 +1,8 @@
 -- name: CreateTransaction :exec
 INSERT INTO transactions (
-    id, account_id, transaction_type, amount, currency, category, description, happened_at
+    id, account_id, transaction_type, amount, currency, category, description, happened_at, transaction_id, exchange_rate
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 );
 
 -- name: ListTransactions :many
@@ -12,9 +12,10 @@ WITH distributions AS (
         SUM(CASE WHEN transaction_type IN ('TRANSFER') THEN amount ELSE 0 END) OVER (PARTITION BY account_id, currency ORDER BY happened_at ASC, id ASC) as system_amount,
         SUM(CASE WHEN transaction_type IN ('DEPOSIT', 'WITHDRAWAL') THEN amount ELSE 0 END) OVER (PARTITION BY account_id, currency ORDER BY happened_at ASC, id ASC) as other_amount
     FROM transactions
+    WHERE voided_at IS NULL
 )
 SELECT
-    t.id, t.account_id, t.transaction_type, t.amount, t.currency, t.category, t.description, t.happened_at, t.created_at,
+    t.id, t.transaction_id, t.account_id, t.transaction_type, t.amount, t.currency, t.category, t.description, t.happened_at, t.created_at, t.exchange_rate,
     COALESCE(CASE 
         WHEN d.system_amount + d.other_amount != 0 THEN ROUND(d.system_amount::DECIMAL / (d.system_amount + d.other_amount)::DECIMAL, 4)::TEXT ELSE '0' END, '0') as system_total_rate
 FROM transactions t
@@ -33,9 +34,10 @@ WITH distributions AS (
         SUM(CASE WHEN transaction_type IN ('TRANSFER') THEN amount ELSE 0 END) OVER (PARTITION BY account_id, currency ORDER BY happened_at ASC, id ASC) as system_amount,
         SUM(CASE WHEN transaction_type IN ('DEPOSIT', 'WITHDRAWAL') THEN amount ELSE 0 END) OVER (PARTITION BY account_id, currency ORDER BY happened_at ASC, id ASC) as other_amount
     FROM transactions
+    WHERE voided_at IS NULL
 )
 SELECT
-    t.id, t.account_id, t.transaction_type, t.amount, t.currency, t.category, t.description, t.happened_at, t.created_at,
+    t.id, t.transaction_id, t.account_id, t.transaction_type, t.amount, t.currency, t.category, t.description, t.happened_at, t.created_at, t.exchange_rate,
     COALESCE(CASE 
         WHEN d.system_amount + d.other_amount != 0 THEN ROUND(d.system_amount::DECIMAL / (d.system_amount + d.other_amount)::DECIMAL, 4)::TEXT ELSE '0' END, '0') as system_total_rate,
     COUNT(*) OVER() as total_count
@@ -49,7 +51,35 @@ WHERE
 ORDER BY t.happened_at DESC, t.id DESC
 LIMIT sqlc.arg('limit_val') OFFSET sqlc.arg('offset_val');
 
+-- name: UpdateTransactionAmount :exec
+UPDATE transactions
+SET amount = $2
+WHERE transaction_id = $1;
+
+-- name: UpdateTransactionHappenedAt :exec
+UPDATE transactions
+SET happened_at = $2
+WHERE transaction_id = $1;
+
+-- name: UpdateTransactionCategory :exec
+-- The FX cost of a transfer, the only expense with an exchange rate, keeps its
+-- category.
+UPDATE transactions
+SET category = $2
+WHERE transaction_id = $1 AND NOT (transaction_type = 'EXPENSE' AND exchange_rate IS NOT NULL);
+
+-- name: UpdateTransactionDescription :exec
+UPDATE transactions
+SET description = $2
+WHERE transaction_id = $1;
+
+-- name: VoidTransaction :exec
+UPDATE transactions
+SET voided_at = $2
+WHERE transaction_id = $1;
+
 -- name: ListCategories :many
 SELECT DISTINCT category 
 FROM transactions 
+WHERE voided_at IS NULL
 ORDER BY category;
@@ -0,0 +1,105 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: recurring_rules.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createRecurringRule = `-- name: CreateRecurringRule :exec
+INSERT INTO recurring_rules (id, kind, account_id, currency, amount, category, description, frequency, "interval", start_date, end_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (id) DO NOTHING
+`
+
+type CreateRecurringRuleParams struct {
+	ID          uuid.UUID    `json:"id"`
+	Kind        string       `json:"kind"`
+	AccountID   uuid.UUID    `json:"account_id"`
+	Currency    string       `json:"currency"`
+	Amount      string       `json:"amount"`
+	Category    string       `json:"category"`
+	Description string       `json:"description"`
+	Frequency   string       `json:"frequency"`
+	Interval    int32        `json:"interval"`
+	StartDate   time.Time    `json:"start_date"`
+	EndDate     sql.NullTime `json:"end_date"`
+}
+
+func (q *Queries) CreateRecurringRule(ctx context.Context, arg CreateRecurringRuleParams) error {
+	_, err := q.db.ExecContext(ctx, createRecurringRule,
+		arg.ID,
+		arg.Kind,
+		arg.AccountID,
+		arg.Currency,
+		arg.Amount,
+		arg.Category,
+		arg.Description,
+		arg.Frequency,
+		arg.Interval,
+		arg.StartDate,
+		arg.EndDate,
+	)
+	return err
+}
+
+const finishRecurringRule = `-- name: FinishRecurringRule :exec
+UPDATE recurring_rules
+SET finished = TRUE
+WHERE id = $1
+`
+
+func (q *Queries) FinishRecurringRule(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, finishRecurringRule, id)
+	return err
+}
+
+const listRecurringRules = `-- name: ListRecurringRules :many
+SELECT id, kind, account_id, currency, amount, category, description, frequency, "interval", start_date, end_date, finished
+FROM recurring_rules
+WHERE NOT ($1::BOOLEAN AND finished)
+ORDER BY start_date, id
+`
+
+func (q *Queries) ListRecurringRules(ctx context.Context, activeOnly bool) ([]RecurringRule, error) {
+	rows, err := q.db.QueryContext(ctx, listRecurringRules, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RecurringRule
+	for rows.Next() {
+		var i RecurringRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.AccountID,
+			&i.Currency,
+			&i.Amount,
+			&i.Category,
+			&i.Description,
+			&i.Frequency,
+			&i.Interval,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Finished,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
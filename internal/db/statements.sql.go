@@ -0,0 +1,188 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: statements.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createCardMovement = `-- name: CreateCardMovement :exec
+INSERT INTO card_movements (transaction_id, account_id, currency, amount, is_payment, happened_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (transaction_id, account_id) DO NOTHING
+`
+
+type CreateCardMovementParams struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	AccountID     uuid.UUID `json:"account_id"`
+	Currency      string    `json:"currency"`
+	Amount        string    `json:"amount"`
+	IsPayment     bool      `json:"is_payment"`
+	HappenedAt    time.Time `json:"happened_at"`
+}
+
+func (q *Queries) CreateCardMovement(ctx context.Context, arg CreateCardMovementParams) error {
+	_, err := q.db.ExecContext(ctx, createCardMovement,
+		arg.TransactionID,
+		arg.AccountID,
+		arg.Currency,
+		arg.Amount,
+		arg.IsPayment,
+		arg.HappenedAt,
+	)
+	return err
+}
+
+const createStatement = `-- name: CreateStatement :exec
+INSERT INTO statements (account_id, closing_date, currency, period_start, due_date, balance, minimum_due)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (account_id, closing_date) DO NOTHING
+`
+
+type CreateStatementParams struct {
+	AccountID   uuid.UUID    `json:"account_id"`
+	ClosingDate time.Time    `json:"closing_date"`
+	Currency    string       `json:"currency"`
+	PeriodStart sql.NullTime `json:"period_start"`
+	DueDate     time.Time    `json:"due_date"`
+	Balance     string       `json:"balance"`
+	MinimumDue  string       `json:"minimum_due"`
+}
+
+func (q *Queries) CreateStatement(ctx context.Context, arg CreateStatementParams) error {
+	_, err := q.db.ExecContext(ctx, createStatement,
+		arg.AccountID,
+		arg.ClosingDate,
+		arg.Currency,
+		arg.PeriodStart,
+		arg.DueDate,
+		arg.Balance,
+		arg.MinimumDue,
+	)
+	return err
+}
+
+const deleteCardMovements = `-- name: DeleteCardMovements :exec
+DELETE FROM card_movements
+WHERE transaction_id = $1
+`
+
+func (q *Queries) DeleteCardMovements(ctx context.Context, transactionID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteCardMovements, transactionID)
+	return err
+}
+
+const getCardBalanceBefore = `-- name: GetCardBalanceBefore :one
+SELECT COALESCE(SUM(amount), 0)::DECIMAL AS balance
+FROM card_movements
+WHERE account_id = $1 AND currency = $2 AND happened_at < $3
+`
+
+type GetCardBalanceBeforeParams struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Currency  string    `json:"currency"`
+	Before    time.Time `json:"before"`
+}
+
+func (q *Queries) GetCardBalanceBefore(ctx context.Context, arg GetCardBalanceBeforeParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getCardBalanceBefore, arg.AccountID, arg.Currency, arg.Before)
+	var balance string
+	err := row.Scan(&balance)
+	return balance, err
+}
+
+const listStatements = `-- name: ListStatements :many
+SELECT s.account_id, s.closing_date, s.currency, s.period_start, s.due_date, s.balance, s.minimum_due,
+    COALESCE((
+        SELECT SUM(m.amount)
+        FROM card_movements m
+        WHERE m.account_id = s.account_id AND m.currency = s.currency AND m.is_payment
+            AND m.happened_at >= s.closing_date + 1 AND m.happened_at < s.due_date + 1
+    ), 0)::DECIMAL AS paid
+FROM statements s
+WHERE s.account_id = $1
+ORDER BY s.closing_date DESC
+`
+
+type ListStatementsRow struct {
+	AccountID   uuid.UUID    `json:"account_id"`
+	ClosingDate time.Time    `json:"closing_date"`
+	Currency    string       `json:"currency"`
+	PeriodStart sql.NullTime `json:"period_start"`
+	DueDate     time.Time    `json:"due_date"`
+	Balance     string       `json:"balance"`
+	MinimumDue  string       `json:"minimum_due"`
+	Paid        string       `json:"paid"`
+}
+
+func (q *Queries) ListStatements(ctx context.Context, accountID uuid.UUID) ([]ListStatementsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStatements, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStatementsRow
+	for rows.Next() {
+		var i ListStatementsRow
+		if err := rows.Scan(
+			&i.AccountID,
+			&i.ClosingDate,
+			&i.Currency,
+			&i.PeriodStart,
+			&i.DueDate,
+			&i.Balance,
+			&i.MinimumDue,
+			&i.Paid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateCardMovementAmount = `-- name: UpdateCardMovementAmount :exec
+UPDATE card_movements
+SET amount = $3
+WHERE transaction_id = $1 AND account_id = $2
+`
+
+type UpdateCardMovementAmountParams struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	AccountID     uuid.UUID `json:"account_id"`
+	Amount        string    `json:"amount"`
+}
+
+func (q *Queries) UpdateCardMovementAmount(ctx context.Context, arg UpdateCardMovementAmountParams) error {
+	_, err := q.db.ExecContext(ctx, updateCardMovementAmount, arg.TransactionID, arg.AccountID, arg.Amount)
+	return err
+}
+
+const updateCardMovementHappenedAt = `-- name: UpdateCardMovementHappenedAt :exec
+UPDATE card_movements
+SET happened_at = $2
+WHERE transaction_id = $1
+`
+
+type UpdateCardMovementHappenedAtParams struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	HappenedAt    time.Time `json:"happened_at"`
+}
+
+func (q *Queries) UpdateCardMovementHappenedAt(ctx context.Context, arg UpdateCardMovementHappenedAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateCardMovementHappenedAt, arg.TransactionID, arg.HappenedAt)
+	return err
+}
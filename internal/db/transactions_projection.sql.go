@@ -16,21 +16,23 @@ import (
 
 const createTransaction = `-- name: CreateTransaction :exec
 INSERT INTO transactions (
-    id, account_id, transaction_type, amount, currency, category, description, happened_at
+    id, account_id, transaction_type, amount, currency, category, description, happened_at, transaction_id, exchange_rate
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 )
 `
 
 type CreateTransactionParams struct {
-	ID              uuid.UUID `json:"id"`
-	AccountID       uuid.UUID `json:"account_id"`
-	TransactionType string    `json:"transaction_type"`
-	Amount          string    `json:"amount"`
-	Currency        string    `json:"currency"`
-	Category        string    `json:"category"`
-	Description     string    `json:"description"`
-	HappenedAt      time.Time `json:"happened_at"`
+	ID              uuid.UUID      `json:"id"`
+	AccountID       uuid.UUID      `json:"account_id"`
+	TransactionType string         `json:"transaction_type"`
+	Amount          string         `json:"amount"`
+	Currency        string         `json:"currency"`
+	Category        string         `json:"category"`
+	Description     string         `json:"description"`
+	HappenedAt      time.Time      `json:"happened_at"`
+	TransactionID   uuid.NullUUID  `json:"transaction_id"`
+	ExchangeRate    sql.NullString `json:"exchange_rate"`
 }
 
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) error {
@@ -43,6 +45,8 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.Category,
 		arg.Description,
 		arg.HappenedAt,
+		arg.TransactionID,
+		arg.ExchangeRate,
 	)
 	return err
 }
@@ -50,6 +54,7 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 const listCategories = `-- name: ListCategories :many
 SELECT DISTINCT category 
 FROM transactions 
+WHERE voided_at IS NULL
 ORDER BY category
 `
 
@@ -83,9 +88,10 @@ WITH distributions AS (
         SUM(CASE WHEN transaction_type IN ('TRANSFER') THEN amount ELSE 0 END) OVER (PARTITION BY account_id, currency ORDER BY happened_at ASC, id ASC) as system_amount,
         SUM(CASE WHEN transaction_type IN ('DEPOSIT', 'WITHDRAWAL') THEN amount ELSE 0 END) OVER (PARTITION BY account_id, currency ORDER BY happened_at ASC, id ASC) as other_amount
     FROM transactions
+    WHERE voided_at IS NULL
 )
 SELECT
-    t.id, t.account_id, t.transaction_type, t.amount, t.currency, t.category, t.description, t.happened_at, t.created_at,
+    t.id, t.transaction_id, t.account_id, t.transaction_type, t.amount, t.currency, t.category, t.description, t.happened_at, t.created_at, t.exchange_rate,
     COALESCE(CASE 
         WHEN d.system_amount + d.other_amount != 0 THEN ROUND(d.system_amount::DECIMAL / (d.system_amount + d.other_amount)::DECIMAL, 4)::TEXT ELSE '0' END, '0') as system_total_rate
 FROM transactions t
@@ -106,16 +112,18 @@ type ListTransactionsParams struct {
 }
 
 type ListTransactionsRow struct {
-	ID              uuid.UUID   `json:"id"`
-	AccountID       uuid.UUID   `json:"account_id"`
-	TransactionType string      `json:"transaction_type"`
-	Amount          string      `json:"amount"`
-	Currency        string      `json:"currency"`
-	Category        string      `json:"category"`
-	Description     string      `json:"description"`
-	HappenedAt      time.Time   `json:"happened_at"`
-	CreatedAt       time.Time   `json:"created_at"`
-	SystemTotalRate interface{} `json:"system_total_rate"`
+	ID              uuid.UUID      `json:"id"`
+	TransactionID   uuid.NullUUID  `json:"transaction_id"`
+	AccountID       uuid.UUID      `json:"account_id"`
+	TransactionType string         `json:"transaction_type"`
+	Amount          string         `json:"amount"`
+	Currency        string         `json:"currency"`
+	Category        string         `json:"category"`
+	Description     string         `json:"description"`
+	HappenedAt      time.Time      `json:"happened_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+	ExchangeRate    sql.NullString `json:"exchange_rate"`
+	SystemTotalRate interface{}    `json:"system_total_rate"`
 }
 
 func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]ListTransactionsRow, error) {
@@ -134,6 +142,7 @@ func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsPara
 		var i ListTransactionsRow
 		if err := rows.Scan(
 			&i.ID,
+			&i.TransactionID,
 			&i.AccountID,
 			&i.TransactionType,
 			&i.Amount,
@@ -142,6 +151,7 @@ func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsPara
 			&i.Description,
 			&i.HappenedAt,
 			&i.CreatedAt,
+			&i.ExchangeRate,
 			&i.SystemTotalRate,
 		); err != nil {
 			return nil, err
@@ -164,9 +174,10 @@ WITH distributions AS (
         SUM(CASE WHEN transaction_type IN ('TRANSFER') THEN amount ELSE 0 END) OVER (PARTITION BY account_id, currency ORDER BY happened_at ASC, id ASC) as system_amount,
         SUM(CASE WHEN transaction_type IN ('DEPOSIT', 'WITHDRAWAL') THEN amount ELSE 0 END) OVER (PARTITION BY account_id, currency ORDER BY happened_at ASC, id ASC) as other_amount
     FROM transactions
+    WHERE voided_at IS NULL
 )
 SELECT
-    t.id, t.account_id, t.transaction_type, t.amount, t.currency, t.category, t.description, t.happened_at, t.created_at,
+    t.id, t.transaction_id, t.account_id, t.transaction_type, t.amount, t.currency, t.category, t.description, t.happened_at, t.created_at, t.exchange_rate,
     COALESCE(CASE 
         WHEN d.system_amount + d.other_amount != 0 THEN ROUND(d.system_amount::DECIMAL / (d.system_amount + d.other_amount)::DECIMAL, 4)::TEXT ELSE '0' END, '0') as system_total_rate,
     COUNT(*) OVER() as total_count
@@ -191,17 +202,19 @@ type ListTransactionsPaginatedParams struct {
 }
 
 type ListTransactionsPaginatedRow struct {
-	ID              uuid.UUID   `json:"id"`
-	AccountID       uuid.UUID   `json:"account_id"`
-	TransactionType string      `json:"transaction_type"`
-	Amount          string      `json:"amount"`
-	Currency        string      `json:"currency"`
-	Category        string      `json:"category"`
-	Description     string      `json:"description"`
-	HappenedAt      time.Time   `json:"happened_at"`
-	CreatedAt       time.Time   `json:"created_at"`
-	SystemTotalRate interface{} `json:"system_total_rate"`
-	TotalCount      int64       `json:"total_count"`
+	ID              uuid.UUID      `json:"id"`
+	TransactionID   uuid.NullUUID  `json:"transaction_id"`
+	AccountID       uuid.UUID      `json:"account_id"`
+	TransactionType string         `json:"transaction_type"`
+	Amount          string         `json:"amount"`
+	Currency        string         `json:"currency"`
+	Category        string         `json:"category"`
+	Description     string         `json:"description"`
+	HappenedAt      time.Time      `json:"happened_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+	ExchangeRate    sql.NullString `json:"exchange_rate"`
+	SystemTotalRate interface{}    `json:"system_total_rate"`
+	TotalCount      int64          `json:"total_count"`
 }
 
 func (q *Queries) ListTransactionsPaginated(ctx context.Context, arg ListTransactionsPaginatedParams) ([]ListTransactionsPaginatedRow, error) {
@@ -222,6 +235,7 @@ func (q *Queries) ListTransactionsPaginated(ctx context.Context, arg ListTransac
 		var i ListTransactionsPaginatedRow
 		if err := rows.Scan(
 			&i.ID,
+			&i.TransactionID,
 			&i.AccountID,
 			&i.TransactionType,
 			&i.Amount,
@@ -230,6 +244,7 @@ func (q *Queries) ListTransactionsPaginated(ctx context.Context, arg ListTransac
 			&i.Description,
 			&i.HappenedAt,
 			&i.CreatedAt,
+			&i.ExchangeRate,
 			&i.SystemTotalRate,
 			&i.TotalCount,
 		); err != nil {
@@ -245,3 +260,85 @@ func (q *Queries) ListTransactionsPaginated(ctx context.Context, arg ListTransac
 	}
 	return items, nil
 }
+
+const updateTransactionAmount = `-- name: UpdateTransactionAmount :exec
+UPDATE transactions
+SET amount = $2
+WHERE transaction_id = $1
+`
+
+type UpdateTransactionAmountParams struct {
+	TransactionID uuid.NullUUID `json:"transaction_id"`
+	Amount        string        `json:"amount"`
+}
+
+func (q *Queries) UpdateTransactionAmount(ctx context.Context, arg UpdateTransactionAmountParams) error {
+	_, err := q.db.ExecContext(ctx, updateTransactionAmount, arg.TransactionID, arg.Amount)
+	return err
+}
+
+const updateTransactionCategory = `-- name: UpdateTransactionCategory :exec
+UPDATE transactions
+SET category = $2
+WHERE transaction_id = $1 AND NOT (transaction_type = 'EXPENSE' AND exchange_rate IS NOT NULL)
+`
+
+type UpdateTransactionCategoryParams struct {
+	TransactionID uuid.NullUUID `json:"transaction_id"`
+	Category      string        `json:"category"`
+}
+
+// The FX cost of a transfer, the only expense with an exchange rate, keeps its
+// category.
+func (q *Queries) UpdateTransactionCategory(ctx context.Context, arg UpdateTransactionCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, updateTransactionCategory, arg.TransactionID, arg.Category)
+	return err
+}
+
+const updateTransactionDescription = `-- name: UpdateTransactionDescription :exec
+UPDATE transactions
+SET description = $2
+WHERE transaction_id = $1
+`
+
+type UpdateTransactionDescriptionParams struct {
+	TransactionID uuid.NullUUID `json:"transaction_id"`
+	Description   string        `json:"description"`
+}
+
+func (q *Queries) UpdateTransactionDescription(ctx context.Context, arg UpdateTransactionDescriptionParams) error {
+	_, err := q.db.ExecContext(ctx, updateTransactionDescription, arg.TransactionID, arg.Description)
+	return err
+}
+
+const updateTransactionHappenedAt = `-- name: UpdateTransactionHappenedAt :exec
+UPDATE transactions
+SET happened_at = $2
+WHERE transaction_id = $1
+`
+
+type UpdateTransactionHappenedAtParams struct {
+	TransactionID uuid.NullUUID `json:"transaction_id"`
+	HappenedAt    time.Time     `json:"happened_at"`
+}
+
+func (q *Queries) UpdateTransactionHappenedAt(ctx context.Context, arg UpdateTransactionHappenedAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateTransactionHappenedAt, arg.TransactionID, arg.HappenedAt)
+	return err
+}
+
+const voidTransaction = `-- name: VoidTransaction :exec
+UPDATE transactions
+SET voided_at = $2
+WHERE transaction_id = $1
+`
+
+type VoidTransactionParams struct {
+	TransactionID uuid.NullUUID `json:"transaction_id"`
+	VoidedAt      sql.NullTime  `json:"voided_at"`
+}
+
+func (q *Queries) VoidTransaction(ctx context.Context, arg VoidTransactionParams) error {
+	_, err := q.db.ExecContext(ctx, voidTransaction, arg.TransactionID, arg.VoidedAt)
+	return err
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/somatom98/brokeli/pkg/event_store/postgres"
+)
+
+// In returns the queries bound to the transaction carried by ctx, if any, so
+// that the projections write in the transaction a named subscription applies
+// a record in, together with its checkpoint.
+func (q *Queries) In(ctx context.Context) *Queries {
+	if tx, ok := postgres.TxFrom(ctx); ok {
+		return q.WithTx(tx)
+	}
+	return q
+}
+
+// InTx runs fn with the queries bound to the transaction carried by ctx or,
+// when there is none, to a new one committed once fn succeeds.
+func InTx(ctx context.Context, conn *sql.DB, fn func(qtx *Queries) error) error {
+	if tx, ok := postgres.TxFrom(ctx); ok {
+		return fn(New(tx))
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(New(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
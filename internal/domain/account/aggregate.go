@@ -2,9 +2,12 @@ package account
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/somatom98/brokeli/internal/domain/account/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
 	"github.com/somatom98/brokeli/pkg/event_store"
 )
 
@@ -13,17 +16,33 @@ type State int
 const (
 	State_Unopened State = iota
 	State_Opened
+	State_Closed
 )
 
 type Account struct {
-	ID    uuid.UUID
-	State State
+	ID       uuid.UUID
+	State    State
+	Currency values.Currency
+	// Balance is the balance by currency of the money deposited into and
+	// withdrawn from the account, and of the money transactions posted to it.
+	Balance map[values.Currency]decimal.Decimal
+	// OverdraftLimits are how far below zero the balance can go, in the
+	// currencies that have a limit.
+	OverdraftLimits map[values.Currency]decimal.Decimal
+	Kind            values.AccountKind
+	Details         values.AccountDetails
+	// StatementCycle is the billing cycle of a credit card, nil until set.
+	StatementCycle *StatementCycle
+	// LastClosingDate is the closing date of the last statement closed.
+	LastClosingDate time.Time
 }
 
 func New(id uuid.UUID) *Account {
 	return &Account{
-		ID:    id,
-		State: State_Unopened,
+		ID:              id,
+		State:           State_Unopened,
+		Balance:         make(map[values.Currency]decimal.Decimal),
+		OverdraftLimits: make(map[values.Currency]decimal.Decimal),
 	}
 }
 
@@ -54,6 +73,60 @@ func (a *Account) Hydrate(records []event_store.Record) error {
 				return fmt.Errorf("decode MoneyWithdrawn event: %w", err)
 			}
 			a.ApplyMoneyWithdrawn(event)
+		case events.TypeMoneyPosted:
+			event, err := event_store.DecodeEvent[events.MoneyPosted](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode MoneyPosted event: %w", err)
+			}
+			a.ApplyMoneyPosted(event)
+		case events.TypeClosed:
+			event, err := event_store.DecodeEvent[events.Closed](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode Closed event: %w", err)
+			}
+			a.ApplyClosed(event)
+		case events.TypeReopened:
+			event, err := event_store.DecodeEvent[events.Reopened](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode Reopened event: %w", err)
+			}
+			a.ApplyReopened(event)
+		case events.TypeOverdraftLimitSet:
+			event, err := event_store.DecodeEvent[events.OverdraftLimitSet](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode OverdraftLimitSet event: %w", err)
+			}
+			a.ApplyOverdraftLimitSet(event)
+		case events.TypeOverdraftLimitRemoved:
+			event, err := event_store.DecodeEvent[events.OverdraftLimitRemoved](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode OverdraftLimitRemoved event: %w", err)
+			}
+			a.ApplyOverdraftLimitRemoved(event)
+		case events.TypeKindChanged:
+			event, err := event_store.DecodeEvent[events.KindChanged](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode KindChanged event: %w", err)
+			}
+			a.ApplyKindChanged(event)
+		case events.TypeDetailsUpdated:
+			event, err := event_store.DecodeEvent[events.DetailsUpdated](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode DetailsUpdated event: %w", err)
+			}
+			a.ApplyDetailsUpdated(event)
+		case events.TypeStatementCycleSet:
+			event, err := event_store.DecodeEvent[events.StatementCycleSet](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode StatementCycleSet event: %w", err)
+			}
+			a.ApplyStatementCycleSet(event)
+		case events.TypeStatementClosed:
+			event, err := event_store.DecodeEvent[events.StatementClosed](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode StatementClosed event: %w", err)
+			}
+			a.ApplyStatementClosed(event)
 		}
 	}
 
@@ -63,15 +136,56 @@ func (a *Account) Hydrate(records []event_store.Record) error {
 func (a *Account) ApplyOpened(event events.Opened) {
 	a.ID = event.AccountID
 	a.State = State_Opened
+	a.Currency = event.Currency
 }
 
 func (a *Account) ApplyNameUpdated(event events.NameUpdated) {
 }
 
 func (a *Account) ApplyMoneyDeposited(event events.MoneyDeposited) {
-	// No state change in aggregate for now, but could update balance if we had it
+	a.Balance[event.Currency] = a.Balance[event.Currency].Add(event.Amount)
 }
 
 func (a *Account) ApplyMoneyWithdrawn(event events.MoneyWithdrawn) {
-	// No state change in aggregate for now
+	a.Balance[event.Currency] = a.Balance[event.Currency].Sub(event.Amount)
+}
+
+func (a *Account) ApplyMoneyPosted(event events.MoneyPosted) {
+	a.Balance[event.Currency] = a.Balance[event.Currency].Add(event.Side.Signed(event.Amount))
+}
+
+func (a *Account) ApplyClosed(event events.Closed) {
+	a.State = State_Closed
+}
+
+func (a *Account) ApplyReopened(event events.Reopened) {
+	a.State = State_Opened
+}
+
+func (a *Account) ApplyOverdraftLimitSet(event events.OverdraftLimitSet) {
+	a.OverdraftLimits[event.Currency] = event.Limit
+}
+
+func (a *Account) ApplyOverdraftLimitRemoved(event events.OverdraftLimitRemoved) {
+	delete(a.OverdraftLimits, event.Currency)
+}
+
+func (a *Account) ApplyKindChanged(event events.KindChanged) {
+	a.Kind = event.Kind
+}
+
+func (a *Account) ApplyDetailsUpdated(event events.DetailsUpdated) {
+	a.Details = event.Details
+}
+
+func (a *Account) ApplyStatementCycleSet(event events.StatementCycleSet) {
+	a.StatementCycle = &StatementCycle{
+		ClosingDay:         event.ClosingDay,
+		DueDay:             event.DueDay,
+		MinimumPaymentRate: event.MinimumPaymentRate,
+	}
+}
+
+func (a *Account) ApplyStatementClosed(event events.StatementClosed) {
+	a.LastClosingDate = event.ClosingDate
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/somatom98/brokeli/internal/domain/account/events"
 	"github.com/somatom98/brokeli/internal/domain/values"
@@ -12,7 +13,16 @@ import (
 
 var (
 	ErrAccountNotOpened     = errors.New("account_not_opened")
+	ErrAccountClosed        = errors.New("account_closed")
 	ErrNegativeOrNullAmount = errors.New("negative_or_null_amount")
+	ErrNonZeroBalance       = errors.New("non_zero_balance")
+	ErrInsufficientFunds    = errors.New("insufficient_funds")
+	ErrNegativeLimit        = errors.New("negative_limit")
+	ErrInvalidKind          = errors.New("invalid_kind")
+	ErrNotCreditCard        = errors.New("not_credit_card")
+	ErrInvalidCycle         = errors.New("invalid_statement_cycle")
+	ErrNoStatementCycle     = errors.New("no_statement_cycle")
+	ErrInvalidClosingDate   = errors.New("invalid_closing_date")
 )
 
 func (a *Account) Open(
@@ -36,7 +46,7 @@ func (a *Account) UpdateName(
 	name string,
 	happenedAt time.Time,
 ) (evt event_store.Event, err error) {
-	if a.State != State_Opened {
+	if a.State < State_Opened {
 		return nil, ErrAccountNotOpened
 	}
 
@@ -59,6 +69,10 @@ func (a *Account) Deposit(
 		return nil, ErrAccountNotOpened
 	}
 
+	if a.State == State_Closed {
+		return nil, ErrAccountClosed
+	}
+
 	if !amount.IsPositive() {
 		return nil, ErrNegativeOrNullAmount
 	}
@@ -86,10 +100,18 @@ func (a *Account) Withdraw(
 		return nil, ErrAccountNotOpened
 	}
 
+	if a.State == State_Closed {
+		return nil, ErrAccountClosed
+	}
+
 	if !amount.IsPositive() {
 		return nil, ErrNegativeOrNullAmount
 	}
 
+	if a.overdrawn(currency, amount) {
+		return nil, ErrInsufficientFunds
+	}
+
 	return &events.MoneyWithdrawn{
 		AccountID:   a.ID,
 		Currency:    currency,
@@ -100,3 +122,297 @@ func (a *Account) Withdraw(
 		HappenedAt:  happenedAt,
 	}, nil
 }
+
+// Post records the money the transaction of transactionID moved on the
+// account, debits being held to the overdraft limit like withdrawals. Unlike
+// them, transactions can be posted to accounts that were never opened.
+func (a *Account) Post(
+	transactionID uuid.UUID,
+	currency values.Currency,
+	amount decimal.Decimal,
+	side values.Side,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State == State_Closed {
+		return nil, ErrAccountClosed
+	}
+
+	if amount.IsZero() {
+		return nil, nil
+	}
+
+	if !amount.IsPositive() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	if side == values.Side_Debit && a.overdrawn(currency, amount) {
+		return nil, ErrInsufficientFunds
+	}
+
+	return &events.MoneyPosted{
+		AccountID:     a.ID,
+		TransactionID: transactionID,
+		Currency:      currency,
+		Amount:        amount,
+		Side:          side,
+		HappenedAt:    happenedAt,
+	}, nil
+}
+
+// overdrawn returns whether taking the amount out of the balance in the
+// currency would take it below the overdraft limit, if any.
+func (a *Account) overdrawn(currency values.Currency, amount decimal.Decimal) bool {
+	limit, ok := a.OverdraftLimits[currency]
+	return ok && a.Balance[currency].Sub(amount).LessThan(limit.Neg())
+}
+
+// Close closes the account, which can then no longer be posted to. The
+// balances of the account in every currency must be zero.
+func (a *Account) Close(
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State < State_Opened {
+		return nil, ErrAccountNotOpened
+	}
+
+	if a.State == State_Closed {
+		return nil, nil
+	}
+
+	for _, balance := range a.Balance {
+		if !balance.IsZero() {
+			return nil, ErrNonZeroBalance
+		}
+	}
+
+	return &events.Closed{
+		AccountID:  a.ID,
+		HappenedAt: happenedAt,
+	}, nil
+}
+
+func (a *Account) Reopen(
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State < State_Opened {
+		return nil, ErrAccountNotOpened
+	}
+
+	if a.State != State_Closed {
+		return nil, nil
+	}
+
+	return &events.Reopened{
+		AccountID:  a.ID,
+		HappenedAt: happenedAt,
+	}, nil
+}
+
+// SetOverdraftLimit limits withdrawals in the currency to the ones leaving the
+// balance at no less than minus the limit. Without a limit, the balance can go
+// below zero freely.
+func (a *Account) SetOverdraftLimit(
+	currency values.Currency,
+	limit decimal.Decimal,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State < State_Opened {
+		return nil, ErrAccountNotOpened
+	}
+
+	if a.State == State_Closed {
+		return nil, ErrAccountClosed
+	}
+
+	if limit.IsNegative() {
+		return nil, ErrNegativeLimit
+	}
+
+	if current, ok := a.OverdraftLimits[currency]; ok && current.Equal(limit) {
+		return nil, nil
+	}
+
+	return &events.OverdraftLimitSet{
+		AccountID:  a.ID,
+		Currency:   currency,
+		Limit:      limit,
+		HappenedAt: happenedAt,
+	}, nil
+}
+
+func (a *Account) RemoveOverdraftLimit(
+	currency values.Currency,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State < State_Opened {
+		return nil, ErrAccountNotOpened
+	}
+
+	if a.State == State_Closed {
+		return nil, ErrAccountClosed
+	}
+
+	if _, ok := a.OverdraftLimits[currency]; !ok {
+		return nil, nil
+	}
+
+	return &events.OverdraftLimitRemoved{
+		AccountID:  a.ID,
+		Currency:   currency,
+		HappenedAt: happenedAt,
+	}, nil
+}
+
+func (a *Account) ChangeKind(
+	kind values.AccountKind,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State < State_Opened {
+		return nil, ErrAccountNotOpened
+	}
+
+	if !kind.IsValid() {
+		return nil, ErrInvalidKind
+	}
+
+	if a.Kind == kind {
+		return nil, nil
+	}
+
+	return &events.KindChanged{
+		AccountID:  a.ID,
+		Kind:       kind,
+		HappenedAt: happenedAt,
+	}, nil
+}
+
+// UpdateDetails replaces the details of the account, masking its number.
+func (a *Account) UpdateDetails(
+	details values.AccountDetails,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State < State_Opened {
+		return nil, ErrAccountNotOpened
+	}
+
+	details = details.Normalized()
+	if a.Details.Equal(details) {
+		return nil, nil
+	}
+
+	return &events.DetailsUpdated{
+		AccountID:  a.ID,
+		Details:    details,
+		HappenedAt: happenedAt,
+	}, nil
+}
+
+// Update lists the changes to the properties of an account, nil fields being
+// left unchanged.
+type Update struct {
+	Name    *string
+	Kind    *values.AccountKind
+	Details *values.AccountDetails
+}
+
+// Update emits the events of every property the update changes.
+func (a *Account) Update(update Update, happenedAt time.Time) (evts []event_store.Event, err error) {
+	collect := func(evt event_store.Event, err error) error {
+		if err != nil || evt == nil {
+			return err
+		}
+		evts = append(evts, evt)
+		return nil
+	}
+
+	if update.Name != nil {
+		if err := collect(a.UpdateName(*update.Name, happenedAt)); err != nil {
+			return nil, err
+		}
+	}
+	if update.Kind != nil {
+		if err := collect(a.ChangeKind(*update.Kind, happenedAt)); err != nil {
+			return nil, err
+		}
+	}
+	if update.Details != nil {
+		if err := collect(a.UpdateDetails(*update.Details, happenedAt)); err != nil {
+			return nil, err
+		}
+	}
+
+	return evts, nil
+}
+
+func (a *Account) SetStatementCycle(
+	cycle StatementCycle,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State < State_Opened {
+		return nil, ErrAccountNotOpened
+	}
+
+	if a.State == State_Closed {
+		return nil, ErrAccountClosed
+	}
+
+	if a.Kind != values.AccountKind_CreditCard {
+		return nil, ErrNotCreditCard
+	}
+
+	if !cycle.IsValid() {
+		return nil, ErrInvalidCycle
+	}
+
+	if a.StatementCycle != nil && a.StatementCycle.Equal(cycle) {
+		return nil, nil
+	}
+
+	return &events.StatementCycleSet{
+		AccountID:          a.ID,
+		ClosingDay:         cycle.ClosingDay,
+		DueDay:             cycle.DueDay,
+		MinimumPaymentRate: cycle.MinimumPaymentRate,
+		HappenedAt:         happenedAt,
+	}, nil
+}
+
+// CloseStatement bills the balance owed on the card at the end of the day of
+// closingDate, which must fall on the closing day of the cycle. Statements
+// are closed in order, so closing one again does nothing.
+func (a *Account) CloseStatement(
+	closingDate time.Time,
+	balance decimal.Decimal,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State < State_Opened {
+		return nil, ErrAccountNotOpened
+	}
+
+	if a.State == State_Closed {
+		return nil, ErrAccountClosed
+	}
+
+	if a.StatementCycle == nil {
+		return nil, ErrNoStatementCycle
+	}
+
+	if closingDate.Day() != a.StatementCycle.ClosingDay {
+		return nil, ErrInvalidClosingDate
+	}
+
+	if !closingDate.After(a.LastClosingDate) {
+		return nil, nil
+	}
+
+	return &events.StatementClosed{
+		AccountID:   a.ID,
+		Currency:    a.Currency,
+		PeriodStart: a.LastClosingDate,
+		ClosingDate: closingDate,
+		DueDate:     a.StatementCycle.DueDate(closingDate),
+		Balance:     balance,
+		MinimumDue:  a.StatementCycle.MinimumDue(balance),
+		HappenedAt:  happenedAt,
+	}, nil
+}
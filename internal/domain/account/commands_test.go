@@ -12,6 +12,7 @@ import (
 	"github.com/somatom98/brokeli/internal/domain/account"
 	"github.com/somatom98/brokeli/internal/domain/account/events"
 	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
 )
 
 func TestOpen(t *testing.T) {
@@ -134,6 +135,19 @@ func TestDeposit(t *testing.T) {
 		require.ErrorIs(t, err, account.ErrNegativeOrNullAmount)
 		assert.Nil(t, evt)
 	})
+
+	t.Run("should return error when account is closed", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Closed
+
+		// act
+		evt, err := acc.Deposit(values.Currency("EUR"), decimal.NewFromInt(100), "", "", "user", now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrAccountClosed)
+		assert.Nil(t, evt)
+	})
 }
 
 func TestWithdraw(t *testing.T) {
@@ -186,4 +200,611 @@ func TestWithdraw(t *testing.T) {
 		require.ErrorIs(t, err, account.ErrNegativeOrNullAmount)
 		assert.Nil(t, evt)
 	})
+
+	t.Run("should return error when account is closed", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Closed
+
+		// act
+		evt, err := acc.Withdraw(values.Currency("EUR"), decimal.NewFromInt(100), "", "", "user", now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrAccountClosed)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when the withdrawal exceeds the overdraft limit", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.ApplyOpened(events.Opened{AccountID: acc.ID})
+		acc.ApplyMoneyDeposited(events.MoneyDeposited{Currency: "EUR", Amount: decimal.NewFromInt(100)})
+		acc.ApplyOverdraftLimitSet(events.OverdraftLimitSet{Currency: "EUR", Limit: decimal.NewFromInt(50)})
+
+		// act
+		evt, err := acc.Withdraw(values.Currency("EUR"), decimal.NewFromInt(151), "", "", "user", now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrInsufficientFunds)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should allow an overdraft within the limit", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.ApplyOpened(events.Opened{AccountID: acc.ID})
+		acc.ApplyMoneyDeposited(events.MoneyDeposited{Currency: "EUR", Amount: decimal.NewFromInt(100)})
+		acc.ApplyOverdraftLimitSet(events.OverdraftLimitSet{Currency: "EUR", Limit: decimal.NewFromInt(50)})
+
+		// act
+		evt, err := acc.Withdraw(values.Currency("EUR"), decimal.NewFromInt(150), "", "", "user", now)
+
+		// assert
+		require.NoError(t, err)
+		assert.NotNil(t, evt)
+	})
+
+	t.Run("should allow any withdrawal in a currency without limit", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.ApplyOpened(events.Opened{AccountID: acc.ID})
+		acc.ApplyOverdraftLimitSet(events.OverdraftLimitSet{Currency: "EUR", Limit: decimal.Zero})
+
+		// act
+		evt, err := acc.Withdraw(values.Currency("USD"), decimal.NewFromInt(1000), "", "", "user", now)
+
+		// assert
+		require.NoError(t, err)
+		assert.NotNil(t, evt)
+	})
+}
+
+func TestPost(t *testing.T) {
+	now := time.Now()
+	transactionID := uuid.New()
+
+	t.Run("should emit money posted event when payload is valid", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		acc := account.New(id)
+
+		// act
+		evt, err := acc.Post(transactionID, values.Currency("EUR"), decimal.NewFromInt(50), values.Side_Debit, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.MoneyPosted{
+			AccountID:     id,
+			TransactionID: transactionID,
+			Currency:      values.Currency("EUR"),
+			Amount:        decimal.NewFromInt(50),
+			Side:          values.Side_Debit,
+			HappenedAt:    now,
+		}, evt)
+	})
+
+	t.Run("should return error when account is closed", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Closed
+
+		// act
+		evt, err := acc.Post(transactionID, values.Currency("EUR"), decimal.NewFromInt(50), values.Side_Credit, now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrAccountClosed)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when a debit exceeds the overdraft limit", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.ApplyOpened(events.Opened{AccountID: acc.ID})
+		acc.ApplyMoneyPosted(events.MoneyPosted{Currency: "EUR", Amount: decimal.NewFromInt(100), Side: values.Side_Credit})
+		acc.ApplyOverdraftLimitSet(events.OverdraftLimitSet{Currency: "EUR", Limit: decimal.NewFromInt(50)})
+
+		// act
+		evt, err := acc.Post(transactionID, values.Currency("EUR"), decimal.NewFromInt(151), values.Side_Debit, now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrInsufficientFunds)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should allow a credit below the overdraft limit", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.ApplyOpened(events.Opened{AccountID: acc.ID})
+		acc.ApplyMoneyPosted(events.MoneyPosted{Currency: "EUR", Amount: decimal.NewFromInt(100), Side: values.Side_Debit})
+		acc.ApplyOverdraftLimitSet(events.OverdraftLimitSet{Currency: "EUR", Limit: decimal.NewFromInt(50)})
+
+		// act
+		evt, err := acc.Post(transactionID, values.Currency("EUR"), decimal.NewFromInt(20), values.Side_Credit, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.NotNil(t, evt)
+	})
+}
+
+func TestClose(t *testing.T) {
+	now := time.Now()
+	t.Run("should emit closed event when balances are zero", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		acc := account.New(id)
+		acc.ApplyOpened(events.Opened{AccountID: id})
+		acc.ApplyMoneyDeposited(events.MoneyDeposited{Currency: "EUR", Amount: decimal.NewFromInt(40)})
+		acc.ApplyMoneyPosted(events.MoneyPosted{Currency: "EUR", Amount: decimal.NewFromInt(40), Side: values.Side_Debit})
+
+		// act
+		evt, err := acc.Close(now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.Closed{
+			AccountID:  id,
+			HappenedAt: now,
+		}, evt)
+	})
+
+	t.Run("should return error when a balance is not zero", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.ApplyOpened(events.Opened{AccountID: acc.ID})
+		acc.ApplyMoneyDeposited(events.MoneyDeposited{Currency: "EUR", Amount: decimal.NewFromInt(40)})
+		acc.ApplyMoneyWithdrawn(events.MoneyWithdrawn{Currency: "EUR", Amount: decimal.NewFromInt(40)})
+		acc.ApplyMoneyPosted(events.MoneyPosted{Currency: "USD", Amount: decimal.NewFromInt(5), Side: values.Side_Debit})
+
+		// act
+		evt, err := acc.Close(now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrNonZeroBalance)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should no-op when account is already closed", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Closed
+
+		// act
+		evt, err := acc.Close(now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when account is not opened", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+
+		// act
+		evt, err := acc.Close(now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrAccountNotOpened)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestReopen(t *testing.T) {
+	now := time.Now()
+	t.Run("should emit reopened event when account is closed", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		acc := account.New(id)
+		acc.State = account.State_Closed
+
+		// act
+		evt, err := acc.Reopen(now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.Reopened{
+			AccountID:  id,
+			HappenedAt: now,
+		}, evt)
+	})
+
+	t.Run("should no-op when account is open", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Opened
+
+		// act
+		evt, err := acc.Reopen(now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestSetOverdraftLimit(t *testing.T) {
+	now := time.Now()
+	t.Run("should emit overdraft limit set event", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		acc := account.New(id)
+		acc.State = account.State_Opened
+
+		// act
+		evt, err := acc.SetOverdraftLimit(values.Currency("EUR"), decimal.NewFromInt(500), now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.OverdraftLimitSet{
+			AccountID:  id,
+			Currency:   values.Currency("EUR"),
+			Limit:      decimal.NewFromInt(500),
+			HappenedAt: now,
+		}, evt)
+	})
+
+	t.Run("should no-op when the limit is unchanged", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Opened
+		acc.ApplyOverdraftLimitSet(events.OverdraftLimitSet{Currency: "EUR", Limit: decimal.NewFromInt(500)})
+
+		// act
+		evt, err := acc.SetOverdraftLimit(values.Currency("EUR"), decimal.RequireFromString("500.00"), now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when the limit is negative", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Opened
+
+		// act
+		evt, err := acc.SetOverdraftLimit(values.Currency("EUR"), decimal.NewFromInt(-1), now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrNegativeLimit)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestRemoveOverdraftLimit(t *testing.T) {
+	now := time.Now()
+	t.Run("should emit overdraft limit removed event", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		acc := account.New(id)
+		acc.State = account.State_Opened
+		acc.ApplyOverdraftLimitSet(events.OverdraftLimitSet{Currency: "EUR", Limit: decimal.Zero})
+
+		// act
+		evt, err := acc.RemoveOverdraftLimit(values.Currency("EUR"), now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.OverdraftLimitRemoved{
+			AccountID:  id,
+			Currency:   values.Currency("EUR"),
+			HappenedAt: now,
+		}, evt)
+	})
+
+	t.Run("should no-op when there is no limit", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Opened
+
+		// act
+		evt, err := acc.RemoveOverdraftLimit(values.Currency("EUR"), now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestChangeKind(t *testing.T) {
+	now := time.Now()
+	t.Run("should emit kind changed event", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		acc := account.New(id)
+		acc.State = account.State_Opened
+
+		// act
+		evt, err := acc.ChangeKind(values.AccountKind_Savings, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.KindChanged{
+			AccountID:  id,
+			Kind:       values.AccountKind_Savings,
+			HappenedAt: now,
+		}, evt)
+	})
+
+	t.Run("should do nothing when the kind is unchanged", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Opened
+		acc.Kind = values.AccountKind_Savings
+
+		// act
+		evt, err := acc.ChangeKind(values.AccountKind_Savings, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when the kind is unknown", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Opened
+
+		// act
+		evt, err := acc.ChangeKind("PIGGY_BANK", now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrInvalidKind)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when account is not opened", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+
+		// act
+		evt, err := acc.ChangeKind(values.AccountKind_Cash, now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrAccountNotOpened)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestUpdateDetails(t *testing.T) {
+	now := time.Now()
+	t.Run("should emit details updated event with the number masked", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		acc := account.New(id)
+		acc.State = account.State_Opened
+
+		// act
+		evt, err := acc.UpdateDetails(values.AccountDetails{
+			Institution:   "Fineco",
+			AccountNumber: "IT60 X054 2811 1010 0000 0123 456",
+			Tags:          []string{" joint", "", "joint", "emergency"},
+		}, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.DetailsUpdated{
+			AccountID: id,
+			Details: values.AccountDetails{
+				Institution:   "Fineco",
+				AccountNumber: "***********************3456",
+				Tags:          []string{"joint", "emergency"},
+			},
+			HappenedAt: now,
+		}, evt)
+	})
+
+	t.Run("should do nothing when the details are unchanged", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Opened
+		acc.Details = values.AccountDetails{AccountNumber: "****3456", Tags: []string{"joint"}}
+
+		// act
+		evt, err := acc.UpdateDetails(values.AccountDetails{AccountNumber: "****3456", Tags: []string{"joint"}}, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when account is not opened", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+
+		// act
+		evt, err := acc.UpdateDetails(values.AccountDetails{Owner: "marco"}, now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrAccountNotOpened)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	now := time.Now()
+	t.Run("should emit an event for every changed property", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		acc := account.New(id)
+		acc.State = account.State_Opened
+		acc.Kind = values.AccountKind_Checking
+		name := "Main"
+		kind := values.AccountKind_Checking
+		details := values.AccountDetails{Owner: "marco"}
+
+		// act
+		evts, err := acc.Update(account.Update{Name: &name, Kind: &kind, Details: &details}, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, []event_store.Event{
+			&events.NameUpdated{AccountID: id, Name: name, HappenedAt: now},
+			&events.DetailsUpdated{AccountID: id, Details: values.AccountDetails{Owner: "marco"}, HappenedAt: now},
+		}, evts)
+	})
+
+	t.Run("should emit nothing when any change is invalid", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Opened
+		name := "Savings"
+		kind := values.AccountKind("PIGGY_BANK")
+
+		// act
+		evts, err := acc.Update(account.Update{Name: &name, Kind: &kind}, now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrInvalidKind)
+		assert.Empty(t, evts)
+	})
+}
+
+func TestSetStatementCycle(t *testing.T) {
+	now := time.Now()
+	cycle := account.StatementCycle{ClosingDay: 25, DueDay: 10, MinimumPaymentRate: decimal.RequireFromString("0.05")}
+
+	t.Run("should emit statement cycle set event", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		acc := account.New(id)
+		acc.State = account.State_Opened
+		acc.Kind = values.AccountKind_CreditCard
+
+		// act
+		evt, err := acc.SetStatementCycle(cycle, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.StatementCycleSet{
+			AccountID:          id,
+			ClosingDay:         25,
+			DueDay:             10,
+			MinimumPaymentRate: cycle.MinimumPaymentRate,
+			HappenedAt:         now,
+		}, evt)
+	})
+
+	t.Run("should do nothing when the cycle is unchanged", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Opened
+		acc.Kind = values.AccountKind_CreditCard
+		acc.StatementCycle = &account.StatementCycle{ClosingDay: 25, DueDay: 10, MinimumPaymentRate: decimal.RequireFromString("0.050")}
+
+		// act
+		evt, err := acc.SetStatementCycle(cycle, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when account is not a credit card", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Opened
+		acc.Kind = values.AccountKind_Checking
+
+		// act
+		evt, err := acc.SetStatementCycle(cycle, now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrNotCreditCard)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when a day does not fall in every month", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+		acc.State = account.State_Opened
+		acc.Kind = values.AccountKind_CreditCard
+
+		// act
+		evt, err := acc.SetStatementCycle(account.StatementCycle{ClosingDay: 31, DueDay: 10}, now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrInvalidCycle)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestCloseStatement(t *testing.T) {
+	now := time.Now()
+	closingDate := time.Date(2024, 9, 25, 0, 0, 0, 0, time.UTC)
+
+	card := func(id uuid.UUID) *account.Account {
+		acc := account.New(id)
+		acc.State = account.State_Opened
+		acc.Currency = "EUR"
+		acc.Kind = values.AccountKind_CreditCard
+		acc.StatementCycle = &account.StatementCycle{ClosingDay: 25, DueDay: 10, MinimumPaymentRate: decimal.RequireFromString("0.05")}
+		return acc
+	}
+
+	t.Run("should emit statement closed event", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		acc := card(id)
+		acc.LastClosingDate = closingDate.AddDate(0, -1, 0)
+
+		// act
+		evt, err := acc.CloseStatement(closingDate, decimal.RequireFromString("812.4"), now)
+
+		// assert
+		require.NoError(t, err)
+		closed, ok := evt.(*events.StatementClosed)
+		require.True(t, ok)
+		assert.Equal(t, "40.62", closed.MinimumDue.String())
+		closed.MinimumDue = decimal.Zero
+		assert.Equal(t, &events.StatementClosed{
+			AccountID:   id,
+			Currency:    "EUR",
+			PeriodStart: closingDate.AddDate(0, -1, 0),
+			ClosingDate: closingDate,
+			DueDate:     time.Date(2024, 10, 10, 0, 0, 0, 0, time.UTC),
+			Balance:     decimal.RequireFromString("812.4"),
+			MinimumDue:  decimal.Zero,
+			HappenedAt:  now,
+		}, closed)
+	})
+
+	t.Run("should do nothing when the statement is already closed", func(t *testing.T) {
+		// arrange
+		acc := card(uuid.New())
+		acc.LastClosingDate = closingDate
+
+		// act
+		evt, err := acc.CloseStatement(closingDate, decimal.RequireFromString("812.4"), now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when the date is not a closing day", func(t *testing.T) {
+		// arrange
+		acc := card(uuid.New())
+
+		// act
+		evt, err := acc.CloseStatement(closingDate.AddDate(0, 0, 1), decimal.RequireFromString("812.4"), now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrInvalidClosingDate)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when the card has no statement cycle", func(t *testing.T) {
+		// arrange
+		acc := card(uuid.New())
+		acc.StatementCycle = nil
+
+		// act
+		evt, err := acc.CloseStatement(closingDate, decimal.RequireFromString("812.4"), now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrNoStatementCycle)
+		assert.Nil(t, evt)
+	})
 }
@@ -29,8 +29,8 @@ func (d *Dispatcher) Open(
 	currency values.Currency,
 	happenedAt time.Time,
 ) error {
-	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) (event_store.Event, error) {
-		return aggr.Open(name, currency, happenedAt)
+	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) ([]event_store.Event, error) {
+		return event_store.One(aggr.Open(name, currency, happenedAt))
 	})
 }
 
@@ -40,8 +40,8 @@ func (d *Dispatcher) UpdateName(
 	name string,
 	happenedAt time.Time,
 ) error {
-	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) (event_store.Event, error) {
-		return aggr.UpdateName(name, happenedAt)
+	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) ([]event_store.Event, error) {
+		return event_store.One(aggr.UpdateName(name, happenedAt))
 	})
 }
 
@@ -55,8 +55,8 @@ func (d *Dispatcher) Deposit(
 	user string,
 	happenedAt time.Time,
 ) error {
-	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) (event_store.Event, error) {
-		return aggr.Deposit(currency, amount, category, description, user, happenedAt)
+	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) ([]event_store.Event, error) {
+		return event_store.One(aggr.Deposit(currency, amount, category, description, user, happenedAt))
 	})
 }
 
@@ -70,7 +70,110 @@ func (d *Dispatcher) Withdraw(
 	user string,
 	happenedAt time.Time,
 ) error {
-	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) (event_store.Event, error) {
-		return aggr.Withdraw(currency, amount, category, description, user, happenedAt)
+	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) ([]event_store.Event, error) {
+		return event_store.One(aggr.Withdraw(currency, amount, category, description, user, happenedAt))
 	})
 }
+
+// Close closes the account, provided that its balances are all zero.
+func (d *Dispatcher) Close(
+	ctx context.Context,
+	id uuid.UUID,
+	happenedAt time.Time,
+) error {
+	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) ([]event_store.Event, error) {
+		return event_store.One(aggr.Close(happenedAt))
+	})
+}
+
+func (d *Dispatcher) Reopen(
+	ctx context.Context,
+	id uuid.UUID,
+	happenedAt time.Time,
+) error {
+	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) ([]event_store.Event, error) {
+		return event_store.One(aggr.Reopen(happenedAt))
+	})
+}
+
+func (d *Dispatcher) SetOverdraftLimit(
+	ctx context.Context,
+	id uuid.UUID,
+	currency values.Currency,
+	limit decimal.Decimal,
+	happenedAt time.Time,
+) error {
+	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) ([]event_store.Event, error) {
+		return event_store.One(aggr.SetOverdraftLimit(currency, limit, happenedAt))
+	})
+}
+
+func (d *Dispatcher) RemoveOverdraftLimit(
+	ctx context.Context,
+	id uuid.UUID,
+	currency values.Currency,
+	happenedAt time.Time,
+) error {
+	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) ([]event_store.Event, error) {
+		return event_store.One(aggr.RemoveOverdraftLimit(currency, happenedAt))
+	})
+}
+
+func (d *Dispatcher) Update(
+	ctx context.Context,
+	id uuid.UUID,
+	update Update,
+	happenedAt time.Time,
+) error {
+	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) ([]event_store.Event, error) {
+		return aggr.Update(update, happenedAt)
+	})
+}
+
+func (d *Dispatcher) SetStatementCycle(
+	ctx context.Context,
+	id uuid.UUID,
+	cycle StatementCycle,
+	happenedAt time.Time,
+) error {
+	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) ([]event_store.Event, error) {
+		return event_store.One(aggr.SetStatementCycle(cycle, happenedAt))
+	})
+}
+
+// StatementLedger tells the balance of an account from the money moved before
+// a point in time.
+type StatementLedger interface {
+	BalanceBefore(ctx context.Context, accountID uuid.UUID, currency values.Currency, before time.Time) (decimal.Decimal, error)
+}
+
+// CloseStatement closes the last statement of a credit card closing on or
+// before asOf, billing what is owed on it according to the ledger.
+func (d *Dispatcher) CloseStatement(
+	ctx context.Context,
+	id uuid.UUID,
+	asOf time.Time,
+	ledger StatementLedger,
+	happenedAt time.Time,
+) error {
+	return d.es.Execute(ctx, id, func(aggr *Account, version uint64) ([]event_store.Event, error) {
+		if aggr.StatementCycle == nil {
+			// The command tells why there is no statement to close.
+			return event_store.One(aggr.CloseStatement(asOf, decimal.Zero, happenedAt))
+		}
+
+		closingDate := aggr.StatementCycle.ClosingDate(asOf)
+		balance, err := ledger.BalanceBefore(ctx, id, aggr.Currency, closingDate.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, err
+		}
+
+		return event_store.One(aggr.CloseStatement(closingDate, balance.Neg(), happenedAt))
+	})
+}
+
+// Version returns the current version of the account, 0 if it does not exist.
+func (d *Dispatcher) Version(ctx context.Context, id uuid.UUID) (uint64, error) {
+	_, version, err := d.es.GetAggregate(ctx, id)
+	return version, err
+}
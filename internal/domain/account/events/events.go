@@ -13,6 +13,18 @@ const (
 	TypeNameUpdated    string = "AccountNameUpdated"
 	TypeMoneyDeposited string = "MoneyDeposited"
 	TypeMoneyWithdrawn string = "MoneyWithdrawn"
+	TypeMoneyPosted    string = "MoneyPosted"
+	TypeClosed         string = "AccountClosed"
+	TypeReopened       string = "AccountReopened"
+
+	TypeOverdraftLimitSet     string = "OverdraftLimitSet"
+	TypeOverdraftLimitRemoved string = "OverdraftLimitRemoved"
+
+	TypeKindChanged    string = "AccountKindChanged"
+	TypeDetailsUpdated string = "AccountDetailsUpdated"
+
+	TypeStatementCycleSet string = "StatementCycleSet"
+	TypeStatementClosed   string = "StatementClosed"
 )
 
 type Opened struct {
@@ -79,3 +91,151 @@ func (e MoneyWithdrawn) Type() string {
 func (e MoneyWithdrawn) Content() any {
 	return e
 }
+
+// MoneyPosted records the money a transaction moved on the account, so that
+// the balance of the account holds it. The transaction itself is what the
+// projections apply.
+type MoneyPosted struct {
+	AccountID     uuid.UUID
+	TransactionID uuid.UUID
+	Currency      values.Currency
+	Amount        decimal.Decimal
+	Side          values.Side
+	HappenedAt    time.Time
+}
+
+func (e MoneyPosted) Type() string {
+	return TypeMoneyPosted
+}
+
+func (e MoneyPosted) Content() any {
+	return e
+}
+
+type Closed struct {
+	AccountID  uuid.UUID
+	HappenedAt time.Time
+}
+
+func (e Closed) Type() string {
+	return TypeClosed
+}
+
+func (e Closed) Content() any {
+	return e
+}
+
+type Reopened struct {
+	AccountID  uuid.UUID
+	HappenedAt time.Time
+}
+
+func (e Reopened) Type() string {
+	return TypeReopened
+}
+
+func (e Reopened) Content() any {
+	return e
+}
+
+// OverdraftLimitSet sets how far below zero the balance of the account in the
+// currency can go. A limit of zero allows no overdraft at all.
+type OverdraftLimitSet struct {
+	AccountID  uuid.UUID
+	Currency   values.Currency
+	Limit      decimal.Decimal
+	HappenedAt time.Time
+}
+
+func (e OverdraftLimitSet) Type() string {
+	return TypeOverdraftLimitSet
+}
+
+func (e OverdraftLimitSet) Content() any {
+	return e
+}
+
+// OverdraftLimitRemoved lets the balance of the account in the currency go
+// below zero without limits again.
+type OverdraftLimitRemoved struct {
+	AccountID  uuid.UUID
+	Currency   values.Currency
+	HappenedAt time.Time
+}
+
+func (e OverdraftLimitRemoved) Type() string {
+	return TypeOverdraftLimitRemoved
+}
+
+func (e OverdraftLimitRemoved) Content() any {
+	return e
+}
+
+type KindChanged struct {
+	AccountID  uuid.UUID
+	Kind       values.AccountKind
+	HappenedAt time.Time
+}
+
+func (e KindChanged) Type() string {
+	return TypeKindChanged
+}
+
+func (e KindChanged) Content() any {
+	return e
+}
+
+// DetailsUpdated replaces all the details of the account at once.
+type DetailsUpdated struct {
+	AccountID  uuid.UUID
+	Details    values.AccountDetails
+	HappenedAt time.Time
+}
+
+func (e DetailsUpdated) Type() string {
+	return TypeDetailsUpdated
+}
+
+func (e DetailsUpdated) Content() any {
+	return e
+}
+
+// StatementCycleSet sets the days a credit card statement closes and is due
+// every month.
+type StatementCycleSet struct {
+	AccountID          uuid.UUID
+	ClosingDay         int
+	DueDay             int
+	MinimumPaymentRate decimal.Decimal
+	HappenedAt         time.Time
+}
+
+func (e StatementCycleSet) Type() string {
+	return TypeStatementCycleSet
+}
+
+func (e StatementCycleSet) Content() any {
+	return e
+}
+
+// StatementClosed bills what is owed on a credit card at the end of the day
+// of ClosingDate. The statement covers the days after PeriodStart, which is
+// zero for the first statement.
+type StatementClosed struct {
+	AccountID   uuid.UUID
+	Currency    values.Currency
+	PeriodStart time.Time
+	ClosingDate time.Time
+	DueDate     time.Time
+	Balance     decimal.Decimal
+	MinimumDue  decimal.Decimal
+	HappenedAt  time.Time
+}
+
+func (e StatementClosed) Type() string {
+	return TypeStatementClosed
+}
+
+func (e StatementClosed) Content() any {
+	return e
+}
@@ -0,0 +1,33 @@
+package events
+
+import "github.com/somatom98/brokeli/pkg/event_store"
+
+// Factory builds the value each stored account event is decoded into.
+func Factory() map[string]func() any {
+	return map[string]func() any{
+		TypeOpened:         func() any { return &Opened{} },
+		TypeNameUpdated:    func() any { return &NameUpdated{} },
+		TypeMoneyDeposited: func() any { return &MoneyDeposited{} },
+		TypeMoneyWithdrawn: func() any { return &MoneyWithdrawn{} },
+		TypeMoneyPosted:    func() any { return &MoneyPosted{} },
+		TypeClosed:         func() any { return &Closed{} },
+		TypeReopened:       func() any { return &Reopened{} },
+
+		TypeOverdraftLimitSet:     func() any { return &OverdraftLimitSet{} },
+		TypeOverdraftLimitRemoved: func() any { return &OverdraftLimitRemoved{} },
+
+		TypeKindChanged:    func() any { return &KindChanged{} },
+		TypeDetailsUpdated: func() any { return &DetailsUpdated{} },
+
+		TypeStatementCycleSet: func() any { return &StatementCycleSet{} },
+		TypeStatementClosed:   func() any { return &StatementClosed{} },
+	}
+}
+
+// Upcasters migrates the payloads stored by older versions of the account
+// events. Changing the shape of an event requires registering the step from
+// its previous schema version here, together with a fixture of that version
+// in testdata.
+func Upcasters() *event_store.Upcasters {
+	return event_store.NewUpcasters()
+}
@@ -0,0 +1,179 @@
+package events_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/account/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+// The fixtures in testdata/v<N> are payloads as they were stored with schema
+// version N. They must keep decoding into the current events.
+func TestHistoricalPayloads(t *testing.T) {
+	accountID := uuid.MustParse("6f1c2a5e-3b1d-4c7a-9e1f-2a3b4c5d6e7f")
+
+	tests := []struct {
+		fixture  string
+		expected event_store.Event
+	}{
+		{
+			fixture: "v1/AccountOpened.json",
+			expected: events.Opened{
+				AccountID:  accountID,
+				Name:       "Checking",
+				Currency:   "EUR",
+				HappenedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/AccountNameUpdated.json",
+			expected: events.NameUpdated{
+				AccountID:  accountID,
+				Name:       "Main checking",
+				HappenedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/MoneyDeposited.json",
+			expected: events.MoneyDeposited{
+				AccountID:   accountID,
+				Currency:    "EUR",
+				Amount:      decimal.RequireFromString("2500"),
+				Category:    "Salary",
+				Description: "March salary",
+				User:        "marco",
+				HappenedAt:  time.Date(2024, 3, 27, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/MoneyWithdrawn.json",
+			expected: events.MoneyWithdrawn{
+				AccountID:   accountID,
+				Currency:    "EUR",
+				Amount:      decimal.RequireFromString("42.5"),
+				Category:    "Groceries",
+				Description: "Weekly shopping",
+				User:        "marco",
+				HappenedAt:  time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/MoneyPosted.json",
+			expected: events.MoneyPosted{
+				AccountID:     accountID,
+				TransactionID: uuid.MustParse("3c9d8e7f-6a5b-4c4d-9e3f-2a1b0c9d8e7f"),
+				Currency:      "EUR",
+				Amount:        decimal.RequireFromString("42.5"),
+				Side:          values.Side_Debit,
+				HappenedAt:    time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/AccountClosed.json",
+			expected: events.Closed{
+				AccountID:  accountID,
+				HappenedAt: time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/AccountReopened.json",
+			expected: events.Reopened{
+				AccountID:  accountID,
+				HappenedAt: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/OverdraftLimitSet.json",
+			expected: events.OverdraftLimitSet{
+				AccountID:  accountID,
+				Currency:   "EUR",
+				Limit:      decimal.RequireFromString("500"),
+				HappenedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/OverdraftLimitRemoved.json",
+			expected: events.OverdraftLimitRemoved{
+				AccountID:  accountID,
+				Currency:   "EUR",
+				HappenedAt: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/AccountKindChanged.json",
+			expected: events.KindChanged{
+				AccountID:  accountID,
+				Kind:       "SAVINGS",
+				HappenedAt: time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/AccountDetailsUpdated.json",
+			expected: events.DetailsUpdated{
+				AccountID: accountID,
+				Details: values.AccountDetails{
+					Institution:   "Fineco",
+					AccountNumber: "*******************0100",
+					Owner:         "marco",
+					Icon:          "piggy-bank",
+					Color:         "#2e7d32",
+					Tags:          []string{"emergency", "joint"},
+				},
+				HappenedAt: time.Date(2024, 8, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/StatementCycleSet.json",
+			expected: events.StatementCycleSet{
+				AccountID:          accountID,
+				ClosingDay:         25,
+				DueDay:             10,
+				MinimumPaymentRate: decimal.RequireFromString("0.05"),
+				HappenedAt:         time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/StatementClosed.json",
+			expected: events.StatementClosed{
+				AccountID:   accountID,
+				Currency:    "EUR",
+				ClosingDate: time.Date(2024, 9, 25, 0, 0, 0, 0, time.UTC),
+				DueDate:     time.Date(2024, 10, 10, 0, 0, 0, 0, time.UTC),
+				Balance:     decimal.RequireFromString("812.4"),
+				MinimumDue:  decimal.RequireFromString("40.62"),
+				HappenedAt:  time.Date(2024, 9, 26, 8, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	t.Run("should have a fixture for every event type", func(t *testing.T) {
+		for eventType := range events.Factory() {
+			_, err := os.Stat(filepath.Join("testdata", "v1", eventType+".json"))
+			assert.NoError(t, err, eventType)
+		}
+	})
+
+	for _, tt := range tests {
+		t.Run("should decode "+tt.fixture+" into the current event", func(t *testing.T) {
+			// arrange
+			data, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			require.NoError(t, err)
+
+			// act
+			content, err := event_store.UnmarshalEvent(events.Factory(), events.Upcasters(), tt.expected.Type(), 1, data)
+
+			// assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, content)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package account
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+// snapshotVersion is bumped whenever the state folded into snapshots changes,
+// so that older snapshots are ignored and the events replayed instead.
+const snapshotVersion = 3
+
+type snapshot struct {
+	Version         int
+	ID              uuid.UUID
+	State           State
+	Currency        values.Currency
+	Balance         map[values.Currency]decimal.Decimal
+	OverdraftLimits map[values.Currency]decimal.Decimal
+	Kind            values.AccountKind
+	Details         values.AccountDetails
+	StatementCycle  *StatementCycle
+	LastClosingDate time.Time
+}
+
+func (a *Account) Snapshot() ([]byte, error) {
+	return json.Marshal(snapshot{
+		Version:         snapshotVersion,
+		ID:              a.ID,
+		State:           a.State,
+		Currency:        a.Currency,
+		Balance:         a.Balance,
+		OverdraftLimits: a.OverdraftLimits,
+		Kind:            a.Kind,
+		Details:         a.Details,
+		StatementCycle:  a.StatementCycle,
+		LastClosingDate: a.LastClosingDate,
+	})
+}
+
+func (a *Account) Restore(data []byte) error {
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	if s.Version != snapshotVersion {
+		return fmt.Errorf("snapshot version %d, expected %d", s.Version, snapshotVersion)
+	}
+
+	a.ID = s.ID
+	a.State = s.State
+	a.Currency = s.Currency
+	a.Balance = make(map[values.Currency]decimal.Decimal)
+	maps.Copy(a.Balance, s.Balance)
+	a.OverdraftLimits = make(map[values.Currency]decimal.Decimal)
+	maps.Copy(a.OverdraftLimits, s.OverdraftLimits)
+	a.Kind = s.Kind
+	a.Details = s.Details
+	a.StatementCycle = s.StatementCycle
+	a.LastClosingDate = s.LastClosingDate
+
+	return nil
+}
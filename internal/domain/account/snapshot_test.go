@@ -0,0 +1,63 @@
+package account_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Run("should restore the state it was taken from", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		acc := account.New(id)
+		acc.State = account.State_Opened
+		acc.Balance["EUR"] = decimal.RequireFromString("120.5")
+		acc.OverdraftLimits["EUR"] = decimal.RequireFromString("500")
+		acc.Kind = values.AccountKind_Checking
+		acc.Details = values.AccountDetails{Institution: "Fineco", Tags: []string{"joint"}}
+		acc.Currency = "EUR"
+		acc.StatementCycle = &account.StatementCycle{ClosingDay: 25, DueDay: 10, MinimumPaymentRate: decimal.RequireFromString("0.05")}
+		acc.LastClosingDate = time.Date(2024, 9, 25, 0, 0, 0, 0, time.UTC)
+
+		// act
+		data, err := acc.Snapshot()
+		require.NoError(t, err)
+
+		restored := account.New(uuid.Nil)
+		err = restored.Restore(data)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, acc, restored)
+	})
+
+	t.Run("should return error when the snapshot predates the balances", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+
+		// act
+		err := acc.Restore([]byte(`{"ID":"6f1c2a5e-3b1d-4c7a-9e1f-2a3b4c5d6e7f","State":1}`))
+
+		// assert
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error when data is not a snapshot", func(t *testing.T) {
+		// arrange
+		acc := account.New(uuid.New())
+
+		// act
+		err := acc.Restore([]byte("not json"))
+
+		// assert
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,60 @@
+package account
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// StatementCycle is the monthly billing cycle of a credit card. Days are
+// capped at 28 so that every month has them.
+type StatementCycle struct {
+	ClosingDay int
+	DueDay     int
+	// MinimumPaymentRate is the share of the statement balance due at the
+	// least, the whole balance being due when it is zero.
+	MinimumPaymentRate decimal.Decimal
+}
+
+func (c StatementCycle) IsValid() bool {
+	return c.ClosingDay >= 1 && c.ClosingDay <= 28 &&
+		c.DueDay >= 1 && c.DueDay <= 28 &&
+		!c.MinimumPaymentRate.IsNegative() && c.MinimumPaymentRate.LessThanOrEqual(decimal.NewFromInt(1))
+}
+
+func (c StatementCycle) Equal(other StatementCycle) bool {
+	return c.ClosingDay == other.ClosingDay &&
+		c.DueDay == other.DueDay &&
+		c.MinimumPaymentRate.Equal(other.MinimumPaymentRate)
+}
+
+// ClosingDate returns the date of the last statement closing on or before t.
+func (c StatementCycle) ClosingDate(t time.Time) time.Time {
+	closing := time.Date(t.Year(), t.Month(), c.ClosingDay, 0, 0, 0, 0, time.UTC)
+	if closing.After(t) {
+		closing = closing.AddDate(0, -1, 0)
+	}
+	return closing
+}
+
+// DueDate returns the date the statement closing on closingDate is due,
+// which falls in the following month unless the due day comes after the
+// closing one.
+func (c StatementCycle) DueDate(closingDate time.Time) time.Time {
+	due := time.Date(closingDate.Year(), closingDate.Month(), c.DueDay, 0, 0, 0, 0, time.UTC)
+	if c.DueDay <= c.ClosingDay {
+		due = due.AddDate(0, 1, 0)
+	}
+	return due
+}
+
+// MinimumDue returns the least to be paid of a statement balance.
+func (c StatementCycle) MinimumDue(balance decimal.Decimal) decimal.Decimal {
+	if !balance.IsPositive() {
+		return decimal.Zero
+	}
+	if c.MinimumPaymentRate.IsZero() {
+		return balance
+	}
+	return balance.Mul(c.MinimumPaymentRate).RoundCeil(2)
+}
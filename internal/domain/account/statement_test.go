@@ -0,0 +1,77 @@
+package account_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/somatom98/brokeli/internal/domain/account"
+)
+
+func TestStatementCycle(t *testing.T) {
+	cycle := account.StatementCycle{ClosingDay: 25, DueDay: 10, MinimumPaymentRate: decimal.RequireFromString("0.05")}
+
+	t.Run("should close on the closing day of the same month", func(t *testing.T) {
+		// act
+		closing := cycle.ClosingDate(time.Date(2024, 9, 25, 18, 0, 0, 0, time.UTC))
+
+		// assert
+		assert.Equal(t, time.Date(2024, 9, 25, 0, 0, 0, 0, time.UTC), closing)
+	})
+
+	t.Run("should close on the closing day of the previous month", func(t *testing.T) {
+		// act
+		closing := cycle.ClosingDate(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC))
+
+		// assert
+		assert.Equal(t, time.Date(2023, 12, 25, 0, 0, 0, 0, time.UTC), closing)
+	})
+
+	t.Run("should be due in the following month", func(t *testing.T) {
+		// act
+		due := cycle.DueDate(time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC))
+
+		// assert
+		assert.Equal(t, time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), due)
+	})
+
+	t.Run("should be due in the same month when the due day comes later", func(t *testing.T) {
+		// arrange
+		cycle := account.StatementCycle{ClosingDay: 5, DueDay: 20}
+
+		// act
+		due := cycle.DueDate(time.Date(2024, 9, 5, 0, 0, 0, 0, time.UTC))
+
+		// assert
+		assert.Equal(t, time.Date(2024, 9, 20, 0, 0, 0, 0, time.UTC), due)
+	})
+
+	t.Run("should round the minimum due up to the cent", func(t *testing.T) {
+		// act
+		minimum := cycle.MinimumDue(decimal.RequireFromString("812.45"))
+
+		// assert
+		assert.Equal(t, "40.63", minimum.String())
+	})
+
+	t.Run("should make the whole balance due without a minimum payment rate", func(t *testing.T) {
+		// arrange
+		cycle := account.StatementCycle{ClosingDay: 25, DueDay: 10}
+
+		// act
+		minimum := cycle.MinimumDue(decimal.RequireFromString("812.45"))
+
+		// assert
+		assert.Equal(t, "812.45", minimum.String())
+	})
+
+	t.Run("should make nothing due on a credit balance", func(t *testing.T) {
+		// act
+		minimum := cycle.MinimumDue(decimal.NewFromInt(-10))
+
+		// assert
+		assert.True(t, minimum.IsZero())
+	})
+}
@@ -0,0 +1,132 @@
+package fx
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+// amountPlaces is the number of decimal places converted amounts are rounded
+// to.
+const amountPlaces = 2
+
+// Converter converts amounts between currencies at the rate of a date. Rates
+// are looked up for the pair of currencies either way round, or else crossed
+// through a currency both are quoted against.
+type Converter struct {
+	repository Repository
+}
+
+func NewConverter(repository Repository) *Converter {
+	return &Converter{
+		repository: repository,
+	}
+}
+
+// Convert returns what an amount in a currency is worth in another on a date,
+// or ErrNotFound if there is no rate to convert it with.
+func (c *Converter) Convert(ctx context.Context, amount decimal.Decimal, from, to values.Currency, on time.Time) (decimal.Decimal, error) {
+	if from == to {
+		return amount, nil
+	}
+
+	rate, err := c.Rate(ctx, from, to, on)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	return amount.Mul(rate).Round(amountPlaces), nil
+}
+
+// Rate returns what one unit of a currency is worth in another on a date, or
+// ErrNotFound if they are not quoted against each other nor against a common
+// currency.
+func (c *Converter) Rate(ctx context.Context, from, to values.Currency, on time.Time) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+
+	fromRates, err := c.latest(ctx, from, on)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	if direct, ok := fromRates[to]; ok {
+		return direct.rate, nil
+	}
+
+	toRates, err := c.latest(ctx, to, on)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	// cross through the common currency quoted most recently against both
+	var cross *crossRate
+	for currency, fromRate := range fromRates {
+		toRate, ok := toRates[currency]
+		if !ok {
+			continue
+		}
+
+		candidate := crossRate{
+			currency: currency,
+			rate:     fromRate.rate.Div(toRate.rate),
+			date:     fromRate.date,
+		}
+		if toRate.date.Before(candidate.date) {
+			candidate.date = toRate.date
+		}
+		if cross == nil || candidate.isBetter(*cross) {
+			cross = &candidate
+		}
+	}
+	if cross == nil {
+		return decimal.Decimal{}, ErrNotFound
+	}
+
+	return cross.rate, nil
+}
+
+type quotedRate struct {
+	rate decimal.Decimal
+	date time.Time
+}
+
+type crossRate struct {
+	currency values.Currency
+	rate     decimal.Decimal
+	date     time.Time
+}
+
+func (r crossRate) isBetter(other crossRate) bool {
+	if !r.date.Equal(other.date) {
+		return r.date.After(other.date)
+	}
+	return strings.Compare(string(r.currency), string(other.currency)) < 0
+}
+
+// latest returns what one unit of a currency is worth in each of the
+// currencies it is quoted against on a date.
+func (c *Converter) latest(ctx context.Context, currency values.Currency, on time.Time) (map[values.Currency]quotedRate, error) {
+	rates, err := c.repository.Latest(ctx, currency, on)
+	if err != nil {
+		return nil, err
+	}
+
+	quoted := make(map[values.Currency]quotedRate, len(rates))
+	for _, r := range rates {
+		other, rate := r.Quote, r.Rate
+		if r.Quote == currency {
+			other, rate = r.Base, decimal.NewFromInt(1).Div(r.Rate)
+		}
+
+		// a pair can be quoted either way round, the latest quote wins
+		if existing, ok := quoted[other]; ok && existing.date.After(r.Date) {
+			continue
+		}
+		quoted[other] = quotedRate{rate: rate, date: r.Date}
+	}
+	return quoted, nil
+}
@@ -0,0 +1,82 @@
+package fx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/fx"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConverter_Convert(t *testing.T) {
+	ctx := context.Background()
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+	eur, dkk, usd := values.Currency("EUR"), values.Currency("DKK"), values.Currency("USD")
+
+	// arrange
+	repository := fx.NewInMemoryRepository()
+	assert.NoError(t, repository.Save(ctx,
+		fx.Rate{Base: eur, Quote: dkk, Date: day(1, 1), Rate: decimal.RequireFromString("7.46")},
+		fx.Rate{Base: eur, Quote: dkk, Date: day(2, 1), Rate: decimal.RequireFromString("7.45")},
+		fx.Rate{Base: eur, Quote: usd, Date: day(1, 1), Rate: decimal.RequireFromString("1.1")},
+	))
+	converter := fx.NewConverter(repository)
+
+	t.Run("should leave amounts in the same currency as they are", func(t *testing.T) {
+		// act
+		amount, err := converter.Convert(ctx, decimal.NewFromInt(100), eur, eur, day(1, 1).AddDate(-1, 0, 0))
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "100", amount.String())
+	})
+
+	t.Run("should convert at the latest rate on or before the date", func(t *testing.T) {
+		// act
+		january, err := converter.Convert(ctx, decimal.NewFromInt(100), eur, dkk, day(1, 31))
+		assert.NoError(t, err)
+		february, err := converter.Convert(ctx, decimal.NewFromInt(100), eur, dkk, day(2, 1))
+		assert.NoError(t, err)
+
+		// assert
+		assert.Equal(t, "746", january.String())
+		assert.Equal(t, "745", february.String())
+	})
+
+	t.Run("should convert at the inverse rate", func(t *testing.T) {
+		// act
+		amount, err := converter.Convert(ctx, decimal.NewFromInt(746), dkk, eur, day(1, 15))
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "100", amount.String())
+	})
+
+	t.Run("should cross the rates through a common currency", func(t *testing.T) {
+		// act
+		amount, err := converter.Convert(ctx, decimal.NewFromInt(746), dkk, usd, day(1, 15))
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "110", amount.String())
+	})
+
+	t.Run("should fail without a rate on or before the date", func(t *testing.T) {
+		// act
+		_, err := converter.Convert(ctx, decimal.NewFromInt(100), eur, dkk, day(1, 1).AddDate(0, 0, -1))
+
+		// assert
+		assert.ErrorIs(t, err, fx.ErrNotFound)
+	})
+
+	t.Run("should fail for currencies not quoted at all", func(t *testing.T) {
+		// act
+		_, err := converter.Convert(ctx, decimal.NewFromInt(100), eur, values.Currency("SEK"), day(1, 15))
+
+		// assert
+		assert.ErrorIs(t, err, fx.ErrNotFound)
+	})
+}
@@ -0,0 +1,111 @@
+package fx
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+// FileProvider reads rates from a CSV or a JSON file, as its extension tells.
+// CSV files have a base,quote,date,rate header, and JSON files hold an array
+// of objects with the same fields. Dates are quoted as 2006-01-02.
+type FileProvider struct {
+	path string
+}
+
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{
+		path: path,
+	}
+}
+
+func (p *FileProvider) Rates(ctx context.Context, from, to time.Time) ([]Rate, error) {
+	file, err := os.Open(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("open rates file: %w", err)
+	}
+	defer file.Close()
+
+	var rates []Rate
+	switch strings.ToLower(filepath.Ext(p.path)) {
+	case ".csv":
+		rates, err = readCSV(file)
+	case ".json":
+		rates, err = readJSON(file)
+	default:
+		return nil, fmt.Errorf("unsupported rates file: %s", p.path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read rates file: %w", err)
+	}
+
+	return within(rates, from, to), nil
+}
+
+func readCSV(r io.Reader) ([]Rate, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"base", "quote", "date", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing %s column", name)
+		}
+	}
+
+	rates := make([]Rate, 0, len(records)-1)
+	for line, record := range records[1:] {
+		amount, err := decimal.NewFromString(record[columns["rate"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, err)
+		}
+
+		rate, err := quote{
+			Base:  values.Currency(record[columns["base"]]),
+			Quote: values.Currency(record[columns["quote"]]),
+			Date:  record[columns["date"]],
+			Rate:  amount,
+		}.toRate()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+func readJSON(r io.Reader) ([]Rate, error) {
+	var quotes []quote
+	if err := json.NewDecoder(r).Decode(&quotes); err != nil {
+		return nil, err
+	}
+
+	rates := make([]Rate, 0, len(quotes))
+	for _, q := range quotes {
+		rate, err := q.toRate()
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
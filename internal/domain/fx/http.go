@@ -0,0 +1,25 @@
+package fx
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+// BaseCurrency returns the currency set in the base_currency query parameter,
+// which the amounts are converted to, or the empty one to leave them as they
+// are.
+func BaseCurrency(r *http.Request) values.Currency {
+	return values.Currency(r.URL.Query().Get("base_currency"))
+}
+
+// WriteError responds to a request whose amounts could not be converted to
+// the base currency.
+func WriteError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	http.Error(w, "internal error", http.StatusInternalServerError)
+}
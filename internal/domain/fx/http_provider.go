@@ -0,0 +1,72 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPProvider fetches rates from an HTTP endpoint, sending the dates as the
+// from and to query parameters, and reading back a JSON array of objects with
+// the base, quote, date and rate fields.
+type HTTPProvider struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPProvider(url string, client *http.Client) *HTTPProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &HTTPProvider{
+		url:    url,
+		client: client,
+	}
+}
+
+func (p *HTTPProvider) Rates(ctx context.Context, from, to time.Time) ([]Rate, error) {
+	endpoint, err := url.Parse(p.url)
+	if err != nil {
+		return nil, fmt.Errorf("parse rates url: %w", err)
+	}
+
+	query := endpoint.Query()
+	query.Set("from", from.Format(time.DateOnly))
+	query.Set("to", to.Format(time.DateOnly))
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch rates: unexpected status %d", resp.StatusCode)
+	}
+
+	var quotes []quote
+	if err := json.NewDecoder(resp.Body).Decode(&quotes); err != nil {
+		return nil, fmt.Errorf("decode rates: %w", err)
+	}
+
+	rates := make([]Rate, 0, len(quotes))
+	for _, q := range quotes {
+		rate, err := q.toRate()
+		if err != nil {
+			return nil, fmt.Errorf("decode rates: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+
+	return within(rates, from, to), nil
+}
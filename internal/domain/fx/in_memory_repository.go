@@ -0,0 +1,63 @@
+package fx
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+type pair struct {
+	base  values.Currency
+	quote values.Currency
+}
+
+type InMemoryRepository struct {
+	rates map[pair][]Rate
+}
+
+func NewInMemoryRepository() *InMemoryRepository {
+	return &InMemoryRepository{
+		rates: make(map[pair][]Rate),
+	}
+}
+
+func (r *InMemoryRepository) Save(ctx context.Context, rates ...Rate) error {
+	for _, rate := range rates {
+		rate.Date = Day(rate.Date)
+		key := pair{rate.Base, rate.Quote}
+
+		pairRates := slices.DeleteFunc(r.rates[key], func(existing Rate) bool {
+			return existing.Date.Equal(rate.Date)
+		})
+		pairRates = append(pairRates, rate)
+		slices.SortFunc(pairRates, func(a, b Rate) int {
+			return a.Date.Compare(b.Date)
+		})
+		r.rates[key] = pairRates
+	}
+	return nil
+}
+
+func (r *InMemoryRepository) Latest(ctx context.Context, currency values.Currency, on time.Time) ([]Rate, error) {
+	on = Day(on)
+
+	latest := make([]Rate, 0)
+	for key, pairRates := range r.rates {
+		if key.base != currency && key.quote != currency {
+			continue
+		}
+		for i := len(pairRates) - 1; i >= 0; i-- {
+			if !pairRates[i].Date.After(on) {
+				latest = append(latest, pairRates[i])
+				break
+			}
+		}
+	}
+	return latest, nil
+}
+
+func (r *InMemoryRepository) List(ctx context.Context, base, quote values.Currency, from, to time.Time) ([]Rate, error) {
+	return within(r.rates[pair{base, quote}], Day(from), Day(to)), nil
+}
@@ -0,0 +1,91 @@
+package fx
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/db"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+type PostgresRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewPostgresRepository(dbConn *sql.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *PostgresRepository) Save(ctx context.Context, rates ...Rate) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	for _, rate := range rates {
+		err := qtx.UpsertFXRate(ctx, db.UpsertFXRateParams{
+			Base:  string(rate.Base),
+			Quote: string(rate.Quote),
+			Date:  Day(rate.Date),
+			Rate:  rate.Rate.String(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresRepository) Latest(ctx context.Context, currency values.Currency, on time.Time) ([]Rate, error) {
+	rows, err := r.queries.ListLatestFXRates(ctx, db.ListLatestFXRatesParams{
+		Currency: string(currency),
+		OnDate:   Day(on),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toRates(rows)
+}
+
+func (r *PostgresRepository) List(ctx context.Context, base, quote values.Currency, from, to time.Time) ([]Rate, error) {
+	rows, err := r.queries.ListFXRates(ctx, db.ListFXRatesParams{
+		Base:     string(base),
+		Quote:    string(quote),
+		FromDate: Day(from),
+		ToDate:   Day(to),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toRates(rows)
+}
+
+func toRates(rows []db.FxRate) ([]Rate, error) {
+	rates := make([]Rate, 0, len(rows))
+	for _, row := range rows {
+		amount, err := decimal.NewFromString(row.Rate)
+		if err != nil {
+			return nil, err
+		}
+
+		rates = append(rates, Rate{
+			Base:  values.Currency(row.Base),
+			Quote: values.Currency(row.Quote),
+			Date:  row.Date,
+			Rate:  amount,
+		})
+	}
+	return rates, nil
+}
@@ -0,0 +1,110 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+var (
+	ErrNotFound    = errors.New("rate_not_found")
+	ErrInvalidRate = errors.New("invalid_rate")
+)
+
+// Rate is what one unit of the base currency is worth in the quote currency
+// on a date.
+type Rate struct {
+	Base  values.Currency `json:"base"`
+	Quote values.Currency `json:"quote"`
+	Date  time.Time       `json:"date"`
+	Rate  decimal.Decimal `json:"rate"`
+}
+
+func (r Rate) Validate() error {
+	if r.Base == "" || r.Quote == "" || r.Base == r.Quote || r.Date.IsZero() || !r.Rate.IsPositive() {
+		return ErrInvalidRate
+	}
+	return nil
+}
+
+type Repository interface {
+	// Save inserts the rates, replacing the ones of the same currencies and
+	// date.
+	Save(ctx context.Context, rates ...Rate) error
+	// Latest returns, for every pair of currencies including the given one,
+	// its rate on the latest date on or before the given one.
+	Latest(ctx context.Context, currency values.Currency, on time.Time) ([]Rate, error)
+	// List returns the rates of a pair of currencies between two dates
+	// included, oldest first.
+	List(ctx context.Context, base, quote values.Currency, from, to time.Time) ([]Rate, error)
+}
+
+// RateProvider fetches the exchange rates between two dates from a source
+// outside of the application.
+type RateProvider interface {
+	Rates(ctx context.Context, from, to time.Time) ([]Rate, error)
+}
+
+// Fetch stores the rates the provider has between two dates, and returns how
+// many it stored.
+func Fetch(ctx context.Context, provider RateProvider, repository Repository, from, to time.Time) (int, error) {
+	rates, err := provider.Rates(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, r := range rates {
+		if err := r.Validate(); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := repository.Save(ctx, rates...); err != nil {
+		return 0, err
+	}
+
+	return len(rates), nil
+}
+
+// Day returns the date of t, at midnight UTC.
+func Day(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// quote is a rate as the providers exchange it, quoted on a date.
+type quote struct {
+	Base  values.Currency `json:"base"`
+	Quote values.Currency `json:"quote"`
+	Date  string          `json:"date"`
+	Rate  decimal.Decimal `json:"rate"`
+}
+
+func (q quote) toRate() (Rate, error) {
+	date, err := time.Parse(time.DateOnly, q.Date)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	return Rate{
+		Base:  q.Base,
+		Quote: q.Quote,
+		Date:  date,
+		Rate:  q.Rate,
+	}, nil
+}
+
+// within keeps the rates between two dates included.
+func within(rates []Rate, from, to time.Time) []Rate {
+	kept := make([]Rate, 0, len(rates))
+	for _, r := range rates {
+		if r.Date.Before(from) || r.Date.After(to) {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept
+}
@@ -0,0 +1,91 @@
+package loan
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/loan/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+type State int
+
+const (
+	State_Unopened State = iota
+	State_Active
+	State_PaidOff
+)
+
+type Loan struct {
+	ID         uuid.UUID
+	State      State
+	Name       string
+	Currency   values.Currency
+	Principal  decimal.Decimal
+	AnnualRate decimal.Decimal
+	TermMonths int
+	StartDate  time.Time
+	// RemainingPrincipal is the principal not repaid yet.
+	RemainingPrincipal decimal.Decimal
+	InterestPaid       decimal.Decimal
+	Payments           int
+}
+
+func New(id uuid.UUID) *Loan {
+	return &Loan{
+		ID:    id,
+		State: State_Unopened,
+	}
+}
+
+func (l *Loan) Hydrate(records []event_store.Record) error {
+	for _, record := range records {
+		switch record.Type() {
+		case events.TypeOpened:
+			event, err := event_store.DecodeEvent[events.Opened](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode Opened event: %w", err)
+			}
+			l.ApplyOpened(event)
+		case events.TypePaymentRecorded:
+			event, err := event_store.DecodeEvent[events.PaymentRecorded](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode PaymentRecorded event: %w", err)
+			}
+			l.ApplyPaymentRecorded(event)
+		case events.TypePaidOff:
+			event, err := event_store.DecodeEvent[events.PaidOff](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode PaidOff event: %w", err)
+			}
+			l.ApplyPaidOff(event)
+		}
+	}
+
+	return nil
+}
+
+func (l *Loan) ApplyOpened(event events.Opened) {
+	l.ID = event.LoanID
+	l.State = State_Active
+	l.Name = event.Name
+	l.Currency = event.Currency
+	l.Principal = event.Principal
+	l.AnnualRate = event.AnnualRate
+	l.TermMonths = event.TermMonths
+	l.StartDate = event.StartDate
+	l.RemainingPrincipal = event.Principal
+}
+
+func (l *Loan) ApplyPaymentRecorded(event events.PaymentRecorded) {
+	l.RemainingPrincipal = event.RemainingPrincipal
+	l.InterestPaid = l.InterestPaid.Add(event.Interest)
+	l.Payments++
+}
+
+func (l *Loan) ApplyPaidOff(event events.PaidOff) {
+	l.State = State_PaidOff
+}
@@ -0,0 +1,102 @@
+package loan
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/loan/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+var (
+	ErrLoanNotOpened           = errors.New("loan_not_opened")
+	ErrLoanPaidOff             = errors.New("loan_paid_off")
+	ErrInvalidTerms            = errors.New("invalid_terms")
+	ErrNegativeOrNullAmount    = errors.New("negative_or_null_amount")
+	ErrPaymentBelowInterest    = errors.New("payment_below_interest")
+	ErrPaymentExceedsPrincipal = errors.New("payment_exceeds_principal")
+)
+
+func (l *Loan) Open(
+	name string,
+	currency values.Currency,
+	principal decimal.Decimal,
+	annualRate decimal.Decimal,
+	termMonths int,
+	startDate time.Time,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if l.State != State_Unopened {
+		return nil, nil
+	}
+
+	if !principal.IsPositive() || annualRate.IsNegative() || termMonths <= 0 {
+		return nil, ErrInvalidTerms
+	}
+
+	return &events.Opened{
+		LoanID:     l.ID,
+		Name:       name,
+		Currency:   currency,
+		Principal:  principal,
+		AnnualRate: annualRate,
+		TermMonths: termMonths,
+		StartDate:  startDate,
+		HappenedAt: happenedAt,
+	}, nil
+}
+
+// RecordPayment pays the interest accrued for the month on the remaining
+// principal first, and repays the principal with the rest of the amount. The
+// loan is paid off once no principal remains.
+func (l *Loan) RecordPayment(
+	accountID uuid.UUID,
+	amount decimal.Decimal,
+	happenedAt time.Time,
+) (evts []event_store.Event, err error) {
+	if l.State == State_Unopened {
+		return nil, ErrLoanNotOpened
+	}
+
+	if l.State == State_PaidOff {
+		return nil, ErrLoanPaidOff
+	}
+
+	if !amount.IsPositive() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	interest := MonthlyInterest(l.RemainingPrincipal, l.AnnualRate)
+	if amount.LessThan(interest) {
+		return nil, ErrPaymentBelowInterest
+	}
+
+	principal := amount.Sub(interest)
+	if principal.GreaterThan(l.RemainingPrincipal) {
+		return nil, ErrPaymentExceedsPrincipal
+	}
+
+	remaining := l.RemainingPrincipal.Sub(principal)
+	evts = append(evts, &events.PaymentRecorded{
+		LoanID:             l.ID,
+		AccountID:          accountID,
+		Currency:           l.Currency,
+		Amount:             amount,
+		Principal:          principal,
+		Interest:           interest,
+		RemainingPrincipal: remaining,
+		HappenedAt:         happenedAt,
+	})
+
+	if remaining.IsZero() {
+		evts = append(evts, &events.PaidOff{
+			LoanID:     l.ID,
+			HappenedAt: happenedAt,
+		})
+	}
+
+	return evts, nil
+}
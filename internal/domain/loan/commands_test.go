@@ -0,0 +1,177 @@
+package loan_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/loan"
+	"github.com/somatom98/brokeli/internal/domain/loan/events"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+func TestOpen(t *testing.T) {
+	now := time.Now()
+	startDate := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should emit loan opened event", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		l := loan.New(id)
+
+		// act
+		evt, err := l.Open("Mortgage", "EUR", decimal.NewFromInt(150000), decimal.RequireFromString("0.036"), 300, startDate, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.Opened{
+			LoanID:     id,
+			Name:       "Mortgage",
+			Currency:   "EUR",
+			Principal:  decimal.NewFromInt(150000),
+			AnnualRate: decimal.RequireFromString("0.036"),
+			TermMonths: 300,
+			StartDate:  startDate,
+			HappenedAt: now,
+		}, evt)
+	})
+
+	t.Run("should do nothing when the loan is already opened", func(t *testing.T) {
+		// arrange
+		l := loan.New(uuid.New())
+		l.State = loan.State_Active
+
+		// act
+		evt, err := l.Open("Mortgage", "EUR", decimal.NewFromInt(150000), decimal.RequireFromString("0.036"), 300, startDate, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when the terms are invalid", func(t *testing.T) {
+		// arrange
+		l := loan.New(uuid.New())
+
+		// act
+		evt, err := l.Open("Mortgage", "EUR", decimal.NewFromInt(150000), decimal.RequireFromString("0.036"), 0, startDate, now)
+
+		// assert
+		require.ErrorIs(t, err, loan.ErrInvalidTerms)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestRecordPayment(t *testing.T) {
+	now := time.Now()
+	accountID := uuid.New()
+
+	active := func(id uuid.UUID, remaining string) *loan.Loan {
+		l := loan.New(id)
+		l.State = loan.State_Active
+		l.Currency = "EUR"
+		l.AnnualRate = decimal.RequireFromString("0.12")
+		l.RemainingPrincipal = decimal.RequireFromString(remaining)
+		return l
+	}
+
+	t.Run("should split the payment into interest and principal", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		l := active(id, "1200")
+
+		// act
+		evts, err := l.RecordPayment(accountID, decimal.RequireFromString("106.62"), now)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, evts, 1)
+		payment, ok := evts[0].(*events.PaymentRecorded)
+		require.True(t, ok)
+		assert.Equal(t, id, payment.LoanID)
+		assert.Equal(t, accountID, payment.AccountID)
+		assert.Equal(t, "12", payment.Interest.String())
+		assert.Equal(t, "94.62", payment.Principal.String())
+		assert.Equal(t, "1105.38", payment.RemainingPrincipal.String())
+	})
+
+	t.Run("should pay off the loan with the last payment", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		l := active(id, "100")
+
+		// act
+		evts, err := l.RecordPayment(accountID, decimal.NewFromInt(101), now)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, evts, 2)
+		assert.Equal(t, []event_store.Event{
+			&events.PaymentRecorded{
+				LoanID:             id,
+				AccountID:          accountID,
+				Currency:           "EUR",
+				Amount:             decimal.NewFromInt(101),
+				Principal:          evts[0].(*events.PaymentRecorded).Principal,
+				Interest:           evts[0].(*events.PaymentRecorded).Interest,
+				RemainingPrincipal: evts[0].(*events.PaymentRecorded).RemainingPrincipal,
+				HappenedAt:         now,
+			},
+			&events.PaidOff{LoanID: id, HappenedAt: now},
+		}, evts)
+		assert.True(t, evts[0].(*events.PaymentRecorded).RemainingPrincipal.IsZero())
+	})
+
+	t.Run("should return error when the payment does not cover the interest", func(t *testing.T) {
+		// arrange
+		l := active(uuid.New(), "1200")
+
+		// act
+		evts, err := l.RecordPayment(accountID, decimal.NewFromInt(10), now)
+
+		// assert
+		require.ErrorIs(t, err, loan.ErrPaymentBelowInterest)
+		assert.Empty(t, evts)
+	})
+
+	t.Run("should return error when the payment exceeds the remaining principal", func(t *testing.T) {
+		// arrange
+		l := active(uuid.New(), "100")
+
+		// act
+		evts, err := l.RecordPayment(accountID, decimal.NewFromInt(200), now)
+
+		// assert
+		require.ErrorIs(t, err, loan.ErrPaymentExceedsPrincipal)
+		assert.Empty(t, evts)
+	})
+
+	t.Run("should return error when the loan is paid off", func(t *testing.T) {
+		// arrange
+		l := active(uuid.New(), "0")
+		l.State = loan.State_PaidOff
+
+		// act
+		evts, err := l.RecordPayment(accountID, decimal.NewFromInt(100), now)
+
+		// assert
+		require.ErrorIs(t, err, loan.ErrLoanPaidOff)
+		assert.Empty(t, evts)
+	})
+
+	t.Run("should return error when the loan is not opened", func(t *testing.T) {
+		// arrange
+		l := loan.New(uuid.New())
+
+		// act
+		evts, err := l.RecordPayment(accountID, decimal.NewFromInt(100), now)
+
+		// assert
+		require.ErrorIs(t, err, loan.ErrLoanNotOpened)
+		assert.Empty(t, evts)
+	})
+}
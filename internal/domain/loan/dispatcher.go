@@ -0,0 +1,130 @@
+package loan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/loan/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+// userSystem is the user the account movements of a payment are made by.
+const userSystem = "system"
+
+const (
+	// CategoryInterest is the expense category of the interest paid on loans.
+	CategoryInterest = "Loan interest"
+	// CategoryPrincipal is the category of the withdrawals repaying loans.
+	CategoryPrincipal = "Loan principal"
+)
+
+// Expenses registers the interest paid on a loan as an expense of the account
+// it was paid from.
+type Expenses interface {
+	RegisterExpense(ctx context.Context, id uuid.UUID, accountID uuid.UUID, currency values.Currency, amount decimal.Decimal, category string, description string, happenedAt time.Time) error
+}
+
+type Dispatcher struct {
+	es       event_store.Store[*Loan]
+	accounts event_store.Store[*account.Account]
+	expenses Expenses
+	uow      event_store.UnitOfWork
+}
+
+func NewDispatcher(
+	es event_store.Store[*Loan],
+	accounts event_store.Store[*account.Account],
+	expenses Expenses,
+	uow event_store.UnitOfWork,
+) *Dispatcher {
+	return &Dispatcher{
+		es:       es,
+		accounts: accounts,
+		expenses: expenses,
+		uow:      uow,
+	}
+}
+
+func (d *Dispatcher) Open(
+	ctx context.Context,
+	id uuid.UUID,
+	name string,
+	currency values.Currency,
+	principal decimal.Decimal,
+	annualRate decimal.Decimal,
+	termMonths int,
+	startDate time.Time,
+	happenedAt time.Time,
+) error {
+	return d.es.Execute(ctx, id, func(aggr *Loan, version uint64) ([]event_store.Event, error) {
+		return event_store.One(aggr.Open(name, currency, principal, annualRate, termMonths, startDate, happenedAt))
+	})
+}
+
+// RecordPayment pays the loan out of an account. The interest is registered
+// as an expense of the account and the principal withdrawn from it, in the
+// same unit of work as the payment.
+func (d *Dispatcher) RecordPayment(
+	ctx context.Context,
+	id uuid.UUID,
+	accountID uuid.UUID,
+	amount decimal.Decimal,
+	happenedAt time.Time,
+) error {
+	paymentID := uuid.New()
+	metadata := event_store.MetadataFrom(ctx)
+	if metadata.CorrelationID == uuid.Nil {
+		metadata.CorrelationID = paymentID
+	}
+	ctx = event_store.WithMetadata(ctx, metadata)
+
+	return d.uow.Do(ctx, func(ctx context.Context) error {
+		var payment *events.PaymentRecorded
+		var name string
+		var number int
+		err := d.es.Execute(ctx, id, func(aggr *Loan, version uint64) ([]event_store.Event, error) {
+			evts, err := aggr.RecordPayment(accountID, amount, happenedAt)
+			if err != nil {
+				return nil, err
+			}
+			payment = evts[0].(*events.PaymentRecorded)
+			name, number = aggr.Name, aggr.Payments+1
+			evts[0] = event_store.WithID(paymentID, evts[0])
+			return evts, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		ctx = event_store.CausedBy(ctx, event_store.Record{ID: paymentID, Metadata: metadata})
+
+		if payment.Interest.IsPositive() {
+			expenseID := uuid.NewMD5(id, []byte(fmt.Sprintf("interest_%d", number)))
+			err = d.expenses.RegisterExpense(ctx, expenseID, accountID, payment.Currency, payment.Interest, CategoryInterest, name, happenedAt)
+			if err != nil {
+				return fmt.Errorf("failed to register interest: %w", err)
+			}
+		}
+
+		if payment.Principal.IsPositive() {
+			err = d.accounts.Execute(ctx, accountID, func(aggr *account.Account, version uint64) ([]event_store.Event, error) {
+				return event_store.One(aggr.Withdraw(payment.Currency, payment.Principal, CategoryPrincipal, name, userSystem, happenedAt))
+			})
+			if err != nil {
+				return fmt.Errorf("failed to withdraw principal: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Get returns the loan as of its latest event, along with its version.
+func (d *Dispatcher) Get(ctx context.Context, id uuid.UUID) (*Loan, uint64, error) {
+	return d.es.GetAggregate(ctx, id)
+}
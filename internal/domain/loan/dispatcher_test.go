@@ -0,0 +1,100 @@
+package loan_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/account"
+	account_events "github.com/somatom98/brokeli/internal/domain/account/events"
+	"github.com/somatom98/brokeli/internal/domain/loan"
+	"github.com/somatom98/brokeli/internal/domain/loan/events"
+	"github.com/somatom98/brokeli/internal/domain/position"
+	"github.com/somatom98/brokeli/internal/domain/transaction"
+	transaction_events "github.com/somatom98/brokeli/internal/domain/transaction/events"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+func TestDispatcher_RecordPayment(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	setup := func(t *testing.T) (*loan.Dispatcher, *event_store.InMemoryStore[*loan.Loan], *event_store.InMemoryStore[*transaction.Transaction], *event_store.InMemoryStore[*account.Account], uuid.UUID, uuid.UUID) {
+		loanES := event_store.NewInMemory(loan.New)
+		transactionES := event_store.NewInMemory(transaction.New)
+		accountES := event_store.NewInMemory(account.New)
+		uow := event_store.NewInMemoryUnitOfWork()
+		dispatcher := loan.NewDispatcher(loanES, accountES, transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), uow), uow)
+
+		loanID, accountID := uuid.New(), uuid.New()
+		require.NoError(t, account.NewDispatcher(accountES).Open(ctx, accountID, "Checking", "EUR", now))
+		require.NoError(t, dispatcher.Open(ctx, loanID, "Car", "EUR", decimal.NewFromInt(1200), decimal.RequireFromString("0.12"), 12, now, now))
+
+		return dispatcher, loanES, transactionES, accountES, loanID, accountID
+	}
+
+	t.Run("should register the interest as an expense and withdraw the principal", func(t *testing.T) {
+		// arrange
+		dispatcher, loanES, transactionES, accountES, loanID, accountID := setup(t)
+
+		// act
+		err := dispatcher.RecordPayment(ctx, loanID, accountID, decimal.RequireFromString("106.62"), now)
+
+		// assert
+		require.NoError(t, err)
+
+		payments, err := loanES.ReadFrom(ctx, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, payments, 1)
+		assert.Equal(t, events.TypePaymentRecorded, payments[0].Type())
+
+		expenses, err := transactionES.ReadFrom(ctx, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, expenses, 1)
+		expense := expenses[0].Content().(transaction_events.MoneySpent)
+		assert.Equal(t, accountID, expense.AccountID)
+		assert.Equal(t, loan.CategoryInterest, expense.Category)
+		assert.Equal(t, "12", expense.Amount.String())
+		assert.Equal(t, payments[0].ID, expenses[0].Metadata.CausationID)
+
+		movements, err := accountES.ReadFrom(ctx, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, movements, 2)
+		posting := movements[0].Content().(account_events.MoneyPosted)
+		assert.Equal(t, expenses[0].AggregateID, posting.TransactionID)
+		assert.Equal(t, "12", posting.Amount.String())
+		assert.Equal(t, expenses[0].ID, movements[0].Metadata.CausationID)
+		withdrawal := movements[1].Content().(account_events.MoneyWithdrawn)
+		assert.Equal(t, loan.CategoryPrincipal, withdrawal.Category)
+		assert.Equal(t, "94.62", withdrawal.Amount.String())
+
+		aggr, _, err := dispatcher.Get(ctx, loanID)
+		require.NoError(t, err)
+		assert.Equal(t, "1105.38", aggr.RemainingPrincipal.String())
+	})
+
+	t.Run("should record nothing when the principal cannot be withdrawn", func(t *testing.T) {
+		// arrange
+		dispatcher, loanES, transactionES, accountES, loanID, accountID := setup(t)
+		require.NoError(t, account.NewDispatcher(accountES).SetOverdraftLimit(ctx, accountID, "EUR", decimal.Zero, now))
+
+		// act
+		err := dispatcher.RecordPayment(ctx, loanID, accountID, decimal.RequireFromString("106.62"), now)
+
+		// assert
+		require.ErrorIs(t, err, account.ErrInsufficientFunds)
+
+		payments, err := loanES.ReadFrom(ctx, 1, 10)
+		require.NoError(t, err)
+		assert.Empty(t, payments)
+
+		expenses, err := transactionES.ReadFrom(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, expenses)
+	})
+}
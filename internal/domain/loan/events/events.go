@@ -0,0 +1,70 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+const (
+	TypeOpened          string = "LoanOpened"
+	TypePaymentRecorded string = "LoanPaymentRecorded"
+	TypePaidOff         string = "LoanPaidOff"
+)
+
+// Opened takes out a loan of Principal, repaid monthly over TermMonths at the
+// AnnualRate from the month after StartDate.
+type Opened struct {
+	LoanID     uuid.UUID
+	Name       string
+	Currency   values.Currency
+	Principal  decimal.Decimal
+	AnnualRate decimal.Decimal
+	TermMonths int
+	StartDate  time.Time
+	HappenedAt time.Time
+}
+
+func (e Opened) Type() string {
+	return TypeOpened
+}
+
+func (e Opened) Content() any {
+	return e
+}
+
+// PaymentRecorded pays Amount of a loan out of an account, split into the
+// interest accrued for the month and the principal repaid.
+type PaymentRecorded struct {
+	LoanID             uuid.UUID
+	AccountID          uuid.UUID
+	Currency           values.Currency
+	Amount             decimal.Decimal
+	Principal          decimal.Decimal
+	Interest           decimal.Decimal
+	RemainingPrincipal decimal.Decimal
+	HappenedAt         time.Time
+}
+
+func (e PaymentRecorded) Type() string {
+	return TypePaymentRecorded
+}
+
+func (e PaymentRecorded) Content() any {
+	return e
+}
+
+type PaidOff struct {
+	LoanID     uuid.UUID
+	HappenedAt time.Time
+}
+
+func (e PaidOff) Type() string {
+	return TypePaidOff
+}
+
+func (e PaidOff) Content() any {
+	return e
+}
@@ -0,0 +1,20 @@
+package events
+
+import "github.com/somatom98/brokeli/pkg/event_store"
+
+// Factory builds the value each stored loan event is decoded into.
+func Factory() map[string]func() any {
+	return map[string]func() any{
+		TypeOpened:          func() any { return &Opened{} },
+		TypePaymentRecorded: func() any { return &PaymentRecorded{} },
+		TypePaidOff:         func() any { return &PaidOff{} },
+	}
+}
+
+// Upcasters migrates the payloads stored by older versions of the loan
+// events. Changing the shape of an event requires registering the step from
+// its previous schema version here, together with a fixture of that version
+// in testdata.
+func Upcasters() *event_store.Upcasters {
+	return event_store.NewUpcasters()
+}
@@ -0,0 +1,83 @@
+package events_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/loan/events"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+// The fixtures in testdata/v<N> are payloads as they were stored with schema
+// version N. They must keep decoding into the current events.
+func TestHistoricalPayloads(t *testing.T) {
+	loanID := uuid.MustParse("0b8f6c1e-7d2a-4e5b-9c3d-1a2b3c4d5e6f")
+
+	tests := []struct {
+		fixture  string
+		expected event_store.Event
+	}{
+		{
+			fixture: "v1/LoanOpened.json",
+			expected: events.Opened{
+				LoanID:     loanID,
+				Name:       "Mortgage",
+				Currency:   "EUR",
+				Principal:  decimal.RequireFromString("150000"),
+				AnnualRate: decimal.RequireFromString("0.036"),
+				TermMonths: 300,
+				StartDate:  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+				HappenedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/LoanPaymentRecorded.json",
+			expected: events.PaymentRecorded{
+				LoanID:             loanID,
+				AccountID:          uuid.MustParse("6f1c2a5e-3b1d-4c7a-9e1f-2a3b4c5d6e7f"),
+				Currency:           "EUR",
+				Amount:             decimal.RequireFromString("759.01"),
+				Principal:          decimal.RequireFromString("309.01"),
+				Interest:           decimal.RequireFromString("450"),
+				RemainingPrincipal: decimal.RequireFromString("149690.99"),
+				HappenedAt:         time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/LoanPaidOff.json",
+			expected: events.PaidOff{
+				LoanID:     loanID,
+				HappenedAt: time.Date(2049, 3, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	t.Run("should have a fixture for every event type", func(t *testing.T) {
+		for eventType := range events.Factory() {
+			_, err := os.Stat(filepath.Join("testdata", "v1", eventType+".json"))
+			assert.NoError(t, err, eventType)
+		}
+	})
+
+	for _, tt := range tests {
+		t.Run("should decode "+tt.fixture+" into the current event", func(t *testing.T) {
+			// arrange
+			data, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			require.NoError(t, err)
+
+			// act
+			content, err := event_store.UnmarshalEvent(events.Factory(), events.Upcasters(), tt.expected.Type(), 1, data)
+
+			// assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, content)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package loan
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var monthsInYear = decimal.NewFromInt(12)
+
+// Installment is a monthly payment of an amortization schedule.
+type Installment struct {
+	Number             int             `json:"number"`
+	DueDate            time.Time       `json:"due_date"`
+	Payment            decimal.Decimal `json:"payment"`
+	Principal          decimal.Decimal `json:"principal"`
+	Interest           decimal.Decimal `json:"interest"`
+	RemainingPrincipal decimal.Decimal `json:"remaining_principal"`
+}
+
+// MonthlyInterest returns the interest accrued in a month on the principal,
+// rounded to the cent.
+func MonthlyInterest(principal decimal.Decimal, annualRate decimal.Decimal) decimal.Decimal {
+	return principal.Mul(annualRate).Div(monthsInYear).Round(2)
+}
+
+// MonthlyPayment returns the constant monthly payment repaying the principal
+// over the term, rounded to the cent.
+func MonthlyPayment(principal decimal.Decimal, annualRate decimal.Decimal, termMonths int) decimal.Decimal {
+	if termMonths <= 0 {
+		return principal
+	}
+
+	if annualRate.IsZero() {
+		return principal.Div(decimal.NewFromInt(int64(termMonths))).RoundCeil(2)
+	}
+
+	rate := annualRate.Div(monthsInYear)
+	growth := decimal.NewFromInt(1).Add(rate).Pow(decimal.NewFromInt(int64(termMonths)))
+	return principal.Mul(rate).Mul(growth).Div(growth.Sub(decimal.NewFromInt(1))).Round(2)
+}
+
+// Amortize returns the schedule repaying the principal in equal monthly
+// installments, the first one due a month after the start date. The last
+// installment makes up for the rounding of the others.
+func Amortize(principal decimal.Decimal, annualRate decimal.Decimal, termMonths int, startDate time.Time) []Installment {
+	payment := MonthlyPayment(principal, annualRate, termMonths)
+
+	schedule := make([]Installment, 0, termMonths)
+	remaining := principal
+	for number := 1; number <= termMonths && remaining.IsPositive(); number++ {
+		interest := MonthlyInterest(remaining, annualRate)
+		repaid := payment.Sub(interest)
+		if number == termMonths || repaid.GreaterThan(remaining) {
+			repaid = remaining
+		}
+		remaining = remaining.Sub(repaid)
+
+		schedule = append(schedule, Installment{
+			Number:             number,
+			DueDate:            startDate.AddDate(0, number, 0),
+			Payment:            repaid.Add(interest),
+			Principal:          repaid,
+			Interest:           interest,
+			RemainingPrincipal: remaining,
+		})
+	}
+
+	return schedule
+}
+
+// Schedule returns the amortization schedule of the loan as it was taken out.
+func (l *Loan) Schedule() []Installment {
+	return Amortize(l.Principal, l.AnnualRate, l.TermMonths, l.StartDate)
+}
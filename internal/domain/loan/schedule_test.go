@@ -0,0 +1,47 @@
+package loan_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/loan"
+)
+
+func TestAmortize(t *testing.T) {
+	startDate := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should repay the principal in equal monthly installments", func(t *testing.T) {
+		// act
+		schedule := loan.Amortize(decimal.NewFromInt(1200), decimal.RequireFromString("0.12"), 12, startDate)
+
+		// assert
+		require.Len(t, schedule, 12)
+		assert.Equal(t, time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), schedule[0].DueDate)
+		assert.Equal(t, "106.62", schedule[0].Payment.String())
+		assert.Equal(t, "12", schedule[0].Interest.String())
+		assert.Equal(t, "94.62", schedule[0].Principal.String())
+		assert.Equal(t, "1105.38", schedule[0].RemainingPrincipal.String())
+
+		repaid := decimal.Zero
+		for _, installment := range schedule {
+			repaid = repaid.Add(installment.Principal)
+		}
+		assert.Equal(t, "1200", repaid.String())
+		assert.True(t, schedule[11].RemainingPrincipal.IsZero())
+	})
+
+	t.Run("should split the principal evenly without interest", func(t *testing.T) {
+		// act
+		schedule := loan.Amortize(decimal.NewFromInt(1000), decimal.Zero, 3, startDate)
+
+		// assert
+		require.Len(t, schedule, 3)
+		assert.Equal(t, "333.34", schedule[0].Payment.String())
+		assert.Equal(t, "333.32", schedule[2].Payment.String())
+		assert.True(t, schedule[2].RemainingPrincipal.IsZero())
+	})
+}
@@ -0,0 +1,111 @@
+package position
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/position/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+// Lot is the units of a purchase still held, at the price they were bought.
+type Lot struct {
+	TransactionID uuid.UUID
+	Units         decimal.Decimal
+	Price         decimal.Decimal
+	AcquiredAt    time.Time
+}
+
+// Position is the units of a ticker held on an account and what they cost,
+// which sales are checked and costed against. Lots are kept oldest first.
+type Position struct {
+	ID        uuid.UUID
+	AccountID uuid.UUID
+	Ticker    string
+	Currency  values.Currency
+	Units     decimal.Decimal
+	CostBasis decimal.Decimal
+	Lots      []Lot
+}
+
+// ID returns the ID of the position of a ticker on an account.
+func ID(accountID uuid.UUID, ticker string) uuid.UUID {
+	return uuid.NewMD5(accountID, []byte(ticker))
+}
+
+func New(id uuid.UUID) *Position {
+	return &Position{
+		ID: id,
+	}
+}
+
+func (p *Position) Hydrate(records []event_store.Record) error {
+	for _, record := range records {
+		switch record.Type() {
+		case events.TypeBought:
+			event, err := event_store.DecodeEvent[events.Bought](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode Bought event: %w", err)
+			}
+			p.ApplyBought(event)
+		case events.TypeSold:
+			event, err := event_store.DecodeEvent[events.Sold](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode Sold event: %w", err)
+			}
+			p.ApplySold(event)
+		case events.TypeSplit:
+			event, err := event_store.DecodeEvent[events.Split](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode Split event: %w", err)
+			}
+			p.ApplySplit(event)
+		}
+	}
+
+	return nil
+}
+
+func (p *Position) ApplyBought(event events.Bought) {
+	p.AccountID = event.AccountID
+	p.Ticker = event.Ticker
+	if p.Currency == "" {
+		p.Currency = event.Currency
+	}
+	p.Lots = append(p.Lots, Lot{
+		TransactionID: event.TransactionID,
+		Units:         event.Units,
+		Price:         event.Price,
+		AcquiredAt:    event.HappenedAt,
+	})
+	p.Units = p.Units.Add(event.Units)
+	p.CostBasis = p.CostBasis.Add(event.Units.Mul(event.Price))
+}
+
+func (p *Position) ApplySold(event events.Sold) {
+	remaining := event.Units
+	lots := make([]Lot, 0, len(p.Lots))
+	for _, lot := range p.Lots {
+		taken := decimal.Min(remaining, lot.Units)
+		remaining = remaining.Sub(taken)
+		lot.Units = lot.Units.Sub(taken)
+		if lot.Units.IsPositive() {
+			lots = append(lots, lot)
+		}
+	}
+
+	p.Lots = lots
+	p.Units = p.Units.Sub(event.Units)
+	p.CostBasis = p.CostBasis.Sub(event.CostBasis)
+}
+
+func (p *Position) ApplySplit(event events.Split) {
+	for i := range p.Lots {
+		p.Lots[i].Units = p.Lots[i].Units.Mul(event.Ratio)
+		p.Lots[i].Price = p.Lots[i].Price.Div(event.Ratio)
+	}
+	p.Units = p.Units.Mul(event.Ratio)
+}
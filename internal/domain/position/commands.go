@@ -0,0 +1,132 @@
+package position
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/position/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+var (
+	ErrNegativeOrNullAmount = errors.New("negative_or_null_amount")
+	ErrInsufficientUnits    = errors.New("insufficient_units")
+)
+
+// CostBasisMethod tells what the units taken out of a position by a sale cost.
+type CostBasisMethod string
+
+const (
+	// CostBasisMethod_FIFO costs the units sold at the price of the oldest
+	// lots still held.
+	CostBasisMethod_FIFO CostBasisMethod = "FIFO"
+	// CostBasisMethod_Average costs the units sold at the average cost of the
+	// position.
+	CostBasisMethod_Average CostBasisMethod = "AVERAGE"
+)
+
+func (m CostBasisMethod) IsValid() bool {
+	return slices.Contains([]CostBasisMethod{CostBasisMethod_FIFO, CostBasisMethod_Average}, m)
+}
+
+// Buy adds the units bought by the transaction as a new lot.
+func (p *Position) Buy(
+	accountID uuid.UUID,
+	ticker string,
+	transactionID uuid.UUID,
+	units decimal.Decimal,
+	price decimal.Decimal,
+	currency values.Currency,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if !units.IsPositive() || !price.IsPositive() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	return &events.Bought{
+		AccountID:     accountID,
+		Ticker:        ticker,
+		TransactionID: transactionID,
+		Units:         units,
+		Price:         price,
+		Currency:      currency,
+		HappenedAt:    happenedAt,
+	}, nil
+}
+
+// Sell takes the units sold by the transaction out of the position, costing
+// them according to method. It returns ErrInsufficientUnits if fewer units are
+// held.
+func (p *Position) Sell(
+	transactionID uuid.UUID,
+	units decimal.Decimal,
+	method CostBasisMethod,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if !units.IsPositive() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	if units.GreaterThan(p.Units) {
+		return nil, ErrInsufficientUnits
+	}
+
+	return &events.Sold{
+		AccountID:     p.AccountID,
+		Ticker:        p.Ticker,
+		TransactionID: transactionID,
+		Units:         units,
+		CostBasis:     p.costOf(units, method),
+		HappenedAt:    happenedAt,
+	}, nil
+}
+
+// Split multiplies the units held by ratio, leaving their cost unchanged. A
+// position holding no lots is left as it is.
+func (p *Position) Split(
+	transactionID uuid.UUID,
+	ratio decimal.Decimal,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if !ratio.IsPositive() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	if len(p.Lots) == 0 {
+		return nil, nil
+	}
+
+	return &events.Split{
+		AccountID:     p.AccountID,
+		Ticker:        p.Ticker,
+		TransactionID: transactionID,
+		Ratio:         ratio,
+		HappenedAt:    happenedAt,
+	}, nil
+}
+
+func (p *Position) costOf(units decimal.Decimal, method CostBasisMethod) decimal.Decimal {
+	if !p.Units.IsPositive() {
+		return decimal.Zero
+	}
+
+	if method == CostBasisMethod_Average {
+		return p.CostBasis.Mul(units).Div(p.Units)
+	}
+
+	cost := decimal.Zero
+	remaining := units
+	for _, lot := range p.Lots {
+		if !remaining.IsPositive() {
+			break
+		}
+		taken := decimal.Min(remaining, lot.Units)
+		cost = cost.Add(taken.Mul(lot.Price))
+		remaining = remaining.Sub(taken)
+	}
+	return cost
+}
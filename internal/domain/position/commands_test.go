@@ -0,0 +1,139 @@
+package position_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/position"
+	"github.com/somatom98/brokeli/internal/domain/position/events"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+func TestSell(t *testing.T) {
+	now := time.Now()
+	accountID := uuid.New()
+
+	// held: 10 units bought at 100, then 10 at 130.
+	held := func(t *testing.T) *position.Position {
+		p := position.New(position.ID(accountID, "VWCE"))
+		for i, price := range []int64{100, 130} {
+			evt, err := p.Buy(accountID, "VWCE", uuid.New(), decimal.NewFromInt(10), decimal.NewFromInt(price), "EUR", now.AddDate(0, 0, i))
+			require.NoError(t, err)
+			require.NoError(t, p.Hydrate([]event_store.Record{{Event: evt}}))
+		}
+		return p
+	}
+
+	t.Run("should cost the units sold at the oldest lots with FIFO", func(t *testing.T) {
+		// arrange
+		p := held(t)
+		transactionID := uuid.New()
+
+		// act
+		evt, err := p.Sell(transactionID, decimal.NewFromInt(15), position.CostBasisMethod_FIFO, now)
+
+		// assert
+		require.NoError(t, err)
+		sold, ok := evt.(*events.Sold)
+		require.True(t, ok)
+		assert.Equal(t, accountID, sold.AccountID)
+		assert.Equal(t, "VWCE", sold.Ticker)
+		assert.Equal(t, transactionID, sold.TransactionID)
+		assert.Equal(t, "1650", sold.CostBasis.String())
+	})
+
+	t.Run("should cost the units sold at the average cost with AVERAGE", func(t *testing.T) {
+		// arrange
+		p := held(t)
+
+		// act
+		evt, err := p.Sell(uuid.New(), decimal.NewFromInt(15), position.CostBasisMethod_Average, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "1725", evt.(*events.Sold).CostBasis.String())
+	})
+
+	t.Run("should take the units sold out of the oldest lots", func(t *testing.T) {
+		// arrange
+		p := held(t)
+		evt, err := p.Sell(uuid.New(), decimal.NewFromInt(15), position.CostBasisMethod_FIFO, now)
+		require.NoError(t, err)
+
+		// act
+		err = p.Hydrate([]event_store.Record{{Event: evt}})
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "5", p.Units.String())
+		assert.Equal(t, "650", p.CostBasis.String())
+		require.Len(t, p.Lots, 1)
+		assert.Equal(t, "5", p.Lots[0].Units.String())
+		assert.Equal(t, "130", p.Lots[0].Price.String())
+	})
+
+	t.Run("should return error when selling more units than held", func(t *testing.T) {
+		// arrange
+		p := held(t)
+
+		// act
+		evt, err := p.Sell(uuid.New(), decimal.NewFromInt(21), position.CostBasisMethod_FIFO, now)
+
+		// assert
+		require.ErrorIs(t, err, position.ErrInsufficientUnits)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when selling a ticker never bought", func(t *testing.T) {
+		// arrange
+		p := position.New(position.ID(accountID, "NVDA"))
+
+		// act
+		evt, err := p.Sell(uuid.New(), decimal.NewFromInt(1), position.CostBasisMethod_FIFO, now)
+
+		// assert
+		require.ErrorIs(t, err, position.ErrInsufficientUnits)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestSplit(t *testing.T) {
+	now := time.Now()
+	accountID := uuid.New()
+
+	t.Run("should multiply the units of every lot and divide their price", func(t *testing.T) {
+		// arrange
+		p := position.New(position.ID(accountID, "VWCE"))
+		evt, err := p.Buy(accountID, "VWCE", uuid.New(), decimal.NewFromInt(10), decimal.NewFromInt(130), "EUR", now)
+		require.NoError(t, err)
+		require.NoError(t, p.Hydrate([]event_store.Record{{Event: evt}}))
+
+		// act
+		evt, err = p.Split(uuid.New(), decimal.NewFromInt(2), now)
+		require.NoError(t, err)
+		require.NoError(t, p.Hydrate([]event_store.Record{{Event: evt}}))
+
+		// assert
+		assert.Equal(t, "20", p.Units.String())
+		assert.Equal(t, "1300", p.CostBasis.String())
+		require.Len(t, p.Lots, 1)
+		assert.Equal(t, "65", p.Lots[0].Price.String())
+	})
+
+	t.Run("should do nothing when no lots are held", func(t *testing.T) {
+		// arrange
+		p := position.New(position.ID(accountID, "NVDA"))
+
+		// act
+		evt, err := p.Split(uuid.New(), decimal.NewFromInt(10), now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+}
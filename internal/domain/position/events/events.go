@@ -0,0 +1,72 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+const (
+	TypeBought string = "PositionBought"
+	TypeSold   string = "PositionSold"
+	TypeSplit  string = "PositionSplit"
+)
+
+// Bought adds a lot of Units bought at Price by the transaction to the position
+// of a ticker on an account.
+type Bought struct {
+	AccountID     uuid.UUID
+	Ticker        string
+	TransactionID uuid.UUID
+	Units         decimal.Decimal
+	Price         decimal.Decimal
+	Currency      values.Currency
+	HappenedAt    time.Time
+}
+
+func (e Bought) Type() string {
+	return TypeBought
+}
+
+func (e Bought) Content() any {
+	return e
+}
+
+// Sold takes Units out of the lots of a position, oldest first, at the
+// CostBasis they were sold for by the transaction.
+type Sold struct {
+	AccountID     uuid.UUID
+	Ticker        string
+	TransactionID uuid.UUID
+	Units         decimal.Decimal
+	CostBasis     decimal.Decimal
+	HappenedAt    time.Time
+}
+
+func (e Sold) Type() string {
+	return TypeSold
+}
+
+func (e Sold) Content() any {
+	return e
+}
+
+// Split multiplies the units of every lot of a position by Ratio and divides
+// their price by it.
+type Split struct {
+	AccountID     uuid.UUID
+	Ticker        string
+	TransactionID uuid.UUID
+	Ratio         decimal.Decimal
+	HappenedAt    time.Time
+}
+
+func (e Split) Type() string {
+	return TypeSplit
+}
+
+func (e Split) Content() any {
+	return e
+}
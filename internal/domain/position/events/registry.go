@@ -0,0 +1,20 @@
+package events
+
+import "github.com/somatom98/brokeli/pkg/event_store"
+
+// Factory builds the value each stored position event is decoded into.
+func Factory() map[string]func() any {
+	return map[string]func() any{
+		TypeBought: func() any { return &Bought{} },
+		TypeSold:   func() any { return &Sold{} },
+		TypeSplit:  func() any { return &Split{} },
+	}
+}
+
+// Upcasters migrates the payloads stored by older versions of the position
+// events. Changing the shape of an event requires registering the step from
+// its previous schema version here, together with a fixture of that version
+// in testdata.
+func Upcasters() *event_store.Upcasters {
+	return event_store.NewUpcasters()
+}
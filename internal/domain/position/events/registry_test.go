@@ -0,0 +1,83 @@
+package events_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/position/events"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+// The fixtures in testdata/v<N> are payloads as they were stored with schema
+// version N. They must keep decoding into the current events.
+func TestHistoricalPayloads(t *testing.T) {
+	accountID := uuid.MustParse("6f1c2a5e-3b1d-4c7a-9e1f-2a3b4c5d6e7f")
+
+	tests := []struct {
+		fixture  string
+		expected event_store.Event
+	}{
+		{
+			fixture: "v1/PositionBought.json",
+			expected: events.Bought{
+				AccountID:     accountID,
+				Ticker:        "VWCE",
+				TransactionID: uuid.MustParse("0b8f6c1e-7d2a-4e5b-9c3d-1a2b3c4d5e6f"),
+				Units:         decimal.RequireFromString("10"),
+				Price:         decimal.RequireFromString("100"),
+				Currency:      "EUR",
+				HappenedAt:    time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/PositionSold.json",
+			expected: events.Sold{
+				AccountID:     accountID,
+				Ticker:        "VWCE",
+				TransactionID: uuid.MustParse("1c9a7d2f-8e3b-4f6c-ad4e-2b3c4d5e6f70"),
+				Units:         decimal.RequireFromString("15"),
+				CostBasis:     decimal.RequireFromString("1650"),
+				HappenedAt:    time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/PositionSplit.json",
+			expected: events.Split{
+				AccountID:     accountID,
+				Ticker:        "VWCE",
+				TransactionID: uuid.MustParse("2da8be30-9f4c-4a7d-be5f-3c4d5e6f7081"),
+				Ratio:         decimal.RequireFromString("2"),
+				HappenedAt:    time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	t.Run("should have a fixture for every event type", func(t *testing.T) {
+		for eventType := range events.Factory() {
+			_, err := os.Stat(filepath.Join("testdata", "v1", eventType+".json"))
+			assert.NoError(t, err, eventType)
+		}
+	})
+
+	for _, tt := range tests {
+		t.Run("should decode "+tt.fixture+" into the current event", func(t *testing.T) {
+			// arrange
+			data, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			require.NoError(t, err)
+
+			// act
+			content, err := event_store.UnmarshalEvent(events.Factory(), events.Upcasters(), tt.expected.Type(), 1, data)
+
+			// assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, content)
+		})
+	}
+}
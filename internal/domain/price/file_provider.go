@@ -0,0 +1,111 @@
+package price
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+// FileProvider reads prices from a CSV or a JSON file, as its extension tells.
+// CSV files have a ticker,date,price,currency header, and JSON files hold an
+// array of objects with the same fields. Dates are quoted as 2006-01-02.
+type FileProvider struct {
+	path string
+}
+
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{
+		path: path,
+	}
+}
+
+func (p *FileProvider) Prices(ctx context.Context, tickers []string, from, to time.Time) ([]Price, error) {
+	file, err := os.Open(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("open prices file: %w", err)
+	}
+	defer file.Close()
+
+	var prices []Price
+	switch strings.ToLower(filepath.Ext(p.path)) {
+	case ".csv":
+		prices, err = readCSV(file)
+	case ".json":
+		prices, err = readJSON(file)
+	default:
+		return nil, fmt.Errorf("unsupported prices file: %s", p.path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read prices file: %w", err)
+	}
+
+	return within(prices, tickers, from, to), nil
+}
+
+func readCSV(r io.Reader) ([]Price, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"ticker", "date", "price", "currency"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing %s column", name)
+		}
+	}
+
+	prices := make([]Price, 0, len(records)-1)
+	for line, record := range records[1:] {
+		amount, err := decimal.NewFromString(record[columns["price"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, err)
+		}
+
+		p, err := quote{
+			Ticker:   record[columns["ticker"]],
+			Date:     record[columns["date"]],
+			Price:    amount,
+			Currency: values.Currency(record[columns["currency"]]),
+		}.toPrice()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, err)
+		}
+		prices = append(prices, p)
+	}
+
+	return prices, nil
+}
+
+func readJSON(r io.Reader) ([]Price, error) {
+	var quotes []quote
+	if err := json.NewDecoder(r).Decode(&quotes); err != nil {
+		return nil, err
+	}
+
+	prices := make([]Price, 0, len(quotes))
+	for _, q := range quotes {
+		p, err := q.toPrice()
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+
+	return prices, nil
+}
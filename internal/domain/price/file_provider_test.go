@@ -0,0 +1,69 @@
+package price_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/somatom98/brokeli/internal/domain/price"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileProvider_Prices(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	write := func(t *testing.T, name, content string) string {
+		path := filepath.Join(t.TempDir(), name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("should read the prices of a CSV file", func(t *testing.T) {
+		// arrange
+		path := write(t, "prices.csv", "date,ticker,currency,price\n"+
+			"2024-01-10,VWCE,EUR,102\n"+
+			"2024-02-10,VWCE,EUR,104\n"+
+			"2024-01-10,AAPL,USD,185.5\n")
+
+		// act
+		prices, err := price.NewFileProvider(path).Prices(ctx, []string{"VWCE"}, from, to)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, prices, 1)
+		assert.Equal(t, "VWCE", prices[0].Ticker)
+		assert.Equal(t, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), prices[0].Date)
+		assert.Equal(t, "102", prices[0].Price.String())
+	})
+
+	t.Run("should read the prices of a JSON file", func(t *testing.T) {
+		// arrange
+		path := write(t, "prices.json", `[
+			{"ticker":"VWCE","date":"2024-01-10","price":"102","currency":"EUR"},
+			{"ticker":"AAPL","date":"2024-01-10","price":"185.5","currency":"USD"}
+		]`)
+
+		// act
+		prices, err := price.NewFileProvider(path).Prices(ctx, nil, from, to)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, prices, 2)
+		assert.Equal(t, "185.5", prices[1].Price.String())
+	})
+
+	t.Run("should fail on a CSV file missing a column", func(t *testing.T) {
+		// arrange
+		path := write(t, "prices.csv", "ticker,date,price\nVWCE,2024-01-10,102\n")
+
+		// act
+		_, err := price.NewFileProvider(path).Prices(ctx, nil, from, to)
+
+		// assert
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,74 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPProvider fetches prices from an HTTP endpoint, sending the tickers and
+// the dates as the tickers, from and to query parameters, and reading back a
+// JSON array of objects with the ticker, date, price and currency fields.
+type HTTPProvider struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPProvider(url string, client *http.Client) *HTTPProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &HTTPProvider{
+		url:    url,
+		client: client,
+	}
+}
+
+func (p *HTTPProvider) Prices(ctx context.Context, tickers []string, from, to time.Time) ([]Price, error) {
+	endpoint, err := url.Parse(p.url)
+	if err != nil {
+		return nil, fmt.Errorf("parse prices url: %w", err)
+	}
+
+	query := endpoint.Query()
+	query.Set("tickers", strings.Join(tickers, ","))
+	query.Set("from", from.Format(time.DateOnly))
+	query.Set("to", to.Format(time.DateOnly))
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch prices: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch prices: unexpected status %d", resp.StatusCode)
+	}
+
+	var quotes []quote
+	if err := json.NewDecoder(resp.Body).Decode(&quotes); err != nil {
+		return nil, fmt.Errorf("decode prices: %w", err)
+	}
+
+	prices := make([]Price, 0, len(quotes))
+	for _, q := range quotes {
+		price, err := q.toPrice()
+		if err != nil {
+			return nil, fmt.Errorf("decode prices: %w", err)
+		}
+		prices = append(prices, price)
+	}
+
+	return within(prices, tickers, from, to), nil
+}
@@ -0,0 +1,49 @@
+package price
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+type InMemoryRepository struct {
+	prices map[string][]Price
+}
+
+func NewInMemoryRepository() *InMemoryRepository {
+	return &InMemoryRepository{
+		prices: make(map[string][]Price),
+	}
+}
+
+func (r *InMemoryRepository) Save(ctx context.Context, prices ...Price) error {
+	for _, p := range prices {
+		p.Date = Day(p.Date)
+
+		tickerPrices := slices.DeleteFunc(r.prices[p.Ticker], func(existing Price) bool {
+			return existing.Date.Equal(p.Date)
+		})
+		tickerPrices = append(tickerPrices, p)
+		slices.SortFunc(tickerPrices, func(a, b Price) int {
+			return a.Date.Compare(b.Date)
+		})
+		r.prices[p.Ticker] = tickerPrices
+	}
+	return nil
+}
+
+func (r *InMemoryRepository) Latest(ctx context.Context, ticker string, on time.Time) (Price, error) {
+	on = Day(on)
+
+	tickerPrices := r.prices[ticker]
+	for i := len(tickerPrices) - 1; i >= 0; i-- {
+		if !tickerPrices[i].Date.After(on) {
+			return tickerPrices[i], nil
+		}
+	}
+	return Price{}, ErrNotFound
+}
+
+func (r *InMemoryRepository) List(ctx context.Context, ticker string, from, to time.Time) ([]Price, error) {
+	return within(r.prices[ticker], nil, Day(from), Day(to)), nil
+}
@@ -0,0 +1,99 @@
+package price
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/db"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+type PostgresRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewPostgresRepository(dbConn *sql.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *PostgresRepository) Save(ctx context.Context, prices ...Price) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	for _, p := range prices {
+		err := qtx.UpsertPrice(ctx, db.UpsertPriceParams{
+			Ticker:   p.Ticker,
+			Date:     Day(p.Date),
+			Price:    p.Price.String(),
+			Currency: string(p.Currency),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresRepository) Latest(ctx context.Context, ticker string, on time.Time) (Price, error) {
+	row, err := r.queries.GetLatestPrice(ctx, db.GetLatestPriceParams{
+		Ticker: ticker,
+		OnDate: Day(on),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Price{}, ErrNotFound
+	}
+	if err != nil {
+		return Price{}, err
+	}
+
+	return toPrice(row)
+}
+
+func (r *PostgresRepository) List(ctx context.Context, ticker string, from, to time.Time) ([]Price, error) {
+	rows, err := r.queries.ListPrices(ctx, db.ListPricesParams{
+		Ticker:   ticker,
+		FromDate: Day(from),
+		ToDate:   Day(to),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make([]Price, 0, len(rows))
+	for _, row := range rows {
+		p, err := toPrice(row)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+
+	return prices, nil
+}
+
+func toPrice(row db.Price) (Price, error) {
+	amount, err := decimal.NewFromString(row.Price)
+	if err != nil {
+		return Price{}, err
+	}
+
+	return Price{
+		Ticker:   row.Ticker,
+		Date:     row.Date,
+		Price:    amount,
+		Currency: values.Currency(row.Currency),
+	}, nil
+}
@@ -0,0 +1,117 @@
+package price
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+var (
+	ErrNotFound     = errors.New("not_found")
+	ErrInvalidPrice = errors.New("invalid_price")
+)
+
+// Price is the closing price of a ticker on a date.
+type Price struct {
+	Ticker   string          `json:"ticker"`
+	Date     time.Time       `json:"date"`
+	Price    decimal.Decimal `json:"price"`
+	Currency values.Currency `json:"currency"`
+}
+
+func (p Price) Validate() error {
+	if p.Ticker == "" || p.Currency == "" || p.Date.IsZero() || !p.Price.IsPositive() {
+		return ErrInvalidPrice
+	}
+	return nil
+}
+
+type Repository interface {
+	// Save inserts the prices, replacing the ones of the same ticker and date.
+	Save(ctx context.Context, prices ...Price) error
+	// Latest returns the price of a ticker on the latest date on or before
+	// the given one, or ErrNotFound if there is none.
+	Latest(ctx context.Context, ticker string, on time.Time) (Price, error)
+	// List returns the prices of a ticker between two dates included, oldest
+	// first.
+	List(ctx context.Context, ticker string, from, to time.Time) ([]Price, error)
+}
+
+// PriceProvider fetches the prices of tickers between two dates from a source
+// outside of the application.
+type PriceProvider interface {
+	Prices(ctx context.Context, tickers []string, from, to time.Time) ([]Price, error)
+}
+
+// Fetch stores the prices the provider has for the tickers between two dates,
+// and returns how many it stored.
+func Fetch(ctx context.Context, provider PriceProvider, repository Repository, tickers []string, from, to time.Time) (int, error) {
+	prices, err := provider.Prices(ctx, tickers, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, p := range prices {
+		if err := p.Validate(); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := repository.Save(ctx, prices...); err != nil {
+		return 0, err
+	}
+
+	return len(prices), nil
+}
+
+// Day returns the date of t, at midnight UTC.
+func Day(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// quote is a price as the providers exchange it, quoted on a date.
+type quote struct {
+	Ticker   string          `json:"ticker"`
+	Date     string          `json:"date"`
+	Price    decimal.Decimal `json:"price"`
+	Currency values.Currency `json:"currency"`
+}
+
+func (q quote) toPrice() (Price, error) {
+	date, err := time.Parse(time.DateOnly, q.Date)
+	if err != nil {
+		return Price{}, err
+	}
+
+	return Price{
+		Ticker:   q.Ticker,
+		Date:     date,
+		Price:    q.Price,
+		Currency: q.Currency,
+	}, nil
+}
+
+// within keeps the prices of the tickers between two dates included, all the
+// tickers if none is given.
+func within(prices []Price, tickers []string, from, to time.Time) []Price {
+	wanted := make(map[string]bool, len(tickers))
+	for _, ticker := range tickers {
+		wanted[ticker] = true
+	}
+
+	kept := make([]Price, 0, len(prices))
+	for _, p := range prices {
+		if len(wanted) > 0 && !wanted[p.Ticker] {
+			continue
+		}
+		if p.Date.Before(from) || p.Date.After(to) {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	return kept
+}
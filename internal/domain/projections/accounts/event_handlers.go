@@ -3,8 +3,11 @@ package accounts
 import (
 	"context"
 
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	account_events "github.com/somatom98/brokeli/internal/domain/account/events"
 	transaction_events "github.com/somatom98/brokeli/internal/domain/transaction/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
 )
 
 func (v *Projection) ApplyAccountOpened(ctx context.Context, e account_events.Opened) error {
@@ -15,10 +18,44 @@ func (v *Projection) ApplyAccountNameUpdated(ctx context.Context, e account_even
 	return v.repository.UpdateAccountName(ctx, e.AccountID, e.Name)
 }
 
+func (v *Projection) ApplyAccountKindChanged(ctx context.Context, e account_events.KindChanged) error {
+	return v.repository.UpdateAccountKind(ctx, e.AccountID, e.Kind)
+}
+
+func (v *Projection) ApplyAccountDetailsUpdated(ctx context.Context, e account_events.DetailsUpdated) error {
+	return v.repository.UpdateAccountDetails(ctx, e.AccountID, e.Details)
+}
+
+func (v *Projection) ApplyAccountClosed(ctx context.Context, e account_events.Closed) error {
+	return v.repository.CloseAccount(ctx, e.AccountID, e.HappenedAt)
+}
+
+func (v *Projection) ApplyAccountReopened(ctx context.Context, e account_events.Reopened) error {
+	return v.repository.ReopenAccount(ctx, e.AccountID)
+}
+
+func (v *Projection) ApplyOverdraftLimitSet(ctx context.Context, e account_events.OverdraftLimitSet) error {
+	return v.repository.SetAccountOverdraftLimit(ctx, e.AccountID, e.Currency, e.Limit)
+}
+
+func (v *Projection) ApplyOverdraftLimitRemoved(ctx context.Context, e account_events.OverdraftLimitRemoved) error {
+	return v.repository.RemoveAccountOverdraftLimit(ctx, e.AccountID, e.Currency)
+}
+
 func (v *Projection) ApplyExpenseCreated(ctx context.Context, e transaction_events.MoneySpent) error {
 	return v.repository.UpdateAccountBalance(ctx, e.AccountID, e.Amount.Neg(), e.Currency)
 }
 
+func (v *Projection) ApplyExpenseSplit(ctx context.Context, e transaction_events.ExpenseSplit) error {
+	for _, line := range e.Lines {
+		err := v.repository.UpdateAccountBalance(ctx, line.AccountID, line.Amount.Neg(), e.Currency)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (v *Projection) ApplyIncomeCreated(ctx context.Context, e transaction_events.MoneyReceived) error {
 	return v.repository.UpdateAccountBalance(ctx, e.AccountID, e.Amount, e.Currency)
 }
@@ -27,6 +64,42 @@ func (v *Projection) ApplyReimbursementReceived(ctx context.Context, e transacti
 	return v.repository.UpdateAccountBalance(ctx, e.AccountID, e.Amount, e.Currency)
 }
 
+func (v *Projection) ApplyExpectedReimbursementSet(ctx context.Context, e transaction_events.ExpectedReimbursementSet) error {
+	return v.repository.UpdateAccountExpectedReimbursements(ctx, e.AccountID, e.Outstanding.Sub(e.PreviousOutstanding), e.Currency)
+}
+
+func (v *Projection) ApplyExpenseReimbursed(ctx context.Context, e transaction_events.ExpenseReimbursed) error {
+	return v.repository.UpdateAccountExpectedReimbursements(ctx, e.AccountID, e.Outstanding.Sub(e.PreviousOutstanding), e.Currency)
+}
+
+func (v *Projection) ApplyReimbursementCancelled(ctx context.Context, e transaction_events.ReimbursementCancelled) error {
+	return v.repository.UpdateAccountExpectedReimbursements(ctx, e.AccountID, e.Outstanding.Sub(e.PreviousOutstanding), e.Currency)
+}
+
+func (v *Projection) ApplyReimbursementAmended(ctx context.Context, e transaction_events.ReimbursementAmended) error {
+	return v.repository.UpdateAccountExpectedReimbursements(ctx, e.AccountID, e.Outstanding.Sub(e.PreviousOutstanding), e.Currency)
+}
+
+func (v *Projection) ApplyAmountChanged(ctx context.Context, e transaction_events.AmountChanged) error {
+	return v.repository.UpdateAccountBalance(ctx, e.AccountID, e.Side.Signed(e.Amount.Sub(e.PreviousAmount)), e.Currency)
+}
+
+// ApplyTransactionVoided reverses the entries of the voided transaction. The
+// ones of transfers are reversed by the account movements voiding them.
+func (v *Projection) ApplyTransactionVoided(ctx context.Context, e transaction_events.TransactionVoided) error {
+	if e.TransactionType == values.TransactionType_Transfer {
+		return nil
+	}
+
+	for _, entry := range e.Entries {
+		err := v.repository.UpdateAccountBalance(ctx, entry.AccountID, entry.Side.Signed(entry.Amount).Neg(), entry.Currency)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (v *Projection) ApplyMoneyDeposited(ctx context.Context, e account_events.MoneyDeposited) error {
 	return v.repository.UpdateAccountBalance(ctx, e.AccountID, e.Amount, e.Currency)
 }
@@ -47,3 +120,30 @@ func (v *Projection) ApplyMoneyInvested(ctx context.Context, e transaction_event
 
 	return v.repository.UpdateAccountBalance(ctx, e.AccountID, e.Fee.Neg(), e.FeeCurrency)
 }
+
+func (v *Projection) ApplyInvestmentSold(ctx context.Context, e transaction_events.InvestmentSold) error {
+	return v.applyProceeds(ctx, e.AccountID, e.Units.Mul(e.Price), e.PriceCurrency, e.Fee, e.FeeCurrency)
+}
+
+func (v *Projection) ApplyDividendReceived(ctx context.Context, e transaction_events.DividendReceived) error {
+	return v.applyProceeds(ctx, e.AccountID, e.Units.Mul(e.Price), e.PriceCurrency, e.Fee, e.FeeCurrency)
+}
+
+func (v *Projection) ApplyInterestReceived(ctx context.Context, e transaction_events.InterestReceived) error {
+	return v.applyProceeds(ctx, e.AccountID, e.Units.Mul(e.Price), e.PriceCurrency, e.Fee, e.FeeCurrency)
+}
+
+func (v *Projection) ApplyInvestmentFeeCharged(ctx context.Context, e transaction_events.InvestmentFeeCharged) error {
+	return v.repository.UpdateAccountBalance(ctx, e.AccountID, e.Fee.Neg(), e.FeeCurrency)
+}
+
+// applyProceeds adds what an investment paid back to the account's liquid
+// balance, less the fee charged on it.
+func (v *Projection) applyProceeds(ctx context.Context, accountID uuid.UUID, proceeds decimal.Decimal, currency values.Currency, fee decimal.Decimal, feeCurrency values.Currency) error {
+	err := v.repository.UpdateAccountBalance(ctx, accountID, proceeds, currency)
+	if err != nil {
+		return err
+	}
+
+	return v.repository.UpdateAccountBalance(ctx, accountID, fee.Neg(), feeCurrency)
+}
@@ -36,6 +36,13 @@ func (r *InMemoryRepository) CloseAccount(ctx context.Context, id uuid.UUID, clo
 	return nil
 }
 
+func (r *InMemoryRepository) ReopenAccount(ctx context.Context, id uuid.UUID) error {
+	acc := r.getOrCreate(id)
+	acc.ClosedAt = nil
+	r.accounts[id] = acc
+	return nil
+}
+
 func (r *InMemoryRepository) UpdateAccountName(ctx context.Context, id uuid.UUID, name string) error {
 	acc := r.getOrCreate(id)
 	acc.Name = name
@@ -43,6 +50,25 @@ func (r *InMemoryRepository) UpdateAccountName(ctx context.Context, id uuid.UUID
 	return nil
 }
 
+func (r *InMemoryRepository) UpdateAccountKind(ctx context.Context, id uuid.UUID, kind values.AccountKind) error {
+	acc := r.getOrCreate(id)
+	acc.Kind = kind
+	r.accounts[id] = acc
+	return nil
+}
+
+func (r *InMemoryRepository) UpdateAccountDetails(ctx context.Context, id uuid.UUID, details values.AccountDetails) error {
+	acc := r.getOrCreate(id)
+	acc.Institution = details.Institution
+	acc.AccountNumber = details.AccountNumber
+	acc.Owner = details.Owner
+	acc.Icon = details.Icon
+	acc.Color = details.Color
+	acc.Tags = append([]string{}, details.Tags...)
+	r.accounts[id] = acc
+	return nil
+}
+
 func (r *InMemoryRepository) UpdateAccountBalance(ctx context.Context, id uuid.UUID, amount decimal.Decimal, currency values.Currency) error {
 	acc := r.getOrCreate(id)
 
@@ -56,6 +82,33 @@ func (r *InMemoryRepository) UpdateAccountBalance(ctx context.Context, id uuid.U
 	return nil
 }
 
+func (r *InMemoryRepository) UpdateAccountExpectedReimbursements(ctx context.Context, id uuid.UUID, amount decimal.Decimal, currency values.Currency) error {
+	acc := r.getOrCreate(id)
+
+	if _, ok := acc.ExpectedReimbursements[currency]; !ok {
+		acc.ExpectedReimbursements[currency] = decimal.Zero
+	}
+
+	acc.ExpectedReimbursements[currency] = acc.ExpectedReimbursements[currency].Add(amount)
+	r.accounts[id] = acc
+
+	return nil
+}
+
+func (r *InMemoryRepository) SetAccountOverdraftLimit(ctx context.Context, id uuid.UUID, currency values.Currency, limit decimal.Decimal) error {
+	acc := r.getOrCreate(id)
+	acc.OverdraftLimits[currency] = limit
+	r.accounts[id] = acc
+	return nil
+}
+
+func (r *InMemoryRepository) RemoveAccountOverdraftLimit(ctx context.Context, id uuid.UUID, currency values.Currency) error {
+	acc := r.getOrCreate(id)
+	delete(acc.OverdraftLimits, currency)
+	r.accounts[id] = acc
+	return nil
+}
+
 func (r *InMemoryRepository) GetAll(ctx context.Context) (map[uuid.UUID]Account, error) {
 	return r.accounts, nil
 }
@@ -9,8 +9,16 @@ import (
 
 type Account struct {
 	Name                   string                              `json:"name"`
+	Kind                   values.AccountKind                  `json:"kind,omitempty"`
+	Institution            string                              `json:"institution,omitempty"`
+	AccountNumber          string                              `json:"account_number,omitempty"`
+	Owner                  string                              `json:"owner,omitempty"`
+	Icon                   string                              `json:"icon,omitempty"`
+	Color                  string                              `json:"color,omitempty"`
+	Tags                   []string                            `json:"tags"`
 	Balance                map[values.Currency]decimal.Decimal `json:"balance"`
 	ExpectedReimbursements map[values.Currency]decimal.Decimal `json:"expected_reimbursements"`
+	OverdraftLimits        map[values.Currency]decimal.Decimal `json:"overdraft_limits"`
 	CreatedAt              *time.Time                          `json:"created_at,omitempty"`
 	ClosedAt               *time.Time                          `json:"closed_at,omitempty"`
 }
@@ -19,5 +27,7 @@ func NewAccount() Account {
 	return Account{
 		Balance:                make(map[values.Currency]decimal.Decimal),
 		ExpectedReimbursements: make(map[values.Currency]decimal.Decimal),
+		OverdraftLimits:        make(map[values.Currency]decimal.Decimal),
+		Tags:                   []string{},
 	}
 }
@@ -25,7 +25,7 @@ func NewPostgresRepository(dbConn *sql.DB) (*PostgresRepository, error) {
 }
 
 func (r *PostgresRepository) CreateAccount(ctx context.Context, id uuid.UUID, name string, createdAt time.Time) error {
-	return r.queries.CreateAccount(ctx, db.CreateAccountParams{
+	return r.queries.In(ctx).CreateAccount(ctx, db.CreateAccountParams{
 		ID:        id,
 		Name:      name,
 		CreatedAt: sql.NullTime{Time: createdAt, Valid: true},
@@ -33,80 +33,206 @@ func (r *PostgresRepository) CreateAccount(ctx context.Context, id uuid.UUID, na
 }
 
 func (r *PostgresRepository) CloseAccount(ctx context.Context, id uuid.UUID, closedAt time.Time) error {
-	return r.queries.CloseAccount(ctx, db.CloseAccountParams{
+	return r.queries.In(ctx).CloseAccount(ctx, db.CloseAccountParams{
 		ID:       id,
 		ClosedAt: sql.NullTime{Time: closedAt, Valid: true},
 	})
 }
 
+func (r *PostgresRepository) ReopenAccount(ctx context.Context, id uuid.UUID) error {
+	return r.queries.In(ctx).ReopenAccount(ctx, id)
+}
+
 func (r *PostgresRepository) UpdateAccountName(ctx context.Context, id uuid.UUID, name string) error {
-	return r.queries.UpdateAccountName(ctx, db.UpdateAccountNameParams{
+	return r.queries.In(ctx).UpdateAccountName(ctx, db.UpdateAccountNameParams{
 		ID:   id,
 		Name: name,
 	})
 }
 
-func (r *PostgresRepository) UpdateAccountBalance(ctx context.Context, id uuid.UUID, amount decimal.Decimal, currency values.Currency) error {
-	// This requires a read-modify-write transaction to ensure consistency.
-	tx, err := r.db.BeginTx(ctx, nil)
+func (r *PostgresRepository) UpdateAccountKind(ctx context.Context, id uuid.UUID, kind values.AccountKind) error {
+	return r.queries.In(ctx).UpdateAccountKind(ctx, db.UpdateAccountKindParams{
+		ID:   id,
+		Kind: string(kind),
+	})
+}
+
+func (r *PostgresRepository) UpdateAccountDetails(ctx context.Context, id uuid.UUID, details values.AccountDetails) error {
+	tags := details.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	tagsJSON, err := json.Marshal(tags)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
 
-	qtx := r.queries.WithTx(tx)
+	return r.queries.In(ctx).UpdateAccountDetails(ctx, db.UpdateAccountDetailsParams{
+		ID:            id,
+		Institution:   details.Institution,
+		AccountNumber: details.AccountNumber,
+		Owner:         details.Owner,
+		Icon:          details.Icon,
+		Color:         details.Color,
+		Tags:          tagsJSON,
+	})
+}
+
+func (r *PostgresRepository) UpdateAccountBalance(ctx context.Context, id uuid.UUID, amount decimal.Decimal, currency values.Currency) error {
+	// This requires a read-modify-write transaction to ensure consistency.
+	return db.InTx(ctx, r.db, func(qtx *db.Queries) error {
+		// Read current balance
+		balanceJSON, err := qtx.GetAccountBalanceForUpdate(ctx, id)
+		if err == sql.ErrNoRows {
+			// If account doesn't exist yet (out of order event?), strictly we should probably create it or error.
+			// For robustness, let's create a placeholder.
+			balanceJSON = []byte("{}")
+			err = qtx.UpsertPlaceholderAccount(ctx, db.UpsertPlaceholderAccountParams{
+				ID:      id,
+				Name:    "", // Placeholder
+				Balance: balanceJSON,
+			})
+			if err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		var balance map[values.Currency]decimal.Decimal
+		if err := json.Unmarshal(balanceJSON, &balance); err != nil {
+			return err
+		}
+		if balance == nil {
+			balance = make(map[values.Currency]decimal.Decimal)
+		}
+
+		// Update balance
+		if _, ok := balance[currency]; !ok {
+			balance[currency] = decimal.Zero
+		}
+		balance[currency] = balance[currency].Add(amount)
 
-	// Read current balance
-	balanceJSON, err := qtx.GetAccountBalanceForUpdate(ctx, id)
-	if err == sql.ErrNoRows {
-		// If account doesn't exist yet (out of order event?), strictly we should probably create it or error.
-		// For robustness, let's create a placeholder.
-		balanceJSON = []byte("{}")
-		err = qtx.UpsertPlaceholderAccount(ctx, db.UpsertPlaceholderAccountParams{
+		newBalanceJSON, err := json.Marshal(balance)
+		if err != nil {
+			return err
+		}
+
+		// Write back
+		err = qtx.UpdateAccountBalance(ctx, db.UpdateAccountBalanceParams{
 			ID:      id,
-			Name:    "", // Placeholder
-			Balance: balanceJSON,
+			Balance: newBalanceJSON,
 		})
 		if err != nil {
 			return err
 		}
-	} else if err != nil {
-		return err
-	}
 
-	var balance map[values.Currency]decimal.Decimal
-	if err := json.Unmarshal(balanceJSON, &balance); err != nil {
-		return err
-	}
-	if balance == nil {
-		balance = make(map[values.Currency]decimal.Decimal)
-	}
+		return nil
+	})
+}
 
-	// Update balance
-	if _, ok := balance[currency]; !ok {
-		balance[currency] = decimal.Zero
-	}
-	balance[currency] = balance[currency].Add(amount)
+func (r *PostgresRepository) UpdateAccountExpectedReimbursements(ctx context.Context, id uuid.UUID, amount decimal.Decimal, currency values.Currency) error {
+	return db.InTx(ctx, r.db, func(qtx *db.Queries) error {
+		expectedJSON, err := qtx.GetAccountExpectedReimbursementsForUpdate(ctx, id)
+		if err == sql.ErrNoRows {
+			expectedJSON = []byte("{}")
+			err = qtx.UpsertPlaceholderAccount(ctx, db.UpsertPlaceholderAccountParams{
+				ID:      id,
+				Name:    "", // Placeholder
+				Balance: []byte("{}"),
+			})
+			if err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
 
-	newBalanceJSON, err := json.Marshal(balance)
-	if err != nil {
-		return err
-	}
+		var expected map[values.Currency]decimal.Decimal
+		if err := json.Unmarshal(expectedJSON, &expected); err != nil {
+			return err
+		}
+		if expected == nil {
+			expected = make(map[values.Currency]decimal.Decimal)
+		}
+
+		expected[currency] = expected[currency].Add(amount)
+
+		newExpectedJSON, err := json.Marshal(expected)
+		if err != nil {
+			return err
+		}
+
+		err = qtx.UpdateAccountExpectedReimbursements(ctx, db.UpdateAccountExpectedReimbursementsParams{
+			ID:                     id,
+			ExpectedReimbursements: newExpectedJSON,
+		})
+		if err != nil {
+			return err
+		}
 
-	// Write back
-	err = qtx.UpdateAccountBalance(ctx, db.UpdateAccountBalanceParams{
-		ID:      id,
-		Balance: newBalanceJSON,
+		return nil
 	})
-	if err != nil {
-		return err
-	}
+}
 
-	return tx.Commit()
+func (r *PostgresRepository) SetAccountOverdraftLimit(ctx context.Context, id uuid.UUID, currency values.Currency, limit decimal.Decimal) error {
+	return r.updateOverdraftLimits(ctx, id, func(limits map[values.Currency]decimal.Decimal) {
+		limits[currency] = limit
+	})
+}
+
+func (r *PostgresRepository) RemoveAccountOverdraftLimit(ctx context.Context, id uuid.UUID, currency values.Currency) error {
+	return r.updateOverdraftLimits(ctx, id, func(limits map[values.Currency]decimal.Decimal) {
+		delete(limits, currency)
+	})
+}
+
+func (r *PostgresRepository) updateOverdraftLimits(ctx context.Context, id uuid.UUID, update func(limits map[values.Currency]decimal.Decimal)) error {
+	return db.InTx(ctx, r.db, func(qtx *db.Queries) error {
+		limitsJSON, err := qtx.GetAccountOverdraftLimitsForUpdate(ctx, id)
+		if err == sql.ErrNoRows {
+			limitsJSON = []byte("{}")
+			err = qtx.UpsertPlaceholderAccount(ctx, db.UpsertPlaceholderAccountParams{
+				ID:      id,
+				Name:    "", // Placeholder
+				Balance: []byte("{}"),
+			})
+			if err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		var limits map[values.Currency]decimal.Decimal
+		if err := json.Unmarshal(limitsJSON, &limits); err != nil {
+			return err
+		}
+		if limits == nil {
+			limits = make(map[values.Currency]decimal.Decimal)
+		}
+
+		update(limits)
+
+		newLimitsJSON, err := json.Marshal(limits)
+		if err != nil {
+			return err
+		}
+
+		err = qtx.UpdateAccountOverdraftLimits(ctx, db.UpdateAccountOverdraftLimitsParams{
+			ID:              id,
+			OverdraftLimits: newLimitsJSON,
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
 }
 
 func (r *PostgresRepository) GetAll(ctx context.Context) (map[uuid.UUID]Account, error) {
-	rows, err := r.queries.GetAllAccounts(ctx)
+	rows, err := r.queries.In(ctx).GetAllAccounts(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -118,9 +244,33 @@ func (r *PostgresRepository) GetAll(ctx context.Context) (map[uuid.UUID]Account,
 			return nil, err
 		}
 
+		var expectedReimbursements map[values.Currency]decimal.Decimal
+		if err := json.Unmarshal(row.ExpectedReimbursements, &expectedReimbursements); err != nil {
+			return nil, err
+		}
+
+		var overdraftLimits map[values.Currency]decimal.Decimal
+		if err := json.Unmarshal(row.OverdraftLimits, &overdraftLimits); err != nil {
+			return nil, err
+		}
+
+		var tags []string
+		if err := json.Unmarshal(row.Tags, &tags); err != nil {
+			return nil, err
+		}
+
 		acc := Account{
-			Name:    row.Name,
-			Balance: balance,
+			Name:                   row.Name,
+			Kind:                   values.AccountKind(row.Kind),
+			Institution:            row.Institution,
+			AccountNumber:          row.AccountNumber,
+			Owner:                  row.Owner,
+			Icon:                   row.Icon,
+			Color:                  row.Color,
+			Tags:                   tags,
+			Balance:                balance,
+			ExpectedReimbursements: expectedReimbursements,
+			OverdraftLimits:        overdraftLimits,
 		}
 		if row.CreatedAt.Valid {
 			t := row.CreatedAt.Time
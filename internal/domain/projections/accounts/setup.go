@@ -17,26 +17,43 @@ import (
 type Repository interface {
 	CreateAccount(ctx context.Context, id uuid.UUID, name string, createdAt time.Time) error
 	CloseAccount(ctx context.Context, id uuid.UUID, closedAt time.Time) error
+	ReopenAccount(ctx context.Context, id uuid.UUID) error
 	UpdateAccountBalance(ctx context.Context, id uuid.UUID, amount decimal.Decimal, currency values.Currency) error
+	// UpdateAccountExpectedReimbursements adds the amount to the money the
+	// account expects back in the currency.
+	UpdateAccountExpectedReimbursements(ctx context.Context, id uuid.UUID, amount decimal.Decimal, currency values.Currency) error
+	SetAccountOverdraftLimit(ctx context.Context, id uuid.UUID, currency values.Currency, limit decimal.Decimal) error
+	RemoveAccountOverdraftLimit(ctx context.Context, id uuid.UUID, currency values.Currency) error
 	UpdateAccountName(ctx context.Context, id uuid.UUID, name string) error
+	UpdateAccountKind(ctx context.Context, id uuid.UUID, kind values.AccountKind) error
+	UpdateAccountDetails(ctx context.Context, id uuid.UUID, details values.AccountDetails) error
 	GetAll(ctx context.Context) (map[uuid.UUID]Account, error)
 }
 
+// SubscriptionName identifies the projection checkpoints in the event stores.
+const SubscriptionName = "accounts_projection"
+
 type Projection struct {
 	repository Repository
 }
 
+// NewProjection returns a projection that is not subscribed to the event
+// stores, for replaying events into a repository of choice.
+func NewProjection(repository Repository) *Projection {
+	return &Projection{
+		repository: repository,
+	}
+}
+
 func New(
 	transactionES event_store.Store[*transaction.Transaction],
 	accountES event_store.Store[*account.Account],
 	repository Repository,
 ) *Projection {
-	p := &Projection{
-		repository: repository,
-	}
+	p := NewProjection(repository)
 
-	transactionES.Subscribe(context.Background(), p.HandleRecord)
-	accountES.Subscribe(context.Background(), p.HandleRecord)
+	transactionES.SubscribeNamed(context.Background(), SubscriptionName, p.HandleRecord)
+	accountES.SubscribeNamed(context.Background(), SubscriptionName, p.HandleRecord)
 
 	return p
 }
@@ -45,6 +62,8 @@ func (v *Projection) HandleRecord(ctx context.Context, record event_store.Record
 	switch record.Type() {
 	case transaction_events.TypeMoneySpent:
 		return v.ApplyExpenseCreated(ctx, record.Content().(transaction_events.MoneySpent))
+	case transaction_events.TypeExpenseSplit:
+		return v.ApplyExpenseSplit(ctx, record.Content().(transaction_events.ExpenseSplit))
 	case transaction_events.TypeMoneyReceived:
 		return v.ApplyIncomeCreated(ctx, record.Content().(transaction_events.MoneyReceived))
 	case transaction_events.TypeReimbursementReceived:
@@ -53,12 +72,44 @@ func (v *Projection) HandleRecord(ctx context.Context, record event_store.Record
 		return v.ApplyAccountOpened(ctx, record.Content().(account_events.Opened))
 	case account_events.TypeNameUpdated:
 		return v.ApplyAccountNameUpdated(ctx, record.Content().(account_events.NameUpdated))
+	case account_events.TypeKindChanged:
+		return v.ApplyAccountKindChanged(ctx, record.Content().(account_events.KindChanged))
+	case account_events.TypeDetailsUpdated:
+		return v.ApplyAccountDetailsUpdated(ctx, record.Content().(account_events.DetailsUpdated))
+	case account_events.TypeClosed:
+		return v.ApplyAccountClosed(ctx, record.Content().(account_events.Closed))
+	case account_events.TypeReopened:
+		return v.ApplyAccountReopened(ctx, record.Content().(account_events.Reopened))
+	case account_events.TypeOverdraftLimitSet:
+		return v.ApplyOverdraftLimitSet(ctx, record.Content().(account_events.OverdraftLimitSet))
+	case account_events.TypeOverdraftLimitRemoved:
+		return v.ApplyOverdraftLimitRemoved(ctx, record.Content().(account_events.OverdraftLimitRemoved))
 	case account_events.TypeMoneyDeposited:
 		return v.ApplyMoneyDeposited(ctx, record.Content().(account_events.MoneyDeposited))
 	case account_events.TypeMoneyWithdrawn:
 		return v.ApplyMoneyWithdrawn(ctx, record.Content().(account_events.MoneyWithdrawn))
 	case transaction_events.TypeMoneyInvested:
 		return v.ApplyMoneyInvested(ctx, record.Content().(transaction_events.MoneyInvested))
+	case transaction_events.TypeInvestmentSold:
+		return v.ApplyInvestmentSold(ctx, record.Content().(transaction_events.InvestmentSold))
+	case transaction_events.TypeDividendReceived:
+		return v.ApplyDividendReceived(ctx, record.Content().(transaction_events.DividendReceived))
+	case transaction_events.TypeInterestReceived:
+		return v.ApplyInterestReceived(ctx, record.Content().(transaction_events.InterestReceived))
+	case transaction_events.TypeInvestmentFeeCharged:
+		return v.ApplyInvestmentFeeCharged(ctx, record.Content().(transaction_events.InvestmentFeeCharged))
+	case transaction_events.TypeAmountChanged:
+		return v.ApplyAmountChanged(ctx, record.Content().(transaction_events.AmountChanged))
+	case transaction_events.TypeExpectedReimbursementSet:
+		return v.ApplyExpectedReimbursementSet(ctx, record.Content().(transaction_events.ExpectedReimbursementSet))
+	case transaction_events.TypeExpenseReimbursed:
+		return v.ApplyExpenseReimbursed(ctx, record.Content().(transaction_events.ExpenseReimbursed))
+	case transaction_events.TypeReimbursementCancelled:
+		return v.ApplyReimbursementCancelled(ctx, record.Content().(transaction_events.ReimbursementCancelled))
+	case transaction_events.TypeReimbursementAmended:
+		return v.ApplyReimbursementAmended(ctx, record.Content().(transaction_events.ReimbursementAmended))
+	case transaction_events.TypeTransactionVoided:
+		return v.ApplyTransactionVoided(ctx, record.Content().(transaction_events.TransactionVoided))
 	}
 	return nil
 }
@@ -66,3 +117,24 @@ func (v *Projection) HandleRecord(ctx context.Context, record event_store.Record
 func (v *Projection) GetAll(ctx context.Context) (map[uuid.UUID]Account, error) {
 	return v.repository.GetAll(ctx)
 }
+
+// GetBalancesByKind sums the balances of the accounts of each kind, the ones
+// without a kind being grouped under the empty kind.
+func (v *Projection) GetBalancesByKind(ctx context.Context) (map[values.AccountKind]map[values.Currency]decimal.Decimal, error) {
+	accounts, err := v.repository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[values.AccountKind]map[values.Currency]decimal.Decimal)
+	for _, acc := range accounts {
+		if _, ok := balances[acc.Kind]; !ok {
+			balances[acc.Kind] = make(map[values.Currency]decimal.Decimal)
+		}
+		for currency, amount := range acc.Balance {
+			balances[acc.Kind][currency] = balances[acc.Kind][currency].Add(amount)
+		}
+	}
+
+	return balances, nil
+}
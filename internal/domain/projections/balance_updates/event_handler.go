@@ -3,10 +3,14 @@ package balance_updates
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	account_events "github.com/somatom98/brokeli/internal/domain/account/events"
+	loan_events "github.com/somatom98/brokeli/internal/domain/loan/events"
 	transaction_events "github.com/somatom98/brokeli/internal/domain/transaction/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
 )
 
 const userSystem = "system"
@@ -14,12 +18,24 @@ const userSystem = "system"
 const (
 	originTransaction = "transaction"
 	originMovement    = "movement"
+	originLoan        = "loan"
 )
 
 func (v *Projection) ApplyExpenseCreated(ctx context.Context, id uuid.UUID, e transaction_events.MoneySpent) error {
 	return v.repository.InsertBalanceUpdate(ctx, id, e.AccountID, e.Currency, e.Amount.Neg(), userSystem, e.HappenedAt, originTransaction, BalanceTypeLiquidity)
 }
 
+func (v *Projection) ApplyExpenseSplit(ctx context.Context, id uuid.UUID, e transaction_events.ExpenseSplit) error {
+	for i, line := range e.Lines {
+		idLine := uuid.NewMD5(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s_line_%d", id.String(), i)))
+		err := v.repository.InsertBalanceUpdate(ctx, idLine, line.AccountID, e.Currency, line.Amount.Neg(), userSystem, e.HappenedAt, originTransaction, BalanceTypeLiquidity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (v *Projection) ApplyIncomeCreated(ctx context.Context, id uuid.UUID, e transaction_events.MoneyReceived) error {
 	return v.repository.InsertBalanceUpdate(ctx, id, e.AccountID, e.Currency, e.Amount, userSystem, e.HappenedAt, originTransaction, BalanceTypeLiquidity)
 }
@@ -28,6 +44,45 @@ func (v *Projection) ApplyReimbursementReceived(ctx context.Context, id uuid.UUI
 	return v.repository.InsertBalanceUpdate(ctx, id, e.AccountID, e.Currency, e.Amount, userSystem, e.HappenedAt, originTransaction, BalanceTypeLiquidity)
 }
 
+func (v *Projection) ApplyAmountChanged(ctx context.Context, id uuid.UUID, e transaction_events.AmountChanged) error {
+	return v.repository.InsertBalanceUpdate(ctx, id, e.AccountID, e.Currency, e.Side.Signed(e.Amount.Sub(e.PreviousAmount)), userSystem, e.HappenedAt, originTransaction, BalanceTypeLiquidity)
+}
+
+// ApplyRedated moves the amount of the transaction from the period of its
+// previous date to the one of its new date.
+func (v *Projection) ApplyRedated(ctx context.Context, id uuid.UUID, e transaction_events.Redated) error {
+	amount := e.Side.Signed(e.Amount)
+	err := v.repository.InsertBalanceUpdate(ctx, id, e.AccountID, e.Currency, amount.Neg(), userSystem, e.PreviousHappenedAt, originTransaction, BalanceTypeLiquidity)
+	if err != nil {
+		return err
+	}
+
+	idRedated := uuid.NewMD5(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s_redated", id.String())))
+	return v.repository.InsertBalanceUpdate(ctx, idRedated, e.AccountID, e.Currency, amount, userSystem, e.HappenedAt, originTransaction, BalanceTypeLiquidity)
+}
+
+// ApplyTransactionVoided reverses the entries of the voided transaction at the
+// date they were recorded at. The ones of transfers are reversed by the
+// account movements voiding them.
+func (v *Projection) ApplyTransactionVoided(ctx context.Context, id uuid.UUID, e transaction_events.TransactionVoided) error {
+	if e.TransactionType == values.TransactionType_Transfer {
+		return nil
+	}
+
+	for i, entry := range e.Entries {
+		idEntry := id
+		if i > 0 {
+			idEntry = uuid.NewMD5(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s_%d", id.String(), i)))
+		}
+
+		err := v.repository.InsertBalanceUpdate(ctx, idEntry, entry.AccountID, entry.Currency, entry.Side.Signed(entry.Amount).Neg(), userSystem, e.HappenedAt, originTransaction, BalanceTypeLiquidity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (v *Projection) ApplyMoneyDeposited(ctx context.Context, id uuid.UUID, e account_events.MoneyDeposited) error {
 	return v.repository.InsertBalanceUpdate(ctx, id, e.AccountID, e.Currency, e.Amount, e.User, e.HappenedAt, originMovement, BalanceTypeLiquidity)
 }
@@ -56,3 +111,51 @@ func (v *Projection) ApplyInvestmentCreated(ctx context.Context, id uuid.UUID, e
 	idInvestment := uuid.NewMD5(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s_investment", id.String())))
 	return v.repository.InsertBalanceUpdate(ctx, idInvestment, e.AccountID, e.PriceCurrency, priceAmount, userSystem, e.HappenedAt, originTransaction, BalanceTypeInvestment)
 }
+
+// ApplyInvestmentSold moves the proceeds of the sale back into liquidity and
+// takes the cost basis of the units sold out of the investment balance.
+func (v *Projection) ApplyInvestmentSold(ctx context.Context, id uuid.UUID, e transaction_events.InvestmentSold) error {
+	err := v.insertProceeds(ctx, id, e.AccountID, e.Units.Mul(e.Price), e.PriceCurrency, e.Fee, e.FeeCurrency, e.HappenedAt)
+	if err != nil {
+		return err
+	}
+
+	idInvestment := uuid.NewMD5(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s_investment", id.String())))
+	return v.repository.InsertBalanceUpdate(ctx, idInvestment, e.AccountID, e.PriceCurrency, e.CostBasis.Neg(), userSystem, e.HappenedAt, originTransaction, BalanceTypeInvestment)
+}
+
+func (v *Projection) ApplyDividendReceived(ctx context.Context, id uuid.UUID, e transaction_events.DividendReceived) error {
+	return v.insertProceeds(ctx, id, e.AccountID, e.Units.Mul(e.Price), e.PriceCurrency, e.Fee, e.FeeCurrency, e.HappenedAt)
+}
+
+func (v *Projection) ApplyInterestReceived(ctx context.Context, id uuid.UUID, e transaction_events.InterestReceived) error {
+	return v.insertProceeds(ctx, id, e.AccountID, e.Units.Mul(e.Price), e.PriceCurrency, e.Fee, e.FeeCurrency, e.HappenedAt)
+}
+
+func (v *Projection) ApplyInvestmentFeeCharged(ctx context.Context, id uuid.UUID, e transaction_events.InvestmentFeeCharged) error {
+	return v.repository.InsertBalanceUpdate(ctx, id, e.AccountID, e.FeeCurrency, e.Fee.Neg(), userSystem, e.HappenedAt, originTransaction, BalanceTypeLiquidity)
+}
+
+// insertProceeds deposits the proceeds into liquidity and withdraws the fee
+// charged on them.
+func (v *Projection) insertProceeds(ctx context.Context, id uuid.UUID, accountID uuid.UUID, proceeds decimal.Decimal, currency values.Currency, fee decimal.Decimal, feeCurrency values.Currency, happenedAt time.Time) error {
+	err := v.repository.InsertBalanceUpdate(ctx, id, accountID, currency, proceeds, userSystem, happenedAt, originTransaction, BalanceTypeLiquidity)
+	if err != nil {
+		return err
+	}
+
+	idFee := uuid.NewMD5(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s_fee", id.String())))
+	return v.repository.InsertBalanceUpdate(ctx, idFee, accountID, feeCurrency, fee.Neg(), userSystem, happenedAt, originTransaction, BalanceTypeLiquidity)
+}
+
+// ApplyLoanOpened owes the principal of the loan from its start date.
+func (v *Projection) ApplyLoanOpened(ctx context.Context, id uuid.UUID, e loan_events.Opened) error {
+	return v.repository.InsertBalanceUpdate(ctx, id, e.LoanID, e.Currency, e.Principal.Neg(), userSystem, e.StartDate, originLoan, BalanceTypeLiability)
+}
+
+// ApplyLoanPaymentRecorded reduces what is owed on the loan by the principal
+// repaid. The payment itself leaves the liquidity of the account it was made
+// from through the interest expense and the principal withdrawal.
+func (v *Projection) ApplyLoanPaymentRecorded(ctx context.Context, id uuid.UUID, e loan_events.PaymentRecorded) error {
+	return v.repository.InsertBalanceUpdate(ctx, id, e.LoanID, e.Currency, e.Principal, userSystem, e.HappenedAt, originLoan, BalanceTypeLiability)
+}
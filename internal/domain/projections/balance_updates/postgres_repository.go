@@ -24,7 +24,7 @@ func NewPostgresRepository(dbConn *sql.DB) (*PostgresRepository, error) {
 }
 
 func (r *PostgresRepository) InsertBalanceUpdate(ctx context.Context, id uuid.UUID, accountID uuid.UUID, currency values.Currency, amount decimal.Decimal, userID string, valueDate time.Time, origin string, balanceType string) error {
-	return r.queries.InsertBalanceUpdate(ctx, db.InsertBalanceUpdateParams{
+	return r.queries.In(ctx).InsertBalanceUpdate(ctx, db.InsertBalanceUpdateParams{
 		ID:          id,
 		AccountID:   accountID,
 		Currency:    string(currency),
@@ -37,7 +37,7 @@ func (r *PostgresRepository) InsertBalanceUpdate(ctx context.Context, id uuid.UU
 }
 
 func (r *PostgresRepository) GetBalancesByAccount(ctx context.Context, accountID uuid.UUID, balanceType string) ([]BalancePeriod, error) {
-	rows, err := r.queries.GetBalancesByAccount(ctx, db.GetBalancesByAccountParams{
+	rows, err := r.queries.In(ctx).GetBalancesByAccount(ctx, db.GetBalancesByAccountParams{
 		AccountID:   accountID,
 		BalanceType: balanceType,
 	})
@@ -66,7 +66,7 @@ func (r *PostgresRepository) GetBalancesByAccount(ctx context.Context, accountID
 }
 
 func (r *PostgresRepository) GetAllBalances(ctx context.Context, balanceType string) ([]BalancePeriod, error) {
-	rows, err := r.queries.GetAllBalances(ctx, balanceType)
+	rows, err := r.queries.In(ctx).GetAllBalances(ctx, balanceType)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +92,7 @@ func (r *PostgresRepository) GetAllBalances(ctx context.Context, balanceType str
 }
 
 func (r *PostgresRepository) GetAccountDistributions(ctx context.Context, accountID uuid.UUID, balanceType string) ([]AccountDistribution, error) {
-	rows, err := r.queries.GetAccountDistributions(ctx, db.GetAccountDistributionsParams{
+	rows, err := r.queries.In(ctx).GetAccountDistributions(ctx, db.GetAccountDistributionsParams{
 		AccountID:   accountID,
 		BalanceType: balanceType,
 	})
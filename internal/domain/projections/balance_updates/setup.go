@@ -9,6 +9,8 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/somatom98/brokeli/internal/domain/account"
 	account_events "github.com/somatom98/brokeli/internal/domain/account/events"
+	"github.com/somatom98/brokeli/internal/domain/loan"
+	loan_events "github.com/somatom98/brokeli/internal/domain/loan/events"
 	"github.com/somatom98/brokeli/internal/domain/transaction"
 	transaction_events "github.com/somatom98/brokeli/internal/domain/transaction/events"
 	"github.com/somatom98/brokeli/internal/domain/values"
@@ -34,6 +36,8 @@ type AccountDistribution struct {
 const (
 	BalanceTypeLiquidity  string = "LIQUIDITY"
 	BalanceTypeInvestment string = "INVESTMENT"
+	// BalanceTypeLiability balances are the principal owed on loans, by loan.
+	BalanceTypeLiability string = "LIABILITY"
 )
 
 type Repository interface {
@@ -43,21 +47,32 @@ type Repository interface {
 	GetAccountDistributions(ctx context.Context, accountID uuid.UUID, balanceType string) ([]AccountDistribution, error)
 }
 
+// SubscriptionName identifies the projection checkpoints in the event stores.
+const SubscriptionName = "balance_updates_projection"
+
 type Projection struct {
 	repository Repository
 }
 
+// NewProjection returns a projection that is not subscribed to the event
+// stores, for replaying events into a repository of choice.
+func NewProjection(repository Repository) *Projection {
+	return &Projection{
+		repository: repository,
+	}
+}
+
 func New(
 	transactionES event_store.Store[*transaction.Transaction],
 	accountES event_store.Store[*account.Account],
+	loanES event_store.Store[*loan.Loan],
 	repository Repository,
 ) *Projection {
-	p := &Projection{
-		repository: repository,
-	}
+	p := NewProjection(repository)
 
-	transactionES.Subscribe(context.Background(), p.HandleRecord)
-	accountES.Subscribe(context.Background(), p.HandleRecord)
+	transactionES.SubscribeNamed(context.Background(), SubscriptionName, p.HandleRecord)
+	accountES.SubscribeNamed(context.Background(), SubscriptionName, p.HandleRecord)
+	loanES.SubscribeNamed(context.Background(), SubscriptionName, p.HandleRecord)
 
 	return p
 }
@@ -65,10 +80,14 @@ func New(
 func (v *Projection) HandleRecord(ctx context.Context, record event_store.Record) error {
 	var aggregateType string
 	switch record.Type() {
-	case transaction_events.TypeMoneySpent, transaction_events.TypeMoneyReceived, transaction_events.TypeReimbursementReceived, transaction_events.TypeMoneyInvested:
+	case transaction_events.TypeMoneySpent, transaction_events.TypeExpenseSplit, transaction_events.TypeMoneyReceived, transaction_events.TypeReimbursementReceived, transaction_events.TypeMoneyInvested,
+		transaction_events.TypeInvestmentSold, transaction_events.TypeDividendReceived, transaction_events.TypeInterestReceived, transaction_events.TypeInvestmentFeeCharged,
+		transaction_events.TypeAmountChanged, transaction_events.TypeRedated, transaction_events.TypeTransactionVoided:
 		aggregateType = "Transaction"
 	case account_events.TypeOpened, account_events.TypeMoneyDeposited, account_events.TypeMoneyWithdrawn:
 		aggregateType = "Account"
+	case loan_events.TypeOpened, loan_events.TypePaymentRecorded:
+		aggregateType = "Loan"
 	default:
 		return nil
 	}
@@ -79,16 +98,36 @@ func (v *Projection) HandleRecord(ctx context.Context, record event_store.Record
 	switch record.Type() {
 	case transaction_events.TypeMoneySpent:
 		return v.ApplyExpenseCreated(ctx, id, record.Content().(transaction_events.MoneySpent))
+	case transaction_events.TypeExpenseSplit:
+		return v.ApplyExpenseSplit(ctx, id, record.Content().(transaction_events.ExpenseSplit))
 	case transaction_events.TypeMoneyReceived:
 		return v.ApplyIncomeCreated(ctx, id, record.Content().(transaction_events.MoneyReceived))
 	case transaction_events.TypeReimbursementReceived:
 		return v.ApplyReimbursementReceived(ctx, id, record.Content().(transaction_events.ReimbursementReceived))
 	case transaction_events.TypeMoneyInvested:
 		return v.ApplyInvestmentCreated(ctx, id, record.Content().(transaction_events.MoneyInvested))
+	case transaction_events.TypeInvestmentSold:
+		return v.ApplyInvestmentSold(ctx, id, record.Content().(transaction_events.InvestmentSold))
+	case transaction_events.TypeDividendReceived:
+		return v.ApplyDividendReceived(ctx, id, record.Content().(transaction_events.DividendReceived))
+	case transaction_events.TypeInterestReceived:
+		return v.ApplyInterestReceived(ctx, id, record.Content().(transaction_events.InterestReceived))
+	case transaction_events.TypeInvestmentFeeCharged:
+		return v.ApplyInvestmentFeeCharged(ctx, id, record.Content().(transaction_events.InvestmentFeeCharged))
+	case transaction_events.TypeAmountChanged:
+		return v.ApplyAmountChanged(ctx, id, record.Content().(transaction_events.AmountChanged))
+	case transaction_events.TypeRedated:
+		return v.ApplyRedated(ctx, id, record.Content().(transaction_events.Redated))
+	case transaction_events.TypeTransactionVoided:
+		return v.ApplyTransactionVoided(ctx, id, record.Content().(transaction_events.TransactionVoided))
 	case account_events.TypeMoneyDeposited:
 		return v.ApplyMoneyDeposited(ctx, id, record.Content().(account_events.MoneyDeposited))
 	case account_events.TypeMoneyWithdrawn:
 		return v.ApplyMoneyWithdrawn(ctx, id, record.Content().(account_events.MoneyWithdrawn))
+	case loan_events.TypeOpened:
+		return v.ApplyLoanOpened(ctx, id, record.Content().(loan_events.Opened))
+	case loan_events.TypePaymentRecorded:
+		return v.ApplyLoanPaymentRecorded(ctx, id, record.Content().(loan_events.PaymentRecorded))
 	}
 	return nil
 }
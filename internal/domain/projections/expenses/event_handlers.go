@@ -0,0 +1,66 @@
+package expenses
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	transaction_events "github.com/somatom98/brokeli/internal/domain/transaction/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+func (v *Projection) ApplyMoneySpent(ctx context.Context, transactionID uuid.UUID, e transaction_events.MoneySpent) error {
+	return v.repository.CreateExpense(ctx, ExpenseRecord{
+		TransactionID: transactionID,
+		AccountID:     e.AccountID,
+		Currency:      e.Currency,
+		Cost:          e.Amount,
+		HappenedAt:    e.HappenedAt,
+	})
+}
+
+func (v *Projection) ApplyExpenseSplit(ctx context.Context, transactionID uuid.UUID, e transaction_events.ExpenseSplit) error {
+	return v.repository.CreateExpense(ctx, ExpenseRecord{
+		TransactionID: transactionID,
+		AccountID:     e.AccountID,
+		Currency:      e.Currency,
+		Cost:          e.Amount,
+		HappenedAt:    e.HappenedAt,
+	})
+}
+
+// ApplyAmountChanged updates the cost of an expense. Amendments of other
+// transactions have no expense to update.
+func (v *Projection) ApplyAmountChanged(ctx context.Context, transactionID uuid.UUID, e transaction_events.AmountChanged) error {
+	if e.Side != values.Side_Debit {
+		return nil
+	}
+	return v.repository.UpdateExpenseCost(ctx, transactionID, e.Amount)
+}
+
+func (v *Projection) ApplyRedated(ctx context.Context, transactionID uuid.UUID, e transaction_events.Redated) error {
+	return v.repository.UpdateExpenseHappenedAt(ctx, transactionID, e.HappenedAt)
+}
+
+func (v *Projection) ApplyTransactionVoided(ctx context.Context, transactionID uuid.UUID, voidedAt time.Time, e transaction_events.TransactionVoided) error {
+	if e.TransactionType != values.TransactionType_Expense {
+		return nil
+	}
+	return v.repository.VoidExpense(ctx, transactionID, voidedAt)
+}
+
+func (v *Projection) ApplyExpectedReimbursementSet(ctx context.Context, transactionID uuid.UUID, e transaction_events.ExpectedReimbursementSet) error {
+	return v.repository.UpdateExpenseReceivable(ctx, transactionID, e.AccountID, e.From, e.Amount, e.Outstanding)
+}
+
+func (v *Projection) ApplyExpenseReimbursed(ctx context.Context, transactionID uuid.UUID, e transaction_events.ExpenseReimbursed) error {
+	return v.repository.UpdateExpenseReceived(ctx, transactionID, e.Amount, e.Outstanding)
+}
+
+func (v *Projection) ApplyReimbursementCancelled(ctx context.Context, transactionID uuid.UUID, e transaction_events.ReimbursementCancelled) error {
+	return v.repository.UpdateExpenseReceived(ctx, transactionID, e.Amount.Neg(), e.Outstanding)
+}
+
+func (v *Projection) ApplyReimbursementAmended(ctx context.Context, transactionID uuid.UUID, e transaction_events.ReimbursementAmended) error {
+	return v.repository.UpdateExpenseReceived(ctx, transactionID, e.Amount.Sub(e.PreviousAmount), e.Outstanding)
+}
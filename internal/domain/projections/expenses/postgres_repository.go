@@ -0,0 +1,154 @@
+package expenses
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/db"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+type PostgresRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewPostgresRepository(dbConn *sql.DB) (*PostgresRepository, error) {
+	return &PostgresRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}, nil
+}
+
+func (r *PostgresRepository) CreateExpense(ctx context.Context, expense ExpenseRecord) error {
+	return r.queries.In(ctx).CreateExpense(ctx, db.CreateExpenseParams{
+		TransactionID: expense.TransactionID,
+		AccountID:     expense.AccountID,
+		Currency:      string(expense.Currency),
+		Cost:          expense.Cost.String(),
+		HappenedAt:    expense.HappenedAt,
+	})
+}
+
+func (r *PostgresRepository) UpdateExpenseCost(ctx context.Context, transactionID uuid.UUID, cost decimal.Decimal) error {
+	return r.queries.In(ctx).UpdateExpenseCost(ctx, db.UpdateExpenseCostParams{
+		TransactionID: transactionID,
+		Cost:          cost.String(),
+	})
+}
+
+func (r *PostgresRepository) UpdateExpenseHappenedAt(ctx context.Context, transactionID uuid.UUID, happenedAt time.Time) error {
+	return r.queries.In(ctx).UpdateExpenseHappenedAt(ctx, db.UpdateExpenseHappenedAtParams{
+		TransactionID: transactionID,
+		HappenedAt:    happenedAt,
+	})
+}
+
+func (r *PostgresRepository) UpdateExpenseReceivable(ctx context.Context, transactionID uuid.UUID, accountID uuid.UUID, counterparty string, expected decimal.Decimal, outstanding decimal.Decimal) error {
+	return r.queries.In(ctx).UpdateExpenseReceivable(ctx, db.UpdateExpenseReceivableParams{
+		TransactionID:       transactionID,
+		ReceivableAccountID: uuid.NullUUID{UUID: accountID, Valid: accountID != uuid.Nil},
+		Counterparty:        counterparty,
+		Expected:            expected.String(),
+		Outstanding:         outstanding.String(),
+	})
+}
+
+func (r *PostgresRepository) UpdateExpenseReceived(ctx context.Context, transactionID uuid.UUID, amount decimal.Decimal, outstanding decimal.Decimal) error {
+	return r.queries.In(ctx).UpdateExpenseReceived(ctx, db.UpdateExpenseReceivedParams{
+		TransactionID: transactionID,
+		Amount:        amount.String(),
+		Outstanding:   outstanding.String(),
+	})
+}
+
+func (r *PostgresRepository) VoidExpense(ctx context.Context, transactionID uuid.UUID, voidedAt time.Time) error {
+	return r.queries.In(ctx).VoidExpense(ctx, db.VoidExpenseParams{
+		TransactionID: transactionID,
+		VoidedAt:      sql.NullTime{Time: voidedAt, Valid: true},
+	})
+}
+
+func (r *PostgresRepository) GetExpense(ctx context.Context, transactionID uuid.UUID) (ExpenseRecord, error) {
+	row, err := r.queries.In(ctx).GetExpense(ctx, transactionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ExpenseRecord{}, ErrNotFound
+	}
+	if err != nil {
+		return ExpenseRecord{}, err
+	}
+
+	cost, err := decimal.NewFromString(row.Cost)
+	if err != nil {
+		return ExpenseRecord{}, err
+	}
+	expected, err := decimal.NewFromString(row.Expected)
+	if err != nil {
+		return ExpenseRecord{}, err
+	}
+	received, err := decimal.NewFromString(row.Received)
+	if err != nil {
+		return ExpenseRecord{}, err
+	}
+	outstanding, err := decimal.NewFromString(row.Outstanding)
+	if err != nil {
+		return ExpenseRecord{}, err
+	}
+
+	return ExpenseRecord{
+		TransactionID:       row.TransactionID,
+		AccountID:           row.AccountID,
+		Currency:            values.Currency(row.Currency),
+		Cost:                cost,
+		ReceivableAccountID: row.ReceivableAccountID.UUID,
+		Counterparty:        row.Counterparty,
+		Expected:            expected,
+		Received:            received,
+		Outstanding:         outstanding,
+		NetCost:             cost.Sub(received),
+		HappenedAt:          row.HappenedAt,
+	}, nil
+}
+
+func (r *PostgresRepository) ListReceivables(ctx context.Context) (Receivables, error) {
+	byAccount, err := r.queries.In(ctx).ListOutstandingByAccount(ctx)
+	if err != nil {
+		return Receivables{}, err
+	}
+	byCounterparty, err := r.queries.In(ctx).ListOutstandingByCounterparty(ctx)
+	if err != nil {
+		return Receivables{}, err
+	}
+
+	receivables := Receivables{
+		ByAccount:      make([]AccountReceivable, 0, len(byAccount)),
+		ByCounterparty: make([]CounterpartyReceivable, 0, len(byCounterparty)),
+	}
+	for _, row := range byAccount {
+		outstanding, err := decimal.NewFromString(row.Outstanding)
+		if err != nil {
+			return Receivables{}, err
+		}
+		receivables.ByAccount = append(receivables.ByAccount, AccountReceivable{
+			AccountID:   row.ReceivableAccountID.UUID,
+			Currency:    values.Currency(row.Currency),
+			Outstanding: outstanding,
+		})
+	}
+	for _, row := range byCounterparty {
+		outstanding, err := decimal.NewFromString(row.Outstanding)
+		if err != nil {
+			return Receivables{}, err
+		}
+		receivables.ByCounterparty = append(receivables.ByCounterparty, CounterpartyReceivable{
+			Counterparty: row.Counterparty,
+			Currency:     values.Currency(row.Currency),
+			Outstanding:  outstanding,
+		})
+	}
+	return receivables, nil
+}
@@ -0,0 +1,124 @@
+package expenses
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/transaction"
+	transaction_events "github.com/somatom98/brokeli/internal/domain/transaction/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+var ErrNotFound = errors.New("not_found")
+
+// ExpenseRecord is an expense along with the reimbursements expected and
+// received for it. NetCost is what the expense costs once reimbursed.
+type ExpenseRecord struct {
+	TransactionID       uuid.UUID       `json:"transaction_id"`
+	AccountID           uuid.UUID       `json:"account_id"`
+	Currency            values.Currency `json:"currency"`
+	Cost                decimal.Decimal `json:"cost"`
+	ReceivableAccountID uuid.UUID       `json:"receivable_account_id"`
+	Counterparty        string          `json:"counterparty"`
+	Expected            decimal.Decimal `json:"expected"`
+	Received            decimal.Decimal `json:"received"`
+	Outstanding         decimal.Decimal `json:"outstanding"`
+	NetCost             decimal.Decimal `json:"net_cost"`
+	HappenedAt          time.Time       `json:"happened_at"`
+}
+
+type AccountReceivable struct {
+	AccountID   uuid.UUID       `json:"account_id"`
+	Currency    values.Currency `json:"currency"`
+	Outstanding decimal.Decimal `json:"outstanding"`
+}
+
+type CounterpartyReceivable struct {
+	Counterparty string          `json:"counterparty"`
+	Currency     values.Currency `json:"currency"`
+	Outstanding  decimal.Decimal `json:"outstanding"`
+}
+
+// Receivables are the reimbursements still outstanding, summed by the account
+// expecting them and by the counterparty owing them.
+type Receivables struct {
+	ByAccount      []AccountReceivable      `json:"by_account"`
+	ByCounterparty []CounterpartyReceivable `json:"by_counterparty"`
+}
+
+type Repository interface {
+	CreateExpense(ctx context.Context, expense ExpenseRecord) error
+	UpdateExpenseCost(ctx context.Context, transactionID uuid.UUID, cost decimal.Decimal) error
+	UpdateExpenseHappenedAt(ctx context.Context, transactionID uuid.UUID, happenedAt time.Time) error
+	UpdateExpenseReceivable(ctx context.Context, transactionID uuid.UUID, accountID uuid.UUID, counterparty string, expected decimal.Decimal, outstanding decimal.Decimal) error
+	// UpdateExpenseReceived adds the amount to the reimbursements received for
+	// the expense, a negative one for a cancelled reimbursement.
+	UpdateExpenseReceived(ctx context.Context, transactionID uuid.UUID, amount decimal.Decimal, outstanding decimal.Decimal) error
+	VoidExpense(ctx context.Context, transactionID uuid.UUID, voidedAt time.Time) error
+	GetExpense(ctx context.Context, transactionID uuid.UUID) (ExpenseRecord, error)
+	ListReceivables(ctx context.Context) (Receivables, error)
+}
+
+// SubscriptionName identifies the projection checkpoints in the event stores.
+const SubscriptionName = "expenses_projection"
+
+type Projection struct {
+	repository Repository
+}
+
+// NewProjection returns a projection that is not subscribed to the event
+// stores, for replaying events into a repository of choice.
+func NewProjection(repository Repository) *Projection {
+	return &Projection{
+		repository: repository,
+	}
+}
+
+func New(
+	transactionES event_store.Store[*transaction.Transaction],
+	accountES event_store.Store[*account.Account],
+	repository Repository,
+) *Projection {
+	p := NewProjection(repository)
+
+	transactionES.SubscribeNamed(context.Background(), SubscriptionName, p.HandleRecord)
+
+	return p
+}
+
+func (v *Projection) HandleRecord(ctx context.Context, record event_store.Record) error {
+	switch record.Type() {
+	case transaction_events.TypeMoneySpent:
+		return v.ApplyMoneySpent(ctx, record.AggregateID, record.Content().(transaction_events.MoneySpent))
+	case transaction_events.TypeExpenseSplit:
+		return v.ApplyExpenseSplit(ctx, record.AggregateID, record.Content().(transaction_events.ExpenseSplit))
+	case transaction_events.TypeAmountChanged:
+		return v.ApplyAmountChanged(ctx, record.AggregateID, record.Content().(transaction_events.AmountChanged))
+	case transaction_events.TypeRedated:
+		return v.ApplyRedated(ctx, record.AggregateID, record.Content().(transaction_events.Redated))
+	case transaction_events.TypeTransactionVoided:
+		return v.ApplyTransactionVoided(ctx, record.AggregateID, record.RecordedAt, record.Content().(transaction_events.TransactionVoided))
+	case transaction_events.TypeExpectedReimbursementSet:
+		return v.ApplyExpectedReimbursementSet(ctx, record.AggregateID, record.Content().(transaction_events.ExpectedReimbursementSet))
+	case transaction_events.TypeExpenseReimbursed:
+		return v.ApplyExpenseReimbursed(ctx, record.AggregateID, record.Content().(transaction_events.ExpenseReimbursed))
+	case transaction_events.TypeReimbursementCancelled:
+		return v.ApplyReimbursementCancelled(ctx, record.AggregateID, record.Content().(transaction_events.ReimbursementCancelled))
+	case transaction_events.TypeReimbursementAmended:
+		return v.ApplyReimbursementAmended(ctx, record.AggregateID, record.Content().(transaction_events.ReimbursementAmended))
+	}
+	return nil
+}
+
+func (v *Projection) GetExpense(ctx context.Context, transactionID uuid.UUID) (ExpenseRecord, error) {
+	return v.repository.GetExpense(ctx, transactionID)
+}
+
+func (v *Projection) ListReceivables(ctx context.Context) (Receivables, error) {
+	return v.repository.ListReceivables(ctx)
+}
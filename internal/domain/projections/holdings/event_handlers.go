@@ -0,0 +1,149 @@
+package holdings
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	transaction_events "github.com/somatom98/brokeli/internal/domain/transaction/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+func (v *Projection) ApplyMoneyInvested(ctx context.Context, transactionID uuid.UUID, e transaction_events.MoneyInvested) error {
+	err := v.repository.CreateTrade(ctx, Trade{
+		TransactionID: transactionID,
+		AccountID:     e.AccountID,
+		Ticker:        e.Ticker,
+		Kind:          TradeKind_Buy,
+		Units:         e.Units,
+		Price:         e.Price,
+		Ratio:         decimal.Zero,
+		Currency:      e.PriceCurrency,
+		HappenedAt:    e.HappenedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	holding, err := v.getHolding(ctx, e.AccountID, e.Ticker, e.PriceCurrency)
+	if err != nil {
+		return err
+	}
+
+	holding.buy(Lot{
+		TransactionID: transactionID,
+		Units:         e.Units,
+		Price:         e.Price,
+		AcquiredAt:    e.HappenedAt,
+	})
+
+	return v.repository.SaveHolding(ctx, holding)
+}
+
+// ApplyInvestmentSold realizes the gain of the proceeds over the cost basis the
+// sale was recorded with, less the fee when it is charged in the currency of
+// the holding.
+func (v *Projection) ApplyInvestmentSold(ctx context.Context, transactionID uuid.UUID, e transaction_events.InvestmentSold) error {
+	err := v.repository.CreateTrade(ctx, Trade{
+		TransactionID: transactionID,
+		AccountID:     e.AccountID,
+		Ticker:        e.Ticker,
+		Kind:          TradeKind_Sell,
+		Units:         e.Units,
+		Price:         e.Price,
+		Ratio:         decimal.Zero,
+		Currency:      e.PriceCurrency,
+		HappenedAt:    e.HappenedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	holding, err := v.getHolding(ctx, e.AccountID, e.Ticker, e.PriceCurrency)
+	if err != nil {
+		return err
+	}
+
+	holding.sell(e.Units, e.CostBasis)
+	gain := e.Units.Mul(e.Price).Sub(e.CostBasis)
+	if e.FeeCurrency == holding.Currency {
+		gain = gain.Sub(e.Fee)
+	}
+	holding.RealizedGain = holding.RealizedGain.Add(gain)
+
+	return v.repository.SaveHolding(ctx, holding)
+}
+
+func (v *Projection) ApplyStockSplit(ctx context.Context, transactionID uuid.UUID, e transaction_events.StockSplit) error {
+	holding, err := v.repository.GetHolding(ctx, e.AccountID, e.Ticker)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = v.repository.CreateTrade(ctx, Trade{
+		TransactionID: transactionID,
+		AccountID:     e.AccountID,
+		Ticker:        e.Ticker,
+		Kind:          TradeKind_Split,
+		Units:         decimal.Zero,
+		Price:         decimal.Zero,
+		Ratio:         e.Ratio,
+		Currency:      holding.Currency,
+		HappenedAt:    e.HappenedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	holding.split(e.Ratio)
+
+	return v.repository.SaveHolding(ctx, holding)
+}
+
+// ApplyDividendReceived records the dividend as an income of the holding,
+// leaving its units and cost untouched.
+func (v *Projection) ApplyDividendReceived(ctx context.Context, transactionID uuid.UUID, e transaction_events.DividendReceived) error {
+	return v.repository.CreateTrade(ctx, Trade{
+		TransactionID: transactionID,
+		AccountID:     e.AccountID,
+		Ticker:        e.Ticker,
+		Kind:          TradeKind_Income,
+		Units:         e.Units,
+		Price:         e.Price,
+		Ratio:         decimal.Zero,
+		Currency:      e.PriceCurrency,
+		HappenedAt:    e.HappenedAt,
+	})
+}
+
+func (v *Projection) ApplyInterestReceived(ctx context.Context, transactionID uuid.UUID, e transaction_events.InterestReceived) error {
+	return v.repository.CreateTrade(ctx, Trade{
+		TransactionID: transactionID,
+		AccountID:     e.AccountID,
+		Ticker:        e.Ticker,
+		Kind:          TradeKind_Income,
+		Units:         e.Units,
+		Price:         e.Price,
+		Ratio:         decimal.Zero,
+		Currency:      e.PriceCurrency,
+		HappenedAt:    e.HappenedAt,
+	})
+}
+
+// getHolding returns the holding of a ticker on an account, or an empty one in
+// currency if it was never bought.
+func (v *Projection) getHolding(ctx context.Context, accountID uuid.UUID, ticker string, currency values.Currency) (Holding, error) {
+	holding, err := v.repository.GetHolding(ctx, accountID, ticker)
+	if errors.Is(err, ErrNotFound) {
+		return Holding{
+			AccountID: accountID,
+			Ticker:    ticker,
+			Currency:  currency,
+		}, nil
+	}
+	return holding, err
+}
@@ -0,0 +1,57 @@
+package holdings
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+func (h *Holding) buy(lot Lot) {
+	h.Lots = append(h.Lots, lot)
+	h.Units = h.Units.Add(lot.Units)
+	h.CostBasis = h.CostBasis.Add(lot.Units.Mul(lot.Price))
+	h.updateAverageCost()
+}
+
+// sell takes units out of the lots of the holding, oldest first, at the cost
+// the sale was recorded with. Units sold beyond the ones held are not taken
+// out, leaving the holding empty.
+func (h *Holding) sell(units decimal.Decimal, cost decimal.Decimal) {
+	units = decimal.Min(units, h.Units)
+	if !units.IsPositive() {
+		return
+	}
+
+	remaining := units
+	lots := make([]Lot, 0, len(h.Lots))
+	for _, lot := range h.Lots {
+		taken := decimal.Min(remaining, lot.Units)
+		remaining = remaining.Sub(taken)
+		lot.Units = lot.Units.Sub(taken)
+		if lot.Units.IsPositive() {
+			lots = append(lots, lot)
+		}
+	}
+
+	h.Lots = lots
+	h.Units = h.Units.Sub(units)
+	h.CostBasis = h.CostBasis.Sub(cost)
+	h.updateAverageCost()
+}
+
+// split multiplies the units of every lot by ratio and divides their price by
+// it, leaving the cost basis unchanged.
+func (h *Holding) split(ratio decimal.Decimal) {
+	for i := range h.Lots {
+		h.Lots[i].Units = h.Lots[i].Units.Mul(ratio)
+		h.Lots[i].Price = h.Lots[i].Price.Div(ratio)
+	}
+	h.Units = h.Units.Mul(ratio)
+	h.updateAverageCost()
+}
+
+func (h *Holding) updateAverageCost() {
+	if !h.Units.IsPositive() {
+		h.AverageCost = decimal.Zero
+		return
+	}
+	h.AverageCost = h.CostBasis.Div(h.Units)
+}
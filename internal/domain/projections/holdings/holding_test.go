@@ -0,0 +1,140 @@
+package holdings_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/projections/holdings"
+	"github.com/somatom98/brokeli/internal/domain/transaction/events"
+)
+
+func TestProjection_Holding(t *testing.T) {
+	ctx := context.Background()
+	accountID := uuid.New()
+	happenedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T) *holdings.Projection {
+		projection := holdings.NewProjection(holdings.NewInMemoryRepository())
+		for i, price := range []int64{100, 130} {
+			require.NoError(t, projection.ApplyMoneyInvested(ctx, uuid.New(), events.MoneyInvested{
+				AccountID:     accountID,
+				Ticker:        "VWCE",
+				Units:         decimal.NewFromInt(10),
+				Price:         decimal.NewFromInt(price),
+				PriceCurrency: "EUR",
+				Fee:           decimal.Zero,
+				FeeCurrency:   "EUR",
+				HappenedAt:    happenedAt.AddDate(0, 0, i),
+			}))
+		}
+		return projection
+	}
+	sell := func(t *testing.T, projection *holdings.Projection, units int64, price int64, costBasis int64) {
+		require.NoError(t, projection.ApplyInvestmentSold(ctx, uuid.New(), events.InvestmentSold{
+			AccountID:     accountID,
+			Ticker:        "VWCE",
+			Units:         decimal.NewFromInt(units),
+			Price:         decimal.NewFromInt(price),
+			PriceCurrency: "EUR",
+			Fee:           decimal.Zero,
+			FeeCurrency:   "EUR",
+			CostBasis:     decimal.NewFromInt(costBasis),
+			HappenedAt:    happenedAt.AddDate(0, 1, 0),
+		}))
+	}
+	holding := func(t *testing.T, projection *holdings.Projection) holdings.Holding {
+		all, err := projection.ListHoldingsByAccount(ctx, accountID)
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		return all[0]
+	}
+
+	t.Run("should take a partial sale spanning lots out of the oldest lots", func(t *testing.T) {
+		// arrange
+		projection := setup(t)
+
+		// act
+		sell(t, projection, 15, 150, 1650)
+
+		// assert
+		h := holding(t, projection)
+		assert.Equal(t, "5", h.Units.String())
+		assert.Equal(t, "650", h.CostBasis.String())
+		assert.Equal(t, "600", h.RealizedGain.String())
+		require.Len(t, h.Lots, 1)
+		assert.Equal(t, "5", h.Lots[0].Units.String())
+		assert.Equal(t, "130", h.Lots[0].Price.String())
+	})
+
+	t.Run("should realize the gain over the cost basis the sale was recorded with", func(t *testing.T) {
+		// arrange
+		projection := setup(t)
+
+		// act
+		sell(t, projection, 15, 150, 1725)
+
+		// assert
+		h := holding(t, projection)
+		assert.Equal(t, "5", h.Units.String())
+		assert.Equal(t, "575", h.CostBasis.String())
+		assert.Equal(t, "525", h.RealizedGain.String())
+		assert.Equal(t, "115", h.AverageCost.String())
+	})
+
+	t.Run("should sell the units of a split at the split price", func(t *testing.T) {
+		// arrange
+		projection := setup(t)
+		require.NoError(t, projection.ApplyStockSplit(ctx, uuid.New(), events.StockSplit{
+			AccountID:  accountID,
+			Ticker:     "VWCE",
+			Ratio:      decimal.NewFromInt(2),
+			HappenedAt: happenedAt.AddDate(0, 0, 15),
+		}))
+
+		// act
+		sell(t, projection, 30, 75, 1650)
+
+		// assert
+		h := holding(t, projection)
+		assert.Equal(t, "10", h.Units.String())
+		assert.Equal(t, "650", h.CostBasis.String())
+		assert.Equal(t, "600", h.RealizedGain.String())
+		assert.Equal(t, "65", h.AverageCost.String())
+	})
+
+	t.Run("should empty the holding when every unit is sold", func(t *testing.T) {
+		// arrange
+		projection := setup(t)
+
+		// act
+		sell(t, projection, 20, 120, 2300)
+
+		// assert
+		h := holding(t, projection)
+		assert.True(t, h.Units.IsZero())
+		assert.True(t, h.CostBasis.IsZero())
+		assert.True(t, h.AverageCost.IsZero())
+		assert.Empty(t, h.Lots)
+		assert.Equal(t, "100", h.RealizedGain.String())
+	})
+
+	t.Run("should not sell more units than held", func(t *testing.T) {
+		// arrange
+		projection := setup(t)
+
+		// act
+		sell(t, projection, 25, 120, 2300)
+
+		// assert
+		h := holding(t, projection)
+		assert.True(t, h.Units.IsZero())
+		assert.True(t, h.CostBasis.IsZero())
+		assert.Empty(t, h.Lots)
+	})
+}
@@ -0,0 +1,90 @@
+package holdings
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type holdingKey struct {
+	accountID uuid.UUID
+	ticker    string
+}
+
+type InMemoryRepository struct {
+	holdings map[holdingKey]Holding
+	trades   []Trade
+}
+
+func NewInMemoryRepository() *InMemoryRepository {
+	return &InMemoryRepository{
+		holdings: make(map[holdingKey]Holding),
+	}
+}
+
+func (r *InMemoryRepository) GetHolding(ctx context.Context, accountID uuid.UUID, ticker string) (Holding, error) {
+	holding, ok := r.holdings[holdingKey{accountID, ticker}]
+	if !ok {
+		return Holding{}, ErrNotFound
+	}
+	holding.Lots = slices.Clone(holding.Lots)
+	return holding, nil
+}
+
+func (r *InMemoryRepository) SaveHolding(ctx context.Context, holding Holding) error {
+	holding.Lots = slices.Clone(holding.Lots)
+	r.holdings[holdingKey{holding.AccountID, holding.Ticker}] = holding
+	return nil
+}
+
+func (r *InMemoryRepository) ListHoldings(ctx context.Context) ([]Holding, error) {
+	holdings := make([]Holding, 0, len(r.holdings))
+	for _, holding := range r.holdings {
+		holdings = append(holdings, holding)
+	}
+	sortHoldings(holdings)
+	return holdings, nil
+}
+
+func (r *InMemoryRepository) ListHoldingsByAccount(ctx context.Context, accountID uuid.UUID) ([]Holding, error) {
+	holdings := make([]Holding, 0)
+	for key, holding := range r.holdings {
+		if key.accountID == accountID {
+			holdings = append(holdings, holding)
+		}
+	}
+	sortHoldings(holdings)
+	return holdings, nil
+}
+
+func (r *InMemoryRepository) CreateTrade(ctx context.Context, trade Trade) error {
+	if slices.ContainsFunc(r.trades, func(t Trade) bool { return t.TransactionID == trade.TransactionID }) {
+		return nil
+	}
+	r.trades = append(r.trades, trade)
+	return nil
+}
+
+func (r *InMemoryRepository) ListTrades(ctx context.Context, accountID uuid.UUID, ticker string) ([]Trade, error) {
+	trades := make([]Trade, 0)
+	for _, trade := range r.trades {
+		if trade.AccountID == accountID && trade.Ticker == ticker {
+			trades = append(trades, trade)
+		}
+	}
+	slices.SortStableFunc(trades, func(a, b Trade) int {
+		return a.HappenedAt.Compare(b.HappenedAt)
+	})
+	return trades, nil
+}
+
+func sortHoldings(holdings []Holding) {
+	slices.SortFunc(holdings, func(a, b Holding) int {
+		if c := strings.Compare(a.AccountID.String(), b.AccountID.String()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Ticker, b.Ticker)
+	})
+}
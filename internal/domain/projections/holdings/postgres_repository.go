@@ -0,0 +1,209 @@
+package holdings
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/db"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+type PostgresRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewPostgresRepository(dbConn *sql.DB) (*PostgresRepository, error) {
+	return &PostgresRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}, nil
+}
+
+func (r *PostgresRepository) GetHolding(ctx context.Context, accountID uuid.UUID, ticker string) (Holding, error) {
+	row, err := r.queries.In(ctx).GetHolding(ctx, db.GetHoldingParams{
+		AccountID: accountID,
+		Ticker:    ticker,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Holding{}, ErrNotFound
+	}
+	if err != nil {
+		return Holding{}, err
+	}
+
+	return r.toHolding(ctx, row)
+}
+
+// SaveHolding writes the holding and replaces its lots in a single
+// transaction.
+func (r *PostgresRepository) SaveHolding(ctx context.Context, holding Holding) error {
+	return db.InTx(ctx, r.db, func(qtx *db.Queries) error {
+		err := qtx.UpsertHolding(ctx, db.UpsertHoldingParams{
+			AccountID:    holding.AccountID,
+			Ticker:       holding.Ticker,
+			Currency:     string(holding.Currency),
+			Units:        holding.Units.String(),
+			CostBasis:    holding.CostBasis.String(),
+			AverageCost:  holding.AverageCost.String(),
+			RealizedGain: holding.RealizedGain.String(),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = qtx.DeleteHoldingLots(ctx, db.DeleteHoldingLotsParams{
+			AccountID: holding.AccountID,
+			Ticker:    holding.Ticker,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, lot := range holding.Lots {
+			err = qtx.CreateHoldingLot(ctx, db.CreateHoldingLotParams{
+				AccountID:     holding.AccountID,
+				Ticker:        holding.Ticker,
+				TransactionID: lot.TransactionID,
+				Units:         lot.Units.String(),
+				Price:         lot.Price.String(),
+				AcquiredAt:    lot.AcquiredAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *PostgresRepository) ListHoldings(ctx context.Context) ([]Holding, error) {
+	rows, err := r.queries.In(ctx).ListHoldings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.toHoldings(ctx, rows)
+}
+
+func (r *PostgresRepository) ListHoldingsByAccount(ctx context.Context, accountID uuid.UUID) ([]Holding, error) {
+	rows, err := r.queries.In(ctx).ListHoldingsByAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.toHoldings(ctx, rows)
+}
+
+func (r *PostgresRepository) CreateTrade(ctx context.Context, trade Trade) error {
+	return r.queries.In(ctx).CreateHoldingTrade(ctx, db.CreateHoldingTradeParams{
+		TransactionID: trade.TransactionID,
+		AccountID:     trade.AccountID,
+		Ticker:        trade.Ticker,
+		Kind:          string(trade.Kind),
+		Units:         trade.Units.String(),
+		Price:         trade.Price.String(),
+		Ratio:         trade.Ratio.String(),
+		Currency:      string(trade.Currency),
+		HappenedAt:    trade.HappenedAt,
+	})
+}
+
+func (r *PostgresRepository) ListTrades(ctx context.Context, accountID uuid.UUID, ticker string) ([]Trade, error) {
+	rows, err := r.queries.In(ctx).ListHoldingTrades(ctx, db.ListHoldingTradesParams{
+		AccountID: accountID,
+		Ticker:    ticker,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]Trade, 0, len(rows))
+	for _, row := range rows {
+		amounts, err := parseDecimals(row.Units, row.Price, row.Ratio)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, Trade{
+			TransactionID: row.TransactionID,
+			AccountID:     row.AccountID,
+			Ticker:        row.Ticker,
+			Kind:          TradeKind(row.Kind),
+			Units:         amounts[0],
+			Price:         amounts[1],
+			Ratio:         amounts[2],
+			Currency:      values.Currency(row.Currency),
+			HappenedAt:    row.HappenedAt,
+		})
+	}
+
+	return trades, nil
+}
+
+func (r *PostgresRepository) toHoldings(ctx context.Context, rows []db.Holding) ([]Holding, error) {
+	holdings := make([]Holding, 0, len(rows))
+	for _, row := range rows {
+		holding, err := r.toHolding(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		holdings = append(holdings, holding)
+	}
+	return holdings, nil
+}
+
+func (r *PostgresRepository) toHolding(ctx context.Context, row db.Holding) (Holding, error) {
+	amounts, err := parseDecimals(row.Units, row.CostBasis, row.AverageCost, row.RealizedGain)
+	if err != nil {
+		return Holding{}, err
+	}
+
+	lotRows, err := r.queries.In(ctx).ListHoldingLots(ctx, db.ListHoldingLotsParams{
+		AccountID: row.AccountID,
+		Ticker:    row.Ticker,
+	})
+	if err != nil {
+		return Holding{}, err
+	}
+
+	lots := make([]Lot, 0, len(lotRows))
+	for _, lotRow := range lotRows {
+		lotAmounts, err := parseDecimals(lotRow.Units, lotRow.Price)
+		if err != nil {
+			return Holding{}, err
+		}
+		lots = append(lots, Lot{
+			TransactionID: lotRow.TransactionID,
+			Units:         lotAmounts[0],
+			Price:         lotAmounts[1],
+			AcquiredAt:    lotRow.AcquiredAt,
+		})
+	}
+
+	return Holding{
+		AccountID:    row.AccountID,
+		Ticker:       row.Ticker,
+		Currency:     values.Currency(row.Currency),
+		Units:        amounts[0],
+		CostBasis:    amounts[1],
+		AverageCost:  amounts[2],
+		RealizedGain: amounts[3],
+		Lots:         lots,
+	}, nil
+}
+
+func parseDecimals(strs ...string) ([]decimal.Decimal, error) {
+	amounts := make([]decimal.Decimal, 0, len(strs))
+	for _, str := range strs {
+		amount, err := decimal.NewFromString(str)
+		if err != nil {
+			return nil, err
+		}
+		amounts = append(amounts, amount)
+	}
+	return amounts, nil
+}
@@ -0,0 +1,128 @@
+package holdings
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/transaction"
+	transaction_events "github.com/somatom98/brokeli/internal/domain/transaction/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+var ErrNotFound = errors.New("not_found")
+
+// Lot is the units of a purchase still held, at the price they were bought.
+type Lot struct {
+	TransactionID uuid.UUID       `json:"transaction_id"`
+	Units         decimal.Decimal `json:"units"`
+	Price         decimal.Decimal `json:"price"`
+	AcquiredAt    time.Time       `json:"acquired_at"`
+}
+
+// Holding is the units of a ticker held on an account, what they cost and the
+// gain realized selling them. Lots are listed oldest first.
+type Holding struct {
+	AccountID    uuid.UUID       `json:"account_id"`
+	Ticker       string          `json:"ticker"`
+	Currency     values.Currency `json:"currency"`
+	Units        decimal.Decimal `json:"units"`
+	CostBasis    decimal.Decimal `json:"cost_basis"`
+	AverageCost  decimal.Decimal `json:"average_cost"`
+	RealizedGain decimal.Decimal `json:"realized_gain"`
+	Lots         []Lot           `json:"lots"`
+}
+
+// TradeKind tells how a trade changed a holding.
+type TradeKind string
+
+const (
+	TradeKind_Buy    TradeKind = "BUY"
+	TradeKind_Sell   TradeKind = "SELL"
+	TradeKind_Split  TradeKind = "SPLIT"
+	TradeKind_Income TradeKind = "INCOME"
+)
+
+// Trade is a purchase, sale, split of a holding or an income paid on it.
+// Units are the ones bought, sold or paid on at Price, while splits carry
+// their Ratio instead.
+type Trade struct {
+	TransactionID uuid.UUID       `json:"transaction_id"`
+	AccountID     uuid.UUID       `json:"account_id"`
+	Ticker        string          `json:"ticker"`
+	Kind          TradeKind       `json:"kind"`
+	Units         decimal.Decimal `json:"units"`
+	Price         decimal.Decimal `json:"price"`
+	Ratio         decimal.Decimal `json:"ratio"`
+	Currency      values.Currency `json:"currency"`
+	HappenedAt    time.Time       `json:"happened_at"`
+}
+
+type Repository interface {
+	// GetHolding returns ErrNotFound if the ticker was never bought on the
+	// account.
+	GetHolding(ctx context.Context, accountID uuid.UUID, ticker string) (Holding, error)
+	SaveHolding(ctx context.Context, holding Holding) error
+	ListHoldings(ctx context.Context) ([]Holding, error)
+	ListHoldingsByAccount(ctx context.Context, accountID uuid.UUID) ([]Holding, error)
+	CreateTrade(ctx context.Context, trade Trade) error
+	// ListTrades returns the trades of a ticker on an account, oldest first.
+	ListTrades(ctx context.Context, accountID uuid.UUID, ticker string) ([]Trade, error)
+}
+
+// SubscriptionName identifies the projection checkpoints in the event stores.
+const SubscriptionName = "holdings_projection"
+
+type Projection struct {
+	repository Repository
+}
+
+// NewProjection returns a projection that is not subscribed to the event
+// stores, for replaying events into a repository of choice.
+func NewProjection(repository Repository) *Projection {
+	return &Projection{
+		repository: repository,
+	}
+}
+
+func New(
+	transactionES event_store.Store[*transaction.Transaction],
+	repository Repository,
+) *Projection {
+	p := NewProjection(repository)
+
+	transactionES.SubscribeNamed(context.Background(), SubscriptionName, p.HandleRecord)
+
+	return p
+}
+
+func (v *Projection) HandleRecord(ctx context.Context, record event_store.Record) error {
+	switch record.Type() {
+	case transaction_events.TypeMoneyInvested:
+		return v.ApplyMoneyInvested(ctx, record.AggregateID, record.Content().(transaction_events.MoneyInvested))
+	case transaction_events.TypeInvestmentSold:
+		return v.ApplyInvestmentSold(ctx, record.AggregateID, record.Content().(transaction_events.InvestmentSold))
+	case transaction_events.TypeStockSplit:
+		return v.ApplyStockSplit(ctx, record.AggregateID, record.Content().(transaction_events.StockSplit))
+	case transaction_events.TypeDividendReceived:
+		return v.ApplyDividendReceived(ctx, record.AggregateID, record.Content().(transaction_events.DividendReceived))
+	case transaction_events.TypeInterestReceived:
+		return v.ApplyInterestReceived(ctx, record.AggregateID, record.Content().(transaction_events.InterestReceived))
+	}
+	return nil
+}
+
+func (v *Projection) ListHoldings(ctx context.Context) ([]Holding, error) {
+	return v.repository.ListHoldings(ctx)
+}
+
+func (v *Projection) ListHoldingsByAccount(ctx context.Context, accountID uuid.UUID) ([]Holding, error) {
+	return v.repository.ListHoldingsByAccount(ctx, accountID)
+}
+
+func (v *Projection) ListTrades(ctx context.Context, accountID uuid.UUID, ticker string) ([]Trade, error) {
+	return v.repository.ListTrades(ctx, accountID, ticker)
+}
@@ -0,0 +1,28 @@
+package recurring_rules
+
+import (
+	"context"
+
+	"github.com/somatom98/brokeli/internal/domain/recurring"
+	"github.com/somatom98/brokeli/internal/domain/recurring/events"
+)
+
+func (v *Projection) ApplyRuleCreated(ctx context.Context, e events.RuleCreated) error {
+	return v.repository.CreateRule(ctx, Rule{
+		ID:          e.RuleID,
+		Kind:        recurring.Kind(e.Kind),
+		AccountID:   e.AccountID,
+		Currency:    e.Currency,
+		Amount:      e.Amount,
+		Category:    e.Category,
+		Description: e.Description,
+		Frequency:   recurring.Frequency(e.Frequency),
+		Interval:    e.Interval,
+		StartDate:   e.StartDate,
+		EndDate:     e.EndDate,
+	})
+}
+
+func (v *Projection) ApplyRuleFinished(ctx context.Context, e events.RuleFinished) error {
+	return v.repository.FinishRule(ctx, e.RuleID)
+}
@@ -0,0 +1,55 @@
+package recurring_rules
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type InMemoryRepository struct {
+	rules map[uuid.UUID]Rule
+}
+
+func NewInMemoryRepository() *InMemoryRepository {
+	return &InMemoryRepository{
+		rules: make(map[uuid.UUID]Rule),
+	}
+}
+
+func (r *InMemoryRepository) CreateRule(ctx context.Context, rule Rule) error {
+	if _, ok := r.rules[rule.ID]; !ok {
+		r.rules[rule.ID] = rule
+	}
+	return nil
+}
+
+func (r *InMemoryRepository) FinishRule(ctx context.Context, id uuid.UUID) error {
+	rule, ok := r.rules[id]
+	if !ok {
+		return nil
+	}
+	rule.Finished = true
+	r.rules[id] = rule
+	return nil
+}
+
+func (r *InMemoryRepository) ListRules(ctx context.Context, activeOnly bool) ([]Rule, error) {
+	rules := make([]Rule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		if activeOnly && rule.Finished {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	slices.SortFunc(rules, func(a, b Rule) int {
+		if c := a.StartDate.Compare(b.StartDate); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+
+	return rules, nil
+}
@@ -0,0 +1,83 @@
+package recurring_rules
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/db"
+	"github.com/somatom98/brokeli/internal/domain/recurring"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+type PostgresRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewPostgresRepository(dbConn *sql.DB) (*PostgresRepository, error) {
+	return &PostgresRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}, nil
+}
+
+func (r *PostgresRepository) CreateRule(ctx context.Context, rule Rule) error {
+	params := db.CreateRecurringRuleParams{
+		ID:          rule.ID,
+		Kind:        string(rule.Kind),
+		AccountID:   rule.AccountID,
+		Currency:    string(rule.Currency),
+		Amount:      rule.Amount.String(),
+		Category:    rule.Category,
+		Description: rule.Description,
+		Frequency:   string(rule.Frequency),
+		Interval:    int32(rule.Interval),
+		StartDate:   rule.StartDate,
+	}
+	if rule.EndDate != nil {
+		params.EndDate = sql.NullTime{Time: *rule.EndDate, Valid: true}
+	}
+
+	return r.queries.In(ctx).CreateRecurringRule(ctx, params)
+}
+
+func (r *PostgresRepository) FinishRule(ctx context.Context, id uuid.UUID) error {
+	return r.queries.In(ctx).FinishRecurringRule(ctx, id)
+}
+
+func (r *PostgresRepository) ListRules(ctx context.Context, activeOnly bool) ([]Rule, error) {
+	rows, err := r.queries.In(ctx).ListRecurringRules(ctx, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0, len(rows))
+	for _, row := range rows {
+		amount, err := decimal.NewFromString(row.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		rule := Rule{
+			ID:          row.ID,
+			Kind:        recurring.Kind(row.Kind),
+			AccountID:   row.AccountID,
+			Currency:    values.Currency(row.Currency),
+			Amount:      amount,
+			Category:    row.Category,
+			Description: row.Description,
+			Frequency:   recurring.Frequency(row.Frequency),
+			Interval:    int(row.Interval),
+			StartDate:   row.StartDate,
+			Finished:    row.Finished,
+		}
+		if row.EndDate.Valid {
+			rule.EndDate = &row.EndDate.Time
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,92 @@
+package recurring_rules
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/recurring"
+	"github.com/somatom98/brokeli/internal/domain/recurring/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+type Rule struct {
+	ID          uuid.UUID           `json:"id"`
+	Kind        recurring.Kind      `json:"kind"`
+	AccountID   uuid.UUID           `json:"account_id"`
+	Currency    values.Currency     `json:"currency"`
+	Amount      decimal.Decimal     `json:"amount"`
+	Category    string              `json:"category"`
+	Description string              `json:"description"`
+	Frequency   recurring.Frequency `json:"frequency"`
+	Interval    int                 `json:"interval"`
+	StartDate   time.Time           `json:"start_date"`
+	EndDate     *time.Time          `json:"end_date,omitempty"`
+	Finished    bool                `json:"finished"`
+}
+
+type Repository interface {
+	CreateRule(ctx context.Context, rule Rule) error
+	FinishRule(ctx context.Context, id uuid.UUID) error
+	// ListRules returns the rules by start date, or only the ones not
+	// finished yet.
+	ListRules(ctx context.Context, activeOnly bool) ([]Rule, error)
+}
+
+// SubscriptionName identifies the projection checkpoints in the event stores.
+const SubscriptionName = "recurring_rules_projection"
+
+type Projection struct {
+	repository Repository
+}
+
+// NewProjection returns a projection that is not subscribed to the event
+// stores, for replaying events into a repository of choice.
+func NewProjection(repository Repository) *Projection {
+	return &Projection{
+		repository: repository,
+	}
+}
+
+func New(
+	ruleES event_store.Store[*recurring.Rule],
+	repository Repository,
+) *Projection {
+	p := NewProjection(repository)
+
+	ruleES.SubscribeNamed(context.Background(), SubscriptionName, p.HandleRecord)
+
+	return p
+}
+
+func (v *Projection) HandleRecord(ctx context.Context, record event_store.Record) error {
+	switch record.Type() {
+	case events.TypeRuleCreated:
+		return v.ApplyRuleCreated(ctx, record.Content().(events.RuleCreated))
+	case events.TypeRuleFinished:
+		return v.ApplyRuleFinished(ctx, record.Content().(events.RuleFinished))
+	}
+	return nil
+}
+
+func (v *Projection) ListRules(ctx context.Context, activeOnly bool) ([]Rule, error) {
+	return v.repository.ListRules(ctx, activeOnly)
+}
+
+// ListActiveRuleIDs returns the rules that may still have occurrences to
+// post, for the scheduler to post them.
+func (v *Projection) ListActiveRuleIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rules, err := v.repository.ListRules(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(rules))
+	for _, rule := range rules {
+		ids = append(ids, rule.ID)
+	}
+
+	return ids, nil
+}
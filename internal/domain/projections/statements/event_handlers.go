@@ -0,0 +1,111 @@
+package statements
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	account_events "github.com/somatom98/brokeli/internal/domain/account/events"
+	transaction_events "github.com/somatom98/brokeli/internal/domain/transaction/events"
+)
+
+func (v *Projection) ApplyMoneySpent(ctx context.Context, transactionID uuid.UUID, e transaction_events.MoneySpent) error {
+	return v.repository.CreateMovement(ctx, Movement{
+		TransactionID: transactionID,
+		AccountID:     e.AccountID,
+		Currency:      e.Currency,
+		Amount:        e.Amount.Neg(),
+		HappenedAt:    e.HappenedAt,
+	})
+}
+
+// ApplyExpenseSplit moves the sum of the lines paid from each account, as a
+// movement is kept by transaction and account.
+func (v *Projection) ApplyExpenseSplit(ctx context.Context, transactionID uuid.UUID, e transaction_events.ExpenseSplit) error {
+	amounts := make(map[uuid.UUID]decimal.Decimal)
+	var accountIDs []uuid.UUID
+	for _, line := range e.Lines {
+		if _, ok := amounts[line.AccountID]; !ok {
+			accountIDs = append(accountIDs, line.AccountID)
+		}
+		amounts[line.AccountID] = amounts[line.AccountID].Sub(line.Amount)
+	}
+
+	for _, accountID := range accountIDs {
+		err := v.repository.CreateMovement(ctx, Movement{
+			TransactionID: transactionID,
+			AccountID:     accountID,
+			Currency:      e.Currency,
+			Amount:        amounts[accountID],
+			HappenedAt:    e.HappenedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *Projection) ApplyMoneyReceived(ctx context.Context, transactionID uuid.UUID, e transaction_events.MoneyReceived) error {
+	return v.repository.CreateMovement(ctx, Movement{
+		TransactionID: transactionID,
+		AccountID:     e.AccountID,
+		Currency:      e.Currency,
+		Amount:        e.Amount,
+		HappenedAt:    e.HappenedAt,
+	})
+}
+
+// ApplyMoneyTransfered records a transfer into an account as a payment, which
+// is how a credit card is paid off from a checking account.
+func (v *Projection) ApplyMoneyTransfered(ctx context.Context, transactionID uuid.UUID, e transaction_events.MoneyTransfered) error {
+	err := v.repository.CreateMovement(ctx, Movement{
+		TransactionID: transactionID,
+		AccountID:     e.FromAccountID,
+		Currency:      e.FromCurrency,
+		Amount:        e.FromAmount.Neg(),
+		HappenedAt:    e.HappenedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return v.repository.CreateMovement(ctx, Movement{
+		TransactionID: transactionID,
+		AccountID:     e.ToAccountID,
+		Currency:      e.ToCurrency,
+		Amount:        e.ToAmount,
+		IsPayment:     true,
+		HappenedAt:    e.HappenedAt,
+	})
+}
+
+func (v *Projection) ApplyAmountChanged(ctx context.Context, transactionID uuid.UUID, e transaction_events.AmountChanged) error {
+	return v.repository.UpdateMovementAmount(ctx, transactionID, e.AccountID, e.Side.Signed(e.Amount))
+}
+
+func (v *Projection) ApplyRedated(ctx context.Context, transactionID uuid.UUID, e transaction_events.Redated) error {
+	return v.repository.UpdateMovementHappenedAt(ctx, transactionID, e.HappenedAt)
+}
+
+func (v *Projection) ApplyTransactionVoided(ctx context.Context, transactionID uuid.UUID, e transaction_events.TransactionVoided) error {
+	return v.repository.DeleteMovements(ctx, transactionID)
+}
+
+func (v *Projection) ApplyStatementClosed(ctx context.Context, e account_events.StatementClosed) error {
+	var periodStart *time.Time
+	if !e.PeriodStart.IsZero() {
+		periodStart = &e.PeriodStart
+	}
+
+	return v.repository.CreateStatement(ctx, Statement{
+		AccountID:   e.AccountID,
+		Currency:    e.Currency,
+		PeriodStart: periodStart,
+		ClosingDate: e.ClosingDate,
+		DueDate:     e.DueDate,
+		Balance:     e.Balance,
+		MinimumDue:  e.MinimumDue,
+	})
+}
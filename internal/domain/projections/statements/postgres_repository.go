@@ -0,0 +1,123 @@
+package statements
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/db"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+type PostgresRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewPostgresRepository(dbConn *sql.DB) (*PostgresRepository, error) {
+	return &PostgresRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}, nil
+}
+
+func (r *PostgresRepository) CreateMovement(ctx context.Context, movement Movement) error {
+	return r.queries.In(ctx).CreateCardMovement(ctx, db.CreateCardMovementParams{
+		TransactionID: movement.TransactionID,
+		AccountID:     movement.AccountID,
+		Currency:      string(movement.Currency),
+		Amount:        movement.Amount.String(),
+		IsPayment:     movement.IsPayment,
+		HappenedAt:    movement.HappenedAt,
+	})
+}
+
+func (r *PostgresRepository) UpdateMovementAmount(ctx context.Context, transactionID uuid.UUID, accountID uuid.UUID, amount decimal.Decimal) error {
+	return r.queries.In(ctx).UpdateCardMovementAmount(ctx, db.UpdateCardMovementAmountParams{
+		TransactionID: transactionID,
+		AccountID:     accountID,
+		Amount:        amount.String(),
+	})
+}
+
+func (r *PostgresRepository) UpdateMovementHappenedAt(ctx context.Context, transactionID uuid.UUID, happenedAt time.Time) error {
+	return r.queries.In(ctx).UpdateCardMovementHappenedAt(ctx, db.UpdateCardMovementHappenedAtParams{
+		TransactionID: transactionID,
+		HappenedAt:    happenedAt,
+	})
+}
+
+func (r *PostgresRepository) DeleteMovements(ctx context.Context, transactionID uuid.UUID) error {
+	return r.queries.In(ctx).DeleteCardMovements(ctx, transactionID)
+}
+
+func (r *PostgresRepository) CreateStatement(ctx context.Context, statement Statement) error {
+	params := db.CreateStatementParams{
+		AccountID:   statement.AccountID,
+		ClosingDate: statement.ClosingDate,
+		Currency:    string(statement.Currency),
+		DueDate:     statement.DueDate,
+		Balance:     statement.Balance.String(),
+		MinimumDue:  statement.MinimumDue.String(),
+	}
+	if statement.PeriodStart != nil {
+		params.PeriodStart = sql.NullTime{Time: *statement.PeriodStart, Valid: true}
+	}
+
+	return r.queries.In(ctx).CreateStatement(ctx, params)
+}
+
+func (r *PostgresRepository) BalanceBefore(ctx context.Context, accountID uuid.UUID, currency values.Currency, before time.Time) (decimal.Decimal, error) {
+	balance, err := r.queries.In(ctx).GetCardBalanceBefore(ctx, db.GetCardBalanceBeforeParams{
+		AccountID: accountID,
+		Currency:  string(currency),
+		Before:    before,
+	})
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	return decimal.NewFromString(balance)
+}
+
+func (r *PostgresRepository) ListStatements(ctx context.Context, accountID uuid.UUID) ([]Statement, error) {
+	rows, err := r.queries.In(ctx).ListStatements(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := make([]Statement, 0, len(rows))
+	for _, row := range rows {
+		balance, err := decimal.NewFromString(row.Balance)
+		if err != nil {
+			return nil, err
+		}
+		minimumDue, err := decimal.NewFromString(row.MinimumDue)
+		if err != nil {
+			return nil, err
+		}
+		paid, err := decimal.NewFromString(row.Paid)
+		if err != nil {
+			return nil, err
+		}
+
+		statement := Statement{
+			AccountID:   row.AccountID,
+			Currency:    values.Currency(row.Currency),
+			ClosingDate: row.ClosingDate,
+			DueDate:     row.DueDate,
+			Balance:     balance,
+			MinimumDue:  minimumDue,
+			Paid:        paid,
+		}
+		if row.PeriodStart.Valid {
+			t := row.PeriodStart.Time
+			statement.PeriodStart = &t
+		}
+
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
@@ -0,0 +1,130 @@
+package statements
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/account"
+	account_events "github.com/somatom98/brokeli/internal/domain/account/events"
+	"github.com/somatom98/brokeli/internal/domain/transaction"
+	transaction_events "github.com/somatom98/brokeli/internal/domain/transaction/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+// Movement is the money a transaction moved in or out of an account. Money
+// transferred into an account is a payment, as far as a credit card is
+// concerned.
+type Movement struct {
+	TransactionID uuid.UUID
+	AccountID     uuid.UUID
+	Currency      values.Currency
+	Amount        decimal.Decimal
+	IsPayment     bool
+	HappenedAt    time.Time
+}
+
+// Statement is a closed credit card statement along with the payments made
+// for it by its due date.
+type Statement struct {
+	AccountID   uuid.UUID       `json:"account_id"`
+	Currency    values.Currency `json:"currency"`
+	PeriodStart *time.Time      `json:"period_start,omitempty"`
+	ClosingDate time.Time       `json:"closing_date"`
+	DueDate     time.Time       `json:"due_date"`
+	Balance     decimal.Decimal `json:"balance"`
+	MinimumDue  decimal.Decimal `json:"minimum_due"`
+	Paid        decimal.Decimal `json:"paid"`
+	PaidInFull  bool            `json:"paid_in_full"`
+}
+
+type Repository interface {
+	CreateMovement(ctx context.Context, movement Movement) error
+	UpdateMovementAmount(ctx context.Context, transactionID uuid.UUID, accountID uuid.UUID, amount decimal.Decimal) error
+	UpdateMovementHappenedAt(ctx context.Context, transactionID uuid.UUID, happenedAt time.Time) error
+	DeleteMovements(ctx context.Context, transactionID uuid.UUID) error
+	CreateStatement(ctx context.Context, statement Statement) error
+	// BalanceBefore sums the movements of an account in a currency that
+	// happened before the given time.
+	BalanceBefore(ctx context.Context, accountID uuid.UUID, currency values.Currency, before time.Time) (decimal.Decimal, error)
+	// ListStatements returns the statements of an account, latest first.
+	ListStatements(ctx context.Context, accountID uuid.UUID) ([]Statement, error)
+}
+
+// SubscriptionName identifies the projection checkpoints in the event stores.
+const SubscriptionName = "statements_projection"
+
+type Projection struct {
+	repository Repository
+}
+
+// NewProjection returns a projection that is not subscribed to the event
+// stores, for replaying events into a repository of choice.
+func NewProjection(repository Repository) *Projection {
+	return &Projection{
+		repository: repository,
+	}
+}
+
+func New(
+	transactionES event_store.Store[*transaction.Transaction],
+	accountES event_store.Store[*account.Account],
+	repository Repository,
+) *Projection {
+	p := NewProjection(repository)
+
+	transactionES.SubscribeNamed(context.Background(), SubscriptionName, p.HandleRecord)
+	accountES.SubscribeNamed(context.Background(), SubscriptionName, p.HandleRecord)
+
+	return p
+}
+
+func (v *Projection) HandleRecord(ctx context.Context, record event_store.Record) error {
+	switch record.Type() {
+	case transaction_events.TypeMoneySpent:
+		return v.ApplyMoneySpent(ctx, record.AggregateID, record.Content().(transaction_events.MoneySpent))
+	case transaction_events.TypeExpenseSplit:
+		return v.ApplyExpenseSplit(ctx, record.AggregateID, record.Content().(transaction_events.ExpenseSplit))
+	case transaction_events.TypeMoneyReceived:
+		return v.ApplyMoneyReceived(ctx, record.AggregateID, record.Content().(transaction_events.MoneyReceived))
+	case transaction_events.TypeMoneyTransfered:
+		return v.ApplyMoneyTransfered(ctx, record.AggregateID, record.Content().(transaction_events.MoneyTransfered))
+	case transaction_events.TypeAmountChanged:
+		return v.ApplyAmountChanged(ctx, record.AggregateID, record.Content().(transaction_events.AmountChanged))
+	case transaction_events.TypeRedated:
+		return v.ApplyRedated(ctx, record.AggregateID, record.Content().(transaction_events.Redated))
+	case transaction_events.TypeTransactionVoided:
+		return v.ApplyTransactionVoided(ctx, record.AggregateID, record.Content().(transaction_events.TransactionVoided))
+	case account_events.TypeStatementClosed:
+		return v.ApplyStatementClosed(ctx, record.Content().(account_events.StatementClosed))
+	}
+	return nil
+}
+
+// BalanceBefore returns the balance of an account from the money moved before
+// the given time, so that statements can be closed on it.
+func (v *Projection) BalanceBefore(ctx context.Context, accountID uuid.UUID, currency values.Currency, before time.Time) (decimal.Decimal, error) {
+	return v.repository.BalanceBefore(ctx, accountID, currency, before)
+}
+
+// ListStatements returns the statements of a credit card, or only the ones
+// not yet paid in full.
+func (v *Projection) ListStatements(ctx context.Context, accountID uuid.UUID, openOnly bool) ([]Statement, error) {
+	all, err := v.repository.ListStatements(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := make([]Statement, 0, len(all))
+	for _, statement := range all {
+		statement.PaidInFull = statement.Paid.GreaterThanOrEqual(statement.Balance)
+		if openOnly && statement.PaidInFull {
+			continue
+		}
+		statements = append(statements, statement)
+	}
+
+	return statements, nil
+}
@@ -3,17 +3,20 @@ package transactions
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/somatom98/brokeli/internal/domain/values"
 	account_events "github.com/somatom98/brokeli/internal/domain/account/events"
 	transaction_events "github.com/somatom98/brokeli/internal/domain/transaction/events"
 )
 
-func (v *Projection) ApplyMoneySpent(ctx context.Context, idStr string, e transaction_events.MoneySpent) error {
+func (v *Projection) ApplyMoneySpent(ctx context.Context, idStr string, transactionID uuid.UUID, e transaction_events.MoneySpent) error {
 	id := uuid.NewMD5(uuid.NameSpaceOID, []byte(idStr))
 	return v.repository.CreateTransaction(ctx, TransactionRecord{
 		ID:              id,
+		TransactionID:   transactionID,
 		AccountID:       e.AccountID,
 		TransactionType: string(values.TransactionType_Expense),
 		Amount:          e.Amount.Neg(),
@@ -24,10 +27,39 @@ func (v *Projection) ApplyMoneySpent(ctx context.Context, idStr string, e transa
 	})
 }
 
-func (v *Projection) ApplyMoneyReceived(ctx context.Context, idStr string, e transaction_events.MoneyReceived) error {
+// ApplyExpenseSplit records a row for each line of the expense, all of them
+// linked to it by their transaction ID, so that categories add up by line.
+func (v *Projection) ApplyExpenseSplit(ctx context.Context, idStr string, transactionID uuid.UUID, e transaction_events.ExpenseSplit) error {
+	for i, line := range e.Lines {
+		description := line.Description
+		if description == "" {
+			description = e.Description
+		}
+
+		idLine := uuid.NewMD5(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s_line_%d", idStr, i)))
+		err := v.repository.CreateTransaction(ctx, TransactionRecord{
+			ID:              idLine,
+			TransactionID:   transactionID,
+			AccountID:       line.AccountID,
+			TransactionType: string(values.TransactionType_Expense),
+			Amount:          line.Amount.Neg(),
+			Currency:        e.Currency,
+			Category:        line.Category,
+			Description:     description,
+			HappenedAt:      e.HappenedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *Projection) ApplyMoneyReceived(ctx context.Context, idStr string, transactionID uuid.UUID, e transaction_events.MoneyReceived) error {
 	id := uuid.NewMD5(uuid.NameSpaceOID, []byte(idStr))
 	return v.repository.CreateTransaction(ctx, TransactionRecord{
 		ID:              id,
+		TransactionID:   transactionID,
 		AccountID:       e.AccountID,
 		TransactionType: string(values.TransactionType_Income),
 		Amount:          e.Amount,
@@ -38,25 +70,64 @@ func (v *Projection) ApplyMoneyReceived(ctx context.Context, idStr string, e tra
 	})
 }
 
-func (v *Projection) ApplyMoneyTransfered(ctx context.Context, idStr string, e transaction_events.MoneyTransfered) error {
+// categoryFXCost is the category the spread paid on a transfer across
+// currencies is spent on.
+const categoryFXCost = "FX cost"
+
+// ApplyMoneyTransfered records the rows leaving and entering the accounts. The
+// rows of a transfer across currencies carry its rate and, when a spread was
+// paid on it, the source row leaves it out in favour of an expense of its own.
+func (v *Projection) ApplyMoneyTransfered(ctx context.Context, idStr string, transactionID uuid.UUID, e transaction_events.MoneyTransfered) error {
+	var exchangeRate *decimal.Decimal
+	if e.FromCurrency != e.ToCurrency && e.Rate.IsPositive() {
+		exchangeRate = &e.Rate
+	}
+
+	transfered := e.FromAmount
+	if e.Spread.IsPositive() {
+		transfered = transfered.Sub(e.Spread)
+	}
+
 	idSource := uuid.NewMD5(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s_source", idStr)))
 	err := v.repository.CreateTransaction(ctx, TransactionRecord{
 		ID:              idSource,
+		TransactionID:   transactionID,
 		AccountID:       e.FromAccountID,
 		TransactionType: string(values.TransactionType_Transfer),
-		Amount:          e.FromAmount.Neg(),
+		Amount:          transfered.Neg(),
 		Currency:        e.FromCurrency,
 		Category:        e.Category,
 		Description:     e.Description,
 		HappenedAt:      e.HappenedAt,
+		ExchangeRate:    exchangeRate,
 	})
 	if err != nil {
 		return err
 	}
 
+	if e.Spread.IsPositive() {
+		idFXCost := uuid.NewMD5(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s_fx_cost", idStr)))
+		err = v.repository.CreateTransaction(ctx, TransactionRecord{
+			ID:              idFXCost,
+			TransactionID:   transactionID,
+			AccountID:       e.FromAccountID,
+			TransactionType: string(values.TransactionType_Expense),
+			Amount:          e.Spread.Neg(),
+			Currency:        e.FromCurrency,
+			Category:        categoryFXCost,
+			Description:     e.Description,
+			HappenedAt:      e.HappenedAt,
+			ExchangeRate:    exchangeRate,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	idDestination := uuid.NewMD5(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s_destination", idStr)))
 	return v.repository.CreateTransaction(ctx, TransactionRecord{
 		ID:              idDestination,
+		TransactionID:   transactionID,
 		AccountID:       e.ToAccountID,
 		TransactionType: string(values.TransactionType_Transfer),
 		Amount:          e.ToAmount,
@@ -64,13 +135,15 @@ func (v *Projection) ApplyMoneyTransfered(ctx context.Context, idStr string, e t
 		Category:        e.Category,
 		Description:     e.Description,
 		HappenedAt:      e.HappenedAt,
+		ExchangeRate:    exchangeRate,
 	})
 }
 
-func (v *Projection) ApplyReimbursementReceived(ctx context.Context, idStr string, e transaction_events.ReimbursementReceived) error {
+func (v *Projection) ApplyReimbursementReceived(ctx context.Context, idStr string, transactionID uuid.UUID, e transaction_events.ReimbursementReceived) error {
 	id := uuid.NewMD5(uuid.NameSpaceOID, []byte(idStr))
 	return v.repository.CreateTransaction(ctx, TransactionRecord{
 		ID:              id,
+		TransactionID:   transactionID,
 		AccountID:       e.AccountID,
 		TransactionType: string(values.TransactionType_Reimbursement),
 		Amount:          e.Amount,
@@ -117,13 +190,14 @@ func (v *Projection) ApplyMoneyWithdrawn(ctx context.Context, idStr string, e ac
 	})
 }
 
-func (v *Projection) ApplyMoneyInvested(ctx context.Context, idStr string, e transaction_events.MoneyInvested) error {
+func (v *Projection) ApplyMoneyInvested(ctx context.Context, idStr string, transactionID uuid.UUID, e transaction_events.MoneyInvested) error {
 	id := uuid.NewMD5(uuid.NameSpaceOID, []byte(idStr))
 
 	if e.PriceCurrency == e.FeeCurrency {
 		amount := e.Units.Mul(e.Price).Add(e.Fee)
 		return v.repository.CreateTransaction(ctx, TransactionRecord{
 			ID:              id,
+			TransactionID:   transactionID,
 			AccountID:       e.AccountID,
 			TransactionType: string(values.TransactionType_Investment),
 			Amount:          amount.Neg(), // Money is leaving liquidity
@@ -138,6 +212,7 @@ func (v *Projection) ApplyMoneyInvested(ctx context.Context, idStr string, e tra
 	priceAmount := e.Units.Mul(e.Price)
 	err := v.repository.CreateTransaction(ctx, TransactionRecord{
 		ID:              id,
+		TransactionID:   transactionID,
 		AccountID:       e.AccountID,
 		TransactionType: string(values.TransactionType_Investment),
 		Amount:          priceAmount.Neg(),
@@ -153,6 +228,7 @@ func (v *Projection) ApplyMoneyInvested(ctx context.Context, idStr string, e tra
 	idFee := uuid.NewMD5(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s_fee", idStr)))
 	return v.repository.CreateTransaction(ctx, TransactionRecord{
 		ID:              idFee,
+		TransactionID:   transactionID,
 		AccountID:       e.AccountID,
 		TransactionType: string(values.TransactionType_Investment),
 		Amount:          e.Fee.Neg(),
@@ -163,3 +239,109 @@ func (v *Projection) ApplyMoneyInvested(ctx context.Context, idStr string, e tra
 	})
 }
 
+func (v *Projection) ApplyInvestmentSold(ctx context.Context, idStr string, transactionID uuid.UUID, e transaction_events.InvestmentSold) error {
+	return v.createProceeds(ctx, idStr, transactionID, e.AccountID, values.TransactionType_Sale, e.Ticker, e.Units.Mul(e.Price), e.PriceCurrency, e.Fee, e.FeeCurrency, e.HappenedAt)
+}
+
+func (v *Projection) ApplyDividendReceived(ctx context.Context, idStr string, transactionID uuid.UUID, e transaction_events.DividendReceived) error {
+	return v.createProceeds(ctx, idStr, transactionID, e.AccountID, values.TransactionType_Dividend, e.Ticker, e.Units.Mul(e.Price), e.PriceCurrency, e.Fee, e.FeeCurrency, e.HappenedAt)
+}
+
+func (v *Projection) ApplyInterestReceived(ctx context.Context, idStr string, transactionID uuid.UUID, e transaction_events.InterestReceived) error {
+	return v.createProceeds(ctx, idStr, transactionID, e.AccountID, values.TransactionType_Interest, e.Ticker, e.Units.Mul(e.Price), e.PriceCurrency, e.Fee, e.FeeCurrency, e.HappenedAt)
+}
+
+func (v *Projection) ApplyInvestmentFeeCharged(ctx context.Context, idStr string, transactionID uuid.UUID, e transaction_events.InvestmentFeeCharged) error {
+	return v.repository.CreateTransaction(ctx, TransactionRecord{
+		ID:              uuid.NewMD5(uuid.NameSpaceOID, []byte(idStr)),
+		TransactionID:   transactionID,
+		AccountID:       e.AccountID,
+		TransactionType: string(values.TransactionType_InvestmentFee),
+		Amount:          e.Fee.Neg(),
+		Currency:        e.FeeCurrency,
+		Category:        "Investments",
+		Description:     fmt.Sprintf("%s (Fee)", e.Ticker),
+		HappenedAt:      e.HappenedAt,
+	})
+}
+
+// createProceeds records what an investment paid back into liquidity, net of
+// the fee when both are in the same currency, and as a separate fee record
+// otherwise.
+func (v *Projection) createProceeds(
+	ctx context.Context,
+	idStr string,
+	transactionID uuid.UUID,
+	accountID uuid.UUID,
+	transactionType values.TransactionType,
+	ticker string,
+	proceeds decimal.Decimal,
+	currency values.Currency,
+	fee decimal.Decimal,
+	feeCurrency values.Currency,
+	happenedAt time.Time,
+) error {
+	id := uuid.NewMD5(uuid.NameSpaceOID, []byte(idStr))
+
+	if currency == feeCurrency {
+		return v.repository.CreateTransaction(ctx, TransactionRecord{
+			ID:              id,
+			TransactionID:   transactionID,
+			AccountID:       accountID,
+			TransactionType: string(transactionType),
+			Amount:          proceeds.Sub(fee),
+			Currency:        currency,
+			Category:        "Investments",
+			Description:     ticker,
+			HappenedAt:      happenedAt,
+		})
+	}
+
+	err := v.repository.CreateTransaction(ctx, TransactionRecord{
+		ID:              id,
+		TransactionID:   transactionID,
+		AccountID:       accountID,
+		TransactionType: string(transactionType),
+		Amount:          proceeds,
+		Currency:        currency,
+		Category:        "Investments",
+		Description:     ticker,
+		HappenedAt:      happenedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	idFee := uuid.NewMD5(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s_fee", idStr)))
+	return v.repository.CreateTransaction(ctx, TransactionRecord{
+		ID:              idFee,
+		TransactionID:   transactionID,
+		AccountID:       accountID,
+		TransactionType: string(transactionType),
+		Amount:          fee.Neg(),
+		Currency:        feeCurrency,
+		Category:        "Investments",
+		Description:     fmt.Sprintf("%s (Fee)", ticker),
+		HappenedAt:      happenedAt,
+	})
+}
+
+func (v *Projection) ApplyAmountChanged(ctx context.Context, transactionID uuid.UUID, e transaction_events.AmountChanged) error {
+	return v.repository.UpdateTransactionAmount(ctx, transactionID, e.Side.Signed(e.Amount))
+}
+
+func (v *Projection) ApplyRedated(ctx context.Context, transactionID uuid.UUID, e transaction_events.Redated) error {
+	return v.repository.UpdateTransactionHappenedAt(ctx, transactionID, e.HappenedAt)
+}
+
+func (v *Projection) ApplyRecategorized(ctx context.Context, transactionID uuid.UUID, e transaction_events.Recategorized) error {
+	return v.repository.UpdateTransactionCategory(ctx, transactionID, e.Category)
+}
+
+func (v *Projection) ApplyRedescribed(ctx context.Context, transactionID uuid.UUID, e transaction_events.Redescribed) error {
+	return v.repository.UpdateTransactionDescription(ctx, transactionID, e.Description)
+}
+
+func (v *Projection) ApplyTransactionVoided(ctx context.Context, transactionID uuid.UUID, voidedAt time.Time, e transaction_events.TransactionVoided) error {
+	return v.repository.VoidTransaction(ctx, transactionID, voidedAt)
+}
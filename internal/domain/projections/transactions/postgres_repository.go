@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/somatom98/brokeli/internal/db"
 	"github.com/somatom98/brokeli/internal/domain/values"
@@ -23,7 +25,7 @@ func NewPostgresRepository(dbConn *sql.DB) (*PostgresRepository, error) {
 }
 
 func (r *PostgresRepository) CreateTransaction(ctx context.Context, tx TransactionRecord) error {
-	return r.queries.CreateTransaction(ctx, db.CreateTransactionParams{
+	return r.queries.In(ctx).CreateTransaction(ctx, db.CreateTransactionParams{
 		ID:              tx.ID,
 		AccountID:       tx.AccountID,
 		TransactionType: tx.TransactionType,
@@ -32,6 +34,43 @@ func (r *PostgresRepository) CreateTransaction(ctx context.Context, tx Transacti
 		Category:        tx.Category,
 		Description:     tx.Description,
 		HappenedAt:      tx.HappenedAt,
+		TransactionID:   uuid.NullUUID{UUID: tx.TransactionID, Valid: tx.TransactionID != uuid.Nil},
+		ExchangeRate:    toNullDecimal(tx.ExchangeRate),
+	})
+}
+
+func (r *PostgresRepository) UpdateTransactionAmount(ctx context.Context, transactionID uuid.UUID, amount decimal.Decimal) error {
+	return r.queries.In(ctx).UpdateTransactionAmount(ctx, db.UpdateTransactionAmountParams{
+		TransactionID: uuid.NullUUID{UUID: transactionID, Valid: true},
+		Amount:        amount.String(),
+	})
+}
+
+func (r *PostgresRepository) UpdateTransactionHappenedAt(ctx context.Context, transactionID uuid.UUID, happenedAt time.Time) error {
+	return r.queries.In(ctx).UpdateTransactionHappenedAt(ctx, db.UpdateTransactionHappenedAtParams{
+		TransactionID: uuid.NullUUID{UUID: transactionID, Valid: true},
+		HappenedAt:    happenedAt,
+	})
+}
+
+func (r *PostgresRepository) UpdateTransactionCategory(ctx context.Context, transactionID uuid.UUID, category string) error {
+	return r.queries.In(ctx).UpdateTransactionCategory(ctx, db.UpdateTransactionCategoryParams{
+		TransactionID: uuid.NullUUID{UUID: transactionID, Valid: true},
+		Category:      category,
+	})
+}
+
+func (r *PostgresRepository) UpdateTransactionDescription(ctx context.Context, transactionID uuid.UUID, description string) error {
+	return r.queries.In(ctx).UpdateTransactionDescription(ctx, db.UpdateTransactionDescriptionParams{
+		TransactionID: uuid.NullUUID{UUID: transactionID, Valid: true},
+		Description:   description,
+	})
+}
+
+func (r *PostgresRepository) VoidTransaction(ctx context.Context, transactionID uuid.UUID, voidedAt time.Time) error {
+	return r.queries.In(ctx).VoidTransaction(ctx, db.VoidTransactionParams{
+		TransactionID: uuid.NullUUID{UUID: transactionID, Valid: true},
+		VoidedAt:      sql.NullTime{Time: voidedAt, Valid: true},
 	})
 }
 
@@ -61,7 +100,7 @@ func (r *PostgresRepository) ListTransactions(ctx context.Context, params ListTr
 		}
 	}
 
-	rows, err := r.queries.ListTransactions(ctx, arg)
+	rows, err := r.queries.In(ctx).ListTransactions(ctx, arg)
 	if err != nil {
 		return nil, err
 	}
@@ -77,6 +116,7 @@ func (r *PostgresRepository) ListTransactions(ctx context.Context, params ListTr
 
 		transactions[i] = TransactionRecord{
 			ID:              row.ID,
+			TransactionID:   row.TransactionID.UUID,
 			AccountID:       row.AccountID,
 			TransactionType: row.TransactionType,
 			Amount:          amount,
@@ -85,6 +125,7 @@ func (r *PostgresRepository) ListTransactions(ctx context.Context, params ListTr
 			Description:     row.Description,
 			HappenedAt:      row.HappenedAt,
 			SystemTotalRate: rate,
+			ExchangeRate:    fromNullDecimal(row.ExchangeRate),
 		}
 	}
 
@@ -119,7 +160,7 @@ func (r *PostgresRepository) ListTransactionsPaginated(ctx context.Context, para
 		}
 	}
 
-	rows, err := r.queries.ListTransactionsPaginated(ctx, arg)
+	rows, err := r.queries.In(ctx).ListTransactionsPaginated(ctx, arg)
 	if err != nil {
 		return PaginatedTransactions{}, err
 	}
@@ -140,6 +181,7 @@ func (r *PostgresRepository) ListTransactionsPaginated(ctx context.Context, para
 
 		transactions[i] = TransactionRecord{
 			ID:              row.ID,
+			TransactionID:   row.TransactionID.UUID,
 			AccountID:       row.AccountID,
 			TransactionType: row.TransactionType,
 			Amount:          amount,
@@ -148,6 +190,7 @@ func (r *PostgresRepository) ListTransactionsPaginated(ctx context.Context, para
 			Description:     row.Description,
 			HappenedAt:      row.HappenedAt,
 			SystemTotalRate: rate,
+			ExchangeRate:    fromNullDecimal(row.ExchangeRate),
 		}
 	}
 
@@ -158,5 +201,23 @@ func (r *PostgresRepository) ListTransactionsPaginated(ctx context.Context, para
 }
 
 func (r *PostgresRepository) ListCategories(ctx context.Context) ([]string, error) {
-	return r.queries.ListCategories(ctx)
+	return r.queries.In(ctx).ListCategories(ctx)
+}
+
+func toNullDecimal(d *decimal.Decimal) sql.NullString {
+	if d == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: d.String(), Valid: true}
+}
+
+func fromNullDecimal(s sql.NullString) *decimal.Decimal {
+	if !s.Valid {
+		return nil
+	}
+	d, err := decimal.NewFromString(s.String)
+	if err != nil {
+		return nil
+	}
+	return &d
 }
@@ -17,6 +17,7 @@ import (
 
 type TransactionRecord struct {
 	ID              uuid.UUID       `json:"id"`
+	TransactionID   uuid.UUID       `json:"transaction_id"`
 	AccountID       uuid.UUID       `json:"account_id"`
 	TransactionType string          `json:"transaction_type"`
 	Amount          decimal.Decimal `json:"amount"`
@@ -25,6 +26,9 @@ type TransactionRecord struct {
 	Description     string          `json:"description"`
 	HappenedAt      time.Time       `json:"happened_at"`
 	SystemTotalRate decimal.Decimal `json:"system_total_rate"`
+	// ExchangeRate is the rate of the transfer across currencies the row was
+	// projected from, nil for any other row.
+	ExchangeRate *decimal.Decimal `json:"exchange_rate,omitempty"`
 }
 
 type ListTransactionsParams struct {
@@ -47,26 +51,42 @@ type PaginatedTransactions struct {
 
 type Repository interface {
 	CreateTransaction(ctx context.Context, tx TransactionRecord) error
+	UpdateTransactionAmount(ctx context.Context, transactionID uuid.UUID, amount decimal.Decimal) error
+	UpdateTransactionHappenedAt(ctx context.Context, transactionID uuid.UUID, happenedAt time.Time) error
+	UpdateTransactionCategory(ctx context.Context, transactionID uuid.UUID, category string) error
+	UpdateTransactionDescription(ctx context.Context, transactionID uuid.UUID, description string) error
+	// VoidTransaction flags the rows of the transaction as voided, hiding them
+	// from the listings.
+	VoidTransaction(ctx context.Context, transactionID uuid.UUID, voidedAt time.Time) error
 	ListTransactions(ctx context.Context, params ListTransactionsParams) ([]TransactionRecord, error)
 	ListTransactionsPaginated(ctx context.Context, params ListTransactionsPaginatedParams) (PaginatedTransactions, error)
 	ListCategories(ctx context.Context) ([]string, error)
 }
 
+// SubscriptionName identifies the projection checkpoints in the event stores.
+const SubscriptionName = "transactions_projection"
+
 type Projection struct {
 	repository Repository
 }
 
+// NewProjection returns a projection that is not subscribed to the event
+// stores, for replaying events into a repository of choice.
+func NewProjection(repository Repository) *Projection {
+	return &Projection{
+		repository: repository,
+	}
+}
+
 func New(
 	transactionES event_store.Store[*transaction.Transaction],
 	accountES event_store.Store[*account.Account],
 	repository Repository,
 ) *Projection {
-	p := &Projection{
-		repository: repository,
-	}
+	p := NewProjection(repository)
 
-	transactionES.Subscribe(context.Background(), p.HandleRecord)
-	accountES.Subscribe(context.Background(), p.HandleRecord)
+	transactionES.SubscribeNamed(context.Background(), SubscriptionName, p.HandleRecord)
+	accountES.SubscribeNamed(context.Background(), SubscriptionName, p.HandleRecord)
 
 	return p
 }
@@ -74,7 +94,18 @@ func New(
 func (v *Projection) HandleRecord(ctx context.Context, record event_store.Record) error {
 	var aggregateType string
 	switch record.Type() {
-	case transaction_events.TypeMoneySpent, transaction_events.TypeMoneyReceived, transaction_events.TypeMoneyTransfered, transaction_events.TypeReimbursementReceived, transaction_events.TypeMoneyInvested:
+	case transaction_events.TypeAmountChanged:
+		return v.ApplyAmountChanged(ctx, record.AggregateID, record.Content().(transaction_events.AmountChanged))
+	case transaction_events.TypeRedated:
+		return v.ApplyRedated(ctx, record.AggregateID, record.Content().(transaction_events.Redated))
+	case transaction_events.TypeRecategorized:
+		return v.ApplyRecategorized(ctx, record.AggregateID, record.Content().(transaction_events.Recategorized))
+	case transaction_events.TypeRedescribed:
+		return v.ApplyRedescribed(ctx, record.AggregateID, record.Content().(transaction_events.Redescribed))
+	case transaction_events.TypeTransactionVoided:
+		return v.ApplyTransactionVoided(ctx, record.AggregateID, record.RecordedAt, record.Content().(transaction_events.TransactionVoided))
+	case transaction_events.TypeMoneySpent, transaction_events.TypeExpenseSplit, transaction_events.TypeMoneyReceived, transaction_events.TypeMoneyTransfered, transaction_events.TypeReimbursementReceived, transaction_events.TypeMoneyInvested,
+		transaction_events.TypeInvestmentSold, transaction_events.TypeDividendReceived, transaction_events.TypeInterestReceived, transaction_events.TypeInvestmentFeeCharged:
 		aggregateType = "Transaction"
 	case account_events.TypeMoneyDeposited, account_events.TypeMoneyWithdrawn:
 		aggregateType = "Account"
@@ -86,15 +117,25 @@ func (v *Projection) HandleRecord(ctx context.Context, record event_store.Record
 
 	switch record.Type() {
 	case transaction_events.TypeMoneySpent:
-		return v.ApplyMoneySpent(ctx, idStr, record.Content().(transaction_events.MoneySpent))
+		return v.ApplyMoneySpent(ctx, idStr, record.AggregateID, record.Content().(transaction_events.MoneySpent))
+	case transaction_events.TypeExpenseSplit:
+		return v.ApplyExpenseSplit(ctx, idStr, record.AggregateID, record.Content().(transaction_events.ExpenseSplit))
 	case transaction_events.TypeMoneyReceived:
-		return v.ApplyMoneyReceived(ctx, idStr, record.Content().(transaction_events.MoneyReceived))
+		return v.ApplyMoneyReceived(ctx, idStr, record.AggregateID, record.Content().(transaction_events.MoneyReceived))
 	case transaction_events.TypeMoneyTransfered:
-		return v.ApplyMoneyTransfered(ctx, idStr, record.Content().(transaction_events.MoneyTransfered))
+		return v.ApplyMoneyTransfered(ctx, idStr, record.AggregateID, record.Content().(transaction_events.MoneyTransfered))
 	case transaction_events.TypeReimbursementReceived:
-		return v.ApplyReimbursementReceived(ctx, idStr, record.Content().(transaction_events.ReimbursementReceived))
+		return v.ApplyReimbursementReceived(ctx, idStr, record.AggregateID, record.Content().(transaction_events.ReimbursementReceived))
 	case transaction_events.TypeMoneyInvested:
-		return v.ApplyMoneyInvested(ctx, idStr, record.Content().(transaction_events.MoneyInvested))
+		return v.ApplyMoneyInvested(ctx, idStr, record.AggregateID, record.Content().(transaction_events.MoneyInvested))
+	case transaction_events.TypeInvestmentSold:
+		return v.ApplyInvestmentSold(ctx, idStr, record.AggregateID, record.Content().(transaction_events.InvestmentSold))
+	case transaction_events.TypeDividendReceived:
+		return v.ApplyDividendReceived(ctx, idStr, record.AggregateID, record.Content().(transaction_events.DividendReceived))
+	case transaction_events.TypeInterestReceived:
+		return v.ApplyInterestReceived(ctx, idStr, record.AggregateID, record.Content().(transaction_events.InterestReceived))
+	case transaction_events.TypeInvestmentFeeCharged:
+		return v.ApplyInvestmentFeeCharged(ctx, idStr, record.AggregateID, record.Content().(transaction_events.InvestmentFeeCharged))
 	case account_events.TypeMoneyDeposited:
 		return v.ApplyMoneyDeposited(ctx, idStr, record.Content().(account_events.MoneyDeposited))
 	case account_events.TypeMoneyWithdrawn:
@@ -0,0 +1,248 @@
+package recurring
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/recurring/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+type State int
+
+const (
+	State_Uncreated State = iota
+	State_Active
+	State_Finished
+)
+
+// Kind tells whether the occurrences of a rule are posted as expenses or as
+// incomes.
+type Kind string
+
+const (
+	Kind_Expense Kind = "EXPENSE"
+	Kind_Income  Kind = "INCOME"
+)
+
+var kinds = []Kind{
+	Kind_Expense,
+	Kind_Income,
+}
+
+func (k Kind) IsValid() bool {
+	return slices.Contains(kinds, k)
+}
+
+// Occurrence is a single posting of a rule, as scheduled or as modified.
+type Occurrence struct {
+	Number      int             `json:"number"`
+	Date        time.Time       `json:"date"`
+	Amount      decimal.Decimal `json:"amount"`
+	Description string          `json:"description"`
+	Skipped     bool            `json:"skipped"`
+	Modified    bool            `json:"modified"`
+	// Failed tells that the occurrence was rejected when posted, for
+	// FailureReason, and will not be posted.
+	Failed        bool   `json:"failed"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+type Rule struct {
+	ID          uuid.UUID
+	State       State
+	Kind        Kind
+	AccountID   uuid.UUID
+	Currency    values.Currency
+	Amount      decimal.Decimal
+	Category    string
+	Description string
+	Cadence     Cadence
+	StartDate   time.Time
+	EndDate     *time.Time
+	// Next is the number of the first occurrence neither posted nor skipped.
+	Next      int
+	Overrides map[int]Occurrence
+}
+
+func New(id uuid.UUID) *Rule {
+	return &Rule{
+		ID:        id,
+		State:     State_Uncreated,
+		Overrides: map[int]Occurrence{},
+	}
+}
+
+func (r *Rule) Hydrate(records []event_store.Record) error {
+	for _, record := range records {
+		switch record.Type() {
+		case events.TypeRuleCreated:
+			event, err := event_store.DecodeEvent[events.RuleCreated](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode RuleCreated event: %w", err)
+			}
+			r.ApplyRuleCreated(event)
+		case events.TypeOccurrenceSkipped:
+			event, err := event_store.DecodeEvent[events.OccurrenceSkipped](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode OccurrenceSkipped event: %w", err)
+			}
+			r.ApplyOccurrenceSkipped(event)
+		case events.TypeOccurrenceModified:
+			event, err := event_store.DecodeEvent[events.OccurrenceModified](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode OccurrenceModified event: %w", err)
+			}
+			r.ApplyOccurrenceModified(event)
+		case events.TypeOccurrencePosted:
+			event, err := event_store.DecodeEvent[events.OccurrencePosted](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode OccurrencePosted event: %w", err)
+			}
+			r.ApplyOccurrencePosted(event)
+		case events.TypeOccurrenceFailed:
+			event, err := event_store.DecodeEvent[events.OccurrenceFailed](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode OccurrenceFailed event: %w", err)
+			}
+			r.ApplyOccurrenceFailed(event)
+		case events.TypeRuleFinished:
+			event, err := event_store.DecodeEvent[events.RuleFinished](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode RuleFinished event: %w", err)
+			}
+			r.ApplyRuleFinished(event)
+		}
+	}
+
+	return nil
+}
+
+func (r *Rule) ApplyRuleCreated(event events.RuleCreated) {
+	r.ID = event.RuleID
+	r.State = State_Active
+	r.Kind = Kind(event.Kind)
+	r.AccountID = event.AccountID
+	r.Currency = event.Currency
+	r.Amount = event.Amount
+	r.Category = event.Category
+	r.Description = event.Description
+	r.Cadence = Cadence{
+		Frequency: Frequency(event.Frequency),
+		Interval:  event.Interval,
+	}
+	r.StartDate = event.StartDate
+	r.EndDate = event.EndDate
+	r.Next = 1
+}
+
+func (r *Rule) ApplyOccurrenceSkipped(event events.OccurrenceSkipped) {
+	occurrence, _ := r.Occurrence(event.Number)
+	occurrence.Skipped = true
+	r.Overrides[event.Number] = occurrence
+
+	if event.Number == r.Next {
+		r.Next = r.nextAfter(event.Number)
+	}
+}
+
+func (r *Rule) ApplyOccurrenceModified(event events.OccurrenceModified) {
+	r.Overrides[event.Number] = Occurrence{
+		Number:      event.Number,
+		Date:        event.Date,
+		Amount:      event.Amount,
+		Description: event.Description,
+		Modified:    true,
+	}
+}
+
+func (r *Rule) ApplyOccurrencePosted(event events.OccurrencePosted) {
+	r.Next = r.nextAfter(event.Number)
+}
+
+func (r *Rule) ApplyOccurrenceFailed(event events.OccurrenceFailed) {
+	occurrence, _ := r.Occurrence(event.Number)
+	occurrence.Failed = true
+	occurrence.FailureReason = event.Reason
+	r.Overrides[event.Number] = occurrence
+
+	if event.Number == r.Next {
+		r.Next = r.nextAfter(event.Number)
+	}
+}
+
+func (r *Rule) ApplyRuleFinished(event events.RuleFinished) {
+	r.State = State_Finished
+}
+
+// Occurrence returns the n-th occurrence of the rule, unless the rule ends
+// before it.
+func (r *Rule) Occurrence(n int) (Occurrence, bool) {
+	date := r.Cadence.Date(r.StartDate, n)
+	if n < 1 || (r.EndDate != nil && date.After(*r.EndDate)) {
+		return Occurrence{}, false
+	}
+
+	if occurrence, ok := r.Overrides[n]; ok {
+		return occurrence, true
+	}
+
+	return Occurrence{
+		Number:      n,
+		Date:        date,
+		Amount:      r.Amount,
+		Description: r.Description,
+	}, true
+}
+
+// Upcoming returns the occurrences not posted yet that are scheduled up to
+// until, skipped ones included.
+func (r *Rule) Upcoming(until time.Time) []Occurrence {
+	if r.State != State_Active {
+		return nil
+	}
+
+	var occurrences []Occurrence
+	for n := r.Next; !r.Cadence.Date(r.StartDate, n).After(until); n++ {
+		occurrence, ok := r.Occurrence(n)
+		if !ok {
+			break
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	return occurrences
+}
+
+// Due returns the occurrences to post as of now, in order. Occurrences are
+// posted in order, so one moved past now holds back the following ones.
+func (r *Rule) Due(now time.Time) []Occurrence {
+	if r.State != State_Active {
+		return nil
+	}
+
+	var occurrences []Occurrence
+	for n := r.Next; ; n = r.nextAfter(n) {
+		occurrence, ok := r.Occurrence(n)
+		if !ok || occurrence.Date.After(now) {
+			break
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	return occurrences
+}
+
+// nextAfter returns the number of the first occurrence after the n-th one
+// that is not skipped.
+func (r *Rule) nextAfter(n int) int {
+	n++
+	for r.Overrides[n].Skipped {
+		n++
+	}
+	return n
+}
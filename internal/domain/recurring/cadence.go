@@ -0,0 +1,64 @@
+package recurring
+
+import (
+	"slices"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Frequency_Daily   Frequency = "DAILY"
+	Frequency_Weekly  Frequency = "WEEKLY"
+	Frequency_Monthly Frequency = "MONTHLY"
+	Frequency_Yearly  Frequency = "YEARLY"
+)
+
+var frequencies = []Frequency{
+	Frequency_Daily,
+	Frequency_Weekly,
+	Frequency_Monthly,
+	Frequency_Yearly,
+}
+
+func (f Frequency) IsValid() bool {
+	return slices.Contains(frequencies, f)
+}
+
+// Cadence repeats a date every Interval days, weeks, months or years, like the
+// FREQ and INTERVAL parts of an RRULE.
+type Cadence struct {
+	Frequency Frequency `json:"frequency"`
+	Interval  int       `json:"interval"`
+}
+
+func (c Cadence) IsValid() bool {
+	return c.Frequency.IsValid() && c.Interval > 0
+}
+
+// Date returns the date of the n-th occurrence from start, the first being
+// start itself. Monthly and yearly occurrences keep the day of the month of
+// start, or fall on the last day of the months too short for it, so that a
+// rule starting on January 31st falls on February 28th or 29th.
+func (c Cadence) Date(start time.Time, n int) time.Time {
+	steps := (n - 1) * c.Interval
+
+	switch c.Frequency {
+	case Frequency_Daily:
+		return start.AddDate(0, 0, steps)
+	case Frequency_Weekly:
+		return start.AddDate(0, 0, 7*steps)
+	case Frequency_Monthly:
+		return addMonths(start, steps)
+	case Frequency_Yearly:
+		return addMonths(start, 12*steps)
+	}
+
+	return start
+}
+
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
@@ -0,0 +1,39 @@
+package recurring_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/somatom98/brokeli/internal/domain/recurring"
+)
+
+func TestCadence_Date(t *testing.T) {
+	start := time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		cadence  recurring.Cadence
+		n        int
+		expected time.Time
+	}{
+		{"should start on the start date", recurring.Cadence{Frequency: recurring.Frequency_Monthly, Interval: 1}, 1, start},
+		{"should repeat every other day", recurring.Cadence{Frequency: recurring.Frequency_Daily, Interval: 2}, 3, time.Date(2024, 2, 4, 9, 0, 0, 0, time.UTC)},
+		{"should repeat every week", recurring.Cadence{Frequency: recurring.Frequency_Weekly, Interval: 1}, 2, time.Date(2024, 2, 7, 9, 0, 0, 0, time.UTC)},
+		{"should fall on the last day of a shorter month", recurring.Cadence{Frequency: recurring.Frequency_Monthly, Interval: 1}, 2, time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC)},
+		{"should keep the day of the month after a shorter month", recurring.Cadence{Frequency: recurring.Frequency_Monthly, Interval: 1}, 3, time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC)},
+		{"should repeat every quarter", recurring.Cadence{Frequency: recurring.Frequency_Monthly, Interval: 3}, 2, time.Date(2024, 4, 30, 9, 0, 0, 0, time.UTC)},
+		{"should repeat every year", recurring.Cadence{Frequency: recurring.Frequency_Yearly, Interval: 1}, 2, time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			date := tt.cadence.Date(start, tt.n)
+
+			// assert
+			assert.Equal(t, tt.expected, date)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package recurring
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/recurring/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+var (
+	ErrRuleNotCreated        = errors.New("rule_not_created")
+	ErrRuleFinished          = errors.New("rule_finished")
+	ErrInvalidRule           = errors.New("invalid_rule")
+	ErrNegativeOrNullAmount  = errors.New("negative_or_null_amount")
+	ErrOccurrenceNotFound    = errors.New("occurrence_not_found")
+	ErrOccurrencePosted      = errors.New("occurrence_posted")
+	ErrOccurrenceSkipped     = errors.New("occurrence_skipped")
+	ErrInvalidOccurrenceDate = errors.New("invalid_occurrence_date")
+	ErrOccurrenceFailed      = errors.New("occurrence_failed")
+)
+
+// Definition is what a rule posts, on which account and how often.
+type Definition struct {
+	Kind        Kind
+	AccountID   uuid.UUID
+	Currency    values.Currency
+	Amount      decimal.Decimal
+	Category    string
+	Description string
+	Cadence     Cadence
+	StartDate   time.Time
+	EndDate     *time.Time
+}
+
+// Change modifies a single occurrence of a rule. Nil fields are left as they
+// are.
+type Change struct {
+	Date        *time.Time
+	Amount      *decimal.Decimal
+	Description *string
+}
+
+func (r *Rule) Create(definition Definition, happenedAt time.Time) (evt event_store.Event, err error) {
+	if r.State != State_Uncreated {
+		return nil, nil
+	}
+
+	if !definition.Kind.IsValid() || !definition.Cadence.IsValid() || definition.AccountID == uuid.Nil ||
+		(definition.EndDate != nil && definition.EndDate.Before(definition.StartDate)) {
+		return nil, ErrInvalidRule
+	}
+
+	if !definition.Amount.IsPositive() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	return &events.RuleCreated{
+		RuleID:      r.ID,
+		Kind:        string(definition.Kind),
+		AccountID:   definition.AccountID,
+		Currency:    definition.Currency,
+		Amount:      definition.Amount,
+		Category:    definition.Category,
+		Description: definition.Description,
+		Frequency:   string(definition.Cadence.Frequency),
+		Interval:    definition.Cadence.Interval,
+		StartDate:   definition.StartDate,
+		EndDate:     definition.EndDate,
+		HappenedAt:  happenedAt,
+	}, nil
+}
+
+// Skip leaves out the n-th occurrence. Skipping the last one finishes the
+// rule.
+func (r *Rule) Skip(n int, happenedAt time.Time) (evts []event_store.Event, err error) {
+	occurrence, err := r.pending(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if occurrence.Skipped {
+		return nil, nil
+	}
+
+	evts = append(evts, &events.OccurrenceSkipped{
+		RuleID:     r.ID,
+		Number:     n,
+		HappenedAt: happenedAt,
+	})
+
+	if n == r.Next {
+		evts = append(evts, r.finishedAfter(n, happenedAt)...)
+	}
+
+	return evts, nil
+}
+
+// Modify changes the date, the amount or the description of the n-th
+// occurrence. Its date must stay between the scheduled dates of the previous
+// and of the next occurrence, so that occurrences keep their order.
+func (r *Rule) Modify(n int, change Change, happenedAt time.Time) (evt event_store.Event, err error) {
+	occurrence, err := r.pending(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if occurrence.Skipped {
+		return nil, ErrOccurrenceSkipped
+	}
+
+	modified := occurrence
+	if change.Date != nil {
+		modified.Date = *change.Date
+	}
+	if change.Amount != nil {
+		modified.Amount = *change.Amount
+	}
+	if change.Description != nil {
+		modified.Description = *change.Description
+	}
+
+	if modified.Date.Equal(occurrence.Date) && modified.Amount.Equal(occurrence.Amount) && modified.Description == occurrence.Description {
+		return nil, nil
+	}
+
+	if !modified.Amount.IsPositive() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	if (n > 1 && !modified.Date.After(r.Cadence.Date(r.StartDate, n-1))) || !modified.Date.Before(r.Cadence.Date(r.StartDate, n+1)) {
+		return nil, ErrInvalidOccurrenceDate
+	}
+
+	return &events.OccurrenceModified{
+		RuleID:      r.ID,
+		Number:      n,
+		Date:        modified.Date,
+		Amount:      modified.Amount,
+		Description: modified.Description,
+		HappenedAt:  happenedAt,
+	}, nil
+}
+
+// PostNext posts the next occurrence if it is due as of now, as the
+// transaction whose ID TransactionID derives from the rule and the number of
+// the occurrence. Posting the last occurrence finishes the rule.
+func (r *Rule) PostNext(now time.Time, happenedAt time.Time) (evts []event_store.Event, err error) {
+	if r.State == State_Uncreated {
+		return nil, ErrRuleNotCreated
+	}
+
+	due := r.Due(now)
+	if len(due) == 0 {
+		return nil, nil
+	}
+
+	occurrence := due[0]
+	evts = append(evts, &events.OccurrencePosted{
+		RuleID:        r.ID,
+		Number:        occurrence.Number,
+		TransactionID: TransactionID(r.ID, occurrence.Number),
+		Date:          occurrence.Date,
+		Amount:        occurrence.Amount,
+		HappenedAt:    happenedAt,
+	})
+
+	return append(evts, r.finishedAfter(occurrence.Number, happenedAt)...), nil
+}
+
+// Fail marks the n-th occurrence as failed for reason, its transaction having
+// been rejected, so that the following ones can be posted. Only the next
+// occurrence to post can fail, and failing the last one finishes the rule.
+func (r *Rule) Fail(n int, reason string, happenedAt time.Time) (evts []event_store.Event, err error) {
+	if _, err := r.pending(n); err != nil {
+		return nil, err
+	}
+
+	if n != r.Next {
+		return nil, nil
+	}
+
+	evts = append(evts, &events.OccurrenceFailed{
+		RuleID:     r.ID,
+		Number:     n,
+		Reason:     reason,
+		HappenedAt: happenedAt,
+	})
+
+	return append(evts, r.finishedAfter(n, happenedAt)...), nil
+}
+
+// TransactionID returns the ID of the transaction the n-th occurrence of a
+// rule is posted as, so that an occurrence cannot be posted twice.
+func TransactionID(ruleID uuid.UUID, n int) uuid.UUID {
+	return uuid.NewMD5(ruleID, []byte(fmt.Sprintf("occurrence_%d", n)))
+}
+
+// pending returns the n-th occurrence, provided it can still be changed.
+func (r *Rule) pending(n int) (Occurrence, error) {
+	switch r.State {
+	case State_Uncreated:
+		return Occurrence{}, ErrRuleNotCreated
+	case State_Finished:
+		return Occurrence{}, ErrRuleFinished
+	}
+
+	occurrence, ok := r.Occurrence(n)
+	if !ok {
+		return Occurrence{}, ErrOccurrenceNotFound
+	}
+
+	if n < r.Next {
+		return Occurrence{}, ErrOccurrencePosted
+	}
+
+	return occurrence, nil
+}
+
+// finishedAfter returns the event finishing the rule if no occurrence
+// follows the n-th one.
+func (r *Rule) finishedAfter(n int, happenedAt time.Time) []event_store.Event {
+	if _, ok := r.Occurrence(r.nextAfter(n)); ok {
+		return nil
+	}
+
+	return []event_store.Event{&events.RuleFinished{
+		RuleID:     r.ID,
+		HappenedAt: happenedAt,
+	}}
+}
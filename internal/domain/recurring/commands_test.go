@@ -0,0 +1,370 @@
+package recurring_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/recurring"
+	"github.com/somatom98/brokeli/internal/domain/recurring/events"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+var monthly = recurring.Cadence{Frequency: recurring.Frequency_Monthly, Interval: 1}
+
+func rule(t *testing.T, endDate *time.Time) *recurring.Rule {
+	r := recurring.New(uuid.New())
+	evt, err := r.Create(recurring.Definition{
+		Kind:        recurring.Kind_Expense,
+		AccountID:   uuid.New(),
+		Currency:    "EUR",
+		Amount:      decimal.NewFromInt(950),
+		Category:    "Rent",
+		Description: "Flat",
+		Cadence:     monthly,
+		StartDate:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     endDate,
+	}, time.Now())
+	require.NoError(t, err)
+	r.ApplyRuleCreated(*evt.(*events.RuleCreated))
+	return r
+}
+
+func apply(t *testing.T, r *recurring.Rule, evts ...event_store.Event) {
+	records := make([]event_store.Record, 0, len(evts))
+	for _, evt := range evts {
+		records = append(records, event_store.Record{Event: evt})
+	}
+	require.NoError(t, r.Hydrate(records))
+}
+
+func TestCreate(t *testing.T) {
+	now := time.Now()
+	definition := recurring.Definition{
+		Kind:      recurring.Kind_Income,
+		AccountID: uuid.New(),
+		Currency:  "EUR",
+		Amount:    decimal.NewFromInt(2500),
+		Category:  "Salary",
+		Cadence:   monthly,
+		StartDate: time.Date(2024, 1, 27, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("should emit rule created event", func(t *testing.T) {
+		// arrange
+		id := uuid.New()
+		r := recurring.New(id)
+
+		// act
+		evt, err := r.Create(definition, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.RuleCreated{
+			RuleID:     id,
+			Kind:       "INCOME",
+			AccountID:  definition.AccountID,
+			Currency:   "EUR",
+			Amount:     decimal.NewFromInt(2500),
+			Category:   "Salary",
+			Frequency:  "MONTHLY",
+			Interval:   1,
+			StartDate:  definition.StartDate,
+			HappenedAt: now,
+		}, evt)
+	})
+
+	t.Run("should do nothing when the rule is already created", func(t *testing.T) {
+		// arrange
+		r := rule(t, nil)
+
+		// act
+		evt, err := r.Create(definition, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when the cadence is invalid", func(t *testing.T) {
+		// arrange
+		invalid := definition
+		invalid.Cadence = recurring.Cadence{Frequency: "HOURLY", Interval: 1}
+
+		// act
+		_, err := recurring.New(uuid.New()).Create(invalid, now)
+
+		// assert
+		assert.ErrorIs(t, err, recurring.ErrInvalidRule)
+	})
+
+	t.Run("should return error when the rule ends before it starts", func(t *testing.T) {
+		// arrange
+		invalid := definition
+		endDate := definition.StartDate.AddDate(0, 0, -1)
+		invalid.EndDate = &endDate
+
+		// act
+		_, err := recurring.New(uuid.New()).Create(invalid, now)
+
+		// assert
+		assert.ErrorIs(t, err, recurring.ErrInvalidRule)
+	})
+
+	t.Run("should return error when the amount is not positive", func(t *testing.T) {
+		// arrange
+		invalid := definition
+		invalid.Amount = decimal.Zero
+
+		// act
+		_, err := recurring.New(uuid.New()).Create(invalid, now)
+
+		// assert
+		assert.ErrorIs(t, err, recurring.ErrNegativeOrNullAmount)
+	})
+}
+
+func TestPostNext(t *testing.T) {
+	now := time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should post the first due occurrence", func(t *testing.T) {
+		// arrange
+		r := rule(t, nil)
+
+		// act
+		evts, err := r.PostNext(now, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, []event_store.Event{&events.OccurrencePosted{
+			RuleID:        r.ID,
+			Number:        1,
+			TransactionID: recurring.TransactionID(r.ID, 1),
+			Date:          time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Amount:        decimal.NewFromInt(950),
+			HappenedAt:    now,
+		}}, evts)
+	})
+
+	t.Run("should do nothing when no occurrence is due", func(t *testing.T) {
+		// arrange
+		r := rule(t, nil)
+		apply(t, r,
+			&events.OccurrencePosted{RuleID: r.ID, Number: 1},
+			&events.OccurrencePosted{RuleID: r.ID, Number: 2},
+		)
+
+		// act
+		evts, err := r.PostNext(now, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Empty(t, evts)
+	})
+
+	t.Run("should finish the rule with its last occurrence", func(t *testing.T) {
+		// arrange
+		endDate := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		r := rule(t, &endDate)
+		apply(t, r, &events.OccurrencePosted{RuleID: r.ID, Number: 1})
+
+		// act
+		evts, err := r.PostNext(now, now)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, evts, 2)
+		assert.Equal(t, 2, evts[0].(*events.OccurrencePosted).Number)
+		assert.Equal(t, &events.RuleFinished{RuleID: r.ID, HappenedAt: now}, evts[1])
+	})
+
+	t.Run("should post a modified occurrence as modified", func(t *testing.T) {
+		// arrange
+		r := rule(t, nil)
+		date := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+		apply(t, r, &events.OccurrenceModified{RuleID: r.ID, Number: 1, Date: date, Amount: decimal.NewFromInt(980), Description: "Flat"})
+
+		// act
+		evts, err := r.PostNext(now, now)
+
+		// assert
+		require.NoError(t, err)
+		posted := evts[0].(*events.OccurrencePosted)
+		assert.Equal(t, date, posted.Date)
+		assert.Equal(t, "980", posted.Amount.String())
+	})
+
+	t.Run("should return error when the rule is not created", func(t *testing.T) {
+		// act
+		_, err := recurring.New(uuid.New()).PostNext(now, now)
+
+		// assert
+		assert.ErrorIs(t, err, recurring.ErrRuleNotCreated)
+	})
+}
+
+func TestSkip(t *testing.T) {
+	now := time.Now()
+
+	t.Run("should skip an upcoming occurrence", func(t *testing.T) {
+		// arrange
+		r := rule(t, nil)
+
+		// act
+		evts, err := r.Skip(2, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, []event_store.Event{&events.OccurrenceSkipped{RuleID: r.ID, Number: 2, HappenedAt: now}}, evts)
+	})
+
+	t.Run("should post the occurrence following a skipped one", func(t *testing.T) {
+		// arrange
+		r := rule(t, nil)
+		apply(t, r, &events.OccurrenceSkipped{RuleID: r.ID, Number: 1})
+
+		// act
+		evts, err := r.PostNext(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, 2, evts[0].(*events.OccurrencePosted).Number)
+	})
+
+	t.Run("should finish the rule when skipping its last occurrence", func(t *testing.T) {
+		// arrange
+		endDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		r := rule(t, &endDate)
+
+		// act
+		evts, err := r.Skip(1, now)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, evts, 2)
+		assert.Equal(t, &events.RuleFinished{RuleID: r.ID, HappenedAt: now}, evts[1])
+	})
+
+	t.Run("should do nothing when the occurrence is already skipped", func(t *testing.T) {
+		// arrange
+		r := rule(t, nil)
+		apply(t, r, &events.OccurrenceSkipped{RuleID: r.ID, Number: 2})
+
+		// act
+		evts, err := r.Skip(2, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Empty(t, evts)
+	})
+
+	t.Run("should return error when the occurrence is posted", func(t *testing.T) {
+		// arrange
+		r := rule(t, nil)
+		apply(t, r, &events.OccurrencePosted{RuleID: r.ID, Number: 1})
+
+		// act
+		_, err := r.Skip(1, now)
+
+		// assert
+		assert.ErrorIs(t, err, recurring.ErrOccurrencePosted)
+	})
+
+	t.Run("should return error when the occurrence is past the end date", func(t *testing.T) {
+		// arrange
+		endDate := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		r := rule(t, &endDate)
+
+		// act
+		_, err := r.Skip(4, now)
+
+		// assert
+		assert.ErrorIs(t, err, recurring.ErrOccurrenceNotFound)
+	})
+}
+
+func TestModify(t *testing.T) {
+	now := time.Now()
+
+	t.Run("should modify the amount of an occurrence", func(t *testing.T) {
+		// arrange
+		r := rule(t, nil)
+		amount := decimal.NewFromInt(980)
+
+		// act
+		evt, err := r.Modify(2, recurring.Change{Amount: &amount}, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.OccurrenceModified{
+			RuleID:      r.ID,
+			Number:      2,
+			Date:        time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			Amount:      amount,
+			Description: "Flat",
+			HappenedAt:  now,
+		}, evt)
+	})
+
+	t.Run("should do nothing when nothing changes", func(t *testing.T) {
+		// arrange
+		r := rule(t, nil)
+		description := "Flat"
+
+		// act
+		evt, err := r.Modify(2, recurring.Change{Description: &description}, now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when the date passes the next occurrence", func(t *testing.T) {
+		// arrange
+		r := rule(t, nil)
+		date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+		// act
+		_, err := r.Modify(2, recurring.Change{Date: &date}, now)
+
+		// assert
+		assert.ErrorIs(t, err, recurring.ErrInvalidOccurrenceDate)
+	})
+
+	t.Run("should return error when the occurrence is skipped", func(t *testing.T) {
+		// arrange
+		r := rule(t, nil)
+		apply(t, r, &events.OccurrenceSkipped{RuleID: r.ID, Number: 2})
+		amount := decimal.NewFromInt(980)
+
+		// act
+		_, err := r.Modify(2, recurring.Change{Amount: &amount}, now)
+
+		// assert
+		assert.ErrorIs(t, err, recurring.ErrOccurrenceSkipped)
+	})
+}
+
+func TestUpcoming(t *testing.T) {
+	t.Run("should list the occurrences not posted yet up to a date", func(t *testing.T) {
+		// arrange
+		r := rule(t, nil)
+		apply(t, r,
+			&events.OccurrencePosted{RuleID: r.ID, Number: 1},
+			&events.OccurrenceSkipped{RuleID: r.ID, Number: 3},
+		)
+
+		// act
+		occurrences := r.Upcoming(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC))
+
+		// assert
+		require.Len(t, occurrences, 3)
+		assert.Equal(t, 2, occurrences[0].Number)
+		assert.True(t, occurrences[1].Skipped)
+		assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), occurrences[2].Date)
+	})
+}
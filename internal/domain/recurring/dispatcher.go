@@ -0,0 +1,189 @@
+package recurring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/recurring/events"
+	"github.com/somatom98/brokeli/internal/domain/transaction"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+// Transactions registers the expenses and incomes the occurrences of rules
+// are posted as.
+type Transactions interface {
+	RegisterExpense(ctx context.Context, id uuid.UUID, accountID uuid.UUID, currency values.Currency, amount decimal.Decimal, category string, description string, happenedAt time.Time) error
+	RegisterIncome(ctx context.Context, id uuid.UUID, accountID uuid.UUID, currency values.Currency, amount decimal.Decimal, category string, description string, happenedAt time.Time) error
+}
+
+type Dispatcher struct {
+	es           event_store.Store[*Rule]
+	transactions Transactions
+	uow          event_store.UnitOfWork
+}
+
+func NewDispatcher(
+	es event_store.Store[*Rule],
+	transactions Transactions,
+	uow event_store.UnitOfWork,
+) *Dispatcher {
+	return &Dispatcher{
+		es:           es,
+		transactions: transactions,
+		uow:          uow,
+	}
+}
+
+func (d *Dispatcher) Create(ctx context.Context, id uuid.UUID, definition Definition, happenedAt time.Time) error {
+	return d.es.Execute(ctx, id, func(aggr *Rule, version uint64) ([]event_store.Event, error) {
+		return event_store.One(aggr.Create(definition, happenedAt))
+	})
+}
+
+func (d *Dispatcher) Skip(ctx context.Context, id uuid.UUID, n int, happenedAt time.Time) error {
+	return d.es.Execute(ctx, id, func(aggr *Rule, version uint64) ([]event_store.Event, error) {
+		return aggr.Skip(n, happenedAt)
+	})
+}
+
+func (d *Dispatcher) Modify(ctx context.Context, id uuid.UUID, n int, change Change, happenedAt time.Time) error {
+	return d.es.Execute(ctx, id, func(aggr *Rule, version uint64) ([]event_store.Event, error) {
+		return event_store.One(aggr.Modify(n, change, happenedAt))
+	})
+}
+
+// PostDue posts every occurrence of the rule due as of now, in order. Each
+// occurrence is posted in a unit of work of its own together with its
+// transaction, so that a failing one leaves the previous ones posted and is
+// retried by the next call. An occurrence whose transaction is rejected is
+// marked as failed instead, and the following ones are posted; the returned
+// error wraps ErrOccurrenceFailed for each of them.
+func (d *Dispatcher) PostDue(ctx context.Context, id uuid.UUID, now time.Time) error {
+	var failures []error
+	for {
+		posted, err := d.postNext(ctx, id, now)
+		if errors.Is(err, ErrOccurrenceFailed) {
+			failures = append(failures, err)
+			continue
+		}
+		if err != nil {
+			return errors.Join(append(failures, err)...)
+		}
+		if !posted {
+			return errors.Join(failures...)
+		}
+	}
+}
+
+// SkipMissed skips the occurrences of the rule due as of now but the last
+// one, which is left to be posted.
+func (d *Dispatcher) SkipMissed(ctx context.Context, id uuid.UUID, now time.Time) error {
+	return d.es.Execute(ctx, id, func(aggr *Rule, version uint64) ([]event_store.Event, error) {
+		due := aggr.Due(now)
+
+		var evts []event_store.Event
+		for i := 0; i < len(due)-1; i++ {
+			skipped, err := aggr.Skip(due[i].Number, now)
+			if err != nil {
+				return nil, err
+			}
+			aggr.ApplyOccurrenceSkipped(*skipped[0].(*events.OccurrenceSkipped))
+			evts = append(evts, skipped...)
+		}
+
+		return evts, nil
+	})
+}
+
+func (d *Dispatcher) postNext(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	postID := uuid.New()
+	metadata := event_store.MetadataFrom(ctx)
+	if metadata.CorrelationID == uuid.Nil {
+		metadata.CorrelationID = postID
+	}
+	ctx = event_store.WithMetadata(ctx, metadata)
+
+	var posted *events.OccurrencePosted
+	var rule Rule
+	err := d.uow.Do(ctx, func(ctx context.Context) error {
+		posted = nil
+		err := d.es.Execute(ctx, id, func(aggr *Rule, version uint64) ([]event_store.Event, error) {
+			evts, err := aggr.PostNext(now, now)
+			if err != nil || len(evts) == 0 {
+				return nil, err
+			}
+			posted = evts[0].(*events.OccurrencePosted)
+			rule = *aggr
+			evts[0] = event_store.WithID(postID, evts[0])
+			return evts, nil
+		})
+		if err != nil || posted == nil {
+			return err
+		}
+
+		occurrence, _ := rule.Occurrence(posted.Number)
+		ctx = event_store.CausedBy(ctx, event_store.Record{ID: postID, Metadata: metadata})
+
+		switch rule.Kind {
+		case Kind_Expense:
+			err = d.transactions.RegisterExpense(ctx, posted.TransactionID, rule.AccountID, rule.Currency, posted.Amount, rule.Category, occurrence.Description, posted.Date)
+		case Kind_Income:
+			err = d.transactions.RegisterIncome(ctx, posted.TransactionID, rule.AccountID, rule.Currency, posted.Amount, rule.Category, occurrence.Description, posted.Date)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to post occurrence %d: %w", posted.Number, err)
+		}
+
+		return nil
+	})
+	if reason, ok := rejection(err); ok && posted != nil {
+		return true, d.fail(ctx, id, posted.Number, reason, now)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return posted != nil, nil
+}
+
+// fail marks the n-th occurrence of the rule as failed, its transaction having
+// been rejected for reason.
+func (d *Dispatcher) fail(ctx context.Context, id uuid.UUID, n int, reason error, now time.Time) error {
+	err := d.es.Execute(ctx, id, func(aggr *Rule, version uint64) ([]event_store.Event, error) {
+		return aggr.Fail(n, reason.Error(), now)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark occurrence %d as failed: %w", n, err)
+	}
+	return fmt.Errorf("%w: occurrence %d: %w", ErrOccurrenceFailed, n, reason)
+}
+
+// rejections are the errors of the transactions of occurrences that posting
+// them again would not change.
+var rejections = []error{
+	account.ErrAccountClosed,
+	account.ErrInsufficientFunds,
+	transaction.ErrNegativeOrNullAmount,
+	transaction.ErrInvalidAmountOrCurrency,
+}
+
+// rejection returns which of the rejections err is, if any.
+func rejection(err error) (error, bool) {
+	for _, rejection := range rejections {
+		if errors.Is(err, rejection) {
+			return rejection, true
+		}
+	}
+	return nil, false
+}
+
+// Get returns the rule as of its latest event, along with its version.
+func (d *Dispatcher) Get(ctx context.Context, id uuid.UUID) (*Rule, uint64, error) {
+	return d.es.GetAggregate(ctx, id)
+}
@@ -0,0 +1,172 @@
+package recurring_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/position"
+	"github.com/somatom98/brokeli/internal/domain/projections/recurring_rules"
+	"github.com/somatom98/brokeli/internal/domain/recurring"
+	"github.com/somatom98/brokeli/internal/domain/transaction"
+	transaction_events "github.com/somatom98/brokeli/internal/domain/transaction/events"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+func TestScheduler_Post(t *testing.T) {
+	ctx := context.Background()
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T) (*recurring.Scheduler, *recurring.Dispatcher, *event_store.InMemoryStore[*transaction.Transaction], uuid.UUID, *account.Dispatcher, uuid.UUID) {
+		ruleES := event_store.NewInMemory(recurring.New)
+		transactionES := event_store.NewInMemory(transaction.New)
+		accountES := event_store.NewInMemory(account.New)
+		uow := event_store.NewInMemoryUnitOfWork()
+		dispatcher := recurring.NewDispatcher(ruleES, transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), uow), uow)
+		scheduler := recurring.NewScheduler(dispatcher, recurring_rules.New(ruleES, recurring_rules.NewInMemoryRepository()), time.Hour, true)
+
+		ruleID, accountID := uuid.New(), uuid.New()
+		accounts := account.NewDispatcher(accountES)
+		require.NoError(t, accounts.Open(ctx, accountID, "Checking", "EUR", startDate))
+		require.NoError(t, dispatcher.Create(ctx, ruleID, recurring.Definition{
+			Kind:        recurring.Kind_Expense,
+			AccountID:   accountID,
+			Currency:    "EUR",
+			Amount:      decimal.NewFromInt(950),
+			Category:    "Rent",
+			Description: "Flat",
+			Cadence:     monthly,
+			StartDate:   startDate,
+		}, startDate))
+
+		return scheduler, dispatcher, transactionES, ruleID, accounts, accountID
+	}
+
+	expenses := func(t *testing.T, transactionES *event_store.InMemoryStore[*transaction.Transaction]) []transaction_events.MoneySpent {
+		records, err := transactionES.ReadFrom(ctx, 0, 100)
+		require.NoError(t, err)
+
+		var expenses []transaction_events.MoneySpent
+		for _, record := range records {
+			expenses = append(expenses, record.Content().(transaction_events.MoneySpent))
+		}
+		return expenses
+	}
+
+	t.Run("should catch up on every missed occurrence, each on its own date", func(t *testing.T) {
+		// arrange
+		scheduler, _, transactionES, ruleID, _, _ := setup(t)
+
+		// act
+		err := scheduler.Post(ctx, now, true)
+
+		// assert
+		require.NoError(t, err)
+
+		posted := expenses(t, transactionES)
+		require.Len(t, posted, 3)
+		for i, expense := range posted {
+			assert.Equal(t, startDate.AddDate(0, i, 0), expense.HappenedAt)
+			assert.Equal(t, "Rent", expense.Category)
+		}
+
+		records, err := transactionES.ReadAggregate(ctx, recurring.TransactionID(ruleID, 1))
+		require.NoError(t, err)
+		assert.Len(t, records, 1)
+	})
+
+	t.Run("should not post an occurrence twice", func(t *testing.T) {
+		// arrange
+		scheduler, _, transactionES, _, _, _ := setup(t)
+		require.NoError(t, scheduler.Post(ctx, now, true))
+
+		// act
+		err := scheduler.Post(ctx, now, true)
+
+		// assert
+		require.NoError(t, err)
+		assert.Len(t, expenses(t, transactionES), 3)
+	})
+
+	t.Run("should skip the missed occurrences but the latest without catching up", func(t *testing.T) {
+		// arrange
+		scheduler, dispatcher, transactionES, ruleID, _, _ := setup(t)
+
+		// act
+		err := scheduler.Post(ctx, now, false)
+
+		// assert
+		require.NoError(t, err)
+
+		posted := expenses(t, transactionES)
+		require.Len(t, posted, 1)
+		assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), posted[0].HappenedAt)
+
+		rule, _, err := dispatcher.Get(ctx, ruleID)
+		require.NoError(t, err)
+		assert.True(t, rule.Overrides[1].Skipped)
+		assert.True(t, rule.Overrides[2].Skipped)
+		assert.Equal(t, 4, rule.Next)
+	})
+
+	t.Run("should mark an occurrence rejected by its account as failed and post the following ones", func(t *testing.T) {
+		// arrange
+		scheduler, dispatcher, transactionES, ruleID, accounts, accountID := setup(t)
+		require.NoError(t, accounts.Deposit(ctx, accountID, "EUR", decimal.NewFromInt(2000), "", "", "test-user", startDate))
+		require.NoError(t, accounts.SetOverdraftLimit(ctx, accountID, "EUR", decimal.Zero, startDate))
+		amount := decimal.NewFromInt(2000)
+		require.NoError(t, dispatcher.Modify(ctx, ruleID, 2, recurring.Change{Amount: &amount}, startDate))
+
+		// act
+		err := scheduler.Post(ctx, now, true)
+
+		// assert
+		require.ErrorIs(t, err, recurring.ErrOccurrenceFailed)
+		assert.ErrorIs(t, err, account.ErrInsufficientFunds)
+
+		posted := expenses(t, transactionES)
+		require.Len(t, posted, 2)
+		assert.Equal(t, startDate, posted[0].HappenedAt)
+		assert.Equal(t, startDate.AddDate(0, 2, 0), posted[1].HappenedAt)
+
+		rule, _, err := dispatcher.Get(ctx, ruleID)
+		require.NoError(t, err)
+		assert.True(t, rule.Overrides[2].Failed)
+		assert.Equal(t, "insufficient_funds", rule.Overrides[2].FailureReason)
+		assert.Equal(t, 4, rule.Next)
+	})
+
+	t.Run("should not retry a failed occurrence", func(t *testing.T) {
+		// arrange
+		scheduler, _, transactionES, _, accounts, accountID := setup(t)
+		require.NoError(t, accounts.Close(ctx, accountID, startDate))
+		require.ErrorIs(t, scheduler.Post(ctx, now, true), account.ErrAccountClosed)
+
+		// act
+		err := scheduler.Post(ctx, now, true)
+
+		// assert
+		require.NoError(t, err)
+		assert.Empty(t, expenses(t, transactionES))
+	})
+
+	t.Run("should not post a skipped occurrence", func(t *testing.T) {
+		// arrange
+		scheduler, dispatcher, transactionES, ruleID, _, _ := setup(t)
+		require.NoError(t, dispatcher.Skip(ctx, ruleID, 2, startDate))
+
+		// act
+		err := scheduler.Post(ctx, now, true)
+
+		// assert
+		require.NoError(t, err)
+		assert.Len(t, expenses(t, transactionES), 2)
+	})
+}
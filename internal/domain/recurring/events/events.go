@@ -0,0 +1,129 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+const (
+	TypeRuleCreated        string = "RecurringRuleCreated"
+	TypeOccurrenceSkipped  string = "RecurringOccurrenceSkipped"
+	TypeOccurrenceModified string = "RecurringOccurrenceModified"
+	TypeOccurrencePosted   string = "RecurringOccurrencePosted"
+	TypeOccurrenceFailed   string = "RecurringOccurrenceFailed"
+	TypeRuleFinished       string = "RecurringRuleFinished"
+)
+
+// RuleCreated schedules an expense or an income of Amount on an account,
+// every Interval days, weeks, months or years from StartDate, and up to
+// EndDate if any.
+type RuleCreated struct {
+	RuleID      uuid.UUID
+	Kind        string
+	AccountID   uuid.UUID
+	Currency    values.Currency
+	Amount      decimal.Decimal
+	Category    string
+	Description string
+	Frequency   string
+	Interval    int
+	StartDate   time.Time
+	EndDate     *time.Time
+	HappenedAt  time.Time
+}
+
+func (e RuleCreated) Type() string {
+	return TypeRuleCreated
+}
+
+func (e RuleCreated) Content() any {
+	return e
+}
+
+// OccurrenceSkipped leaves out the Number-th occurrence of a rule, which is
+// not posted.
+type OccurrenceSkipped struct {
+	RuleID     uuid.UUID
+	Number     int
+	HappenedAt time.Time
+}
+
+func (e OccurrenceSkipped) Type() string {
+	return TypeOccurrenceSkipped
+}
+
+func (e OccurrenceSkipped) Content() any {
+	return e
+}
+
+// OccurrenceModified posts the Number-th occurrence of a rule on Date, with
+// Amount and Description, instead of the ones of the rule.
+type OccurrenceModified struct {
+	RuleID      uuid.UUID
+	Number      int
+	Date        time.Time
+	Amount      decimal.Decimal
+	Description string
+	HappenedAt  time.Time
+}
+
+func (e OccurrenceModified) Type() string {
+	return TypeOccurrenceModified
+}
+
+func (e OccurrenceModified) Content() any {
+	return e
+}
+
+// OccurrencePosted records the transaction the Number-th occurrence of a rule
+// was posted as.
+type OccurrencePosted struct {
+	RuleID        uuid.UUID
+	Number        int
+	TransactionID uuid.UUID
+	Date          time.Time
+	Amount        decimal.Decimal
+	HappenedAt    time.Time
+}
+
+func (e OccurrencePosted) Type() string {
+	return TypeOccurrencePosted
+}
+
+func (e OccurrencePosted) Content() any {
+	return e
+}
+
+// OccurrenceFailed records that the transaction of the Number-th occurrence
+// of a rule was rejected for Reason, so that it is not posted again.
+type OccurrenceFailed struct {
+	RuleID     uuid.UUID
+	Number     int
+	Reason     string
+	HappenedAt time.Time
+}
+
+func (e OccurrenceFailed) Type() string {
+	return TypeOccurrenceFailed
+}
+
+func (e OccurrenceFailed) Content() any {
+	return e
+}
+
+// RuleFinished follows the last occurrence of a rule before its end date.
+type RuleFinished struct {
+	RuleID     uuid.UUID
+	HappenedAt time.Time
+}
+
+func (e RuleFinished) Type() string {
+	return TypeRuleFinished
+}
+
+func (e RuleFinished) Content() any {
+	return e
+}
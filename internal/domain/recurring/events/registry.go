@@ -0,0 +1,23 @@
+package events
+
+import "github.com/somatom98/brokeli/pkg/event_store"
+
+// Factory builds the value each stored recurring rule event is decoded into.
+func Factory() map[string]func() any {
+	return map[string]func() any{
+		TypeRuleCreated:        func() any { return &RuleCreated{} },
+		TypeOccurrenceSkipped:  func() any { return &OccurrenceSkipped{} },
+		TypeOccurrenceModified: func() any { return &OccurrenceModified{} },
+		TypeOccurrencePosted:   func() any { return &OccurrencePosted{} },
+		TypeOccurrenceFailed:   func() any { return &OccurrenceFailed{} },
+		TypeRuleFinished:       func() any { return &RuleFinished{} },
+	}
+}
+
+// Upcasters migrates the payloads stored by older versions of the recurring
+// rule events. Changing the shape of an event requires registering the step
+// from its previous schema version here, together with a fixture of that
+// version in testdata.
+func Upcasters() *event_store.Upcasters {
+	return event_store.NewUpcasters()
+}
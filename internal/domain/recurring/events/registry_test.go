@@ -0,0 +1,114 @@
+package events_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/recurring/events"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+// The fixtures in testdata/v<N> are payloads as they were stored with schema
+// version N. They must keep decoding into the current events.
+func TestHistoricalPayloads(t *testing.T) {
+	ruleID := uuid.MustParse("3c9d2e7a-1f4b-4a6c-8d2e-5b7a9c1d3e5f")
+	endDate := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		fixture  string
+		expected event_store.Event
+	}{
+		{
+			fixture: "v1/RecurringRuleCreated.json",
+			expected: events.RuleCreated{
+				RuleID:      ruleID,
+				Kind:        "EXPENSE",
+				AccountID:   uuid.MustParse("6f1c2a5e-3b1d-4c7a-9e1f-2a3b4c5d6e7f"),
+				Currency:    "EUR",
+				Amount:      decimal.RequireFromString("950"),
+				Category:    "Rent",
+				Description: "Flat",
+				Frequency:   "MONTHLY",
+				Interval:    1,
+				StartDate:   time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+				EndDate:     &endDate,
+				HappenedAt:  time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/RecurringOccurrenceSkipped.json",
+			expected: events.OccurrenceSkipped{
+				RuleID:     ruleID,
+				Number:     3,
+				HappenedAt: time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/RecurringOccurrenceFailed.json",
+			expected: events.OccurrenceFailed{
+				RuleID:     ruleID,
+				Number:     3,
+				Reason:     "account_closed",
+				HappenedAt: time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/RecurringOccurrenceModified.json",
+			expected: events.OccurrenceModified{
+				RuleID:      ruleID,
+				Number:      4,
+				Date:        time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC),
+				Amount:      decimal.RequireFromString("980"),
+				Description: "Flat, new rent",
+				HappenedAt:  time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/RecurringOccurrencePosted.json",
+			expected: events.OccurrencePosted{
+				RuleID:        ruleID,
+				Number:        1,
+				TransactionID: uuid.MustParse("9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"),
+				Date:          time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+				Amount:        decimal.RequireFromString("950"),
+				HappenedAt:    time.Date(2024, 1, 31, 6, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/RecurringRuleFinished.json",
+			expected: events.RuleFinished{
+				RuleID:     ruleID,
+				HappenedAt: time.Date(2024, 12, 31, 6, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	t.Run("should have a fixture for every event type", func(t *testing.T) {
+		for eventType := range events.Factory() {
+			_, err := os.Stat(filepath.Join("testdata", "v1", eventType+".json"))
+			assert.NoError(t, err, eventType)
+		}
+	})
+
+	for _, tt := range tests {
+		t.Run("should decode "+tt.fixture+" into the current event", func(t *testing.T) {
+			// arrange
+			data, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			require.NoError(t, err)
+
+			// act
+			content, err := event_store.UnmarshalEvent(events.Factory(), events.Upcasters(), tt.expected.Type(), 1, data)
+
+			// assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, content)
+		})
+	}
+}
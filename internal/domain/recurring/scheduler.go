@@ -0,0 +1,90 @@
+package recurring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+// Rules lists the rules that may still have occurrences to post.
+type Rules interface {
+	ListActiveRuleIDs(ctx context.Context) ([]uuid.UUID, error)
+}
+
+// Scheduler posts the occurrences of the rules as they fall due.
+type Scheduler struct {
+	dispatcher *Dispatcher
+	rules      Rules
+	interval   time.Duration
+	catchUp    bool
+}
+
+// NewScheduler returns a scheduler posting the due occurrences every
+// interval. In catch-up mode, the occurrences missed while the scheduler was
+// not running are all posted when it starts, each on its own date. Otherwise
+// only the latest missed occurrence of each rule is, and the others are
+// skipped.
+func NewScheduler(dispatcher *Dispatcher, rules Rules, interval time.Duration, catchUp bool) *Scheduler {
+	return &Scheduler{
+		dispatcher: dispatcher,
+		rules:      rules,
+		interval:   interval,
+		catchUp:    catchUp,
+	}
+}
+
+// Run posts the due occurrences right away and then every interval, until
+// ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) error {
+	ctx = event_store.WithSource(ctx, event_store.SourceSystem)
+
+	if err := s.Post(ctx, time.Now(), s.catchUp); err != nil {
+		log.Printf("failed to post recurring occurrences: %v", err)
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.Post(ctx, time.Now(), true); err != nil {
+				log.Printf("failed to post recurring occurrences: %v", err)
+			}
+		}
+	}
+}
+
+// Post posts the occurrences of every active rule due as of now. Unless
+// catching up, all but the latest due occurrence of each rule are skipped.
+// A failing rule does not hold back the others, and an occurrence its
+// account rejects is marked failed and reported once rather than retried.
+func (s *Scheduler) Post(ctx context.Context, now time.Time, catchUp bool) error {
+	ids, err := s.rules.ListActiveRuleIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list rules: %w", err)
+	}
+
+	var errs []error
+	for _, id := range ids {
+		if !catchUp {
+			if err := s.dispatcher.SkipMissed(ctx, id, now); err != nil {
+				errs = append(errs, fmt.Errorf("rule %s: %w", id, err))
+				continue
+			}
+		}
+
+		if err := s.dispatcher.PostDue(ctx, id, now); err != nil {
+			errs = append(errs, fmt.Errorf("rule %s: %w", id, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -1,9 +1,15 @@
 package transaction
 
 import (
+	"bytes"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/somatom98/brokeli/internal/domain/transaction/events"
 	"github.com/somatom98/brokeli/internal/domain/values"
 	"github.com/somatom98/brokeli/pkg/event_store"
@@ -25,8 +31,119 @@ type Transaction struct {
 	State       State
 	Type        values.TransactionType
 	Entries     []values.Entry
+	Lines       []values.SplitLine
 	Category    string
 	Description string
+	HappenedAt  time.Time
+	// ExpenseID is the expense a reimbursement was received for, if any.
+	ExpenseID uuid.UUID
+	// Receivable tracks the reimbursement of an expense.
+	Receivable Receivable
+}
+
+// Receivable is the amount expected back for an expense, from a counterparty
+// into an account, and the reimbursements received for it by their ID.
+type Receivable struct {
+	AccountID      uuid.UUID
+	From           string
+	Expected       decimal.Decimal
+	Reimbursements map[uuid.UUID]decimal.Decimal
+}
+
+// Received returns the amount of the reimbursements received.
+func (r Receivable) Received() decimal.Decimal {
+	received := decimal.Zero
+	for _, amount := range r.Reimbursements {
+		received = received.Add(amount)
+	}
+	return received
+}
+
+// Outstanding returns the amount expected back and not received yet.
+func (r Receivable) Outstanding() decimal.Decimal {
+	return decimal.Max(r.Expected.Sub(r.Received()), decimal.Zero)
+}
+
+// accountIDs returns the accounts the transaction is posted to.
+func (t *Transaction) accountIDs() []uuid.UUID {
+	accountIDs := make([]uuid.UUID, 0, len(t.Entries))
+	for _, entry := range t.Entries {
+		accountIDs = append(accountIDs, entry.AccountID)
+	}
+	return accountIDs
+}
+
+// balanceKey identifies the balance of an account in a currency.
+type balanceKey struct {
+	AccountID uuid.UUID
+	Currency  values.Currency
+}
+
+// balances returns the money the transaction moves on its accounts, by account
+// and currency. Transfers move money through the accounts themselves, and
+// voided transactions no longer move any.
+func (t *Transaction) balances() map[balanceKey]decimal.Decimal {
+	balances := make(map[balanceKey]decimal.Decimal)
+	if t.State != State_Created || t.Type == values.TransactionType_Transfer {
+		return balances
+	}
+
+	for _, entry := range t.Entries {
+		if entry.AccountID == uuid.Nil {
+			continue
+		}
+		key := balanceKey{AccountID: entry.AccountID, Currency: entry.Currency}
+		balances[key] = balances[key].Add(entry.Side.Signed(entry.Amount))
+	}
+	return balances
+}
+
+// postings returns the entries the events change on the accounts of the
+// transaction, to be posted to them, and the date of the transaction after
+// them.
+func (t *Transaction) postings(evts []event_store.Event) ([]values.Entry, time.Time, error) {
+	draft := *t
+	draft.Entries = slices.Clone(t.Entries)
+	draft.Receivable.Reimbursements = maps.Clone(t.Receivable.Reimbursements)
+	for _, evt := range evts {
+		if evt == nil {
+			continue
+		}
+		if err := draft.Hydrate([]event_store.Record{{Event: evt}}); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+
+	before, after := t.balances(), draft.balances()
+	for key, amount := range before {
+		after[key] = after[key].Sub(amount)
+	}
+
+	postings := make([]values.Entry, 0, len(after))
+	for key, amount := range after {
+		if amount.IsZero() {
+			continue
+		}
+		side := values.Side_Credit
+		if amount.IsNegative() {
+			side = values.Side_Debit
+		}
+		postings = append(postings, values.Entry{
+			AccountID: key.AccountID,
+			Currency:  key.Currency,
+			Amount:    amount.Abs(),
+			Side:      side,
+		})
+	}
+	// Accounts are posted to in order, so that units of work lock them in
+	// the same one.
+	slices.SortFunc(postings, func(a, b values.Entry) int {
+		if c := bytes.Compare(a.AccountID[:], b.AccountID[:]); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.Currency), string(b.Currency))
+	})
+	return postings, draft.HappenedAt, nil
 }
 
 func New(id uuid.UUID) *Transaction {
@@ -51,6 +168,12 @@ func (t *Transaction) Hydrate(records []event_store.Record) error {
 				return fmt.Errorf("decode MoneySpent event: %w", err)
 			}
 			t.ApplyExpenseCreated(event)
+		case events.TypeExpenseSplit:
+			event, err := event_store.DecodeEvent[events.ExpenseSplit](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode ExpenseSplit event: %w", err)
+			}
+			t.ApplyExpenseSplit(event)
 		case events.TypeMoneyReceived:
 			event, err := event_store.DecodeEvent[events.MoneyReceived](record.Content())
 			if err != nil {
@@ -75,6 +198,84 @@ func (t *Transaction) Hydrate(records []event_store.Record) error {
 				return fmt.Errorf("decode MoneyInvested event: %w", err)
 			}
 			t.ApplyInvestmentCreated(event)
+		case events.TypeInvestmentSold:
+			event, err := event_store.DecodeEvent[events.InvestmentSold](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode InvestmentSold event: %w", err)
+			}
+			t.ApplyInvestmentSold(event)
+		case events.TypeDividendReceived:
+			event, err := event_store.DecodeEvent[events.DividendReceived](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode DividendReceived event: %w", err)
+			}
+			t.ApplyDividendReceived(event)
+		case events.TypeInterestReceived:
+			event, err := event_store.DecodeEvent[events.InterestReceived](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode InterestReceived event: %w", err)
+			}
+			t.ApplyInterestReceived(event)
+		case events.TypeStockSplit:
+			event, err := event_store.DecodeEvent[events.StockSplit](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode StockSplit event: %w", err)
+			}
+			t.ApplyStockSplit(event)
+		case events.TypeInvestmentFeeCharged:
+			event, err := event_store.DecodeEvent[events.InvestmentFeeCharged](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode InvestmentFeeCharged event: %w", err)
+			}
+			t.ApplyInvestmentFeeCharged(event)
+		case events.TypeAmountChanged:
+			event, err := event_store.DecodeEvent[events.AmountChanged](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode AmountChanged event: %w", err)
+			}
+			t.ApplyAmountChanged(event)
+		case events.TypeRedated:
+			event, err := event_store.DecodeEvent[events.Redated](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode Redated event: %w", err)
+			}
+			t.ApplyRedated(event)
+		case events.TypeRecategorized:
+			event, err := event_store.DecodeEvent[events.Recategorized](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode Recategorized event: %w", err)
+			}
+			t.ApplyRecategorized(event)
+		case events.TypeRedescribed:
+			event, err := event_store.DecodeEvent[events.Redescribed](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode Redescribed event: %w", err)
+			}
+			t.ApplyRedescribed(event)
+		case events.TypeExpenseReimbursed:
+			event, err := event_store.DecodeEvent[events.ExpenseReimbursed](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode ExpenseReimbursed event: %w", err)
+			}
+			t.ApplyExpenseReimbursed(event)
+		case events.TypeReimbursementCancelled:
+			event, err := event_store.DecodeEvent[events.ReimbursementCancelled](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode ReimbursementCancelled event: %w", err)
+			}
+			t.ApplyReimbursementCancelled(event)
+		case events.TypeReimbursementAmended:
+			event, err := event_store.DecodeEvent[events.ReimbursementAmended](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode ReimbursementAmended event: %w", err)
+			}
+			t.ApplyReimbursementAmended(event)
+		case events.TypeTransactionVoided:
+			event, err := event_store.DecodeEvent[events.TransactionVoided](record.Content())
+			if err != nil {
+				return fmt.Errorf("decode TransactionVoided event: %w", err)
+			}
+			t.ApplyTransactionVoided(event)
 		}
 	}
 
@@ -2,6 +2,8 @@ package transaction
 
 import (
 	"errors"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,10 +17,19 @@ var (
 	ErrNegativeOrNullAmount    = errors.New("negative_or_null_amount")
 	ErrInvalidAccount          = errors.New("invalid_account")
 	ErrInvalidAmountOrCurrency = errors.New("invalid_amount_or_currency")
+	ErrNotAmendable            = errors.New("not_amendable")
+	ErrNotVoidable             = errors.New("not_voidable")
+	ErrTransactionNotRecorded  = errors.New("transaction_not_recorded")
+	ErrInvalidSplit            = errors.New("invalid_split")
+	ErrNotReimbursable         = errors.New("not_reimbursable")
+	ErrOverReimbursed          = errors.New("over_reimbursed")
 )
 
+// SetExpectedReimbursement sets the amount the expense is expected to be
+// reimbursed, from the counterparty into the account.
 func (a *Transaction) SetExpectedReimbursement(
 	accountID uuid.UUID,
+	from string,
 	currency values.Currency,
 	amount decimal.Decimal,
 	happenedAt time.Time,
@@ -31,11 +42,150 @@ func (a *Transaction) SetExpectedReimbursement(
 		return nil, ErrNegativeOrNullAmount
 	}
 
+	if err := a.reimbursable(currency); err != nil {
+		return nil, err
+	}
+
+	// The outstanding amount stays on the account it was expected into.
+	if a.Receivable.Expected.IsPositive() && a.Receivable.AccountID != accountID {
+		return nil, ErrInvalidAccount
+	}
+
+	if from == "" {
+		from = a.Receivable.From
+	}
+
+	receivable := a.Receivable
+	receivable.Expected = amount
+
 	return &events.ExpectedReimbursementSet{
-		AccountID:  accountID,
-		Currency:   currency,
-		Amount:     amount,
-		HappenedAt: happenedAt,
+		AccountID:           accountID,
+		From:                from,
+		Currency:            currency,
+		Amount:              amount,
+		PreviousOutstanding: a.Receivable.Outstanding(),
+		Outstanding:         receivable.Outstanding(),
+		HappenedAt:          happenedAt,
+	}, nil
+}
+
+// Reimburse links the reimbursement of reimbursementID to the expense. The
+// reimbursement is credited to the account expecting it, if any, and else to
+// the account it was received into.
+func (a *Transaction) Reimburse(
+	reimbursementID uuid.UUID,
+	accountID uuid.UUID,
+	from string,
+	currency values.Currency,
+	amount decimal.Decimal,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State > State_Created {
+		return nil, nil
+	}
+
+	if !amount.IsPositive() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	if err := a.reimbursable(currency); err != nil {
+		return nil, err
+	}
+
+	if _, ok := a.Receivable.Reimbursements[reimbursementID]; ok {
+		return nil, nil
+	}
+
+	if a.Receivable.AccountID != uuid.Nil {
+		accountID = a.Receivable.AccountID
+	}
+	if a.Receivable.From != "" {
+		from = a.Receivable.From
+	}
+
+	receivable := a.Receivable
+	receivable.Reimbursements = maps.Clone(a.Receivable.Reimbursements)
+	if receivable.Reimbursements == nil {
+		receivable.Reimbursements = make(map[uuid.UUID]decimal.Decimal)
+	}
+	receivable.Reimbursements[reimbursementID] = amount
+
+	return &events.ExpenseReimbursed{
+		ReimbursementID:     reimbursementID,
+		AccountID:           accountID,
+		From:                from,
+		Currency:            currency,
+		Amount:              amount,
+		PreviousOutstanding: a.Receivable.Outstanding(),
+		Outstanding:         receivable.Outstanding(),
+		HappenedAt:          happenedAt,
+	}, nil
+}
+
+// AmendReimbursement corrects the amount of the linked reimbursement of
+// reimbursementID. The reimbursements cannot add up to more than the amount
+// expected back, when one is.
+func (a *Transaction) AmendReimbursement(
+	reimbursementID uuid.UUID,
+	amount decimal.Decimal,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State > State_Created {
+		return nil, nil
+	}
+
+	previous, ok := a.Receivable.Reimbursements[reimbursementID]
+	if !ok || previous.Equal(amount) {
+		return nil, nil
+	}
+
+	receivable := a.Receivable
+	receivable.Reimbursements = maps.Clone(a.Receivable.Reimbursements)
+	receivable.Reimbursements[reimbursementID] = amount
+
+	if receivable.Expected.IsPositive() && receivable.Received().GreaterThan(receivable.Expected) {
+		return nil, ErrOverReimbursed
+	}
+
+	return &events.ReimbursementAmended{
+		ReimbursementID:     reimbursementID,
+		AccountID:           a.Receivable.AccountID,
+		From:                a.Receivable.From,
+		Currency:            a.Entries[0].Currency,
+		PreviousAmount:      previous,
+		Amount:              amount,
+		PreviousOutstanding: a.Receivable.Outstanding(),
+		Outstanding:         receivable.Outstanding(),
+		HappenedAt:          happenedAt,
+	}, nil
+}
+
+// CancelReimbursement unlinks the voided reimbursement of reimbursementID from
+// the expense.
+func (a *Transaction) CancelReimbursement(
+	reimbursementID uuid.UUID,
+) (evt event_store.Event, err error) {
+	if a.State > State_Created {
+		return nil, nil
+	}
+
+	amount, ok := a.Receivable.Reimbursements[reimbursementID]
+	if !ok {
+		return nil, nil
+	}
+
+	receivable := a.Receivable
+	receivable.Reimbursements = maps.Clone(a.Receivable.Reimbursements)
+	delete(receivable.Reimbursements, reimbursementID)
+
+	return &events.ReimbursementCancelled{
+		ReimbursementID:     reimbursementID,
+		AccountID:           a.Receivable.AccountID,
+		From:                a.Receivable.From,
+		Currency:            a.Entries[0].Currency,
+		Amount:              amount,
+		PreviousOutstanding: a.Receivable.Outstanding(),
+		Outstanding:         receivable.Outstanding(),
 	}, nil
 }
 
@@ -65,6 +215,55 @@ func (a *Transaction) RegisterExpense(
 	}, nil
 }
 
+// RegisterSplitExpense records an expense split into lines, which must sum to
+// its amount. Lines without an account are spent from the one of the expense.
+func (a *Transaction) RegisterSplitExpense(
+	accountID uuid.UUID,
+	currency values.Currency,
+	amount decimal.Decimal,
+	lines []values.SplitLine,
+	description string,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State > State_Created {
+		return nil, nil
+	}
+
+	if !amount.IsPositive() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	if len(lines) == 0 {
+		return nil, ErrInvalidSplit
+	}
+
+	split := make([]values.SplitLine, 0, len(lines))
+	total := decimal.Zero
+	for _, line := range lines {
+		if !line.Amount.IsPositive() {
+			return nil, ErrNegativeOrNullAmount
+		}
+		if line.AccountID == uuid.Nil {
+			line.AccountID = accountID
+		}
+		total = total.Add(line.Amount)
+		split = append(split, line)
+	}
+
+	if !total.Equal(amount) {
+		return nil, ErrInvalidSplit
+	}
+
+	return &events.ExpenseSplit{
+		AccountID:   accountID,
+		Currency:    currency,
+		Amount:      amount,
+		Lines:       split,
+		Description: description,
+		HappenedAt:  happenedAt,
+	}, nil
+}
+
 func (a *Transaction) RegisterIncome(
 	accountID uuid.UUID,
 	currency values.Currency,
@@ -91,6 +290,9 @@ func (a *Transaction) RegisterIncome(
 	}, nil
 }
 
+// RegisterTransfer records money moved between two accounts. A transfer
+// across currencies records the rate it was made at and, when referenceRate
+// is positive, the spread paid on it compared to that rate.
 func (a *Transaction) RegisterTransfer(
 	fromAccountID uuid.UUID,
 	fromCurrency values.Currency,
@@ -98,6 +300,7 @@ func (a *Transaction) RegisterTransfer(
 	toAccountID uuid.UUID,
 	toCurrency values.Currency,
 	toAmount decimal.Decimal,
+	referenceRate decimal.Decimal,
 	category string,
 	description string,
 	happenedAt time.Time,
@@ -122,6 +325,13 @@ func (a *Transaction) RegisterTransfer(
 		}
 	}
 
+	var spread decimal.Decimal
+	if fromCurrency == toCurrency || !referenceRate.IsPositive() {
+		referenceRate = decimal.Decimal{}
+	} else {
+		spread = fromAmount.Sub(toAmount.Div(referenceRate)).Round(fromCurrency.MinorUnits())
+	}
+
 	return &events.MoneyTransfered{
 		FromAccountID: fromAccountID,
 		FromCurrency:  fromCurrency,
@@ -129,13 +339,19 @@ func (a *Transaction) RegisterTransfer(
 		ToAccountID:   toAccountID,
 		ToCurrency:    toCurrency,
 		ToAmount:      toAmount,
+		Rate:          events.ImpliedRate(fromAmount, toAmount),
+		ReferenceRate: referenceRate,
+		Spread:        spread,
 		Category:      category,
 		Description:   description,
 		HappenedAt:    happenedAt,
 	}, nil
 }
 
+// RegisterReimbursement records money received back for the expense of
+// expenseID, or for no expense in particular when it is nil.
 func (a *Transaction) RegisterReimbursement(
+	expenseID uuid.UUID,
 	accountID uuid.UUID,
 	from string,
 	currency values.Currency,
@@ -153,6 +369,7 @@ func (a *Transaction) RegisterReimbursement(
 	}
 
 	return &events.ReimbursementReceived{
+		ExpenseID:   expenseID,
 		AccountID:   accountID,
 		From:        from,
 		Currency:    currency,
@@ -192,3 +409,369 @@ func (a *Transaction) RegisterInvestment(
 		HappenedAt:    happenedAt,
 	}, nil
 }
+
+// RegisterSale sells units of an investment. The transaction does not know
+// what the units sold cost when bought, so their cost basis is left for the
+// dispatcher to set from the position they are taken out of.
+func (a *Transaction) RegisterSale(
+	accountID uuid.UUID,
+	ticker string,
+	units decimal.Decimal,
+	price decimal.Decimal,
+	priceCurrency values.Currency,
+	fee decimal.Decimal,
+	feeCurrency values.Currency,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State > State_Created {
+		return nil, nil
+	}
+
+	if !units.IsPositive() || !price.IsPositive() || fee.IsNegative() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	return &events.InvestmentSold{
+		AccountID:     accountID,
+		Ticker:        ticker,
+		Units:         units,
+		Price:         price,
+		PriceCurrency: priceCurrency,
+		Fee:           fee,
+		FeeCurrency:   feeCurrency,
+		HappenedAt:    happenedAt,
+	}, nil
+}
+
+// RegisterDividend receives a dividend of price per unit held.
+func (a *Transaction) RegisterDividend(
+	accountID uuid.UUID,
+	ticker string,
+	units decimal.Decimal,
+	price decimal.Decimal,
+	priceCurrency values.Currency,
+	fee decimal.Decimal,
+	feeCurrency values.Currency,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State > State_Created {
+		return nil, nil
+	}
+
+	if !units.IsPositive() || !price.IsPositive() || fee.IsNegative() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	return &events.DividendReceived{
+		AccountID:     accountID,
+		Ticker:        ticker,
+		Units:         units,
+		Price:         price,
+		PriceCurrency: priceCurrency,
+		Fee:           fee,
+		FeeCurrency:   feeCurrency,
+		HappenedAt:    happenedAt,
+	}, nil
+}
+
+// RegisterInterest receives interest of price per unit held.
+func (a *Transaction) RegisterInterest(
+	accountID uuid.UUID,
+	ticker string,
+	units decimal.Decimal,
+	price decimal.Decimal,
+	priceCurrency values.Currency,
+	fee decimal.Decimal,
+	feeCurrency values.Currency,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State > State_Created {
+		return nil, nil
+	}
+
+	if !units.IsPositive() || !price.IsPositive() || fee.IsNegative() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	return &events.InterestReceived{
+		AccountID:     accountID,
+		Ticker:        ticker,
+		Units:         units,
+		Price:         price,
+		PriceCurrency: priceCurrency,
+		Fee:           fee,
+		FeeCurrency:   feeCurrency,
+		HappenedAt:    happenedAt,
+	}, nil
+}
+
+func (a *Transaction) RegisterStockSplit(
+	accountID uuid.UUID,
+	ticker string,
+	ratio decimal.Decimal,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State > State_Created {
+		return nil, nil
+	}
+
+	if !ratio.IsPositive() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	return &events.StockSplit{
+		AccountID:  accountID,
+		Ticker:     ticker,
+		Ratio:      ratio,
+		HappenedAt: happenedAt,
+	}, nil
+}
+
+func (a *Transaction) RegisterInvestmentFee(
+	accountID uuid.UUID,
+	ticker string,
+	fee decimal.Decimal,
+	feeCurrency values.Currency,
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State > State_Created {
+		return nil, nil
+	}
+
+	if !fee.IsPositive() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	return &events.InvestmentFeeCharged{
+		AccountID:   accountID,
+		Ticker:      ticker,
+		Fee:         fee,
+		FeeCurrency: feeCurrency,
+		HappenedAt:  happenedAt,
+	}, nil
+}
+
+// Amendment lists the corrections to a recorded transaction, nil fields being
+// left unchanged.
+type Amendment struct {
+	Amount      *decimal.Decimal
+	Category    *string
+	Description *string
+	HappenedAt  *time.Time
+}
+
+// Amend emits the correction events of every field the amendment changes.
+func (a *Transaction) Amend(amendment Amendment) (evts []event_store.Event, err error) {
+	draft := *a
+	draft.Entries = slices.Clone(a.Entries)
+
+	amend := func(evt event_store.Event, err error) error {
+		if err != nil || evt == nil {
+			return err
+		}
+		evts = append(evts, evt)
+		return draft.Hydrate([]event_store.Record{{Event: evt}})
+	}
+
+	if amendment.Amount != nil {
+		if err := amend(draft.ChangeAmount(*amendment.Amount)); err != nil {
+			return nil, err
+		}
+	}
+	if amendment.HappenedAt != nil {
+		if err := amend(draft.Redate(*amendment.HappenedAt)); err != nil {
+			return nil, err
+		}
+	}
+	if amendment.Category != nil {
+		if err := amend(draft.Recategorize(*amendment.Category)); err != nil {
+			return nil, err
+		}
+	}
+	if amendment.Description != nil {
+		if err := amend(draft.Redescribe(*amendment.Description)); err != nil {
+			return nil, err
+		}
+	}
+
+	return evts, nil
+}
+
+func (a *Transaction) ChangeAmount(
+	amount decimal.Decimal,
+) (evt event_store.Event, err error) {
+	if a.State > State_Created {
+		return nil, nil
+	}
+
+	entry, ok := a.amendableEntry()
+	if !ok {
+		return nil, ErrNotAmendable
+	}
+
+	if !amount.IsPositive() {
+		return nil, ErrNegativeOrNullAmount
+	}
+
+	if amount.Equal(entry.Amount) {
+		return nil, nil
+	}
+
+	// An expense cannot cost less than what is expected or received back for
+	// it.
+	if amount.LessThan(decimal.Max(a.Receivable.Expected, a.Receivable.Received())) {
+		return nil, ErrOverReimbursed
+	}
+
+	return &events.AmountChanged{
+		AccountID:      entry.AccountID,
+		Currency:       entry.Currency,
+		Side:           entry.Side,
+		PreviousAmount: entry.Amount,
+		Amount:         amount,
+		HappenedAt:     a.HappenedAt,
+	}, nil
+}
+
+func (a *Transaction) Redate(
+	happenedAt time.Time,
+) (evt event_store.Event, err error) {
+	if a.State > State_Created {
+		return nil, nil
+	}
+
+	entry, ok := a.amendableEntry()
+	if !ok {
+		return nil, ErrNotAmendable
+	}
+
+	if happenedAt.Equal(a.HappenedAt) {
+		return nil, nil
+	}
+
+	return &events.Redated{
+		AccountID:          entry.AccountID,
+		Currency:           entry.Currency,
+		Side:               entry.Side,
+		Amount:             entry.Amount,
+		PreviousHappenedAt: a.HappenedAt,
+		HappenedAt:         happenedAt,
+	}, nil
+}
+
+// Recategorize corrects the category of the transaction. Split expenses are
+// categorized and described by line, so they cannot be amended as a whole.
+func (a *Transaction) Recategorize(
+	category string,
+) (evt event_store.Event, err error) {
+	if a.State > State_Created {
+		return nil, nil
+	}
+
+	if len(a.Entries) == 0 || len(a.Lines) > 0 {
+		return nil, ErrNotAmendable
+	}
+
+	if category == a.Category {
+		return nil, nil
+	}
+
+	return &events.Recategorized{
+		PreviousCategory: a.Category,
+		Category:         category,
+	}, nil
+}
+
+func (a *Transaction) Redescribe(
+	description string,
+) (evt event_store.Event, err error) {
+	if a.State > State_Created {
+		return nil, nil
+	}
+
+	if len(a.Entries) == 0 || len(a.Lines) > 0 {
+		return nil, ErrNotAmendable
+	}
+
+	if description == a.Description {
+		return nil, nil
+	}
+
+	return &events.Redescribed{
+		PreviousDescription: a.Description,
+		Description:         description,
+	}, nil
+}
+
+// Void cancels the transaction, reversing every entry it recorded. The amount
+// still expected back for an expense is no longer expected. Investments and
+// the other investment transactions cannot be voided, since the investment
+// balance and the holdings they feed are not derived from their entries.
+func (a *Transaction) Void() (evts []event_store.Event, err error) {
+	if a.State > State_Created {
+		return nil, nil
+	}
+
+	switch a.Type {
+	case "":
+		return nil, ErrTransactionNotRecorded
+	case values.TransactionType_Investment, values.TransactionType_Sale, values.TransactionType_Dividend,
+		values.TransactionType_Interest, values.TransactionType_StockSplit, values.TransactionType_InvestmentFee:
+		return nil, ErrNotVoidable
+	}
+
+	if outstanding := a.Receivable.Outstanding(); outstanding.IsPositive() {
+		evts = append(evts, &events.ExpectedReimbursementSet{
+			AccountID:           a.Receivable.AccountID,
+			From:                a.Receivable.From,
+			Currency:            a.Entries[0].Currency,
+			Amount:              decimal.Zero,
+			PreviousOutstanding: outstanding,
+			Outstanding:         decimal.Zero,
+			HappenedAt:          a.HappenedAt,
+		})
+	}
+
+	return append(evts, &events.TransactionVoided{
+		TransactionType: a.Type,
+		Entries:         slices.Clone(a.Entries),
+		Category:        a.Category,
+		Description:     a.Description,
+		HappenedAt:      a.HappenedAt,
+	}), nil
+}
+
+// reimbursable checks that the transaction is an expense in currency, which
+// can be reimbursed.
+func (a *Transaction) reimbursable(currency values.Currency) error {
+	switch a.Type {
+	case "":
+		return ErrTransactionNotRecorded
+	case values.TransactionType_Expense:
+	default:
+		return ErrNotReimbursable
+	}
+
+	if a.Entries[0].Currency != currency {
+		return ErrInvalidAmountOrCurrency
+	}
+	return nil
+}
+
+// amendableEntry returns the entry of the transactions whose amount and date
+// can be corrected: expenses, incomes and reimbursements, moving money on a
+// single account. Transfers are recorded on both accounts, split expenses on
+// each of their lines, and investments derive their amount from units and
+// price.
+func (a *Transaction) amendableEntry() (values.Entry, bool) {
+	switch a.Type {
+	case values.TransactionType_Expense, values.TransactionType_Income, values.TransactionType_Reimbursement:
+	default:
+		return values.Entry{}, false
+	}
+
+	if len(a.Entries) != 1 || len(a.Lines) > 0 {
+		return values.Entry{}, false
+	}
+	return a.Entries[0], true
+}
@@ -12,36 +12,73 @@ import (
 	"github.com/somatom98/brokeli/internal/domain/transaction"
 	"github.com/somatom98/brokeli/internal/domain/transaction/events"
 	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
 )
 
 func TestSetExpectedReimbursement(t *testing.T) {
+	accountID := uuid.New()
+
+	expense := func(t *testing.T) *transaction.Transaction {
+		tx := transaction.New(uuid.New())
+		tx.ApplyExpenseCreated(events.MoneySpent{
+			AccountID: accountID,
+			Currency:  values.Currency("USD"),
+			Amount:    decimal.NewFromInt(200),
+		})
+		return tx
+	}
+
 	t.Run("should emit expected reimbursement set event when amount is positive", func(t *testing.T) {
 		// arrange
-		tx := transaction.New(uuid.New())
-		accountID := uuid.New()
+		tx := expense(t)
 		amount := decimal.NewFromInt(100)
 		now := time.Now()
 
 		// act
-		evt, err := tx.SetExpectedReimbursement(accountID, values.Currency("USD"), amount, now)
+		evt, err := tx.SetExpectedReimbursement(accountID, "Alice", values.Currency("USD"), amount, now)
 
 		// assert
 		require.NoError(t, err)
-		assert.Equal(t, &events.ExpectedReimbursementSet{
-			AccountID:  accountID,
-			Currency:   values.Currency("USD"),
-			Amount:     amount,
-			HappenedAt: now,
-		}, evt)
+		set, ok := evt.(*events.ExpectedReimbursementSet)
+		require.True(t, ok)
+		assert.Equal(t, accountID, set.AccountID)
+		assert.Equal(t, "Alice", set.From)
+		assert.Equal(t, values.Currency("USD"), set.Currency)
+		assert.Equal(t, amount, set.Amount)
+		assert.Equal(t, "0", set.PreviousOutstanding.String())
+		assert.Equal(t, "100", set.Outstanding.String())
+		assert.Equal(t, now, set.HappenedAt)
+	})
+
+	t.Run("should deduct the reimbursements received from the outstanding amount", func(t *testing.T) {
+		// arrange
+		tx := expense(t)
+		tx.ApplyExpenseReimbursed(events.ExpenseReimbursed{
+			ReimbursementID: uuid.New(),
+			AccountID:       accountID,
+			From:            "Alice",
+			Amount:          decimal.NewFromInt(30),
+		})
+
+		// act
+		evt, err := tx.SetExpectedReimbursement(accountID, "", values.Currency("USD"), decimal.NewFromInt(100), time.Now())
+
+		// assert
+		require.NoError(t, err)
+		set, ok := evt.(*events.ExpectedReimbursementSet)
+		require.True(t, ok)
+		assert.Equal(t, "Alice", set.From)
+		assert.Equal(t, "0", set.PreviousOutstanding.String())
+		assert.Equal(t, "70", set.Outstanding.String())
 	})
 
 	t.Run("should no-op when transaction is already deleted", func(t *testing.T) {
 		// arrange
-		tx := transaction.New(uuid.New())
+		tx := expense(t)
 		tx.State = transaction.State_Deleted
 
 		// act
-		evt, err := tx.SetExpectedReimbursement(uuid.New(), values.Currency("USD"), decimal.NewFromInt(100), time.Now())
+		evt, err := tx.SetExpectedReimbursement(uuid.New(), "Alice", values.Currency("USD"), decimal.NewFromInt(100), time.Now())
 
 		// assert
 		require.NoError(t, err)
@@ -50,15 +87,189 @@ func TestSetExpectedReimbursement(t *testing.T) {
 
 	t.Run("should return error when amount is not positive", func(t *testing.T) {
 		// arrange
-		tx := transaction.New(uuid.New())
+		tx := expense(t)
 
 		// act
-		evt, err := tx.SetExpectedReimbursement(uuid.New(), values.Currency("USD"), decimal.NewFromInt(0), time.Now())
+		evt, err := tx.SetExpectedReimbursement(uuid.New(), "Alice", values.Currency("USD"), decimal.NewFromInt(0), time.Now())
 
 		// assert
 		require.ErrorIs(t, err, transaction.ErrNegativeOrNullAmount)
 		assert.Nil(t, evt)
 	})
+
+	t.Run("should return error when the transaction is not an expense", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		tx.ApplyIncomeCreated(events.MoneyReceived{
+			AccountID: accountID,
+			Currency:  values.Currency("USD"),
+			Amount:    decimal.NewFromInt(200),
+		})
+
+		// act
+		evt, err := tx.SetExpectedReimbursement(accountID, "Alice", values.Currency("USD"), decimal.NewFromInt(100), time.Now())
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrNotReimbursable)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when the expense was never recorded", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+
+		// act
+		evt, err := tx.SetExpectedReimbursement(accountID, "Alice", values.Currency("USD"), decimal.NewFromInt(100), time.Now())
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrTransactionNotRecorded)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when the currency is not the one of the expense", func(t *testing.T) {
+		// arrange
+		tx := expense(t)
+
+		// act
+		evt, err := tx.SetExpectedReimbursement(accountID, "Alice", values.Currency("EUR"), decimal.NewFromInt(100), time.Now())
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrInvalidAmountOrCurrency)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestReimburse(t *testing.T) {
+	accountID := uuid.New()
+
+	expense := func(t *testing.T) *transaction.Transaction {
+		tx := transaction.New(uuid.New())
+		tx.ApplyExpenseCreated(events.MoneySpent{
+			AccountID: accountID,
+			Currency:  values.Currency("USD"),
+			Amount:    decimal.NewFromInt(200),
+		})
+		tx.ApplyExpectedReimbursementSet(events.ExpectedReimbursementSet{
+			AccountID: accountID,
+			From:      "Alice",
+			Currency:  values.Currency("USD"),
+			Amount:    decimal.NewFromInt(100),
+		})
+		return tx
+	}
+
+	t.Run("should credit the reimbursement to the account expecting it", func(t *testing.T) {
+		// arrange
+		tx := expense(t)
+		reimbursementID := uuid.New()
+
+		// act
+		evt, err := tx.Reimburse(reimbursementID, uuid.New(), "alice", values.Currency("USD"), decimal.NewFromInt(60), time.Now())
+
+		// assert
+		require.NoError(t, err)
+		reimbursed, ok := evt.(*events.ExpenseReimbursed)
+		require.True(t, ok)
+		assert.Equal(t, reimbursementID, reimbursed.ReimbursementID)
+		assert.Equal(t, accountID, reimbursed.AccountID)
+		assert.Equal(t, "Alice", reimbursed.From)
+		assert.Equal(t, "100", reimbursed.PreviousOutstanding.String())
+		assert.Equal(t, "40", reimbursed.Outstanding.String())
+	})
+
+	t.Run("should no-op when the reimbursement is already linked", func(t *testing.T) {
+		// arrange
+		tx := expense(t)
+		reimbursementID := uuid.New()
+		tx.ApplyExpenseReimbursed(events.ExpenseReimbursed{ReimbursementID: reimbursementID, AccountID: accountID, Amount: decimal.NewFromInt(60)})
+
+		// act
+		evt, err := tx.Reimburse(reimbursementID, accountID, "Alice", values.Currency("USD"), decimal.NewFromInt(60), time.Now())
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should make a cancelled reimbursement outstanding again", func(t *testing.T) {
+		// arrange
+		tx := expense(t)
+		reimbursementID := uuid.New()
+		tx.ApplyExpenseReimbursed(events.ExpenseReimbursed{ReimbursementID: reimbursementID, AccountID: accountID, Amount: decimal.NewFromInt(60)})
+
+		// act
+		evt, err := tx.CancelReimbursement(reimbursementID)
+
+		// assert
+		require.NoError(t, err)
+		cancelled, ok := evt.(*events.ReimbursementCancelled)
+		require.True(t, ok)
+		assert.Equal(t, "60", cancelled.Amount.String())
+		assert.Equal(t, "40", cancelled.PreviousOutstanding.String())
+		assert.Equal(t, "100", cancelled.Outstanding.String())
+	})
+
+	t.Run("should correct the amount of a linked reimbursement", func(t *testing.T) {
+		// arrange
+		tx := expense(t)
+		reimbursementID := uuid.New()
+		tx.ApplyExpenseReimbursed(events.ExpenseReimbursed{ReimbursementID: reimbursementID, AccountID: accountID, Amount: decimal.NewFromInt(60)})
+
+		// act
+		evt, err := tx.AmendReimbursement(reimbursementID, decimal.NewFromInt(80), time.Now())
+
+		// assert
+		require.NoError(t, err)
+		amended, ok := evt.(*events.ReimbursementAmended)
+		require.True(t, ok)
+		assert.Equal(t, "60", amended.PreviousAmount.String())
+		assert.Equal(t, "80", amended.Amount.String())
+		assert.Equal(t, "40", amended.PreviousOutstanding.String())
+		assert.Equal(t, "20", amended.Outstanding.String())
+	})
+
+	t.Run("should return error when the reimbursements exceed the amount expected", func(t *testing.T) {
+		// arrange
+		tx := expense(t)
+		reimbursementID := uuid.New()
+		tx.ApplyExpenseReimbursed(events.ExpenseReimbursed{ReimbursementID: reimbursementID, AccountID: accountID, Amount: decimal.NewFromInt(60)})
+
+		// act
+		evt, err := tx.AmendReimbursement(reimbursementID, decimal.NewFromInt(120), time.Now())
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrOverReimbursed)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should no-op when the reimbursement is not linked", func(t *testing.T) {
+		// arrange
+		tx := expense(t)
+
+		// act
+		evt, err := tx.AmendReimbursement(uuid.New(), decimal.NewFromInt(80), time.Now())
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when the transaction is not an expense", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		tx.ApplyReimbursementReceived(events.ReimbursementReceived{
+			AccountID: accountID,
+			Currency:  values.Currency("USD"),
+			Amount:    decimal.NewFromInt(60),
+		})
+
+		// act
+		evt, err := tx.Reimburse(uuid.New(), accountID, "Alice", values.Currency("USD"), decimal.NewFromInt(60), time.Now())
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrNotReimbursable)
+		assert.Nil(t, evt)
+	})
 }
 
 func TestRegisterExpense(t *testing.T) {
@@ -110,6 +321,102 @@ func TestRegisterExpense(t *testing.T) {
 	})
 }
 
+func TestRegisterSplitExpense(t *testing.T) {
+	accountID := uuid.New()
+	cardID := uuid.New()
+
+	t.Run("should emit expense split event spending lines without account from the expense one", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		now := time.Now()
+		lines := []values.SplitLine{
+			{Category: "Groceries", Amount: decimal.NewFromInt(30)},
+			{AccountID: cardID, Category: "Household", Amount: decimal.NewFromInt(20), Description: "detergent"},
+		}
+
+		// act
+		evt, err := tx.RegisterSplitExpense(accountID, values.Currency("EUR"), decimal.NewFromInt(50), lines, "supermarket", now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.ExpenseSplit{
+			AccountID: accountID,
+			Currency:  values.Currency("EUR"),
+			Amount:    decimal.NewFromInt(50),
+			Lines: []values.SplitLine{
+				{AccountID: accountID, Category: "Groceries", Amount: decimal.NewFromInt(30)},
+				{AccountID: cardID, Category: "Household", Amount: decimal.NewFromInt(20), Description: "detergent"},
+			},
+			Description: "supermarket",
+			HappenedAt:  now,
+		}, evt)
+	})
+
+	t.Run("should return error when lines do not sum to the amount", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		lines := []values.SplitLine{
+			{Category: "Groceries", Amount: decimal.NewFromInt(30)},
+			{Category: "Household", Amount: decimal.NewFromInt(10)},
+		}
+
+		// act
+		evt, err := tx.RegisterSplitExpense(accountID, values.Currency("EUR"), decimal.NewFromInt(50), lines, "supermarket", time.Now())
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrInvalidSplit)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when there are no lines", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+
+		// act
+		evt, err := tx.RegisterSplitExpense(accountID, values.Currency("EUR"), decimal.NewFromInt(50), nil, "supermarket", time.Now())
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrInvalidSplit)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when a line amount is not positive", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		lines := []values.SplitLine{
+			{Category: "Groceries", Amount: decimal.NewFromInt(60)},
+			{Category: "Household", Amount: decimal.NewFromInt(-10)},
+		}
+
+		// act
+		evt, err := tx.RegisterSplitExpense(accountID, values.Currency("EUR"), decimal.NewFromInt(50), lines, "supermarket", time.Now())
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrNegativeOrNullAmount)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when recategorizing the expense as a whole", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		tx.ApplyExpenseSplit(events.ExpenseSplit{
+			AccountID: accountID,
+			Currency:  values.Currency("EUR"),
+			Amount:    decimal.NewFromInt(50),
+			Lines: []values.SplitLine{
+				{AccountID: accountID, Category: "Groceries", Amount: decimal.NewFromInt(50)},
+			},
+		})
+
+		// act
+		evt, err := tx.Recategorize("Household")
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrNotAmendable)
+		assert.Nil(t, evt)
+	})
+}
+
 func TestRegisterIncome(t *testing.T) {
 	t.Run("should emit money received event when amount is positive", func(t *testing.T) {
 		// arrange
@@ -175,6 +482,7 @@ func TestRegisterTransfer(t *testing.T) {
 			toAccountID,
 			values.Currency("EUR"),
 			decimal.NewFromInt(90),
+			decimal.Decimal{},
 			"transfer",
 			"currency exchange",
 			now,
@@ -182,6 +490,9 @@ func TestRegisterTransfer(t *testing.T) {
 
 		// assert
 		require.NoError(t, err)
+		transfer, ok := evt.(*events.MoneyTransfered)
+		require.True(t, ok)
+		assert.Equal(t, "0.9", transfer.Rate.String())
 		assert.Equal(t, &events.MoneyTransfered{
 			FromAccountID: fromAccountID,
 			FromCurrency:  values.Currency("USD"),
@@ -189,6 +500,7 @@ func TestRegisterTransfer(t *testing.T) {
 			ToAccountID:   toAccountID,
 			ToCurrency:    values.Currency("EUR"),
 			ToAmount:      decimal.NewFromInt(90),
+			Rate:          transfer.Rate,
 			Category:      "transfer",
 			Description:   "currency exchange",
 			HappenedAt:    now,
@@ -208,6 +520,7 @@ func TestRegisterTransfer(t *testing.T) {
 			uuid.New(),
 			values.Currency("EUR"),
 			decimal.NewFromInt(90),
+			decimal.Decimal{},
 			"transfer",
 			"currency exchange",
 			time.Now(),
@@ -230,6 +543,7 @@ func TestRegisterTransfer(t *testing.T) {
 			uuid.New(),
 			values.Currency("EUR"),
 			decimal.NewFromInt(90),
+			decimal.Decimal{},
 			"transfer",
 			"currency exchange",
 			time.Now(),
@@ -253,6 +567,7 @@ func TestRegisterTransfer(t *testing.T) {
 			accountID,
 			values.Currency("EUR"),
 			decimal.NewFromInt(90),
+			decimal.Decimal{},
 			"transfer",
 			"currency exchange",
 			time.Now(),
@@ -275,6 +590,7 @@ func TestRegisterTransfer(t *testing.T) {
 			accountID,
 			values.Currency("USD"),
 			decimal.NewFromInt(90),
+			decimal.Decimal{},
 			"transfer",
 			"",
 			time.Now(),
@@ -297,6 +613,7 @@ func TestRegisterTransfer(t *testing.T) {
 			uuid.New(),
 			values.Currency("USD"),
 			decimal.NewFromInt(50),
+			decimal.Decimal{},
 			"transfer",
 			"currency exchange",
 			time.Now(),
@@ -306,22 +623,103 @@ func TestRegisterTransfer(t *testing.T) {
 		require.ErrorIs(t, err, transaction.ErrInvalidAmountOrCurrency)
 		assert.Nil(t, evt)
 	})
+
+	t.Run("should record the spread paid against the reference rate", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+
+		// act
+		evt, err := tx.RegisterTransfer(
+			uuid.New(),
+			values.Currency("USD"),
+			decimal.NewFromInt(100),
+			uuid.New(),
+			values.Currency("EUR"),
+			decimal.NewFromInt(90),
+			decimal.RequireFromString("0.92"),
+			"transfer",
+			"currency exchange",
+			time.Now(),
+		)
+
+		// assert
+		require.NoError(t, err)
+		transfer, ok := evt.(*events.MoneyTransfered)
+		require.True(t, ok)
+		assert.Equal(t, "0.9", transfer.Rate.String())
+		assert.Equal(t, "0.92", transfer.ReferenceRate.String())
+		assert.Equal(t, "2.17", transfer.Spread.String())
+	})
+
+	t.Run("should round the spread to the minor units of the source currency", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+
+		// act
+		evt, err := tx.RegisterTransfer(
+			uuid.New(),
+			values.Currency("JPY"),
+			decimal.NewFromInt(10000),
+			uuid.New(),
+			values.Currency("KWD"),
+			decimal.RequireFromString("19.8"),
+			decimal.RequireFromString("0.00203"),
+			"transfer",
+			"currency exchange",
+			time.Now(),
+		)
+
+		// assert
+		require.NoError(t, err)
+		transfer, ok := evt.(*events.MoneyTransfered)
+		require.True(t, ok)
+		assert.Equal(t, "246", transfer.Spread.String())
+	})
+
+	t.Run("should not record a spread when currencies match", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+
+		// act
+		evt, err := tx.RegisterTransfer(
+			uuid.New(),
+			values.Currency("USD"),
+			decimal.NewFromInt(100),
+			uuid.New(),
+			values.Currency("USD"),
+			decimal.NewFromInt(100),
+			decimal.RequireFromString("1.1"),
+			"transfer",
+			"",
+			time.Now(),
+		)
+
+		// assert
+		require.NoError(t, err)
+		transfer, ok := evt.(*events.MoneyTransfered)
+		require.True(t, ok)
+		assert.Equal(t, "1", transfer.Rate.String())
+		assert.True(t, transfer.ReferenceRate.IsZero())
+		assert.True(t, transfer.Spread.IsZero())
+	})
 }
 
 func TestRegisterReimbursement(t *testing.T) {
 	t.Run("should emit reimbursement received event when amount is positive", func(t *testing.T) {
 		// arrange
 		tx := transaction.New(uuid.New())
+		expenseID := uuid.New()
 		accountID := uuid.New()
 		amount := decimal.NewFromInt(75)
 		now := time.Now()
 
 		// act
-		evt, err := tx.RegisterReimbursement(accountID, "company", values.Currency("USD"), amount, "Work", "Lunch reimbursement", now)
+		evt, err := tx.RegisterReimbursement(expenseID, accountID, "company", values.Currency("USD"), amount, "Work", "Lunch reimbursement", now)
 
 		// assert
 		require.NoError(t, err)
 		assert.Equal(t, &events.ReimbursementReceived{
+			ExpenseID:   expenseID,
 			AccountID:   accountID,
 			From:        "company",
 			Currency:    values.Currency("USD"),
@@ -338,7 +736,7 @@ func TestRegisterReimbursement(t *testing.T) {
 		tx.State = transaction.State_Deleted
 
 		// act
-		evt, err := tx.RegisterReimbursement(uuid.New(), "company", values.Currency("USD"), decimal.NewFromInt(75), "", "", time.Now())
+		evt, err := tx.RegisterReimbursement(uuid.Nil, uuid.New(), "company", values.Currency("USD"), decimal.NewFromInt(75), "", "", time.Now())
 
 		// assert
 		require.NoError(t, err)
@@ -350,7 +748,7 @@ func TestRegisterReimbursement(t *testing.T) {
 		tx := transaction.New(uuid.New())
 
 		// act
-		evt, err := tx.RegisterReimbursement(uuid.New(), "company", values.Currency("USD"), decimal.NewFromInt(0), "", "", time.Now())
+		evt, err := tx.RegisterReimbursement(uuid.Nil, uuid.New(), "company", values.Currency("USD"), decimal.NewFromInt(0), "", "", time.Now())
 
 		// assert
 		require.ErrorIs(t, err, transaction.ErrNegativeOrNullAmount)
@@ -423,3 +821,496 @@ func TestRegisterInvestment(t *testing.T) {
 		assert.Nil(t, evt)
 	})
 }
+
+func TestRegisterSale(t *testing.T) {
+	t.Run("should emit investment sold event leaving the cost basis to the position", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		accountID := uuid.New()
+		units := decimal.NewFromInt(4)
+		price := decimal.NewFromInt(120)
+		fee := decimal.NewFromInt(2)
+		now := time.Now()
+
+		// act
+		evt, err := tx.RegisterSale(accountID, "AAPL", units, price, values.Currency("USD"), fee, values.Currency("USD"), now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.InvestmentSold{
+			AccountID:     accountID,
+			Ticker:        "AAPL",
+			Units:         units,
+			Price:         price,
+			PriceCurrency: values.Currency("USD"),
+			Fee:           fee,
+			FeeCurrency:   values.Currency("USD"),
+			HappenedAt:    now,
+		}, evt)
+	})
+
+	t.Run("should return error when units is not positive", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+
+		// act
+		evt, err := tx.RegisterSale(uuid.New(), "AAPL", decimal.Zero, decimal.NewFromInt(120), values.Currency("USD"), decimal.Zero, values.Currency("USD"), time.Now())
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrNegativeOrNullAmount)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestRegisterDividend(t *testing.T) {
+	t.Run("should emit dividend received event when units and price are positive", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		accountID := uuid.New()
+		now := time.Now()
+
+		// act
+		evt, err := tx.RegisterDividend(accountID, "VWCE", decimal.NewFromInt(10), decimal.NewFromFloat(0.5), values.Currency("EUR"), decimal.NewFromInt(1), values.Currency("EUR"), now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.DividendReceived{
+			AccountID:     accountID,
+			Ticker:        "VWCE",
+			Units:         decimal.NewFromInt(10),
+			Price:         decimal.NewFromFloat(0.5),
+			PriceCurrency: values.Currency("EUR"),
+			Fee:           decimal.NewFromInt(1),
+			FeeCurrency:   values.Currency("EUR"),
+			HappenedAt:    now,
+		}, evt)
+	})
+
+	t.Run("should return error when fee is negative", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+
+		// act
+		evt, err := tx.RegisterDividend(uuid.New(), "VWCE", decimal.NewFromInt(10), decimal.NewFromFloat(0.5), values.Currency("EUR"), decimal.NewFromInt(-1), values.Currency("EUR"), time.Now())
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrNegativeOrNullAmount)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestRegisterInterest(t *testing.T) {
+	t.Run("should emit interest received event when units and price are positive", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		accountID := uuid.New()
+		now := time.Now()
+
+		// act
+		evt, err := tx.RegisterInterest(accountID, "BTP", decimal.NewFromInt(1000), decimal.NewFromFloat(0.02), values.Currency("EUR"), decimal.Zero, values.Currency("EUR"), now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.InterestReceived{
+			AccountID:     accountID,
+			Ticker:        "BTP",
+			Units:         decimal.NewFromInt(1000),
+			Price:         decimal.NewFromFloat(0.02),
+			PriceCurrency: values.Currency("EUR"),
+			Fee:           decimal.Zero,
+			FeeCurrency:   values.Currency("EUR"),
+			HappenedAt:    now,
+		}, evt)
+	})
+
+	t.Run("should return error when price is not positive", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+
+		// act
+		evt, err := tx.RegisterInterest(uuid.New(), "BTP", decimal.NewFromInt(1000), decimal.Zero, values.Currency("EUR"), decimal.Zero, values.Currency("EUR"), time.Now())
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrNegativeOrNullAmount)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestRegisterStockSplit(t *testing.T) {
+	t.Run("should emit stock split event when ratio is positive", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		accountID := uuid.New()
+		now := time.Now()
+
+		// act
+		evt, err := tx.RegisterStockSplit(accountID, "NVDA", decimal.NewFromInt(10), now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.StockSplit{
+			AccountID:  accountID,
+			Ticker:     "NVDA",
+			Ratio:      decimal.NewFromInt(10),
+			HappenedAt: now,
+		}, evt)
+	})
+
+	t.Run("should return error when ratio is not positive", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+
+		// act
+		evt, err := tx.RegisterStockSplit(uuid.New(), "NVDA", decimal.Zero, time.Now())
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrNegativeOrNullAmount)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestRegisterInvestmentFee(t *testing.T) {
+	t.Run("should emit investment fee charged event when fee is positive", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		accountID := uuid.New()
+		now := time.Now()
+
+		// act
+		evt, err := tx.RegisterInvestmentFee(accountID, "VWCE", decimal.NewFromInt(3), values.Currency("EUR"), now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.InvestmentFeeCharged{
+			AccountID:   accountID,
+			Ticker:      "VWCE",
+			Fee:         decimal.NewFromInt(3),
+			FeeCurrency: values.Currency("EUR"),
+			HappenedAt:  now,
+		}, evt)
+	})
+
+	t.Run("should return error when fee is not positive", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+
+		// act
+		evt, err := tx.RegisterInvestmentFee(uuid.New(), "VWCE", decimal.Zero, values.Currency("EUR"), time.Now())
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrNegativeOrNullAmount)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestAmend(t *testing.T) {
+	accountID := uuid.New()
+	happenedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	expense := func(t *testing.T) *transaction.Transaction {
+		tx := transaction.New(uuid.New())
+		tx.ApplyExpenseCreated(events.MoneySpent{
+			AccountID:   accountID,
+			Currency:    values.Currency("EUR"),
+			Amount:      decimal.NewFromInt(40),
+			Category:    "Groceries",
+			Description: "Weekly shopping",
+			HappenedAt:  happenedAt,
+		})
+		return tx
+	}
+
+	t.Run("should return error when the expense would cost less than what is expected back", func(t *testing.T) {
+		// arrange
+		tx := expense(t)
+		tx.ApplyExpectedReimbursementSet(events.ExpectedReimbursementSet{
+			AccountID: accountID,
+			From:      "Alice",
+			Currency:  values.Currency("EUR"),
+			Amount:    decimal.NewFromInt(30),
+		})
+		amount := decimal.NewFromInt(25)
+
+		// act
+		evts, err := tx.Amend(transaction.Amendment{Amount: &amount})
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrOverReimbursed)
+		assert.Empty(t, evts)
+	})
+
+	t.Run("should emit a correction event for every changed field", func(t *testing.T) {
+		// arrange
+		tx := expense(t)
+		amount := decimal.NewFromInt(45)
+		redatedAt := happenedAt.AddDate(0, 0, -1)
+		category := "Household"
+		description := "Weekly shopping"
+
+		// act
+		evts, err := tx.Amend(transaction.Amendment{
+			Amount:      &amount,
+			HappenedAt:  &redatedAt,
+			Category:    &category,
+			Description: &description,
+		})
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, []event_store.Event{
+			&events.AmountChanged{
+				AccountID:      accountID,
+				Currency:       values.Currency("EUR"),
+				Side:           values.Side_Debit,
+				PreviousAmount: decimal.NewFromInt(40),
+				Amount:         amount,
+				HappenedAt:     happenedAt,
+			},
+			&events.Redated{
+				AccountID:          accountID,
+				Currency:           values.Currency("EUR"),
+				Side:               values.Side_Debit,
+				Amount:             amount,
+				PreviousHappenedAt: happenedAt,
+				HappenedAt:         redatedAt,
+			},
+			&events.Recategorized{
+				PreviousCategory: "Groceries",
+				Category:         category,
+			},
+		}, evts)
+	})
+
+	t.Run("should leave the transaction unchanged", func(t *testing.T) {
+		// arrange
+		tx := expense(t)
+		amount := decimal.NewFromInt(45)
+
+		// act
+		_, err := tx.Amend(transaction.Amendment{Amount: &amount})
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "40", tx.Entries[0].Amount.String())
+	})
+
+	t.Run("should return error when the amount is not positive", func(t *testing.T) {
+		// arrange
+		tx := expense(t)
+
+		// act
+		evt, err := tx.ChangeAmount(decimal.Zero)
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrNegativeOrNullAmount)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should return error when changing the amount of a transfer", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		tx.ApplyTransferCreated(events.MoneyTransfered{
+			FromAccountID: accountID,
+			FromCurrency:  values.Currency("EUR"),
+			FromAmount:    decimal.NewFromInt(100),
+			ToAccountID:   uuid.New(),
+			ToCurrency:    values.Currency("EUR"),
+			ToAmount:      decimal.NewFromInt(100),
+			HappenedAt:    happenedAt,
+		})
+
+		// act
+		evt, err := tx.ChangeAmount(decimal.NewFromInt(50))
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrNotAmendable)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should recategorize a transfer", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		tx.ApplyTransferCreated(events.MoneyTransfered{
+			FromAccountID: accountID,
+			FromCurrency:  values.Currency("EUR"),
+			FromAmount:    decimal.NewFromInt(100),
+			ToAccountID:   uuid.New(),
+			ToCurrency:    values.Currency("EUR"),
+			ToAmount:      decimal.NewFromInt(100),
+			Category:      "Transfer",
+			HappenedAt:    happenedAt,
+		})
+
+		// act
+		evt, err := tx.Recategorize("Savings")
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, &events.Recategorized{PreviousCategory: "Transfer", Category: "Savings"}, evt)
+	})
+
+	t.Run("should return error when the transaction was never recorded", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+
+		// act
+		evt, err := tx.Redescribe("Weekly shopping")
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrNotAmendable)
+		assert.Nil(t, evt)
+	})
+
+	t.Run("should no-op when transaction is already deleted", func(t *testing.T) {
+		// arrange
+		tx := expense(t)
+		tx.State = transaction.State_Deleted
+
+		// act
+		evt, err := tx.Redate(happenedAt.AddDate(0, 0, 1))
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evt)
+	})
+}
+
+func TestVoid(t *testing.T) {
+	accountID := uuid.New()
+	happenedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("should emit transaction voided event with the entries to reverse", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		tx.ApplyExpenseCreated(events.MoneySpent{
+			AccountID:   accountID,
+			Currency:    values.Currency("EUR"),
+			Amount:      decimal.NewFromInt(40),
+			Category:    "Groceries",
+			Description: "Weekly shopping",
+			HappenedAt:  happenedAt,
+		})
+
+		// act
+		evts, err := tx.Void()
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, []event_store.Event{&events.TransactionVoided{
+			TransactionType: values.TransactionType_Expense,
+			Entries: []values.Entry{
+				{
+					AccountID: accountID,
+					Currency:  values.Currency("EUR"),
+					Amount:    decimal.NewFromInt(40),
+					Side:      values.Side_Debit,
+				},
+			},
+			Category:    "Groceries",
+			Description: "Weekly shopping",
+			HappenedAt:  happenedAt,
+		}}, evts)
+	})
+
+	t.Run("should no longer expect the outstanding reimbursement of an expense", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		tx.ApplyExpenseCreated(events.MoneySpent{
+			AccountID: accountID,
+			Currency:  values.Currency("EUR"),
+			Amount:    decimal.NewFromInt(40),
+		})
+		tx.ApplyExpectedReimbursementSet(events.ExpectedReimbursementSet{
+			AccountID: accountID,
+			From:      "Alice",
+			Currency:  values.Currency("EUR"),
+			Amount:    decimal.NewFromInt(20),
+		})
+
+		// act
+		evts, err := tx.Void()
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, evts, 2)
+		set, ok := evts[0].(*events.ExpectedReimbursementSet)
+		require.True(t, ok)
+		assert.Equal(t, "20", set.PreviousOutstanding.String())
+		assert.True(t, set.Outstanding.IsZero())
+		assert.IsType(t, &events.TransactionVoided{}, evts[1])
+	})
+
+	t.Run("should return error when voiding an investment", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		tx.ApplyInvestmentCreated(events.MoneyInvested{
+			AccountID:     accountID,
+			Ticker:        "VWCE",
+			Units:         decimal.NewFromInt(3),
+			Price:         decimal.NewFromInt(100),
+			PriceCurrency: values.Currency("EUR"),
+			FeeCurrency:   values.Currency("EUR"),
+			HappenedAt:    happenedAt,
+		})
+
+		// act
+		evts, err := tx.Void()
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrNotVoidable)
+		assert.Nil(t, evts)
+	})
+
+	t.Run("should return error when voiding a sale", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		tx.ApplyInvestmentSold(events.InvestmentSold{
+			AccountID:     accountID,
+			Ticker:        "VWCE",
+			Units:         decimal.NewFromInt(1),
+			Price:         decimal.NewFromInt(110),
+			PriceCurrency: values.Currency("EUR"),
+			FeeCurrency:   values.Currency("EUR"),
+			CostBasis:     decimal.NewFromInt(100),
+			HappenedAt:    happenedAt,
+		})
+
+		// act
+		evts, err := tx.Void()
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrNotVoidable)
+		assert.Nil(t, evts)
+	})
+
+	t.Run("should return error when the transaction was never recorded", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+
+		// act
+		evts, err := tx.Void()
+
+		// assert
+		require.ErrorIs(t, err, transaction.ErrTransactionNotRecorded)
+		assert.Nil(t, evts)
+	})
+
+	t.Run("should no-op when transaction is already deleted", func(t *testing.T) {
+		// arrange
+		tx := transaction.New(uuid.New())
+		tx.ApplyIncomeCreated(events.MoneyReceived{
+			AccountID:  accountID,
+			Currency:   values.Currency("EUR"),
+			Amount:     decimal.NewFromInt(40),
+			HappenedAt: happenedAt,
+		})
+		tx.ApplyTransactionVoided(events.TransactionVoided{})
+
+		// act
+		evts, err := tx.Void()
+
+		// assert
+		require.NoError(t, err)
+		assert.Nil(t, evts)
+	})
+}
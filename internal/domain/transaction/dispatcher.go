@@ -2,24 +2,78 @@ package transaction
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/fx"
+	"github.com/somatom98/brokeli/internal/domain/position"
+	position_events "github.com/somatom98/brokeli/internal/domain/position/events"
+	"github.com/somatom98/brokeli/internal/domain/transaction/events"
 	"github.com/somatom98/brokeli/internal/domain/values"
 	"github.com/somatom98/brokeli/pkg/event_store"
 )
 
+// userSystem is the user the account movements of a transfer are made by.
+const userSystem = "system"
+
+// categoryClosing is the category of the transfers closing an account.
+const categoryClosing = "Account closing"
+
+// ReferenceRates returns what one unit of a currency is worth in another on a
+// date, or fx.ErrNotFound when it is not known.
+type ReferenceRates interface {
+	Rate(ctx context.Context, from, to values.Currency, on time.Time) (decimal.Decimal, error)
+}
+
 type Dispatcher struct {
-	es event_store.Store[*Transaction]
+	es              event_store.Store[*Transaction]
+	accounts        event_store.Store[*account.Account]
+	positions       event_store.Store[*position.Position]
+	uow             event_store.UnitOfWork
+	referenceRates  ReferenceRates
+	costBasisMethod position.CostBasisMethod
+}
+
+type DispatcherOption func(*Dispatcher)
+
+// WithReferenceRates makes the transfers across currencies record the spread
+// paid on them compared to the reference rates.
+func WithReferenceRates(referenceRates ReferenceRates) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.referenceRates = referenceRates
+	}
+}
+
+// WithCostBasisMethod sets how the units taken out of a position by a sale
+// are costed, FIFO by default.
+func WithCostBasisMethod(method position.CostBasisMethod) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.costBasisMethod = method
+	}
 }
 
 func NewDispatcher(
 	es event_store.Store[*Transaction],
+	accounts event_store.Store[*account.Account],
+	positions event_store.Store[*position.Position],
+	uow event_store.UnitOfWork,
+	opts ...DispatcherOption,
 ) *Dispatcher {
-	return &Dispatcher{
-		es: es,
+	d := &Dispatcher{
+		es:              es,
+		accounts:        accounts,
+		positions:       positions,
+		uow:             uow,
+		costBasisMethod: position.CostBasisMethod_FIFO,
+	}
+	for _, opt := range opts {
+		opt(d)
 	}
+	return d
 }
 
 func (d *Dispatcher) RegisterExpense(
@@ -32,11 +86,38 @@ func (d *Dispatcher) RegisterExpense(
 	description string,
 	happenedAt time.Time,
 ) error {
-	return d.es.Execute(ctx, id, func(aggr *Transaction, version uint64) (event_store.Event, error) {
+	if err := d.postable(ctx, accountID); err != nil {
+		return err
+	}
+
+	return d.record(ctx, id, func(aggr *Transaction) (event_store.Event, error) {
 		return aggr.RegisterExpense(accountID, currency, amount, category, description, happenedAt)
 	})
 }
 
+func (d *Dispatcher) RegisterSplitExpense(
+	ctx context.Context,
+	id uuid.UUID,
+	accountID uuid.UUID,
+	currency values.Currency,
+	amount decimal.Decimal,
+	lines []values.SplitLine,
+	description string,
+	happenedAt time.Time,
+) error {
+	accountIDs := []uuid.UUID{accountID}
+	for _, line := range lines {
+		accountIDs = append(accountIDs, line.AccountID)
+	}
+	if err := d.postable(ctx, accountIDs...); err != nil {
+		return err
+	}
+
+	return d.record(ctx, id, func(aggr *Transaction) (event_store.Event, error) {
+		return aggr.RegisterSplitExpense(accountID, currency, amount, lines, description, happenedAt)
+	})
+}
+
 func (d *Dispatcher) RegisterIncome(
 	ctx context.Context,
 	id uuid.UUID,
@@ -47,7 +128,11 @@ func (d *Dispatcher) RegisterIncome(
 	description string,
 	happenedAt time.Time,
 ) error {
-	return d.es.Execute(ctx, id, func(aggr *Transaction, version uint64) (event_store.Event, error) {
+	if err := d.postable(ctx, accountID); err != nil {
+		return err
+	}
+
+	return d.record(ctx, id, func(aggr *Transaction) (event_store.Event, error) {
 		return aggr.RegisterIncome(accountID, currency, amount, category, description, happenedAt)
 	})
 }
@@ -65,8 +150,48 @@ func (d *Dispatcher) RegisterTransfer(
 	description string,
 	happenedAt time.Time,
 ) error {
-	return d.es.Execute(ctx, id, func(aggr *Transaction, version uint64) (event_store.Event, error) {
-		return aggr.RegisterTransfer(fromAccountID, fromCurrency, fromAmount, toAccountID, toCurrency, toAmount, category, description, happenedAt)
+	referenceRate, err := d.referenceRate(ctx, fromCurrency, toCurrency, happenedAt)
+	if err != nil {
+		return err
+	}
+
+	transferID := uuid.New()
+	metadata := event_store.MetadataFrom(ctx)
+	if metadata.CorrelationID == uuid.Nil {
+		metadata.CorrelationID = transferID
+	}
+	ctx = event_store.WithMetadata(ctx, metadata)
+
+	// The transfer and the movements it makes on both accounts are committed
+	// together, so that a transfer never half-applies.
+	return d.uow.Do(ctx, func(ctx context.Context) error {
+		var transfer *events.MoneyTransfered
+		err := d.es.Execute(ctx, id, func(aggr *Transaction, version uint64) ([]event_store.Event, error) {
+			e, err := aggr.RegisterTransfer(fromAccountID, fromCurrency, fromAmount, toAccountID, toCurrency, toAmount, referenceRate, category, description, happenedAt)
+			transfer, _ = e.(*events.MoneyTransfered)
+			return event_store.One(event_store.WithID(transferID, e), err)
+		})
+		if err != nil || transfer == nil {
+			return err
+		}
+
+		ctx = event_store.CausedBy(ctx, event_store.Record{ID: transferID, Metadata: metadata})
+
+		err = d.accounts.Execute(ctx, transfer.FromAccountID, func(aggr *account.Account, version uint64) ([]event_store.Event, error) {
+			return event_store.One(aggr.Withdraw(transfer.FromCurrency, transfer.FromAmount, transfer.Category, transfer.Description, userSystem, transfer.HappenedAt))
+		})
+		if err != nil {
+			return fmt.Errorf("failed to withdraw transfer: %w", err)
+		}
+
+		err = d.accounts.Execute(ctx, transfer.ToAccountID, func(aggr *account.Account, version uint64) ([]event_store.Event, error) {
+			return event_store.One(aggr.Deposit(transfer.ToCurrency, transfer.ToAmount, transfer.Category, transfer.Description, userSystem, transfer.HappenedAt))
+		})
+		if err != nil {
+			return fmt.Errorf("failed to deposit transfer: %w", err)
+		}
+
+		return nil
 	})
 }
 
@@ -74,18 +199,26 @@ func (d *Dispatcher) SetExpectedReimbursement(
 	ctx context.Context,
 	id uuid.UUID,
 	accountID uuid.UUID,
+	from string,
 	currency values.Currency,
 	amount decimal.Decimal,
 	happenedAt time.Time,
 ) error {
-	return d.es.Execute(ctx, id, func(aggr *Transaction, version uint64) (event_store.Event, error) {
-		return aggr.SetExpectedReimbursement(accountID, currency, amount, happenedAt)
+	if err := d.postable(ctx, accountID); err != nil {
+		return err
+	}
+
+	return d.es.Execute(ctx, id, func(aggr *Transaction, version uint64) ([]event_store.Event, error) {
+		return event_store.One(aggr.SetExpectedReimbursement(accountID, from, currency, amount, happenedAt))
 	})
 }
 
+// RegisterReimbursement records a reimbursement and, unless expenseID is nil,
+// links it to the expense in the same unit of work.
 func (d *Dispatcher) RegisterReimbursement(
 	ctx context.Context,
 	id uuid.UUID,
+	expenseID uuid.UUID,
 	accountID uuid.UUID,
 	from string,
 	currency values.Currency,
@@ -94,8 +227,48 @@ func (d *Dispatcher) RegisterReimbursement(
 	description string,
 	happenedAt time.Time,
 ) error {
-	return d.es.Execute(ctx, id, func(aggr *Transaction, version uint64) (event_store.Event, error) {
-		return aggr.RegisterReimbursement(accountID, from, currency, amount, category, description, happenedAt)
+	if err := d.postable(ctx, accountID); err != nil {
+		return err
+	}
+
+	if expenseID == uuid.Nil {
+		return d.record(ctx, id, func(aggr *Transaction) (event_store.Event, error) {
+			return aggr.RegisterReimbursement(uuid.Nil, accountID, from, currency, amount, category, description, happenedAt)
+		})
+	}
+
+	reimbursementID := uuid.New()
+	metadata := event_store.MetadataFrom(ctx)
+	if metadata.CorrelationID == uuid.Nil {
+		metadata.CorrelationID = reimbursementID
+	}
+	ctx = event_store.WithMetadata(ctx, metadata)
+
+	return d.uow.Do(ctx, func(ctx context.Context) error {
+		var reimbursement *events.ReimbursementReceived
+		var postings []values.Entry
+		err := d.es.Execute(ctx, id, func(aggr *Transaction, version uint64) ([]event_store.Event, error) {
+			e, err := aggr.RegisterReimbursement(expenseID, accountID, from, currency, amount, category, description, happenedAt)
+			if err != nil {
+				return nil, err
+			}
+			reimbursement, _ = e.(*events.ReimbursementReceived)
+			postings, _, err = aggr.postings([]event_store.Event{e})
+			return event_store.One(event_store.WithID(reimbursementID, e), err)
+		})
+		if err != nil || reimbursement == nil {
+			return err
+		}
+
+		ctx = event_store.CausedBy(ctx, event_store.Record{ID: reimbursementID, Metadata: metadata})
+
+		if err := d.post(ctx, id, postings, reimbursement.HappenedAt); err != nil {
+			return err
+		}
+
+		return d.es.Execute(ctx, expenseID, func(aggr *Transaction, version uint64) ([]event_store.Event, error) {
+			return event_store.One(aggr.Reimburse(id, reimbursement.AccountID, reimbursement.From, reimbursement.Currency, reimbursement.Amount, reimbursement.HappenedAt))
+		})
 	})
 }
 
@@ -111,7 +284,425 @@ func (d *Dispatcher) RegisterInvestment(
 	feeCurrency values.Currency,
 	happenedAt time.Time,
 ) error {
-	return d.es.Execute(ctx, id, func(aggr *Transaction, version uint64) (event_store.Event, error) {
+	if err := d.postable(ctx, accountID); err != nil {
+		return err
+	}
+
+	positionID := position.ID(accountID, ticker)
+	return d.record(ctx, id, func(aggr *Transaction) (event_store.Event, error) {
 		return aggr.RegisterInvestment(accountID, ticker, units, price, priceCurrency, fee, feeCurrency, happenedAt)
+	}, func(ctx context.Context) error {
+		return d.positions.Execute(ctx, positionID, func(aggr *position.Position, version uint64) ([]event_store.Event, error) {
+			return event_store.One(aggr.Buy(accountID, ticker, id, units, price, priceCurrency, happenedAt))
+		})
+	})
+}
+
+// RegisterSale records a sale and takes the units sold out of the position of
+// the ticker on the account, in the same unit of work. The cost basis of the
+// sale is what the position tells the units sold cost, and
+// position.ErrInsufficientUnits is returned if fewer units are held.
+func (d *Dispatcher) RegisterSale(
+	ctx context.Context,
+	id uuid.UUID,
+	accountID uuid.UUID,
+	ticker string,
+	units decimal.Decimal,
+	price decimal.Decimal,
+	priceCurrency values.Currency,
+	fee decimal.Decimal,
+	feeCurrency values.Currency,
+	happenedAt time.Time,
+) error {
+	if err := d.postable(ctx, accountID); err != nil {
+		return err
+	}
+
+	positionID := position.ID(accountID, ticker)
+	var sold event_store.Event
+	var positionVersion uint64
+	return d.record(ctx, id, func(aggr *Transaction) (event_store.Event, error) {
+		e, err := aggr.RegisterSale(accountID, ticker, units, price, priceCurrency, fee, feeCurrency, happenedAt)
+		sale, _ := e.(*events.InvestmentSold)
+		if err != nil || sale == nil {
+			return e, err
+		}
+
+		pos, version, err := d.positions.GetAggregate(ctx, positionID)
+		if err != nil {
+			return nil, err
+		}
+		sold, err = pos.Sell(id, units, d.costBasisMethod, happenedAt)
+		if err != nil {
+			return nil, err
+		}
+		positionVersion = version
+		sale.CostBasis = sold.(*position_events.Sold).CostBasis
+		return sale, nil
+	}, func(ctx context.Context) error {
+		// The units are taken out of the position only if it is still at the
+		// version the cost basis was decided on.
+		return d.positions.Execute(ctx, positionID, event_store.Expecting[*position.Position](positionID, positionVersion, []event_store.Event{sold}))
+	})
+}
+
+func (d *Dispatcher) RegisterDividend(
+	ctx context.Context,
+	id uuid.UUID,
+	accountID uuid.UUID,
+	ticker string,
+	units decimal.Decimal,
+	price decimal.Decimal,
+	priceCurrency values.Currency,
+	fee decimal.Decimal,
+	feeCurrency values.Currency,
+	happenedAt time.Time,
+) error {
+	if err := d.postable(ctx, accountID); err != nil {
+		return err
+	}
+
+	return d.record(ctx, id, func(aggr *Transaction) (event_store.Event, error) {
+		return aggr.RegisterDividend(accountID, ticker, units, price, priceCurrency, fee, feeCurrency, happenedAt)
+	})
+}
+
+func (d *Dispatcher) RegisterInterest(
+	ctx context.Context,
+	id uuid.UUID,
+	accountID uuid.UUID,
+	ticker string,
+	units decimal.Decimal,
+	price decimal.Decimal,
+	priceCurrency values.Currency,
+	fee decimal.Decimal,
+	feeCurrency values.Currency,
+	happenedAt time.Time,
+) error {
+	if err := d.postable(ctx, accountID); err != nil {
+		return err
+	}
+
+	return d.record(ctx, id, func(aggr *Transaction) (event_store.Event, error) {
+		return aggr.RegisterInterest(accountID, ticker, units, price, priceCurrency, fee, feeCurrency, happenedAt)
+	})
+}
+
+func (d *Dispatcher) RegisterStockSplit(
+	ctx context.Context,
+	id uuid.UUID,
+	accountID uuid.UUID,
+	ticker string,
+	ratio decimal.Decimal,
+	happenedAt time.Time,
+) error {
+	if err := d.postable(ctx, accountID); err != nil {
+		return err
+	}
+
+	positionID := position.ID(accountID, ticker)
+	return d.record(ctx, id, func(aggr *Transaction) (event_store.Event, error) {
+		return aggr.RegisterStockSplit(accountID, ticker, ratio, happenedAt)
+	}, func(ctx context.Context) error {
+		return d.positions.Execute(ctx, positionID, func(aggr *position.Position, version uint64) ([]event_store.Event, error) {
+			return event_store.One(aggr.Split(id, ratio, happenedAt))
+		})
 	})
 }
+
+func (d *Dispatcher) RegisterInvestmentFee(
+	ctx context.Context,
+	id uuid.UUID,
+	accountID uuid.UUID,
+	ticker string,
+	fee decimal.Decimal,
+	feeCurrency values.Currency,
+	happenedAt time.Time,
+) error {
+	if err := d.postable(ctx, accountID); err != nil {
+		return err
+	}
+
+	return d.record(ctx, id, func(aggr *Transaction) (event_store.Event, error) {
+		return aggr.RegisterInvestmentFee(accountID, ticker, fee, feeCurrency, happenedAt)
+	})
+}
+
+// Amend corrects the transaction. The amount of a reimbursement is corrected
+// on its expense too, and the change of amount posted to the account, in the
+// same unit of work.
+func (d *Dispatcher) Amend(
+	ctx context.Context,
+	id uuid.UUID,
+	amendment Amendment,
+) error {
+	amendID := uuid.New()
+	metadata := event_store.MetadataFrom(ctx)
+	if metadata.CorrelationID == uuid.Nil {
+		metadata.CorrelationID = amendID
+	}
+	ctx = event_store.WithMetadata(ctx, metadata)
+
+	return d.uow.Do(ctx, func(ctx context.Context) error {
+		var changed *events.AmountChanged
+		var expenseID uuid.UUID
+		var postings []values.Entry
+		var happenedAt time.Time
+		err := d.es.Execute(ctx, id, func(aggr *Transaction, version uint64) ([]event_store.Event, error) {
+			if err := d.postable(ctx, aggr.accountIDs()...); err != nil {
+				return nil, err
+			}
+
+			evts, err := aggr.Amend(amendment)
+			if err != nil {
+				return nil, err
+			}
+			postings, happenedAt, err = aggr.postings(evts)
+			for i, e := range evts {
+				if c, ok := e.(*events.AmountChanged); ok {
+					changed = c
+					evts[i] = event_store.WithID(amendID, e)
+				}
+			}
+			expenseID = aggr.ExpenseID
+			return evts, err
+		})
+		if err != nil || changed == nil {
+			return err
+		}
+
+		ctx = event_store.CausedBy(ctx, event_store.Record{ID: amendID, Metadata: metadata})
+
+		if err := d.post(ctx, id, postings, happenedAt); err != nil {
+			return err
+		}
+		if expenseID == uuid.Nil {
+			return nil
+		}
+
+		return d.es.Execute(ctx, expenseID, func(aggr *Transaction, version uint64) ([]event_store.Event, error) {
+			return event_store.One(aggr.AmendReimbursement(id, changed.Amount, changed.HappenedAt))
+		})
+	})
+}
+
+// Void cancels the transaction. The movements a transfer made on its accounts
+// and the money other transactions posted to theirs are reversed, and a
+// reimbursement is unlinked from its expense, in the same unit of work.
+func (d *Dispatcher) Void(ctx context.Context, id uuid.UUID) error {
+	voidID := uuid.New()
+	metadata := event_store.MetadataFrom(ctx)
+	if metadata.CorrelationID == uuid.Nil {
+		metadata.CorrelationID = voidID
+	}
+	ctx = event_store.WithMetadata(ctx, metadata)
+
+	return d.uow.Do(ctx, func(ctx context.Context) error {
+		var voided *events.TransactionVoided
+		var expenseID uuid.UUID
+		var postings []values.Entry
+		err := d.es.Execute(ctx, id, func(aggr *Transaction, version uint64) ([]event_store.Event, error) {
+			if err := d.postable(ctx, aggr.accountIDs()...); err != nil {
+				return nil, err
+			}
+
+			evts, err := aggr.Void()
+			if err != nil {
+				return nil, err
+			}
+			postings, _, err = aggr.postings(evts)
+			for i, e := range evts {
+				if v, ok := e.(*events.TransactionVoided); ok {
+					voided = v
+					evts[i] = event_store.WithID(voidID, e)
+				}
+			}
+			expenseID = aggr.ExpenseID
+			return evts, err
+		})
+		if err != nil || voided == nil {
+			return err
+		}
+
+		ctx = event_store.CausedBy(ctx, event_store.Record{ID: voidID, Metadata: metadata})
+
+		if err := d.post(ctx, id, postings, voided.HappenedAt); err != nil {
+			return err
+		}
+
+		switch {
+		case voided.TransactionType == values.TransactionType_Transfer:
+			return d.reverseTransfer(ctx, voided)
+		case expenseID != uuid.Nil:
+			return d.es.Execute(ctx, expenseID, func(aggr *Transaction, version uint64) ([]event_store.Event, error) {
+				return event_store.One(aggr.CancelReimbursement(id))
+			})
+		}
+		return nil
+	})
+}
+
+// record executes the command on the transaction and posts the money its event
+// moves to the accounts, in the same unit of work. The follow-ups run within
+// the unit of work too, unless the command recorded nothing.
+func (d *Dispatcher) record(
+	ctx context.Context,
+	id uuid.UUID,
+	command func(aggr *Transaction) (event_store.Event, error),
+	followUps ...func(ctx context.Context) error,
+) error {
+	recordID := uuid.New()
+	metadata := event_store.MetadataFrom(ctx)
+	if metadata.CorrelationID == uuid.Nil {
+		metadata.CorrelationID = recordID
+	}
+	ctx = event_store.WithMetadata(ctx, metadata)
+
+	return d.uow.Do(ctx, func(ctx context.Context) error {
+		var recorded event_store.Event
+		var postings []values.Entry
+		var happenedAt time.Time
+		err := d.es.Execute(ctx, id, func(aggr *Transaction, version uint64) ([]event_store.Event, error) {
+			e, err := command(aggr)
+			if err != nil {
+				return nil, err
+			}
+			recorded = event_store.WithID(recordID, e)
+			postings, happenedAt, err = aggr.postings([]event_store.Event{e})
+			return event_store.One(recorded, err)
+		})
+		if err != nil || recorded == nil {
+			return err
+		}
+
+		ctx = event_store.CausedBy(ctx, event_store.Record{ID: recordID, Metadata: metadata})
+
+		if err := d.post(ctx, id, postings, happenedAt); err != nil {
+			return err
+		}
+		for _, followUp := range followUps {
+			if err := followUp(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// post records on their accounts the entries the transaction of id posted to
+// them.
+func (d *Dispatcher) post(ctx context.Context, id uuid.UUID, postings []values.Entry, happenedAt time.Time) error {
+	for _, entry := range postings {
+		err := d.accounts.Execute(ctx, entry.AccountID, func(aggr *account.Account, version uint64) ([]event_store.Event, error) {
+			return event_store.One(aggr.Post(id, entry.Currency, entry.Amount, entry.Side, happenedAt))
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post transaction: %w", err)
+		}
+	}
+	return nil
+}
+
+// reverseTransfer moves the money of a voided transfer back between its
+// accounts.
+func (d *Dispatcher) reverseTransfer(ctx context.Context, voided *events.TransactionVoided) error {
+	for _, entry := range voided.Entries {
+		err := d.accounts.Execute(ctx, entry.AccountID, func(aggr *account.Account, version uint64) ([]event_store.Event, error) {
+			if entry.Side == values.Side_Debit {
+				return event_store.One(aggr.Deposit(entry.Currency, entry.Amount, voided.Category, voided.Description, userSystem, voided.HappenedAt))
+			}
+			return event_store.One(aggr.Withdraw(entry.Currency, entry.Amount, voided.Category, voided.Description, userSystem, voided.HappenedAt))
+		})
+		if err != nil {
+			return fmt.Errorf("failed to reverse transfer: %w", err)
+		}
+	}
+	return nil
+}
+
+// CloseAccount closes the account. Unless transferTo is nil, its remaining
+// balances are transferred to that account first, in the same unit of work.
+func (d *Dispatcher) CloseAccount(
+	ctx context.Context,
+	id uuid.UUID,
+	transferTo uuid.UUID,
+	happenedAt time.Time,
+) error {
+	metadata := event_store.MetadataFrom(ctx)
+	if metadata.CorrelationID == uuid.Nil {
+		metadata.CorrelationID = uuid.New()
+	}
+	ctx = event_store.WithMetadata(ctx, metadata)
+
+	return d.uow.Do(ctx, func(ctx context.Context) error {
+		if transferTo != uuid.Nil {
+			aggr, _, err := d.accounts.GetAggregate(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			for currency, balance := range aggr.Balance {
+				var err error
+				switch {
+				case balance.IsPositive():
+					err = d.RegisterTransfer(ctx, uuid.New(), id, currency, balance, transferTo, currency, balance, categoryClosing, "", happenedAt)
+				case balance.IsNegative():
+					err = d.RegisterTransfer(ctx, uuid.New(), transferTo, currency, balance.Neg(), id, currency, balance.Neg(), categoryClosing, "", happenedAt)
+				}
+				if err != nil {
+					return fmt.Errorf("failed to transfer the balance: %w", err)
+				}
+			}
+		}
+
+		return d.accounts.Execute(ctx, id, func(aggr *account.Account, version uint64) ([]event_store.Event, error) {
+			return event_store.One(aggr.Close(happenedAt))
+		})
+	})
+}
+
+// postable returns account.ErrAccountClosed if any of the accounts is closed.
+// Accounts that were never opened can still be posted to.
+func (d *Dispatcher) postable(ctx context.Context, accountIDs ...uuid.UUID) error {
+	for _, accountID := range accountIDs {
+		if accountID == uuid.Nil {
+			continue
+		}
+
+		aggr, _, err := d.accounts.GetAggregate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		if aggr.State == account.State_Closed {
+			return account.ErrAccountClosed
+		}
+	}
+	return nil
+}
+
+// referenceRate returns the rate a transfer between the currencies is compared
+// to, zero when they match, no reference rates are set or none is known for
+// the day.
+func (d *Dispatcher) referenceRate(ctx context.Context, from, to values.Currency, on time.Time) (decimal.Decimal, error) {
+	if d.referenceRates == nil || from == to {
+		return decimal.Decimal{}, nil
+	}
+
+	rate, err := d.referenceRates.Rate(ctx, from, to, on)
+	if errors.Is(err, fx.ErrNotFound) {
+		return decimal.Decimal{}, nil
+	}
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("failed to get reference rate: %w", err)
+	}
+	return rate, nil
+}
+
+// History returns every revision of the transaction, from the oldest.
+func (d *Dispatcher) History(ctx context.Context, id uuid.UUID) ([]Revision, error) {
+	records, err := d.es.ReadAggregate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return History(id, records)
+}
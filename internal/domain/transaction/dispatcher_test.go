@@ -0,0 +1,601 @@
+package transaction_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/account"
+	account_events "github.com/somatom98/brokeli/internal/domain/account/events"
+	"github.com/somatom98/brokeli/internal/domain/fx"
+	"github.com/somatom98/brokeli/internal/domain/position"
+	"github.com/somatom98/brokeli/internal/domain/transaction"
+	"github.com/somatom98/brokeli/internal/domain/transaction/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+func TestDispatcher_RegisterTransfer(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	amount := decimal.NewFromInt(100)
+
+	setup := func(t *testing.T) (*transaction.Dispatcher, *event_store.InMemoryStore[*transaction.Transaction], *event_store.InMemoryStore[*account.Account], uuid.UUID) {
+		transactionES := event_store.NewInMemory(transaction.New)
+		accountES := event_store.NewInMemory(account.New)
+		dispatcher := transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), event_store.NewInMemoryUnitOfWork())
+
+		fromID := uuid.New()
+		require.NoError(t, account.NewDispatcher(accountES).Open(ctx, fromID, "Checking", "EUR", now))
+
+		return dispatcher, transactionES, accountES, fromID
+	}
+
+	t.Run("should record the transfer and move the money between the accounts together", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, accountES, fromID := setup(t)
+		toID := uuid.New()
+		require.NoError(t, account.NewDispatcher(accountES).Open(ctx, toID, "Savings", "EUR", now))
+
+		// act
+		err := dispatcher.RegisterTransfer(ctx, uuid.New(), fromID, "EUR", amount, toID, "EUR", amount, "Transfer", "Savings", now)
+
+		// assert
+		require.NoError(t, err)
+
+		transfers, err := transactionES.ReadFrom(ctx, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, transfers, 1)
+		assert.Equal(t, events.TypeMoneyTransfered, transfers[0].Type())
+
+		movements, err := accountES.ReadFrom(ctx, 2, 10)
+		require.NoError(t, err)
+		require.Len(t, movements, 2)
+
+		assert.Equal(t, fromID, movements[0].AggregateID)
+		assert.Equal(t, account_events.TypeMoneyWithdrawn, movements[0].Type())
+		assert.Equal(t, toID, movements[1].AggregateID)
+		assert.Equal(t, account_events.TypeMoneyDeposited, movements[1].Type())
+
+		for _, movement := range movements {
+			assert.Equal(t, transfers[0].ID, movement.Metadata.CausationID)
+			assert.Equal(t, transfers[0].Metadata.CorrelationID, movement.Metadata.CorrelationID)
+			assert.Equal(t, event_store.SourceSystem, movement.Metadata.Source)
+		}
+	})
+
+	t.Run("should record nothing when a movement fails", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, accountES, fromID := setup(t)
+		unopenedID := uuid.New()
+
+		// act
+		err := dispatcher.RegisterTransfer(ctx, uuid.New(), fromID, "EUR", amount, unopenedID, "EUR", amount, "Transfer", "Savings", now)
+
+		// assert
+		assert.ErrorIs(t, err, account.ErrAccountNotOpened)
+
+		transfers, err := transactionES.ReadFrom(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, transfers)
+
+		movements, err := accountES.ReadFrom(ctx, 1, 10)
+		require.NoError(t, err)
+		assert.Empty(t, movements)
+	})
+
+	t.Run("should record the spread against the reference rate of the day", func(t *testing.T) {
+		// arrange
+		transactionES := event_store.NewInMemory(transaction.New)
+		accountES := event_store.NewInMemory(account.New)
+		rates := fx.NewInMemoryRepository()
+		require.NoError(t, rates.Save(ctx, fx.Rate{Base: "EUR", Quote: "USD", Date: fx.Day(now), Rate: decimal.RequireFromString("1.1")}))
+		dispatcher := transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), event_store.NewInMemoryUnitOfWork(), transaction.WithReferenceRates(fx.NewConverter(rates)))
+		fromID, toID := uuid.New(), uuid.New()
+		require.NoError(t, account.NewDispatcher(accountES).Open(ctx, fromID, "Checking", "EUR", now))
+		require.NoError(t, account.NewDispatcher(accountES).Open(ctx, toID, "Dollars", "USD", now))
+
+		// act
+		err := dispatcher.RegisterTransfer(ctx, uuid.New(), fromID, "EUR", amount, toID, "USD", decimal.NewFromInt(105), "Transfer", "Savings", now)
+
+		// assert
+		require.NoError(t, err)
+
+		transfers, err := transactionES.ReadFrom(ctx, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, transfers, 1)
+
+		transfer := transfers[0].Content().(events.MoneyTransfered)
+		assert.Equal(t, "1.05", transfer.Rate.String())
+		assert.Equal(t, "1.1", transfer.ReferenceRate.String())
+		assert.Equal(t, "4.55", transfer.Spread.String())
+	})
+
+	t.Run("should record no spread when the reference rate is not known", func(t *testing.T) {
+		// arrange
+		transactionES := event_store.NewInMemory(transaction.New)
+		accountES := event_store.NewInMemory(account.New)
+		dispatcher := transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), event_store.NewInMemoryUnitOfWork(), transaction.WithReferenceRates(fx.NewConverter(fx.NewInMemoryRepository())))
+		fromID, toID := uuid.New(), uuid.New()
+		require.NoError(t, account.NewDispatcher(accountES).Open(ctx, fromID, "Checking", "EUR", now))
+		require.NoError(t, account.NewDispatcher(accountES).Open(ctx, toID, "Dollars", "USD", now))
+
+		// act
+		err := dispatcher.RegisterTransfer(ctx, uuid.New(), fromID, "EUR", amount, toID, "USD", decimal.NewFromInt(105), "Transfer", "Savings", now)
+
+		// assert
+		require.NoError(t, err)
+
+		transfers, err := transactionES.ReadFrom(ctx, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, transfers, 1)
+
+		transfer := transfers[0].Content().(events.MoneyTransfered)
+		assert.Equal(t, "1.05", transfer.Rate.String())
+		assert.True(t, transfer.ReferenceRate.IsZero())
+		assert.True(t, transfer.Spread.IsZero())
+	})
+}
+
+func TestDispatcher_RegisterExpense(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	setup := func(t *testing.T) (*transaction.Dispatcher, *event_store.InMemoryStore[*transaction.Transaction], *event_store.InMemoryStore[*account.Account], uuid.UUID) {
+		transactionES := event_store.NewInMemory(transaction.New)
+		accountES := event_store.NewInMemory(account.New)
+		dispatcher := transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), event_store.NewInMemoryUnitOfWork())
+
+		accountID := uuid.New()
+		accounts := account.NewDispatcher(accountES)
+		require.NoError(t, accounts.Open(ctx, accountID, "Checking", "EUR", now))
+		require.NoError(t, accounts.SetOverdraftLimit(ctx, accountID, "EUR", decimal.NewFromInt(50), now))
+
+		return dispatcher, transactionES, accountES, accountID
+	}
+
+	t.Run("should post the expense to its account", func(t *testing.T) {
+		// arrange
+		dispatcher, _, accountES, accountID := setup(t)
+		id := uuid.New()
+
+		// act
+		err := dispatcher.RegisterExpense(ctx, id, accountID, "EUR", decimal.NewFromInt(40), "Groceries", "Weekly shopping", now)
+
+		// assert
+		require.NoError(t, err)
+
+		acc, _, err := accountES.GetAggregate(ctx, accountID)
+		require.NoError(t, err)
+		assert.Equal(t, "-40", acc.Balance["EUR"].String())
+	})
+
+	t.Run("should record nothing when the expense exceeds the overdraft limit", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, _, accountID := setup(t)
+		require.NoError(t, dispatcher.RegisterIncome(ctx, uuid.New(), accountID, "EUR", decimal.NewFromInt(100), "Salary", "October", now))
+
+		// act
+		err := dispatcher.RegisterExpense(ctx, uuid.New(), accountID, "EUR", decimal.NewFromInt(151), "Groceries", "Weekly shopping", now)
+
+		// assert
+		assert.ErrorIs(t, err, account.ErrInsufficientFunds)
+
+		records, err := transactionES.ReadFrom(ctx, 1, 10)
+		require.NoError(t, err)
+		assert.Empty(t, records)
+	})
+
+	t.Run("should hold the lines of a split expense to the overdraft limit of their accounts", func(t *testing.T) {
+		// arrange
+		dispatcher, _, _, accountID := setup(t)
+		lines := []values.SplitLine{
+			{Category: "Groceries", Amount: decimal.NewFromInt(30)},
+			{AccountID: accountID, Category: "Household", Amount: decimal.NewFromInt(60)},
+		}
+
+		// act
+		err := dispatcher.RegisterSplitExpense(ctx, uuid.New(), uuid.New(), "EUR", decimal.NewFromInt(90), lines, "Supermarket", now)
+
+		// assert
+		assert.ErrorIs(t, err, account.ErrInsufficientFunds)
+	})
+}
+
+func TestDispatcher_RegisterSale(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	// arrange positions: 10 units of VWCE bought at 100, then 10 at 130.
+	setup := func(t *testing.T, opts ...transaction.DispatcherOption) (*transaction.Dispatcher, *event_store.InMemoryStore[*transaction.Transaction], *event_store.InMemoryStore[*position.Position], uuid.UUID) {
+		transactionES := event_store.NewInMemory(transaction.New)
+		positionES := event_store.NewInMemory(position.New)
+		dispatcher := transaction.NewDispatcher(transactionES, event_store.NewInMemory(account.New), positionES, event_store.NewInMemoryUnitOfWork(), opts...)
+
+		accountID := uuid.New()
+		require.NoError(t, dispatcher.RegisterInvestment(ctx, uuid.New(), accountID, "VWCE", decimal.NewFromInt(10), decimal.NewFromInt(100), "EUR", decimal.Zero, "EUR", now))
+		require.NoError(t, dispatcher.RegisterInvestment(ctx, uuid.New(), accountID, "VWCE", decimal.NewFromInt(10), decimal.NewFromInt(130), "EUR", decimal.Zero, "EUR", now.AddDate(0, 0, 1)))
+
+		return dispatcher, transactionES, positionES, accountID
+	}
+
+	sale := func(t *testing.T, transactionES *event_store.InMemoryStore[*transaction.Transaction], id uuid.UUID) events.InvestmentSold {
+		records, err := transactionES.ReadAggregate(ctx, id)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		sold, ok := records[0].Content().(events.InvestmentSold)
+		require.True(t, ok)
+		return sold
+	}
+
+	t.Run("should cost the units sold at the oldest lots of the position", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, positionES, accountID := setup(t)
+		id := uuid.New()
+
+		// act
+		err := dispatcher.RegisterSale(ctx, id, accountID, "VWCE", decimal.NewFromInt(15), decimal.NewFromInt(150), "EUR", decimal.Zero, "EUR", now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "1650", sale(t, transactionES, id).CostBasis.String())
+
+		pos, _, err := positionES.GetAggregate(ctx, position.ID(accountID, "VWCE"))
+		require.NoError(t, err)
+		assert.Equal(t, "5", pos.Units.String())
+		assert.Equal(t, "650", pos.CostBasis.String())
+	})
+
+	t.Run("should cost the units sold at the average cost of the position", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, _, accountID := setup(t, transaction.WithCostBasisMethod(position.CostBasisMethod_Average))
+		id := uuid.New()
+
+		// act
+		err := dispatcher.RegisterSale(ctx, id, accountID, "VWCE", decimal.NewFromInt(15), decimal.NewFromInt(150), "EUR", decimal.Zero, "EUR", now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "1725", sale(t, transactionES, id).CostBasis.String())
+	})
+
+	t.Run("should record nothing when selling more units than held", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, _, accountID := setup(t)
+		id := uuid.New()
+
+		// act
+		err := dispatcher.RegisterSale(ctx, id, accountID, "VWCE", decimal.NewFromInt(21), decimal.NewFromInt(150), "EUR", decimal.Zero, "EUR", now)
+
+		// assert
+		assert.ErrorIs(t, err, position.ErrInsufficientUnits)
+
+		records, err := transactionES.ReadAggregate(ctx, id)
+		require.NoError(t, err)
+		assert.Empty(t, records)
+	})
+
+	t.Run("should sell the units a split multiplied", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, _, accountID := setup(t)
+		require.NoError(t, dispatcher.RegisterStockSplit(ctx, uuid.New(), accountID, "VWCE", decimal.NewFromInt(2), now))
+		id := uuid.New()
+
+		// act
+		err := dispatcher.RegisterSale(ctx, id, accountID, "VWCE", decimal.NewFromInt(40), decimal.NewFromInt(75), "EUR", decimal.Zero, "EUR", now)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, "2300", sale(t, transactionES, id).CostBasis.String())
+	})
+}
+
+func TestDispatcher_Void(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	amount := decimal.NewFromInt(100)
+
+	t.Run("should void a transfer and reverse its movements together", func(t *testing.T) {
+		// arrange
+		transactionES := event_store.NewInMemory(transaction.New)
+		accountES := event_store.NewInMemory(account.New)
+		dispatcher := transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), event_store.NewInMemoryUnitOfWork())
+		fromID, toID, id := uuid.New(), uuid.New(), uuid.New()
+		require.NoError(t, account.NewDispatcher(accountES).Open(ctx, fromID, "Checking", "EUR", now))
+		require.NoError(t, account.NewDispatcher(accountES).Open(ctx, toID, "Savings", "EUR", now))
+		require.NoError(t, dispatcher.RegisterTransfer(ctx, id, fromID, "EUR", amount, toID, "EUR", amount, "Transfer", "Savings", now))
+
+		// act
+		err := dispatcher.Void(ctx, id)
+
+		// assert
+		require.NoError(t, err)
+
+		voids, err := transactionES.ReadFrom(ctx, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, voids, 1)
+		assert.Equal(t, events.TypeTransactionVoided, voids[0].Type())
+
+		reversals, err := accountES.ReadFrom(ctx, 4, 10)
+		require.NoError(t, err)
+		require.Len(t, reversals, 2)
+
+		assert.Equal(t, fromID, reversals[0].AggregateID)
+		assert.Equal(t, account_events.TypeMoneyDeposited, reversals[0].Type())
+		assert.Equal(t, toID, reversals[1].AggregateID)
+		assert.Equal(t, account_events.TypeMoneyWithdrawn, reversals[1].Type())
+
+		for _, reversal := range reversals {
+			assert.Equal(t, voids[0].ID, reversal.Metadata.CausationID)
+		}
+	})
+
+	t.Run("should void an expense posting it back to its account", func(t *testing.T) {
+		// arrange
+		transactionES := event_store.NewInMemory(transaction.New)
+		accountES := event_store.NewInMemory(account.New)
+		dispatcher := transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), event_store.NewInMemoryUnitOfWork())
+		id, accountID := uuid.New(), uuid.New()
+		require.NoError(t, dispatcher.RegisterExpense(ctx, id, accountID, "EUR", amount, "Groceries", "Weekly shopping", now))
+
+		// act
+		err := dispatcher.Void(ctx, id)
+
+		// assert
+		require.NoError(t, err)
+
+		postings, err := accountES.ReadFrom(ctx, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, postings, 2)
+		for _, posting := range postings {
+			assert.Equal(t, account_events.TypeMoneyPosted, posting.Type())
+		}
+		assert.Equal(t, values.Side_Credit, postings[1].Content().(account_events.MoneyPosted).Side)
+
+		acc, _, err := accountES.GetAggregate(ctx, accountID)
+		require.NoError(t, err)
+		assert.Equal(t, "0", acc.Balance["EUR"].String())
+	})
+}
+
+func TestDispatcher_RegisterReimbursement(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	setup := func(t *testing.T) (*transaction.Dispatcher, *event_store.InMemoryStore[*transaction.Transaction], uuid.UUID, uuid.UUID) {
+		transactionES := event_store.NewInMemory(transaction.New)
+		accountES := event_store.NewInMemory(account.New)
+		dispatcher := transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), event_store.NewInMemoryUnitOfWork())
+
+		expenseID, accountID := uuid.New(), uuid.New()
+		require.NoError(t, dispatcher.RegisterExpense(ctx, expenseID, accountID, "EUR", decimal.NewFromInt(100), "Dinner", "Dinner with Alice", now))
+		require.NoError(t, dispatcher.SetExpectedReimbursement(ctx, expenseID, accountID, "Alice", "EUR", decimal.NewFromInt(50), now))
+
+		return dispatcher, transactionES, expenseID, accountID
+	}
+
+	t.Run("should link the reimbursement to its expense", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, expenseID, accountID := setup(t)
+		id := uuid.New()
+
+		// act
+		err := dispatcher.RegisterReimbursement(ctx, id, expenseID, accountID, "Alice", "EUR", decimal.NewFromInt(30), "Dinner", "Alice's share", now)
+
+		// assert
+		require.NoError(t, err)
+
+		records, err := transactionES.ReadFrom(ctx, 2, 10)
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+
+		assert.Equal(t, id, records[0].AggregateID)
+		assert.Equal(t, events.TypeReimbursementReceived, records[0].Type())
+		assert.Equal(t, expenseID, records[1].AggregateID)
+		assert.Equal(t, events.TypeExpenseReimbursed, records[1].Type())
+		assert.Equal(t, records[0].ID, records[1].Metadata.CausationID)
+
+		reimbursed := records[1].Content().(events.ExpenseReimbursed)
+		assert.Equal(t, id, reimbursed.ReimbursementID)
+		assert.Equal(t, "20", reimbursed.Outstanding.String())
+	})
+
+	t.Run("should make the reimbursement outstanding again when it is voided", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, expenseID, accountID := setup(t)
+		id := uuid.New()
+		require.NoError(t, dispatcher.RegisterReimbursement(ctx, id, expenseID, accountID, "Alice", "EUR", decimal.NewFromInt(30), "Dinner", "Alice's share", now))
+
+		// act
+		err := dispatcher.Void(ctx, id)
+
+		// assert
+		require.NoError(t, err)
+
+		records, err := transactionES.ReadFrom(ctx, 4, 10)
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+
+		assert.Equal(t, events.TypeTransactionVoided, records[0].Type())
+		assert.Equal(t, expenseID, records[1].AggregateID)
+		assert.Equal(t, events.TypeReimbursementCancelled, records[1].Type())
+		assert.Equal(t, "50", records[1].Content().(events.ReimbursementCancelled).Outstanding.String())
+	})
+
+	t.Run("should correct the outstanding amount of the expense when the reimbursement is amended", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, expenseID, accountID := setup(t)
+		id := uuid.New()
+		require.NoError(t, dispatcher.RegisterReimbursement(ctx, id, expenseID, accountID, "Alice", "EUR", decimal.NewFromInt(30), "Dinner", "Alice's share", now))
+		amount := decimal.NewFromInt(45)
+
+		// act
+		err := dispatcher.Amend(ctx, id, transaction.Amendment{Amount: &amount})
+
+		// assert
+		require.NoError(t, err)
+
+		records, err := transactionES.ReadFrom(ctx, 4, 10)
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+
+		assert.Equal(t, events.TypeAmountChanged, records[0].Type())
+		assert.Equal(t, expenseID, records[1].AggregateID)
+		assert.Equal(t, events.TypeReimbursementAmended, records[1].Type())
+		assert.Equal(t, records[0].ID, records[1].Metadata.CausationID)
+
+		expense, _, err := transactionES.GetAggregate(ctx, expenseID)
+		require.NoError(t, err)
+		assert.Equal(t, "45", expense.Receivable.Received().String())
+		assert.Equal(t, "5", expense.Receivable.Outstanding().String())
+	})
+
+	t.Run("should record nothing when the amended reimbursement exceeds the amount expected", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, expenseID, accountID := setup(t)
+		id := uuid.New()
+		require.NoError(t, dispatcher.RegisterReimbursement(ctx, id, expenseID, accountID, "Alice", "EUR", decimal.NewFromInt(30), "Dinner", "Alice's share", now))
+		amount := decimal.NewFromInt(60)
+
+		// act
+		err := dispatcher.Amend(ctx, id, transaction.Amendment{Amount: &amount})
+
+		// assert
+		assert.ErrorIs(t, err, transaction.ErrOverReimbursed)
+
+		records, err := transactionES.ReadFrom(ctx, 4, 10)
+		require.NoError(t, err)
+		assert.Empty(t, records)
+
+		expense, _, err := transactionES.GetAggregate(ctx, expenseID)
+		require.NoError(t, err)
+		assert.Equal(t, "20", expense.Receivable.Outstanding().String())
+	})
+
+	t.Run("should record nothing when the expense is not reimbursable", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, _, accountID := setup(t)
+		incomeID := uuid.New()
+		require.NoError(t, dispatcher.RegisterIncome(ctx, incomeID, accountID, "EUR", decimal.NewFromInt(100), "Salary", "October", now))
+
+		// act
+		err := dispatcher.RegisterReimbursement(ctx, uuid.New(), incomeID, accountID, "Alice", "EUR", decimal.NewFromInt(30), "Dinner", "Alice's share", now)
+
+		// assert
+		assert.ErrorIs(t, err, transaction.ErrNotReimbursable)
+
+		records, err := transactionES.ReadFrom(ctx, 3, 10)
+		require.NoError(t, err)
+		assert.Empty(t, records)
+	})
+}
+
+func TestDispatcher_CloseAccount(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	setup := func(t *testing.T) (*transaction.Dispatcher, *event_store.InMemoryStore[*transaction.Transaction], *event_store.InMemoryStore[*account.Account], uuid.UUID, uuid.UUID) {
+		transactionES := event_store.NewInMemory(transaction.New)
+		accountES := event_store.NewInMemory(account.New)
+		dispatcher := transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), event_store.NewInMemoryUnitOfWork())
+
+		id, savingsID := uuid.New(), uuid.New()
+		require.NoError(t, account.NewDispatcher(accountES).Open(ctx, id, "Checking", "EUR", now))
+		require.NoError(t, account.NewDispatcher(accountES).Open(ctx, savingsID, "Savings", "EUR", now))
+
+		return dispatcher, transactionES, accountES, id, savingsID
+	}
+
+	t.Run("should transfer the remaining balances before closing the account", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, accountES, id, savingsID := setup(t)
+		require.NoError(t, account.NewDispatcher(accountES).Deposit(ctx, id, "EUR", decimal.NewFromInt(40), "", "", "test-user", now))
+
+		// act
+		err := dispatcher.CloseAccount(ctx, id, savingsID, now)
+
+		// assert
+		require.NoError(t, err)
+
+		transfers, err := transactionES.ReadFrom(ctx, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, transfers, 1)
+		transfer := transfers[0].Content().(events.MoneyTransfered)
+		assert.Equal(t, id, transfer.FromAccountID)
+		assert.Equal(t, savingsID, transfer.ToAccountID)
+		assert.Equal(t, "40", transfer.FromAmount.String())
+
+		records, err := accountES.ReadFrom(ctx, 3, 10)
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, account_events.TypeClosed, records[2].Type())
+		assert.Equal(t, transfers[0].Metadata.CorrelationID, records[2].Metadata.CorrelationID)
+	})
+
+	t.Run("should not close the account with a balance and no account to transfer it to", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, accountES, id, _ := setup(t)
+		require.NoError(t, dispatcher.RegisterIncome(ctx, uuid.New(), id, "EUR", decimal.NewFromInt(40), "Salary", "October", now))
+
+		// act
+		err := dispatcher.CloseAccount(ctx, id, uuid.Nil, now)
+
+		// assert
+		assert.ErrorIs(t, err, account.ErrNonZeroBalance)
+
+		transfers, err := transactionES.ReadFrom(ctx, 1, 10)
+		require.NoError(t, err)
+		assert.Empty(t, transfers)
+
+		records, err := accountES.ReadFrom(ctx, 3, 10)
+		require.NoError(t, err)
+		assert.Empty(t, records)
+	})
+
+	t.Run("should reject transactions posted to a closed account", func(t *testing.T) {
+		// arrange
+		dispatcher, transactionES, _, id, _ := setup(t)
+		require.NoError(t, dispatcher.CloseAccount(ctx, id, uuid.Nil, now))
+
+		// act
+		err := dispatcher.RegisterExpense(ctx, uuid.New(), id, "EUR", decimal.NewFromInt(10), "Groceries", "Weekly shopping", now)
+
+		// assert
+		assert.ErrorIs(t, err, account.ErrAccountClosed)
+
+		records, err := transactionES.ReadFrom(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, records)
+	})
+}
+
+func TestDispatcher_History(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("should return the state of the transaction after each revision", func(t *testing.T) {
+		// arrange
+		transactionES := event_store.NewInMemory(transaction.New)
+		dispatcher := transaction.NewDispatcher(transactionES, event_store.NewInMemory(account.New), event_store.NewInMemory(position.New), event_store.NewInMemoryUnitOfWork())
+		id := uuid.New()
+		amount := decimal.NewFromInt(45)
+		require.NoError(t, dispatcher.RegisterExpense(ctx, id, uuid.New(), "EUR", decimal.NewFromInt(40), "Groceries", "Weekly shopping", now))
+		require.NoError(t, dispatcher.Amend(ctx, id, transaction.Amendment{Amount: &amount}))
+
+		// act
+		history, err := dispatcher.History(ctx, id)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, events.TypeMoneySpent, history[0].Record.Type())
+		assert.Equal(t, "40", history[0].Transaction.Entries[0].Amount.String())
+		assert.Equal(t, events.TypeAmountChanged, history[1].Record.Type())
+		assert.Equal(t, "45", history[1].Transaction.Entries[0].Amount.String())
+	})
+}
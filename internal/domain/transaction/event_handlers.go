@@ -1,14 +1,28 @@
 package transaction
 
 import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/somatom98/brokeli/internal/domain/transaction/events"
 	"github.com/somatom98/brokeli/internal/domain/values"
 )
 
 func (t *Transaction) ApplyExpectedReimbursementSet(e events.ExpectedReimbursementSet) {
-	t.State = State_Created
-	t.Type = values.TransactionType_ExpectedReimbursement
-	t.Description = ""
+	// Expected reimbursements used to be recorded as transactions of their
+	// own, before they were set on expenses.
+	if t.Type == "" || t.Type == values.TransactionType_ExpectedReimbursement {
+		t.State = State_Created
+		t.Type = values.TransactionType_ExpectedReimbursement
+		t.Description = ""
+		t.HappenedAt = e.HappenedAt
+		return
+	}
+
+	t.Receivable.AccountID = e.AccountID
+	if e.From != "" {
+		t.Receivable.From = e.From
+	}
+	t.Receivable.Expected = e.Amount
 }
 
 func (t *Transaction) ApplyExpenseCreated(e events.MoneySpent) {
@@ -23,6 +37,24 @@ func (t *Transaction) ApplyExpenseCreated(e events.MoneySpent) {
 	t.Entries = append(t.Entries, entry)
 	t.Category = e.Category
 	t.Description = e.Description
+	t.HappenedAt = e.HappenedAt
+}
+
+func (t *Transaction) ApplyExpenseSplit(e events.ExpenseSplit) {
+	t.State = State_Created
+	t.Type = values.TransactionType_Expense
+	for _, line := range e.Lines {
+		entry := values.Entry{
+			AccountID: line.AccountID,
+			Currency:  e.Currency,
+			Amount:    line.Amount,
+			Side:      values.Side_Debit,
+		}
+		t.Entries = append(t.Entries, entry)
+	}
+	t.Lines = e.Lines
+	t.Description = e.Description
+	t.HappenedAt = e.HappenedAt
 }
 
 func (t *Transaction) ApplyIncomeCreated(e events.MoneyReceived) {
@@ -37,6 +69,7 @@ func (t *Transaction) ApplyIncomeCreated(e events.MoneyReceived) {
 	t.Entries = append(t.Entries, entry)
 	t.Category = e.Category
 	t.Description = e.Description
+	t.HappenedAt = e.HappenedAt
 }
 
 func (t *Transaction) ApplyTransferCreated(e events.MoneyTransfered) {
@@ -57,11 +90,13 @@ func (t *Transaction) ApplyTransferCreated(e events.MoneyTransfered) {
 	t.Entries = append(t.Entries, from, to)
 	t.Category = e.Category
 	t.Description = e.Description
+	t.HappenedAt = e.HappenedAt
 }
 
 func (t *Transaction) ApplyReimbursementReceived(e events.ReimbursementReceived) {
 	t.State = State_Created
 	t.Type = values.TransactionType_Reimbursement
+	t.ExpenseID = e.ExpenseID
 	entry := values.Entry{
 		AccountID: e.AccountID,
 		Currency:  e.Currency,
@@ -71,6 +106,7 @@ func (t *Transaction) ApplyReimbursementReceived(e events.ReimbursementReceived)
 	t.Entries = append(t.Entries, entry)
 	t.Category = e.Category
 	t.Description = e.Description
+	t.HappenedAt = e.HappenedAt
 }
 
 func (t *Transaction) ApplyInvestmentCreated(e events.MoneyInvested) {
@@ -105,4 +141,131 @@ func (t *Transaction) ApplyInvestmentCreated(e events.MoneyInvested) {
 
 	t.Category = "Investments"
 	t.Description = e.Ticker
+	t.HappenedAt = e.HappenedAt
+}
+
+func (t *Transaction) ApplyInvestmentSold(e events.InvestmentSold) {
+	t.State = State_Created
+	t.Type = values.TransactionType_Sale
+	t.Entries = append(t.Entries, proceedsEntries(e.AccountID, e.Units.Mul(e.Price), e.PriceCurrency, e.Fee, e.FeeCurrency)...)
+	t.Category = "Investments"
+	t.Description = e.Ticker
+	t.HappenedAt = e.HappenedAt
+}
+
+func (t *Transaction) ApplyDividendReceived(e events.DividendReceived) {
+	t.State = State_Created
+	t.Type = values.TransactionType_Dividend
+	t.Entries = append(t.Entries, proceedsEntries(e.AccountID, e.Units.Mul(e.Price), e.PriceCurrency, e.Fee, e.FeeCurrency)...)
+	t.Category = "Investments"
+	t.Description = e.Ticker
+	t.HappenedAt = e.HappenedAt
+}
+
+func (t *Transaction) ApplyInterestReceived(e events.InterestReceived) {
+	t.State = State_Created
+	t.Type = values.TransactionType_Interest
+	t.Entries = append(t.Entries, proceedsEntries(e.AccountID, e.Units.Mul(e.Price), e.PriceCurrency, e.Fee, e.FeeCurrency)...)
+	t.Category = "Investments"
+	t.Description = e.Ticker
+	t.HappenedAt = e.HappenedAt
+}
+
+// ApplyStockSplit records no entry, since a split moves no money.
+func (t *Transaction) ApplyStockSplit(e events.StockSplit) {
+	t.State = State_Created
+	t.Type = values.TransactionType_StockSplit
+	t.Category = "Investments"
+	t.Description = e.Ticker
+	t.HappenedAt = e.HappenedAt
+}
+
+func (t *Transaction) ApplyInvestmentFeeCharged(e events.InvestmentFeeCharged) {
+	t.State = State_Created
+	t.Type = values.TransactionType_InvestmentFee
+	t.Entries = append(t.Entries, values.Entry{
+		AccountID: e.AccountID,
+		Currency:  e.FeeCurrency,
+		Amount:    e.Fee,
+		Side:      values.Side_Debit,
+	})
+	t.Category = "Investments"
+	t.Description = e.Ticker
+	t.HappenedAt = e.HappenedAt
+}
+
+// proceedsEntries returns the entries of money received on an account less a
+// fee, netted into a single entry when both are in the same currency.
+func proceedsEntries(accountID uuid.UUID, proceeds decimal.Decimal, currency values.Currency, fee decimal.Decimal, feeCurrency values.Currency) []values.Entry {
+	if currency == feeCurrency {
+		net := proceeds.Sub(fee)
+		side := values.Side_Credit
+		if net.IsNegative() {
+			side = values.Side_Debit
+		}
+		return []values.Entry{{
+			AccountID: accountID,
+			Currency:  currency,
+			Amount:    net.Abs(),
+			Side:      side,
+		}}
+	}
+
+	return []values.Entry{
+		{
+			AccountID: accountID,
+			Currency:  currency,
+			Amount:    proceeds,
+			Side:      values.Side_Credit,
+		},
+		{
+			AccountID: accountID,
+			Currency:  feeCurrency,
+			Amount:    fee,
+			Side:      values.Side_Debit,
+		},
+	}
+}
+
+func (t *Transaction) ApplyAmountChanged(e events.AmountChanged) {
+	if len(t.Entries) == 1 {
+		t.Entries[0].Amount = e.Amount
+	}
+}
+
+func (t *Transaction) ApplyRedated(e events.Redated) {
+	t.HappenedAt = e.HappenedAt
+}
+
+func (t *Transaction) ApplyRecategorized(e events.Recategorized) {
+	t.Category = e.Category
+}
+
+func (t *Transaction) ApplyRedescribed(e events.Redescribed) {
+	t.Description = e.Description
+}
+
+func (t *Transaction) ApplyExpenseReimbursed(e events.ExpenseReimbursed) {
+	t.Receivable.AccountID = e.AccountID
+	if t.Receivable.From == "" {
+		t.Receivable.From = e.From
+	}
+	if t.Receivable.Reimbursements == nil {
+		t.Receivable.Reimbursements = make(map[uuid.UUID]decimal.Decimal)
+	}
+	t.Receivable.Reimbursements[e.ReimbursementID] = e.Amount
+}
+
+func (t *Transaction) ApplyReimbursementCancelled(e events.ReimbursementCancelled) {
+	delete(t.Receivable.Reimbursements, e.ReimbursementID)
+}
+
+func (t *Transaction) ApplyReimbursementAmended(e events.ReimbursementAmended) {
+	if _, ok := t.Receivable.Reimbursements[e.ReimbursementID]; ok {
+		t.Receivable.Reimbursements[e.ReimbursementID] = e.Amount
+	}
+}
+
+func (t *Transaction) ApplyTransactionVoided(e events.TransactionVoided) {
+	t.State = State_Deleted
 }
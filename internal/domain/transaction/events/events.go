@@ -15,6 +15,20 @@ const (
 	TypeReimbursementReceived    string = "ReimbursementReceived"
 	TypeExpectedReimbursementSet string = "ExpectedReimbursementSet"
 	TypeMoneyInvested            string = "MoneyInvested"
+	TypeInvestmentSold           string = "InvestmentSold"
+	TypeDividendReceived         string = "DividendReceived"
+	TypeInterestReceived         string = "InterestReceived"
+	TypeStockSplit               string = "StockSplit"
+	TypeInvestmentFeeCharged     string = "InvestmentFeeCharged"
+	TypeAmountChanged            string = "AmountChanged"
+	TypeRedated                  string = "Redated"
+	TypeRecategorized            string = "Recategorized"
+	TypeRedescribed              string = "Redescribed"
+	TypeTransactionVoided        string = "TransactionVoided"
+	TypeExpenseSplit             string = "ExpenseSplit"
+	TypeExpenseReimbursed        string = "ExpenseReimbursed"
+	TypeReimbursementCancelled   string = "ReimbursementCancelled"
+	TypeReimbursementAmended     string = "ReimbursementAmended"
 )
 
 type MoneySpent struct {
@@ -34,6 +48,25 @@ func (e MoneySpent) Content() any {
 	return e
 }
 
+// ExpenseSplit records an expense whose total is split into lines, each
+// spent on its own category and from its own account.
+type ExpenseSplit struct {
+	AccountID   uuid.UUID
+	Currency    values.Currency
+	Amount      decimal.Decimal
+	Lines       []values.SplitLine
+	Description string
+	HappenedAt  time.Time
+}
+
+func (e ExpenseSplit) Type() string {
+	return TypeExpenseSplit
+}
+
+func (e ExpenseSplit) Content() any {
+	return e
+}
+
 type MoneyReceived struct {
 	AccountID   uuid.UUID
 	Currency    values.Currency
@@ -51,6 +84,11 @@ func (e MoneyReceived) Content() any {
 	return e
 }
 
+// MoneyTransfered records money moved between two accounts. Rate is the one
+// the transfer was made at, what one FromCurrency was exchanged for in
+// ToCurrency. When a ReferenceRate was known for the day, Spread is the part
+// of FromAmount, in FromCurrency, lost on it compared to that rate, rounded
+// to the minor units of FromCurrency.
 type MoneyTransfered struct {
 	FromAccountID uuid.UUID
 	FromCurrency  values.Currency
@@ -58,6 +96,9 @@ type MoneyTransfered struct {
 	ToAccountID   uuid.UUID
 	ToCurrency    values.Currency
 	ToAmount      decimal.Decimal
+	Rate          decimal.Decimal
+	ReferenceRate decimal.Decimal
+	Spread        decimal.Decimal
 	Category      string
 	Description   string
 	HappenedAt    time.Time
@@ -71,7 +112,23 @@ func (e MoneyTransfered) Content() any {
 	return e
 }
 
+// ratePlaces is the number of decimal places the rate of a transfer is
+// rounded to.
+const ratePlaces = 6
+
+// ImpliedRate returns the rate a transfer of fromAmount that was received as
+// toAmount was made at.
+func ImpliedRate(fromAmount, toAmount decimal.Decimal) decimal.Decimal {
+	if !fromAmount.IsPositive() {
+		return decimal.Decimal{}
+	}
+	return toAmount.Div(fromAmount).Round(ratePlaces)
+}
+
+// ReimbursementReceived records money received back for an expense, the one
+// of ExpenseID unless it is nil.
 type ReimbursementReceived struct {
+	ExpenseID   uuid.UUID
 	AccountID   uuid.UUID
 	From        string
 	Currency    values.Currency
@@ -89,11 +146,17 @@ func (e ReimbursementReceived) Content() any {
 	return e
 }
 
+// ExpectedReimbursementSet sets the amount expected back for an expense, from
+// the From counterparty into the account. The outstanding amount before and
+// after it are carried along, so that projections can apply the difference.
 type ExpectedReimbursementSet struct {
-	AccountID  uuid.UUID
-	Currency   values.Currency
-	Amount     decimal.Decimal
-	HappenedAt time.Time
+	AccountID           uuid.UUID
+	From                string
+	Currency            values.Currency
+	Amount              decimal.Decimal
+	PreviousOutstanding decimal.Decimal
+	Outstanding         decimal.Decimal
+	HappenedAt          time.Time
 }
 
 func (e ExpectedReimbursementSet) Type() string {
@@ -104,6 +167,69 @@ func (e ExpectedReimbursementSet) Content() any {
 	return e
 }
 
+// ExpenseReimbursed links the reimbursement of ReimbursementID to the expense,
+// reducing the amount still outstanding on the account expecting it.
+type ExpenseReimbursed struct {
+	ReimbursementID     uuid.UUID
+	AccountID           uuid.UUID
+	From                string
+	Currency            values.Currency
+	Amount              decimal.Decimal
+	PreviousOutstanding decimal.Decimal
+	Outstanding         decimal.Decimal
+	HappenedAt          time.Time
+}
+
+func (e ExpenseReimbursed) Type() string {
+	return TypeExpenseReimbursed
+}
+
+func (e ExpenseReimbursed) Content() any {
+	return e
+}
+
+// ReimbursementCancelled unlinks a voided reimbursement from the expense, so
+// that its amount is outstanding again.
+type ReimbursementCancelled struct {
+	ReimbursementID     uuid.UUID
+	AccountID           uuid.UUID
+	From                string
+	Currency            values.Currency
+	Amount              decimal.Decimal
+	PreviousOutstanding decimal.Decimal
+	Outstanding         decimal.Decimal
+}
+
+func (e ReimbursementCancelled) Type() string {
+	return TypeReimbursementCancelled
+}
+
+func (e ReimbursementCancelled) Content() any {
+	return e
+}
+
+// ReimbursementAmended corrects the amount of a reimbursement linked to the
+// expense, after the amount of the reimbursement itself was changed.
+type ReimbursementAmended struct {
+	ReimbursementID     uuid.UUID
+	AccountID           uuid.UUID
+	From                string
+	Currency            values.Currency
+	PreviousAmount      decimal.Decimal
+	Amount              decimal.Decimal
+	PreviousOutstanding decimal.Decimal
+	Outstanding         decimal.Decimal
+	HappenedAt          time.Time
+}
+
+func (e ReimbursementAmended) Type() string {
+	return TypeReimbursementAmended
+}
+
+func (e ReimbursementAmended) Content() any {
+	return e
+}
+
 type MoneyInvested struct {
 	AccountID     uuid.UUID
 	Ticker        string
@@ -122,3 +248,186 @@ func (e MoneyInvested) Type() string {
 func (e MoneyInvested) Content() any {
 	return e
 }
+
+// InvestmentSold sells Units of Ticker at Price. CostBasis is what the units
+// sold cost when they were bought, in PriceCurrency, so that the gain realized
+// is the proceeds less the cost basis and the fee.
+type InvestmentSold struct {
+	AccountID     uuid.UUID
+	Ticker        string
+	Units         decimal.Decimal
+	Price         decimal.Decimal
+	PriceCurrency values.Currency
+	Fee           decimal.Decimal
+	FeeCurrency   values.Currency
+	CostBasis     decimal.Decimal
+	HappenedAt    time.Time
+}
+
+func (e InvestmentSold) Type() string {
+	return TypeInvestmentSold
+}
+
+func (e InvestmentSold) Content() any {
+	return e
+}
+
+// DividendReceived pays Price per unit on Units of Ticker, less the Fee, such
+// as a withholding tax.
+type DividendReceived struct {
+	AccountID     uuid.UUID
+	Ticker        string
+	Units         decimal.Decimal
+	Price         decimal.Decimal
+	PriceCurrency values.Currency
+	Fee           decimal.Decimal
+	FeeCurrency   values.Currency
+	HappenedAt    time.Time
+}
+
+func (e DividendReceived) Type() string {
+	return TypeDividendReceived
+}
+
+func (e DividendReceived) Content() any {
+	return e
+}
+
+// InterestReceived pays Price per unit on Units of Ticker, such as the coupon
+// of a bond, less the Fee.
+type InterestReceived struct {
+	AccountID     uuid.UUID
+	Ticker        string
+	Units         decimal.Decimal
+	Price         decimal.Decimal
+	PriceCurrency values.Currency
+	Fee           decimal.Decimal
+	FeeCurrency   values.Currency
+	HappenedAt    time.Time
+}
+
+func (e InterestReceived) Type() string {
+	return TypeInterestReceived
+}
+
+func (e InterestReceived) Content() any {
+	return e
+}
+
+// StockSplit turns every unit of Ticker held into Ratio units, leaving their
+// cost basis unchanged. A ratio below one is a reverse split.
+type StockSplit struct {
+	AccountID  uuid.UUID
+	Ticker     string
+	Ratio      decimal.Decimal
+	HappenedAt time.Time
+}
+
+func (e StockSplit) Type() string {
+	return TypeStockSplit
+}
+
+func (e StockSplit) Content() any {
+	return e
+}
+
+// InvestmentFeeCharged charges a fee on the holding of Ticker alone, such as a
+// custody fee.
+type InvestmentFeeCharged struct {
+	AccountID   uuid.UUID
+	Ticker      string
+	Fee         decimal.Decimal
+	FeeCurrency values.Currency
+	HappenedAt  time.Time
+}
+
+func (e InvestmentFeeCharged) Type() string {
+	return TypeInvestmentFeeCharged
+}
+
+func (e InvestmentFeeCharged) Content() any {
+	return e
+}
+
+// AmountChanged corrects the amount of the entry of a transaction. The entry
+// and the previous amount are carried along, so that projections can apply
+// the difference.
+type AmountChanged struct {
+	AccountID      uuid.UUID
+	Currency       values.Currency
+	Side           values.Side
+	PreviousAmount decimal.Decimal
+	Amount         decimal.Decimal
+	HappenedAt     time.Time
+}
+
+func (e AmountChanged) Type() string {
+	return TypeAmountChanged
+}
+
+func (e AmountChanged) Content() any {
+	return e
+}
+
+// Redated corrects the date a transaction happened at, moving its entry from
+// the previous date to the new one.
+type Redated struct {
+	AccountID          uuid.UUID
+	Currency           values.Currency
+	Side               values.Side
+	Amount             decimal.Decimal
+	PreviousHappenedAt time.Time
+	HappenedAt         time.Time
+}
+
+func (e Redated) Type() string {
+	return TypeRedated
+}
+
+func (e Redated) Content() any {
+	return e
+}
+
+type Recategorized struct {
+	PreviousCategory string
+	Category         string
+}
+
+func (e Recategorized) Type() string {
+	return TypeRecategorized
+}
+
+func (e Recategorized) Content() any {
+	return e
+}
+
+type Redescribed struct {
+	PreviousDescription string
+	Description         string
+}
+
+func (e Redescribed) Type() string {
+	return TypeRedescribed
+}
+
+func (e Redescribed) Content() any {
+	return e
+}
+
+// TransactionVoided cancels a transaction recorded by mistake. Its entries, as
+// last amended, are carried along so that projections can reverse them.
+type TransactionVoided struct {
+	TransactionType values.TransactionType
+	Entries         []values.Entry
+	Category        string
+	Description     string
+	HappenedAt      time.Time
+}
+
+func (e TransactionVoided) Type() string {
+	return TypeTransactionVoided
+}
+
+func (e TransactionVoided) Content() any {
+	return e
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+// Factory builds the value each stored transaction event is decoded into.
+func Factory() map[string]func() any {
+	return map[string]func() any{
+		TypeMoneySpent:               func() any { return &MoneySpent{} },
+		TypeMoneyReceived:            func() any { return &MoneyReceived{} },
+		TypeMoneyTransfered:          func() any { return &MoneyTransfered{} },
+		TypeReimbursementReceived:    func() any { return &ReimbursementReceived{} },
+		TypeExpectedReimbursementSet: func() any { return &ExpectedReimbursementSet{} },
+		TypeMoneyInvested:            func() any { return &MoneyInvested{} },
+		TypeInvestmentSold:           func() any { return &InvestmentSold{} },
+		TypeDividendReceived:         func() any { return &DividendReceived{} },
+		TypeInterestReceived:         func() any { return &InterestReceived{} },
+		TypeStockSplit:               func() any { return &StockSplit{} },
+		TypeInvestmentFeeCharged:     func() any { return &InvestmentFeeCharged{} },
+		TypeAmountChanged:            func() any { return &AmountChanged{} },
+		TypeRedated:                  func() any { return &Redated{} },
+		TypeRecategorized:            func() any { return &Recategorized{} },
+		TypeRedescribed:              func() any { return &Redescribed{} },
+		TypeTransactionVoided:        func() any { return &TransactionVoided{} },
+		TypeExpenseSplit:             func() any { return &ExpenseSplit{} },
+		TypeExpenseReimbursed:        func() any { return &ExpenseReimbursed{} },
+		TypeReimbursementCancelled:   func() any { return &ReimbursementCancelled{} },
+		TypeReimbursementAmended:     func() any { return &ReimbursementAmended{} },
+	}
+}
+
+// Upcasters migrates the payloads stored by older versions of the transaction
+// events. Changing the shape of an event requires registering the step from
+// its previous schema version here, together with a fixture of that version
+// in testdata.
+func Upcasters() *event_store.Upcasters {
+	return event_store.NewUpcasters().
+		Register(TypeExpectedReimbursementSet, 1, upcastExpectedReimbursementSetOutstanding).
+		Register(TypeReimbursementReceived, 1, upcastReimbursementReceivedExpense).
+		Register(TypeMoneyTransfered, 1, upcastMoneyTransferedRate)
+}
+
+// upcastExpectedReimbursementSetOutstanding sets the outstanding amount of the
+// expected reimbursements stored before it was recorded, the whole amount
+// expected, from no counterparty in particular.
+func upcastExpectedReimbursementSetOutstanding(data json.RawMessage) (json.RawMessage, error) {
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+
+	var amount decimal.Decimal
+	if err := json.Unmarshal(payload["Amount"], &amount); err != nil {
+		return nil, err
+	}
+
+	outstanding, err := json.Marshal(amount)
+	if err != nil {
+		return nil, err
+	}
+	previousOutstanding, err := json.Marshal(decimal.Zero)
+	if err != nil {
+		return nil, err
+	}
+	payload["From"] = json.RawMessage(`""`)
+	payload["PreviousOutstanding"] = previousOutstanding
+	payload["Outstanding"] = outstanding
+
+	return json.Marshal(payload)
+}
+
+// upcastReimbursementReceivedExpense links the reimbursements stored before
+// they could be linked to no expense.
+func upcastReimbursementReceivedExpense(data json.RawMessage) (json.RawMessage, error) {
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+
+	expenseID, err := json.Marshal(uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	payload["ExpenseID"] = expenseID
+
+	return json.Marshal(payload)
+}
+
+// upcastMoneyTransferedRate sets the rate of the transfers stored before it
+// was recorded, the one implied by their amounts. Their reference rate and
+// spread stay unknown.
+func upcastMoneyTransferedRate(data json.RawMessage) (json.RawMessage, error) {
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+
+	var fromAmount, toAmount decimal.Decimal
+	if err := json.Unmarshal(payload["FromAmount"], &fromAmount); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(payload["ToAmount"], &toAmount); err != nil {
+		return nil, err
+	}
+
+	rate, err := json.Marshal(ImpliedRate(fromAmount, toAmount))
+	if err != nil {
+		return nil, err
+	}
+	payload["Rate"] = rate
+
+	return json.Marshal(payload)
+}
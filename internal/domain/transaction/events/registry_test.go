@@ -0,0 +1,345 @@
+package events_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/projections/accounts"
+	"github.com/somatom98/brokeli/internal/domain/transaction/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+// The fixtures in testdata/v<N> are payloads as they were stored with schema
+// version N. They must keep decoding into the current events.
+func TestHistoricalPayloads(t *testing.T) {
+	accountID := uuid.MustParse("6f1c2a5e-3b1d-4c7a-9e1f-2a3b4c5d6e7f")
+
+	tests := []struct {
+		fixture  string
+		expected event_store.Event
+	}{
+		{
+			fixture: "v1/MoneySpent.json",
+			expected: events.MoneySpent{
+				AccountID:   accountID,
+				Currency:    "EUR",
+				Amount:      decimal.RequireFromString("42.5"),
+				Category:    "Groceries",
+				Description: "Weekly shopping",
+				HappenedAt:  time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/ExpenseSplit.json",
+			expected: events.ExpenseSplit{
+				AccountID: accountID,
+				Currency:  "EUR",
+				Amount:    decimal.RequireFromString("42.5"),
+				Lines: []values.SplitLine{
+					{
+						AccountID: accountID,
+						Category:  "Groceries",
+						Amount:    decimal.RequireFromString("30"),
+					},
+					{
+						AccountID:   uuid.MustParse("0b8e7d6c-5a4f-4e3d-8c2b-1a0f9e8d7c6b"),
+						Category:    "Household",
+						Amount:      decimal.RequireFromString("12.5"),
+						Description: "Detergent",
+					},
+				},
+				Description: "Weekly shopping",
+				HappenedAt:  time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/MoneyReceived.json",
+			expected: events.MoneyReceived{
+				AccountID:   accountID,
+				Currency:    "EUR",
+				Amount:      decimal.RequireFromString("2500"),
+				Category:    "Salary",
+				Description: "March salary",
+				HappenedAt:  time.Date(2024, 3, 27, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/MoneyTransfered.json",
+			expected: events.MoneyTransfered{
+				FromAccountID: accountID,
+				FromCurrency:  "EUR",
+				FromAmount:    decimal.RequireFromString("100"),
+				ToAccountID:   uuid.MustParse("0b8e7d6c-5a4f-4e3d-8c2b-1a0f9e8d7c6b"),
+				ToCurrency:    "USD",
+				ToAmount:      decimal.RequireFromString("108.3"),
+				Rate:          decimal.RequireFromString("1.083"),
+				Category:      "Transfer",
+				Description:   "Savings",
+				HappenedAt:    time.Date(2024, 3, 2, 12, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/ReimbursementReceived.json",
+			expected: events.ReimbursementReceived{
+				AccountID:   accountID,
+				From:        "Alice",
+				Currency:    "EUR",
+				Amount:      decimal.RequireFromString("21.25"),
+				Category:    "Groceries",
+				Description: "Half of the shopping",
+				HappenedAt:  time.Date(2024, 3, 3, 18, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/ExpectedReimbursementSet.json",
+			expected: events.ExpectedReimbursementSet{
+				AccountID:           accountID,
+				Currency:            "EUR",
+				Amount:              decimal.RequireFromString("21.25"),
+				PreviousOutstanding: decimal.RequireFromString("0"),
+				Outstanding:         decimal.RequireFromString("21.25"),
+				HappenedAt:          time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/MoneyInvested.json",
+			expected: events.MoneyInvested{
+				AccountID:     accountID,
+				Ticker:        "VWCE",
+				Units:         decimal.RequireFromString("3"),
+				Price:         decimal.RequireFromString("112.4"),
+				PriceCurrency: "EUR",
+				Fee:           decimal.RequireFromString("1.5"),
+				FeeCurrency:   "EUR",
+				HappenedAt:    time.Date(2024, 3, 4, 15, 45, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/InvestmentSold.json",
+			expected: events.InvestmentSold{
+				AccountID:     accountID,
+				Ticker:        "VWCE",
+				Units:         decimal.RequireFromString("2"),
+				Price:         decimal.RequireFromString("120.1"),
+				PriceCurrency: "EUR",
+				Fee:           decimal.RequireFromString("1.5"),
+				FeeCurrency:   "EUR",
+				CostBasis:     decimal.RequireFromString("224.8"),
+				HappenedAt:    time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/DividendReceived.json",
+			expected: events.DividendReceived{
+				AccountID:     accountID,
+				Ticker:        "AAPL",
+				Units:         decimal.RequireFromString("10"),
+				Price:         decimal.RequireFromString("0.25"),
+				PriceCurrency: "USD",
+				Fee:           decimal.RequireFromString("0.38"),
+				FeeCurrency:   "USD",
+				HappenedAt:    time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/InterestReceived.json",
+			expected: events.InterestReceived{
+				AccountID:     accountID,
+				Ticker:        "BTP-2030",
+				Units:         decimal.RequireFromString("5"),
+				Price:         decimal.RequireFromString("17.5"),
+				PriceCurrency: "EUR",
+				Fee:           decimal.RequireFromString("0"),
+				FeeCurrency:   "EUR",
+				HappenedAt:    time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/StockSplit.json",
+			expected: events.StockSplit{
+				AccountID:  accountID,
+				Ticker:     "NVDA",
+				Ratio:      decimal.RequireFromString("10"),
+				HappenedAt: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/InvestmentFeeCharged.json",
+			expected: events.InvestmentFeeCharged{
+				AccountID:   accountID,
+				Ticker:      "VWCE",
+				Fee:         decimal.RequireFromString("2"),
+				FeeCurrency: "EUR",
+				HappenedAt:  time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/AmountChanged.json",
+			expected: events.AmountChanged{
+				AccountID:      accountID,
+				Currency:       "EUR",
+				Side:           values.Side_Debit,
+				PreviousAmount: decimal.RequireFromString("42.5"),
+				Amount:         decimal.RequireFromString("45"),
+				HappenedAt:     time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/Redated.json",
+			expected: events.Redated{
+				AccountID:          accountID,
+				Currency:           "EUR",
+				Side:               values.Side_Debit,
+				Amount:             decimal.RequireFromString("42.5"),
+				PreviousHappenedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+				HappenedAt:         time.Date(2024, 2, 28, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/Recategorized.json",
+			expected: events.Recategorized{
+				PreviousCategory: "Groceries",
+				Category:         "Household",
+			},
+		},
+		{
+			fixture: "v1/Redescribed.json",
+			expected: events.Redescribed{
+				PreviousDescription: "Weekly shopping",
+				Description:         "Weekly shopping and cleaning supplies",
+			},
+		},
+		{
+			fixture: "v1/ExpenseReimbursed.json",
+			expected: events.ExpenseReimbursed{
+				ReimbursementID:     uuid.MustParse("3c9d8e7f-6a5b-4c4d-9e3f-2a1b0c9d8e7f"),
+				AccountID:           accountID,
+				From:                "Alice",
+				Currency:            "EUR",
+				Amount:              decimal.RequireFromString("21.25"),
+				PreviousOutstanding: decimal.RequireFromString("21.25"),
+				Outstanding:         decimal.RequireFromString("0"),
+				HappenedAt:          time.Date(2024, 3, 3, 18, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/ReimbursementCancelled.json",
+			expected: events.ReimbursementCancelled{
+				ReimbursementID:     uuid.MustParse("3c9d8e7f-6a5b-4c4d-9e3f-2a1b0c9d8e7f"),
+				AccountID:           accountID,
+				From:                "Alice",
+				Currency:            "EUR",
+				Amount:              decimal.RequireFromString("21.25"),
+				PreviousOutstanding: decimal.RequireFromString("0"),
+				Outstanding:         decimal.RequireFromString("21.25"),
+			},
+		},
+		{
+			fixture: "v1/ReimbursementAmended.json",
+			expected: events.ReimbursementAmended{
+				ReimbursementID:     uuid.MustParse("3c9d8e7f-6a5b-4c4d-9e3f-2a1b0c9d8e7f"),
+				AccountID:           accountID,
+				From:                "Alice",
+				Currency:            "EUR",
+				PreviousAmount:      decimal.RequireFromString("21.25"),
+				Amount:              decimal.RequireFromString("20"),
+				PreviousOutstanding: decimal.RequireFromString("0"),
+				Outstanding:         decimal.RequireFromString("1.25"),
+				HappenedAt:          time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			fixture: "v1/TransactionVoided.json",
+			expected: events.TransactionVoided{
+				TransactionType: values.TransactionType_Expense,
+				Entries: []values.Entry{
+					{
+						AccountID: accountID,
+						Currency:  "EUR",
+						Amount:    decimal.RequireFromString("42.5"),
+						Side:      values.Side_Debit,
+					},
+				},
+				Category:    "Groceries",
+				Description: "Weekly shopping",
+				HappenedAt:  time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	t.Run("should have a fixture for every event type", func(t *testing.T) {
+		for eventType := range events.Factory() {
+			_, err := os.Stat(filepath.Join("testdata", "v1", eventType+".json"))
+			assert.NoError(t, err, eventType)
+		}
+	})
+
+	for _, tt := range tests {
+		t.Run("should decode "+tt.fixture+" into the current event", func(t *testing.T) {
+			// arrange
+			data, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			require.NoError(t, err)
+
+			// act
+			content, err := event_store.UnmarshalEvent(events.Factory(), events.Upcasters(), tt.expected.Type(), 1, data)
+
+			// assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, content)
+		})
+	}
+}
+
+func TestHistoricalPayloads_Projected(t *testing.T) {
+	accountID := uuid.MustParse("6f1c2a5e-3b1d-4c7a-9e1f-2a3b4c5d6e7f")
+
+	t.Run("should project the receivable of a v1 expected reimbursement", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		data, err := os.ReadFile(filepath.Join("testdata", "v1", "ExpectedReimbursementSet.json"))
+		require.NoError(t, err)
+		content, err := event_store.UnmarshalEvent(events.Factory(), events.Upcasters(), events.TypeExpectedReimbursementSet, 1, data)
+		require.NoError(t, err)
+		projection := accounts.NewProjection(accounts.NewInMemoryRepository())
+
+		// act
+		err = projection.ApplyExpectedReimbursementSet(ctx, content.(events.ExpectedReimbursementSet))
+
+		// assert
+		require.NoError(t, err)
+		all, err := projection.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "21.25", all[accountID].ExpectedReimbursements["EUR"].String())
+	})
+
+	t.Run("should project a v1 reimbursement linked to no expense", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		data, err := os.ReadFile(filepath.Join("testdata", "v1", "ReimbursementReceived.json"))
+		require.NoError(t, err)
+		content, err := event_store.UnmarshalEvent(events.Factory(), events.Upcasters(), events.TypeReimbursementReceived, 1, data)
+		require.NoError(t, err)
+		reimbursement := content.(events.ReimbursementReceived)
+		projection := accounts.NewProjection(accounts.NewInMemoryRepository())
+
+		// act
+		err = projection.ApplyReimbursementReceived(ctx, reimbursement)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Nil, reimbursement.ExpenseID)
+		all, err := projection.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "21.25", all[accountID].Balance["EUR"].String())
+		assert.True(t, all[accountID].ExpectedReimbursements["EUR"].IsZero())
+	})
+}
@@ -0,0 +1,34 @@
+package transaction
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+// Revision is the state of a transaction after one of its records.
+type Revision struct {
+	Record      event_store.Record
+	Transaction Transaction
+}
+
+// History replays the records of a transaction one at a time, returning its
+// state after each of them.
+func History(id uuid.UUID, records []event_store.Record) ([]Revision, error) {
+	t := New(id)
+	revisions := make([]Revision, 0, len(records))
+	for _, record := range records {
+		if err := t.Hydrate([]event_store.Record{record}); err != nil {
+			return nil, err
+		}
+
+		revision := *t
+		revision.Entries = slices.Clone(t.Entries)
+		revisions = append(revisions, Revision{
+			Record:      record,
+			Transaction: revision,
+		})
+	}
+	return revisions, nil
+}
@@ -0,0 +1,303 @@
+package valuation
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/price"
+	"github.com/somatom98/brokeli/internal/domain/projections/holdings"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+// returnPlaces is the number of decimal places returns are rounded to.
+const returnPlaces = 6
+
+type Holdings interface {
+	ListHoldings(ctx context.Context) ([]holdings.Holding, error)
+	ListHoldingsByAccount(ctx context.Context, accountID uuid.UUID) ([]holdings.Holding, error)
+	ListTrades(ctx context.Context, accountID uuid.UUID, ticker string) ([]holdings.Trade, error)
+}
+
+type Prices interface {
+	Latest(ctx context.Context, ticker string, on time.Time) (price.Price, error)
+}
+
+// HoldingValuation is a holding valued at the latest price of its ticker.
+// PricedOn is the date of that price, nil if the ticker has none.
+type HoldingValuation struct {
+	Ticker             string          `json:"ticker"`
+	Currency           values.Currency `json:"currency"`
+	Units              decimal.Decimal `json:"units"`
+	Price              decimal.Decimal `json:"price"`
+	PricedOn           *time.Time      `json:"priced_on,omitempty"`
+	MarketValue        decimal.Decimal `json:"market_value"`
+	CostBasis          decimal.Decimal `json:"cost_basis"`
+	UnrealizedPL       decimal.Decimal `json:"unrealized_pl"`
+	RealizedGain       decimal.Decimal `json:"realized_gain"`
+	TimeWeightedReturn decimal.Decimal `json:"time_weighted_return"`
+}
+
+// AccountValuation sums the valuations of the holdings of an account in a
+// currency.
+type AccountValuation struct {
+	AccountID          uuid.UUID          `json:"account_id"`
+	Currency           values.Currency    `json:"currency"`
+	MarketValue        decimal.Decimal    `json:"market_value"`
+	CostBasis          decimal.Decimal    `json:"cost_basis"`
+	UnrealizedPL       decimal.Decimal    `json:"unrealized_pl"`
+	RealizedGain       decimal.Decimal    `json:"realized_gain"`
+	TimeWeightedReturn decimal.Decimal    `json:"time_weighted_return"`
+	Holdings           []HoldingValuation `json:"holdings"`
+}
+
+// Service values holdings at market prices. A ticker is priced at its latest
+// stored price, or at the one of its latest purchase or sale when it is more
+// recent, adjusted for the splits since.
+type Service struct {
+	holdings Holdings
+	prices   Prices
+}
+
+func NewService(holdings Holdings, prices Prices) *Service {
+	return &Service{
+		holdings: holdings,
+		prices:   prices,
+	}
+}
+
+// ValueAccount values the holdings of an account, grouped by currency, with
+// their time-weighted return since from, or since they were first bought if
+// from is zero.
+func (s *Service) ValueAccount(ctx context.Context, accountID uuid.UUID, from time.Time) ([]AccountValuation, error) {
+	all, err := s.holdings.ListHoldingsByAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.value(ctx, all, from, time.Now())
+}
+
+// ValueAll values the holdings of every account, as ValueAccount does.
+func (s *Service) ValueAll(ctx context.Context, from time.Time) ([]AccountValuation, error) {
+	all, err := s.holdings.ListHoldings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.value(ctx, all, from, time.Now())
+}
+
+type accountKey struct {
+	accountID uuid.UUID
+	currency  values.Currency
+}
+
+func (s *Service) value(ctx context.Context, all []holdings.Holding, from, to time.Time) ([]AccountValuation, error) {
+	keys := make([]accountKey, 0)
+	accounts := make(map[accountKey]*AccountValuation)
+	trades := make(map[accountKey][]holdings.Trade)
+
+	for _, holding := range all {
+		holdingTrades, err := s.holdings.ListTrades(ctx, holding.AccountID, holding.Ticker)
+		if err != nil {
+			return nil, err
+		}
+
+		valuation, err := s.valueHolding(ctx, holding, holdingTrades, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		key := accountKey{holding.AccountID, holding.Currency}
+		account, ok := accounts[key]
+		if !ok {
+			account = &AccountValuation{
+				AccountID: holding.AccountID,
+				Currency:  holding.Currency,
+				Holdings:  make([]HoldingValuation, 0),
+			}
+			accounts[key] = account
+			keys = append(keys, key)
+		}
+
+		account.MarketValue = account.MarketValue.Add(valuation.MarketValue)
+		account.CostBasis = account.CostBasis.Add(valuation.CostBasis)
+		account.UnrealizedPL = account.UnrealizedPL.Add(valuation.UnrealizedPL)
+		account.RealizedGain = account.RealizedGain.Add(valuation.RealizedGain)
+		account.Holdings = append(account.Holdings, valuation)
+		trades[key] = append(trades[key], holdingTrades...)
+	}
+
+	valuations := make([]AccountValuation, 0, len(keys))
+	for _, key := range keys {
+		twr, err := s.timeWeightedReturn(ctx, trades[key], from, to)
+		if err != nil {
+			return nil, err
+		}
+		accounts[key].TimeWeightedReturn = twr
+		valuations = append(valuations, *accounts[key])
+	}
+
+	return valuations, nil
+}
+
+func (s *Service) valueHolding(ctx context.Context, holding holdings.Holding, trades []holdings.Trade, from, to time.Time) (HoldingValuation, error) {
+	marketPrice, pricedOn, err := s.priceOn(ctx, holding.Ticker, to, trades)
+	if err != nil {
+		return HoldingValuation{}, err
+	}
+
+	twr, err := s.timeWeightedReturn(ctx, trades, from, to)
+	if err != nil {
+		return HoldingValuation{}, err
+	}
+
+	marketValue := holding.Units.Mul(marketPrice)
+	valuation := HoldingValuation{
+		Ticker:             holding.Ticker,
+		Currency:           holding.Currency,
+		Units:              holding.Units,
+		Price:              marketPrice,
+		MarketValue:        marketValue,
+		CostBasis:          holding.CostBasis,
+		UnrealizedPL:       marketValue.Sub(holding.CostBasis),
+		RealizedGain:       holding.RealizedGain,
+		TimeWeightedReturn: twr,
+	}
+	if !pricedOn.IsZero() {
+		valuation.PricedOn = &pricedOn
+	}
+
+	return valuation, nil
+}
+
+// timeWeightedReturn chains the returns of the trades' holdings between their
+// purchases and sales from one time to another, so that money put in or taken
+// out does not count as a return. Incomes paid count as returns of the period
+// they are paid in.
+func (s *Service) timeWeightedReturn(ctx context.Context, trades []holdings.Trade, from, to time.Time) (decimal.Decimal, error) {
+	trades = slices.Clone(trades)
+	slices.SortStableFunc(trades, func(a, b holdings.Trade) int {
+		return a.HappenedAt.Compare(b.HappenedAt)
+	})
+
+	units := make(map[string]decimal.Decimal)
+	i := 0
+	for ; i < len(trades) && !trades[i].HappenedAt.After(from); i++ {
+		applyTrade(units, trades[i])
+	}
+
+	start, err := s.marketValue(ctx, units, from, trades)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	growth := decimal.NewFromInt(1)
+	income := decimal.Zero
+	for ; i < len(trades) && !trades[i].HappenedAt.After(to); i++ {
+		trade := trades[i]
+		switch trade.Kind {
+		case holdings.TradeKind_Income:
+			income = income.Add(trade.Units.Mul(trade.Price))
+			continue
+		case holdings.TradeKind_Split:
+			applyTrade(units, trade)
+			continue
+		}
+
+		end, err := s.marketValue(ctx, units, trade.HappenedAt, trades)
+		if err != nil {
+			return decimal.Decimal{}, err
+		}
+		if start.IsPositive() {
+			growth = growth.Mul(end.Add(income)).Div(start)
+		}
+
+		applyTrade(units, trade)
+		income = decimal.Zero
+
+		start, err = s.marketValue(ctx, units, trade.HappenedAt, trades)
+		if err != nil {
+			return decimal.Decimal{}, err
+		}
+	}
+
+	end, err := s.marketValue(ctx, units, to, trades)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	if start.IsPositive() {
+		growth = growth.Mul(end.Add(income)).Div(start)
+	}
+
+	return growth.Sub(decimal.NewFromInt(1)).Round(returnPlaces), nil
+}
+
+func (s *Service) marketValue(ctx context.Context, units map[string]decimal.Decimal, at time.Time, trades []holdings.Trade) (decimal.Decimal, error) {
+	value := decimal.Zero
+	for ticker, held := range units {
+		if held.IsZero() {
+			continue
+		}
+
+		marketPrice, _, err := s.priceOn(ctx, ticker, at, trades)
+		if err != nil {
+			return decimal.Decimal{}, err
+		}
+		value = value.Add(held.Mul(marketPrice))
+	}
+	return value, nil
+}
+
+// priceOn returns the price of a ticker at a time and the date it is of, or a
+// zero price and date if the ticker has none yet.
+func (s *Service) priceOn(ctx context.Context, ticker string, at time.Time, trades []holdings.Trade) (decimal.Decimal, time.Time, error) {
+	var quoted decimal.Decimal
+	var quotedOn time.Time
+
+	p, err := s.prices.Latest(ctx, ticker, at)
+	switch {
+	case err == nil:
+		quoted, quotedOn = p.Price, p.Date
+	case !errors.Is(err, price.ErrNotFound):
+		return decimal.Decimal{}, time.Time{}, err
+	}
+
+	ratio := decimal.NewFromInt(1)
+	for i := len(trades) - 1; i >= 0; i-- {
+		trade := trades[i]
+		if trade.Ticker != ticker || trade.HappenedAt.After(at) {
+			continue
+		}
+		if !quotedOn.IsZero() && !price.Day(trade.HappenedAt).After(quotedOn) {
+			break
+		}
+
+		switch trade.Kind {
+		case holdings.TradeKind_Split:
+			ratio = ratio.Mul(trade.Ratio)
+		case holdings.TradeKind_Buy, holdings.TradeKind_Sell:
+			return trade.Price.Div(ratio), price.Day(trade.HappenedAt), nil
+		}
+	}
+
+	if quotedOn.IsZero() {
+		return decimal.Zero, time.Time{}, nil
+	}
+	return quoted.Div(ratio), quotedOn, nil
+}
+
+func applyTrade(units map[string]decimal.Decimal, trade holdings.Trade) {
+	switch trade.Kind {
+	case holdings.TradeKind_Buy:
+		units[trade.Ticker] = units[trade.Ticker].Add(trade.Units)
+	case holdings.TradeKind_Sell:
+		units[trade.Ticker] = units[trade.Ticker].Sub(trade.Units)
+	case holdings.TradeKind_Split:
+		units[trade.Ticker] = units[trade.Ticker].Mul(trade.Ratio)
+	}
+}
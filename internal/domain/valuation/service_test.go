@@ -0,0 +1,163 @@
+package valuation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/price"
+	"github.com/somatom98/brokeli/internal/domain/projections/holdings"
+	"github.com/somatom98/brokeli/internal/domain/valuation"
+)
+
+func TestService_ValueAccount(t *testing.T) {
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	trade := func(kind holdings.TradeKind, units, tradePrice string, happenedAt time.Time) holdings.Trade {
+		return holdings.Trade{
+			TransactionID: uuid.New(),
+			AccountID:     accountID,
+			Ticker:        "VWCE",
+			Kind:          kind,
+			Units:         decimal.RequireFromString(units),
+			Price:         decimal.RequireFromString(tradePrice),
+			Currency:      "EUR",
+			HappenedAt:    happenedAt,
+		}
+	}
+	split := func(ratio string, happenedAt time.Time) holdings.Trade {
+		splitTrade := trade(holdings.TradeKind_Split, "0", "0", happenedAt)
+		splitTrade.Ratio = decimal.RequireFromString(ratio)
+		return splitTrade
+	}
+	quote := func(value string, date time.Time) price.Price {
+		return price.Price{Ticker: "VWCE", Date: date, Price: decimal.RequireFromString(value), Currency: "EUR"}
+	}
+
+	tests := []struct {
+		name        string
+		units       string
+		costBasis   string
+		trades      []holdings.Trade
+		prices      []price.Price
+		from        time.Time
+		price       string
+		pricedOn    time.Time
+		marketValue string
+		twr         string
+	}{
+		{
+			name:      "should chain the returns of a buy then a sell across price changes",
+			units:     "5",
+			costBasis: "500",
+			trades: []holdings.Trade{
+				trade(holdings.TradeKind_Buy, "10", "100", day(1, 2)),
+				trade(holdings.TradeKind_Sell, "5", "120", day(3, 1)),
+			},
+			prices:      []price.Price{quote("100", day(1, 2)), quote("132", day(6, 1))},
+			price:       "132",
+			pricedOn:    day(6, 1),
+			marketValue: "660",
+			twr:         "0.32",
+		},
+		{
+			name:      "should count an income as a return of the period it is paid in",
+			units:     "10",
+			costBasis: "1000",
+			trades: []holdings.Trade{
+				trade(holdings.TradeKind_Buy, "10", "100", day(1, 2)),
+				trade(holdings.TradeKind_Income, "10", "1", day(3, 1)),
+			},
+			prices:      []price.Price{quote("100", day(1, 2)), quote("110", day(6, 1))},
+			price:       "110",
+			pricedOn:    day(6, 1),
+			marketValue: "1100",
+			twr:         "0.11",
+		},
+		{
+			name:      "should adjust a price quoted before a split",
+			units:     "20",
+			costBasis: "1000",
+			trades: []holdings.Trade{
+				trade(holdings.TradeKind_Buy, "10", "100", day(1, 2)),
+				split("2", day(2, 1)),
+			},
+			prices:      []price.Price{quote("100", day(1, 10))},
+			price:       "50",
+			pricedOn:    day(1, 10),
+			marketValue: "1000",
+			twr:         "0",
+		},
+		{
+			name:      "should fall back to the price of the latest trade without stored prices",
+			units:     "10",
+			costBasis: "1000",
+			trades: []holdings.Trade{
+				trade(holdings.TradeKind_Buy, "10", "100", day(1, 2)),
+			},
+			price:       "100",
+			pricedOn:    day(1, 2),
+			marketValue: "1000",
+			twr:         "0",
+		},
+		{
+			name:      "should compute the return from a date after the first trade",
+			units:     "10",
+			costBasis: "1000",
+			trades: []holdings.Trade{
+				trade(holdings.TradeKind_Buy, "10", "100", day(1, 2)),
+			},
+			prices:      []price.Price{quote("100", day(1, 2)), quote("110", day(2, 1)), quote("121", day(6, 1))},
+			from:        day(2, 1),
+			price:       "121",
+			pricedOn:    day(6, 1),
+			marketValue: "1210",
+			twr:         "0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			holdingsRepository := holdings.NewInMemoryRepository()
+			require.NoError(t, holdingsRepository.SaveHolding(ctx, holdings.Holding{
+				AccountID: accountID,
+				Ticker:    "VWCE",
+				Currency:  "EUR",
+				Units:     decimal.RequireFromString(tt.units),
+				CostBasis: decimal.RequireFromString(tt.costBasis),
+			}))
+			for _, trade := range tt.trades {
+				require.NoError(t, holdingsRepository.CreateTrade(ctx, trade))
+			}
+			priceRepository := price.NewInMemoryRepository()
+			require.NoError(t, priceRepository.Save(ctx, tt.prices...))
+			service := valuation.NewService(holdingsRepository, priceRepository)
+
+			// act
+			valuations, err := service.ValueAccount(ctx, accountID, tt.from)
+
+			// assert
+			require.NoError(t, err)
+			require.Len(t, valuations, 1)
+			require.Len(t, valuations[0].Holdings, 1)
+
+			holding := valuations[0].Holdings[0]
+			assert.Equal(t, tt.price, holding.Price.String())
+			require.NotNil(t, holding.PricedOn)
+			assert.Equal(t, tt.pricedOn, *holding.PricedOn)
+			assert.Equal(t, tt.marketValue, holding.MarketValue.String())
+			assert.Equal(t, tt.twr, holding.TimeWeightedReturn.String())
+			assert.Equal(t, tt.twr, valuations[0].TimeWeightedReturn.String())
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package values
+
+import (
+	"slices"
+	"strings"
+)
+
+// AccountDetails describe an account beyond its name. The account number is
+// only ever kept masked.
+type AccountDetails struct {
+	Institution   string
+	AccountNumber string
+	Owner         string
+	Icon          string
+	Color         string
+	Tags          []string
+}
+
+// Normalized returns the details with the account number masked and the tags
+// trimmed, without blanks nor duplicates.
+func (d AccountDetails) Normalized() AccountDetails {
+	d.AccountNumber = MaskAccountNumber(d.AccountNumber)
+
+	var tags []string
+	for _, tag := range d.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	d.Tags = tags
+
+	return d
+}
+
+func (d AccountDetails) Equal(other AccountDetails) bool {
+	return d.Institution == other.Institution &&
+		d.AccountNumber == other.AccountNumber &&
+		d.Owner == other.Owner &&
+		d.Icon == other.Icon &&
+		d.Color == other.Color &&
+		slices.Equal(d.Tags, other.Tags)
+}
+
+// MaskAccountNumber hides all but the last four characters of an IBAN or
+// account number, ignoring spaces. Masking a masked number leaves it as is.
+func MaskAccountNumber(number string) string {
+	number = strings.ReplaceAll(number, " ", "")
+	if len(number) <= 4 {
+		return number
+	}
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
@@ -0,0 +1,27 @@
+package values
+
+import "slices"
+
+type AccountKind string
+
+const (
+	AccountKind_Checking   AccountKind = "CHECKING"
+	AccountKind_Savings    AccountKind = "SAVINGS"
+	AccountKind_CreditCard AccountKind = "CREDIT_CARD"
+	AccountKind_Cash       AccountKind = "CASH"
+	AccountKind_Brokerage  AccountKind = "BROKERAGE"
+	AccountKind_Loan       AccountKind = "LOAN"
+)
+
+var accountKinds = []AccountKind{
+	AccountKind_Checking,
+	AccountKind_Savings,
+	AccountKind_CreditCard,
+	AccountKind_Cash,
+	AccountKind_Brokerage,
+	AccountKind_Loan,
+}
+
+func (k AccountKind) IsValid() bool {
+	return slices.Contains(accountKinds, k)
+}
@@ -1,3 +1,22 @@
 package values
 
 type Currency string
+
+// minorUnits lists the ISO 4217 currencies that do not have two decimal
+// places.
+var minorUnits = map[Currency]int32{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0,
+	"XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// MinorUnits returns the number of decimal places amounts in the currency are
+// expressed in, two unless ISO 4217 says otherwise.
+func (c Currency) MinorUnits() int32 {
+	if places, ok := minorUnits[c]; ok {
+		return places
+	}
+	return 2
+}
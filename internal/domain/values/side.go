@@ -1,8 +1,19 @@
 package values
 
+import "github.com/shopspring/decimal"
+
 type Side int
 
 const (
 	Side_Credit Side = iota
 	Side_Debit
 )
+
+// Signed returns the amount of an entry on the side as it affects the balance
+// of its account: negative for debits.
+func (s Side) Signed(amount decimal.Decimal) decimal.Decimal {
+	if s == Side_Debit {
+		return amount.Neg()
+	}
+	return amount
+}
@@ -0,0 +1,15 @@
+package values
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// SplitLine is the part of a split expense spent on a category, possibly from
+// another account than the rest of it.
+type SplitLine struct {
+	AccountID   uuid.UUID
+	Category    string
+	Amount      decimal.Decimal
+	Description string
+}
@@ -11,4 +11,9 @@ const (
 	TransactionType_Deposit               TransactionType = "DEPOSIT"
 	TransactionType_Withdrawal            TransactionType = "WITHDRAWAL"
 	TransactionType_Investment            TransactionType = "INVESTMENT"
+	TransactionType_Sale                  TransactionType = "SALE"
+	TransactionType_Dividend              TransactionType = "DIVIDEND"
+	TransactionType_Interest              TransactionType = "INTEREST"
+	TransactionType_StockSplit            TransactionType = "STOCK_SPLIT"
+	TransactionType_InvestmentFee         TransactionType = "INVESTMENT_FEE"
 )
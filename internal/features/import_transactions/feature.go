@@ -14,6 +14,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
 )
 
 var ErrEmptyAmount = errors.New("empty amounts")
@@ -21,7 +22,7 @@ var ErrEmptyAmount = errors.New("empty amounts")
 type TransactionDispatcher interface {
 	RegisterTransfer(ctx context.Context, id uuid.UUID, fromAccountID uuid.UUID, fromCurrency values.Currency, fromAmount decimal.Decimal, toAccountID uuid.UUID, toCurrency values.Currency, toAmount decimal.Decimal, category, description string, happenedAt time.Time) error
 	RegisterExpense(ctx context.Context, id uuid.UUID, accountID uuid.UUID, currency values.Currency, amount decimal.Decimal, category, description string, happenedAt time.Time) error
-	RegisterReimbursement(ctx context.Context, id uuid.UUID, accountID uuid.UUID, from string, currency values.Currency, amount decimal.Decimal, category string, description string, happenedAt time.Time) error
+	RegisterReimbursement(ctx context.Context, id uuid.UUID, expenseID uuid.UUID, accountID uuid.UUID, from string, currency values.Currency, amount decimal.Decimal, category string, description string, happenedAt time.Time) error
 	RegisterIncome(ctx context.Context, id uuid.UUID, accountID uuid.UUID, currency values.Currency, amount decimal.Decimal, category, description string, happenedAt time.Time) error
 }
 
@@ -50,6 +51,8 @@ func New(
 }
 
 func (f *Feature) ImportTransactions(ctx context.Context, filePath string) error {
+	ctx = event_store.WithSource(ctx, event_store.SourceImport)
+
 	file, err := os.Open(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to open file: %w", err)
@@ -137,6 +140,7 @@ func (f *Feature) ImportTransactions(ctx context.Context, filePath string) error
 			err = f.dispatcher.RegisterReimbursement(
 				ctx,
 				uuid.Must(uuid.NewV7()),
+				uuid.Nil,
 				t.credit.AccountID,
 				fromStr,
 				t.credit.Currency,
@@ -133,7 +133,7 @@ func (m *DispatcherMock) RegisterTransfer(ctx context.Context, id uuid.UUID, fro
 	return nil
 }
 
-func (m *DispatcherMock) RegisterReimbursement(ctx context.Context, id uuid.UUID, accountID uuid.UUID, from string, currency values.Currency, amount decimal.Decimal, category string, description string, happenedAt time.Time) error {
+func (m *DispatcherMock) RegisterReimbursement(ctx context.Context, id uuid.UUID, expenseID uuid.UUID, accountID uuid.UUID, from string, currency values.Currency, amount decimal.Decimal, category string, description string, happenedAt time.Time) error {
 	m.Reimbursements = append(m.Reimbursements, reimbursementCall{
 		AccountID:   accountID,
 		From:        from,
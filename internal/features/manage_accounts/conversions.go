@@ -0,0 +1,99 @@
+package manage_accounts
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/projections/accounts"
+	"github.com/somatom98/brokeli/internal/domain/projections/balance_updates"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+// convertBalances sums the balances in every currency into the base one, at
+// the rates of a date.
+func (f *Feature) convertBalances(ctx context.Context, balances map[values.Currency]decimal.Decimal, base values.Currency, on time.Time) (map[values.Currency]decimal.Decimal, error) {
+	total := decimal.Zero
+	for currency, amount := range balances {
+		converted, err := f.converter.Convert(ctx, amount, currency, base, on)
+		if err != nil {
+			return nil, err
+		}
+		total = total.Add(converted)
+	}
+
+	return map[values.Currency]decimal.Decimal{base: total}, nil
+}
+
+// convertAccounts converts the balances and the expected reimbursements of the
+// accounts to the base currency, at today's rates.
+func (f *Feature) convertAccounts(ctx context.Context, all map[uuid.UUID]accounts.Account, base values.Currency) (map[uuid.UUID]accounts.Account, error) {
+	now := time.Now()
+
+	converted := make(map[uuid.UUID]accounts.Account, len(all))
+	for id, acc := range all {
+		balance, err := f.convertBalances(ctx, acc.Balance, base, now)
+		if err != nil {
+			return nil, err
+		}
+		expectedReimbursements, err := f.convertBalances(ctx, acc.ExpectedReimbursements, base, now)
+		if err != nil {
+			return nil, err
+		}
+
+		acc.Balance = balance
+		acc.ExpectedReimbursements = expectedReimbursements
+		converted[id] = acc
+	}
+
+	return converted, nil
+}
+
+// convertPeriods converts the monthly balances to the base currency, at the
+// rates of the end of their month, summing the ones of the same month.
+func (f *Feature) convertPeriods(ctx context.Context, periods []balance_updates.BalancePeriod, base values.Currency) ([]balance_updates.BalancePeriod, error) {
+	converted := make([]balance_updates.BalancePeriod, 0, len(periods))
+	months := make(map[int64]int)
+	for _, period := range periods {
+		amount, err := f.converter.Convert(ctx, period.Amount, period.Currency, base, period.Month.AddDate(0, 1, -1))
+		if err != nil {
+			return nil, err
+		}
+
+		if i, ok := months[period.Month.Unix()]; ok {
+			converted[i].Amount = converted[i].Amount.Add(amount)
+			continue
+		}
+
+		months[period.Month.Unix()] = len(converted)
+		converted = append(converted, balance_updates.BalancePeriod{
+			Month:    period.Month,
+			Currency: base,
+			Amount:   amount,
+		})
+	}
+
+	return converted, nil
+}
+
+// convertDistributions converts the movements of an account to the base
+// currency, at the rates of their value date.
+func (f *Feature) convertDistributions(ctx context.Context, distributions []balance_updates.AccountDistribution, base values.Currency) ([]balance_updates.AccountDistribution, error) {
+	converted := make([]balance_updates.AccountDistribution, 0, len(distributions))
+	for _, distribution := range distributions {
+		amounts := []*decimal.Decimal{&distribution.Amount, &distribution.SystemAmount, &distribution.OtherAmount}
+		for _, amount := range amounts {
+			value, err := f.converter.Convert(ctx, *amount, distribution.Currency, base, distribution.ValueDate)
+			if err != nil {
+				return nil, err
+			}
+			*amount = value
+		}
+
+		distribution.Currency = base
+		converted = append(converted, distribution)
+	}
+
+	return converted, nil
+}
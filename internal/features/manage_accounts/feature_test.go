@@ -2,24 +2,19 @@ package manage_accounts_test
 
 import (
 	"context"
-	"net/http"
-	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
-	"github.com/somatom98/brokeli/internal/domain/transaction"
-	transaction_events "github.com/somatom98/brokeli/internal/domain/transaction/events"
+	"github.com/somatom98/brokeli/internal/domain/account"
 	"github.com/somatom98/brokeli/internal/domain/values"
-	"github.com/somatom98/brokeli/internal/features/manage_accounts"
-	"github.com/somatom98/brokeli/pkg/event_store"
-	"github.com/stretchr/testify/assert"
 )
 
 type DispatcherMock struct {
 	Opens       []openCall
 	Withdrawals []withdrawalCall
 	Deposits    []depositCall
+	Versions    map[uuid.UUID]uint64
 }
 
 type openCall struct {
@@ -50,7 +45,31 @@ func (m *DispatcherMock) Open(ctx context.Context, id uuid.UUID, name string, cu
 	})
 	return nil
 }
+func (m *DispatcherMock) Reopen(ctx context.Context, id uuid.UUID, happenedAt time.Time) error {
+	return nil
+}
+
+func (m *DispatcherMock) SetOverdraftLimit(ctx context.Context, id uuid.UUID, currency values.Currency, limit decimal.Decimal, happenedAt time.Time) error {
+	return nil
+}
+
+func (m *DispatcherMock) RemoveOverdraftLimit(ctx context.Context, id uuid.UUID, currency values.Currency, happenedAt time.Time) error {
+	return nil
+}
+
+func (m *DispatcherMock) SetStatementCycle(ctx context.Context, id uuid.UUID, cycle account.StatementCycle, happenedAt time.Time) error {
+	return nil
+}
+
+func (m *DispatcherMock) CloseStatement(ctx context.Context, id uuid.UUID, asOf time.Time, ledger account.StatementLedger, happenedAt time.Time) error {
+	return nil
+}
+
 func (m *DispatcherMock) UpdateName(ctx context.Context, id uuid.UUID, name string, happenedAt time.Time) error { return nil }
+func (m *DispatcherMock) Update(ctx context.Context, id uuid.UUID, update account.Update, happenedAt time.Time) error {
+	return nil
+}
+
 func (m *DispatcherMock) Deposit(ctx context.Context, id uuid.UUID, currency values.Currency, amount decimal.Decimal, category, description, user string, happenedAt time.Time) error {
 	m.Deposits = append(m.Deposits, depositCall{
 		ID:       id,
@@ -71,54 +90,10 @@ func (m *DispatcherMock) Withdraw(ctx context.Context, id uuid.UUID, currency va
 	return nil
 }
 
-func (m *DispatcherMock) Transfer(ctx context.Context, fromID uuid.UUID, toID uuid.UUID, fromCurrency values.Currency, toCurrency values.Currency, fromAmount decimal.Decimal, toAmount decimal.Decimal, user string) error {
-	return nil
+func (m *DispatcherMock) Version(ctx context.Context, id uuid.UUID) (uint64, error) {
+	return m.Versions[id], nil
 }
 
-func TestManageAccounts_TransferEventHandler(t *testing.T) {
-	// arrange
-	transactionES := event_store.NewInMemory[*transaction.Transaction](transaction.New)
-	dispatcher := &DispatcherMock{}
-	feature := manage_accounts.New(&http.ServeMux{}, nil, nil, dispatcher, transactionES)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	feature.Setup(ctx)
-
-	fromID := uuid.New()
-	toID := uuid.New()
-	amount := decimal.NewFromInt(100)
-
-	event := transaction_events.MoneyTransfered{
-		FromAccountID: fromID,
-		ToAccountID:   toID,
-		FromCurrency:  values.Currency("EUR"),
-		ToCurrency:    values.Currency("EUR"),
-		FromAmount:    amount,
-		ToAmount:      amount,
-	}
-
-	// act
-	err := transactionES.Append(ctx, event_store.Record{
-		AggregateID: uuid.New(),
-		Version:     1,
-		Event:       event,
-	})
-	assert.NoError(t, err)
-
-	// assert
-	assert.Eventually(t, func() bool {
-		return len(dispatcher.Withdrawals) == 1 && len(dispatcher.Deposits) == 1
-	}, 1*time.Second, 10*time.Millisecond)
-
-	withdrawal := dispatcher.Withdrawals[0]
-	assert.Equal(t, fromID, withdrawal.ID)
-	assert.Equal(t, amount, withdrawal.Amount)
-	assert.Equal(t, "system", withdrawal.User)
-
-	deposit := dispatcher.Deposits[0]
-	assert.Equal(t, toID, deposit.ID)
-	assert.Equal(t, amount, deposit.Amount)
-	assert.Equal(t, "system", deposit.User)
+func (m *DispatcherMock) Transfer(ctx context.Context, fromID uuid.UUID, toID uuid.UUID, fromCurrency values.Currency, toCurrency values.Currency, fromAmount decimal.Decimal, toAmount decimal.Decimal, user string) error {
+	return nil
 }
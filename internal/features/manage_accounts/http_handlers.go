@@ -2,21 +2,89 @@ package manage_accounts
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"maps"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/fx"
 	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
 )
 
+// The statuses GET /api/accounts can be filtered by.
+const (
+	statusOpen   = "open"
+	statusClosed = "closed"
+)
+
+// accountDetails is the JSON shape of the details of an account.
+type accountDetails struct {
+	Institution   string   `json:"institution"`
+	AccountNumber string   `json:"account_number"`
+	Owner         string   `json:"owner"`
+	Icon          string   `json:"icon"`
+	Color         string   `json:"color"`
+	Tags          []string `json:"tags"`
+}
+
+func (d *accountDetails) values() *values.AccountDetails {
+	if d == nil {
+		return nil
+	}
+	return &values.AccountDetails{
+		Institution:   d.Institution,
+		AccountNumber: d.AccountNumber,
+		Owner:         d.Owner,
+		Icon:          d.Icon,
+		Color:         d.Color,
+		Tags:          d.Tags,
+	}
+}
+
 func (f *Feature) handleGetAccounts(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status != "" && status != statusOpen && status != statusClosed {
+		http.Error(w, "bad request: invalid status", http.StatusBadRequest)
+		return
+	}
+
+	kind := values.AccountKind(r.URL.Query().Get("kind"))
+	if kind != "" && !kind.IsValid() {
+		http.Error(w, "bad request: invalid kind", http.StatusBadRequest)
+		return
+	}
+
 	accounts, err := f.accountsView.GetAll(r.Context())
 	if err != nil {
 		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
 
+	if status != "" || kind != "" {
+		accounts = maps.Clone(accounts)
+		for id, acc := range accounts {
+			if status != "" && (acc.ClosedAt != nil) != (status == statusClosed) {
+				delete(accounts, id)
+			}
+			if kind != "" && acc.Kind != kind {
+				delete(accounts, id)
+			}
+		}
+	}
+
+	if base := fx.BaseCurrency(r); base != "" {
+		accounts, err = f.convertAccounts(r.Context(), accounts, base)
+		if err != nil {
+			fx.WriteError(w, err)
+			return
+		}
+	}
+
 	jsonAccounts, err := json.Marshal(accounts)
 	if err != nil {
 		http.Error(w, "internal error", http.StatusInternalServerError)
@@ -44,6 +112,20 @@ func (f *Feature) handleGetAccountBalances(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if base := fx.BaseCurrency(r); base != "" {
+		balances, err = f.convertPeriods(r.Context(), balances, base)
+		if err != nil {
+			fx.WriteError(w, err)
+			return
+		}
+	}
+
+	version, err := f.accountDispatcher.Version(r.Context(), id)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
 	jsonBalances, err := json.Marshal(balances)
 	if err != nil {
 		http.Error(w, "internal error", http.StatusInternalServerError)
@@ -51,6 +133,7 @@ func (f *Feature) handleGetAccountBalances(w http.ResponseWriter, r *http.Reques
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(version))
 	w.WriteHeader(http.StatusOK)
 	w.Write(jsonBalances)
 }
@@ -71,6 +154,14 @@ func (f *Feature) handleGetAccountDistributions(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	if base := fx.BaseCurrency(r); base != "" {
+		distributions, err = f.convertDistributions(r.Context(), distributions, base)
+		if err != nil {
+			fx.WriteError(w, err)
+			return
+		}
+	}
+
 	jsonDistributions, err := json.Marshal(distributions)
 	if err != nil {
 		http.Error(w, "internal error", http.StatusInternalServerError)
@@ -91,6 +182,43 @@ func (f *Feature) handleGetAllBalances(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if base := fx.BaseCurrency(r); base != "" {
+		balances, err = f.convertPeriods(r.Context(), balances, base)
+		if err != nil {
+			fx.WriteError(w, err)
+			return
+		}
+	}
+
+	jsonBalances, err := json.Marshal(balances)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBalances)
+}
+
+func (f *Feature) handleGetBalancesByKind(w http.ResponseWriter, r *http.Request) {
+	balances, err := f.accountsView.GetBalancesByKind(r.Context())
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	if base := fx.BaseCurrency(r); base != "" {
+		now := time.Now()
+		for kind, kindBalances := range balances {
+			balances[kind], err = f.convertBalances(r.Context(), kindBalances, base, now)
+			if err != nil {
+				fx.WriteError(w, err)
+				return
+			}
+		}
+	}
+
 	jsonBalances, err := json.Marshal(balances)
 	if err != nil {
 		http.Error(w, "internal error", http.StatusInternalServerError)
@@ -130,8 +258,14 @@ func (f *Feature) handleDeposit(w http.ResponseWriter, r *http.Request) {
 		req.HappenedAt = time.Now()
 	}
 
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
 	if err := f.accountDispatcher.Deposit(
-		r.Context(),
+		event_store.WithVersionCheck(r.Context(), check),
 		id,
 		req.Currency,
 		req.Amount,
@@ -140,11 +274,12 @@ func (f *Feature) handleDeposit(w http.ResponseWriter, r *http.Request) {
 		req.User,
 		req.HappenedAt,
 	); err != nil {
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		writeCommandError(w, check, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
 	w.WriteHeader(http.StatusCreated)
 }
 
@@ -176,8 +311,14 @@ func (f *Feature) handleWithdrawal(w http.ResponseWriter, r *http.Request) {
 		req.HappenedAt = time.Now()
 	}
 
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
 	if err := f.accountDispatcher.Withdraw(
-		r.Context(),
+		event_store.WithVersionCheck(r.Context(), check),
 		id,
 		req.Currency,
 		req.Amount,
@@ -186,20 +327,23 @@ func (f *Feature) handleWithdrawal(w http.ResponseWriter, r *http.Request) {
 		req.User,
 		req.HappenedAt,
 	); err != nil {
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		writeCommandError(w, check, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
 	w.WriteHeader(http.StatusCreated)
 }
 
 func (f *Feature) handleOpenAccount(w http.ResponseWriter, r *http.Request) {
 	type OpenAccountRequest struct {
-		ID         uuid.UUID       `json:"id"`
-		Name       string          `json:"name"`
-		Currency   values.Currency `json:"currency"`
-		HappenedAt time.Time       `json:"happened_at"`
+		ID         uuid.UUID           `json:"id"`
+		Name       string              `json:"name"`
+		Currency   values.Currency     `json:"currency"`
+		Kind       *values.AccountKind `json:"kind"`
+		Details    *accountDetails     `json:"details"`
+		HappenedAt time.Time           `json:"happened_at"`
 	}
 
 	var req OpenAccountRequest
@@ -217,18 +361,411 @@ func (f *Feature) handleOpenAccount(w http.ResponseWriter, r *http.Request) {
 		req.HappenedAt = time.Now()
 	}
 
+	// The kind is checked upfront so that an invalid one does not leave an
+	// account opened without its details.
+	if req.Kind != nil && !req.Kind.IsValid() {
+		http.Error(w, "unprocessable entity: "+account.ErrInvalidKind.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	check, err := event_store.IfNoneMatch(req.ID, r.Header)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := event_store.WithVersionCheck(r.Context(), check)
 	if err := f.accountDispatcher.Open(
-		r.Context(),
+		ctx,
 		req.ID,
 		req.Name,
 		req.Currency,
 		req.HappenedAt,
 	); err != nil {
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		writeCommandError(w, check, err)
 		return
 	}
 
+	if req.Kind != nil || req.Details != nil {
+		if err := f.accountDispatcher.Update(ctx, req.ID, account.Update{
+			Kind:    req.Kind,
+			Details: req.Details.values(),
+		}, req.HappenedAt); err != nil {
+			writeCommandError(w, check, err)
+			return
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]string{"id": req.ID.String()})
 }
+
+// handleUpdateAccount changes the properties given in the request, leaving
+// the others as they are.
+func (f *Feature) handleUpdateAccount(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	type UpdateAccountRequest struct {
+		Name       *string             `json:"name"`
+		Kind       *values.AccountKind `json:"kind"`
+		Details    *accountDetails     `json:"details"`
+		HappenedAt time.Time           `json:"happened_at"`
+	}
+
+	var req UpdateAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.HappenedAt.IsZero() {
+		req.HappenedAt = time.Now()
+	}
+
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	if err := f.accountDispatcher.Update(event_store.WithVersionCheck(r.Context(), check), id, account.Update{
+		Name:    req.Name,
+		Kind:    req.Kind,
+		Details: req.Details.values(),
+	}, req.HappenedAt); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCloseAccount closes an account. Its remaining balances are transferred
+// to the transfer_to account if one is given, and must be zero otherwise.
+func (f *Feature) handleCloseAccount(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	type CloseAccountRequest struct {
+		TransferTo uuid.UUID `json:"transfer_to"`
+		HappenedAt time.Time `json:"happened_at"`
+	}
+
+	var req CloseAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.HappenedAt.IsZero() {
+		req.HappenedAt = time.Now()
+	}
+
+	if req.TransferTo == id {
+		http.Error(w, "bad request: cannot transfer to the account being closed", http.StatusBadRequest)
+		return
+	}
+
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	if err := f.accountCloser.CloseAccount(
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
+		req.TransferTo,
+		req.HappenedAt,
+	); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *Feature) handleReopenAccount(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	if err := f.accountDispatcher.Reopen(event_store.WithVersionCheck(r.Context(), check), id, time.Now()); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *Feature) handleSetOverdraftLimit(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	type SetOverdraftLimitRequest struct {
+		Limit decimal.Decimal `json:"limit"`
+	}
+
+	var req SetOverdraftLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	if err := f.accountDispatcher.SetOverdraftLimit(
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
+		values.Currency(r.PathValue("currency")),
+		req.Limit,
+		time.Now(),
+	); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *Feature) handleRemoveOverdraftLimit(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	if err := f.accountDispatcher.RemoveOverdraftLimit(
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
+		values.Currency(r.PathValue("currency")),
+		time.Now(),
+	); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *Feature) handleSetStatementCycle(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	type SetStatementCycleRequest struct {
+		ClosingDay         int             `json:"closing_day"`
+		DueDay             int             `json:"due_day"`
+		MinimumPaymentRate decimal.Decimal `json:"minimum_payment_rate"`
+	}
+
+	var req SetStatementCycleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	if err := f.accountDispatcher.SetStatementCycle(
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
+		account.StatementCycle{
+			ClosingDay:         req.ClosingDay,
+			DueDay:             req.DueDay,
+			MinimumPaymentRate: req.MinimumPaymentRate,
+		},
+		time.Now(),
+	); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCloseStatement closes the last statement of a credit card closing on
+// or before as_of, today if not given.
+func (f *Feature) handleCloseStatement(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	type CloseStatementRequest struct {
+		AsOf time.Time `json:"as_of"`
+	}
+
+	var req CloseStatementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	now := time.Now()
+	if req.AsOf.IsZero() {
+		req.AsOf = now
+	}
+
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	if err := f.accountDispatcher.CloseStatement(
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
+		req.AsOf,
+		f.statementsView,
+		now,
+	); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *Feature) handleGetStatements(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" && status != statusOpen {
+		http.Error(w, "bad request: invalid status", http.StatusBadRequest)
+		return
+	}
+
+	statements, err := f.statementsView.ListStatements(r.Context(), id, status == statusOpen)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	jsonStatements, err := json.Marshal(statements)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonStatements)
+}
+
+func (f *Feature) handleGetAccountHoldings(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	holdings, err := f.holdingsView.ListHoldingsByAccount(r.Context(), id)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	jsonHoldings, err := json.Marshal(holdings)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonHoldings)
+}
+
+func (f *Feature) handleGetAllHoldings(w http.ResponseWriter, r *http.Request) {
+	holdings, err := f.holdingsView.ListHoldings(r.Context())
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	jsonHoldings, err := json.Marshal(holdings)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonHoldings)
+}
+
+// writeCommandError maps the error of a command to its response. A conflict
+// fails the precondition of a request with an If-Match header, and can be
+// retried otherwise.
+func writeCommandError(w http.ResponseWriter, check *event_store.VersionCheck, err error) {
+	switch {
+	case errors.Is(err, event_store.ErrConcurrencyConflict) && check.Expected != nil:
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+	case errors.Is(err, event_store.ErrConcurrencyConflict):
+		http.Error(w, "conflict", http.StatusConflict)
+	case errors.Is(err, account.ErrAccountNotOpened):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, account.ErrAccountClosed), errors.Is(err, account.ErrNonZeroBalance),
+		errors.Is(err, account.ErrInsufficientFunds), errors.Is(err, account.ErrNegativeLimit),
+		errors.Is(err, account.ErrInvalidKind), errors.Is(err, account.ErrNotCreditCard),
+		errors.Is(err, account.ErrInvalidCycle), errors.Is(err, account.ErrNoStatementCycle),
+		errors.Is(err, account.ErrInvalidClosingDate):
+		http.Error(w, "unprocessable entity: "+err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
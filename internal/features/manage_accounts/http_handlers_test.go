@@ -12,7 +12,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/fx"
+	"github.com/somatom98/brokeli/internal/domain/loan"
+	"github.com/somatom98/brokeli/internal/domain/position"
+	"github.com/somatom98/brokeli/internal/domain/projections/accounts"
 	"github.com/somatom98/brokeli/internal/domain/projections/balance_updates"
+	"github.com/somatom98/brokeli/internal/domain/projections/holdings"
+	"github.com/somatom98/brokeli/internal/domain/projections/statements"
 	"github.com/somatom98/brokeli/internal/domain/transaction"
 	"github.com/somatom98/brokeli/internal/domain/values"
 	"github.com/somatom98/brokeli/internal/features/manage_accounts"
@@ -56,8 +62,8 @@ func TestManageAccounts_Handlers(t *testing.T) {
 	dispatcher := &DispatcherMock{}
 	transactionES := event_store.NewInMemory[*transaction.Transaction](transaction.New)
 	accountES := event_store.NewInMemory[*account.Account](account.New)
-	balanceUpdatesProjection := balance_updates.New(transactionES, accountES, repo)
-	feature := manage_accounts.New(mux, nil, balanceUpdatesProjection, dispatcher, transactionES)
+	balanceUpdatesProjection := balance_updates.New(transactionES, accountES, event_store.NewInMemory[*loan.Loan](loan.New), repo)
+	feature := manage_accounts.New(mux, nil, balanceUpdatesProjection, dispatcher, nil, nil, nil, nil)
 	feature.Setup(context.Background())
 
 	t.Run("GET /api/balances", func(t *testing.T) {
@@ -78,6 +84,7 @@ func TestManageAccounts_Handlers(t *testing.T) {
 
 	t.Run("GET /api/accounts/{id}/balances", func(t *testing.T) {
 		id := uuid.New()
+		dispatcher.Versions = map[uuid.UUID]uint64{id: 4}
 		req := httptest.NewRequest(http.MethodGet, "/api/accounts/"+id.String()+"/balances", nil)
 		rec := httptest.NewRecorder()
 
@@ -85,6 +92,7 @@ func TestManageAccounts_Handlers(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, rec.Code)
 		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
 
 		var result []balance_updates.BalancePeriod
 		err := json.NewDecoder(rec.Body).Decode(&result)
@@ -152,3 +160,575 @@ func TestManageAccounts_Handlers(t *testing.T) {
 		assert.Equal(t, "test-user", dispatcher.Withdrawals[0].User)
 	})
 }
+
+func TestManageAccounts_IfMatch(t *testing.T) {
+	// arrange
+	mux := http.NewServeMux()
+	accountES := event_store.NewInMemory[*account.Account](account.New)
+	feature := manage_accounts.New(mux, nil, nil, account.NewDispatcher(accountES), nil, nil, nil, nil)
+	feature.Setup(context.Background())
+
+	id := uuid.New()
+	open := httptest.NewRequest(http.MethodPost, "/api/accounts", bytes.NewBufferString(`{"id":"`+id.String()+`","name":"test-account","currency":"EUR"}`))
+	opened := httptest.NewRecorder()
+	mux.ServeHTTP(opened, open)
+	assert.Equal(t, http.StatusCreated, opened.Code)
+	assert.Equal(t, `"1"`, opened.Header().Get("ETag"))
+
+	deposit := func(ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/accounts/"+id.String()+"/deposits", bytes.NewBufferString(`{"currency":"EUR","amount":"10"}`))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("should apply the command at the current version", func(t *testing.T) {
+		// act
+		rec := deposit(`"1"`)
+
+		// assert
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("should fail the precondition at a stale version", func(t *testing.T) {
+		// act
+		rec := deposit(`"1"`)
+
+		// assert
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+		_, version, err := accountES.GetAggregate(context.Background(), id)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), version)
+	})
+
+	t.Run("should apply the command without a precondition", func(t *testing.T) {
+		// act
+		rec := deposit("")
+
+		// assert
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("should reject an invalid If-Match header", func(t *testing.T) {
+		// act
+		rec := deposit("3")
+
+		// assert
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("should fail the precondition of opening an existing account", func(t *testing.T) {
+		// arrange
+		req := httptest.NewRequest(http.MethodPost, "/api/accounts", bytes.NewBufferString(`{"id":"`+id.String()+`","name":"test-account","currency":"EUR"}`))
+		req.Header.Set("If-None-Match", "*")
+		rec := httptest.NewRecorder()
+
+		// act
+		mux.ServeHTTP(rec, req)
+
+		// assert
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	})
+
+	t.Run("should reject an If-Match header on opening", func(t *testing.T) {
+		// arrange
+		req := httptest.NewRequest(http.MethodPost, "/api/accounts", bytes.NewBufferString(`{"name":"other-account","currency":"EUR"}`))
+		req.Header.Set("If-Match", `"0"`)
+		rec := httptest.NewRecorder()
+
+		// act
+		mux.ServeHTTP(rec, req)
+
+		// assert
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestManageAccounts_Close(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	transactionES := event_store.NewInMemory[*transaction.Transaction](transaction.New)
+	accountES := event_store.NewInMemory[*account.Account](account.New)
+	accountDispatcher := account.NewDispatcher(accountES)
+	transactionDispatcher := transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), event_store.NewInMemoryUnitOfWork())
+	accountsProjection := accounts.New(transactionES, accountES, accounts.NewInMemoryRepository())
+	feature := manage_accounts.New(mux, accountsProjection, nil, accountDispatcher, transactionDispatcher, nil, nil, nil)
+	feature.Setup(ctx)
+
+	id, savingsID := uuid.New(), uuid.New()
+	assert.NoError(t, accountDispatcher.Open(ctx, id, "Checking", "EUR", time.Now()))
+	assert.NoError(t, accountDispatcher.Open(ctx, savingsID, "Savings", "EUR", time.Now()))
+	assert.NoError(t, accountDispatcher.Deposit(ctx, id, "EUR", decimal.NewFromInt(40), "", "", "test-user", time.Now()))
+
+	post := func(path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	list := func(status string) map[uuid.UUID]accounts.Account {
+		req := httptest.NewRequest(http.MethodGet, "/api/accounts?status="+status, nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var result map[uuid.UUID]accounts.Account
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		return result
+	}
+
+	t.Run("should not close an account with a balance", func(t *testing.T) {
+		// act
+		rec := post("/api/accounts/"+id.String()+"/close", "")
+
+		// assert
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Len(t, list("closed"), 0)
+	})
+
+	t.Run("should close an account transferring its balance", func(t *testing.T) {
+		// act
+		rec := post("/api/accounts/"+id.String()+"/close", `{"transfer_to":"`+savingsID.String()+`"}`)
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+
+		closed := list("closed")
+		assert.Len(t, closed, 1)
+		assert.True(t, closed[id].Balance["EUR"].IsZero())
+		assert.Equal(t, "40", list("open")[savingsID].Balance["EUR"].String())
+	})
+
+	t.Run("should reject deposits into a closed account", func(t *testing.T) {
+		// act
+		rec := post("/api/accounts/"+id.String()+"/deposits", `{"currency":"EUR","amount":"10"}`)
+
+		// assert
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("should reopen a closed account", func(t *testing.T) {
+		// act
+		rec := post("/api/accounts/"+id.String()+"/reopen", "")
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Len(t, list("closed"), 0)
+		assert.Len(t, list("open"), 2)
+	})
+
+	t.Run("should reject an invalid status", func(t *testing.T) {
+		// act
+		req := httptest.NewRequest(http.MethodGet, "/api/accounts?status=archived", nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		// assert
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestManageAccounts_OverdraftLimit(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	accountES := event_store.NewInMemory[*account.Account](account.New)
+	accountDispatcher := account.NewDispatcher(accountES)
+	feature := manage_accounts.New(mux, nil, nil, accountDispatcher, nil, nil, nil, nil)
+	feature.Setup(ctx)
+
+	id := uuid.New()
+	assert.NoError(t, accountDispatcher.Open(ctx, id, "Savings", "EUR", time.Now()))
+	assert.NoError(t, accountDispatcher.Deposit(ctx, id, "EUR", decimal.NewFromInt(40), "", "", "test-user", time.Now()))
+
+	send := func(method string, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("should set the overdraft limit", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPut, "/api/accounts/"+id.String()+"/overdraft-limits/EUR", `{"limit":"0"}`)
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("should reject a withdrawal overdrawing the account", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPost, "/api/accounts/"+id.String()+"/withdrawals", `{"currency":"EUR","amount":"50"}`)
+
+		// assert
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), account.ErrInsufficientFunds.Error())
+	})
+
+	t.Run("should allow the withdrawal once the limit is removed", func(t *testing.T) {
+		// arrange
+		removed := send(http.MethodDelete, "/api/accounts/"+id.String()+"/overdraft-limits/EUR", "")
+		assert.Equal(t, http.StatusNoContent, removed.Code)
+
+		// act
+		rec := send(http.MethodPost, "/api/accounts/"+id.String()+"/withdrawals", `{"currency":"EUR","amount":"50"}`)
+
+		// assert
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+}
+
+func TestManageAccounts_Details(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	transactionES := event_store.NewInMemory[*transaction.Transaction](transaction.New)
+	accountES := event_store.NewInMemory[*account.Account](account.New)
+	accountDispatcher := account.NewDispatcher(accountES)
+	accountsProjection := accounts.New(transactionES, accountES, accounts.NewInMemoryRepository())
+	feature := manage_accounts.New(mux, accountsProjection, nil, accountDispatcher, nil, nil, nil, nil)
+	feature.Setup(ctx)
+
+	savingsID := uuid.New()
+	assert.NoError(t, accountDispatcher.Open(ctx, savingsID, "Savings", "EUR", time.Now()))
+	assert.NoError(t, accountDispatcher.Deposit(ctx, savingsID, "EUR", decimal.NewFromInt(40), "", "", "test-user", time.Now()))
+
+	send := func(method string, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("should open an account with its kind and details", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPost, "/api/accounts", `{"name":"Checking","currency":"EUR","kind":"CHECKING","details":{"institution":"Fineco","account_number":"IT60X0542811101000000123456","tags":["joint"]}}`)
+
+		// assert
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("should reject an invalid kind", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPatch, "/api/accounts/"+savingsID.String(), `{"kind":"PIGGY_BANK"}`)
+
+		// assert
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("should update the kind of an account", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPatch, "/api/accounts/"+savingsID.String(), `{"kind":"SAVINGS","details":{"owner":"marco"}}`)
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("should filter accounts by kind", func(t *testing.T) {
+		// act
+		rec := send(http.MethodGet, "/api/accounts?kind=CHECKING", "")
+
+		// assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var result map[uuid.UUID]accounts.Account
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		assert.Len(t, result, 1)
+		for _, acc := range result {
+			assert.Equal(t, "Checking", acc.Name)
+			assert.Equal(t, "Fineco", acc.Institution)
+			assert.Equal(t, "***********************3456", acc.AccountNumber)
+			assert.Equal(t, []string{"joint"}, acc.Tags)
+		}
+	})
+
+	t.Run("should group balances by kind", func(t *testing.T) {
+		// act
+		rec := send(http.MethodGet, "/api/balances/by-kind", "")
+
+		// assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var result map[values.AccountKind]map[values.Currency]decimal.Decimal
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		assert.Equal(t, "40", result[values.AccountKind_Savings]["EUR"].String())
+		assert.Contains(t, result, values.AccountKind_Checking)
+	})
+}
+
+type StatementsViewMock struct {
+	Balance decimal.Decimal
+	Before  []time.Time
+}
+
+func (m *StatementsViewMock) BalanceBefore(ctx context.Context, accountID uuid.UUID, currency values.Currency, before time.Time) (decimal.Decimal, error) {
+	m.Before = append(m.Before, before)
+	return m.Balance, nil
+}
+
+func (m *StatementsViewMock) ListStatements(ctx context.Context, accountID uuid.UUID, openOnly bool) ([]statements.Statement, error) {
+	return nil, nil
+}
+
+func TestManageAccounts_Statements(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	accountES := event_store.NewInMemory[*account.Account](account.New)
+	accountDispatcher := account.NewDispatcher(accountES)
+	statementsView := &StatementsViewMock{Balance: decimal.RequireFromString("-812.4")}
+	feature := manage_accounts.New(mux, nil, nil, accountDispatcher, nil, statementsView, nil, nil)
+	feature.Setup(ctx)
+
+	cardID, checkingID := uuid.New(), uuid.New()
+	creditCard, checking := values.AccountKind_CreditCard, values.AccountKind_Checking
+	assert.NoError(t, accountDispatcher.Open(ctx, cardID, "Visa", "EUR", time.Now()))
+	assert.NoError(t, accountDispatcher.Update(ctx, cardID, account.Update{Kind: &creditCard}, time.Now()))
+	assert.NoError(t, accountDispatcher.Open(ctx, checkingID, "Checking", "EUR", time.Now()))
+	assert.NoError(t, accountDispatcher.Update(ctx, checkingID, account.Update{Kind: &checking}, time.Now()))
+
+	send := func(method string, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("should not close a statement without a cycle", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPost, "/api/accounts/"+cardID.String()+"/statements", "")
+
+		// assert
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), account.ErrNoStatementCycle.Error())
+	})
+
+	t.Run("should reject a statement cycle on a checking account", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPut, "/api/accounts/"+checkingID.String()+"/statement-cycle", `{"closing_day":25,"due_day":10}`)
+
+		// assert
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), account.ErrNotCreditCard.Error())
+	})
+
+	t.Run("should set the statement cycle of a credit card", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPut, "/api/accounts/"+cardID.String()+"/statement-cycle", `{"closing_day":25,"due_day":10,"minimum_payment_rate":"0.05"}`)
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("should close the last statement on the balance at the end of its closing day", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPost, "/api/accounts/"+cardID.String()+"/statements", `{"as_of":"2024-10-03T12:00:00Z"}`)
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+		assert.Equal(t, []time.Time{time.Date(2024, 9, 26, 0, 0, 0, 0, time.UTC)}, statementsView.Before)
+
+		acc, _, err := accountES.GetAggregate(ctx, cardID)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 9, 25, 0, 0, 0, 0, time.UTC), acc.LastClosingDate)
+	})
+
+	t.Run("should not close the same statement twice", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPost, "/api/accounts/"+cardID.String()+"/statements", `{"as_of":"2024-10-20T12:00:00Z"}`)
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+	})
+}
+
+func TestManageAccounts_Holdings(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	// arrange trades: 10 units at 100, 10 at 130, then 15 sold at 150 with a
+	// fee of 5 and the units left split 2 for 1.
+	setup := func(t *testing.T, method position.CostBasisMethod) (*http.ServeMux, uuid.UUID) {
+		mux := http.NewServeMux()
+		transactionES := event_store.NewInMemory[*transaction.Transaction](transaction.New)
+		accountES := event_store.NewInMemory[*account.Account](account.New)
+		accountDispatcher := account.NewDispatcher(accountES)
+		transactionDispatcher := transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), event_store.NewInMemoryUnitOfWork(), transaction.WithCostBasisMethod(method))
+		holdingsProjection := holdings.New(transactionES, holdings.NewInMemoryRepository())
+		manage_accounts.New(mux, nil, nil, accountDispatcher, nil, nil, holdingsProjection, nil).Setup(ctx)
+
+		id := uuid.New()
+		eur := values.Currency("EUR")
+		assert.NoError(t, accountDispatcher.Open(ctx, id, "Broker", eur, day(1)))
+		assert.NoError(t, transactionDispatcher.RegisterInvestment(ctx, uuid.New(), id, "VWCE", decimal.NewFromInt(10), decimal.NewFromInt(100), eur, decimal.Zero, eur, day(2)))
+		assert.NoError(t, transactionDispatcher.RegisterInvestment(ctx, uuid.New(), id, "VWCE", decimal.NewFromInt(10), decimal.NewFromInt(130), eur, decimal.Zero, eur, day(3)))
+		assert.NoError(t, transactionDispatcher.RegisterSale(ctx, uuid.New(), id, "VWCE", decimal.NewFromInt(15), decimal.NewFromInt(150), eur, decimal.NewFromInt(5), eur, day(4)))
+		assert.NoError(t, transactionDispatcher.RegisterStockSplit(ctx, uuid.New(), id, "VWCE", decimal.NewFromInt(2), day(5)))
+
+		return mux, id
+	}
+
+	get := func(mux *http.ServeMux, path string) []holdings.Holding {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var result []holdings.Holding
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		return result
+	}
+
+	t.Run("should cost the units sold at the oldest lots with FIFO", func(t *testing.T) {
+		// arrange
+		mux, id := setup(t, position.CostBasisMethod_FIFO)
+
+		// act
+		result := get(mux, "/api/accounts/"+id.String()+"/holdings")
+
+		// assert
+		assert.Len(t, result, 1)
+		assert.Equal(t, "10", result[0].Units.String())
+		assert.Equal(t, "650", result[0].CostBasis.String())
+		assert.Equal(t, "65", result[0].AverageCost.String())
+		assert.Equal(t, "595", result[0].RealizedGain.String())
+		assert.Len(t, result[0].Lots, 1)
+		assert.Equal(t, "10", result[0].Lots[0].Units.String())
+		assert.Equal(t, "65", result[0].Lots[0].Price.String())
+	})
+
+	t.Run("should cost the units sold at the average cost with AVERAGE", func(t *testing.T) {
+		// arrange
+		mux, id := setup(t, position.CostBasisMethod_Average)
+
+		// act
+		result := get(mux, "/api/accounts/"+id.String()+"/holdings")
+
+		// assert
+		assert.Len(t, result, 1)
+		assert.Equal(t, "10", result[0].Units.String())
+		assert.Equal(t, "575", result[0].CostBasis.String())
+		assert.Equal(t, "57.5", result[0].AverageCost.String())
+		assert.Equal(t, "520", result[0].RealizedGain.String())
+	})
+
+	t.Run("should list the holdings of every account", func(t *testing.T) {
+		// arrange
+		mux, id := setup(t, position.CostBasisMethod_FIFO)
+
+		// act
+		result := get(mux, "/api/holdings")
+
+		// assert
+		assert.Len(t, result, 1)
+		assert.Equal(t, id, result[0].AccountID)
+		assert.Equal(t, "VWCE", result[0].Ticker)
+	})
+}
+
+func TestManageAccounts_BaseCurrency(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	eur, dkk := values.Currency("EUR"), values.Currency("DKK")
+	march := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	repo := &BalanceUpdatesRepositoryMock{
+		Balances: []balance_updates.BalancePeriod{
+			{Month: march, Currency: eur, Amount: decimal.NewFromInt(100)},
+			{Month: march, Currency: dkk, Amount: decimal.NewFromInt(746)},
+		},
+		Distributions: []balance_updates.AccountDistribution{
+			{ID: uuid.New(), Currency: dkk, Amount: decimal.NewFromInt(746), ValueDate: march.AddDate(0, 0, 9), SystemAmount: decimal.Zero, OtherAmount: decimal.NewFromInt(746)},
+		},
+	}
+	transactionES := event_store.NewInMemory[*transaction.Transaction](transaction.New)
+	accountES := event_store.NewInMemory[*account.Account](account.New)
+	accountDispatcher := account.NewDispatcher(accountES)
+	accountsProjection := accounts.New(transactionES, accountES, accounts.NewInMemoryRepository())
+	balanceUpdatesProjection := balance_updates.New(transactionES, accountES, event_store.NewInMemory[*loan.Loan](loan.New), repo)
+
+	rates := fx.NewInMemoryRepository()
+	assert.NoError(t, rates.Save(ctx, fx.Rate{Base: eur, Quote: dkk, Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Rate: decimal.RequireFromString("7.46")}))
+	manage_accounts.New(mux, accountsProjection, balanceUpdatesProjection, accountDispatcher, nil, nil, nil, fx.NewConverter(rates)).Setup(ctx)
+
+	id := uuid.New()
+	assert.NoError(t, accountDispatcher.Open(ctx, id, "Checking", dkk, time.Now()))
+	assert.NoError(t, accountDispatcher.Deposit(ctx, id, dkk, decimal.NewFromInt(1492), "", "", "test-user", time.Now()))
+	assert.NoError(t, accountDispatcher.Deposit(ctx, id, eur, decimal.NewFromInt(50), "", "", "test-user", time.Now()))
+
+	get := func(path string, result any) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code == http.StatusOK {
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(result))
+		}
+		return rec
+	}
+
+	t.Run("should sum the balances of a month in the base currency", func(t *testing.T) {
+		// act
+		var result []balance_updates.BalancePeriod
+		rec := get("/api/balances?base_currency=EUR", &result)
+
+		// assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, result, 1)
+		assert.Equal(t, eur, result[0].Currency)
+		assert.Equal(t, "200", result[0].Amount.String())
+	})
+
+	t.Run("should convert the distributions at the rate of their value date", func(t *testing.T) {
+		// act
+		var result []balance_updates.AccountDistribution
+		rec := get("/api/accounts/"+id.String()+"/distributions?base_currency=EUR", &result)
+
+		// assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, result, 1)
+		assert.Equal(t, eur, result[0].Currency)
+		assert.Equal(t, "100", result[0].Amount.String())
+		assert.Equal(t, "100", result[0].OtherAmount.String())
+	})
+
+	t.Run("should convert the balances of the accounts", func(t *testing.T) {
+		// act
+		var result map[uuid.UUID]accounts.Account
+		rec := get("/api/accounts?base_currency=EUR", &result)
+
+		// assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, map[values.Currency]decimal.Decimal{eur: decimal.NewFromInt(250)}, result[id].Balance)
+	})
+
+	t.Run("should convert the balances by kind", func(t *testing.T) {
+		// act
+		var result map[values.AccountKind]map[values.Currency]decimal.Decimal
+		rec := get("/api/balances/by-kind?base_currency=DKK", &result)
+
+		// assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1865", result[""][dkk].String())
+	})
+
+	t.Run("should reject a base currency without rates", func(t *testing.T) {
+		// act
+		rec := get("/api/balances?base_currency=SEK", nil)
+
+		// assert
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+}
@@ -7,18 +7,50 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/account"
 	"github.com/somatom98/brokeli/internal/domain/projections/accounts"
 	"github.com/somatom98/brokeli/internal/domain/projections/balance_updates"
-	"github.com/somatom98/brokeli/internal/domain/transaction"
+	"github.com/somatom98/brokeli/internal/domain/projections/holdings"
+	"github.com/somatom98/brokeli/internal/domain/projections/statements"
 	"github.com/somatom98/brokeli/internal/domain/values"
-	"github.com/somatom98/brokeli/pkg/event_store"
 )
 
 type AccountDispatcher interface {
 	Open(ctx context.Context, id uuid.UUID, name string, currency values.Currency, happenedAt time.Time) error
 	UpdateName(ctx context.Context, id uuid.UUID, name string, happenedAt time.Time) error
+	Update(ctx context.Context, id uuid.UUID, update account.Update, happenedAt time.Time) error
 	Deposit(ctx context.Context, id uuid.UUID, currency values.Currency, amount decimal.Decimal, category, description, user string, happenedAt time.Time) error
 	Withdraw(ctx context.Context, id uuid.UUID, currency values.Currency, amount decimal.Decimal, category, description, user string, happenedAt time.Time) error
+	Reopen(ctx context.Context, id uuid.UUID, happenedAt time.Time) error
+	SetOverdraftLimit(ctx context.Context, id uuid.UUID, currency values.Currency, limit decimal.Decimal, happenedAt time.Time) error
+	RemoveOverdraftLimit(ctx context.Context, id uuid.UUID, currency values.Currency, happenedAt time.Time) error
+	SetStatementCycle(ctx context.Context, id uuid.UUID, cycle account.StatementCycle, happenedAt time.Time) error
+	CloseStatement(ctx context.Context, id uuid.UUID, asOf time.Time, ledger account.StatementLedger, happenedAt time.Time) error
+	Version(ctx context.Context, id uuid.UUID) (uint64, error)
+}
+
+// AccountCloser closes accounts, transferring their remaining balances to
+// another account unless transferTo is nil.
+type AccountCloser interface {
+	CloseAccount(ctx context.Context, id uuid.UUID, transferTo uuid.UUID, happenedAt time.Time) error
+}
+
+// StatementsView lists the statements of credit cards, and tells the balances
+// they are closed on.
+type StatementsView interface {
+	account.StatementLedger
+	ListStatements(ctx context.Context, accountID uuid.UUID, openOnly bool) ([]statements.Statement, error)
+}
+
+// HoldingsView lists the units held of each ticker, and what they cost.
+type HoldingsView interface {
+	ListHoldings(ctx context.Context) ([]holdings.Holding, error)
+	ListHoldingsByAccount(ctx context.Context, accountID uuid.UUID) ([]holdings.Holding, error)
+}
+
+// Converter converts amounts between currencies at the rate of a date.
+type Converter interface {
+	Convert(ctx context.Context, amount decimal.Decimal, from, to values.Currency, on time.Time) (decimal.Decimal, error)
 }
 
 type Feature struct {
@@ -26,6 +58,10 @@ type Feature struct {
 	accountsView      *accounts.Projection
 	balanceUpdatesView *balance_updates.Projection
 	accountDispatcher AccountDispatcher
+	accountCloser     AccountCloser
+	statementsView    StatementsView
+	holdingsView      HoldingsView
+	converter         Converter
 }
 
 func New(
@@ -33,26 +69,40 @@ func New(
 	accountsView *accounts.Projection,
 	balanceUpdatesView *balance_updates.Projection,
 	accountDispatcher AccountDispatcher,
-	transactionES event_store.Store[*transaction.Transaction],
+	accountCloser AccountCloser,
+	statementsView StatementsView,
+	holdingsView HoldingsView,
+	converter Converter,
 ) *Feature {
-	f := &Feature{
+	return &Feature{
 		httpHandler:       httpHandler,
 		accountsView:      accountsView,
 		balanceUpdatesView: balanceUpdatesView,
 		accountDispatcher: accountDispatcher,
+		accountCloser:     accountCloser,
+		statementsView:    statementsView,
+		holdingsView:      holdingsView,
+		converter:         converter,
 	}
-
-	transactionES.Subscribe(context.Background(), f.HandleRecord)
-
-	return f
 }
 
 func (f *Feature) Setup(ctx context.Context) {
 	f.httpHandler.HandleFunc("GET /api/accounts", f.handleGetAccounts)
 	f.httpHandler.HandleFunc("POST /api/accounts", f.handleOpenAccount)
+	f.httpHandler.HandleFunc("PATCH /api/accounts/{id}", f.handleUpdateAccount)
 	f.httpHandler.HandleFunc("GET /api/accounts/{id}/balances", f.handleGetAccountBalances)
 	f.httpHandler.HandleFunc("GET /api/accounts/{id}/distributions", f.handleGetAccountDistributions)
 	f.httpHandler.HandleFunc("GET /api/balances", f.handleGetAllBalances)
+	f.httpHandler.HandleFunc("GET /api/balances/by-kind", f.handleGetBalancesByKind)
 	f.httpHandler.HandleFunc("POST /api/accounts/{id}/deposits", f.handleDeposit)
 	f.httpHandler.HandleFunc("POST /api/accounts/{id}/withdrawals", f.handleWithdrawal)
+	f.httpHandler.HandleFunc("POST /api/accounts/{id}/close", f.handleCloseAccount)
+	f.httpHandler.HandleFunc("POST /api/accounts/{id}/reopen", f.handleReopenAccount)
+	f.httpHandler.HandleFunc("PUT /api/accounts/{id}/overdraft-limits/{currency}", f.handleSetOverdraftLimit)
+	f.httpHandler.HandleFunc("DELETE /api/accounts/{id}/overdraft-limits/{currency}", f.handleRemoveOverdraftLimit)
+	f.httpHandler.HandleFunc("PUT /api/accounts/{id}/statement-cycle", f.handleSetStatementCycle)
+	f.httpHandler.HandleFunc("GET /api/accounts/{id}/statements", f.handleGetStatements)
+	f.httpHandler.HandleFunc("POST /api/accounts/{id}/statements", f.handleCloseStatement)
+	f.httpHandler.HandleFunc("GET /api/accounts/{id}/holdings", f.handleGetAccountHoldings)
+	f.httpHandler.HandleFunc("GET /api/holdings", f.handleGetAllHoldings)
 }
@@ -0,0 +1,138 @@
+package manage_fx_rates
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/fx"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+// RateRequest is a rate as it is sent, quoted on a date.
+type RateRequest struct {
+	Base  values.Currency `json:"base"`
+	Quote values.Currency `json:"quote"`
+	Date  string          `json:"date"`
+	Rate  decimal.Decimal `json:"rate"`
+}
+
+func (req RateRequest) toRate() (fx.Rate, error) {
+	date, err := time.Parse(time.DateOnly, req.Date)
+	if err != nil {
+		return fx.Rate{}, fx.ErrInvalidRate
+	}
+
+	rate := fx.Rate{
+		Base:  req.Base,
+		Quote: req.Quote,
+		Date:  date,
+		Rate:  req.Rate,
+	}
+	return rate, rate.Validate()
+}
+
+func (f *Feature) handleGetRates(w http.ResponseWriter, r *http.Request) {
+	to := time.Now()
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		t, err := time.Parse(time.DateOnly, toStr)
+		if err != nil {
+			http.Error(w, "bad request: invalid to date", http.StatusBadRequest)
+			return
+		}
+		to = t
+	}
+
+	// without a from date, the rates of the last year are listed
+	from := to.AddDate(-1, 0, 0)
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		t, err := time.Parse(time.DateOnly, fromStr)
+		if err != nil {
+			http.Error(w, "bad request: invalid from date", http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+
+	base := values.Currency(r.PathValue("base"))
+	quote := values.Currency(r.PathValue("quote"))
+
+	rates, err := f.rateRepository.List(r.Context(), base, quote, from, to)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rates)
+}
+
+func (f *Feature) handleSaveRates(w http.ResponseWriter, r *http.Request) {
+	var req []RateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	rates := make([]fx.Rate, 0, len(req))
+	for _, rateReq := range req {
+		rate, err := rateReq.toRate()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := f.rateRepository.Save(r.Context(), rates...); err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *Feature) handleFetchRates(w http.ResponseWriter, r *http.Request) {
+	type FetchRatesRequest struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+
+	type FetchRatesResponse struct {
+		Fetched int `json:"fetched"`
+	}
+
+	var req FetchRatesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	from, err := time.Parse(time.DateOnly, req.From)
+	if err != nil {
+		http.Error(w, "bad request: invalid from date", http.StatusBadRequest)
+		return
+	}
+	to, err := time.Parse(time.DateOnly, req.To)
+	if err != nil {
+		http.Error(w, "bad request: invalid to date", http.StatusBadRequest)
+		return
+	}
+
+	fetched, err := fx.Fetch(r.Context(), f.rateProvider, f.rateRepository, from, to)
+	if errors.Is(err, fx.ErrInvalidRate) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, "bad gateway: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(FetchRatesResponse{Fetched: fetched})
+}
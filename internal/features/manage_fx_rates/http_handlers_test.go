@@ -0,0 +1,82 @@
+package manage_fx_rates_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/somatom98/brokeli/internal/domain/fx"
+	"github.com/somatom98/brokeli/internal/features/manage_fx_rates"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManageFXRates_Handlers(t *testing.T) {
+	// arrange
+	mux := http.NewServeMux()
+	rateRepository := fx.NewInMemoryRepository()
+
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2024-01-01", r.URL.Query().Get("from"))
+		assert.Equal(t, "2024-01-31", r.URL.Query().Get("to"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"base":"EUR","quote":"USD","date":"2024-01-10","rate":"1.09"},
+			{"base":"EUR","quote":"USD","date":"2024-02-10","rate":"1.08"}
+		]`))
+	}))
+	defer provider.Close()
+
+	manage_fx_rates.New(mux, rateRepository, fx.NewHTTPProvider(provider.URL, provider.Client())).Setup()
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	list := func(path string) []fx.Rate {
+		rec := send(http.MethodGet, path, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var result []fx.Rate
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		return result
+	}
+
+	t.Run("should save rates in bulk", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPut, "/api/fx-rates", `[
+			{"base":"EUR","quote":"DKK","date":"2024-01-02","rate":"7.46"},
+			{"base":"EUR","quote":"DKK","date":"2024-01-03","rate":"7.45"}
+		]`)
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		result := list("/api/fx-rates/EUR/DKK?from=2024-01-01&to=2024-01-31")
+		assert.Len(t, result, 2)
+		assert.Equal(t, "7.46", result[0].Rate.String())
+		assert.Equal(t, "7.45", result[1].Rate.String())
+	})
+
+	t.Run("should reject invalid rates", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPut, "/api/fx-rates", `[{"base":"EUR","quote":"EUR","date":"2024-01-02","rate":"1"}]`)
+
+		// assert
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("should store the rates fetched from the provider", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPost, "/api/fx-rates/fetch", `{"from":"2024-01-01","to":"2024-01-31"}`)
+
+		// assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"fetched":1}`, rec.Body.String())
+		assert.Len(t, list("/api/fx-rates/EUR/USD?from=2024-01-01&to=2024-12-31"), 1)
+	})
+}
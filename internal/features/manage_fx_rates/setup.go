@@ -0,0 +1,35 @@
+package manage_fx_rates
+
+import (
+	"net/http"
+
+	"github.com/somatom98/brokeli/internal/domain/fx"
+)
+
+type Feature struct {
+	httpHandler    *http.ServeMux
+	rateRepository fx.Repository
+	rateProvider   fx.RateProvider
+}
+
+// New returns the feature managing exchange rates. The rate provider can be
+// nil, in which case rates can only be set manually.
+func New(
+	httpHandler *http.ServeMux,
+	rateRepository fx.Repository,
+	rateProvider fx.RateProvider,
+) *Feature {
+	return &Feature{
+		httpHandler:    httpHandler,
+		rateRepository: rateRepository,
+		rateProvider:   rateProvider,
+	}
+}
+
+func (f *Feature) Setup() {
+	f.httpHandler.HandleFunc("GET /api/fx-rates/{base}/{quote}", f.handleGetRates)
+	f.httpHandler.HandleFunc("PUT /api/fx-rates", f.handleSaveRates)
+	if f.rateProvider != nil {
+		f.httpHandler.HandleFunc("POST /api/fx-rates/fetch", f.handleFetchRates)
+	}
+}
@@ -0,0 +1,202 @@
+package manage_loans
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/loan"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+func (f *Feature) handleOpenLoan(w http.ResponseWriter, r *http.Request) {
+	type OpenLoanRequest struct {
+		ID         uuid.UUID       `json:"id"`
+		Name       string          `json:"name"`
+		Currency   values.Currency `json:"currency"`
+		Principal  decimal.Decimal `json:"principal"`
+		AnnualRate decimal.Decimal `json:"annual_rate"`
+		TermMonths int             `json:"term_months"`
+		StartDate  time.Time       `json:"start_date"`
+		HappenedAt time.Time       `json:"happened_at"`
+	}
+
+	var req OpenLoanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.ID == uuid.Nil {
+		req.ID = uuid.New()
+	}
+
+	if req.HappenedAt.IsZero() {
+		req.HappenedAt = time.Now()
+	}
+
+	if req.StartDate.IsZero() {
+		req.StartDate = req.HappenedAt
+	}
+
+	check, err := event_store.IfNoneMatch(req.ID, r.Header)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := f.loanDispatcher.Open(
+		event_store.WithVersionCheck(r.Context(), check),
+		req.ID,
+		req.Name,
+		req.Currency,
+		req.Principal,
+		req.AnnualRate,
+		req.TermMonths,
+		req.StartDate,
+		req.HappenedAt,
+	); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": req.ID.String()})
+}
+
+// handleGetLoan returns the state of a loan along with its amortization
+// schedule.
+func (f *Feature) handleGetLoan(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	l, version, err := f.loanDispatcher.Get(r.Context(), id)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	if l.State == loan.State_Unopened {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	type LoanResponse struct {
+		ID                 uuid.UUID          `json:"id"`
+		Name               string             `json:"name"`
+		Currency           values.Currency    `json:"currency"`
+		Principal          decimal.Decimal    `json:"principal"`
+		AnnualRate         decimal.Decimal    `json:"annual_rate"`
+		TermMonths         int                `json:"term_months"`
+		StartDate          time.Time          `json:"start_date"`
+		RemainingPrincipal decimal.Decimal    `json:"remaining_principal"`
+		InterestPaid       decimal.Decimal    `json:"interest_paid"`
+		Payments           int                `json:"payments"`
+		PaidOff            bool               `json:"paid_off"`
+		Schedule           []loan.Installment `json:"schedule"`
+	}
+
+	jsonLoan, err := json.Marshal(LoanResponse{
+		ID:                 l.ID,
+		Name:               l.Name,
+		Currency:           l.Currency,
+		Principal:          l.Principal,
+		AnnualRate:         l.AnnualRate,
+		TermMonths:         l.TermMonths,
+		StartDate:          l.StartDate,
+		RemainingPrincipal: l.RemainingPrincipal,
+		InterestPaid:       l.InterestPaid,
+		Payments:           l.Payments,
+		PaidOff:            l.State == loan.State_PaidOff,
+		Schedule:           l.Schedule(),
+	})
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(version))
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonLoan)
+}
+
+// handleRecordPayment pays a loan out of an account, splitting the amount
+// into the interest accrued since the last payment and the principal.
+func (f *Feature) handleRecordPayment(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	type RecordPaymentRequest struct {
+		AccountID  uuid.UUID       `json:"account_id"`
+		Amount     decimal.Decimal `json:"amount"`
+		HappenedAt time.Time       `json:"happened_at"`
+	}
+
+	var req RecordPaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.HappenedAt.IsZero() {
+		req.HappenedAt = time.Now()
+	}
+
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	if err := f.loanDispatcher.RecordPayment(
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
+		req.AccountID,
+		req.Amount,
+		req.HappenedAt,
+	); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// writeCommandError maps the error of a command to its response. A conflict
+// fails the precondition of a request with an If-Match header, and can be
+// retried otherwise.
+func writeCommandError(w http.ResponseWriter, check *event_store.VersionCheck, err error) {
+	switch {
+	case errors.Is(err, event_store.ErrConcurrencyConflict) && check.Expected != nil:
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+	case errors.Is(err, event_store.ErrConcurrencyConflict):
+		http.Error(w, "conflict", http.StatusConflict)
+	case errors.Is(err, loan.ErrLoanNotOpened):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, loan.ErrLoanPaidOff), errors.Is(err, loan.ErrInvalidTerms),
+		errors.Is(err, loan.ErrNegativeOrNullAmount), errors.Is(err, loan.ErrPaymentBelowInterest),
+		errors.Is(err, loan.ErrPaymentExceedsPrincipal), errors.Is(err, account.ErrAccountNotOpened),
+		errors.Is(err, account.ErrAccountClosed), errors.Is(err, account.ErrInsufficientFunds):
+		http.Error(w, "unprocessable entity: "+err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,121 @@
+package manage_loans_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/loan"
+	"github.com/somatom98/brokeli/internal/domain/position"
+	"github.com/somatom98/brokeli/internal/domain/transaction"
+	"github.com/somatom98/brokeli/internal/features/manage_loans"
+	"github.com/somatom98/brokeli/pkg/event_store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManageLoans_Handlers(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	loanES := event_store.NewInMemory[*loan.Loan](loan.New)
+	transactionES := event_store.NewInMemory[*transaction.Transaction](transaction.New)
+	accountES := event_store.NewInMemory[*account.Account](account.New)
+	uow := event_store.NewInMemoryUnitOfWork()
+	accountDispatcher := account.NewDispatcher(accountES)
+	dispatcher := loan.NewDispatcher(loanES, accountES, transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), uow), uow)
+	feature := manage_loans.New(mux, dispatcher)
+	feature.Setup()
+
+	loanID, accountID := uuid.New(), uuid.New()
+	assert.NoError(t, accountDispatcher.Open(ctx, accountID, "Checking", "EUR", time.Now()))
+	assert.NoError(t, accountDispatcher.Deposit(ctx, accountID, "EUR", decimal.NewFromInt(2000), "", "", "test-user", time.Now()))
+
+	post := func(path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	get := func(id uuid.UUID) (*httptest.ResponseRecorder, map[string]any) {
+		req := httptest.NewRequest(http.MethodGet, "/api/loans/"+id.String(), nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		var result map[string]any
+		json.NewDecoder(rec.Body).Decode(&result)
+		return rec, result
+	}
+
+	t.Run("should reject invalid terms", func(t *testing.T) {
+		// act
+		rec := post("/api/loans", `{"name":"Car","currency":"EUR","principal":"1200","annual_rate":"0.12","term_months":0}`)
+
+		// assert
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("should open a loan", func(t *testing.T) {
+		// act
+		rec := post("/api/loans", `{"id":"`+loanID.String()+`","name":"Car","currency":"EUR","principal":"1200","annual_rate":"0.12","term_months":12,"start_date":"2024-01-01T00:00:00Z"}`)
+
+		// assert
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("should return the loan with its schedule", func(t *testing.T) {
+		// act
+		rec, result := get(loanID)
+
+		// assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1200", result["remaining_principal"])
+		assert.Equal(t, false, result["paid_off"])
+		assert.Len(t, result["schedule"], 12)
+	})
+
+	t.Run("should not find an unopened loan", func(t *testing.T) {
+		// act
+		rec, _ := get(uuid.New())
+
+		// assert
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("should record a payment", func(t *testing.T) {
+		// act
+		rec := post("/api/loans/"+loanID.String()+"/payments", `{"account_id":"`+accountID.String()+`","amount":"106.62"}`)
+
+		// assert
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+
+		_, result := get(loanID)
+		assert.Equal(t, "1105.38", result["remaining_principal"])
+		assert.Equal(t, "12", result["interest_paid"])
+	})
+
+	t.Run("should reject a payment below the interest", func(t *testing.T) {
+		// act
+		rec := post("/api/loans/"+loanID.String()+"/payments", `{"account_id":"`+accountID.String()+`","amount":"5"}`)
+
+		// assert
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("should not pay an unopened loan", func(t *testing.T) {
+		// act
+		rec := post("/api/loans/"+uuid.New().String()+"/payments", `{"account_id":"`+accountID.String()+`","amount":"100"}`)
+
+		// assert
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -0,0 +1,39 @@
+package manage_loans
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/loan"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+type LoanDispatcher interface {
+	Open(ctx context.Context, id uuid.UUID, name string, currency values.Currency, principal decimal.Decimal, annualRate decimal.Decimal, termMonths int, startDate time.Time, happenedAt time.Time) error
+	RecordPayment(ctx context.Context, id uuid.UUID, accountID uuid.UUID, amount decimal.Decimal, happenedAt time.Time) error
+	Get(ctx context.Context, id uuid.UUID) (*loan.Loan, uint64, error)
+}
+
+type Feature struct {
+	httpHandler    *http.ServeMux
+	loanDispatcher LoanDispatcher
+}
+
+func New(
+	httpHandler *http.ServeMux,
+	loanDispatcher LoanDispatcher,
+) *Feature {
+	return &Feature{
+		httpHandler:    httpHandler,
+		loanDispatcher: loanDispatcher,
+	}
+}
+
+func (f *Feature) Setup() {
+	f.httpHandler.HandleFunc("POST /api/loans", f.handleOpenLoan)
+	f.httpHandler.HandleFunc("GET /api/loans/{id}", f.handleGetLoan)
+	f.httpHandler.HandleFunc("POST /api/loans/{id}/payments", f.handleRecordPayment)
+}
@@ -0,0 +1,224 @@
+package manage_prices
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/price"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+// PriceRequest is a price as it is sent, quoted on a date.
+type PriceRequest struct {
+	Ticker   string          `json:"ticker"`
+	Date     string          `json:"date"`
+	Price    decimal.Decimal `json:"price"`
+	Currency values.Currency `json:"currency"`
+}
+
+func (req PriceRequest) toPrice() (price.Price, error) {
+	date, err := time.Parse(time.DateOnly, req.Date)
+	if err != nil {
+		return price.Price{}, price.ErrInvalidPrice
+	}
+
+	p := price.Price{
+		Ticker:   req.Ticker,
+		Date:     date,
+		Price:    req.Price,
+		Currency: req.Currency,
+	}
+	return p, p.Validate()
+}
+
+func (f *Feature) handleGetPrices(w http.ResponseWriter, r *http.Request) {
+	to := time.Now()
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		t, err := time.Parse(time.DateOnly, toStr)
+		if err != nil {
+			http.Error(w, "bad request: invalid to date", http.StatusBadRequest)
+			return
+		}
+		to = t
+	}
+
+	// without a from date, the prices of the last year are listed
+	from := to.AddDate(-1, 0, 0)
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		t, err := time.Parse(time.DateOnly, fromStr)
+		if err != nil {
+			http.Error(w, "bad request: invalid from date", http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+
+	prices, err := f.priceRepository.List(r.Context(), r.PathValue("ticker"), from, to)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prices)
+}
+
+func (f *Feature) handleSavePrices(w http.ResponseWriter, r *http.Request) {
+	var req []PriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	prices := make([]price.Price, 0, len(req))
+	for _, priceReq := range req {
+		p, err := priceReq.toPrice()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		prices = append(prices, p)
+	}
+
+	if err := f.priceRepository.Save(r.Context(), prices...); err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *Feature) handleSavePrice(w http.ResponseWriter, r *http.Request) {
+	type SavePriceRequest struct {
+		Price    decimal.Decimal `json:"price"`
+		Currency values.Currency `json:"currency"`
+	}
+
+	var req SavePriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	p, err := PriceRequest{
+		Ticker:   r.PathValue("ticker"),
+		Date:     r.PathValue("date"),
+		Price:    req.Price,
+		Currency: req.Currency,
+	}.toPrice()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := f.priceRepository.Save(r.Context(), p); err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *Feature) handleFetchPrices(w http.ResponseWriter, r *http.Request) {
+	type FetchPricesRequest struct {
+		Tickers []string `json:"tickers"`
+		From    string   `json:"from"`
+		To      string   `json:"to"`
+	}
+
+	type FetchPricesResponse struct {
+		Fetched int `json:"fetched"`
+	}
+
+	var req FetchPricesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	from, err := time.Parse(time.DateOnly, req.From)
+	if err != nil {
+		http.Error(w, "bad request: invalid from date", http.StatusBadRequest)
+		return
+	}
+	to, err := time.Parse(time.DateOnly, req.To)
+	if err != nil {
+		http.Error(w, "bad request: invalid to date", http.StatusBadRequest)
+		return
+	}
+
+	fetched, err := price.Fetch(r.Context(), f.priceProvider, f.priceRepository, req.Tickers, from, to)
+	if errors.Is(err, price.ErrInvalidPrice) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, "bad gateway: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(FetchPricesResponse{Fetched: fetched})
+}
+
+func (f *Feature) handleGetValuations(w http.ResponseWriter, r *http.Request) {
+	from, ok := parseFrom(w, r)
+	if !ok {
+		return
+	}
+
+	valuations, err := f.valuer.ValueAll(r.Context(), from)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(valuations)
+}
+
+func (f *Feature) handleGetAccountValuation(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	from, ok := parseFrom(w, r)
+	if !ok {
+		return
+	}
+
+	valuations, err := f.valuer.ValueAccount(r.Context(), id, from)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(valuations)
+}
+
+// parseFrom returns the date returns are computed from, zero to compute them
+// since the holdings were first bought.
+func parseFrom(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	fromStr := r.URL.Query().Get("from")
+	if fromStr == "" {
+		return time.Time{}, true
+	}
+
+	from, err := time.Parse(time.DateOnly, fromStr)
+	if err != nil {
+		http.Error(w, "bad request: invalid from date", http.StatusBadRequest)
+		return time.Time{}, false
+	}
+
+	return from, true
+}
@@ -0,0 +1,195 @@
+package manage_prices_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/position"
+	"github.com/somatom98/brokeli/internal/domain/price"
+	"github.com/somatom98/brokeli/internal/domain/projections/holdings"
+	"github.com/somatom98/brokeli/internal/domain/transaction"
+	"github.com/somatom98/brokeli/internal/domain/valuation"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/internal/features/manage_prices"
+	"github.com/somatom98/brokeli/pkg/event_store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagePrices_Prices(t *testing.T) {
+	// arrange
+	mux := http.NewServeMux()
+	priceRepository := price.NewInMemoryRepository()
+
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "VWCE,AAPL", r.URL.Query().Get("tickers"))
+		assert.Equal(t, "2024-01-01", r.URL.Query().Get("from"))
+		assert.Equal(t, "2024-01-31", r.URL.Query().Get("to"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"ticker":"VWCE","date":"2024-01-10","price":"102","currency":"EUR"},
+			{"ticker":"AAPL","date":"2024-01-10","price":"185.5","currency":"USD"},
+			{"ticker":"MSFT","date":"2024-01-10","price":"375","currency":"USD"}
+		]`))
+	}))
+	defer provider.Close()
+
+	manage_prices.New(mux, priceRepository, price.NewHTTPProvider(provider.URL, provider.Client()), nil).Setup()
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	list := func(path string) []price.Price {
+		rec := send(http.MethodGet, path, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var result []price.Price
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		return result
+	}
+
+	t.Run("should save prices in bulk", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPut, "/api/prices", `[
+			{"ticker":"VWCE","date":"2024-01-02","price":"100","currency":"EUR"},
+			{"ticker":"VWCE","date":"2024-01-03","price":"101","currency":"EUR"}
+		]`)
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		result := list("/api/prices/VWCE?from=2024-01-01&to=2024-01-31")
+		assert.Len(t, result, 2)
+		assert.Equal(t, "100", result[0].Price.String())
+		assert.Equal(t, "101", result[1].Price.String())
+	})
+
+	t.Run("should replace the price of a ticker on a date", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPut, "/api/prices/VWCE/2024-01-03", `{"price":"101.5","currency":"EUR"}`)
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		result := list("/api/prices/VWCE?from=2024-01-03&to=2024-01-03")
+		assert.Len(t, result, 1)
+		assert.Equal(t, "101.5", result[0].Price.String())
+	})
+
+	t.Run("should reject invalid prices", func(t *testing.T) {
+		// act
+		negative := send(http.MethodPut, "/api/prices/VWCE/2024-01-04", `{"price":"-1","currency":"EUR"}`)
+		badDate := send(http.MethodPut, "/api/prices", `[{"ticker":"VWCE","date":"04/01/2024","price":"100","currency":"EUR"}]`)
+
+		// assert
+		assert.Equal(t, http.StatusUnprocessableEntity, negative.Code)
+		assert.Equal(t, http.StatusUnprocessableEntity, badDate.Code)
+	})
+
+	t.Run("should store the prices fetched from the provider", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPost, "/api/prices/fetch", `{"tickers":["VWCE","AAPL"],"from":"2024-01-01","to":"2024-01-31"}`)
+
+		// assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"fetched":2}`, rec.Body.String())
+		assert.Len(t, list("/api/prices/VWCE?from=2024-01-01&to=2024-01-31"), 3)
+		assert.Len(t, list("/api/prices/AAPL?from=2024-01-01&to=2024-01-31"), 1)
+		assert.Len(t, list("/api/prices/MSFT?from=2024-01-01&to=2024-01-31"), 0)
+	})
+}
+
+func TestManagePrices_Valuations(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	// arrange trades: 10 units bought at 100, 10 more when they are worth 110,
+	// then a dividend of 1.1 per unit paid and the price up to 121.
+	mux := http.NewServeMux()
+	transactionES := event_store.NewInMemory[*transaction.Transaction](transaction.New)
+	accountES := event_store.NewInMemory[*account.Account](account.New)
+	accountDispatcher := account.NewDispatcher(accountES)
+	transactionDispatcher := transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), event_store.NewInMemoryUnitOfWork())
+	holdingsProjection := holdings.New(transactionES, holdings.NewInMemoryRepository())
+	priceRepository := price.NewInMemoryRepository()
+	manage_prices.New(mux, priceRepository, nil, valuation.NewService(holdingsProjection, priceRepository)).Setup()
+
+	id := uuid.New()
+	eur := values.Currency("EUR")
+	assert.NoError(t, accountDispatcher.Open(ctx, id, "Broker", eur, day(1)))
+	assert.NoError(t, transactionDispatcher.RegisterInvestment(ctx, uuid.New(), id, "VWCE", decimal.NewFromInt(10), decimal.NewFromInt(100), eur, decimal.Zero, eur, day(2)))
+	assert.NoError(t, transactionDispatcher.RegisterInvestment(ctx, uuid.New(), id, "VWCE", decimal.NewFromInt(10), decimal.NewFromInt(110), eur, decimal.Zero, eur, day(3)))
+	assert.NoError(t, transactionDispatcher.RegisterDividend(ctx, uuid.New(), id, "VWCE", decimal.NewFromInt(20), decimal.RequireFromString("1.1"), eur, decimal.Zero, eur, day(4)))
+	assert.NoError(t, priceRepository.Save(ctx, price.Price{Ticker: "VWCE", Date: day(4), Price: decimal.NewFromInt(121), Currency: eur}))
+
+	get := func(path string) []valuation.AccountValuation {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var result []valuation.AccountValuation
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		return result
+	}
+
+	t.Run("should value the holdings at their latest price", func(t *testing.T) {
+		// act
+		result := get("/api/accounts/" + id.String() + "/valuation")
+
+		// assert
+		assert.Len(t, result, 1)
+		assert.Equal(t, "2420", result[0].MarketValue.String())
+		assert.Equal(t, "2100", result[0].CostBasis.String())
+		assert.Equal(t, "320", result[0].UnrealizedPL.String())
+		assert.Len(t, result[0].Holdings, 1)
+		assert.Equal(t, "121", result[0].Holdings[0].Price.String())
+		assert.Equal(t, day(4), *result[0].Holdings[0].PricedOn)
+	})
+
+	t.Run("should chain the returns between purchases since inception", func(t *testing.T) {
+		// act
+		result := get("/api/accounts/" + id.String() + "/valuation")
+
+		// assert
+		assert.Equal(t, "0.221", result[0].TimeWeightedReturn.String())
+		assert.Equal(t, "0.221", result[0].Holdings[0].TimeWeightedReturn.String())
+	})
+
+	t.Run("should compute the returns from a date", func(t *testing.T) {
+		// act
+		result := get("/api/accounts/" + id.String() + "/valuation?from=2024-01-03")
+
+		// assert
+		assert.Equal(t, "0.11", result[0].TimeWeightedReturn.String())
+	})
+
+	t.Run("should value the holdings of every account", func(t *testing.T) {
+		// act
+		result := get("/api/valuations")
+
+		// assert
+		assert.Len(t, result, 1)
+		assert.Equal(t, id, result[0].AccountID)
+	})
+
+	t.Run("should reject an invalid from date", func(t *testing.T) {
+		// act
+		req := httptest.NewRequest(http.MethodGet, "/api/valuations?from=yesterday", nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		// assert
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,50 @@
+package manage_prices
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/somatom98/brokeli/internal/domain/price"
+	"github.com/somatom98/brokeli/internal/domain/valuation"
+)
+
+type Valuer interface {
+	ValueAccount(ctx context.Context, accountID uuid.UUID, from time.Time) ([]valuation.AccountValuation, error)
+	ValueAll(ctx context.Context, from time.Time) ([]valuation.AccountValuation, error)
+}
+
+type Feature struct {
+	httpHandler     *http.ServeMux
+	priceRepository price.Repository
+	priceProvider   price.PriceProvider
+	valuer          Valuer
+}
+
+// New returns the feature managing prices and valuing holdings on them. The
+// price provider can be nil, in which case prices can only be set manually.
+func New(
+	httpHandler *http.ServeMux,
+	priceRepository price.Repository,
+	priceProvider price.PriceProvider,
+	valuer Valuer,
+) *Feature {
+	return &Feature{
+		httpHandler:     httpHandler,
+		priceRepository: priceRepository,
+		priceProvider:   priceProvider,
+		valuer:          valuer,
+	}
+}
+
+func (f *Feature) Setup() {
+	f.httpHandler.HandleFunc("GET /api/prices/{ticker}", f.handleGetPrices)
+	f.httpHandler.HandleFunc("PUT /api/prices", f.handleSavePrices)
+	f.httpHandler.HandleFunc("PUT /api/prices/{ticker}/{date}", f.handleSavePrice)
+	if f.priceProvider != nil {
+		f.httpHandler.HandleFunc("POST /api/prices/fetch", f.handleFetchPrices)
+	}
+	f.httpHandler.HandleFunc("GET /api/valuations", f.handleGetValuations)
+	f.httpHandler.HandleFunc("GET /api/accounts/{id}/valuation", f.handleGetAccountValuation)
+}
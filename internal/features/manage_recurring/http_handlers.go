@@ -0,0 +1,333 @@
+package manage_recurring
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/recurring"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+// upcomingHorizon is how far ahead occurrences are listed when no until date
+// is given.
+const upcomingHorizon = 3 * 30 * 24 * time.Hour
+
+// UpcomingOccurrence is an occurrence along with the rule it belongs to.
+type UpcomingOccurrence struct {
+	RuleID    uuid.UUID       `json:"rule_id"`
+	Kind      recurring.Kind  `json:"kind"`
+	AccountID uuid.UUID       `json:"account_id"`
+	Currency  values.Currency `json:"currency"`
+	Category  string          `json:"category"`
+	recurring.Occurrence
+}
+
+func (f *Feature) handleGetRules(w http.ResponseWriter, r *http.Request) {
+	var activeOnly bool
+	switch r.URL.Query().Get("status") {
+	case "":
+	case "active":
+		activeOnly = true
+	default:
+		http.Error(w, "bad request: invalid status", http.StatusBadRequest)
+		return
+	}
+
+	rules, err := f.recurringRulesView.ListRules(r.Context(), activeOnly)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	jsonRules, err := json.Marshal(rules)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonRules)
+}
+
+func (f *Feature) handleCreateRule(w http.ResponseWriter, r *http.Request) {
+	type CreateRuleRequest struct {
+		ID          uuid.UUID           `json:"id"`
+		Kind        recurring.Kind      `json:"kind"`
+		AccountID   uuid.UUID           `json:"account_id"`
+		Currency    values.Currency     `json:"currency"`
+		Amount      decimal.Decimal     `json:"amount"`
+		Category    string              `json:"category"`
+		Description string              `json:"description"`
+		Frequency   recurring.Frequency `json:"frequency"`
+		Interval    int                 `json:"interval"`
+		StartDate   time.Time           `json:"start_date"`
+		EndDate     *time.Time          `json:"end_date"`
+		HappenedAt  time.Time           `json:"happened_at"`
+	}
+
+	var req CreateRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.ID == uuid.Nil {
+		req.ID = uuid.New()
+	}
+
+	if req.HappenedAt.IsZero() {
+		req.HappenedAt = time.Now()
+	}
+
+	if req.StartDate.IsZero() {
+		req.StartDate = req.HappenedAt
+	}
+
+	if req.Interval == 0 {
+		req.Interval = 1
+	}
+
+	check, err := event_store.IfNoneMatch(req.ID, r.Header)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := f.recurringDispatcher.Create(event_store.WithVersionCheck(r.Context(), check), req.ID, recurring.Definition{
+		Kind:        req.Kind,
+		AccountID:   req.AccountID,
+		Currency:    req.Currency,
+		Amount:      req.Amount,
+		Category:    req.Category,
+		Description: req.Description,
+		Cadence: recurring.Cadence{
+			Frequency: req.Frequency,
+			Interval:  req.Interval,
+		},
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+	}, req.HappenedAt); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": req.ID.String()})
+}
+
+// handleGetUpcoming lists the occurrences of every active rule up to the
+// until date, by date.
+func (f *Feature) handleGetUpcoming(w http.ResponseWriter, r *http.Request) {
+	until, ok := parseUntil(w, r)
+	if !ok {
+		return
+	}
+
+	ids, err := f.recurringRulesView.ListActiveRuleIDs(r.Context())
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	occurrences := make([]UpcomingOccurrence, 0)
+	for _, id := range ids {
+		rule, _, err := f.recurringDispatcher.Get(r.Context(), id)
+		if err != nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		occurrences = append(occurrences, upcoming(rule, until)...)
+	}
+
+	slices.SortStableFunc(occurrences, func(a, b UpcomingOccurrence) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	jsonOccurrences, err := json.Marshal(occurrences)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonOccurrences)
+}
+
+// handleGetOccurrences lists the occurrences of a rule not posted yet, up to
+// the until date.
+func (f *Feature) handleGetOccurrences(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	until, ok := parseUntil(w, r)
+	if !ok {
+		return
+	}
+
+	rule, version, err := f.recurringDispatcher.Get(r.Context(), id)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	if rule.State == recurring.State_Uncreated {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	jsonOccurrences, err := json.Marshal(upcoming(rule, until))
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(version))
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonOccurrences)
+}
+
+func (f *Feature) handleModifyOccurrence(w http.ResponseWriter, r *http.Request) {
+	id, number, ok := parseOccurrence(w, r)
+	if !ok {
+		return
+	}
+
+	type ModifyOccurrenceRequest struct {
+		Date        *time.Time       `json:"date"`
+		Amount      *decimal.Decimal `json:"amount"`
+		Description *string          `json:"description"`
+		HappenedAt  time.Time        `json:"happened_at"`
+	}
+
+	var req ModifyOccurrenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.HappenedAt.IsZero() {
+		req.HappenedAt = time.Now()
+	}
+
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	if err := f.recurringDispatcher.Modify(event_store.WithVersionCheck(r.Context(), check), id, number, recurring.Change{
+		Date:        req.Date,
+		Amount:      req.Amount,
+		Description: req.Description,
+	}, req.HappenedAt); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *Feature) handleSkipOccurrence(w http.ResponseWriter, r *http.Request) {
+	id, number, ok := parseOccurrence(w, r)
+	if !ok {
+		return
+	}
+
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	if err := f.recurringDispatcher.Skip(event_store.WithVersionCheck(r.Context(), check), id, number, time.Now()); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func upcoming(rule *recurring.Rule, until time.Time) []UpcomingOccurrence {
+	occurrences := make([]UpcomingOccurrence, 0)
+	for _, occurrence := range rule.Upcoming(until) {
+		occurrences = append(occurrences, UpcomingOccurrence{
+			RuleID:     rule.ID,
+			Kind:       rule.Kind,
+			AccountID:  rule.AccountID,
+			Currency:   rule.Currency,
+			Category:   rule.Category,
+			Occurrence: occurrence,
+		})
+	}
+	return occurrences
+}
+
+func parseUntil(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	untilStr := r.URL.Query().Get("until")
+	if untilStr == "" {
+		return time.Now().Add(upcomingHorizon), true
+	}
+
+	until, err := time.Parse(time.DateOnly, untilStr)
+	if err != nil {
+		http.Error(w, "bad request: invalid until date", http.StatusBadRequest)
+		return time.Time{}, false
+	}
+
+	return until, true
+}
+
+func parseOccurrence(w http.ResponseWriter, r *http.Request) (uuid.UUID, int, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return uuid.Nil, 0, false
+	}
+
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil {
+		http.Error(w, "invalid occurrence number", http.StatusBadRequest)
+		return uuid.Nil, 0, false
+	}
+
+	return id, number, true
+}
+
+// writeCommandError maps the error of a command to its response. A conflict
+// fails the precondition of a request with an If-Match header, and can be
+// retried otherwise.
+func writeCommandError(w http.ResponseWriter, check *event_store.VersionCheck, err error) {
+	switch {
+	case errors.Is(err, event_store.ErrConcurrencyConflict) && check.Expected != nil:
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+	case errors.Is(err, event_store.ErrConcurrencyConflict):
+		http.Error(w, "conflict", http.StatusConflict)
+	case errors.Is(err, recurring.ErrRuleNotCreated), errors.Is(err, recurring.ErrOccurrenceNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, recurring.ErrRuleFinished), errors.Is(err, recurring.ErrInvalidRule),
+		errors.Is(err, recurring.ErrNegativeOrNullAmount), errors.Is(err, recurring.ErrOccurrencePosted),
+		errors.Is(err, recurring.ErrOccurrenceSkipped), errors.Is(err, recurring.ErrInvalidOccurrenceDate):
+		http.Error(w, "unprocessable entity: "+err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
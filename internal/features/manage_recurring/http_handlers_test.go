@@ -0,0 +1,137 @@
+package manage_recurring_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/position"
+	"github.com/somatom98/brokeli/internal/domain/projections/recurring_rules"
+	"github.com/somatom98/brokeli/internal/domain/recurring"
+	"github.com/somatom98/brokeli/internal/domain/transaction"
+	"github.com/somatom98/brokeli/internal/features/manage_recurring"
+	"github.com/somatom98/brokeli/pkg/event_store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManageRecurring_Handlers(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	ruleES := event_store.NewInMemory[*recurring.Rule](recurring.New)
+	transactionES := event_store.NewInMemory[*transaction.Transaction](transaction.New)
+	accountES := event_store.NewInMemory[*account.Account](account.New)
+	uow := event_store.NewInMemoryUnitOfWork()
+	dispatcher := recurring.NewDispatcher(ruleES, transaction.NewDispatcher(transactionES, accountES, event_store.NewInMemory(position.New), uow), uow)
+	rulesProjection := recurring_rules.New(ruleES, recurring_rules.NewInMemoryRepository())
+	feature := manage_recurring.New(mux, dispatcher, rulesProjection)
+	feature.Setup()
+
+	ruleID, accountID := uuid.New(), uuid.New()
+	require.NoError(t, account.NewDispatcher(accountES).Open(ctx, accountID, "Checking", "EUR", time.Now()))
+
+	send := func(method string, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	occurrences := func(path string) []manage_recurring.UpcomingOccurrence {
+		rec := send(http.MethodGet, path, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var result []manage_recurring.UpcomingOccurrence
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		return result
+	}
+
+	occurrencesPath := "/api/recurring-rules/" + ruleID.String() + "/occurrences"
+
+	t.Run("should reject an invalid frequency", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPost, "/api/recurring-rules", `{"kind":"EXPENSE","account_id":"`+accountID.String()+`","currency":"EUR","amount":"950","frequency":"HOURLY","start_date":"2030-01-01T00:00:00Z"}`)
+
+		// assert
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("should create a rule", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPost, "/api/recurring-rules", `{"id":"`+ruleID.String()+`","kind":"EXPENSE","account_id":"`+accountID.String()+`","currency":"EUR","amount":"950","category":"Rent","description":"Flat","frequency":"MONTHLY","start_date":"2030-01-31T00:00:00Z"}`)
+
+		// assert
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+
+		rec = send(http.MethodGet, "/api/recurring-rules?status=active", "")
+		var rules []recurring_rules.Rule
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&rules))
+		require.Len(t, rules, 1)
+		assert.Equal(t, ruleID, rules[0].ID)
+	})
+
+	t.Run("should list the upcoming occurrences of a rule", func(t *testing.T) {
+		// act
+		result := occurrences(occurrencesPath + "?until=2030-03-31")
+
+		// assert
+		require.Len(t, result, 3)
+		assert.Equal(t, time.Date(2030, 2, 28, 0, 0, 0, 0, time.UTC), result[1].Date)
+		assert.Equal(t, "Rent", result[1].Category)
+	})
+
+	t.Run("should skip an occurrence", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPost, occurrencesPath+"/2/skip", "")
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.True(t, occurrences(occurrencesPath + "?until=2030-03-31")[1].Skipped)
+	})
+
+	t.Run("should modify an occurrence", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPatch, occurrencesPath+"/3", `{"amount":"980","date":"2030-03-29T00:00:00Z"}`)
+
+		// assert
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+
+		result := occurrences("/api/recurring-rules/upcoming?until=2030-03-31")
+		require.Len(t, result, 3)
+		assert.Equal(t, ruleID, result[2].RuleID)
+		assert.Equal(t, "980", result[2].Amount.String())
+		assert.True(t, result[2].Modified)
+	})
+
+	t.Run("should reject a date past the next occurrence", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPatch, occurrencesPath+"/3", `{"date":"2030-05-01T00:00:00Z"}`)
+
+		// assert
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("should not find the occurrences of an unknown rule", func(t *testing.T) {
+		// act
+		rec := send(http.MethodGet, "/api/recurring-rules/"+uuid.New().String()+"/occurrences", "")
+
+		// assert
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("should reject an invalid occurrence number", func(t *testing.T) {
+		// act
+		rec := send(http.MethodPost, occurrencesPath+"/first/skip", "")
+
+		// assert
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
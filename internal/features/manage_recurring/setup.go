@@ -0,0 +1,45 @@
+package manage_recurring
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/somatom98/brokeli/internal/domain/projections/recurring_rules"
+	"github.com/somatom98/brokeli/internal/domain/recurring"
+)
+
+type RecurringDispatcher interface {
+	Create(ctx context.Context, id uuid.UUID, definition recurring.Definition, happenedAt time.Time) error
+	Skip(ctx context.Context, id uuid.UUID, n int, happenedAt time.Time) error
+	Modify(ctx context.Context, id uuid.UUID, n int, change recurring.Change, happenedAt time.Time) error
+	Get(ctx context.Context, id uuid.UUID) (*recurring.Rule, uint64, error)
+}
+
+type Feature struct {
+	httpHandler         *http.ServeMux
+	recurringDispatcher RecurringDispatcher
+	recurringRulesView  *recurring_rules.Projection
+}
+
+func New(
+	httpHandler *http.ServeMux,
+	recurringDispatcher RecurringDispatcher,
+	recurringRulesView *recurring_rules.Projection,
+) *Feature {
+	return &Feature{
+		httpHandler:         httpHandler,
+		recurringDispatcher: recurringDispatcher,
+		recurringRulesView:  recurringRulesView,
+	}
+}
+
+func (f *Feature) Setup() {
+	f.httpHandler.HandleFunc("GET /api/recurring-rules", f.handleGetRules)
+	f.httpHandler.HandleFunc("POST /api/recurring-rules", f.handleCreateRule)
+	f.httpHandler.HandleFunc("GET /api/recurring-rules/upcoming", f.handleGetUpcoming)
+	f.httpHandler.HandleFunc("GET /api/recurring-rules/{id}/occurrences", f.handleGetOccurrences)
+	f.httpHandler.HandleFunc("PATCH /api/recurring-rules/{id}/occurrences/{number}", f.handleModifyOccurrence)
+	f.httpHandler.HandleFunc("POST /api/recurring-rules/{id}/occurrences/{number}/skip", f.handleSkipOccurrence)
+}
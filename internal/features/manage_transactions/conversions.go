@@ -0,0 +1,26 @@
+package manage_transactions
+
+import (
+	"context"
+
+	"github.com/somatom98/brokeli/internal/domain/projections/transactions"
+	"github.com/somatom98/brokeli/internal/domain/values"
+)
+
+// convertTransactions converts the amounts of the transactions to the base
+// currency, at the rates of the day they happened.
+func (f *Feature) convertTransactions(ctx context.Context, records []transactions.TransactionRecord, base values.Currency) ([]transactions.TransactionRecord, error) {
+	converted := make([]transactions.TransactionRecord, 0, len(records))
+	for _, record := range records {
+		amount, err := f.converter.Convert(ctx, record.Amount, record.Currency, base, record.HappenedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		record.Amount = amount
+		record.Currency = base
+		converted = append(converted, record)
+	}
+
+	return converted, nil
+}
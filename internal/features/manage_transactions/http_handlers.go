@@ -2,14 +2,21 @@ package manage_transactions
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/fx"
+	"github.com/somatom98/brokeli/internal/domain/position"
+	"github.com/somatom98/brokeli/internal/domain/projections/expenses"
 	"github.com/somatom98/brokeli/internal/domain/projections/transactions"
+	"github.com/somatom98/brokeli/internal/domain/transaction"
 	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/pkg/event_store"
 )
 
 func (f *Feature) handleRegisterExpense(w http.ResponseWriter, r *http.Request) {
@@ -32,9 +39,16 @@ func (f *Feature) handleRegisterExpense(w http.ResponseWriter, r *http.Request)
 		req.HappenedAt = time.Now()
 	}
 
+	id := uuid.Must(uuid.NewV7())
+	check, err := event_store.IfNoneMatch(id, r.Header)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if err := f.dispatcher.RegisterExpense(
-		r.Context(),
-		uuid.Must(uuid.NewV7()),
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
 		req.AccountID,
 		req.Currency,
 		req.Amount,
@@ -42,12 +56,77 @@ func (f *Feature) handleRegisterExpense(w http.ResponseWriter, r *http.Request)
 		req.Description,
 		req.HappenedAt,
 	); err != nil {
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": id.String()})
+}
+
+func (f *Feature) handleRegisterSplitExpense(w http.ResponseWriter, r *http.Request) {
+	type SplitLine struct {
+		AccountID   uuid.UUID       `json:"account_id"`
+		Category    string          `json:"category"`
+		Amount      decimal.Decimal `json:"amount"`
+		Description string          `json:"description"`
+	}
+	type RegisterSplitExpenseRequest struct {
+		AccountID   uuid.UUID       `json:"account_id"`
+		Currency    values.Currency `json:"currency"`
+		Amount      decimal.Decimal `json:"amount"`
+		Lines       []SplitLine     `json:"lines"`
+		Description string          `json:"description"`
+		HappenedAt  time.Time       `json:"happened_at"`
+	}
+	var req RegisterSplitExpenseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.HappenedAt.IsZero() {
+		req.HappenedAt = time.Now()
+	}
+
+	lines := make([]values.SplitLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, values.SplitLine{
+			AccountID:   line.AccountID,
+			Category:    line.Category,
+			Amount:      line.Amount,
+			Description: line.Description,
+		})
+	}
+
+	id := uuid.Must(uuid.NewV7())
+	check, err := event_store.IfNoneMatch(id, r.Header)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := f.dispatcher.RegisterSplitExpense(
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
+		req.AccountID,
+		req.Currency,
+		req.Amount,
+		lines,
+		req.Description,
+		req.HappenedAt,
+	); err != nil {
+		writeCommandError(w, check, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
 	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": id.String()})
 }
 
 func (f *Feature) handleRegisterIncome(w http.ResponseWriter, r *http.Request) {
@@ -70,9 +149,16 @@ func (f *Feature) handleRegisterIncome(w http.ResponseWriter, r *http.Request) {
 		req.HappenedAt = time.Now()
 	}
 
+	id := uuid.Must(uuid.NewV7())
+	check, err := event_store.IfNoneMatch(id, r.Header)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if err := f.dispatcher.RegisterIncome(
-		r.Context(),
-		uuid.Must(uuid.NewV7()),
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
 		req.AccountID,
 		req.Currency,
 		req.Amount,
@@ -80,12 +166,14 @@ func (f *Feature) handleRegisterIncome(w http.ResponseWriter, r *http.Request) {
 		req.Description,
 		req.HappenedAt,
 	); err != nil {
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		writeCommandError(w, check, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
 	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": id.String()})
 }
 
 func (f *Feature) handleRegisterTransfer(w http.ResponseWriter, r *http.Request) {
@@ -111,9 +199,16 @@ func (f *Feature) handleRegisterTransfer(w http.ResponseWriter, r *http.Request)
 		req.HappenedAt = time.Now()
 	}
 
+	id := uuid.Must(uuid.NewV7())
+	check, err := event_store.IfNoneMatch(id, r.Header)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if err := f.dispatcher.RegisterTransfer(
-		r.Context(),
-		uuid.Must(uuid.NewV7()),
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
 		req.FromAccountID,
 		req.FromCurrency,
 		req.FromAmount,
@@ -124,12 +219,14 @@ func (f *Feature) handleRegisterTransfer(w http.ResponseWriter, r *http.Request)
 		req.Description,
 		req.HappenedAt,
 	); err != nil {
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		writeCommandError(w, check, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
 	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": id.String()})
 }
 
 func (f *Feature) handleRegisterReimbursement(w http.ResponseWriter, r *http.Request) {
@@ -139,7 +236,7 @@ func (f *Feature) handleRegisterReimbursement(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	id, err := uuid.Parse(transactionID)
+	expenseID, err := uuid.Parse(transactionID)
 	if err != nil {
 		http.Error(w, "bad request: invalid transaction id", http.StatusBadRequest)
 		return
@@ -166,9 +263,18 @@ func (f *Feature) handleRegisterReimbursement(w http.ResponseWriter, r *http.Req
 		req.HappenedAt = time.Now()
 	}
 
+	// The If-Match header and the ETag refer to the expense.
+	check, err := event_store.IfMatch(expenseID, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	id := uuid.Must(uuid.NewV7())
 	if err := f.dispatcher.RegisterReimbursement(
-		r.Context(),
+		event_store.WithVersionCheck(r.Context(), check),
 		id,
+		expenseID,
 		req.AccountID,
 		req.From,
 		req.Currency,
@@ -177,12 +283,14 @@ func (f *Feature) handleRegisterReimbursement(w http.ResponseWriter, r *http.Req
 		req.Description,
 		req.HappenedAt,
 	); err != nil {
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		writeCommandError(w, check, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
 	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": id.String()})
 }
 
 func (f *Feature) handleSetExpectedReimbursement(w http.ResponseWriter, r *http.Request) {
@@ -200,6 +308,7 @@ func (f *Feature) handleSetExpectedReimbursement(w http.ResponseWriter, r *http.
 
 	type SetExpectedReimbursementRequest struct {
 		AccountID  uuid.UUID       `json:"account_id"`
+		From       string          `json:"from"`
 		Currency   values.Currency `json:"currency"`
 		Amount     decimal.Decimal `json:"amount"`
 		HappenedAt time.Time       `json:"happened_at"`
@@ -216,19 +325,27 @@ func (f *Feature) handleSetExpectedReimbursement(w http.ResponseWriter, r *http.
 		req.HappenedAt = time.Now()
 	}
 
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
 	if err := f.dispatcher.SetExpectedReimbursement(
-		r.Context(),
+		event_store.WithVersionCheck(r.Context(), check),
 		id,
 		req.AccountID,
+		req.From,
 		req.Currency,
 		req.Amount,
 		req.HappenedAt,
 	); err != nil {
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		writeCommandError(w, check, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
 	w.WriteHeader(http.StatusCreated)
 }
 
@@ -255,9 +372,16 @@ func (f *Feature) handleRegisterInvestment(w http.ResponseWriter, r *http.Reques
 		req.HappenedAt = time.Now()
 	}
 
+	id := uuid.Must(uuid.NewV7())
+	check, err := event_store.IfNoneMatch(id, r.Header)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if err := f.dispatcher.RegisterInvestment(
-		r.Context(),
-		uuid.Must(uuid.NewV7()),
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
 		req.AccountID,
 		req.Ticker,
 		req.Units,
@@ -267,12 +391,436 @@ func (f *Feature) handleRegisterInvestment(w http.ResponseWriter, r *http.Reques
 		req.FeeCurrency,
 		req.HappenedAt,
 	); err != nil {
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		writeCommandError(w, check, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
 	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": id.String()})
+}
+
+func (f *Feature) handleRegisterSale(w http.ResponseWriter, r *http.Request) {
+	type RegisterSaleRequest struct {
+		AccountID     uuid.UUID       `json:"account_id"`
+		Ticker        string          `json:"ticker"`
+		Units         decimal.Decimal `json:"units"`
+		Price         decimal.Decimal `json:"price"`
+		PriceCurrency values.Currency `json:"price_currency"`
+		Fee           decimal.Decimal `json:"fee"`
+		FeeCurrency   values.Currency `json:"fee_currency"`
+		HappenedAt    time.Time       `json:"happened_at"`
+	}
+
+	var req RegisterSaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.HappenedAt.IsZero() {
+		req.HappenedAt = time.Now()
+	}
+
+	id := uuid.Must(uuid.NewV7())
+	check, err := event_store.IfNoneMatch(id, r.Header)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := f.dispatcher.RegisterSale(
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
+		req.AccountID,
+		req.Ticker,
+		req.Units,
+		req.Price,
+		req.PriceCurrency,
+		req.Fee,
+		req.FeeCurrency,
+		req.HappenedAt,
+	); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": id.String()})
+}
+
+func (f *Feature) handleRegisterDividend(w http.ResponseWriter, r *http.Request) {
+	type RegisterDividendRequest struct {
+		AccountID     uuid.UUID       `json:"account_id"`
+		Ticker        string          `json:"ticker"`
+		Units         decimal.Decimal `json:"units"`
+		Price         decimal.Decimal `json:"price"`
+		PriceCurrency values.Currency `json:"price_currency"`
+		Fee           decimal.Decimal `json:"fee"`
+		FeeCurrency   values.Currency `json:"fee_currency"`
+		HappenedAt    time.Time       `json:"happened_at"`
+	}
+
+	var req RegisterDividendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.HappenedAt.IsZero() {
+		req.HappenedAt = time.Now()
+	}
+
+	id := uuid.Must(uuid.NewV7())
+	check, err := event_store.IfNoneMatch(id, r.Header)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := f.dispatcher.RegisterDividend(
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
+		req.AccountID,
+		req.Ticker,
+		req.Units,
+		req.Price,
+		req.PriceCurrency,
+		req.Fee,
+		req.FeeCurrency,
+		req.HappenedAt,
+	); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": id.String()})
+}
+
+func (f *Feature) handleRegisterInterest(w http.ResponseWriter, r *http.Request) {
+	type RegisterInterestRequest struct {
+		AccountID     uuid.UUID       `json:"account_id"`
+		Ticker        string          `json:"ticker"`
+		Units         decimal.Decimal `json:"units"`
+		Price         decimal.Decimal `json:"price"`
+		PriceCurrency values.Currency `json:"price_currency"`
+		Fee           decimal.Decimal `json:"fee"`
+		FeeCurrency   values.Currency `json:"fee_currency"`
+		HappenedAt    time.Time       `json:"happened_at"`
+	}
+
+	var req RegisterInterestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.HappenedAt.IsZero() {
+		req.HappenedAt = time.Now()
+	}
+
+	id := uuid.Must(uuid.NewV7())
+	check, err := event_store.IfNoneMatch(id, r.Header)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := f.dispatcher.RegisterInterest(
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
+		req.AccountID,
+		req.Ticker,
+		req.Units,
+		req.Price,
+		req.PriceCurrency,
+		req.Fee,
+		req.FeeCurrency,
+		req.HappenedAt,
+	); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": id.String()})
+}
+
+func (f *Feature) handleRegisterStockSplit(w http.ResponseWriter, r *http.Request) {
+	type RegisterStockSplitRequest struct {
+		AccountID  uuid.UUID       `json:"account_id"`
+		Ticker     string          `json:"ticker"`
+		Ratio      decimal.Decimal `json:"ratio"`
+		HappenedAt time.Time       `json:"happened_at"`
+	}
+
+	var req RegisterStockSplitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.HappenedAt.IsZero() {
+		req.HappenedAt = time.Now()
+	}
+
+	id := uuid.Must(uuid.NewV7())
+	check, err := event_store.IfNoneMatch(id, r.Header)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := f.dispatcher.RegisterStockSplit(
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
+		req.AccountID,
+		req.Ticker,
+		req.Ratio,
+		req.HappenedAt,
+	); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": id.String()})
+}
+
+func (f *Feature) handleRegisterInvestmentFee(w http.ResponseWriter, r *http.Request) {
+	type RegisterInvestmentFeeRequest struct {
+		AccountID   uuid.UUID       `json:"account_id"`
+		Ticker      string          `json:"ticker"`
+		Fee         decimal.Decimal `json:"fee"`
+		FeeCurrency values.Currency `json:"fee_currency"`
+		HappenedAt  time.Time       `json:"happened_at"`
+	}
+
+	var req RegisterInvestmentFeeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.HappenedAt.IsZero() {
+		req.HappenedAt = time.Now()
+	}
+
+	id := uuid.Must(uuid.NewV7())
+	check, err := event_store.IfNoneMatch(id, r.Header)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := f.dispatcher.RegisterInvestmentFee(
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
+		req.AccountID,
+		req.Ticker,
+		req.Fee,
+		req.FeeCurrency,
+		req.HappenedAt,
+	); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": id.String()})
+}
+
+func (f *Feature) handleAmendTransaction(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "bad request: invalid transaction id", http.StatusBadRequest)
+		return
+	}
+
+	type AmendTransactionRequest struct {
+		Amount      *decimal.Decimal `json:"amount"`
+		Category    *string          `json:"category"`
+		Description *string          `json:"description"`
+		HappenedAt  *time.Time       `json:"happened_at"`
+	}
+
+	var req AmendTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	if err := f.dispatcher.Amend(
+		event_store.WithVersionCheck(r.Context(), check),
+		id,
+		transaction.Amendment{
+			Amount:      req.Amount,
+			Category:    req.Category,
+			Description: req.Description,
+			HappenedAt:  req.HappenedAt,
+		},
+	); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleVoidTransaction voids a transaction, reversing its effect on the
+// balances of its accounts.
+func (f *Feature) handleVoidTransaction(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "bad request: invalid transaction id", http.StatusBadRequest)
+		return
+	}
+
+	check, err := event_store.IfMatch(id, r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, "bad request: invalid If-Match header", http.StatusBadRequest)
+		return
+	}
+
+	if err := f.dispatcher.Void(event_store.WithVersionCheck(r.Context(), check), id); err != nil {
+		writeCommandError(w, check, err)
+		return
+	}
+
+	w.Header().Set("ETag", event_store.ETag(check.Version))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type Revision struct {
+	Version       uint64      `json:"version"`
+	Type          string      `json:"type"`
+	Content       any         `json:"content"`
+	CorrelationID uuid.UUID   `json:"correlation_id"`
+	Actor         string      `json:"actor"`
+	Source        string      `json:"source"`
+	RecordedAt    time.Time   `json:"recorded_at"`
+	Transaction   Transaction `json:"transaction"`
+}
+
+// Transaction is the state of a transaction as of a revision.
+type Transaction struct {
+	Type        values.TransactionType `json:"type"`
+	Entries     []Entry                `json:"entries"`
+	Lines       []Line                 `json:"lines,omitempty"`
+	Category    string                 `json:"category"`
+	Description string                 `json:"description"`
+	HappenedAt  time.Time              `json:"happened_at"`
+	Voided      bool                   `json:"voided"`
+}
+
+type Entry struct {
+	AccountID uuid.UUID       `json:"account_id"`
+	Currency  values.Currency `json:"currency"`
+	// Amount is signed: negative for the money leaving the account.
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// Line is a line of a split expense.
+type Line struct {
+	AccountID   uuid.UUID       `json:"account_id"`
+	Category    string          `json:"category"`
+	Amount      decimal.Decimal `json:"amount"`
+	Description string          `json:"description"`
+}
+
+// handleGetTransactionHistory returns every revision of a transaction, from
+// the event store, with its state after each of them.
+func (f *Feature) handleGetTransactionHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "bad request: invalid transaction id", http.StatusBadRequest)
+		return
+	}
+
+	history, err := f.dispatcher.History(r.Context(), id)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	if len(history) == 0 {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	revisions := make([]Revision, 0, len(history))
+	for _, revision := range history {
+		entries := make([]Entry, 0, len(revision.Transaction.Entries))
+		for _, entry := range revision.Transaction.Entries {
+			entries = append(entries, Entry{
+				AccountID: entry.AccountID,
+				Currency:  entry.Currency,
+				Amount:    entry.Side.Signed(entry.Amount),
+			})
+		}
+
+		var lines []Line
+		for _, line := range revision.Transaction.Lines {
+			lines = append(lines, Line{
+				AccountID:   line.AccountID,
+				Category:    line.Category,
+				Amount:      line.Amount,
+				Description: line.Description,
+			})
+		}
+
+		revisions = append(revisions, Revision{
+			Version:       revision.Record.Version,
+			Type:          revision.Record.Type(),
+			Content:       revision.Record.Content(),
+			CorrelationID: revision.Record.Metadata.CorrelationID,
+			Actor:         revision.Record.Metadata.Actor,
+			Source:        revision.Record.Metadata.Source,
+			RecordedAt:    revision.Record.RecordedAt,
+			Transaction: Transaction{
+				Type:        revision.Transaction.Type,
+				Entries:     entries,
+				Lines:       lines,
+				Category:    revision.Transaction.Category,
+				Description: revision.Transaction.Description,
+				HappenedAt:  revision.Transaction.HappenedAt,
+				Voided:      revision.Transaction.State == transaction.State_Deleted,
+			},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", event_store.ETag(revisions[len(revisions)-1].Version))
+	if err := json.NewEncoder(w).Encode(revisions); err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
 }
 
 func (f *Feature) handleGetTransactions(w http.ResponseWriter, r *http.Request) {
@@ -328,6 +876,14 @@ func (f *Feature) handleGetTransactions(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
+		if base := fx.BaseCurrency(r); base != "" {
+			results.Transactions, err = f.convertTransactions(r.Context(), results.Transactions, base)
+			if err != nil {
+				fx.WriteError(w, err)
+				return
+			}
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(results); err != nil {
 			http.Error(w, "internal error", http.StatusInternalServerError)
@@ -342,9 +898,78 @@ func (f *Feature) handleGetTransactions(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if base := fx.BaseCurrency(r); base != "" {
+		transactions, err = f.convertTransactions(r.Context(), transactions, base)
+		if err != nil {
+			fx.WriteError(w, err)
+			return
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(transactions); err != nil {
 		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
 }
+
+// handleGetExpense returns an expense with the reimbursements expected and
+// received for it, and its net cost.
+func (f *Feature) handleGetExpense(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "bad request: invalid transaction id", http.StatusBadRequest)
+		return
+	}
+
+	expense, err := f.expensesView.GetExpense(r.Context(), id)
+	if errors.Is(err, expenses.ErrNotFound) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(expense); err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (f *Feature) handleGetReceivables(w http.ResponseWriter, r *http.Request) {
+	receivables, err := f.expensesView.ListReceivables(r.Context())
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(receivables); err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// writeCommandError maps the error of a command to its response. A conflict
+// fails the precondition of a request with an If-Match header, and can be
+// retried otherwise.
+func writeCommandError(w http.ResponseWriter, check *event_store.VersionCheck, err error) {
+	switch {
+	case errors.Is(err, event_store.ErrConcurrencyConflict) && check.Expected != nil:
+		http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+	case errors.Is(err, event_store.ErrConcurrencyConflict):
+		http.Error(w, "conflict", http.StatusConflict)
+	case errors.Is(err, transaction.ErrTransactionNotRecorded):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, transaction.ErrNotAmendable), errors.Is(err, transaction.ErrNotVoidable), errors.Is(err, transaction.ErrNegativeOrNullAmount),
+		errors.Is(err, transaction.ErrInvalidSplit), errors.Is(err, transaction.ErrNotReimbursable), errors.Is(err, transaction.ErrOverReimbursed),
+		errors.Is(err, transaction.ErrInvalidAccount), errors.Is(err, transaction.ErrInvalidAmountOrCurrency),
+		errors.Is(err, account.ErrAccountClosed), errors.Is(err, account.ErrInsufficientFunds), errors.Is(err, position.ErrInsufficientUnits):
+		http.Error(w, "unprocessable entity: "+err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
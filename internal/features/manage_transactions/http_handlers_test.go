@@ -0,0 +1,424 @@
+package manage_transactions_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/fx"
+	"github.com/somatom98/brokeli/internal/domain/position"
+	"github.com/somatom98/brokeli/internal/domain/projections/transactions"
+	"github.com/somatom98/brokeli/internal/domain/transaction"
+	"github.com/somatom98/brokeli/internal/domain/transaction/events"
+	"github.com/somatom98/brokeli/internal/domain/values"
+	"github.com/somatom98/brokeli/internal/features/manage_transactions"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+func TestManageTransactions_Amend(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	dispatcher := transaction.NewDispatcher(
+		event_store.NewInMemory(transaction.New),
+		event_store.NewInMemory(account.New),
+		event_store.NewInMemory(position.New),
+		event_store.NewInMemoryUnitOfWork(),
+	)
+	manage_transactions.New(mux, dispatcher, nil, nil, nil).Setup()
+
+	id := uuid.New()
+	accountID := uuid.New()
+	happenedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, dispatcher.RegisterExpense(ctx, id, accountID, "EUR", decimal.NewFromInt(40), "Groceries", "Weekly shopping", happenedAt))
+
+	amend := func(body, ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/transactions/"+id.String(), bytes.NewBufferString(body))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("PATCH /api/transactions/{id}", func(t *testing.T) {
+		rec := amend(`{"amount":"45","category":"Household"}`, `"1"`)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("PATCH /api/transactions/{id} - stale version", func(t *testing.T) {
+		rec := amend(`{"description":"Shopping"}`, `"1"`)
+
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	})
+
+	t.Run("PATCH /api/transactions/{id} - invalid amount", func(t *testing.T) {
+		rec := amend(`{"amount":"-5"}`, "")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("GET /api/transactions/{id}/history", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/transactions/"+id.String()+"/history", nil)
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+
+		var revisions []manage_transactions.Revision
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&revisions))
+		require.Len(t, revisions, 3)
+		assert.Equal(t, events.TypeMoneySpent, revisions[0].Type)
+		assert.Equal(t, "-40", revisions[0].Transaction.Entries[0].Amount.String())
+		assert.Equal(t, events.TypeAmountChanged, revisions[1].Type)
+		assert.Equal(t, "-45", revisions[1].Transaction.Entries[0].Amount.String())
+		assert.Equal(t, events.TypeRecategorized, revisions[2].Type)
+		assert.Equal(t, "Household", revisions[2].Transaction.Category)
+	})
+
+	t.Run("GET /api/transactions/{id}/history - unknown transaction", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/transactions/"+uuid.New().String()+"/history", nil)
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestManageTransactions_Create(t *testing.T) {
+	// arrange
+	mux := http.NewServeMux()
+	dispatcher := transaction.NewDispatcher(
+		event_store.NewInMemory(transaction.New),
+		event_store.NewInMemory(account.New),
+		event_store.NewInMemory(position.New),
+		event_store.NewInMemoryUnitOfWork(),
+	)
+	manage_transactions.New(mux, dispatcher, nil, nil, nil).Setup()
+
+	send := func(method, target, body string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	expense := `{"account_id":"` + uuid.NewString() + `","currency":"EUR","amount":"40","category":"Groceries"}`
+
+	t.Run("POST /api/expenses - returns the id the ETag belongs to", func(t *testing.T) {
+		rec := send(http.MethodPost, "/api/expenses", expense, nil)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var created struct {
+			ID uuid.UUID `json:"id"`
+		}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+		assert.NotEqual(t, uuid.Nil, created.ID)
+
+		amended := send(http.MethodPatch, "/api/transactions/"+created.ID.String(), `{"amount":"45"}`, http.Header{"If-Match": {rec.Header().Get("ETag")}})
+		assert.Equal(t, http.StatusOK, amended.Code)
+	})
+
+	t.Run("POST /api/expenses - If-None-Match wildcard", func(t *testing.T) {
+		rec := send(http.MethodPost, "/api/expenses", expense, http.Header{"If-None-Match": {"*"}})
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("POST /api/expenses - If-Match", func(t *testing.T) {
+		rec := send(http.MethodPost, "/api/expenses", expense, http.Header{"If-Match": {`"0"`}})
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestManageTransactions_Void(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	dispatcher := transaction.NewDispatcher(
+		event_store.NewInMemory(transaction.New),
+		event_store.NewInMemory(account.New),
+		event_store.NewInMemory(position.New),
+		event_store.NewInMemoryUnitOfWork(),
+	)
+	manage_transactions.New(mux, dispatcher, nil, nil, nil).Setup()
+
+	id := uuid.New()
+	require.NoError(t, dispatcher.RegisterExpense(ctx, id, uuid.New(), "EUR", decimal.NewFromInt(40), "Groceries", "Weekly shopping", time.Now()))
+
+	void := func(id uuid.UUID) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/api/transactions/"+id.String(), nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("DELETE /api/transactions/{id}", func(t *testing.T) {
+		rec := void(id)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("DELETE /api/transactions/{id} - already voided", func(t *testing.T) {
+		rec := void(id)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("DELETE /api/transactions/{id} - unknown transaction", func(t *testing.T) {
+		rec := void(uuid.New())
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestManageTransactions_SplitExpense(t *testing.T) {
+	// arrange
+	mux := http.NewServeMux()
+	dispatcher := transaction.NewDispatcher(
+		event_store.NewInMemory(transaction.New),
+		event_store.NewInMemory(account.New),
+		event_store.NewInMemory(position.New),
+		event_store.NewInMemoryUnitOfWork(),
+	)
+	manage_transactions.New(mux, dispatcher, nil, nil, nil).Setup()
+
+	split := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/expenses/split", bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("POST /api/expenses/split", func(t *testing.T) {
+		rec := split(`{"account_id":"` + uuid.NewString() + `","currency":"EUR","amount":"50","lines":[{"category":"Groceries","amount":"30"},{"category":"Household","amount":"20"}]}`)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("POST /api/expenses/split - lines not summing to the amount", func(t *testing.T) {
+		rec := split(`{"account_id":"` + uuid.NewString() + `","currency":"EUR","amount":"50","lines":[{"category":"Groceries","amount":"30"}]}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+}
+
+func TestManageTransactions_Investments(t *testing.T) {
+	// arrange
+	mux := http.NewServeMux()
+	dispatcher := transaction.NewDispatcher(
+		event_store.NewInMemory(transaction.New),
+		event_store.NewInMemory(account.New),
+		event_store.NewInMemory(position.New),
+		event_store.NewInMemoryUnitOfWork(),
+	)
+	manage_transactions.New(mux, dispatcher, nil, nil, nil).Setup()
+
+	accountID := uuid.NewString()
+	require.NoError(t, dispatcher.RegisterInvestment(context.Background(), uuid.New(), uuid.MustParse(accountID), "VWCE", decimal.NewFromInt(10), decimal.NewFromInt(100), "EUR", decimal.Zero, "EUR", time.Now()))
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("POST /api/investments/sales", func(t *testing.T) {
+		rec := post("/api/investments/sales", `{"account_id":"`+accountID+`","ticker":"VWCE","units":"2","price":"110","price_currency":"EUR","fee":"1","fee_currency":"EUR"}`)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("POST /api/investments/sales - more units than held", func(t *testing.T) {
+		rec := post("/api/investments/sales", `{"account_id":"`+accountID+`","ticker":"VWCE","units":"100","price":"110","price_currency":"EUR","fee":"1","fee_currency":"EUR"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("POST /api/investments/dividends", func(t *testing.T) {
+		rec := post("/api/investments/dividends", `{"account_id":"`+accountID+`","ticker":"VWCE","units":"10","price":"0.5","price_currency":"EUR","fee":"0","fee_currency":"EUR"}`)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("POST /api/investments/interest", func(t *testing.T) {
+		rec := post("/api/investments/interest", `{"account_id":"`+accountID+`","ticker":"BTP","units":"1000","price":"0.02","price_currency":"EUR","fee":"0","fee_currency":"EUR"}`)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("POST /api/investments/splits", func(t *testing.T) {
+		rec := post("/api/investments/splits", `{"account_id":"`+accountID+`","ticker":"NVDA","ratio":"10"}`)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("POST /api/investments/fees", func(t *testing.T) {
+		rec := post("/api/investments/fees", `{"account_id":"`+accountID+`","ticker":"VWCE","fee":"3","fee_currency":"EUR"}`)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+}
+
+func TestManageTransactions_SaleCostBasis(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	transactionES := event_store.NewInMemory(transaction.New)
+	dispatcher := transaction.NewDispatcher(
+		transactionES,
+		event_store.NewInMemory(account.New),
+		event_store.NewInMemory(position.New),
+		event_store.NewInMemoryUnitOfWork(),
+	)
+	manage_transactions.New(mux, dispatcher, nil, nil, nil).Setup()
+
+	accountID := uuid.New()
+	happenedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, dispatcher.RegisterInvestment(ctx, uuid.New(), accountID, "VWCE", decimal.NewFromInt(10), decimal.NewFromInt(100), "EUR", decimal.Zero, "EUR", happenedAt))
+	require.NoError(t, dispatcher.RegisterInvestment(ctx, uuid.New(), accountID, "VWCE", decimal.NewFromInt(10), decimal.NewFromInt(130), "EUR", decimal.Zero, "EUR", happenedAt.AddDate(0, 0, 1)))
+
+	t.Run("POST /api/investments/sales - cost basis from the position", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/investments/sales", bytes.NewBufferString(`{"account_id":"`+accountID.String()+`","ticker":"VWCE","units":"15","price":"150","price_currency":"EUR","fee":"0","fee_currency":"EUR"}`))
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		records, err := transactionES.ReadFrom(ctx, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		sold, ok := records[2].Content().(events.InvestmentSold)
+		require.True(t, ok)
+		assert.Equal(t, "1650", sold.CostBasis.String())
+	})
+}
+
+type TransactionsRepositoryMock struct {
+	Transactions []transactions.TransactionRecord
+}
+
+func (m *TransactionsRepositoryMock) CreateTransaction(ctx context.Context, tx transactions.TransactionRecord) error {
+	return nil
+}
+
+func (m *TransactionsRepositoryMock) UpdateTransactionAmount(ctx context.Context, transactionID uuid.UUID, amount decimal.Decimal) error {
+	return nil
+}
+
+func (m *TransactionsRepositoryMock) UpdateTransactionHappenedAt(ctx context.Context, transactionID uuid.UUID, happenedAt time.Time) error {
+	return nil
+}
+
+func (m *TransactionsRepositoryMock) UpdateTransactionCategory(ctx context.Context, transactionID uuid.UUID, category string) error {
+	return nil
+}
+
+func (m *TransactionsRepositoryMock) UpdateTransactionDescription(ctx context.Context, transactionID uuid.UUID, description string) error {
+	return nil
+}
+
+func (m *TransactionsRepositoryMock) VoidTransaction(ctx context.Context, transactionID uuid.UUID, voidedAt time.Time) error {
+	return nil
+}
+
+func (m *TransactionsRepositoryMock) ListTransactions(ctx context.Context, params transactions.ListTransactionsParams) ([]transactions.TransactionRecord, error) {
+	return m.Transactions, nil
+}
+
+func (m *TransactionsRepositoryMock) ListTransactionsPaginated(ctx context.Context, params transactions.ListTransactionsPaginatedParams) (transactions.PaginatedTransactions, error) {
+	return transactions.PaginatedTransactions{Transactions: m.Transactions, TotalCount: int64(len(m.Transactions))}, nil
+}
+
+func (m *TransactionsRepositoryMock) ListCategories(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func TestManageTransactions_BaseCurrency(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	eur, usd := values.Currency("EUR"), values.Currency("USD")
+	repo := &TransactionsRepositoryMock{
+		Transactions: []transactions.TransactionRecord{
+			{ID: uuid.New(), Amount: decimal.NewFromInt(-110), Currency: usd, HappenedAt: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)},
+			{ID: uuid.New(), Amount: decimal.NewFromInt(-108), Currency: usd, HappenedAt: time.Date(2024, 2, 15, 10, 0, 0, 0, time.UTC)},
+			{ID: uuid.New(), Amount: decimal.NewFromInt(-40), Currency: eur, HappenedAt: time.Date(2024, 2, 20, 10, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	rates := fx.NewInMemoryRepository()
+	require.NoError(t, rates.Save(ctx,
+		fx.Rate{Base: eur, Quote: usd, Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Rate: decimal.RequireFromString("1.1")},
+		fx.Rate{Base: eur, Quote: usd, Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Rate: decimal.RequireFromString("1.08")},
+	))
+	manage_transactions.New(mux, nil, transactions.NewProjection(repo), nil, fx.NewConverter(rates)).Setup()
+
+	t.Run("should convert the transactions at the rate of the day they happened", func(t *testing.T) {
+		// act
+		req := httptest.NewRequest(http.MethodGet, "/api/transactions?base_currency=EUR", nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		// assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var result []transactions.TransactionRecord
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		require.Len(t, result, 3)
+		assert.Equal(t, "-100", result[0].Amount.String())
+		assert.Equal(t, "-100", result[1].Amount.String())
+		assert.Equal(t, "-40", result[2].Amount.String())
+		assert.Equal(t, eur, result[0].Currency)
+	})
+
+	t.Run("should convert the pages of transactions", func(t *testing.T) {
+		// act
+		req := httptest.NewRequest(http.MethodGet, "/api/transactions?paginated=true&base_currency=EUR", nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		// assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var result transactions.PaginatedTransactions
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		assert.Equal(t, "-100", result.Transactions[0].Amount.String())
+		assert.Equal(t, int64(3), result.TotalCount)
+	})
+
+	t.Run("should leave the amounts as they are without a base currency", func(t *testing.T) {
+		// act
+		req := httptest.NewRequest(http.MethodGet, "/api/transactions", nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		// assert
+		var result []transactions.TransactionRecord
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		assert.Equal(t, "-110", result[0].Amount.String())
+		assert.Equal(t, usd, result[0].Currency)
+	})
+}
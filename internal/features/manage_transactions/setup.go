@@ -7,43 +7,76 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"github.com/somatom98/brokeli/internal/domain/projections/expenses"
 	"github.com/somatom98/brokeli/internal/domain/projections/transactions"
+	"github.com/somatom98/brokeli/internal/domain/transaction"
 	"github.com/somatom98/brokeli/internal/domain/values"
 )
 
 type Dispatcher interface {
 	RegisterExpense(ctx context.Context, id uuid.UUID, accountID uuid.UUID, currency values.Currency, amount decimal.Decimal, category, description string, happenedAt time.Time) error
+	RegisterSplitExpense(ctx context.Context, id uuid.UUID, accountID uuid.UUID, currency values.Currency, amount decimal.Decimal, lines []values.SplitLine, description string, happenedAt time.Time) error
 	RegisterIncome(ctx context.Context, id uuid.UUID, accountID uuid.UUID, currency values.Currency, amount decimal.Decimal, category, description string, happenedAt time.Time) error
 	RegisterTransfer(ctx context.Context, id uuid.UUID, fromAccountID uuid.UUID, fromCurrency values.Currency, fromAmount decimal.Decimal, toAccountID uuid.UUID, toCurrency values.Currency, toAmount decimal.Decimal, category, description string, happenedAt time.Time) error
-	RegisterReimbursement(ctx context.Context, id uuid.UUID, accountID uuid.UUID, from string, currency values.Currency, amount decimal.Decimal, category string, description string, happenedAt time.Time) error
+	RegisterReimbursement(ctx context.Context, id uuid.UUID, expenseID uuid.UUID, accountID uuid.UUID, from string, currency values.Currency, amount decimal.Decimal, category string, description string, happenedAt time.Time) error
 	RegisterInvestment(ctx context.Context, id uuid.UUID, accountID uuid.UUID, ticker string, units decimal.Decimal, price decimal.Decimal, priceCurrency values.Currency, fee decimal.Decimal, feeCurrency values.Currency, happenedAt time.Time) error
-	SetExpectedReimbursement(ctx context.Context, id uuid.UUID, accountID uuid.UUID, currency values.Currency, amount decimal.Decimal, happenedAt time.Time) error
+	RegisterSale(ctx context.Context, id uuid.UUID, accountID uuid.UUID, ticker string, units decimal.Decimal, price decimal.Decimal, priceCurrency values.Currency, fee decimal.Decimal, feeCurrency values.Currency, happenedAt time.Time) error
+	RegisterDividend(ctx context.Context, id uuid.UUID, accountID uuid.UUID, ticker string, units decimal.Decimal, price decimal.Decimal, priceCurrency values.Currency, fee decimal.Decimal, feeCurrency values.Currency, happenedAt time.Time) error
+	RegisterInterest(ctx context.Context, id uuid.UUID, accountID uuid.UUID, ticker string, units decimal.Decimal, price decimal.Decimal, priceCurrency values.Currency, fee decimal.Decimal, feeCurrency values.Currency, happenedAt time.Time) error
+	RegisterStockSplit(ctx context.Context, id uuid.UUID, accountID uuid.UUID, ticker string, ratio decimal.Decimal, happenedAt time.Time) error
+	RegisterInvestmentFee(ctx context.Context, id uuid.UUID, accountID uuid.UUID, ticker string, fee decimal.Decimal, feeCurrency values.Currency, happenedAt time.Time) error
+	SetExpectedReimbursement(ctx context.Context, id uuid.UUID, accountID uuid.UUID, from string, currency values.Currency, amount decimal.Decimal, happenedAt time.Time) error
+	Amend(ctx context.Context, id uuid.UUID, amendment transaction.Amendment) error
+	Void(ctx context.Context, id uuid.UUID) error
+	History(ctx context.Context, id uuid.UUID) ([]transaction.Revision, error)
+}
+
+// Converter converts amounts between currencies at the rate of a date.
+type Converter interface {
+	Convert(ctx context.Context, amount decimal.Decimal, from, to values.Currency, on time.Time) (decimal.Decimal, error)
 }
 
 type Feature struct {
 	httpHandler      *http.ServeMux
 	dispatcher       Dispatcher
 	transactionsView *transactions.Projection
+	expensesView     *expenses.Projection
+	converter        Converter
 }
 
 func New(
 	httpHandler *http.ServeMux,
 	api Dispatcher,
 	transactionsView *transactions.Projection,
+	expensesView *expenses.Projection,
+	converter Converter,
 ) *Feature {
 	return &Feature{
 		httpHandler:      httpHandler,
 		dispatcher:       api,
 		transactionsView: transactionsView,
+		expensesView:     expensesView,
+		converter:        converter,
 	}
 }
 
 func (f *Feature) Setup() {
 	f.httpHandler.HandleFunc("GET /api/transactions", f.handleGetTransactions)
+	f.httpHandler.HandleFunc("GET /api/transactions/{id}/history", f.handleGetTransactionHistory)
+	f.httpHandler.HandleFunc("PATCH /api/transactions/{id}", f.handleAmendTransaction)
+	f.httpHandler.HandleFunc("DELETE /api/transactions/{id}", f.handleVoidTransaction)
+	f.httpHandler.HandleFunc("GET /api/expenses/{id}", f.handleGetExpense)
+	f.httpHandler.HandleFunc("GET /api/receivables", f.handleGetReceivables)
 	f.httpHandler.HandleFunc("POST /api/expenses", f.handleRegisterExpense)
+	f.httpHandler.HandleFunc("POST /api/expenses/split", f.handleRegisterSplitExpense)
 	f.httpHandler.HandleFunc("POST /api/incomes", f.handleRegisterIncome)
 	f.httpHandler.HandleFunc("POST /api/transfers", f.handleRegisterTransfer)
 	f.httpHandler.HandleFunc("POST /api/investments", f.handleRegisterInvestment)
+	f.httpHandler.HandleFunc("POST /api/investments/sales", f.handleRegisterSale)
+	f.httpHandler.HandleFunc("POST /api/investments/dividends", f.handleRegisterDividend)
+	f.httpHandler.HandleFunc("POST /api/investments/interest", f.handleRegisterInterest)
+	f.httpHandler.HandleFunc("POST /api/investments/splits", f.handleRegisterStockSplit)
+	f.httpHandler.HandleFunc("POST /api/investments/fees", f.handleRegisterInvestmentFee)
 	f.httpHandler.HandleFunc("POST /api/{transaction_id}/reimbursement", f.handleRegisterReimbursement)
 	f.httpHandler.HandleFunc("POST /api/{transaction_id}/expected-reimbursements", f.handleSetExpectedReimbursement)
 }
@@ -0,0 +1,69 @@
+package rebuild_projections
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+func (f *Feature) handleGetProjections(w http.ResponseWriter, r *http.Request) {
+	projections := make([]Progress, 0)
+	for _, name := range f.runner.Projections() {
+		progress, ok := f.runner.Progress(name)
+		if !ok {
+			progress = Progress{Projection: name}
+		}
+		projections = append(projections, progress)
+	}
+
+	writeJSON(w, http.StatusOK, projections)
+}
+
+func (f *Feature) handleStartRebuild(w http.ResponseWriter, r *http.Request) {
+	mode := Mode(r.URL.Query().Get("mode"))
+	if mode == "" {
+		mode = ModeShadow
+	}
+
+	// The rebuild outlives the request.
+	progress, err := f.runner.Start(context.WithoutCancel(r.Context()), r.PathValue("name"), mode)
+	switch {
+	case errors.Is(err, ErrUnknownProjection):
+		http.Error(w, "projection not found", http.StatusNotFound)
+		return
+	case errors.Is(err, ErrUnknownMode):
+		http.Error(w, "invalid mode", http.StatusBadRequest)
+		return
+	case errors.Is(err, ErrRebuildInProgress):
+		http.Error(w, "rebuild already in progress", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, progress)
+}
+
+func (f *Feature) handleGetRebuild(w http.ResponseWriter, r *http.Request) {
+	progress, ok := f.runner.Progress(r.PathValue("name"))
+	if !ok {
+		http.Error(w, "rebuild not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, progress)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
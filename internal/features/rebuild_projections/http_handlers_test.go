@@ -0,0 +1,147 @@
+package rebuild_projections_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/features/rebuild_projections"
+)
+
+type RunnerMock struct {
+	Started []rebuild_projections.Mode
+	Running map[string]rebuild_projections.Progress
+	Names   []string
+}
+
+func (m *RunnerMock) Projections() []string {
+	return m.Names
+}
+
+func (m *RunnerMock) Start(ctx context.Context, name string, mode rebuild_projections.Mode) (rebuild_projections.Progress, error) {
+	if name != "transactions" {
+		return rebuild_projections.Progress{}, rebuild_projections.ErrUnknownProjection
+	}
+	if mode != rebuild_projections.ModeShadow && mode != rebuild_projections.ModeInPlace {
+		return rebuild_projections.Progress{}, rebuild_projections.ErrUnknownMode
+	}
+	if progress, ok := m.Running[name]; ok {
+		return progress, rebuild_projections.ErrRebuildInProgress
+	}
+
+	m.Started = append(m.Started, mode)
+	progress := rebuild_projections.Progress{
+		Projection: name,
+		Mode:       mode,
+		Status:     rebuild_projections.StatusRunning,
+	}
+	m.Running[name] = progress
+	return progress, nil
+}
+
+func (m *RunnerMock) Progress(name string) (rebuild_projections.Progress, bool) {
+	progress, ok := m.Running[name]
+	return progress, ok
+}
+
+func TestRebuildProjections_Handlers(t *testing.T) {
+	// arrange
+	mux := http.NewServeMux()
+	runner := &RunnerMock{
+		Running: make(map[string]rebuild_projections.Progress),
+		Names:   []string{"accounts_projection", "transactions"},
+	}
+	rebuild_projections.New(mux, runner).Setup()
+
+	t.Run("POST /api/admin/projections/{name}/rebuild - defaults to shadow mode", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/projections/transactions/rebuild", nil)
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, []rebuild_projections.Mode{rebuild_projections.ModeShadow}, runner.Started)
+
+		var result rebuild_projections.Progress
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		assert.Equal(t, "transactions", result.Projection)
+		assert.Equal(t, rebuild_projections.StatusRunning, result.Status)
+	})
+
+	t.Run("POST /api/admin/projections/{name}/rebuild - already running", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/projections/transactions/rebuild?mode=in_place", nil)
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("POST /api/admin/projections/{name}/rebuild - unknown projection", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/projections/unknown/rebuild", nil)
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("POST /api/admin/projections/{name}/rebuild - invalid mode", func(t *testing.T) {
+		delete(runner.Running, "transactions")
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/projections/transactions/rebuild?mode=everything", nil)
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("GET /api/admin/projections/{name}/rebuild", func(t *testing.T) {
+		runner.Running["transactions"] = rebuild_projections.Progress{
+			Projection: "transactions",
+			Status:     rebuild_projections.StatusRunning,
+			Processed:  10,
+			Position:   12,
+			Head:       40,
+		}
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/projections/transactions/rebuild", nil)
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var result rebuild_projections.Progress
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		assert.Equal(t, uint64(10), result.Processed)
+		assert.Equal(t, uint64(40), result.Head)
+	})
+
+	t.Run("GET /api/admin/projections/{name}/rebuild - never rebuilt", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/projections/accounts_projection/rebuild", nil)
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("GET /api/admin/projections", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/projections", nil)
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var result []rebuild_projections.Progress
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		require.Len(t, result, 2)
+		assert.Equal(t, "accounts_projection", result[0].Projection)
+		assert.Empty(t, result[0].Status)
+		assert.Equal(t, rebuild_projections.StatusRunning, result[1].Status)
+	})
+}
@@ -0,0 +1,537 @@
+package rebuild_projections
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/somatom98/brokeli/pkg/event_store"
+	"github.com/somatom98/brokeli/pkg/event_store/postgres"
+)
+
+const batchSize = 500
+
+var (
+	ErrUnknownProjection = errors.New("unknown_projection")
+	ErrUnknownMode       = errors.New("unknown_mode")
+	ErrRebuildInProgress = errors.New("rebuild_in_progress")
+)
+
+type Mode string
+
+const (
+	// ModeShadow builds the projection into a copy of its tables and swaps
+	// them in at the end, so the API keeps reading the old data meanwhile.
+	ModeShadow Mode = "shadow"
+	// ModeInPlace truncates the projection tables and rebuilds them directly.
+	ModeInPlace Mode = "in_place"
+)
+
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// EventStore is the part of a Postgres event store a rebuild needs: reading
+// the history and taking over the live subscription of the projection.
+type EventStore interface {
+	ReadFrom(ctx context.Context, position uint64, limit int) ([]event_store.Record, error)
+	LastPosition(ctx context.Context) (uint64, error)
+	LockSubscription(ctx context.Context, tx *sql.Tx, name string) error
+	SaveCheckpoint(ctx context.Context, tx *sql.Tx, name string, position uint64) error
+}
+
+// Projection describes a read model that can be rebuilt.
+type Projection struct {
+	Name         string
+	Subscription string
+	Tables       []string
+	// New returns the handler applying events to the projection tables
+	// reachable through db.
+	New func(db *sql.DB) (event_store.SubscribeHandler, error)
+}
+
+type Progress struct {
+	Projection string     `json:"projection"`
+	Mode       Mode       `json:"mode"`
+	Status     Status     `json:"status"`
+	Processed  uint64     `json:"processed"`
+	Position   uint64     `json:"position"`
+	Head       uint64     `json:"head"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+type Rebuilder struct {
+	db          *sql.DB
+	dsn         string
+	stores      []EventStore
+	projections map[string]Projection
+	mu          sync.Mutex
+	progress    map[string]*Progress
+}
+
+func NewRebuilder(db *sql.DB, dsn string, projections []Projection, stores ...EventStore) *Rebuilder {
+	r := &Rebuilder{
+		db:          db,
+		dsn:         dsn,
+		stores:      stores,
+		projections: make(map[string]Projection),
+		progress:    make(map[string]*Progress),
+	}
+	for _, p := range projections {
+		r.projections[p.Name] = p
+	}
+	return r
+}
+
+// Projections returns the names of the projections that can be rebuilt.
+func (r *Rebuilder) Projections() []string {
+	names := make([]string, 0, len(r.projections))
+	for name := range r.projections {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Rebuild replays every event into the projection and returns once it is done.
+func (r *Rebuilder) Rebuild(ctx context.Context, name string, mode Mode) (Progress, error) {
+	progress, err := r.begin(name, mode)
+	if err != nil {
+		return progress, err
+	}
+
+	err = r.run(ctx, name, mode)
+	return r.finish(name, err), err
+}
+
+// Start rebuilds the projection in the background; its advancement is
+// available through Progress.
+func (r *Rebuilder) Start(ctx context.Context, name string, mode Mode) (Progress, error) {
+	progress, err := r.begin(name, mode)
+	if err != nil {
+		return progress, err
+	}
+
+	go func() {
+		err := r.run(ctx, name, mode)
+		r.finish(name, err)
+	}()
+
+	return progress, nil
+}
+
+// Progress returns the state of the current or latest rebuild of the projection.
+func (r *Rebuilder) Progress(name string) (Progress, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	progress, ok := r.progress[name]
+	if !ok {
+		return Progress{}, false
+	}
+	return *progress, true
+}
+
+func (r *Rebuilder) begin(name string, mode Mode) (Progress, error) {
+	if _, ok := r.projections[name]; !ok {
+		return Progress{}, ErrUnknownProjection
+	}
+	if mode != ModeShadow && mode != ModeInPlace {
+		return Progress{}, ErrUnknownMode
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if progress, ok := r.progress[name]; ok && progress.Status == StatusRunning {
+		return *progress, ErrRebuildInProgress
+	}
+
+	progress := &Progress{
+		Projection: name,
+		Mode:       mode,
+		Status:     StatusRunning,
+		StartedAt:  time.Now(),
+	}
+	r.progress[name] = progress
+	return *progress, nil
+}
+
+func (r *Rebuilder) finish(name string, err error) Progress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	progress := r.progress[name]
+	finishedAt := time.Now()
+	progress.FinishedAt = &finishedAt
+	progress.Status = StatusCompleted
+	if err != nil {
+		log.Printf("rebuild of projection %s failed: %v", name, err)
+		progress.Status = StatusFailed
+		progress.Error = err.Error()
+	}
+	return *progress
+}
+
+func (r *Rebuilder) update(name string, fn func(p *Progress)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fn(r.progress[name])
+}
+
+func (r *Rebuilder) run(ctx context.Context, name string, mode Mode) error {
+	projection := r.projections[name]
+	if mode == ModeInPlace {
+		return r.rebuildInPlace(ctx, projection)
+	}
+	return r.rebuildShadow(ctx, projection)
+}
+
+func (r *Rebuilder) rebuildInPlace(ctx context.Context, projection Projection) error {
+	handler, err := projection.New(r.db)
+	if err != nil {
+		return fmt.Errorf("failed to create projection: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.lockSubscriptions(ctx, tx, projection); err != nil {
+		return err
+	}
+
+	// The tables are emptied together with the checkpoints moving back to the
+	// start, and each batch is then applied together with the checkpoints
+	// moving past it, so that the live subscription picks up where the rebuild
+	// stopped if it fails.
+	if err := r.truncate(ctx, projection); err != nil {
+		return err
+	}
+
+	position, err := r.replay(ctx, projection.Name, handler, 0, func(ctx context.Context, position uint64, apply func(ctx context.Context) error) error {
+		return r.checkpointed(ctx, projection, position, apply)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := r.saveCheckpoints(ctx, tx, projection, position); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// rebuildShadow replays the history into a copy of the projection tables
+// living in a dedicated schema, then pauses the live subscription, catches up
+// with the events appended meanwhile and moves the copy in place of the live
+// tables.
+func (r *Rebuilder) rebuildShadow(ctx context.Context, projection Projection) error {
+	var liveSchema string
+	if err := r.db.QueryRowContext(ctx, "SELECT current_schema()").Scan(&liveSchema); err != nil {
+		return fmt.Errorf("failed to get current schema: %w", err)
+	}
+
+	shadowSchema := "rebuild_" + projection.Name
+	retiredSchema := "retired_" + projection.Name
+
+	if err := r.createShadowTables(ctx, projection, liveSchema, shadowSchema); err != nil {
+		return err
+	}
+	defer r.dropSchema(shadowSchema)
+
+	shadowDB, err := sql.Open("postgres", withSearchPath(r.dsn, shadowSchema, liveSchema))
+	if err != nil {
+		return fmt.Errorf("failed to open shadow connection: %w", err)
+	}
+	defer shadowDB.Close()
+
+	handler, err := projection.New(shadowDB)
+	if err != nil {
+		return fmt.Errorf("failed to create projection: %w", err)
+	}
+
+	position, err := r.replay(ctx, projection.Name, handler, 0, nil)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.lockSubscriptions(ctx, tx, projection); err != nil {
+		return err
+	}
+
+	position, err = r.replay(ctx, projection.Name, handler, position, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "CREATE SCHEMA "+pq.QuoteIdentifier(retiredSchema)); err != nil {
+		return fmt.Errorf("failed to create schema %s: %w", retiredSchema, err)
+	}
+
+	for _, table := range projection.Tables {
+		swap := fmt.Sprintf(
+			"ALTER TABLE %s.%s SET SCHEMA %s; ALTER TABLE %s.%s SET SCHEMA %s",
+			pq.QuoteIdentifier(liveSchema), pq.QuoteIdentifier(table), pq.QuoteIdentifier(retiredSchema),
+			pq.QuoteIdentifier(shadowSchema), pq.QuoteIdentifier(table), pq.QuoteIdentifier(liveSchema),
+		)
+		if _, err := tx.ExecContext(ctx, swap); err != nil {
+			return fmt.Errorf("failed to swap %s: %w", table, err)
+		}
+	}
+
+	if err := r.saveCheckpoints(ctx, tx, projection, position); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.dropSchema(retiredSchema)
+
+	return nil
+}
+
+// truncate empties the projection tables and moves the checkpoints of its
+// subscription back to the start in a single transaction.
+func (r *Rebuilder) truncate(ctx context.Context, projection Projection) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range projection.Tables {
+		if _, err := tx.ExecContext(ctx, "TRUNCATE "+pq.QuoteIdentifier(table)); err != nil {
+			return fmt.Errorf("failed to truncate %s: %w", table, err)
+		}
+	}
+
+	if err := r.saveCheckpoints(ctx, tx, projection, 0); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// checkpointed runs apply in a transaction, carried by the context apply
+// receives, which also moves the checkpoints of the projection subscription
+// to the given position.
+func (r *Rebuilder) checkpointed(ctx context.Context, projection Projection, position uint64, apply func(ctx context.Context) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := apply(postgres.WithTx(ctx, tx)); err != nil {
+		return err
+	}
+
+	if err := r.saveCheckpoints(ctx, tx, projection, position); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Rebuilder) createShadowTables(ctx context.Context, projection Projection, liveSchema, shadowSchema string) error {
+	r.dropSchema(shadowSchema)
+
+	if _, err := r.db.ExecContext(ctx, "CREATE SCHEMA "+pq.QuoteIdentifier(shadowSchema)); err != nil {
+		return fmt.Errorf("failed to create schema %s: %w", shadowSchema, err)
+	}
+
+	for _, table := range projection.Tables {
+		create := fmt.Sprintf(
+			"CREATE TABLE %s.%s (LIKE %s.%s INCLUDING ALL)",
+			pq.QuoteIdentifier(shadowSchema), pq.QuoteIdentifier(table),
+			pq.QuoteIdentifier(liveSchema), pq.QuoteIdentifier(table),
+		)
+		if _, err := r.db.ExecContext(ctx, create); err != nil {
+			return fmt.Errorf("failed to create shadow table %s: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Rebuilder) dropSchema(schema string) {
+	_, err := r.db.Exec("DROP SCHEMA IF EXISTS " + pq.QuoteIdentifier(schema) + " CASCADE")
+	if err != nil {
+		log.Printf("failed to drop schema %s: %v", schema, err)
+	}
+}
+
+func (r *Rebuilder) lockSubscriptions(ctx context.Context, tx *sql.Tx, projection Projection) error {
+	for _, store := range r.stores {
+		if err := store.LockSubscription(ctx, tx, projection.Subscription); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Rebuilder) saveCheckpoints(ctx context.Context, tx *sql.Tx, projection Projection, position uint64) error {
+	for _, store := range r.stores {
+		if err := store.SaveCheckpoint(ctx, tx, projection.Subscription, position); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replay applies, in global order, the events of every store with a position
+// greater than the given one, and returns the position of the last one. When
+// set, batch runs the application of each batch of events, passing it the
+// position the batch ends at.
+func (r *Rebuilder) replay(ctx context.Context, name string, handler event_store.SubscribeHandler, position uint64, batch func(ctx context.Context, position uint64, apply func(ctx context.Context) error) error) (uint64, error) {
+	head, err := r.head(ctx)
+	if err != nil {
+		return position, err
+	}
+	r.update(name, func(p *Progress) { p.Head = head })
+
+	for {
+		records, err := r.read(ctx, position)
+		if err != nil {
+			return position, err
+		}
+
+		if len(records) == 0 {
+			return position, nil
+		}
+
+		apply := func(ctx context.Context) error {
+			for _, record := range records {
+				if err := handler(ctx, record); err != nil {
+					return fmt.Errorf("failed to apply %s at position %d: %w", record.Type(), record.Position, err)
+				}
+			}
+			return nil
+		}
+
+		last := records[len(records)-1].Position
+		if batch != nil {
+			err = batch(ctx, last, apply)
+		} else {
+			err = apply(ctx)
+		}
+		if err != nil {
+			return position, err
+		}
+		position = last
+
+		r.update(name, func(p *Progress) {
+			p.Processed += uint64(len(records))
+			p.Position = position
+			p.Head = max(p.Head, position)
+		})
+	}
+}
+
+// read merges the next batch of every store by position. When a store returns
+// a full batch, the records of the other stores past its last position are
+// left for the next read, as that store may have more before them. So are the
+// records past the head read beforehand, as a store read earlier may not have
+// seen the ones committed meanwhile before them.
+func (r *Rebuilder) read(ctx context.Context, position uint64) ([]event_store.Record, error) {
+	head, err := r.head(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]event_store.Record, 0)
+	limit := head
+
+	for _, store := range r.stores {
+		batch, err := store.ReadFrom(ctx, position, batchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(batch) == batchSize {
+			limit = min(limit, batch[len(batch)-1].Position)
+		}
+
+		records = append(records, batch...)
+	}
+
+	slices.SortFunc(records, func(a, b event_store.Record) int {
+		return cmp.Compare(a.Position, b.Position)
+	})
+
+	records = slices.DeleteFunc(records, func(record event_store.Record) bool {
+		return record.Position > limit
+	})
+
+	return records, nil
+}
+
+func (r *Rebuilder) head(ctx context.Context) (uint64, error) {
+	head := uint64(0)
+	for _, store := range r.stores {
+		position, err := store.LastPosition(ctx)
+		if err != nil {
+			return 0, err
+		}
+		head = max(head, position)
+	}
+	return head, nil
+}
+
+// withSearchPath sets the search_path runtime parameter of a lib/pq DSN, in
+// either URL or key/value form.
+func withSearchPath(dsn string, schemas ...string) string {
+	searchPath := strings.Join(schemas, ",")
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err == nil {
+			query := u.Query()
+			query.Set("search_path", searchPath)
+			u.RawQuery = query.Encode()
+			return u.String()
+		}
+	}
+
+	return strings.TrimSpace(dsn + " search_path=" + searchPath)
+}
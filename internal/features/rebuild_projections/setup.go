@@ -0,0 +1,33 @@
+package rebuild_projections
+
+import (
+	"context"
+	"net/http"
+)
+
+type Runner interface {
+	Projections() []string
+	Start(ctx context.Context, name string, mode Mode) (Progress, error)
+	Progress(name string) (Progress, bool)
+}
+
+type Feature struct {
+	httpHandler *http.ServeMux
+	runner      Runner
+}
+
+func New(
+	httpHandler *http.ServeMux,
+	runner Runner,
+) *Feature {
+	return &Feature{
+		httpHandler: httpHandler,
+		runner:      runner,
+	}
+}
+
+func (f *Feature) Setup() {
+	f.httpHandler.HandleFunc("GET /api/admin/projections", f.handleGetProjections)
+	f.httpHandler.HandleFunc("POST /api/admin/projections/{name}/rebuild", f.handleStartRebuild)
+	f.httpHandler.HandleFunc("GET /api/admin/projections/{name}/rebuild", f.handleGetRebuild)
+}
@@ -0,0 +1,75 @@
+package trace_events
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Event struct {
+	ID            uuid.UUID `json:"id"`
+	AggregateType string    `json:"aggregate_type"`
+	AggregateID   uuid.UUID `json:"aggregate_id"`
+	Version       uint64    `json:"version"`
+	Position      uint64    `json:"position"`
+	Type          string    `json:"type"`
+	Content       any       `json:"content"`
+	CorrelationID uuid.UUID `json:"correlation_id"`
+	CausationID   uuid.UUID `json:"causation_id"`
+	Actor         string    `json:"actor"`
+	Source        string    `json:"source"`
+	RecordedAt    time.Time `json:"recorded_at"`
+}
+
+// handleGetCorrelation returns every event appended within a correlation, so
+// that a balance change can be traced back to the request that caused it.
+func (f *Feature) handleGetCorrelation(w http.ResponseWriter, r *http.Request) {
+	correlationID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	events := make([]Event, 0)
+	for aggregateType, store := range f.stores {
+		records, err := store.ReadCorrelation(r.Context(), correlationID)
+		if err != nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		for _, record := range records {
+			events = append(events, Event{
+				ID:            record.ID,
+				AggregateType: aggregateType,
+				AggregateID:   record.AggregateID,
+				Version:       record.Version,
+				Position:      record.Position,
+				Type:          record.Type(),
+				Content:       record.Content(),
+				CorrelationID: record.Metadata.CorrelationID,
+				CausationID:   record.Metadata.CausationID,
+				Actor:         record.Metadata.Actor,
+				Source:        record.Metadata.Source,
+				RecordedAt:    record.RecordedAt,
+			})
+		}
+	}
+
+	slices.SortFunc(events, func(a, b Event) int {
+		return cmp.Compare(a.Position, b.Position)
+	})
+
+	body, err := json.Marshal(events)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
@@ -0,0 +1,105 @@
+package trace_events_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/internal/features/trace_events"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+type EventStoreMock struct {
+	Records []event_store.Record
+}
+
+func (m *EventStoreMock) ReadCorrelation(ctx context.Context, correlationID uuid.UUID) ([]event_store.Record, error) {
+	records := make([]event_store.Record, 0)
+	for _, record := range m.Records {
+		if record.Metadata.CorrelationID == correlationID {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+type mockEvent struct {
+	typ string
+}
+
+func (e mockEvent) Type() string { return e.typ }
+func (e mockEvent) Content() any { return map[string]string{"type": e.typ} }
+
+func TestTraceEvents_Handlers(t *testing.T) {
+	// arrange
+	correlationID := uuid.New()
+	transfer := event_store.Record{
+		ID:          uuid.New(),
+		AggregateID: uuid.New(),
+		Version:     1,
+		Position:    10,
+		Metadata:    event_store.Metadata{CorrelationID: correlationID, Actor: "marco", Source: event_store.SourceAPI},
+		Event:       mockEvent{typ: "MoneyTransfered"},
+	}
+	withdrawal := event_store.Record{
+		ID:          uuid.New(),
+		AggregateID: uuid.New(),
+		Version:     4,
+		Position:    11,
+		Metadata:    event_store.Metadata{CorrelationID: correlationID, CausationID: transfer.ID, Actor: "marco", Source: event_store.SourceSystem},
+		Event:       mockEvent{typ: "MoneyWithdrawn"},
+	}
+	unrelated := event_store.Record{
+		ID:       uuid.New(),
+		Position: 12,
+		Metadata: event_store.Metadata{CorrelationID: uuid.New()},
+		Event:    mockEvent{typ: "MoneyDeposited"},
+	}
+
+	mux := http.NewServeMux()
+	trace_events.New(mux, map[string]trace_events.EventStore{
+		"Account":     &EventStoreMock{Records: []event_store.Record{withdrawal, unrelated}},
+		"Transaction": &EventStoreMock{Records: []event_store.Record{transfer}},
+	}).Setup()
+
+	t.Run("should return the events of the correlation across stores in position order", func(t *testing.T) {
+		// act
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/correlations/"+correlationID.String()+"/events", nil)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+
+		// assert
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var events []trace_events.Event
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &events))
+		require.Len(t, events, 2)
+
+		assert.Equal(t, transfer.ID, events[0].ID)
+		assert.Equal(t, "Transaction", events[0].AggregateType)
+		assert.Equal(t, "MoneyTransfered", events[0].Type)
+		assert.Equal(t, event_store.SourceAPI, events[0].Source)
+
+		assert.Equal(t, withdrawal.ID, events[1].ID)
+		assert.Equal(t, "Account", events[1].AggregateType)
+		assert.Equal(t, transfer.ID, events[1].CausationID)
+		assert.Equal(t, "marco", events[1].Actor)
+		assert.Equal(t, event_store.SourceSystem, events[1].Source)
+	})
+
+	t.Run("should reject an invalid correlation id", func(t *testing.T) {
+		// act
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/correlations/nope/events", nil)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+
+		// assert
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,34 @@
+package trace_events
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+type EventStore interface {
+	ReadCorrelation(ctx context.Context, correlationID uuid.UUID) ([]event_store.Record, error)
+}
+
+type Feature struct {
+	httpHandler *http.ServeMux
+	stores      map[string]EventStore
+}
+
+// New returns the feature tracing the events of the given stores, keyed by
+// aggregate type.
+func New(
+	httpHandler *http.ServeMux,
+	stores map[string]EventStore,
+) *Feature {
+	return &Feature{
+		httpHandler: httpHandler,
+		stores:      stores,
+	}
+}
+
+func (f *Feature) Setup() {
+	f.httpHandler.HandleFunc("GET /api/admin/correlations/{id}/events", f.handleGetCorrelation)
+}
@@ -0,0 +1,172 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+)
+
+var ErrNotFound = errors.New("not_found")
+
+// Response is the response of the first request made with a key.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Entry is what is stored under a key: the fingerprint of the first request
+// made with it and, once it was served, its response.
+type Entry struct {
+	Fingerprint string
+	Response    *Response
+}
+
+type Repository interface {
+	// Reserve stores the key for the request with the given fingerprint, and
+	// reports false when it is already stored.
+	Reserve(ctx context.Context, key, fingerprint string) (bool, error)
+	Get(ctx context.Context, key string) (Entry, error)
+	Complete(ctx context.Context, key string, response Response) error
+	Release(ctx context.Context, key string) error
+}
+
+// Handler serves the commands carrying an Idempotency-Key header at most
+// once: the response of the first request made with a key is stored and
+// replayed for the requests repeating it. Reusing a key for another request
+// is rejected with 422, and repeating a request still being served with 409.
+// Responses with a server error, or requests whose handler panics, are not
+// stored, so that they can be retried.
+func Handler(repository Repository, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(HeaderKey)
+		if key == "" || !isCommand(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		fingerprint := fingerprint(r, body)
+
+		reserved, err := repository.Reserve(ctx, key, fingerprint)
+		if err != nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		if !reserved {
+			replay(ctx, w, repository, key, fingerprint)
+			return
+		}
+
+		// The outcome is stored even if the client went away meanwhile.
+		ctx = context.WithoutCancel(ctx)
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		defer func() {
+			if v := recover(); v != nil {
+				_ = repository.Release(ctx, key)
+				panic(v)
+			}
+		}()
+		next.ServeHTTP(recorder, r)
+
+		if recorder.statusCode >= http.StatusInternalServerError {
+			_ = repository.Release(ctx, key)
+			return
+		}
+
+		_ = repository.Complete(ctx, key, Response{
+			StatusCode: recorder.statusCode,
+			Header:     recorder.header,
+			Body:       recorder.body.Bytes(),
+		})
+	})
+}
+
+func replay(ctx context.Context, w http.ResponseWriter, repository Repository, key, fingerprint string) {
+	entry, err := repository.Get(ctx, key)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		// The first request failed and released the key in the meantime.
+		http.Error(w, "conflict: request with the same idempotency key in progress", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	case entry.Fingerprint != fingerprint:
+		http.Error(w, "unprocessable entity: idempotency key reused with another request", http.StatusUnprocessableEntity)
+		return
+	case entry.Response == nil:
+		http.Error(w, "conflict: request with the same idempotency key in progress", http.StatusConflict)
+		return
+	}
+
+	for name, values := range entry.Response.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(HeaderReplayed, "true")
+	w.WriteHeader(entry.Response.StatusCode)
+	w.Write(entry.Response.Body)
+}
+
+func isCommand(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+// fingerprint identifies a request by its method, URL and body.
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder writes the response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	header      http.Header
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+	r.statusCode = statusCode
+	r.header = r.ResponseWriter.Header().Clone()
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
@@ -0,0 +1,175 @@
+package idempotency_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/somatom98/brokeli/internal/idempotency"
+	"github.com/stretchr/testify/assert"
+)
+
+type RepositoryMock struct {
+	mu      sync.Mutex
+	Entries map[string]idempotency.Entry
+}
+
+func NewRepositoryMock() *RepositoryMock {
+	return &RepositoryMock{Entries: make(map[string]idempotency.Entry)}
+}
+
+func (m *RepositoryMock) Reserve(ctx context.Context, key, fingerprint string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.Entries[key]; ok {
+		return false, nil
+	}
+	m.Entries[key] = idempotency.Entry{Fingerprint: fingerprint}
+	return true, nil
+}
+
+func (m *RepositoryMock) Get(ctx context.Context, key string) (idempotency.Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.Entries[key]
+	if !ok {
+		return idempotency.Entry{}, idempotency.ErrNotFound
+	}
+	return entry, nil
+}
+
+func (m *RepositoryMock) Complete(ctx context.Context, key string, response idempotency.Response) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry := m.Entries[key]
+	entry.Response = &response
+	m.Entries[key] = entry
+	return nil
+}
+
+func (m *RepositoryMock) Release(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.Entries, key)
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	newHandler := func(statusCode int) (http.Handler, *int) {
+		calls := 0
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("ETag", `"1"`)
+			w.WriteHeader(statusCode)
+			w.Write([]byte(`{"id":"first"}`))
+		})
+		return idempotency.Handler(NewRepositoryMock(), next), &calls
+	}
+
+	post := func(handler http.Handler, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/expenses", bytes.NewBufferString(body))
+		if key != "" {
+			req.Header.Set(idempotency.HeaderKey, key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("should replay the response of a repeated request", func(t *testing.T) {
+		// arrange
+		handler, calls := newHandler(http.StatusCreated)
+		first := post(handler, "key", `{"amount":"10"}`)
+
+		// act
+		rec := post(handler, "key", `{"amount":"10"}`)
+
+		// assert
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+		assert.Equal(t, "true", rec.Header().Get(idempotency.HeaderReplayed))
+		assert.Equal(t, first.Body.String(), rec.Body.String())
+	})
+
+	t.Run("should reject a key reused with another request", func(t *testing.T) {
+		// arrange
+		handler, calls := newHandler(http.StatusCreated)
+		post(handler, "key", `{"amount":"10"}`)
+
+		// act
+		rec := post(handler, "key", `{"amount":"20"}`)
+
+		// assert
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("should reject a request repeated while the first is served", func(t *testing.T) {
+		// arrange
+		repository := NewRepositoryMock()
+		var rec *httptest.ResponseRecorder
+		var handler http.Handler
+		handler = idempotency.Handler(repository, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec = post(handler, "key", `{"amount":"10"}`)
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		// act
+		post(handler, "key", `{"amount":"10"}`)
+
+		// assert
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("should serve the request again after a server error", func(t *testing.T) {
+		// arrange
+		handler, calls := newHandler(http.StatusInternalServerError)
+		post(handler, "key", `{"amount":"10"}`)
+
+		// act
+		rec := post(handler, "key", `{"amount":"10"}`)
+
+		// assert
+		assert.Equal(t, 2, *calls)
+		assert.Empty(t, rec.Header().Get(idempotency.HeaderReplayed))
+	})
+
+	t.Run("should serve the request again after a panic", func(t *testing.T) {
+		// arrange
+		calls := 0
+		handler := idempotency.Handler(NewRepositoryMock(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				panic("boom")
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+		assert.Panics(t, func() { post(handler, "key", `{"amount":"10"}`) })
+
+		// act
+		rec := post(handler, "key", `{"amount":"10"}`)
+
+		// assert
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Empty(t, rec.Header().Get(idempotency.HeaderReplayed))
+	})
+
+	t.Run("should serve every request without a key", func(t *testing.T) {
+		// arrange
+		handler, calls := newHandler(http.StatusCreated)
+
+		// act
+		post(handler, "", `{"amount":"10"}`)
+		post(handler, "", `{"amount":"10"}`)
+
+		// assert
+		assert.Equal(t, 2, *calls)
+	})
+}
@@ -0,0 +1,85 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/somatom98/brokeli/internal/db"
+)
+
+// keyTTL is how long keys are kept: past it, a key can be used again.
+const keyTTL = 24 * time.Hour
+
+type PostgresRepository struct {
+	queries *db.Queries
+}
+
+func NewPostgresRepository(dbConn *sql.DB) *PostgresRepository {
+	return &PostgresRepository{
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *PostgresRepository) Reserve(ctx context.Context, key, fingerprint string) (bool, error) {
+	now := time.Now()
+	rows, err := r.queries.ReserveIdempotencyKey(ctx, db.ReserveIdempotencyKeyParams{
+		Key:           key,
+		Fingerprint:   fingerprint,
+		CreatedAt:     now,
+		ExpiredBefore: now.Add(-keyTTL),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *PostgresRepository) Get(ctx context.Context, key string) (Entry, error) {
+	row, err := r.queries.GetIdempotencyKey(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Entry{}, ErrNotFound
+	}
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	entry := Entry{Fingerprint: row.Fingerprint}
+	if !row.StatusCode.Valid {
+		return entry, nil
+	}
+
+	var header http.Header
+	if err := json.Unmarshal(row.Headers, &header); err != nil {
+		return Entry{}, fmt.Errorf("failed to unmarshal idempotency key headers: %w", err)
+	}
+
+	entry.Response = &Response{
+		StatusCode: int(row.StatusCode.Int32),
+		Header:     header,
+		Body:       row.Body,
+	}
+	return entry, nil
+}
+
+func (r *PostgresRepository) Complete(ctx context.Context, key string, response Response) error {
+	headers, err := json.Marshal(response.Header)
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency key headers: %w", err)
+	}
+
+	return r.queries.CompleteIdempotencyKey(ctx, db.CompleteIdempotencyKeyParams{
+		Key:        key,
+		StatusCode: sql.NullInt32{Int32: int32(response.StatusCode), Valid: true},
+		Headers:    headers,
+		Body:       response.Body,
+	})
+}
+
+func (r *PostgresRepository) Release(ctx context.Context, key string) error {
+	return r.queries.DeleteIdempotencyKey(ctx, key)
+}
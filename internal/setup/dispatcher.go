@@ -1,15 +1,56 @@
 package setup
 
 import (
+	"os"
+
 	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/loan"
+	"github.com/somatom98/brokeli/internal/domain/position"
+	"github.com/somatom98/brokeli/internal/domain/recurring"
 	"github.com/somatom98/brokeli/internal/domain/transaction"
 	"github.com/somatom98/brokeli/pkg/event_store"
 )
 
-func TransactionDispatcher(es event_store.Store[*transaction.Transaction]) *transaction.Dispatcher {
-	return transaction.NewDispatcher(es)
+func TransactionDispatcher(
+	es event_store.Store[*transaction.Transaction],
+	accountES event_store.Store[*account.Account],
+	positionES event_store.Store[*position.Position],
+	uow event_store.UnitOfWork,
+	referenceRates transaction.ReferenceRates,
+) *transaction.Dispatcher {
+	return transaction.NewDispatcher(es, accountES, positionES, uow,
+		transaction.WithReferenceRates(referenceRates),
+		transaction.WithCostBasisMethod(CostBasisMethod()),
+	)
+}
+
+// CostBasisMethod returns the cost basis method set in COST_BASIS_METHOD, or
+// FIFO if it is unset or unknown.
+func CostBasisMethod() position.CostBasisMethod {
+	method := position.CostBasisMethod(os.Getenv("COST_BASIS_METHOD"))
+	if !method.IsValid() {
+		return position.CostBasisMethod_FIFO
+	}
+	return method
 }
 
 func AccountDispatcher(es event_store.Store[*account.Account]) *account.Dispatcher {
 	return account.NewDispatcher(es)
 }
+
+func LoanDispatcher(
+	es event_store.Store[*loan.Loan],
+	accountES event_store.Store[*account.Account],
+	expenses loan.Expenses,
+	uow event_store.UnitOfWork,
+) *loan.Dispatcher {
+	return loan.NewDispatcher(es, accountES, expenses, uow)
+}
+
+func RecurringDispatcher(
+	es event_store.Store[*recurring.Rule],
+	transactions recurring.Transactions,
+	uow event_store.UnitOfWork,
+) *recurring.Dispatcher {
+	return recurring.NewDispatcher(es, transactions, uow)
+}
@@ -0,0 +1,20 @@
+package setup
+
+import (
+	"os"
+
+	"github.com/somatom98/brokeli/internal/domain/fx"
+)
+
+// RateProvider returns the provider exchange rates are fetched from: the file
+// set in FX_RATES_FILE, or else the endpoint set in FX_RATES_URL, or nil if
+// neither is.
+func RateProvider() fx.RateProvider {
+	if path := os.Getenv("FX_RATES_FILE"); path != "" {
+		return fx.NewFileProvider(path)
+	}
+	if url := os.Getenv("FX_RATES_URL"); url != "" {
+		return fx.NewHTTPProvider(url, nil)
+	}
+	return nil
+}
@@ -3,6 +3,14 @@ package setup
 import (
 	"fmt"
 	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+const (
+	HeaderCorrelationID = "X-Correlation-ID"
+	HeaderUserID        = "X-User-ID"
 )
 
 func HttpHandler() *http.ServeMux {
@@ -15,3 +23,25 @@ func HttpHandler() *http.ServeMux {
 
 	return mux
 }
+
+// EventMetadata records the events appended while serving a request with the
+// correlation ID of the request, taken from the X-Correlation-ID header or
+// generated, and with the user of the X-User-ID header as actor. The
+// correlation ID is echoed in the response.
+func EventMetadata(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationID, err := uuid.Parse(r.Header.Get(HeaderCorrelationID))
+		if err != nil {
+			correlationID = uuid.New()
+		}
+
+		w.Header().Set(HeaderCorrelationID, correlationID.String())
+
+		ctx := event_store.WithMetadata(r.Context(), event_store.Metadata{
+			CorrelationID: correlationID,
+			Actor:         r.Header.Get(HeaderUserID),
+			Source:        event_store.SourceAPI,
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,19 @@
+package setup
+
+import (
+	"os"
+
+	"github.com/somatom98/brokeli/internal/domain/price"
+)
+
+// PriceProvider returns the provider prices are fetched from: the file set in
+// PRICES_FILE, or else the endpoint set in PRICES_URL, or nil if neither is.
+func PriceProvider() price.PriceProvider {
+	if path := os.Getenv("PRICES_FILE"); path != "" {
+		return price.NewFileProvider(path)
+	}
+	if url := os.Getenv("PRICES_URL"); url != "" {
+		return price.NewHTTPProvider(url, nil)
+	}
+	return nil
+}
@@ -4,9 +4,15 @@ import (
 	"context"
 
 	"github.com/somatom98/brokeli/internal/domain/account"
+	"github.com/somatom98/brokeli/internal/domain/loan"
 	"github.com/somatom98/brokeli/internal/domain/projections/accounts"
 	"github.com/somatom98/brokeli/internal/domain/projections/balance_updates"
+	"github.com/somatom98/brokeli/internal/domain/projections/expenses"
+	"github.com/somatom98/brokeli/internal/domain/projections/holdings"
+	"github.com/somatom98/brokeli/internal/domain/projections/recurring_rules"
+	"github.com/somatom98/brokeli/internal/domain/projections/statements"
 	"github.com/somatom98/brokeli/internal/domain/projections/transactions"
+	"github.com/somatom98/brokeli/internal/domain/recurring"
 	"github.com/somatom98/brokeli/internal/domain/transaction"
 	"github.com/somatom98/brokeli/pkg/event_store"
 )
@@ -24,9 +30,10 @@ func BalanceUpdatesProjection(
 	ctx context.Context,
 	transactionES event_store.Store[*transaction.Transaction],
 	accountES event_store.Store[*account.Account],
+	loanES event_store.Store[*loan.Loan],
 	repository balance_updates.Repository,
 ) *balance_updates.Projection {
-	return balance_updates.New(transactionES, accountES, repository)
+	return balance_updates.New(transactionES, accountES, loanES, repository)
 }
 
 func TransactionsProjection(
@@ -37,3 +44,37 @@ func TransactionsProjection(
 ) *transactions.Projection {
 	return transactions.New(transactionES, accountES, repository)
 }
+
+func ExpensesProjection(
+	ctx context.Context,
+	transactionES event_store.Store[*transaction.Transaction],
+	accountES event_store.Store[*account.Account],
+	repository expenses.Repository,
+) *expenses.Projection {
+	return expenses.New(transactionES, accountES, repository)
+}
+
+func StatementsProjection(
+	ctx context.Context,
+	transactionES event_store.Store[*transaction.Transaction],
+	accountES event_store.Store[*account.Account],
+	repository statements.Repository,
+) *statements.Projection {
+	return statements.New(transactionES, accountES, repository)
+}
+
+func RecurringRulesProjection(
+	ctx context.Context,
+	ruleES event_store.Store[*recurring.Rule],
+	repository recurring_rules.Repository,
+) *recurring_rules.Projection {
+	return recurring_rules.New(ruleES, repository)
+}
+
+func HoldingsProjection(
+	ctx context.Context,
+	transactionES event_store.Store[*transaction.Transaction],
+	repository holdings.Repository,
+) *holdings.Projection {
+	return holdings.New(transactionES, repository)
+}
@@ -0,0 +1,115 @@
+package setup
+
+import (
+	"database/sql"
+
+	"github.com/somatom98/brokeli/internal/domain/projections/accounts"
+	"github.com/somatom98/brokeli/internal/domain/projections/balance_updates"
+	"github.com/somatom98/brokeli/internal/domain/projections/expenses"
+	"github.com/somatom98/brokeli/internal/domain/projections/holdings"
+	"github.com/somatom98/brokeli/internal/domain/projections/recurring_rules"
+	"github.com/somatom98/brokeli/internal/domain/projections/statements"
+	"github.com/somatom98/brokeli/internal/domain/projections/transactions"
+	"github.com/somatom98/brokeli/internal/features/rebuild_projections"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+func Rebuilder(
+	db *sql.DB,
+	dsn string,
+	transactionES rebuild_projections.EventStore,
+	accountES rebuild_projections.EventStore,
+	loanES rebuild_projections.EventStore,
+	ruleES rebuild_projections.EventStore,
+) *rebuild_projections.Rebuilder {
+	return rebuild_projections.NewRebuilder(db, dsn, RebuildableProjections(), transactionES, accountES, loanES, ruleES)
+}
+
+func RebuildableProjections() []rebuild_projections.Projection {
+	return []rebuild_projections.Projection{
+		{
+			Name:         "accounts_projection",
+			Subscription: accounts.SubscriptionName,
+			Tables:       []string{"accounts_projection"},
+			New: func(db *sql.DB) (event_store.SubscribeHandler, error) {
+				repository, err := accounts.NewPostgresRepository(db)
+				if err != nil {
+					return nil, err
+				}
+				return accounts.NewProjection(repository).HandleRecord, nil
+			},
+		},
+		{
+			Name:         "balance_updates",
+			Subscription: balance_updates.SubscriptionName,
+			Tables:       []string{"balance_updates"},
+			New: func(db *sql.DB) (event_store.SubscribeHandler, error) {
+				repository, err := balance_updates.NewPostgresRepository(db)
+				if err != nil {
+					return nil, err
+				}
+				return balance_updates.NewProjection(repository).HandleRecord, nil
+			},
+		},
+		{
+			Name:         "transactions",
+			Subscription: transactions.SubscriptionName,
+			Tables:       []string{"transactions"},
+			New: func(db *sql.DB) (event_store.SubscribeHandler, error) {
+				repository, err := transactions.NewPostgresRepository(db)
+				if err != nil {
+					return nil, err
+				}
+				return transactions.NewProjection(repository).HandleRecord, nil
+			},
+		},
+		{
+			Name:         "expenses",
+			Subscription: expenses.SubscriptionName,
+			Tables:       []string{"expenses"},
+			New: func(db *sql.DB) (event_store.SubscribeHandler, error) {
+				repository, err := expenses.NewPostgresRepository(db)
+				if err != nil {
+					return nil, err
+				}
+				return expenses.NewProjection(repository).HandleRecord, nil
+			},
+		},
+		{
+			Name:         "statements",
+			Subscription: statements.SubscriptionName,
+			Tables:       []string{"card_movements", "statements"},
+			New: func(db *sql.DB) (event_store.SubscribeHandler, error) {
+				repository, err := statements.NewPostgresRepository(db)
+				if err != nil {
+					return nil, err
+				}
+				return statements.NewProjection(repository).HandleRecord, nil
+			},
+		},
+		{
+			Name:         "recurring_rules",
+			Subscription: recurring_rules.SubscriptionName,
+			Tables:       []string{"recurring_rules"},
+			New: func(db *sql.DB) (event_store.SubscribeHandler, error) {
+				repository, err := recurring_rules.NewPostgresRepository(db)
+				if err != nil {
+					return nil, err
+				}
+				return recurring_rules.NewProjection(repository).HandleRecord, nil
+			},
+		},
+		{
+			Name:         "holdings",
+			Subscription: holdings.SubscriptionName,
+			Tables:       []string{"holdings", "holding_lots", "holding_trades"},
+			New: func(db *sql.DB) (event_store.SubscribeHandler, error) {
+				repository, err := holdings.NewPostgresRepository(db)
+				if err != nil {
+					return nil, err
+				}
+				return holdings.NewProjection(repository).HandleRecord, nil
+			},
+		},
+	}
+}
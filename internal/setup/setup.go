@@ -7,53 +7,76 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/IBM/sarama"
 	_ "github.com/lib/pq"
 	projections_db "github.com/somatom98/brokeli/internal/db"
 	"github.com/somatom98/brokeli/internal/domain/account"
 	account_events "github.com/somatom98/brokeli/internal/domain/account/events"
 	"github.com/somatom98/brokeli/internal/domain/budget"
+	"github.com/somatom98/brokeli/internal/domain/fx"
+	"github.com/somatom98/brokeli/internal/domain/loan"
+	loan_events "github.com/somatom98/brokeli/internal/domain/loan/events"
+	"github.com/somatom98/brokeli/internal/domain/position"
+	position_events "github.com/somatom98/brokeli/internal/domain/position/events"
+	"github.com/somatom98/brokeli/internal/domain/price"
 	"github.com/somatom98/brokeli/internal/domain/projections/accounts"
 	"github.com/somatom98/brokeli/internal/domain/projections/balance_updates"
+	"github.com/somatom98/brokeli/internal/domain/projections/expenses"
+	"github.com/somatom98/brokeli/internal/domain/projections/holdings"
+	"github.com/somatom98/brokeli/internal/domain/projections/recurring_rules"
+	"github.com/somatom98/brokeli/internal/domain/projections/statements"
 	"github.com/somatom98/brokeli/internal/domain/projections/transactions"
+	"github.com/somatom98/brokeli/internal/domain/recurring"
+	recurring_events "github.com/somatom98/brokeli/internal/domain/recurring/events"
 	"github.com/somatom98/brokeli/internal/domain/transaction"
 	transaction_events "github.com/somatom98/brokeli/internal/domain/transaction/events"
+	"github.com/somatom98/brokeli/internal/domain/valuation"
 	"github.com/somatom98/brokeli/internal/features/import_transactions"
 	"github.com/somatom98/brokeli/internal/features/manage_accounts"
 	"github.com/somatom98/brokeli/internal/features/manage_budgets"
+	"github.com/somatom98/brokeli/internal/features/manage_fx_rates"
+	"github.com/somatom98/brokeli/internal/features/manage_loans"
+	"github.com/somatom98/brokeli/internal/features/manage_prices"
+	"github.com/somatom98/brokeli/internal/features/manage_recurring"
 	"github.com/somatom98/brokeli/internal/features/manage_transactions"
+	"github.com/somatom98/brokeli/internal/features/rebuild_projections"
+	"github.com/somatom98/brokeli/internal/features/trace_events"
+	"github.com/somatom98/brokeli/internal/idempotency"
 	"github.com/somatom98/brokeli/pkg/database"
 	"github.com/somatom98/brokeli/pkg/event_store"
+	"github.com/somatom98/brokeli/pkg/event_store/kafka"
 	"github.com/somatom98/brokeli/pkg/event_store/postgres"
 	event_store_db "github.com/somatom98/brokeli/pkg/event_store/postgres/db"
 )
 
+// schedulerInterval is how often the scheduler posts the recurring
+// transactions falling due.
+const schedulerInterval = time.Hour
+
 type App struct {
 	HttpHandler   *http.ServeMux
+	idempotency   idempotency.Repository
 	httpServer    *http.Server
 	transactionES event_store.Store[*transaction.Transaction]
 	accountES     event_store.Store[*account.Account]
+	loanES        event_store.Store[*loan.Loan]
+	ruleES        event_store.Store[*recurring.Rule]
+	positionES    event_store.Store[*position.Position]
+	scheduler     *recurring.Scheduler
 	db            *sql.DB
+	publisher     *kafka.Publisher
 	cancelRelays  context.CancelFunc
 }
 
 func Setup(ctx context.Context) (*App, error) {
 	httpHandler := HttpHandler()
 
-	db, err := sql.Open("postgres", os.Getenv("DB_DSN"))
+	db, err := OpenDB(os.Getenv("DB_DSN"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to open db: %w", err)
-	}
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping db: %w", err)
-	}
-
-	// Run migrations
-	if err := database.Migrate(db, event_store_db.MigrationsFS(), "event_store_migrations"); err != nil {
-		return nil, fmt.Errorf("failed to run event store migrations: %w", err)
-	}
-	if err := database.Migrate(db, projections_db.MigrationsFS(), "projections_migrations"); err != nil {
-		return nil, fmt.Errorf("failed to run projections migrations: %w", err)
+		return nil, err
 	}
 
 	accountsRepository, err := accounts.NewPostgresRepository(db)
@@ -71,76 +94,187 @@ func Setup(ctx context.Context) (*App, error) {
 		return nil, fmt.Errorf("failed to create transactions repository: %w", err)
 	}
 
-	budgetsRepository := budget.NewPostgresRepository(db)
-
-	var transactionES event_store.Store[*transaction.Transaction]
+	expensesRepository, err := expenses.NewPostgresRepository(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create expenses repository: %w", err)
+	}
 
-	transactionEventsFactory := map[string]func() any{
-		transaction_events.TypeMoneySpent:               func() any { return &transaction_events.MoneySpent{} },
-		transaction_events.TypeMoneyReceived:            func() any { return &transaction_events.MoneyReceived{} },
-		transaction_events.TypeMoneyTransfered:          func() any { return &transaction_events.MoneyTransfered{} },
-		transaction_events.TypeReimbursementReceived:    func() any { return &transaction_events.ReimbursementReceived{} },
-		transaction_events.TypeExpectedReimbursementSet: func() any { return &transaction_events.ExpectedReimbursementSet{} },
-		transaction_events.TypeMoneyInvested:            func() any { return &transaction_events.MoneyInvested{} },
+	statementsRepository, err := statements.NewPostgresRepository(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create statements repository: %w", err)
 	}
 
-	transactionES, err = postgres.NewPostgresStore(db, transaction.New, transactionEventsFactory)
+	recurringRulesRepository, err := recurring_rules.NewPostgresRepository(db)
 	if err != nil {
-		return nil, fmt.Errorf("failed to setup transaction postgres store: %w", err)
+		return nil, fmt.Errorf("failed to create recurring rules repository: %w", err)
 	}
 
-	var accountES event_store.Store[*account.Account]
+	holdingsRepository, err := holdings.NewPostgresRepository(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create holdings repository: %w", err)
+	}
 
-	accountEventsFactory := map[string]func() any{
-		account_events.TypeOpened:         func() any { return &account_events.Opened{} },
-		account_events.TypeNameUpdated:    func() any { return &account_events.NameUpdated{} },
-		account_events.TypeMoneyDeposited: func() any { return &account_events.MoneyDeposited{} },
-		account_events.TypeMoneyWithdrawn: func() any { return &account_events.MoneyWithdrawn{} },
+	budgetsRepository := budget.NewPostgresRepository(db)
+	priceRepository := price.NewPostgresRepository(db)
+	rateRepository := fx.NewPostgresRepository(db)
+	converter := fx.NewConverter(rateRepository)
+
+	opts := make([]event_store.Option, 0)
+	var publisher *kafka.Publisher
+	if brokers := os.Getenv("KAFKA_BROKERS"); brokers != "" {
+		producer, err := sarama.NewSyncProducer(strings.Split(brokers, ","), kafka.NewConfig())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kafka producer: %w", err)
+		}
+		publisher = kafka.NewPublisher(producer, os.Getenv("KAFKA_TOPIC_PREFIX"))
+		opts = append(opts, event_store.WithPublisher(publisher))
 	}
 
-	accountES, err = postgres.NewPostgresStore(db, account.New, accountEventsFactory)
+	transactionES, accountES, loanES, ruleES, positionES, err := EventStores(db, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to setup account postgres store: %w", err)
+		return nil, err
 	}
 
-	transactionDispatcher := TransactionDispatcher(transactionES)
+	transactionDispatcher := TransactionDispatcher(transactionES, accountES, positionES, postgres.NewUnitOfWork(db), converter)
 	accountDispatcher := AccountDispatcher(accountES)
+	loanDispatcher := LoanDispatcher(loanES, accountES, transactionDispatcher, postgres.NewUnitOfWork(db))
+	recurringDispatcher := RecurringDispatcher(ruleES, transactionDispatcher, postgres.NewUnitOfWork(db))
 
 	accountsProjection := AccountsProjection(ctx, transactionES, accountES, accountsRepository)
-	balanceUpdatesProjection := BalanceUpdatesProjection(ctx, transactionES, accountES, balanceUpdatesRepository)
+	balanceUpdatesProjection := BalanceUpdatesProjection(ctx, transactionES, accountES, loanES, balanceUpdatesRepository)
 	transactionsProjection := TransactionsProjection(ctx, transactionES, accountES, transactionsRepository)
+	expensesProjection := ExpensesProjection(ctx, transactionES, accountES, expensesRepository)
+	statementsProjection := StatementsProjection(ctx, transactionES, accountES, statementsRepository)
+	recurringRulesProjection := RecurringRulesProjection(ctx, ruleES, recurringRulesRepository)
+	holdingsProjection := HoldingsProjection(ctx, transactionES, holdingsRepository)
 
 	manage_transactions.
-		New(httpHandler, transactionDispatcher, transactionsProjection).
+		New(httpHandler, transactionDispatcher, transactionsProjection, expensesProjection, converter).
 		Setup()
 
 	manage_accounts.
-		New(httpHandler, accountsProjection, balanceUpdatesProjection, accountDispatcher, transactionES).
+		New(httpHandler, accountsProjection, balanceUpdatesProjection, accountDispatcher, transactionDispatcher, statementsProjection, holdingsProjection, converter).
 		Setup(ctx)
 
 	import_transactions.
 		New(httpHandler, transactionDispatcher, accountDispatcher).
 		Setup()
 
+	manage_loans.
+		New(httpHandler, loanDispatcher).
+		Setup()
+
+	manage_recurring.
+		New(httpHandler, recurringDispatcher, recurringRulesProjection).
+		Setup()
+
 	manage_budgets.
 		New(httpHandler, budgetsRepository, transactionsProjection).
 		Setup(ctx)
 
+	manage_prices.
+		New(httpHandler, priceRepository, PriceProvider(), valuation.NewService(holdingsProjection, priceRepository)).
+		Setup()
+
+	manage_fx_rates.
+		New(httpHandler, rateRepository, RateProvider()).
+		Setup()
+
+	rebuild_projections.
+		New(httpHandler, Rebuilder(db, os.Getenv("DB_DSN"), transactionES, accountES, loanES, ruleES)).
+		Setup()
+
+	trace_events.
+		New(httpHandler, map[string]trace_events.EventStore{
+			"Transaction": transactionES,
+			"Account":     accountES,
+			"Loan":        loanES,
+			"Rule":        ruleES,
+			"Position":    positionES,
+		}).
+		Setup()
+
 	return &App{
 		HttpHandler:   httpHandler,
+		idempotency:   idempotency.NewPostgresRepository(db),
 		transactionES: transactionES,
 		accountES:     accountES,
+		loanES:        loanES,
+		ruleES:        ruleES,
+		positionES:    positionES,
+		scheduler:     recurring.NewScheduler(recurringDispatcher, recurringRulesProjection, schedulerInterval, os.Getenv("RECURRING_CATCH_UP") != "false"),
 		db:            db,
+		publisher:     publisher,
 		cancelRelays:  func() {},
 	}, nil
 }
 
+// OpenDB connects to the database and runs the migrations.
+func OpenDB(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open db: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping db: %w", err)
+	}
+
+	// Run migrations
+	if err := database.Migrate(db, event_store_db.MigrationsFS(), "event_store_migrations"); err != nil {
+		return nil, fmt.Errorf("failed to run event store migrations: %w", err)
+	}
+	if err := database.Migrate(db, projections_db.MigrationsFS(), "projections_migrations"); err != nil {
+		return nil, fmt.Errorf("failed to run projections migrations: %w", err)
+	}
+
+	return db, nil
+}
+
+func EventStores(db *sql.DB, opts ...event_store.Option) (
+	*postgres.PostgresStore[*transaction.Transaction],
+	*postgres.PostgresStore[*account.Account],
+	*postgres.PostgresStore[*loan.Loan],
+	*postgres.PostgresStore[*recurring.Rule],
+	*postgres.PostgresStore[*position.Position],
+	error,
+) {
+	transactionES, err := postgres.NewPostgresStore(db, transaction.New, transaction_events.Factory(), append(opts, event_store.WithUpcasters(transaction_events.Upcasters()))...)
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("failed to setup transaction postgres store: %w", err)
+	}
+
+	accountES, err := postgres.NewPostgresStore(db, account.New, account_events.Factory(), append(opts,
+		event_store.WithUpcasters(account_events.Upcasters()),
+		event_store.WithSnapshotPolicy(event_store.EveryNEvents(100)),
+	)...)
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("failed to setup account postgres store: %w", err)
+	}
+
+	loanES, err := postgres.NewPostgresStore(db, loan.New, loan_events.Factory(), append(opts, event_store.WithUpcasters(loan_events.Upcasters()))...)
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("failed to setup loan postgres store: %w", err)
+	}
+
+	ruleES, err := postgres.NewPostgresStore(db, recurring.New, recurring_events.Factory(), append(opts, event_store.WithUpcasters(recurring_events.Upcasters()))...)
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("failed to setup recurring rule postgres store: %w", err)
+	}
+
+	positionES, err := postgres.NewPostgresStore(db, position.New, position_events.Factory(), append(opts, event_store.WithUpcasters(position_events.Upcasters()))...)
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("failed to setup position postgres store: %w", err)
+	}
+
+	return transactionES, accountES, loanES, ruleES, positionES, nil
+}
+
 func (a *App) Start() <-chan error {
 	port := os.Getenv("PORT")
 
 	a.httpServer = &http.Server{
 		Addr:    ":" + port,
-		Handler: a.HttpHandler,
+		Handler: EventMetadata(idempotency.Handler(a.idempotency, a.HttpHandler)),
 	}
 
 	errCh := make(chan error)
@@ -165,6 +299,39 @@ func (a *App) Start() <-chan error {
 		}()
 	}
 
+	if es, ok := a.loanES.(*postgres.PostgresStore[*loan.Loan]); ok {
+		go func() {
+			if err := es.RunRelay(relayCtx); err != nil && err != context.Canceled {
+				log.Printf("Loan Relay error: %v", err)
+			}
+		}()
+	}
+
+	if es, ok := a.ruleES.(*postgres.PostgresStore[*recurring.Rule]); ok {
+		go func() {
+			if err := es.RunRelay(relayCtx); err != nil && err != context.Canceled {
+				log.Printf("Rule Relay error: %v", err)
+			}
+		}()
+	}
+
+	if es, ok := a.positionES.(*postgres.PostgresStore[*position.Position]); ok {
+		go func() {
+			if err := es.RunRelay(relayCtx); err != nil && err != context.Canceled {
+				log.Printf("Position Relay error: %v", err)
+			}
+		}()
+	}
+
+	// Start the scheduler posting recurring transactions
+	if a.scheduler != nil {
+		go func() {
+			if err := a.scheduler.Run(relayCtx); err != nil && err != context.Canceled {
+				log.Printf("Scheduler error: %v", err)
+			}
+		}()
+	}
+
 	go func() {
 		defer close(errCh)
 
@@ -200,5 +367,21 @@ func (a *App) Stop(ctx context.Context) error {
 		closer.Close()
 	}
 
+	if closer, ok := a.loanES.(interface{ Close() error }); ok {
+		closer.Close()
+	}
+
+	if closer, ok := a.ruleES.(interface{ Close() error }); ok {
+		closer.Close()
+	}
+
+	if closer, ok := a.positionES.(interface{ Close() error }); ok {
+		closer.Close()
+	}
+
+	if a.publisher != nil {
+		a.publisher.Close()
+	}
+
 	return nil
 }
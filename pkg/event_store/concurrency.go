@@ -0,0 +1,141 @@
+package event_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrConcurrencyConflict is returned when events are appended to an
+	// aggregate which is no longer at the version they were decided on.
+	ErrConcurrencyConflict = errors.New("concurrency_conflict")
+	// ErrIfMatchOnCreation is returned for an If-Match header sent along with
+	// the creation of an aggregate, which has no version to match yet.
+	ErrIfMatchOnCreation = errors.New("if_match_on_creation")
+)
+
+// VersionCheck ties the commands executed on an aggregate to its version: they
+// fail with ErrConcurrencyConflict unless the aggregate is at Expected, when it
+// is set, and Version is set to the version they leave the aggregate at.
+type VersionCheck struct {
+	AggregateID uuid.UUID
+	Expected    *uint64
+	Version     uint64
+}
+
+type versionCheckKey struct{}
+
+// WithVersionCheck returns a context whose Execute calls on the aggregate of
+// the check are subject to it.
+func WithVersionCheck(ctx context.Context, check *VersionCheck) context.Context {
+	return context.WithValue(ctx, versionCheckKey{}, check)
+}
+
+// Checked wraps the command of an Execute call on the aggregate with the
+// version check carried by ctx, if any.
+func Checked[A any](ctx context.Context, id uuid.UUID, fn func(aggr A, version uint64) ([]Event, error)) func(aggr A, version uint64) ([]Event, error) {
+	check, ok := ctx.Value(versionCheckKey{}).(*VersionCheck)
+	if !ok || check.AggregateID != id {
+		return fn
+	}
+
+	return func(aggr A, version uint64) ([]Event, error) {
+		if check.Expected != nil && *check.Expected != version {
+			return nil, conflict(id, *check.Expected, version)
+		}
+
+		events, err := fn(aggr, version)
+		if err != nil {
+			return nil, err
+		}
+
+		check.Version = version + uint64(len(NewRecords(id, version, events)))
+		if check.Expected != nil {
+			// The next commands within the same unit of work follow on from
+			// this one.
+			expected := check.Version
+			check.Expected = &expected
+		}
+		return events, nil
+	}
+}
+
+// Expecting returns a command emitting the events only if the aggregate is at
+// expectedVersion.
+func Expecting[A any](id uuid.UUID, expectedVersion uint64, events []Event) func(aggr A, version uint64) ([]Event, error) {
+	return func(aggr A, version uint64) ([]Event, error) {
+		if version != expectedVersion {
+			return nil, conflict(id, expectedVersion, version)
+		}
+		return events, nil
+	}
+}
+
+func conflict(id uuid.UUID, expected, actual uint64) error {
+	return fmt.Errorf("%w: aggregate %s is at version %d, expected %d", ErrConcurrencyConflict, id, actual, expected)
+}
+
+// ETag formats the version of an aggregate as an HTTP entity tag.
+func ETag(version uint64) string {
+	return strconv.Quote(strconv.FormatUint(version, 10))
+}
+
+// ParseETag returns the version of an aggregate formatted by ETag. Weak tags
+// are accepted as well, since versions identify the state of an aggregate.
+func ParseETag(tag string) (uint64, error) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, fmt.Errorf("invalid entity tag %s: %w", tag, err)
+	}
+
+	version, err := strconv.ParseUint(unquoted, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid entity tag %s: %w", tag, err)
+	}
+	return version, nil
+}
+
+// IfMatch returns the version check of a command on the aggregate, expecting
+// the version of an If-Match header unless it is empty or a wildcard.
+func IfMatch(id uuid.UUID, header string) (*VersionCheck, error) {
+	check := &VersionCheck{AggregateID: id}
+	if header = strings.TrimSpace(header); header == "" || header == "*" {
+		return check, nil
+	}
+
+	version, err := ParseETag(header)
+	if err != nil {
+		return nil, err
+	}
+	check.Expected = &version
+	return check, nil
+}
+
+// IfNoneMatch returns the version check of a command creating the aggregate.
+// With an If-None-Match header set to a wildcard, the command applies only if
+// the aggregate does not exist yet. An If-Match header is rejected with
+// ErrIfMatchOnCreation.
+func IfNoneMatch(id uuid.UUID, header http.Header) (*VersionCheck, error) {
+	if strings.TrimSpace(header.Get("If-Match")) != "" {
+		return nil, ErrIfMatchOnCreation
+	}
+
+	check := &VersionCheck{AggregateID: id}
+	switch value := strings.TrimSpace(header.Get("If-None-Match")); value {
+	case "":
+		return check, nil
+	case "*":
+		none := uint64(0)
+		check.Expected = &none
+		return check, nil
+	default:
+		return nil, fmt.Errorf("invalid If-None-Match header %s: only * is supported", value)
+	}
+}
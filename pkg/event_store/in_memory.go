@@ -2,25 +2,52 @@ package event_store
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type InMemoryStore[A Aggregate] struct {
-	mu       sync.RWMutex
-	locks    map[uuid.UUID]*sync.Mutex
-	events   map[uuid.UUID][]Record
-	handlers []SubscribeHandler
-	new      func(uuid.UUID) A
+	mu            sync.RWMutex
+	deliverMu     sync.Mutex
+	locks         map[uuid.UUID]*sync.Mutex
+	events        map[uuid.UUID][]Record
+	log           []Record
+	appended      chan struct{}
+	snapshots     map[uuid.UUID]snapshot
+	handlers      []SubscribeHandler
+	subscriptions []*namedSubscription
+	deadLetters   []DeadLetter
+	new           func(uuid.UUID) A
+	options       Options
 }
 
-func NewInMemory[A Aggregate](new func(uuid.UUID) A) *InMemoryStore[A] {
+type namedSubscription struct {
+	name     string
+	handler  SubscribeHandler
+	position uint64
+}
+
+type snapshot struct {
+	version uint64
+	data    []byte
+}
+
+func NewInMemory[A Aggregate](new func(uuid.UUID) A, opts ...Option) *InMemoryStore[A] {
 	return &InMemoryStore[A]{
-		locks:    make(map[uuid.UUID]*sync.Mutex),
-		events:   make(map[uuid.UUID][]Record),
-		handlers: make([]SubscribeHandler, 0),
-		new:      new,
+		locks:         make(map[uuid.UUID]*sync.Mutex),
+		events:        make(map[uuid.UUID][]Record),
+		log:           make([]Record, 0),
+		appended:      make(chan struct{}),
+		snapshots:     make(map[uuid.UUID]snapshot),
+		handlers:      make([]SubscribeHandler, 0),
+		subscriptions: make([]*namedSubscription, 0),
+		deadLetters:   make([]DeadLetter, 0),
+		new:           new,
+		options:       NewOptions(opts...),
 	}
 }
 
@@ -44,7 +71,129 @@ func (s *InMemoryStore[A]) Subscribe(ctx context.Context, handler SubscribeHandl
 	s.handlers = append(s.handlers, handler)
 }
 
-func (s *InMemoryStore[A]) Execute(ctx context.Context, id uuid.UUID, fn func(aggr A, version uint64) (Event, error)) error {
+func (s *InMemoryStore[A]) SubscribeFrom(ctx context.Context, position uint64, handler SubscribeHandler) {
+	go func() {
+		for {
+			s.mu.RLock()
+			records := s.log[min(position, uint64(len(s.log))):]
+			appended := s.appended
+			s.mu.RUnlock()
+
+			for _, record := range records {
+				if ctx.Err() != nil {
+					return
+				}
+
+				_ = handler(CausedBy(ctx, record), record)
+				position = record.Position
+			}
+
+			if len(records) > 0 {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-appended:
+			}
+		}
+	}()
+}
+
+// SubscribeNamed delivers the history and every new record to the handler,
+// synchronously with the Append that stored it. Registering a name twice
+// replaces the handler and keeps the checkpoint.
+func (s *InMemoryStore[A]) SubscribeNamed(ctx context.Context, name string, handler SubscribeHandler) {
+	s.mu.Lock()
+	idx := slices.IndexFunc(s.subscriptions, func(sub *namedSubscription) bool {
+		return sub.name == name
+	})
+	if idx >= 0 {
+		s.subscriptions[idx].handler = handler
+	} else {
+		s.subscriptions = append(s.subscriptions, &namedSubscription{name: name, handler: handler})
+	}
+	s.mu.Unlock()
+
+	s.dispatch(ctx)
+}
+
+// DeadLetters returns the records the named subscriptions gave up on.
+func (s *InMemoryStore[A]) DeadLetters() []DeadLetter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.deadLetters)
+}
+
+// dispatch brings the named subscriptions up to date with the log. Only one
+// goroutine delivers at a time: records appended meanwhile, including by the
+// handlers themselves, are picked up by the one already delivering.
+func (s *InMemoryStore[A]) dispatch(ctx context.Context) {
+	for s.pending() {
+		if !s.deliverMu.TryLock() {
+			return
+		}
+		s.catchUp(ctx)
+		s.deliverMu.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (s *InMemoryStore[A]) pending() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, sub := range s.subscriptions {
+		if sub.position < uint64(len(s.log)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *InMemoryStore[A]) catchUp(ctx context.Context) {
+	s.mu.RLock()
+	subscriptions := slices.Clone(s.subscriptions)
+	s.mu.RUnlock()
+
+	for _, sub := range subscriptions {
+		s.mu.RLock()
+		records := s.log[sub.position:]
+		handler := sub.handler
+		s.mu.RUnlock()
+
+		for _, record := range records {
+			attempts, err := Deliver(ctx, s.options.RetryPolicy, handler, record)
+			if ctx.Err() != nil {
+				return
+			}
+
+			s.mu.Lock()
+			if err != nil {
+				s.deadLetters = append(s.deadLetters, DeadLetter{
+					Subscription: sub.name,
+					Record:       record,
+					Attempts:     attempts,
+					Error:        err.Error(),
+				})
+			}
+			sub.position = record.Position
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *InMemoryStore[A]) Execute(ctx context.Context, id uuid.UUID, fn func(aggr A, version uint64) ([]Event, error)) error {
+	fn = Checked(ctx, id, fn)
+	if unit, ok := ctx.Value(unitKey{}).(*inMemoryUnit); ok {
+		return s.executeInUnit(ctx, unit, id, fn)
+	}
+
 	lock := s.getLock(id)
 	lock.Lock()
 	defer lock.Unlock()
@@ -54,23 +203,92 @@ func (s *InMemoryStore[A]) Execute(ctx context.Context, id uuid.UUID, fn func(ag
 		return err
 	}
 
-	e, err := fn(aggr, version)
+	events, err := fn(aggr, version)
+	if err != nil {
+		return err
+	}
+
+	records := NewRecords(id, version, events)
+	if len(records) == 0 {
+		return nil
+	}
+
+	for _, record := range records {
+		if err := s.Append(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	return s.snapshotAfter(id, aggr, version, records)
+}
+
+// executeInUnit stages the events of the command in the unit, on top of the
+// ones the unit already staged for the aggregate.
+func (s *InMemoryStore[A]) executeInUnit(ctx context.Context, unit *inMemoryUnit, id uuid.UUID, fn func(aggr A, version uint64) ([]Event, error)) error {
+	unit.lock(s.getLock(id))
+
+	aggr, version, err := s.GetAggregate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	key := stagedKey{store: s, id: id}
+	staged := unit.staged[key]
+	if len(staged) > 0 {
+		if err := aggr.Hydrate(staged); err != nil {
+			return fmt.Errorf("failed to hydrate aggregate: %w", err)
+		}
+		version += uint64(len(staged))
+	}
+
+	events, err := fn(aggr, version)
 	if err != nil {
 		return err
 	}
 
-	if e == nil {
+	records := NewRecords(id, version, events)
+	if len(records) == 0 {
 		return nil
 	}
 
-	return s.Append(ctx, Record{
-		AggregateID: id,
-		Version:     version + 1,
-		Event: simpleEvent{
-			eventType: e.Type(),
-			content:   e.Content(),
-		},
+	// The metadata is the one of the command, not of the commit.
+	for i := range records {
+		records[i] = Stamp(ctx, records[i])
+	}
+
+	unit.staged[key] = append(staged, records...)
+	unit.commits = append(unit.commits, func(ctx context.Context) func() {
+		stored := make([]Record, 0, len(records))
+		for _, record := range records {
+			stored = append(stored, s.store(ctx, record))
+		}
+
+		// Snapshots are only an optimisation, the unit is committed anyway.
+		_ = s.snapshotAfter(id, aggr, version, records)
+
+		return func() {
+			for _, record := range stored {
+				s.publish(ctx, record)
+			}
+		}
 	})
+
+	return nil
+}
+
+// snapshotAfter takes a snapshot of the aggregate, hydrated up to version,
+// when the policy asks for one after the records.
+func (s *InMemoryStore[A]) snapshotAfter(id uuid.UUID, aggr A, version uint64, records []Record) error {
+	last := records[len(records)-1].Version
+	if !s.options.SnapshotPolicy(version, last) {
+		return nil
+	}
+
+	if err := aggr.Hydrate(records); err != nil {
+		return fmt.Errorf("failed to hydrate aggregate: %w", err)
+	}
+
+	return s.saveSnapshot(id, aggr, last)
 }
 
 type simpleEvent struct {
@@ -87,17 +305,59 @@ func (e simpleEvent) Content() any {
 }
 
 func (s *InMemoryStore[A]) Append(ctx context.Context, record Record) error {
+	s.publish(ctx, s.store(ctx, record))
+	return nil
+}
+
+func (s *InMemoryStore[A]) AppendExpected(ctx context.Context, id uuid.UUID, expectedVersion uint64, events []Event) error {
+	return s.Execute(ctx, id, Expecting[A](id, expectedVersion, events))
+}
+
+// store adds the record to the log and returns it as stored.
+func (s *InMemoryStore[A]) store(ctx context.Context, record Record) Record {
+	record = Stamp(ctx, record)
+	record.RecordedAt = time.Now()
+
 	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record.Position = uint64(len(s.log)) + 1
 	s.events[record.AggregateID] = append(s.events[record.AggregateID], record)
-	handlers := make([]SubscribeHandler, len(s.handlers))
-	copy(handlers, s.handlers)
-	s.mu.Unlock()
+	s.log = append(s.log, record)
+
+	// Wake up the catch-up subscriptions waiting for new records.
+	close(s.appended)
+	s.appended = make(chan struct{})
+
+	return record
+}
+
+// publish delivers a stored record to the subscribers.
+func (s *InMemoryStore[A]) publish(ctx context.Context, record Record) {
+	s.mu.RLock()
+	handlers := slices.Clone(s.handlers)
+	s.mu.RUnlock()
 
 	for _, h := range handlers {
-		_ = h(ctx, record)
+		_ = h(CausedBy(ctx, record), record)
 	}
 
-	return nil
+	s.dispatch(ctx)
+}
+
+func (s *InMemoryStore[A]) ReadFrom(ctx context.Context, position uint64, limit int) ([]Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := s.log[min(position, uint64(len(s.log))):]
+	return slices.Clone(records[:min(limit, len(records))]), nil
+}
+
+func (s *InMemoryStore[A]) ReadAggregate(ctx context.Context, id uuid.UUID) ([]Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.events[id]), nil
 }
 
 func (s *InMemoryStore[A]) GetAggregate(ctx context.Context, id uuid.UUID) (A, uint64, error) {
@@ -105,15 +365,61 @@ func (s *InMemoryStore[A]) GetAggregate(ctx context.Context, id uuid.UUID) (A, u
 	defer s.mu.RUnlock()
 
 	aggregate := s.new(id)
-	records, ok := s.events[id]
-	if !ok {
-		return aggregate, 0, nil
+	records := s.events[id]
+
+	var version uint64
+	if snap, ok := s.snapshots[id]; ok {
+		if snapshotter, ok := any(aggregate).(Snapshotter); ok && snapshotter.Restore(snap.data) == nil {
+			version = snap.version
+		} else {
+			aggregate = s.new(id)
+		}
 	}
 
-	if err := aggregate.Hydrate(records); err != nil {
+	if err := aggregate.Hydrate(records[version:]); err != nil {
 		var zero A
 		return zero, 0, err
 	}
 
 	return aggregate, uint64(len(records)), nil
 }
+
+// TakeSnapshot stores the current state of the aggregate, if it supports snapshots.
+func (s *InMemoryStore[A]) TakeSnapshot(ctx context.Context, id uuid.UUID) error {
+	lock := s.getLock(id)
+	lock.Lock()
+	defer lock.Unlock()
+
+	aggr, version, err := s.GetAggregate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		return nil
+	}
+
+	return s.saveSnapshot(id, aggr, version)
+}
+
+func (s *InMemoryStore[A]) saveSnapshot(id uuid.UUID, aggr A, version uint64) error {
+	snapshotter, ok := any(aggr).(Snapshotter)
+	if !ok {
+		return nil
+	}
+
+	data, err := snapshotter.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot aggregate %s: %w", id, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshots[id] = snapshot{
+		version: version,
+		data:    data,
+	}
+
+	return nil
+}
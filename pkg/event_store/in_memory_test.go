@@ -0,0 +1,638 @@
+package event_store_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+type counterEvent struct{}
+
+func (e counterEvent) Type() string { return "Incremented" }
+func (e counterEvent) Content() any { return e }
+
+// counter counts the records it was hydrated with, so tests can tell a full
+// replay apart from a replay starting at a snapshot.
+type counter struct {
+	ID       uuid.UUID
+	Count    int
+	Hydrated int
+}
+
+func (c *counter) Hydrate(records []event_store.Record) error {
+	c.Count += len(records)
+	c.Hydrated += len(records)
+	return nil
+}
+
+func (c *counter) Snapshot() ([]byte, error) {
+	return json.Marshal(c.Count)
+}
+
+func (c *counter) Restore(data []byte) error {
+	return json.Unmarshal(data, &c.Count)
+}
+
+func increment(aggr *counter, version uint64) ([]event_store.Event, error) {
+	return []event_store.Event{counterEvent{}}, nil
+}
+
+func newCounter(id uuid.UUID) *counter {
+	return &counter{ID: id}
+}
+
+func TestInMemoryStore_Snapshots(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should hydrate from the latest snapshot taken by the policy", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter, event_store.WithSnapshotPolicy(event_store.EveryNEvents(3)))
+		id := uuid.New()
+
+		// act
+		for range 7 {
+			require.NoError(t, store.Execute(ctx, id, increment))
+		}
+		aggr, version, err := store.GetAggregate(ctx, id)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, uint64(7), version)
+		assert.Equal(t, 7, aggr.Count)
+		assert.Equal(t, 1, aggr.Hydrated)
+	})
+
+	t.Run("should replay the full history when no snapshot was taken", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+		id := uuid.New()
+
+		// act
+		for range 4 {
+			require.NoError(t, store.Execute(ctx, id, increment))
+		}
+		aggr, version, err := store.GetAggregate(ctx, id)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), version)
+		assert.Equal(t, 4, aggr.Count)
+		assert.Equal(t, 4, aggr.Hydrated)
+	})
+
+	t.Run("should take a snapshot on demand", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+		id := uuid.New()
+		for range 4 {
+			require.NoError(t, store.Execute(ctx, id, increment))
+		}
+
+		// act
+		require.NoError(t, store.TakeSnapshot(ctx, id))
+		require.NoError(t, store.Execute(ctx, id, increment))
+		aggr, version, err := store.GetAggregate(ctx, id)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, uint64(5), version)
+		assert.Equal(t, 5, aggr.Count)
+		assert.Equal(t, 1, aggr.Hydrated)
+	})
+}
+
+func TestInMemoryStore_SubscribeFrom(t *testing.T) {
+	t.Run("should replay the history after the position and then deliver new records", func(t *testing.T) {
+		// arrange
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		store := event_store.NewInMemory(newCounter)
+		id := uuid.New()
+		for range 3 {
+			require.NoError(t, store.Execute(ctx, id, increment))
+		}
+
+		var mu sync.Mutex
+		positions := make([]uint64, 0)
+		handler := func(ctx context.Context, record event_store.Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			positions = append(positions, record.Position)
+			return nil
+		}
+
+		// act
+		store.SubscribeFrom(ctx, 1, handler)
+		for range 2 {
+			require.NoError(t, store.Execute(ctx, uuid.New(), increment))
+		}
+
+		// assert
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return assert.ObjectsAreEqual([]uint64{2, 3, 4, 5}, positions)
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("should stop delivering once the context is cancelled", func(t *testing.T) {
+		// arrange
+		ctx, cancel := context.WithCancel(context.Background())
+		store := event_store.NewInMemory(newCounter)
+
+		var mu sync.Mutex
+		delivered := 0
+		store.SubscribeFrom(ctx, 0, func(ctx context.Context, record event_store.Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			delivered++
+			return nil
+		})
+		require.NoError(t, store.Execute(context.Background(), uuid.New(), increment))
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return delivered == 1
+		}, time.Second, 10*time.Millisecond)
+
+		// act
+		cancel()
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, store.Execute(context.Background(), uuid.New(), increment))
+		time.Sleep(50 * time.Millisecond)
+
+		// assert
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, 1, delivered)
+	})
+}
+
+func TestInMemoryStore_SubscribeNamed(t *testing.T) {
+	ctx := context.Background()
+	retryPolicy := event_store.WithRetryPolicy(event_store.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	})
+
+	t.Run("should deliver the history to a late subscriber", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+		for range 2 {
+			require.NoError(t, store.Execute(ctx, uuid.New(), increment))
+		}
+
+		positions := make([]uint64, 0)
+
+		// act
+		store.SubscribeNamed(ctx, "test", func(ctx context.Context, record event_store.Record) error {
+			positions = append(positions, record.Position)
+			return nil
+		})
+		require.NoError(t, store.Execute(ctx, uuid.New(), increment))
+
+		// assert
+		assert.Equal(t, []uint64{1, 2, 3}, positions)
+	})
+
+	t.Run("should retry a failing record until it succeeds", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter, retryPolicy)
+		attempts := 0
+		store.SubscribeNamed(ctx, "test", func(ctx context.Context, record event_store.Record) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("temporary_failure")
+			}
+			return nil
+		})
+
+		// act
+		require.NoError(t, store.Execute(ctx, uuid.New(), increment))
+
+		// assert
+		assert.Equal(t, 3, attempts)
+		assert.Empty(t, store.DeadLetters())
+	})
+
+	t.Run("should dead-letter a poison record and move on", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter, retryPolicy)
+		poison := uuid.New()
+		delivered := make([]uuid.UUID, 0)
+		store.SubscribeNamed(ctx, "test", func(ctx context.Context, record event_store.Record) error {
+			if record.AggregateID == poison {
+				return errors.New("poison")
+			}
+			delivered = append(delivered, record.AggregateID)
+			return nil
+		})
+
+		// act
+		require.NoError(t, store.Execute(ctx, poison, increment))
+		healthy := uuid.New()
+		require.NoError(t, store.Execute(ctx, healthy, increment))
+
+		// assert
+		assert.Equal(t, []uuid.UUID{healthy}, delivered)
+		deadLetters := store.DeadLetters()
+		require.Len(t, deadLetters, 1)
+		assert.Equal(t, "test", deadLetters[0].Subscription)
+		assert.Equal(t, poison, deadLetters[0].Record.AggregateID)
+		assert.Equal(t, 3, deadLetters[0].Attempts)
+		assert.Equal(t, "poison", deadLetters[0].Error)
+	})
+
+	t.Run("should not let a failing subscription affect the others", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter, retryPolicy)
+		store.SubscribeNamed(ctx, "failing", func(ctx context.Context, record event_store.Record) error {
+			return errors.New("failure")
+		})
+		delivered := 0
+		store.SubscribeNamed(ctx, "healthy", func(ctx context.Context, record event_store.Record) error {
+			delivered++
+			return nil
+		})
+
+		// act
+		for range 2 {
+			require.NoError(t, store.Execute(ctx, uuid.New(), increment))
+		}
+
+		// assert
+		assert.Equal(t, 2, delivered)
+		assert.Len(t, store.DeadLetters(), 2)
+	})
+}
+
+func TestInMemoryStore_ReadFrom(t *testing.T) {
+	t.Run("should return a page of records after the position", func(t *testing.T) {
+		// arrange
+		ctx := context.Background()
+		store := event_store.NewInMemory(newCounter)
+		for range 5 {
+			require.NoError(t, store.Execute(ctx, uuid.New(), increment))
+		}
+
+		// act
+		records, err := store.ReadFrom(ctx, 2, 2)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, uint64(3), records[0].Position)
+		assert.Equal(t, uint64(4), records[1].Position)
+	})
+}
+
+func TestInMemoryStore_Metadata(t *testing.T) {
+	t.Run("should record the metadata of the context the event was appended in", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+		metadata := event_store.Metadata{
+			CorrelationID: uuid.New(),
+			Actor:         "marco",
+			Source:        event_store.SourceAPI,
+		}
+		ctx := event_store.WithMetadata(context.Background(), metadata)
+
+		// act
+		require.NoError(t, store.Execute(ctx, uuid.New(), increment))
+		records, err := store.ReadFrom(ctx, 0, 10)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.NotEqual(t, uuid.Nil, records[0].ID)
+		assert.Equal(t, metadata, records[0].Metadata)
+		assert.False(t, records[0].RecordedAt.IsZero())
+	})
+
+	t.Run("should start a correlation when the context carries none", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+
+		// act
+		require.NoError(t, store.Execute(context.Background(), uuid.New(), increment))
+		records, err := store.ReadFrom(context.Background(), 0, 10)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, records[0].ID, records[0].Metadata.CorrelationID)
+	})
+
+	t.Run("should link the events appended by a subscription to the event that caused them", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+		source, reaction := uuid.New(), uuid.New()
+		store.SubscribeNamed(context.Background(), "reactions", func(ctx context.Context, record event_store.Record) error {
+			if record.AggregateID != source {
+				return nil
+			}
+			return store.Execute(ctx, reaction, increment)
+		})
+		ctx := event_store.WithMetadata(context.Background(), event_store.Metadata{
+			CorrelationID: uuid.New(),
+			Actor:         "marco",
+			Source:        event_store.SourceAPI,
+		})
+
+		// act
+		require.NoError(t, store.Execute(ctx, source, increment))
+		records, err := store.ReadFrom(ctx, 0, 10)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, event_store.Metadata{
+			CorrelationID: records[0].Metadata.CorrelationID,
+			CausationID:   records[0].ID,
+			Actor:         "marco",
+			Source:        event_store.SourceSystem,
+		}, records[1].Metadata)
+	})
+}
+
+func TestInMemoryStore_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should append every event emitted by the command", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+		id := uuid.New()
+
+		// act
+		err := store.Execute(ctx, id, func(aggr *counter, version uint64) ([]event_store.Event, error) {
+			return []event_store.Event{counterEvent{}, nil, counterEvent{}}, nil
+		})
+		aggr, version, getErr := store.GetAggregate(ctx, id)
+
+		// assert
+		require.NoError(t, err)
+		require.NoError(t, getErr)
+		assert.Equal(t, uint64(2), version)
+		assert.Equal(t, 2, aggr.Count)
+	})
+}
+
+func TestInMemoryStore_AppendExpected(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should append the events at the expected version", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+		id := uuid.New()
+		require.NoError(t, store.Execute(ctx, id, increment))
+
+		// act
+		err := store.AppendExpected(ctx, id, 1, []event_store.Event{counterEvent{}, counterEvent{}})
+		_, version, getErr := store.GetAggregate(ctx, id)
+
+		// assert
+		require.NoError(t, err)
+		require.NoError(t, getErr)
+		assert.Equal(t, uint64(3), version)
+	})
+
+	t.Run("should return a concurrency conflict at another version", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+		id := uuid.New()
+		require.NoError(t, store.Execute(ctx, id, increment))
+
+		// act
+		err := store.AppendExpected(ctx, id, 0, []event_store.Event{counterEvent{}})
+		_, version, getErr := store.GetAggregate(ctx, id)
+
+		// assert
+		assert.ErrorIs(t, err, event_store.ErrConcurrencyConflict)
+		require.NoError(t, getErr)
+		assert.Equal(t, uint64(1), version)
+	})
+}
+
+func TestInMemoryStore_VersionCheck(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should report the version the command leaves the aggregate at", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+		id := uuid.New()
+		require.NoError(t, store.Execute(ctx, id, increment))
+		expected := uint64(1)
+		check := &event_store.VersionCheck{AggregateID: id, Expected: &expected}
+
+		// act
+		err := store.Execute(event_store.WithVersionCheck(ctx, check), id, increment)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), check.Version)
+	})
+
+	t.Run("should reject the command when the aggregate moved on", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+		id := uuid.New()
+		require.NoError(t, store.Execute(ctx, id, increment))
+		expected := uint64(0)
+		check := &event_store.VersionCheck{AggregateID: id, Expected: &expected}
+
+		// act
+		err := store.Execute(event_store.WithVersionCheck(ctx, check), id, increment)
+
+		// assert
+		assert.ErrorIs(t, err, event_store.ErrConcurrencyConflict)
+	})
+
+	t.Run("should not check the other aggregates", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+		id, other := uuid.New(), uuid.New()
+		require.NoError(t, store.Execute(ctx, other, increment))
+		expected := uint64(0)
+		check := &event_store.VersionCheck{AggregateID: id, Expected: &expected}
+
+		// act
+		err := store.Execute(event_store.WithVersionCheck(ctx, check), other, increment)
+
+		// assert
+		require.NoError(t, err)
+		assert.Zero(t, check.Version)
+	})
+
+	t.Run("should follow on from the previous command within a unit of work", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+		id := uuid.New()
+		require.NoError(t, store.Execute(ctx, id, increment))
+		expected := uint64(1)
+		check := &event_store.VersionCheck{AggregateID: id, Expected: &expected}
+		ctx := event_store.WithVersionCheck(ctx, check)
+
+		// act
+		err := event_store.NewInMemoryUnitOfWork().Do(ctx, func(ctx context.Context) error {
+			if err := store.Execute(ctx, id, increment); err != nil {
+				return err
+			}
+			return store.Execute(ctx, id, increment)
+		})
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), check.Version)
+	})
+}
+
+func TestIfMatch(t *testing.T) {
+	id := uuid.New()
+	version := uint64(3)
+
+	tests := []struct {
+		name     string
+		header   string
+		expected *uint64
+		wantErr  bool
+	}{
+		{name: "should not expect a version without a header", header: ""},
+		{name: "should not expect a version with a wildcard", header: "*"},
+		{name: "should expect the version of a strong tag", header: event_store.ETag(version), expected: &version},
+		{name: "should expect the version of a weak tag", header: `W/"3"`, expected: &version},
+		{name: "should reject an unquoted tag", header: "3", wantErr: true},
+		{name: "should reject a tag which is not a version", header: `"abc"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			check, err := event_store.IfMatch(id, tt.header)
+
+			// assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, id, check.AggregateID)
+			assert.Equal(t, tt.expected, check.Expected)
+		})
+	}
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	id := uuid.New()
+	none := uint64(0)
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		ifMatch     string
+		expected    *uint64
+		wantErr     bool
+	}{
+		{name: "should not expect a version without a header"},
+		{name: "should expect no version with a wildcard", ifNoneMatch: "*", expected: &none},
+		{name: "should reject an entity tag", ifNoneMatch: `"1"`, wantErr: true},
+		{name: "should reject an If-Match header", ifMatch: `"1"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			header := http.Header{}
+			if tt.ifNoneMatch != "" {
+				header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			if tt.ifMatch != "" {
+				header.Set("If-Match", tt.ifMatch)
+			}
+
+			// act
+			check, err := event_store.IfNoneMatch(id, header)
+
+			// assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, id, check.AggregateID)
+			assert.Equal(t, tt.expected, check.Expected)
+		})
+	}
+}
+
+func TestInMemoryUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should commit the events of every aggregate together", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+		uow := event_store.NewInMemoryUnitOfWork()
+		first, second := uuid.New(), uuid.New()
+
+		var delivered []uuid.UUID
+		store.Subscribe(ctx, func(ctx context.Context, record event_store.Record) error {
+			delivered = append(delivered, record.AggregateID)
+			return nil
+		})
+
+		// act
+		err := uow.Do(ctx, func(ctx context.Context) error {
+			require.NoError(t, store.Execute(ctx, first, increment))
+			require.NoError(t, store.Execute(ctx, first, increment))
+			require.NoError(t, store.Execute(ctx, second, increment))
+
+			assert.Empty(t, delivered)
+			return nil
+		})
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{first, first, second}, delivered)
+
+		_, version, err := store.GetAggregate(ctx, first)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), version)
+	})
+
+	t.Run("should discard every event when the unit fails", func(t *testing.T) {
+		// arrange
+		store := event_store.NewInMemory(newCounter)
+		uow := event_store.NewInMemoryUnitOfWork()
+		first, second := uuid.New(), uuid.New()
+		failure := errors.New("failure")
+
+		// act
+		err := uow.Do(ctx, func(ctx context.Context) error {
+			require.NoError(t, store.Execute(ctx, first, increment))
+			return store.Execute(ctx, second, func(aggr *counter, version uint64) ([]event_store.Event, error) {
+				return nil, failure
+			})
+		})
+
+		// assert
+		assert.ErrorIs(t, err, failure)
+
+		records, err := store.ReadFrom(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, records)
+
+		// The aggregates are released.
+		require.NoError(t, store.Execute(ctx, first, increment))
+	})
+}
@@ -0,0 +1,217 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+// Consumer reads the events published by Publisher from a topic and hands
+// them to its subscribers, so that read models can live in another process.
+// Offsets are committed per consumer group once every handler was called.
+type Consumer struct {
+	group        sarama.ConsumerGroup
+	topic        string
+	eventFactory map[string]func() any
+	options      event_store.Options
+	mu           sync.RWMutex
+	handlers     []event_store.SubscribeHandler
+}
+
+var _ sarama.ConsumerGroupHandler = &Consumer{}
+
+func NewConsumer(
+	group sarama.ConsumerGroup,
+	topic string,
+	eventFactory map[string]func() any,
+	opts ...event_store.Option,
+) *Consumer {
+	return &Consumer{
+		group:        group,
+		topic:        topic,
+		eventFactory: eventFactory,
+		options:      event_store.NewOptions(opts...),
+		handlers:     make([]event_store.SubscribeHandler, 0),
+	}
+}
+
+func (c *Consumer) Subscribe(ctx context.Context, handler event_store.SubscribeHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.handlers = append(c.handlers, handler)
+}
+
+// Run consumes the topic until ctx is cancelled, rejoining the consumer group
+// after each rebalance.
+func (c *Consumer) Run(ctx context.Context) error {
+	for {
+		if err := c.group.Consume(ctx, []string{c.topic}, c); err != nil {
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				return nil
+			}
+			return fmt.Errorf("failed to consume %s: %w", c.topic, err)
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *Consumer) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (c *Consumer) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	ctx := session.Context()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+
+			c.handle(ctx, message)
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			session.MarkMessage(message, "")
+		}
+	}
+}
+
+func (c *Consumer) handle(ctx context.Context, message *sarama.ConsumerMessage) {
+	record, err := c.decode(message)
+	if err != nil {
+		log.Printf("skipping message %s/%d/%d: %v", message.Topic, message.Partition, message.Offset, err)
+		return
+	}
+
+	c.mu.RLock()
+	handlers := c.handlers
+	c.mu.RUnlock()
+
+	for _, h := range handlers {
+		attempts, err := event_store.Deliver(ctx, c.options.RetryPolicy, h, record)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Event Store handler error on %s at position %d after %d attempts: %v", record.Type(), record.Position, attempts, err)
+		}
+	}
+}
+
+func (c *Consumer) decode(message *sarama.ConsumerMessage) (event_store.Record, error) {
+	headers := make(map[string]string, len(message.Headers))
+	for _, h := range message.Headers {
+		headers[string(h.Key)] = string(h.Value)
+	}
+
+	aggregateID, err := uuid.Parse(string(message.Key))
+	if err != nil {
+		return event_store.Record{}, fmt.Errorf("invalid aggregate id: %w", err)
+	}
+
+	version, err := strconv.ParseUint(headers[HeaderVersion], 10, 64)
+	if err != nil {
+		return event_store.Record{}, fmt.Errorf("invalid version: %w", err)
+	}
+
+	position, err := strconv.ParseUint(headers[HeaderPosition], 10, 64)
+	if err != nil {
+		return event_store.Record{}, fmt.Errorf("invalid position: %w", err)
+	}
+
+	// Messages published before schema versions were introduced carry the
+	// first one.
+	schemaVersion := 1
+	if v, ok := headers[HeaderSchemaVersion]; ok {
+		schemaVersion, err = strconv.Atoi(v)
+		if err != nil {
+			return event_store.Record{}, fmt.Errorf("invalid schema version: %w", err)
+		}
+	}
+
+	id, err := uuid.Parse(headers[HeaderEventID])
+	if err != nil {
+		return event_store.Record{}, fmt.Errorf("invalid event id: %w", err)
+	}
+
+	metadata, err := decodeMetadata(headers)
+	if err != nil {
+		return event_store.Record{}, err
+	}
+
+	eventType := headers[HeaderEventType]
+	content, err := event_store.UnmarshalEvent(c.eventFactory, c.options.Upcasters, eventType, schemaVersion, message.Value)
+	if err != nil {
+		return event_store.Record{}, err
+	}
+
+	return event_store.Record{
+		ID:          id,
+		AggregateID: aggregateID,
+		Version:     version,
+		Position:    position,
+		Metadata:    metadata,
+		RecordedAt:  message.Timestamp,
+		Event: event{
+			eventType: eventType,
+			content:   content,
+		},
+	}, nil
+}
+
+// decodeMetadata reads the metadata headers, which messages published before
+// they were introduced do not have.
+func decodeMetadata(headers map[string]string) (event_store.Metadata, error) {
+	metadata := event_store.Metadata{
+		Actor:  headers[HeaderActor],
+		Source: headers[HeaderSource],
+	}
+
+	for header, id := range map[string]*uuid.UUID{
+		HeaderCorrelationID: &metadata.CorrelationID,
+		HeaderCausationID:   &metadata.CausationID,
+	} {
+		value, ok := headers[header]
+		if !ok {
+			continue
+		}
+
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			return event_store.Metadata{}, fmt.Errorf("invalid %s: %w", header, err)
+		}
+		*id = parsed
+	}
+
+	return metadata, nil
+}
+
+type event struct {
+	eventType string
+	content   any
+}
+
+func (e event) Type() string {
+	return e.eventType
+}
+
+func (e event) Content() any {
+	return e.content
+}
@@ -0,0 +1,213 @@
+package kafka_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/pkg/event_store"
+	"github.com/somatom98/brokeli/pkg/event_store/kafka"
+)
+
+type spent struct {
+	Amount string `json:"amount"`
+}
+
+var eventFactory = map[string]func() any{
+	"MoneySpent": func() any { return &spent{} },
+}
+
+// fakeSession and fakeClaim stand in for a consumer group generation, the way
+// the broker would drive Consumer.ConsumeClaim.
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	mu     sync.Mutex
+	marked []int64
+}
+
+func (s *fakeSession) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.marked = append(s.marked, msg.Offset)
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.messages
+}
+
+// publish runs messages through a Publisher and returns them as the consumer
+// would read them from the topic.
+func publish(t *testing.T, messages ...event_store.Message) []*sarama.ConsumerMessage {
+	producer := mocks.NewSyncProducer(t, kafka.NewConfig())
+	defer producer.Close()
+
+	consumed := make([]*sarama.ConsumerMessage, 0, len(messages))
+	for range messages {
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(m *sarama.ProducerMessage) error {
+			key, _ := m.Key.Encode()
+			value, _ := m.Value.Encode()
+			consumerMessage := &sarama.ConsumerMessage{
+				Topic:  m.Topic,
+				Key:    key,
+				Value:  value,
+				Offset: int64(len(consumed)),
+			}
+			for _, h := range m.Headers {
+				consumerMessage.Headers = append(consumerMessage.Headers, &sarama.RecordHeader{Key: h.Key, Value: h.Value})
+			}
+			consumed = append(consumed, consumerMessage)
+			return nil
+		})
+	}
+
+	require.NoError(t, kafka.NewPublisher(producer, "brokeli").Publish(context.Background(), messages))
+	return consumed
+}
+
+func consume(t *testing.T, consumer *kafka.Consumer, messages []*sarama.ConsumerMessage) *fakeSession {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	session := &fakeSession{ctx: ctx}
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, len(messages))}
+	for _, m := range messages {
+		claim.messages <- m
+	}
+	close(claim.messages)
+
+	require.NoError(t, consumer.ConsumeClaim(session, claim))
+	return session
+}
+
+func TestConsumer_ConsumeClaim(t *testing.T) {
+	retryPolicy := event_store.WithRetryPolicy(event_store.RetryPolicy{
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	})
+
+	t.Run("should hand the published events to the subscribers", func(t *testing.T) {
+		// arrange
+		aggregateID := uuid.New()
+		metadata := event_store.Metadata{CorrelationID: uuid.New(), CausationID: uuid.New(), Actor: "marco", Source: event_store.SourceAPI}
+		messages := publish(t,
+			event_store.Message{ID: uuid.New(), AggregateID: aggregateID, AggregateType: "Transaction", Version: 1, Position: 7, EventType: "MoneySpent", Metadata: metadata, Data: []byte(`{"amount":"10"}`)},
+			event_store.Message{ID: uuid.New(), AggregateID: aggregateID, AggregateType: "Transaction", Version: 2, Position: 9, EventType: "MoneySpent", Data: []byte(`{"amount":"20"}`)},
+		)
+
+		consumer := kafka.NewConsumer(nil, kafka.Topic("brokeli", "Transaction"), eventFactory)
+		records := make([]event_store.Record, 0)
+		consumer.Subscribe(context.Background(), func(ctx context.Context, record event_store.Record) error {
+			records = append(records, record)
+			return nil
+		})
+
+		// act
+		session := consume(t, consumer, messages)
+
+		// assert
+		require.Len(t, records, 2)
+		assert.Equal(t, aggregateID, records[0].AggregateID)
+		assert.NotEqual(t, uuid.Nil, records[0].ID)
+		assert.Equal(t, metadata, records[0].Metadata)
+		assert.Equal(t, uint64(1), records[0].Version)
+		assert.Equal(t, uint64(7), records[0].Position)
+		assert.Equal(t, "MoneySpent", records[0].Type())
+		assert.Equal(t, spent{Amount: "10"}, records[0].Content())
+		assert.Equal(t, spent{Amount: "20"}, records[1].Content())
+		assert.Equal(t, []int64{0, 1}, session.marked)
+	})
+
+	t.Run("should retry a failing subscriber and move on when it keeps failing", func(t *testing.T) {
+		// arrange
+		messages := publish(t,
+			event_store.Message{ID: uuid.New(), AggregateID: uuid.New(), AggregateType: "Transaction", Version: 1, Position: 1, EventType: "MoneySpent", Data: []byte(`{"amount":"10"}`)},
+		)
+
+		consumer := kafka.NewConsumer(nil, kafka.Topic("brokeli", "Transaction"), eventFactory, retryPolicy)
+		attempts := 0
+		consumer.Subscribe(context.Background(), func(ctx context.Context, record event_store.Record) error {
+			attempts++
+			return errors.New("failure")
+		})
+
+		// act
+		session := consume(t, consumer, messages)
+
+		// assert
+		assert.Equal(t, 2, attempts)
+		assert.Equal(t, []int64{0}, session.marked)
+	})
+
+	t.Run("should upcast payloads published with an older schema version", func(t *testing.T) {
+		// arrange
+		messages := publish(t,
+			event_store.Message{ID: uuid.New(), AggregateID: uuid.New(), AggregateType: "Transaction", Version: 1, Position: 1, EventType: "MoneySpent", SchemaVersion: 1, Data: []byte(`{"value":"10"}`)},
+		)
+
+		upcasters := event_store.NewUpcasters().
+			Register("MoneySpent", 1, func(data json.RawMessage) (json.RawMessage, error) {
+				var v1 struct {
+					Value string `json:"value"`
+				}
+				if err := json.Unmarshal(data, &v1); err != nil {
+					return nil, err
+				}
+				return json.Marshal(spent{Amount: v1.Value})
+			})
+
+		consumer := kafka.NewConsumer(nil, kafka.Topic("brokeli", "Transaction"), eventFactory, event_store.WithUpcasters(upcasters))
+		records := make([]event_store.Record, 0)
+		consumer.Subscribe(context.Background(), func(ctx context.Context, record event_store.Record) error {
+			records = append(records, record)
+			return nil
+		})
+
+		// act
+		consume(t, consumer, messages)
+
+		// assert
+		require.Len(t, records, 1)
+		assert.Equal(t, spent{Amount: "10"}, records[0].Content())
+	})
+
+	t.Run("should skip messages it cannot decode", func(t *testing.T) {
+		// arrange
+		messages := publish(t,
+			event_store.Message{ID: uuid.New(), AggregateID: uuid.New(), AggregateType: "Transaction", Version: 1, Position: 1, EventType: "Unknown", Data: []byte(`{}`)},
+		)
+
+		consumer := kafka.NewConsumer(nil, kafka.Topic("brokeli", "Transaction"), eventFactory)
+		delivered := 0
+		consumer.Subscribe(context.Background(), func(ctx context.Context, record event_store.Record) error {
+			delivered++
+			return nil
+		})
+
+		// act
+		session := consume(t, consumer, messages)
+
+		// assert
+		assert.Zero(t, delivered)
+		assert.Equal(t, []int64{0}, session.marked)
+	})
+}
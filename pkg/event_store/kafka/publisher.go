@@ -0,0 +1,104 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+const (
+	HeaderEventID       = "event_id"
+	HeaderEventType     = "event_type"
+	HeaderAggregateType = "aggregate_type"
+	HeaderVersion       = "version"
+	HeaderPosition      = "position"
+	HeaderSchemaVersion = "schema_version"
+	HeaderCorrelationID = "correlation_id"
+	HeaderCausationID   = "causation_id"
+	HeaderActor         = "actor"
+	HeaderSource        = "source"
+)
+
+// Topic returns the topic the events of an aggregate type are published to.
+func Topic(prefix, aggregateType string) string {
+	topic := strings.ToLower(aggregateType)
+	if prefix == "" {
+		return topic
+	}
+	return prefix + "." + topic
+}
+
+// NewConfig returns a producer and consumer configuration that keeps the
+// order of the messages of each aggregate, even when sends are retried.
+func NewConfig() *sarama.Config {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Idempotent = true
+	config.Net.MaxOpenRequests = 1
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	return config
+}
+
+// Publisher writes outbox messages to a topic per aggregate type, keyed by
+// aggregate ID so that the events of an aggregate land on the same partition.
+type Publisher struct {
+	producer    sarama.SyncProducer
+	topicPrefix string
+}
+
+var _ event_store.Publisher = &Publisher{}
+
+func NewPublisher(producer sarama.SyncProducer, topicPrefix string) *Publisher {
+	return &Publisher{
+		producer:    producer,
+		topicPrefix: topicPrefix,
+	}
+}
+
+func (p *Publisher) Publish(ctx context.Context, messages []event_store.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	producerMessages := make([]*sarama.ProducerMessage, 0, len(messages))
+	for _, m := range messages {
+		producerMessages = append(producerMessages, &sarama.ProducerMessage{
+			Topic: Topic(p.topicPrefix, m.AggregateType),
+			Key:   sarama.StringEncoder(m.AggregateID.String()),
+			Value: sarama.ByteEncoder(m.Data),
+			Headers: []sarama.RecordHeader{
+				{Key: []byte(HeaderEventID), Value: []byte(m.ID.String())},
+				{Key: []byte(HeaderEventType), Value: []byte(m.EventType)},
+				{Key: []byte(HeaderAggregateType), Value: []byte(m.AggregateType)},
+				{Key: []byte(HeaderVersion), Value: []byte(strconv.FormatUint(m.Version, 10))},
+				{Key: []byte(HeaderPosition), Value: []byte(strconv.FormatUint(m.Position, 10))},
+				{Key: []byte(HeaderSchemaVersion), Value: []byte(strconv.Itoa(m.SchemaVersion))},
+				{Key: []byte(HeaderCorrelationID), Value: []byte(m.Metadata.CorrelationID.String())},
+				{Key: []byte(HeaderCausationID), Value: []byte(m.Metadata.CausationID.String())},
+				{Key: []byte(HeaderActor), Value: []byte(m.Metadata.Actor)},
+				{Key: []byte(HeaderSource), Value: []byte(m.Metadata.Source)},
+			},
+			Timestamp: m.CreatedAt,
+		})
+	}
+
+	if err := p.producer.SendMessages(producerMessages); err != nil {
+		var producerErrors sarama.ProducerErrors
+		if errors.As(err, &producerErrors) && len(producerErrors) > 0 {
+			return fmt.Errorf("failed to publish %d of %d messages: %w", len(producerErrors), len(messages), producerErrors[0].Err)
+		}
+		return fmt.Errorf("failed to publish messages: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Publisher) Close() error {
+	return p.producer.Close()
+}
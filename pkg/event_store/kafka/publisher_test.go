@@ -0,0 +1,170 @@
+package kafka_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/pkg/event_store"
+	"github.com/somatom98/brokeli/pkg/event_store/kafka"
+)
+
+func headers(m *sarama.ProducerMessage) map[string]string {
+	result := make(map[string]string, len(m.Headers))
+	for _, h := range m.Headers {
+		result[string(h.Key)] = string(h.Value)
+	}
+	return result
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should publish each message to the topic of its aggregate type, keyed by aggregate", func(t *testing.T) {
+		// arrange
+		producer := mocks.NewSyncProducer(t, kafka.NewConfig())
+		defer producer.Close()
+
+		sent := make([]*sarama.ProducerMessage, 0)
+		capture := func(m *sarama.ProducerMessage) error {
+			sent = append(sent, m)
+			return nil
+		}
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(capture)
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(capture)
+
+		publisher := kafka.NewPublisher(producer, "brokeli")
+		message := event_store.Message{
+			ID:            uuid.New(),
+			AggregateID:   uuid.New(),
+			AggregateType: "Transaction",
+			Version:       3,
+			Position:      42,
+			EventType:     "MoneySpent",
+			SchemaVersion: 2,
+			Metadata: event_store.Metadata{
+				CorrelationID: uuid.New(),
+				CausationID:   uuid.New(),
+				Actor:         "marco",
+				Source:        event_store.SourceAPI,
+			},
+			Data:      []byte(`{"amount":"10"}`),
+			CreatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		}
+		account := event_store.Message{
+			ID:            uuid.New(),
+			AggregateID:   uuid.New(),
+			AggregateType: "Account",
+			Version:       1,
+			Position:      43,
+			EventType:     "AccountOpened",
+			Data:          []byte(`{}`),
+		}
+
+		// act
+		err := publisher.Publish(ctx, []event_store.Message{message, account})
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, sent, 2)
+		assert.Equal(t, "brokeli.transaction", sent[0].Topic)
+		assert.Equal(t, "brokeli.account", sent[1].Topic)
+
+		key, err := sent[0].Key.Encode()
+		require.NoError(t, err)
+		assert.Equal(t, message.AggregateID.String(), string(key))
+
+		value, err := sent[0].Value.Encode()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"amount":"10"}`, string(value))
+
+		assert.Equal(t, map[string]string{
+			kafka.HeaderEventID:       message.ID.String(),
+			kafka.HeaderEventType:     "MoneySpent",
+			kafka.HeaderAggregateType: "Transaction",
+			kafka.HeaderVersion:       "3",
+			kafka.HeaderPosition:      "42",
+			kafka.HeaderSchemaVersion: "2",
+			kafka.HeaderCorrelationID: message.Metadata.CorrelationID.String(),
+			kafka.HeaderCausationID:   message.Metadata.CausationID.String(),
+			kafka.HeaderActor:         "marco",
+			kafka.HeaderSource:        "api",
+		}, headers(sent[0]))
+		assert.Equal(t, message.CreatedAt, sent[0].Timestamp)
+	})
+
+	t.Run("should return an error when the broker rejects the messages", func(t *testing.T) {
+		// arrange
+		producer := mocks.NewSyncProducer(t, kafka.NewConfig())
+		defer producer.Close()
+		producer.ExpectSendMessageAndFail(sarama.ErrNotEnoughReplicas)
+
+		publisher := kafka.NewPublisher(producer, "brokeli")
+
+		// act
+		err := publisher.Publish(ctx, []event_store.Message{{
+			ID:            uuid.New(),
+			AggregateID:   uuid.New(),
+			AggregateType: "Transaction",
+			EventType:     "MoneySpent",
+			Data:          []byte(`{}`),
+		}})
+
+		// assert
+		assert.ErrorIs(t, err, sarama.ErrNotEnoughReplicas)
+	})
+}
+
+func TestTopic(t *testing.T) {
+	assert.Equal(t, "brokeli.transaction", kafka.Topic("brokeli", "Transaction"))
+	assert.Equal(t, "account", kafka.Topic("", "Account"))
+}
+
+func TestPublisher_MockBroker(t *testing.T) {
+	// arrange
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	topic := kafka.Topic("brokeli", "Transaction")
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader(topic, 0, broker.BrokerID()),
+		"ApiVersionsRequest":    sarama.NewMockApiVersionsResponse(t),
+		"InitProducerIDRequest": sarama.NewMockInitProducerIDResponse(t),
+		"ProduceRequest":        sarama.NewMockProduceResponse(t),
+	})
+
+	producer, err := sarama.NewSyncProducer([]string{broker.Addr()}, kafka.NewConfig())
+	require.NoError(t, err)
+	defer producer.Close()
+
+	publisher := kafka.NewPublisher(producer, "brokeli")
+
+	// act
+	err = publisher.Publish(context.Background(), []event_store.Message{{
+		ID:            uuid.New(),
+		AggregateID:   uuid.New(),
+		AggregateType: "Transaction",
+		Version:       1,
+		Position:      1,
+		EventType:     "MoneySpent",
+		Data:          []byte(`{}`),
+	}})
+
+	// assert
+	require.NoError(t, err)
+	produced := 0
+	for _, r := range broker.History() {
+		if _, ok := r.Request.(*sarama.ProduceRequest); ok {
+			produced++
+		}
+	}
+	assert.Equal(t, 1, produced)
+}
@@ -0,0 +1,73 @@
+package event_store
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Sources an event can be recorded from.
+const (
+	SourceAPI    = "api"
+	SourceImport = "import"
+	SourceSystem = "system"
+)
+
+// Metadata records why and on whose behalf an event was appended. Every event
+// appended while handling the same request shares its CorrelationID, and
+// events appended in reaction to another one have its ID as CausationID.
+type Metadata struct {
+	CorrelationID uuid.UUID
+	CausationID   uuid.UUID
+	Actor         string
+	Source        string
+}
+
+type metadataKey struct{}
+
+// WithMetadata returns a context whose appends are recorded with the given
+// metadata.
+func WithMetadata(ctx context.Context, metadata Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// MetadataFrom returns the metadata carried by ctx, if any.
+func MetadataFrom(ctx context.Context) Metadata {
+	metadata, _ := ctx.Value(metadataKey{}).(Metadata)
+	return metadata
+}
+
+// WithSource returns a context whose appends are recorded from the given
+// source, keeping the rest of the metadata carried by ctx.
+func WithSource(ctx context.Context, source string) context.Context {
+	metadata := MetadataFrom(ctx)
+	metadata.Source = source
+	return WithMetadata(ctx, metadata)
+}
+
+// CausedBy returns a context whose appends are recorded as a reaction of the
+// system to record, on behalf of the same actor and within its correlation.
+func CausedBy(ctx context.Context, record Record) context.Context {
+	return WithMetadata(ctx, Metadata{
+		CorrelationID: record.Metadata.CorrelationID,
+		CausationID:   record.ID,
+		Actor:         record.Metadata.Actor,
+		Source:        SourceSystem,
+	})
+}
+
+// Stamp assigns an ID to a record about to be appended and, unless it already
+// has some, the metadata carried by ctx. A record appended outside of any
+// correlation starts its own.
+func Stamp(ctx context.Context, record Record) Record {
+	if record.ID == uuid.Nil {
+		record.ID = uuid.New()
+	}
+	if record.Metadata == (Metadata{}) {
+		record.Metadata = MetadataFrom(ctx)
+	}
+	if record.Metadata.CorrelationID == uuid.Nil {
+		record.Metadata.CorrelationID = record.ID
+	}
+	return record
+}
@@ -0,0 +1,97 @@
+package event_store
+
+import "time"
+
+// SnapshotPolicy reports whether a snapshot should be taken after an aggregate
+// moved from version `from` to version `to`.
+type SnapshotPolicy func(from, to uint64) bool
+
+// EveryNEvents takes a snapshot each time the aggregate crosses a multiple of n events.
+func EveryNEvents(n uint64) SnapshotPolicy {
+	return func(from, to uint64) bool {
+		if n == 0 {
+			return false
+		}
+		return from/n != to/n
+	}
+}
+
+// OnDemand never takes snapshots automatically; they are only written by TakeSnapshot.
+func OnDemand() SnapshotPolicy {
+	return func(from, to uint64) bool {
+		return false
+	}
+}
+
+// RetryPolicy controls how many times a named subscription calls its handler
+// for the same record before dead-lettering it, and how long it waits between
+// attempts.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Backoff returns the delay before the given retry, doubling from
+// InitialBackoff up to MaxBackoff.
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	for range retry - 1 {
+		backoff *= 2
+		if backoff >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return min(backoff, p.MaxBackoff)
+}
+
+type Options struct {
+	SnapshotPolicy SnapshotPolicy
+	RetryPolicy    RetryPolicy
+	// Publisher, when set, receives the outbox of the stores that have one.
+	Publisher Publisher
+	// Upcasters migrate stored payloads written with an older schema version.
+	Upcasters *Upcasters
+}
+
+type Option func(*Options)
+
+func WithSnapshotPolicy(policy SnapshotPolicy) Option {
+	return func(o *Options) {
+		o.SnapshotPolicy = policy
+	}
+}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *Options) {
+		o.RetryPolicy = policy
+	}
+}
+
+func WithPublisher(publisher Publisher) Option {
+	return func(o *Options) {
+		o.Publisher = publisher
+	}
+}
+
+func WithUpcasters(upcasters *Upcasters) Option {
+	return func(o *Options) {
+		o.Upcasters = upcasters
+	}
+}
+
+func NewOptions(opts ...Option) Options {
+	o := Options{
+		SnapshotPolicy: OnDemand(),
+		RetryPolicy: RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     5 * time.Second,
+		},
+		Upcasters: NewUpcasters(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,37 @@
+package event_store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	t.Run("should double the backoff up to the maximum", func(t *testing.T) {
+		// arrange
+		policy := event_store.RetryPolicy{
+			MaxAttempts:    10,
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     time.Second,
+		}
+
+		// act
+		backoffs := make([]time.Duration, 0)
+		for retry := 1; retry <= 6; retry++ {
+			backoffs = append(backoffs, policy.Backoff(retry))
+		}
+
+		// assert
+		assert.Equal(t, []time.Duration{
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+			time.Second,
+			time.Second,
+		}, backoffs)
+	})
+}
@@ -7,14 +7,16 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 
 	"github.com/google/uuid"
 )
 
-const appendEvent = `-- name: AppendEvent :exec
-INSERT INTO events (id, aggregate_id, aggregate_type, version, event_type, event_data)
-VALUES ($1, $2, $3, $4, $5, $6)
+const appendEvent = `-- name: AppendEvent :one
+INSERT INTO events (id, aggregate_id, aggregate_type, version, event_type, event_data, schema_version, correlation_id, causation_id, actor, source)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING position, created_at
 `
 
 type AppendEventParams struct {
@@ -24,23 +26,40 @@ type AppendEventParams struct {
 	Version       int64           `json:"version"`
 	EventType     string          `json:"event_type"`
 	EventData     json.RawMessage `json:"event_data"`
+	SchemaVersion int32           `json:"schema_version"`
+	CorrelationID uuid.NullUUID   `json:"correlation_id"`
+	CausationID   uuid.NullUUID   `json:"causation_id"`
+	Actor         string          `json:"actor"`
+	Source        string          `json:"source"`
 }
 
-func (q *Queries) AppendEvent(ctx context.Context, arg AppendEventParams) error {
-	_, err := q.db.ExecContext(ctx, appendEvent,
+type AppendEventRow struct {
+	Position  int64        `json:"position"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+func (q *Queries) AppendEvent(ctx context.Context, arg AppendEventParams) (AppendEventRow, error) {
+	row := q.db.QueryRowContext(ctx, appendEvent,
 		arg.ID,
 		arg.AggregateID,
 		arg.AggregateType,
 		arg.Version,
 		arg.EventType,
 		arg.EventData,
+		arg.SchemaVersion,
+		arg.CorrelationID,
+		arg.CausationID,
+		arg.Actor,
+		arg.Source,
 	)
-	return err
+	var i AppendEventRow
+	err := row.Scan(&i.Position, &i.CreatedAt)
+	return i, err
 }
 
 const appendToOutbox = `-- name: AppendToOutbox :exec
-INSERT INTO outbox_events (id, aggregate_id, aggregate_type, version, event_type, event_data)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO outbox_events (id, aggregate_id, aggregate_type, version, event_type, event_data, position, schema_version, correlation_id, causation_id, actor, source, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 `
 
 type AppendToOutboxParams struct {
@@ -50,6 +69,13 @@ type AppendToOutboxParams struct {
 	Version       int64           `json:"version"`
 	EventType     string          `json:"event_type"`
 	EventData     json.RawMessage `json:"event_data"`
+	Position      int64           `json:"position"`
+	SchemaVersion int32           `json:"schema_version"`
+	CorrelationID uuid.NullUUID   `json:"correlation_id"`
+	CausationID   uuid.NullUUID   `json:"causation_id"`
+	Actor         string          `json:"actor"`
+	Source        string          `json:"source"`
+	CreatedAt     sql.NullTime    `json:"created_at"`
 }
 
 func (q *Queries) AppendToOutbox(ctx context.Context, arg AppendToOutboxParams) error {
@@ -60,6 +86,13 @@ func (q *Queries) AppendToOutbox(ctx context.Context, arg AppendToOutboxParams)
 		arg.Version,
 		arg.EventType,
 		arg.EventData,
+		arg.Position,
+		arg.SchemaVersion,
+		arg.CorrelationID,
+		arg.CausationID,
+		arg.Actor,
+		arg.Source,
+		arg.CreatedAt,
 	)
 	return err
 }
@@ -110,14 +143,245 @@ func (q *Queries) GetEvents(ctx context.Context, aggregateID uuid.UUID) ([]GetEv
 	return items, nil
 }
 
+const getEventsAfterPosition = `-- name: GetEventsAfterPosition :many
+SELECT id, aggregate_id, version, event_type, event_data, position, schema_version, correlation_id, causation_id, actor, source, created_at
+FROM events
+WHERE aggregate_type = $1 AND position > $2 AND position <= $3
+ORDER BY position ASC
+LIMIT $4
+`
+
+type GetEventsAfterPositionParams struct {
+	AggregateType string `json:"aggregate_type"`
+	Position      int64  `json:"position"`
+	Through       int64  `json:"through"`
+	Limit         int32  `json:"limit"`
+}
+
+type GetEventsAfterPositionRow struct {
+	ID            uuid.UUID       `json:"id"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	Version       int64           `json:"version"`
+	EventType     string          `json:"event_type"`
+	EventData     json.RawMessage `json:"event_data"`
+	Position      int64           `json:"position"`
+	SchemaVersion int32           `json:"schema_version"`
+	CorrelationID uuid.NullUUID   `json:"correlation_id"`
+	CausationID   uuid.NullUUID   `json:"causation_id"`
+	Actor         string          `json:"actor"`
+	Source        string          `json:"source"`
+	CreatedAt     sql.NullTime    `json:"created_at"`
+}
+
+func (q *Queries) GetEventsAfterPosition(ctx context.Context, arg GetEventsAfterPositionParams) ([]GetEventsAfterPositionRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEventsAfterPosition,
+		arg.AggregateType,
+		arg.Position,
+		arg.Through,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEventsAfterPositionRow
+	for rows.Next() {
+		var i GetEventsAfterPositionRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AggregateID,
+			&i.Version,
+			&i.EventType,
+			&i.EventData,
+			&i.Position,
+			&i.SchemaVersion,
+			&i.CorrelationID,
+			&i.CausationID,
+			&i.Actor,
+			&i.Source,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEventsAfterVersion = `-- name: GetEventsAfterVersion :many
+SELECT id, version, event_type, event_data, position, schema_version, correlation_id, causation_id, actor, source, created_at
+FROM events
+WHERE aggregate_id = $1 AND version > $2
+ORDER BY version ASC
+`
+
+type GetEventsAfterVersionParams struct {
+	AggregateID uuid.UUID `json:"aggregate_id"`
+	Version     int64     `json:"version"`
+}
+
+type GetEventsAfterVersionRow struct {
+	ID            uuid.UUID       `json:"id"`
+	Version       int64           `json:"version"`
+	EventType     string          `json:"event_type"`
+	EventData     json.RawMessage `json:"event_data"`
+	Position      int64           `json:"position"`
+	SchemaVersion int32           `json:"schema_version"`
+	CorrelationID uuid.NullUUID   `json:"correlation_id"`
+	CausationID   uuid.NullUUID   `json:"causation_id"`
+	Actor         string          `json:"actor"`
+	Source        string          `json:"source"`
+	CreatedAt     sql.NullTime    `json:"created_at"`
+}
+
+func (q *Queries) GetEventsAfterVersion(ctx context.Context, arg GetEventsAfterVersionParams) ([]GetEventsAfterVersionRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEventsAfterVersion, arg.AggregateID, arg.Version)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEventsAfterVersionRow
+	for rows.Next() {
+		var i GetEventsAfterVersionRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Version,
+			&i.EventType,
+			&i.EventData,
+			&i.Position,
+			&i.SchemaVersion,
+			&i.CorrelationID,
+			&i.CausationID,
+			&i.Actor,
+			&i.Source,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEventsByCorrelation = `-- name: GetEventsByCorrelation :many
+SELECT id, aggregate_id, version, event_type, event_data, position, schema_version, correlation_id, causation_id, actor, source, created_at
+FROM events
+WHERE aggregate_type = $1 AND correlation_id = $2
+ORDER BY position ASC
+`
+
+type GetEventsByCorrelationParams struct {
+	AggregateType string        `json:"aggregate_type"`
+	CorrelationID uuid.NullUUID `json:"correlation_id"`
+}
+
+type GetEventsByCorrelationRow struct {
+	ID            uuid.UUID       `json:"id"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	Version       int64           `json:"version"`
+	EventType     string          `json:"event_type"`
+	EventData     json.RawMessage `json:"event_data"`
+	Position      int64           `json:"position"`
+	SchemaVersion int32           `json:"schema_version"`
+	CorrelationID uuid.NullUUID   `json:"correlation_id"`
+	CausationID   uuid.NullUUID   `json:"causation_id"`
+	Actor         string          `json:"actor"`
+	Source        string          `json:"source"`
+	CreatedAt     sql.NullTime    `json:"created_at"`
+}
+
+func (q *Queries) GetEventsByCorrelation(ctx context.Context, arg GetEventsByCorrelationParams) ([]GetEventsByCorrelationRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEventsByCorrelation, arg.AggregateType, arg.CorrelationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEventsByCorrelationRow
+	for rows.Next() {
+		var i GetEventsByCorrelationRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AggregateID,
+			&i.Version,
+			&i.EventType,
+			&i.EventData,
+			&i.Position,
+			&i.SchemaVersion,
+			&i.CorrelationID,
+			&i.CausationID,
+			&i.Actor,
+			&i.Source,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getHighWaterMark = `-- name: GetHighWaterMark :one
+SELECT COALESCE(MAX(position), 0)::bigint
+FROM events
+`
+
+func (q *Queries) GetHighWaterMark(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getHighWaterMark)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const getLastPosition = `-- name: GetLastPosition :one
+SELECT COALESCE(MAX(position), 0)::bigint
+FROM events
+WHERE aggregate_type = $1 AND position <= $2
+`
+
+type GetLastPositionParams struct {
+	AggregateType string `json:"aggregate_type"`
+	Through       int64  `json:"through"`
+}
+
+func (q *Queries) GetLastPosition(ctx context.Context, arg GetLastPositionParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getLastPosition, arg.AggregateType, arg.Through)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const getOutboxEvents = `-- name: GetOutboxEvents :many
-SELECT id, aggregate_id, aggregate_type, version, event_type, event_data, created_at FROM outbox_events
-ORDER BY created_at ASC
-LIMIT $1
+SELECT id, aggregate_id, aggregate_type, version, event_type, event_data, created_at, position, schema_version, correlation_id, causation_id, actor, source FROM outbox_events
+WHERE aggregate_type = $1
+ORDER BY position ASC, created_at ASC
+LIMIT $2
 `
 
-func (q *Queries) GetOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error) {
-	rows, err := q.db.QueryContext(ctx, getOutboxEvents, limit)
+type GetOutboxEventsParams struct {
+	AggregateType string `json:"aggregate_type"`
+	Limit         int32  `json:"limit"`
+}
+
+func (q *Queries) GetOutboxEvents(ctx context.Context, arg GetOutboxEventsParams) ([]OutboxEvent, error) {
+	rows, err := q.db.QueryContext(ctx, getOutboxEvents, arg.AggregateType, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -133,6 +397,12 @@ func (q *Queries) GetOutboxEvents(ctx context.Context, limit int32) ([]OutboxEve
 			&i.EventType,
 			&i.EventData,
 			&i.CreatedAt,
+			&i.Position,
+			&i.SchemaVersion,
+			&i.CorrelationID,
+			&i.CausationID,
+			&i.Actor,
+			&i.Source,
 		); err != nil {
 			return nil, err
 		}
@@ -146,3 +416,29 @@ func (q *Queries) GetOutboxEvents(ctx context.Context, limit int32) ([]OutboxEve
 	}
 	return items, nil
 }
+
+const lockEventsAppend = `-- name: LockEventsAppend :exec
+SELECT pg_advisory_xact_lock_shared(0, 0)
+`
+
+// Positions are taken from a sequence when an event is inserted, so they can
+// become visible out of order: an append can commit before another one still
+// in progress with a lower position. Appends hold this lock shared until they
+// commit, which lets them run concurrently, while readers needing the events
+// without gaps take it exclusively, only for the time of reading the high
+// water mark. The two-key form keeps it apart from the per-aggregate locks.
+func (q *Queries) LockEventsAppend(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, lockEventsAppend)
+	return err
+}
+
+const lockEventsRead = `-- name: LockEventsRead :exec
+SELECT pg_advisory_xact_lock(0, 0)
+`
+
+// Waits for the appends in progress and blocks new ones until the
+// transaction ends.
+func (q *Queries) LockEventsRead(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, lockEventsRead)
+	return err
+}
@@ -0,0 +1,8 @@
+CREATE TABLE snapshots (
+    aggregate_id UUID NOT NULL,
+    aggregate_type VARCHAR(255) NOT NULL,
+    version BIGINT NOT NULL,
+    data BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (aggregate_type, aggregate_id)
+);
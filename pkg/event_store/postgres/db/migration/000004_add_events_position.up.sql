@@ -0,0 +1,29 @@
+CREATE SEQUENCE events_position_seq;
+
+ALTER TABLE events ADD COLUMN position BIGINT;
+
+UPDATE events
+SET position = ordered.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (ORDER BY created_at, aggregate_id, version) AS position
+    FROM events
+) AS ordered
+WHERE events.id = ordered.id;
+
+SELECT setval('events_position_seq', COALESCE((SELECT MAX(position) FROM events), 0) + 1, false);
+
+ALTER TABLE events ALTER COLUMN position SET DEFAULT nextval('events_position_seq');
+ALTER TABLE events ALTER COLUMN position SET NOT NULL;
+ALTER SEQUENCE events_position_seq OWNED BY events.position;
+
+CREATE UNIQUE INDEX idx_events_position ON events (position);
+CREATE INDEX idx_events_aggregate_type_position ON events (aggregate_type, position);
+
+ALTER TABLE outbox_events ADD COLUMN position BIGINT NOT NULL DEFAULT 0;
+
+-- Events still pending delivery share their id with the event they were
+-- appended as.
+UPDATE outbox_events
+SET position = events.position
+FROM events
+WHERE outbox_events.id = events.id;
@@ -0,0 +1,21 @@
+CREATE TABLE subscription_checkpoints (
+    name VARCHAR(255) NOT NULL,
+    aggregate_type VARCHAR(255) NOT NULL,
+    position BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (name, aggregate_type)
+);
+
+CREATE TABLE dead_letter_events (
+    subscription VARCHAR(255) NOT NULL,
+    position BIGINT NOT NULL,
+    aggregate_id UUID NOT NULL,
+    aggregate_type VARCHAR(255) NOT NULL,
+    version BIGINT NOT NULL,
+    event_type VARCHAR(255) NOT NULL,
+    event_data JSONB NOT NULL,
+    attempts INT NOT NULL,
+    error TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (subscription, position)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE events ADD COLUMN schema_version INT NOT NULL DEFAULT 1;
+ALTER TABLE outbox_events ADD COLUMN schema_version INT NOT NULL DEFAULT 1;
+ALTER TABLE dead_letter_events ADD COLUMN schema_version INT NOT NULL DEFAULT 1;
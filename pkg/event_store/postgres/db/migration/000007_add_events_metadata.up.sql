@@ -0,0 +1,12 @@
+ALTER TABLE events ADD COLUMN correlation_id UUID;
+ALTER TABLE events ADD COLUMN causation_id UUID;
+ALTER TABLE events ADD COLUMN actor TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN source TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_events_correlation_id ON events (correlation_id);
+CREATE INDEX idx_events_causation_id ON events (causation_id);
+
+ALTER TABLE outbox_events ADD COLUMN correlation_id UUID;
+ALTER TABLE outbox_events ADD COLUMN causation_id UUID;
+ALTER TABLE outbox_events ADD COLUMN actor TEXT NOT NULL DEFAULT '';
+ALTER TABLE outbox_events ADD COLUMN source TEXT NOT NULL DEFAULT '';
@@ -11,6 +11,20 @@ import (
 	"github.com/google/uuid"
 )
 
+type DeadLetterEvent struct {
+	Subscription  string          `json:"subscription"`
+	Position      int64           `json:"position"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	AggregateType string          `json:"aggregate_type"`
+	Version       int64           `json:"version"`
+	EventType     string          `json:"event_type"`
+	EventData     json.RawMessage `json:"event_data"`
+	Attempts      int32           `json:"attempts"`
+	Error         string          `json:"error"`
+	CreatedAt     sql.NullTime    `json:"created_at"`
+	SchemaVersion int32           `json:"schema_version"`
+}
+
 type Event struct {
 	ID            uuid.UUID       `json:"id"`
 	AggregateID   uuid.UUID       `json:"aggregate_id"`
@@ -19,6 +33,12 @@ type Event struct {
 	EventType     string          `json:"event_type"`
 	EventData     json.RawMessage `json:"event_data"`
 	CreatedAt     sql.NullTime    `json:"created_at"`
+	Position      int64           `json:"position"`
+	SchemaVersion int32           `json:"schema_version"`
+	CorrelationID uuid.NullUUID   `json:"correlation_id"`
+	CausationID   uuid.NullUUID   `json:"causation_id"`
+	Actor         string          `json:"actor"`
+	Source        string          `json:"source"`
 }
 
 type OutboxEvent struct {
@@ -29,4 +49,25 @@ type OutboxEvent struct {
 	EventType     string          `json:"event_type"`
 	EventData     json.RawMessage `json:"event_data"`
 	CreatedAt     sql.NullTime    `json:"created_at"`
+	Position      int64           `json:"position"`
+	SchemaVersion int32           `json:"schema_version"`
+	CorrelationID uuid.NullUUID   `json:"correlation_id"`
+	CausationID   uuid.NullUUID   `json:"causation_id"`
+	Actor         string          `json:"actor"`
+	Source        string          `json:"source"`
+}
+
+type Snapshot struct {
+	AggregateID   uuid.UUID    `json:"aggregate_id"`
+	AggregateType string       `json:"aggregate_type"`
+	Version       int64        `json:"version"`
+	Data          []byte       `json:"data"`
+	CreatedAt     sql.NullTime `json:"created_at"`
+}
+
+type SubscriptionCheckpoint struct {
+	Name          string       `json:"name"`
+	AggregateType string       `json:"aggregate_type"`
+	Position      int64        `json:"position"`
+	UpdatedAt     sql.NullTime `json:"updated_at"`
 }
@@ -11,11 +11,33 @@ import (
 )
 
 type Querier interface {
-	AppendEvent(ctx context.Context, arg AppendEventParams) error
+	AppendEvent(ctx context.Context, arg AppendEventParams) (AppendEventRow, error)
 	AppendToOutbox(ctx context.Context, arg AppendToOutboxParams) error
 	DeleteOutboxEvent(ctx context.Context, id uuid.UUID) error
+	GetCheckpoint(ctx context.Context, arg GetCheckpointParams) (int64, error)
 	GetEvents(ctx context.Context, aggregateID uuid.UUID) ([]GetEventsRow, error)
-	GetOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error)
+	GetEventsAfterPosition(ctx context.Context, arg GetEventsAfterPositionParams) ([]GetEventsAfterPositionRow, error)
+	GetEventsAfterVersion(ctx context.Context, arg GetEventsAfterVersionParams) ([]GetEventsAfterVersionRow, error)
+	GetEventsByCorrelation(ctx context.Context, arg GetEventsByCorrelationParams) ([]GetEventsByCorrelationRow, error)
+	GetHighWaterMark(ctx context.Context) (int64, error)
+	GetLastPosition(ctx context.Context, arg GetLastPositionParams) (int64, error)
+	GetLatestSnapshot(ctx context.Context, arg GetLatestSnapshotParams) (GetLatestSnapshotRow, error)
+	GetOutboxEvents(ctx context.Context, arg GetOutboxEventsParams) ([]OutboxEvent, error)
+	InsertDeadLetter(ctx context.Context, arg InsertDeadLetterParams) error
+	// Positions are taken from a sequence when an event is inserted, so they can
+	// become visible out of order: an append can commit before another one still
+	// in progress with a lower position. Appends hold this lock shared until they
+	// commit, which lets them run concurrently, while readers needing the events
+	// without gaps take it exclusively, only for the time of reading the high
+	// water mark. The two-key form keeps it apart from the per-aggregate locks.
+	LockEventsAppend(ctx context.Context) error
+	// Waits for the appends in progress and blocks new ones until the
+	// transaction ends.
+	LockEventsRead(ctx context.Context) error
+	LockSubscription(ctx context.Context, arg LockSubscriptionParams) error
+	SaveCheckpoint(ctx context.Context, arg SaveCheckpointParams) error
+	SaveSnapshot(ctx context.Context, arg SaveSnapshotParams) error
+	TryLockSubscription(ctx context.Context, arg TryLockSubscriptionParams) (bool, error)
 }
 
 var _ Querier = (*Queries)(nil)
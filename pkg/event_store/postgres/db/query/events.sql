@@ -1,15 +1,35 @@
--- name: AppendEvent :exec
-INSERT INTO events (id, aggregate_id, aggregate_type, version, event_type, event_data)
-VALUES ($1, $2, $3, $4, $5, $6);
+-- Positions are taken from a sequence when an event is inserted, so they can
+-- become visible out of order: an append can commit before another one still
+-- in progress with a lower position. Appends hold this lock shared until they
+-- commit, which lets them run concurrently, while readers needing the events
+-- without gaps take it exclusively, only for the time of reading the high
+-- water mark. The two-key form keeps it apart from the per-aggregate locks.
+-- name: LockEventsAppend :exec
+SELECT pg_advisory_xact_lock_shared(0, 0);
+
+-- Waits for the appends in progress and blocks new ones until the
+-- transaction ends.
+-- name: LockEventsRead :exec
+SELECT pg_advisory_xact_lock(0, 0);
+
+-- name: GetHighWaterMark :one
+SELECT COALESCE(MAX(position), 0)::bigint
+FROM events;
+
+-- name: AppendEvent :one
+INSERT INTO events (id, aggregate_id, aggregate_type, version, event_type, event_data, schema_version, correlation_id, causation_id, actor, source)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING position, created_at;
 
 -- name: AppendToOutbox :exec
-INSERT INTO outbox_events (id, aggregate_id, aggregate_type, version, event_type, event_data)
-VALUES ($1, $2, $3, $4, $5, $6);
+INSERT INTO outbox_events (id, aggregate_id, aggregate_type, version, event_type, event_data, position, schema_version, correlation_id, causation_id, actor, source, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
 
 -- name: GetOutboxEvents :many
 SELECT * FROM outbox_events
-ORDER BY created_at ASC
-LIMIT $1;
+WHERE aggregate_type = $1
+ORDER BY position ASC, created_at ASC
+LIMIT $2;
 
 -- name: DeleteOutboxEvent :exec
 DELETE FROM outbox_events
@@ -20,3 +40,27 @@ SELECT version, event_type, event_data
 FROM events
 WHERE aggregate_id = $1
 ORDER BY version ASC;
+
+-- name: GetEventsAfterVersion :many
+SELECT id, version, event_type, event_data, position, schema_version, correlation_id, causation_id, actor, source, created_at
+FROM events
+WHERE aggregate_id = $1 AND version > $2
+ORDER BY version ASC;
+
+-- name: GetEventsAfterPosition :many
+SELECT id, aggregate_id, version, event_type, event_data, position, schema_version, correlation_id, causation_id, actor, source, created_at
+FROM events
+WHERE aggregate_type = sqlc.arg(aggregate_type) AND position > sqlc.arg(position) AND position <= sqlc.arg(through)
+ORDER BY position ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetLastPosition :one
+SELECT COALESCE(MAX(position), 0)::bigint
+FROM events
+WHERE aggregate_type = sqlc.arg(aggregate_type) AND position <= sqlc.arg(through);
+
+-- name: GetEventsByCorrelation :many
+SELECT id, aggregate_id, version, event_type, event_data, position, schema_version, correlation_id, causation_id, actor, source, created_at
+FROM events
+WHERE aggregate_type = $1 AND correlation_id = $2
+ORDER BY position ASC;
@@ -0,0 +1,11 @@
+-- name: GetLatestSnapshot :one
+SELECT version, data
+FROM snapshots
+WHERE aggregate_type = $1 AND aggregate_id = $2;
+
+-- name: SaveSnapshot :exec
+INSERT INTO snapshots (aggregate_id, aggregate_type, version, data)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (aggregate_type, aggregate_id) DO UPDATE
+SET version = EXCLUDED.version, data = EXCLUDED.data, created_at = NOW()
+WHERE snapshots.version < EXCLUDED.version;
@@ -0,0 +1,21 @@
+-- name: TryLockSubscription :one
+SELECT pg_try_advisory_xact_lock(hashtext(sqlc.arg(name)::text), hashtext(sqlc.arg(aggregate_type)::text));
+
+-- name: LockSubscription :exec
+SELECT pg_advisory_xact_lock(hashtext(sqlc.arg(name)::text), hashtext(sqlc.arg(aggregate_type)::text));
+
+-- name: GetCheckpoint :one
+SELECT position
+FROM subscription_checkpoints
+WHERE name = $1 AND aggregate_type = $2;
+
+-- name: SaveCheckpoint :exec
+INSERT INTO subscription_checkpoints (name, aggregate_type, position)
+VALUES ($1, $2, $3)
+ON CONFLICT (name, aggregate_type) DO UPDATE
+SET position = EXCLUDED.position, updated_at = NOW();
+
+-- name: InsertDeadLetter :exec
+INSERT INTO dead_letter_events (subscription, position, aggregate_id, aggregate_type, version, event_type, event_data, schema_version, attempts, error)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (subscription, position) DO NOTHING;
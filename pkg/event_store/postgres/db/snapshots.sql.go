@@ -0,0 +1,60 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: snapshots.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const getLatestSnapshot = `-- name: GetLatestSnapshot :one
+SELECT version, data
+FROM snapshots
+WHERE aggregate_type = $1 AND aggregate_id = $2
+`
+
+type GetLatestSnapshotParams struct {
+	AggregateType string    `json:"aggregate_type"`
+	AggregateID   uuid.UUID `json:"aggregate_id"`
+}
+
+type GetLatestSnapshotRow struct {
+	Version int64  `json:"version"`
+	Data    []byte `json:"data"`
+}
+
+func (q *Queries) GetLatestSnapshot(ctx context.Context, arg GetLatestSnapshotParams) (GetLatestSnapshotRow, error) {
+	row := q.db.QueryRowContext(ctx, getLatestSnapshot, arg.AggregateType, arg.AggregateID)
+	var i GetLatestSnapshotRow
+	err := row.Scan(&i.Version, &i.Data)
+	return i, err
+}
+
+const saveSnapshot = `-- name: SaveSnapshot :exec
+INSERT INTO snapshots (aggregate_id, aggregate_type, version, data)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (aggregate_type, aggregate_id) DO UPDATE
+SET version = EXCLUDED.version, data = EXCLUDED.data, created_at = NOW()
+WHERE snapshots.version < EXCLUDED.version
+`
+
+type SaveSnapshotParams struct {
+	AggregateID   uuid.UUID `json:"aggregate_id"`
+	AggregateType string    `json:"aggregate_type"`
+	Version       int64     `json:"version"`
+	Data          []byte    `json:"data"`
+}
+
+func (q *Queries) SaveSnapshot(ctx context.Context, arg SaveSnapshotParams) error {
+	_, err := q.db.ExecContext(ctx, saveSnapshot,
+		arg.AggregateID,
+		arg.AggregateType,
+		arg.Version,
+		arg.Data,
+	)
+	return err
+}
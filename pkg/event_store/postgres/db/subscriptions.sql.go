@@ -0,0 +1,114 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: subscriptions.sql
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+const getCheckpoint = `-- name: GetCheckpoint :one
+SELECT position
+FROM subscription_checkpoints
+WHERE name = $1 AND aggregate_type = $2
+`
+
+type GetCheckpointParams struct {
+	Name          string `json:"name"`
+	AggregateType string `json:"aggregate_type"`
+}
+
+func (q *Queries) GetCheckpoint(ctx context.Context, arg GetCheckpointParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getCheckpoint, arg.Name, arg.AggregateType)
+	var position int64
+	err := row.Scan(&position)
+	return position, err
+}
+
+const insertDeadLetter = `-- name: InsertDeadLetter :exec
+INSERT INTO dead_letter_events (subscription, position, aggregate_id, aggregate_type, version, event_type, event_data, schema_version, attempts, error)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (subscription, position) DO NOTHING
+`
+
+type InsertDeadLetterParams struct {
+	Subscription  string          `json:"subscription"`
+	Position      int64           `json:"position"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	AggregateType string          `json:"aggregate_type"`
+	Version       int64           `json:"version"`
+	EventType     string          `json:"event_type"`
+	EventData     json.RawMessage `json:"event_data"`
+	SchemaVersion int32           `json:"schema_version"`
+	Attempts      int32           `json:"attempts"`
+	Error         string          `json:"error"`
+}
+
+func (q *Queries) InsertDeadLetter(ctx context.Context, arg InsertDeadLetterParams) error {
+	_, err := q.db.ExecContext(ctx, insertDeadLetter,
+		arg.Subscription,
+		arg.Position,
+		arg.AggregateID,
+		arg.AggregateType,
+		arg.Version,
+		arg.EventType,
+		arg.EventData,
+		arg.SchemaVersion,
+		arg.Attempts,
+		arg.Error,
+	)
+	return err
+}
+
+const lockSubscription = `-- name: LockSubscription :exec
+SELECT pg_advisory_xact_lock(hashtext($1::text), hashtext($2::text))
+`
+
+type LockSubscriptionParams struct {
+	Name          string `json:"name"`
+	AggregateType string `json:"aggregate_type"`
+}
+
+func (q *Queries) LockSubscription(ctx context.Context, arg LockSubscriptionParams) error {
+	_, err := q.db.ExecContext(ctx, lockSubscription, arg.Name, arg.AggregateType)
+	return err
+}
+
+const saveCheckpoint = `-- name: SaveCheckpoint :exec
+INSERT INTO subscription_checkpoints (name, aggregate_type, position)
+VALUES ($1, $2, $3)
+ON CONFLICT (name, aggregate_type) DO UPDATE
+SET position = EXCLUDED.position, updated_at = NOW()
+`
+
+type SaveCheckpointParams struct {
+	Name          string `json:"name"`
+	AggregateType string `json:"aggregate_type"`
+	Position      int64  `json:"position"`
+}
+
+func (q *Queries) SaveCheckpoint(ctx context.Context, arg SaveCheckpointParams) error {
+	_, err := q.db.ExecContext(ctx, saveCheckpoint, arg.Name, arg.AggregateType, arg.Position)
+	return err
+}
+
+const tryLockSubscription = `-- name: TryLockSubscription :one
+SELECT pg_try_advisory_xact_lock(hashtext($1::text), hashtext($2::text))
+`
+
+type TryLockSubscriptionParams struct {
+	Name          string `json:"name"`
+	AggregateType string `json:"aggregate_type"`
+}
+
+func (q *Queries) TryLockSubscription(ctx context.Context, arg TryLockSubscriptionParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, tryLockSubscription, arg.Name, arg.AggregateType)
+	var pg_try_advisory_xact_lock bool
+	err := row.Scan(&pg_try_advisory_xact_lock)
+	return pg_try_advisory_xact_lock, err
+}
@@ -5,18 +5,29 @@ import (
 	"database/sql"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/somatom98/brokeli/pkg/event_store"
 	"github.com/somatom98/brokeli/pkg/event_store/postgres/db"
 )
 
+const (
+	pollInterval          = 100 * time.Millisecond
+	subscriptionBatchSize = 100
+
+	// uniqueViolation is the SQLSTATE of unique constraint violations.
+	uniqueViolation = "23505"
+)
+
 type PostgresStore[A event_store.Aggregate] struct {
 	db            *sql.DB
 	queries       *db.Queries
@@ -24,7 +35,14 @@ type PostgresStore[A event_store.Aggregate] struct {
 	eventFactory  map[string]func() any
 	mu            sync.RWMutex
 	handlers      []event_store.SubscribeHandler
+	subscriptions []namedSubscription
 	aggregateType string
+	options       event_store.Options
+}
+
+type namedSubscription struct {
+	name    string
+	handler event_store.SubscribeHandler
 }
 
 var _ event_store.Store[event_store.Aggregate] = &PostgresStore[event_store.Aggregate]{}
@@ -33,20 +51,33 @@ func NewPostgresStore[A event_store.Aggregate](
 	dbConn *sql.DB,
 	new func(uuid.UUID) A,
 	eventFactory map[string]func() any,
+	opts ...event_store.Option,
 ) (*PostgresStore[A], error) {
 	store := &PostgresStore[A]{
-		db:           dbConn,
-		queries:      db.New(dbConn),
-		new:          new,
-		eventFactory: eventFactory,
-		handlers:     make([]event_store.SubscribeHandler, 0),
+		db:            dbConn,
+		queries:       db.New(dbConn),
+		new:           new,
+		eventFactory:  eventFactory,
+		handlers:      make([]event_store.SubscribeHandler, 0),
+		subscriptions: make([]namedSubscription, 0),
+		options:       event_store.NewOptions(opts...),
 	}
 	store.aggregateType = store.getAggregateType()
 	return store, nil
 }
 
+// RunRelay delivers the outbox to the plain subscribers and runs a worker for
+// each named subscription registered so far, until ctx is cancelled.
 func (s *PostgresStore[A]) RunRelay(ctx context.Context) error {
-	ticker := time.NewTicker(100 * time.Millisecond)
+	s.mu.RLock()
+	subscriptions := slices.Clone(s.subscriptions)
+	s.mu.RUnlock()
+
+	for _, sub := range subscriptions {
+		go s.runSubscription(ctx, sub)
+	}
+
+	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
 
 	for {
@@ -66,7 +97,173 @@ func (s *PostgresStore[A]) Subscribe(ctx context.Context, handler event_store.Su
 	s.handlers = append(s.handlers, handler)
 }
 
-func (s *PostgresStore[A]) Execute(ctx context.Context, id uuid.UUID, fn func(aggr A, version uint64) (event_store.Event, error)) error {
+// SubscribeNamed registers a durable subscription, whose checkpoint is stored
+// in subscription_checkpoints under the name and the aggregate type of this
+// store. Its worker is started by RunRelay.
+func (s *PostgresStore[A]) SubscribeNamed(ctx context.Context, name string, handler event_store.SubscribeHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := slices.IndexFunc(s.subscriptions, func(sub namedSubscription) bool {
+		return sub.name == name
+	})
+	if idx >= 0 {
+		s.subscriptions[idx].handler = handler
+		return
+	}
+
+	s.subscriptions = append(s.subscriptions, namedSubscription{name: name, handler: handler})
+}
+
+func (s *PostgresStore[A]) runSubscription(ctx context.Context, sub namedSubscription) {
+	for {
+		n, err := s.handleSubscription(ctx, sub)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("subscription %s on %s failed: %v", sub.name, s.aggregateType, err)
+		}
+
+		if err == nil && n == subscriptionBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// handleSubscription delivers the next batch of events to a named
+// subscription. The batch runs in a transaction holding an advisory lock on
+// the subscription, so that several instances of the application never
+// deliver the same events concurrently, while each event is applied in a
+// transaction of its own which also moves the checkpoint past it.
+func (s *PostgresStore[A]) handleSubscription(ctx context.Context, sub namedSubscription) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	locked, err := qtx.TryLockSubscription(ctx, db.TryLockSubscriptionParams{
+		Name:          sub.name,
+		AggregateType: s.aggregateType,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire subscription lock: %w", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	position, err := qtx.GetCheckpoint(ctx, db.GetCheckpointParams{
+		Name:          sub.name,
+		AggregateType: s.aggregateType,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to get checkpoint: %w", err)
+	}
+
+	through, err := s.highWaterMark(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := qtx.GetEventsAfterPosition(ctx, db.GetEventsAfterPositionParams{
+		AggregateType: s.aggregateType,
+		Position:      position,
+		Through:       through,
+		Limit:         subscriptionBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to query events: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	for _, row := range rows {
+		if err := s.deliver(ctx, sub, row); err != nil {
+			return 0, err
+		}
+	}
+
+	// Releases the subscription lock.
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(rows), nil
+}
+
+// deliver hands a single event to the subscription handler, dead-lettering it
+// when it cannot be decoded or the handler keeps failing. Only a cancelled
+// context stops the batch. Each attempt runs in the transaction of the
+// checkpoint, which the handler context carries: the writes made through it
+// are applied once, even when the event is delivered again after a crash.
+func (s *PostgresStore[A]) deliver(ctx context.Context, sub namedSubscription, row db.GetEventsAfterPositionRow) error {
+	e, err := s.decodeEvent(row.EventType, row.SchemaVersion, row.EventData)
+	if err != nil {
+		return s.deadLetter(ctx, sub, row, 0, err)
+	}
+
+	record := event_store.Record{
+		ID:          row.ID,
+		AggregateID: row.AggregateID,
+		Version:     uint64(row.Version),
+		Position:    uint64(row.Position),
+		Metadata:    metadata(row.CorrelationID, row.CausationID, row.Actor, row.Source),
+		RecordedAt:  row.CreatedAt.Time,
+		Event:       e,
+	}
+
+	handler := func(ctx context.Context, record event_store.Record) error {
+		return s.checkpointed(ctx, sub, row.Position, func(ctx context.Context, q db.Querier) error {
+			return sub.handler(ctx, record)
+		})
+	}
+
+	attempts, err := event_store.Deliver(ctx, s.options.RetryPolicy, handler, record)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return s.deadLetter(ctx, sub, row, attempts, err)
+	}
+
+	return nil
+}
+
+func (s *PostgresStore[A]) deadLetter(ctx context.Context, sub namedSubscription, row db.GetEventsAfterPositionRow, attempts int, cause error) error {
+	log.Printf("subscription %s: dead-lettering event %s at position %d after %d attempts: %v", sub.name, row.EventType, row.Position, attempts, cause)
+
+	return s.checkpointed(ctx, sub, row.Position, func(ctx context.Context, q db.Querier) error {
+		err := q.InsertDeadLetter(ctx, db.InsertDeadLetterParams{
+			Subscription:  sub.name,
+			Position:      row.Position,
+			AggregateID:   row.AggregateID,
+			AggregateType: s.aggregateType,
+			Version:       row.Version,
+			EventType:     row.EventType,
+			EventData:     row.EventData,
+			SchemaVersion: row.SchemaVersion,
+			Attempts:      int32(attempts),
+			Error:         cause.Error(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert dead letter: %w", err)
+		}
+		return nil
+	})
+}
+
+// checkpointed runs fn in a transaction, carried by the context fn receives,
+// which also moves the checkpoint of the subscription to the given position.
+func (s *PostgresStore[A]) checkpointed(ctx context.Context, sub namedSubscription, position int64, fn func(ctx context.Context, q db.Querier) error) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -75,6 +272,261 @@ func (s *PostgresStore[A]) Execute(ctx context.Context, id uuid.UUID, fn func(ag
 
 	qtx := s.queries.WithTx(tx)
 
+	if err := fn(WithTx(ctx, tx), qtx); err != nil {
+		return err
+	}
+
+	err = qtx.SaveCheckpoint(ctx, db.SaveCheckpointParams{
+		Name:          sub.name,
+		AggregateType: s.aggregateType,
+		Position:      position,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SubscribeFrom polls the events table for records of this aggregate type
+// with a position greater than the given one, so the same loop serves both
+// the replay of the history and the live delivery.
+func (s *PostgresStore[A]) SubscribeFrom(ctx context.Context, position uint64, handler event_store.SubscribeHandler) {
+	go func() {
+		for {
+			records, err := s.ReadFrom(ctx, position, subscriptionBatchSize)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("failed to read events after position %d: %v", position, err)
+			}
+
+			for _, record := range records {
+				if ctx.Err() != nil {
+					return
+				}
+
+				if err := handler(event_store.CausedBy(ctx, record), record); err != nil {
+					log.Printf("Event Store handler error: %v", err)
+				}
+				position = record.Position
+			}
+
+			if len(records) == subscriptionBatchSize {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(pollInterval):
+			}
+		}
+	}()
+}
+
+// ReadFrom only returns the events below the high water mark, so that the
+// events committed after them never have a lower position.
+func (s *PostgresStore[A]) ReadFrom(ctx context.Context, position uint64, limit int) ([]event_store.Record, error) {
+	through, err := s.highWaterMark(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.queries.GetEventsAfterPosition(ctx, db.GetEventsAfterPositionParams{
+		AggregateType: s.aggregateType,
+		Position:      int64(position),
+		Through:       through,
+		Limit:         int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+
+	records := make([]event_store.Record, 0, len(rows))
+	for _, row := range rows {
+		e, err := s.decodeEvent(row.EventType, row.SchemaVersion, row.EventData)
+		if err != nil {
+			return records, err
+		}
+
+		records = append(records, event_store.Record{
+			ID:          row.ID,
+			AggregateID: row.AggregateID,
+			Version:     uint64(row.Version),
+			Position:    uint64(row.Position),
+			Metadata:    metadata(row.CorrelationID, row.CausationID, row.Actor, row.Source),
+			RecordedAt:  row.CreatedAt.Time,
+			Event:       e,
+		})
+	}
+
+	return records, nil
+}
+
+// ReadCorrelation returns the records of this aggregate type appended within
+// the given correlation, in position order.
+func (s *PostgresStore[A]) ReadCorrelation(ctx context.Context, correlationID uuid.UUID) ([]event_store.Record, error) {
+	rows, err := s.queries.GetEventsByCorrelation(ctx, db.GetEventsByCorrelationParams{
+		AggregateType: s.aggregateType,
+		CorrelationID: uuid.NullUUID{UUID: correlationID, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+
+	records := make([]event_store.Record, 0, len(rows))
+	for _, row := range rows {
+		e, err := s.decodeEvent(row.EventType, row.SchemaVersion, row.EventData)
+		if err != nil {
+			return records, err
+		}
+
+		records = append(records, event_store.Record{
+			ID:          row.ID,
+			AggregateID: row.AggregateID,
+			Version:     uint64(row.Version),
+			Position:    uint64(row.Position),
+			Metadata:    metadata(row.CorrelationID, row.CausationID, row.Actor, row.Source),
+			RecordedAt:  row.CreatedAt.Time,
+			Event:       e,
+		})
+	}
+
+	return records, nil
+}
+
+func (s *PostgresStore[A]) ReadAggregate(ctx context.Context, id uuid.UUID) ([]event_store.Record, error) {
+	rows, err := s.queries.GetEventsAfterVersion(ctx, db.GetEventsAfterVersionParams{
+		AggregateID: id,
+		Version:     0,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+
+	records := make([]event_store.Record, 0, len(rows))
+	for _, row := range rows {
+		e, err := s.decodeEvent(row.EventType, row.SchemaVersion, row.EventData)
+		if err != nil {
+			return records, err
+		}
+
+		records = append(records, event_store.Record{
+			ID:          row.ID,
+			AggregateID: id,
+			Version:     uint64(row.Version),
+			Position:    uint64(row.Position),
+			Metadata:    metadata(row.CorrelationID, row.CausationID, row.Actor, row.Source),
+			RecordedAt:  row.CreatedAt.Time,
+			Event:       e,
+		})
+	}
+
+	return records, nil
+}
+
+// LastPosition returns the position of the latest event of this aggregate
+// type below the high water mark, or 0 when there is none.
+func (s *PostgresStore[A]) LastPosition(ctx context.Context) (uint64, error) {
+	through, err := s.highWaterMark(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	position, err := s.queries.GetLastPosition(ctx, db.GetLastPositionParams{
+		AggregateType: s.aggregateType,
+		Through:       through,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last position: %w", err)
+	}
+	return uint64(position), nil
+}
+
+// highWaterMark returns the position up to which every event of every
+// aggregate type is committed: once the appends in progress are over, the
+// next ones can only take greater positions.
+func (s *PostgresStore[A]) highWaterMark(ctx context.Context) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	if err := qtx.LockEventsRead(ctx); err != nil {
+		return 0, fmt.Errorf("failed to acquire read lock: %w", err)
+	}
+
+	position, err := qtx.GetHighWaterMark(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get high water mark: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return position, nil
+}
+
+// LockSubscription blocks until tx holds the lock of the named subscription
+// on this aggregate type, pausing its worker until tx ends.
+func (s *PostgresStore[A]) LockSubscription(ctx context.Context, tx *sql.Tx, name string) error {
+	err := s.queries.WithTx(tx).LockSubscription(ctx, db.LockSubscriptionParams{
+		Name:          name,
+		AggregateType: s.aggregateType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to acquire subscription lock: %w", err)
+	}
+	return nil
+}
+
+// SaveCheckpoint moves the checkpoint of the named subscription on this
+// aggregate type to the given position.
+func (s *PostgresStore[A]) SaveCheckpoint(ctx context.Context, tx *sql.Tx, name string, position uint64) error {
+	err := s.queries.WithTx(tx).SaveCheckpoint(ctx, db.SaveCheckpointParams{
+		Name:          name,
+		AggregateType: s.aggregateType,
+		Position:      int64(position),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// decodeEvent upcasts a stored payload to the current schema version of its
+// type and decodes it. Every read path of the store goes through it.
+func (s *PostgresStore[A]) decodeEvent(eventType string, schemaVersion int32, data json.RawMessage) (event_store.Event, error) {
+	content, err := event_store.UnmarshalEvent(s.eventFactory, s.options.Upcasters, eventType, int(schemaVersion), data)
+	if err != nil {
+		return nil, err
+	}
+
+	return event{
+		EventType:    eventType,
+		EventContent: content,
+	}, nil
+}
+
+func (s *PostgresStore[A]) Execute(ctx context.Context, id uuid.UUID, fn func(aggr A, version uint64) ([]event_store.Event, error)) error {
+	fn = event_store.Checked(ctx, id, fn)
+	if tx, ok := TxFrom(ctx); ok {
+		return s.execute(ctx, s.queries.WithTx(tx), id, fn)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// Acquire a transactional advisory lock on the aggregate ID.
 	// We use the first 8 bytes of the UUID as the lock key.
 	lockKey := int64(binary.BigEndian.Uint64(id[:8]))
@@ -83,37 +535,114 @@ func (s *PostgresStore[A]) Execute(ctx context.Context, id uuid.UUID, fn func(ag
 		return fmt.Errorf("failed to acquire advisory lock: %w", err)
 	}
 
-	aggr, version, err := s.getAggregate(ctx, qtx, id)
+	if err := s.execute(ctx, s.queries.WithTx(tx), id, fn); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// AppendExpected does not lock the aggregate: of two concurrent appends at the
+// same version, the unique index on the aggregate versions rejects the last.
+func (s *PostgresStore[A]) AppendExpected(ctx context.Context, id uuid.UUID, expectedVersion uint64, events []event_store.Event) error {
+	fn := event_store.Expecting[A](id, expectedVersion, events)
+	if tx, ok := TxFrom(ctx); ok {
+		return s.execute(ctx, s.queries.WithTx(tx), id, fn)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.execute(ctx, s.queries.WithTx(tx), id, fn); err != nil {
 		return err
 	}
 
-	e, err := fn(aggr, version)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PostgresStore[A]) execute(ctx context.Context, q db.Querier, id uuid.UUID, fn func(aggr A, version uint64) ([]event_store.Event, error)) error {
+	aggr, version, err := s.getAggregate(ctx, q, id)
 	if err != nil {
 		return err
 	}
 
-	if e == nil {
-		return tx.Commit()
+	events, err := fn(aggr, version)
+	if err != nil {
+		return err
 	}
 
-	log.Printf("event: %v", e)
+	records := event_store.NewRecords(id, version, events)
+	if len(records) == 0 {
+		return nil
+	}
 
-	record := event_store.Record{
-		AggregateID: id,
-		Version:     version + 1,
-		Event: event{
-			EventType:    e.Type(),
-			EventContent: e.Content(),
-		},
+	for _, record := range records {
+		log.Printf("event: %v", record.Event)
+
+		if err := s.append(ctx, q, record); err != nil {
+			return err
+		}
 	}
 
-	if err := s.append(ctx, qtx, record); err != nil {
+	last := records[len(records)-1].Version
+	if s.options.SnapshotPolicy(version, last) {
+		if err := aggr.Hydrate(records); err != nil {
+			return fmt.Errorf("failed to hydrate aggregate: %w", err)
+		}
+
+		if err := s.saveSnapshot(ctx, q, id, aggr, last); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// TakeSnapshot stores the current state of the aggregate, if it implements
+// event_store.Snapshotter.
+func (s *PostgresStore[A]) TakeSnapshot(ctx context.Context, id uuid.UUID) error {
+	aggr, version, err := s.getAggregate(ctx, s.queries, id)
+	if err != nil {
 		return err
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	if version == 0 {
+		return nil
+	}
+
+	return s.saveSnapshot(ctx, s.queries, id, aggr, version)
+}
+
+func (s *PostgresStore[A]) saveSnapshot(ctx context.Context, q db.Querier, id uuid.UUID, aggr A, version uint64) error {
+	snapshotter, ok := any(aggr).(event_store.Snapshotter)
+	if !ok {
+		return nil
+	}
+
+	data, err := snapshotter.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot aggregate %s: %w", id, err)
+	}
+
+	err = q.SaveSnapshot(ctx, db.SaveSnapshotParams{
+		AggregateID:   id,
+		AggregateType: s.aggregateType,
+		Version:       int64(version),
+		Data:          data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
 	}
 
 	return nil
@@ -167,27 +696,57 @@ func (s *PostgresStore[A]) Append(ctx context.Context, record event_store.Record
 
 func (s *PostgresStore[A]) append(ctx context.Context, q db.Querier, record event_store.Record) error {
 	aggregateType := s.aggregateType
+	record = event_store.Stamp(ctx, record)
 
 	eventData, err := json.Marshal(record.Content())
 	if err != nil {
 		return fmt.Errorf("failed to marshal event data: %w", err)
 	}
 
+	// Appends only wait for each other on the aggregates they share; the lock
+	// keeps the readers of the positions from running past this one until it
+	// is committed.
+	if err := q.LockEventsAppend(ctx); err != nil {
+		return fmt.Errorf("failed to acquire append lock: %w", err)
+	}
+
 	params := db.AppendEventParams{
-		ID:            uuid.New(),
+		ID:            record.ID,
 		AggregateID:   record.AggregateID,
 		AggregateType: aggregateType,
 		Version:       int64(record.Version),
 		EventType:     record.Type(),
 		EventData:     eventData,
+		SchemaVersion: int32(s.options.Upcasters.Version(record.Type())),
+		CorrelationID: nullUUID(record.Metadata.CorrelationID),
+		CausationID:   nullUUID(record.Metadata.CausationID),
+		Actor:         record.Metadata.Actor,
+		Source:        record.Metadata.Source,
 	}
 
-	err = q.AppendEvent(ctx, params)
+	appended, err := q.AppendEvent(ctx, params)
+	if isVersionConflict(err) {
+		return fmt.Errorf("%w: version %d of aggregate %s already exists", event_store.ErrConcurrencyConflict, record.Version, record.AggregateID)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to insert event: %w", err)
 	}
 
-	err = q.AppendToOutbox(ctx, db.AppendToOutboxParams(params))
+	err = q.AppendToOutbox(ctx, db.AppendToOutboxParams{
+		ID:            params.ID,
+		AggregateID:   params.AggregateID,
+		AggregateType: params.AggregateType,
+		Version:       params.Version,
+		EventType:     params.EventType,
+		EventData:     params.EventData,
+		Position:      appended.Position,
+		SchemaVersion: params.SchemaVersion,
+		CorrelationID: params.CorrelationID,
+		CausationID:   params.CausationID,
+		Actor:         params.Actor,
+		Source:        params.Source,
+		CreatedAt:     appended.CreatedAt,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to insert outbox event: %w", err)
 	}
@@ -202,40 +761,39 @@ func (s *PostgresStore[A]) GetAggregate(ctx context.Context, id uuid.UUID) (A, u
 func (s *PostgresStore[A]) getAggregate(ctx context.Context, q db.Querier, id uuid.UUID) (A, uint64, error) {
 	var zero A
 
-	events, err := q.GetEvents(ctx, id)
+	aggregate, version, err := s.restoreSnapshot(ctx, q, id)
+	if err != nil {
+		return zero, 0, err
+	}
+
+	events, err := q.GetEventsAfterVersion(ctx, db.GetEventsAfterVersionParams{
+		AggregateID: id,
+		Version:     int64(version),
+	})
 	if err != nil {
 		return zero, 0, fmt.Errorf("failed to query events: %w", err)
 	}
 
-	aggregate := s.new(id)
-	var version uint64 = 0
 	for _, row := range events {
 		version++
 		if version != uint64(row.Version) {
 			return zero, version, fmt.Errorf("invalid version number for aggregate %s: %v, expected %v", id, row.Version, version)
 		}
 
-		factory, ok := s.eventFactory[row.EventType]
-		if !ok {
-			return zero, version, fmt.Errorf("unknown event type: %s", row.EventType)
-		}
-
-		eventPtr := factory()
-		if err := json.Unmarshal(row.EventData, eventPtr); err != nil {
-			return zero, version, fmt.Errorf("failed to unmarshal event data: %w", err)
+		e, err := s.decodeEvent(row.EventType, row.SchemaVersion, row.EventData)
+		if err != nil {
+			return zero, version, err
 		}
 
-		// Dereference if it's a pointer to get the value
-		content := reflect.ValueOf(eventPtr).Elem().Interface()
-
 		records := []event_store.Record{
 			{
+				ID:          row.ID,
 				AggregateID: id,
 				Version:     uint64(row.Version),
-				Event: event{
-					EventType:    row.EventType,
-					EventContent: content,
-				},
+				Position:    uint64(row.Position),
+				Metadata:    metadata(row.CorrelationID, row.CausationID, row.Actor, row.Source),
+				RecordedAt:  row.CreatedAt.Time,
+				Event:       e,
 			},
 		}
 
@@ -247,12 +805,47 @@ func (s *PostgresStore[A]) getAggregate(ctx context.Context, q db.Querier, id uu
 	return aggregate, version, nil
 }
 
+// restoreSnapshot returns a fresh aggregate, restored from its latest snapshot
+// when one is available, together with the version the snapshot was taken at.
+func (s *PostgresStore[A]) restoreSnapshot(ctx context.Context, q db.Querier, id uuid.UUID) (A, uint64, error) {
+	aggregate := s.new(id)
+
+	snapshotter, ok := any(aggregate).(event_store.Snapshotter)
+	if !ok {
+		return aggregate, 0, nil
+	}
+
+	snapshot, err := q.GetLatestSnapshot(ctx, db.GetLatestSnapshotParams{
+		AggregateType: s.aggregateType,
+		AggregateID:   id,
+	})
+	if err == sql.ErrNoRows {
+		return aggregate, 0, nil
+	}
+	if err != nil {
+		var zero A
+		return zero, 0, fmt.Errorf("failed to query snapshot: %w", err)
+	}
+
+	if err := snapshotter.Restore(snapshot.Data); err != nil {
+		// A snapshot that no longer matches the aggregate shape is not fatal,
+		// the full history is still available.
+		log.Printf("ignoring snapshot of aggregate %s at version %d: %v", id, snapshot.Version, err)
+		return s.new(id), 0, nil
+	}
+
+	return aggregate, uint64(snapshot.Version), nil
+}
+
 func (s *PostgresStore[A]) Close() error {
 	return nil
 }
 
 func (s *PostgresStore[A]) handleEvents(ctx context.Context) error {
-	rows, err := s.queries.GetOutboxEvents(ctx, 10)
+	rows, err := s.queries.GetOutboxEvents(ctx, db.GetOutboxEventsParams{
+		AggregateType: s.aggregateType,
+		Limit:         10,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to get outbox events: %w", err)
 	}
@@ -261,26 +854,43 @@ func (s *PostgresStore[A]) handleEvents(ctx context.Context) error {
 		return nil
 	}
 
-	for _, row := range rows {
-		factory, ok := s.eventFactory[row.EventType]
-		if !ok {
-			return fmt.Errorf("event factory not found for event %s", row.EventType)
+	if s.options.Publisher != nil {
+		messages := make([]event_store.Message, 0, len(rows))
+		for _, row := range rows {
+			messages = append(messages, event_store.Message{
+				ID:            row.ID,
+				AggregateID:   row.AggregateID,
+				AggregateType: row.AggregateType,
+				Version:       uint64(row.Version),
+				Position:      uint64(row.Position),
+				EventType:     row.EventType,
+				SchemaVersion: int(row.SchemaVersion),
+				Metadata:      metadata(row.CorrelationID, row.CausationID, row.Actor, row.Source),
+				Data:          row.EventData,
+				CreatedAt:     row.CreatedAt.Time,
+			})
 		}
 
-		eventPtr := factory()
-		if err := json.Unmarshal(row.EventData, eventPtr); err != nil {
-			return fmt.Errorf("failed to unmarshal event: %w", err)
+		if err := s.options.Publisher.Publish(ctx, messages); err != nil {
+			log.Printf("failed to publish %s outbox: %v", s.aggregateType, err)
+			return fmt.Errorf("failed to publish outbox events: %w", err)
 		}
+	}
 
-		content := reflect.ValueOf(eventPtr).Elem().Interface()
+	for _, row := range rows {
+		e, err := s.decodeEvent(row.EventType, row.SchemaVersion, row.EventData)
+		if err != nil {
+			return err
+		}
 
 		record := event_store.Record{
+			ID:          row.ID,
 			AggregateID: row.AggregateID,
 			Version:     uint64(row.Version),
-			Event: event{
-				EventType:    row.EventType,
-				EventContent: content,
-			},
+			Position:    uint64(row.Position),
+			Metadata:    metadata(row.CorrelationID, row.CausationID, row.Actor, row.Source),
+			RecordedAt:  row.CreatedAt.Time,
+			Event:       e,
 		}
 
 		s.mu.RLock()
@@ -288,7 +898,7 @@ func (s *PostgresStore[A]) handleEvents(ctx context.Context) error {
 		s.mu.RUnlock()
 
 		for _, h := range handlers {
-			if err := h(ctx, record); err != nil {
+			if err := h(event_store.CausedBy(ctx, record), record); err != nil {
 				log.Printf("Event Store handler error: %v", err)
 			}
 		}
@@ -301,6 +911,19 @@ func (s *PostgresStore[A]) handleEvents(ctx context.Context) error {
 	return nil
 }
 
+func metadata(correlationID, causationID uuid.NullUUID, actor, source string) event_store.Metadata {
+	return event_store.Metadata{
+		CorrelationID: correlationID.UUID,
+		CausationID:   causationID.UUID,
+		Actor:         actor,
+		Source:        source,
+	}
+}
+
+func nullUUID(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: id != uuid.Nil}
+}
+
 func (s *PostgresStore[A]) getAggregateType() string {
 	aggregate := s.new(uuid.New())
 	aggregateType := reflect.TypeOf(aggregate)
@@ -311,3 +934,10 @@ func (s *PostgresStore[A]) getAggregateType() string {
 	parts := strings.Split(aggregateType.String(), ".")
 	return parts[len(parts)-1]
 }
+
+// isVersionConflict reports whether err is the violation of the unique index
+// on the aggregate versions, raised when another writer appended first.
+func isVersionConflict(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "idx_events_aggregate_id_version"
+}
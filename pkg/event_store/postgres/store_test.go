@@ -5,14 +5,18 @@ import (
 	"database/sql"
 	"encoding/json"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/somatom98/brokeli/pkg/database"
 	"github.com/somatom98/brokeli/pkg/event_store"
+	event_store_db "github.com/somatom98/brokeli/pkg/event_store/postgres/db"
 )
 
 // MockAggregate for testing
@@ -49,10 +53,7 @@ func TestPostgresStore_Concurrency_UniqueVersion(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	// Ensure clean state for this test
-	schema, err := os.ReadFile("db/migration/000001_create_events_table.up.sql")
-	require.NoError(t, err)
-	_, err = db.Exec(string(schema))
+	err = database.Migrate(db, event_store_db.MigrationsFS(), "event_store_migrations")
 	require.NoError(t, err)
 
 	id := uuid.New()
@@ -84,7 +85,57 @@ func TestPostgresStore_Concurrency_UniqueVersion(t *testing.T) {
 
 	// Second append with SAME version should FAIL
 	err = store.Append(ctx, rec2)
-	assert.Error(t, err, "Expected error when appending event with duplicate version")
+	assert.ErrorIs(t, err, event_store.ErrConcurrencyConflict)
+}
+
+func TestPostgresStore_AppendExpected(t *testing.T) {
+	dsn := os.Getenv("DB_DSN")
+	if dsn == "" {
+		t.Skip("Skipping integration test: DB_DSN not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = database.Migrate(db, event_store_db.MigrationsFS(), "event_store_migrations")
+	require.NoError(t, err)
+
+	store, err := NewPostgresStore[*MockAggregate](
+		db,
+		func(uid uuid.UUID) *MockAggregate { return &MockAggregate{ID: uid} },
+		map[string]func() any{"TEST": func() any { return new(string) }},
+	)
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+
+	t.Run("should append the events at the expected version", func(t *testing.T) {
+		id := uuid.New()
+
+		err := store.AppendExpected(ctx, id, 0, []event_store.Event{MockEvent{typ: "TEST", content: "A"}})
+		require.NoError(t, err)
+		err = store.AppendExpected(ctx, id, 1, []event_store.Event{MockEvent{typ: "TEST", content: "B"}})
+		require.NoError(t, err)
+
+		aggr, version, err := store.GetAggregate(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), version)
+		assert.Equal(t, "B", aggr.State)
+	})
+
+	t.Run("should return a concurrency conflict at another version", func(t *testing.T) {
+		id := uuid.New()
+		require.NoError(t, store.AppendExpected(ctx, id, 0, []event_store.Event{MockEvent{typ: "TEST", content: "A"}}))
+
+		err := store.AppendExpected(ctx, id, 0, []event_store.Event{MockEvent{typ: "TEST", content: "B"}})
+		assert.ErrorIs(t, err, event_store.ErrConcurrencyConflict)
+
+		_, version, err := store.GetAggregate(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), version)
+	})
 }
 
 func TestPostgresStore_Execute_NilInterface(t *testing.T) {
@@ -109,9 +160,493 @@ func TestPostgresStore_Execute_NilInterface(t *testing.T) {
 	ctx := context.Background()
 
 	// This should NOT panic
-	err = store.Execute(ctx, id, func(aggr *MockAggregate, version uint64) (event_store.Event, error) {
+	err = store.Execute(ctx, id, func(aggr *MockAggregate, version uint64) ([]event_store.Event, error) {
 		var e *MockEvent
-		return e, nil
+		return []event_store.Event{e}, nil
 	})
 	assert.NoError(t, err)
 }
+
+// SnapshotAggregate counts hydrated records and supports snapshots.
+type SnapshotAggregate struct {
+	ID       uuid.UUID
+	Count    int
+	Hydrated int
+}
+
+func (a *SnapshotAggregate) Hydrate(records []event_store.Record) error {
+	a.Count += len(records)
+	a.Hydrated += len(records)
+	return nil
+}
+
+func (a *SnapshotAggregate) Snapshot() ([]byte, error) {
+	return json.Marshal(a.Count)
+}
+
+func (a *SnapshotAggregate) Restore(data []byte) error {
+	return json.Unmarshal(data, &a.Count)
+}
+
+func TestPostgresStore_Snapshots(t *testing.T) {
+	dsn := os.Getenv("DB_DSN")
+	if dsn == "" {
+		t.Skip("Skipping integration test: DB_DSN not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = database.Migrate(db, event_store_db.MigrationsFS(), "event_store_migrations")
+	require.NoError(t, err)
+
+	store, err := NewPostgresStore[*SnapshotAggregate](
+		db,
+		func(uid uuid.UUID) *SnapshotAggregate { return &SnapshotAggregate{ID: uid} },
+		map[string]func() any{"TEST": func() any { return new(string) }},
+		event_store.WithSnapshotPolicy(event_store.EveryNEvents(3)),
+	)
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	id := uuid.New()
+
+	for range 7 {
+		err = store.Execute(ctx, id, func(aggr *SnapshotAggregate, version uint64) ([]event_store.Event, error) {
+			return []event_store.Event{MockEvent{typ: "TEST", content: "A"}}, nil
+		})
+		require.NoError(t, err)
+	}
+
+	aggr, version, err := store.GetAggregate(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), version)
+	assert.Equal(t, 7, aggr.Count)
+	assert.Equal(t, 1, aggr.Hydrated)
+}
+
+func TestPostgresStore_SubscribeFrom(t *testing.T) {
+	dsn := os.Getenv("DB_DSN")
+	if dsn == "" {
+		t.Skip("Skipping integration test: DB_DSN not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = database.Migrate(db, event_store_db.MigrationsFS(), "event_store_migrations")
+	require.NoError(t, err)
+
+	store, err := NewPostgresStore[*MockAggregate](
+		db,
+		func(uid uuid.UUID) *MockAggregate { return &MockAggregate{ID: uid} },
+		map[string]func() any{"TEST": func() any { return new(string) }},
+	)
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	id := uuid.New()
+	execute := func(content string) {
+		err := store.Execute(ctx, id, func(aggr *MockAggregate, version uint64) ([]event_store.Event, error) {
+			return []event_store.Event{MockEvent{typ: "TEST", content: content}}, nil
+		})
+		require.NoError(t, err)
+	}
+
+	execute("A")
+	execute("B")
+
+	var mu sync.Mutex
+	var received []event_store.Record
+	store.SubscribeFrom(ctx, 0, func(ctx context.Context, record event_store.Record) error {
+		if record.AggregateID != id {
+			return nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, record)
+		return nil
+	})
+
+	execute("C")
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 3
+	}, 5*time.Second, 50*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, record := range received {
+		assert.Equal(t, uint64(i+1), record.Version)
+		assert.Equal(t, string(rune('A'+i)), record.Content())
+		if i > 0 {
+			assert.Greater(t, record.Position, received[i-1].Position)
+		}
+	}
+}
+
+func TestPostgresStore_SubscribeNamed(t *testing.T) {
+	dsn := os.Getenv("DB_DSN")
+	if dsn == "" {
+		t.Skip("Skipping integration test: DB_DSN not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = database.Migrate(db, event_store_db.MigrationsFS(), "event_store_migrations")
+	require.NoError(t, err)
+
+	store, err := NewPostgresStore[*MockAggregate](
+		db,
+		func(uid uuid.UUID) *MockAggregate { return &MockAggregate{ID: uid} },
+		map[string]func() any{"TEST": func() any { return new(string) }},
+		event_store.WithRetryPolicy(event_store.RetryPolicy{
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+		}),
+	)
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	id := uuid.New()
+	for _, content := range []string{"poison", "healthy"} {
+		err := store.Execute(ctx, id, func(aggr *MockAggregate, version uint64) ([]event_store.Event, error) {
+			return []event_store.Event{MockEvent{typ: "TEST", content: content}}, nil
+		})
+		require.NoError(t, err)
+	}
+
+	name := "test_" + id.String()
+	var mu sync.Mutex
+	var delivered []string
+	store.SubscribeNamed(ctx, name, func(ctx context.Context, record event_store.Record) error {
+		if record.AggregateID != id {
+			return nil
+		}
+		if record.Content() == "poison" {
+			return assert.AnError
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, record.Content().(string))
+		return nil
+	})
+	go store.RunRelay(ctx)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+
+	var attempts int
+	err = db.QueryRow("SELECT attempts FROM dead_letter_events WHERE subscription = $1", name).Scan(&attempts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+
+	var position int64
+	err = db.QueryRow("SELECT position FROM subscription_checkpoints WHERE name = $1", name).Scan(&position)
+	require.NoError(t, err)
+	assert.Positive(t, position)
+}
+
+func TestPostgresStore_SubscribeNamed_Transactional(t *testing.T) {
+	dsn := os.Getenv("DB_DSN")
+	if dsn == "" {
+		t.Skip("Skipping integration test: DB_DSN not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = database.Migrate(db, event_store_db.MigrationsFS(), "event_store_migrations")
+	require.NoError(t, err)
+
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS subscription_test_writes (aggregate_id UUID NOT NULL, content TEXT NOT NULL)")
+	require.NoError(t, err)
+
+	store, err := NewPostgresStore[*MockAggregate](
+		db,
+		func(uid uuid.UUID) *MockAggregate { return &MockAggregate{ID: uid} },
+		map[string]func() any{"TEST": func() any { return new(string) }},
+		event_store.WithRetryPolicy(event_store.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+		}),
+	)
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	id := uuid.New()
+	for _, content := range []string{"flaky", "healthy"} {
+		err := store.Execute(ctx, id, func(aggr *MockAggregate, version uint64) ([]event_store.Event, error) {
+			return []event_store.Event{MockEvent{typ: "TEST", content: content}}, nil
+		})
+		require.NoError(t, err)
+	}
+
+	var mu sync.Mutex
+	failed := false
+	store.SubscribeNamed(ctx, "test_"+id.String(), func(ctx context.Context, record event_store.Record) error {
+		if record.AggregateID != id {
+			return nil
+		}
+		tx, ok := TxFrom(ctx)
+		if !ok {
+			return assert.AnError
+		}
+		_, err := tx.ExecContext(ctx, "INSERT INTO subscription_test_writes (aggregate_id, content) VALUES ($1, $2)", id, record.Content())
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if record.Content() == "flaky" && !failed {
+			failed = true
+			return assert.AnError
+		}
+		return nil
+	})
+	go store.RunRelay(ctx)
+
+	// The write of the failed attempt is rolled back with it.
+	assert.Eventually(t, func() bool {
+		var count int
+		err := db.QueryRow("SELECT COUNT(*) FROM subscription_test_writes WHERE aggregate_id = $1", id).Scan(&count)
+		return err == nil && count == 2
+	}, 5*time.Second, 50*time.Millisecond)
+
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM subscription_test_writes WHERE aggregate_id = $1 AND content = 'flaky'", id).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+type PublisherMock struct {
+	mu       sync.Mutex
+	messages []event_store.Message
+}
+
+func (p *PublisherMock) Publish(ctx context.Context, messages []event_store.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, messages...)
+	return nil
+}
+
+func TestPostgresStore_RelayPublisher(t *testing.T) {
+	dsn := os.Getenv("DB_DSN")
+	if dsn == "" {
+		t.Skip("Skipping integration test: DB_DSN not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = database.Migrate(db, event_store_db.MigrationsFS(), "event_store_migrations")
+	require.NoError(t, err)
+
+	publisher := &PublisherMock{}
+	store, err := NewPostgresStore[*MockAggregate](
+		db,
+		func(uid uuid.UUID) *MockAggregate { return &MockAggregate{ID: uid} },
+		map[string]func() any{"TEST": func() any { return new(string) }},
+		event_store.WithPublisher(publisher),
+	)
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	id := uuid.New()
+	err = store.Execute(ctx, id, func(aggr *MockAggregate, version uint64) ([]event_store.Event, error) {
+		return []event_store.Event{MockEvent{typ: "TEST", content: "A"}}, nil
+	})
+	require.NoError(t, err)
+
+	go store.RunRelay(ctx)
+
+	assert.Eventually(t, func() bool {
+		publisher.mu.Lock()
+		defer publisher.mu.Unlock()
+		for _, m := range publisher.messages {
+			if m.AggregateID == id {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 50*time.Millisecond)
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	for _, m := range publisher.messages {
+		assert.Equal(t, "MockAggregate", m.AggregateType)
+		if m.AggregateID == id {
+			assert.Equal(t, uint64(1), m.Version)
+			assert.Positive(t, m.Position)
+			assert.JSONEq(t, `"A"`, string(m.Data))
+		}
+	}
+}
+
+func TestPostgresStore_Upcasters(t *testing.T) {
+	dsn := os.Getenv("DB_DSN")
+	if dsn == "" {
+		t.Skip("Skipping integration test: DB_DSN not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = database.Migrate(db, event_store_db.MigrationsFS(), "event_store_migrations")
+	require.NoError(t, err)
+
+	upcasters := event_store.NewUpcasters().
+		Register("TEST", 1, func(data json.RawMessage) (json.RawMessage, error) {
+			var v1 string
+			if err := json.Unmarshal(data, &v1); err != nil {
+				return nil, err
+			}
+			return json.Marshal(v1 + "2")
+		})
+
+	store, err := NewPostgresStore[*MockAggregate](
+		db,
+		func(uid uuid.UUID) *MockAggregate { return &MockAggregate{ID: uid} },
+		map[string]func() any{"TEST": func() any { return new(string) }},
+		event_store.WithUpcasters(upcasters),
+	)
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	id := uuid.New()
+
+	// An event written before the payload changed shape.
+	_, err = db.Exec(
+		"INSERT INTO events (id, aggregate_id, aggregate_type, version, event_type, event_data, schema_version) VALUES ($1, $2, 'MockAggregate', 1, 'TEST', '\"A\"', 1)",
+		uuid.New(), id,
+	)
+	require.NoError(t, err)
+
+	err = store.Execute(ctx, id, func(aggr *MockAggregate, version uint64) ([]event_store.Event, error) {
+		assert.Equal(t, "A2", aggr.State)
+		return []event_store.Event{MockEvent{typ: "TEST", content: "B2"}}, nil
+	})
+	require.NoError(t, err)
+
+	var schemaVersion int
+	err = db.QueryRow("SELECT schema_version FROM events WHERE aggregate_id = $1 AND version = 2", id).Scan(&schemaVersion)
+	require.NoError(t, err)
+	assert.Equal(t, 2, schemaVersion)
+
+	aggr, _, err := store.GetAggregate(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "B2", aggr.State)
+
+	records, err := store.ReadFrom(ctx, 0, 1_000_000)
+	require.NoError(t, err)
+	contents := make([]any, 0)
+	for _, record := range records {
+		if record.AggregateID == id {
+			contents = append(contents, record.Content())
+		}
+	}
+	assert.Equal(t, []any{"A2", "B2"}, contents)
+}
+
+func TestPostgresStore_UnitOfWork(t *testing.T) {
+	dsn := os.Getenv("DB_DSN")
+	if dsn == "" {
+		t.Skip("Skipping integration test: DB_DSN not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = database.Migrate(db, event_store_db.MigrationsFS(), "event_store_migrations")
+	require.NoError(t, err)
+
+	store, err := NewPostgresStore[*MockAggregate](
+		db,
+		func(uid uuid.UUID) *MockAggregate { return &MockAggregate{ID: uid} },
+		map[string]func() any{"TEST": func() any { return new(string) }},
+	)
+	require.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	uow := NewUnitOfWork(db)
+	emit := func(contents ...string) func(aggr *MockAggregate, version uint64) ([]event_store.Event, error) {
+		return func(aggr *MockAggregate, version uint64) ([]event_store.Event, error) {
+			events := make([]event_store.Event, 0, len(contents))
+			for _, content := range contents {
+				events = append(events, MockEvent{typ: "TEST", content: content})
+			}
+			return events, nil
+		}
+	}
+
+	t.Run("should commit the events of every aggregate together", func(t *testing.T) {
+		first, second := uuid.New(), uuid.New()
+
+		err := uow.Do(ctx, func(ctx context.Context) error {
+			if err := store.Execute(ctx, first, emit("A", "B")); err != nil {
+				return err
+			}
+			return store.Execute(ctx, second, emit("C"))
+		})
+		require.NoError(t, err)
+
+		aggr, version, err := store.GetAggregate(ctx, first)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), version)
+		assert.Equal(t, "B", aggr.State)
+
+		_, version, err = store.GetAggregate(ctx, second)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), version)
+	})
+
+	t.Run("should discard every event when the unit fails", func(t *testing.T) {
+		first, second := uuid.New(), uuid.New()
+
+		err := uow.Do(ctx, func(ctx context.Context) error {
+			if err := store.Execute(ctx, first, emit("A")); err != nil {
+				return err
+			}
+			return store.Execute(ctx, second, func(aggr *MockAggregate, version uint64) ([]event_store.Event, error) {
+				return nil, assert.AnError
+			})
+		})
+		assert.ErrorIs(t, err, assert.AnError)
+
+		_, version, err := store.GetAggregate(ctx, first)
+		require.NoError(t, err)
+		assert.Zero(t, version)
+	})
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+type txKey struct{}
+
+// WithTx returns a context whose Execute calls, and the writes of the
+// repositories looking it up with TxFrom, run in tx.
+func WithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFrom returns the transaction carried by ctx: the one of a unit of work, or
+// the one a named subscription applies a record in.
+func TxFrom(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// UnitOfWork runs the Execute calls of the stores sharing its database in a
+// single transaction. Aggregates are not locked within a unit, which would
+// deadlock units locking them in another order: concurrency is optimistic,
+// and an event appended meanwhile with the same version fails the unit on the
+// unique index of the events table.
+type UnitOfWork struct {
+	db *sql.DB
+}
+
+var _ event_store.UnitOfWork = &UnitOfWork{}
+
+func NewUnitOfWork(db *sql.DB) *UnitOfWork {
+	return &UnitOfWork{
+		db: db,
+	}
+}
+
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := TxFrom(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(WithTx(ctx, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
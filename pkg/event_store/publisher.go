@@ -0,0 +1,30 @@
+package event_store
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message is an event in the serialized form it is stored in, ready to be
+// forwarded outside of the process.
+type Message struct {
+	ID            uuid.UUID
+	AggregateID   uuid.UUID
+	AggregateType string
+	Version       uint64
+	Position      uint64
+	EventType     string
+	SchemaVersion int
+	Metadata      Metadata
+	Data          []byte
+	CreatedAt     time.Time
+}
+
+// Publisher forwards the messages of a store outbox to an external system.
+// Messages are removed from the outbox only after Publish succeeds, so they
+// are delivered at least once.
+type Publisher interface {
+	Publish(ctx context.Context, messages []Message) error
+}
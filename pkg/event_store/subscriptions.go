@@ -0,0 +1,36 @@
+package event_store
+
+import (
+	"context"
+	"time"
+)
+
+// DeadLetter is a record a named subscription gave up on, either because it
+// could not be decoded or because its handler kept failing.
+type DeadLetter struct {
+	Subscription string
+	Record       Record
+	Attempts     int
+	Error        string
+}
+
+// Deliver calls the handler until it succeeds, the retry policy is exhausted
+// or ctx is cancelled. It returns the number of attempts made and the last
+// handler error. Events appended by the handler are caused by record.
+func Deliver(ctx context.Context, policy RetryPolicy, handler SubscribeHandler, record Record) (int, error) {
+	ctx = CausedBy(ctx, record)
+	attempts := 0
+	for {
+		attempts++
+		err := handler(ctx, record)
+		if err == nil || attempts >= policy.MaxAttempts {
+			return attempts, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempts, ctx.Err()
+		case <-time.After(policy.Backoff(attempts)):
+		}
+	}
+}
@@ -2,6 +2,7 @@ package event_store
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -12,8 +13,14 @@ type Event interface {
 }
 
 type Record struct {
+	ID          uuid.UUID
 	AggregateID uuid.UUID
 	Version     uint64
+	// Position is the global, monotonically increasing sequence number the
+	// store assigned to the record when it was appended.
+	Position   uint64
+	Metadata   Metadata
+	RecordedAt time.Time
 	Event
 }
 
@@ -21,11 +28,39 @@ type Aggregate interface {
 	Hydrate(records []Record) error
 }
 
+// Snapshotter is implemented by aggregates that can serialize their state, so
+// that hydration can start from the latest snapshot instead of the first event.
+type Snapshotter interface {
+	Snapshot() ([]byte, error)
+	Restore(data []byte) error
+}
+
 type SubscribeHandler func(ctx context.Context, record Record) error
 
 type Store[A Aggregate] interface {
 	Subscribe(ctx context.Context, handler SubscribeHandler)
+	// SubscribeFrom delivers, in order, every record with a position greater
+	// than the given one: first the stored history, then new appends, until
+	// ctx is cancelled.
+	SubscribeFrom(ctx context.Context, position uint64, handler SubscribeHandler)
+	// SubscribeNamed registers a durable subscription: the store keeps a
+	// checkpoint under the given name, retries failing records according to
+	// the retry policy and dead-letters the ones that keep failing.
+	SubscribeNamed(ctx context.Context, name string, handler SubscribeHandler)
+	// ReadFrom returns up to limit records with a position greater than the
+	// given one, in position order.
+	ReadFrom(ctx context.Context, position uint64, limit int) ([]Record, error)
+	// ReadAggregate returns every record of the aggregate, in version order.
+	ReadAggregate(ctx context.Context, id uuid.UUID) ([]Record, error)
 	GetAggregate(ctx context.Context, id uuid.UUID) (A, uint64, error)
 	Append(ctx context.Context, record Record) error
-	Execute(ctx context.Context, id uuid.UUID, fn func(aggr A, version uint64) (Event, error)) error
+	// AppendExpected appends the events to the aggregate only if it is still
+	// at expectedVersion, and fails with ErrConcurrencyConflict otherwise.
+	AppendExpected(ctx context.Context, id uuid.UUID, expectedVersion uint64, events []Event) error
+	// Execute hydrates the aggregate and appends the events fn emits, all
+	// together or not at all. Within a UnitOfWork they are only appended when
+	// the whole unit is committed. The VersionCheck carried by ctx, if any,
+	// applies to fn.
+	Execute(ctx context.Context, id uuid.UUID, fn func(aggr A, version uint64) ([]Event, error)) error
+	TakeSnapshot(ctx context.Context, id uuid.UUID) error
 }
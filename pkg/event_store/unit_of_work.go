@@ -0,0 +1,144 @@
+package event_store
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// UnitOfWork commits the events appended by several Execute calls, on one or
+// more aggregates and stores, all together or not at all.
+type UnitOfWork interface {
+	// Do runs fn. The Execute calls made with the context fn receives are
+	// committed when it returns nil and discarded otherwise.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// One adapts a command emitting at most one event to Execute.
+func One(e Event, err error) ([]Event, error) {
+	if err != nil {
+		return nil, err
+	}
+	return []Event{e}, nil
+}
+
+// WithID sets the ID the event is recorded with, so that the events it causes
+// within the same unit of work can refer to it before it is appended.
+func WithID(id uuid.UUID, e Event) Event {
+	if isNil(e) {
+		return nil
+	}
+	return identified{id: id, Event: e}
+}
+
+type identified struct {
+	id uuid.UUID
+	Event
+}
+
+// NewRecords numbers the events emitted by a command on the aggregate at the
+// given version, skipping the nil ones.
+func NewRecords(id uuid.UUID, version uint64, events []Event) []Record {
+	records := make([]Record, 0, len(events))
+	for _, e := range events {
+		if isNil(e) {
+			continue
+		}
+
+		var recordID uuid.UUID
+		if ie, ok := e.(identified); ok {
+			recordID = ie.id
+		}
+
+		version++
+		records = append(records, Record{
+			ID:          recordID,
+			AggregateID: id,
+			Version:     version,
+			Event: simpleEvent{
+				eventType: e.Type(),
+				content:   e.Content(),
+			},
+		})
+	}
+	return records
+}
+
+func isNil(e Event) bool {
+	if e == nil {
+		return true
+	}
+	v := reflect.ValueOf(e)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// InMemoryUnitOfWork is the UnitOfWork of the in-memory stores. The
+// aggregates executed within a unit stay locked until it ends.
+type InMemoryUnitOfWork struct{}
+
+var _ UnitOfWork = &InMemoryUnitOfWork{}
+
+func NewInMemoryUnitOfWork() *InMemoryUnitOfWork {
+	return &InMemoryUnitOfWork{}
+}
+
+type unitKey struct{}
+
+type inMemoryUnit struct {
+	locks   []*sync.Mutex
+	staged  map[stagedKey][]Record
+	commits []func(ctx context.Context) (publish func())
+}
+
+type stagedKey struct {
+	store any
+	id    uuid.UUID
+}
+
+func (u *InMemoryUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(unitKey{}).(*inMemoryUnit); ok {
+		return fn(ctx)
+	}
+
+	unit := &inMemoryUnit{
+		staged: make(map[stagedKey][]Record),
+	}
+
+	err := fn(context.WithValue(ctx, unitKey{}, unit))
+	if err != nil {
+		unit.unlock()
+		return err
+	}
+
+	publishes := make([]func(), 0, len(unit.commits))
+	for _, commit := range unit.commits {
+		publishes = append(publishes, commit(ctx))
+	}
+
+	// Subscribers may execute commands on the same aggregates.
+	unit.unlock()
+	for _, publish := range publishes {
+		publish()
+	}
+
+	return nil
+}
+
+// lock acquires the lock of an aggregate for the rest of the unit.
+func (u *inMemoryUnit) lock(lock *sync.Mutex) {
+	if slices.Contains(u.locks, lock) {
+		return
+	}
+	lock.Lock()
+	u.locks = append(u.locks, lock)
+}
+
+func (u *inMemoryUnit) unlock() {
+	for _, lock := range u.locks {
+		lock.Unlock()
+	}
+	u.locks = nil
+}
@@ -0,0 +1,95 @@
+package event_store
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Upcaster migrates the payload of an event from one schema version to the
+// next one.
+type Upcaster func(data json.RawMessage) (json.RawMessage, error)
+
+// Upcasters is the registry of the payload migrations of a store, keyed by
+// event type and by the schema version they upgrade from. Events are stored
+// with the current schema version of their type and upcast, one version at a
+// time, when they are read with an older one.
+type Upcasters struct {
+	steps    map[string]map[int]Upcaster
+	versions map[string]int
+}
+
+func NewUpcasters() *Upcasters {
+	return &Upcasters{
+		steps:    make(map[string]map[int]Upcaster),
+		versions: make(map[string]int),
+	}
+}
+
+// Register adds the migration of eventType payloads from schema version from
+// to from+1, which becomes the current version of the type unless a later
+// migration is already registered.
+func (u *Upcasters) Register(eventType string, from int, upcaster Upcaster) *Upcasters {
+	if u.steps[eventType] == nil {
+		u.steps[eventType] = make(map[int]Upcaster)
+	}
+	u.steps[eventType][from] = upcaster
+	u.versions[eventType] = max(u.versions[eventType], from+1)
+	return u
+}
+
+// Version returns the current schema version of eventType, 1 when it never
+// changed.
+func (u *Upcasters) Version(eventType string) int {
+	if u == nil {
+		return 1
+	}
+	return max(u.versions[eventType], 1)
+}
+
+// Upcast migrates a payload stored with the given schema version to the
+// current one of its type. Payloads without a schema version (0) are taken as
+// the first one.
+func (u *Upcasters) Upcast(eventType string, version int, data json.RawMessage) (json.RawMessage, error) {
+	version = max(version, 1)
+	current := u.Version(eventType)
+	if version > current {
+		return nil, fmt.Errorf("schema version %d of %s is newer than the current one %d", version, eventType, current)
+	}
+
+	for ; version < current; version++ {
+		upcaster, ok := u.steps[eventType][version]
+		if !ok {
+			return nil, fmt.Errorf("no upcaster for %s from schema version %d", eventType, version)
+		}
+
+		upcasted, err := upcaster(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upcast %s from schema version %d: %w", eventType, version, err)
+		}
+		data = upcasted
+	}
+
+	return data, nil
+}
+
+// UnmarshalEvent upcasts a stored payload and decodes it into the value built
+// by the factory of its event type.
+func UnmarshalEvent(eventFactory map[string]func() any, upcasters *Upcasters, eventType string, version int, data json.RawMessage) (any, error) {
+	factory, ok := eventFactory[eventType]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type: %s", eventType)
+	}
+
+	data, err := upcasters.Upcast(eventType, version, data)
+	if err != nil {
+		return nil, err
+	}
+
+	eventPtr := factory()
+	if err := json.Unmarshal(data, eventPtr); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event data: %w", err)
+	}
+
+	return reflect.ValueOf(eventPtr).Elem().Interface(), nil
+}
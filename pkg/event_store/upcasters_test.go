@@ -0,0 +1,94 @@
+package event_store_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/somatom98/brokeli/pkg/event_store"
+)
+
+type renamed struct {
+	Total string
+}
+
+func rename(from, to string) event_store.Upcaster {
+	return func(data json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(strings.Replace(string(data), `"`+from+`"`, `"`+to+`"`, 1)), nil
+	}
+}
+
+func TestUpcasters_Upcast(t *testing.T) {
+	upcasters := event_store.NewUpcasters().
+		Register("Spent", 1, rename("Value", "Amount")).
+		Register("Spent", 2, rename("Amount", "Total"))
+
+	t.Run("should report the current schema version of each event type", func(t *testing.T) {
+		// act & assert
+		assert.Equal(t, 3, upcasters.Version("Spent"))
+		assert.Equal(t, 1, upcasters.Version("Received"))
+	})
+
+	t.Run("should apply every upcaster from the stored schema version on", func(t *testing.T) {
+		for version, payload := range map[int]string{
+			1: `{"Value":"10"}`,
+			2: `{"Amount":"10"}`,
+			3: `{"Total":"10"}`,
+		} {
+			// act
+			data, err := upcasters.Upcast("Spent", version, json.RawMessage(payload))
+
+			// assert
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"Total":"10"}`, string(data))
+		}
+	})
+
+	t.Run("should fail on a schema version newer than the current one", func(t *testing.T) {
+		// act
+		_, err := upcasters.Upcast("Spent", 4, json.RawMessage(`{}`))
+
+		// assert
+		assert.Error(t, err)
+	})
+
+	t.Run("should fail when a step of the chain is missing", func(t *testing.T) {
+		// arrange
+		upcasters := event_store.NewUpcasters().Register("Spent", 2, rename("Amount", "Total"))
+
+		// act
+		_, err := upcasters.Upcast("Spent", 1, json.RawMessage(`{}`))
+
+		// assert
+		assert.Error(t, err)
+	})
+}
+
+func TestUnmarshalEvent(t *testing.T) {
+	eventFactory := map[string]func() any{
+		"Spent": func() any { return &renamed{} },
+	}
+	upcasters := event_store.NewUpcasters().
+		Register("Spent", 1, rename("Value", "Amount")).
+		Register("Spent", 2, rename("Amount", "Total"))
+
+	t.Run("should decode an upcast payload into the current event", func(t *testing.T) {
+		// act
+		content, err := event_store.UnmarshalEvent(eventFactory, upcasters, "Spent", 1, json.RawMessage(`{"Value":"10"}`))
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, renamed{Total: "10"}, content)
+	})
+
+	t.Run("should fail on an unknown event type", func(t *testing.T) {
+		// act
+		_, err := event_store.UnmarshalEvent(eventFactory, upcasters, "Received", 1, json.RawMessage(`{}`))
+
+		// assert
+		assert.Error(t, err)
+	})
+}